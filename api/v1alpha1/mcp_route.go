@@ -7,6 +7,7 @@ package v1alpha1
 
 import (
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
@@ -42,6 +43,8 @@ type MCPRouteList struct {
 }
 
 // MCPRouteSpec details the MCPRoute configuration.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.rateLimits) || self.rateLimits.all(l, self.backendRefs.exists(b, b.name == l.backend))", message="rateLimits must reference the names of backendRefs"
 type MCPRouteSpec struct {
 	// ParentRefs are the names of the Gateway resources this MCPRoute is being attached to.
 	// Cross namespace references are not supported. In other words, the Gateway resources must be in the
@@ -56,6 +59,9 @@ type MCPRouteSpec struct {
 	// Path is the HTTP endpoint path that serves MCP requests over the Streamable HTTP transport.
 	// If not specified, the default is "/mcp".
 	//
+	// Clients using the legacy HTTP+SSE transport of the 2024-11-05 MCP specification open the SSE stream at
+	// "<path>/sse" and post their messages to "<path>/messages".
+	//
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=/mcp
 	// +kubebuilder:validation:MaxLength=1024
@@ -84,6 +90,7 @@ type MCPRouteSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:MaxItems=256
 	// +kubebuilder:validation:XValidation:rule="self.all(i, self.exists_one(j, j.name == i.name))", message="all backendRefs names must be unique"
+	// +kubebuilder:validation:XValidation:rule="self.filter(b, has(b.toolNamePrefix) && has(b.toolNamePrefix.disabled) && b.toolNamePrefix.disabled).size() <= 1", message="at most one backendRef can disable the tool name prefix"
 	BackendRefs []MCPRouteBackendRef `json:"backendRefs"`
 
 	// SecurityPolicy defines the security policy for this MCPRoute.
@@ -91,10 +98,34 @@ type MCPRouteSpec struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	SecurityPolicy *MCPRouteSecurityPolicy `json:"securityPolicy,omitempty"`
+
+	// RateLimits limit the number of calls to the tools of the backends of this MCPRoute per client.
+	// A call that exceeds any of the limits is rejected with a JSON-RPC error, and is not sent to the backend.
+	//
+	// By default, the limits are enforced per replica of the gateway. They can be shared by all the replicas
+	// by storing the counters in Redis, see the mcpRateLimitBackend flag of the external processor.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	RateLimits []MCPToolRateLimit `json:"rateLimits,omitempty"`
+
+	// ToolSearch replaces the tools of the backends in the tools/list responses with a pair of meta tools:
+	// search_tools, which returns the tools best matching a query, and call_tool, which calls one of them.
+	// This avoids sending hundreds of tool definitions to the clients when many backends are aggregated.
+	// The tool calls made through call_tool are subject to the same checks as the direct calls.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ToolSearch *MCPToolSearch `json:"toolSearch,omitempty"`
 }
 
 // MCPRouteBackendRef wraps a EG's BackendObjectReference to reference an MCP server.
 // TODO: move to a standalone MCPBackend CRD to avoid k8s object size limit.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.openAPI) && has(self.securityPolicy) && has(self.securityPolicy.apiKey) && has(self.securityPolicy.apiKey.queryParam))", message="queryParam API keys are not supported for openAPI backends"
+// +kubebuilder:validation:XValidation:rule="!(has(self.transport) && self.transport == 'SSE' && has(self.openAPI))", message="transport cannot be SSE for openAPI backends"
+// +kubebuilder:validation:XValidation:rule="!(has(self.transport) && self.transport == 'SSE' && has(self.securityPolicy) && has(self.securityPolicy.apiKey) && has(self.securityPolicy.apiKey.queryParam))", message="queryParam API keys are not supported for SSE backends"
 type MCPRouteBackendRef struct {
 	gwapiv1.BackendObjectReference `json:",inline"`
 
@@ -107,6 +138,26 @@ type MCPRouteBackendRef struct {
 	// +optional
 	Path *string `json:"path,omitempty"`
 
+	// Transport is the MCP transport spoken by the backend MCP server.
+	// If not specified, the default is "StreamableHTTP".
+	//
+	// With "SSE", the backend speaks the legacy HTTP+SSE transport of the 2024-11-05 MCP specification: Path is the
+	// path of its SSE endpoint, and the messages are posted to the endpoint advertised by the server on the stream.
+	// The backend can be aggregated with Streamable HTTP backends in the same MCPRoute.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=StreamableHTTP
+	// +optional
+	Transport *MCPBackendTransport `json:"transport,omitempty"`
+
+	// OpenAPI turns a REST service described by an OpenAPI document into a virtual MCP server. The operations of
+	// the document are exposed as tools, and the tool calls are translated into HTTP requests to the backend.
+	// When specified, Path is ignored and the backend is not expected to speak MCP.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	OpenAPI *MCPOpenAPIBackend `json:"openAPI,omitempty"`
+
 	// ToolSelector filters the tools exposed by this MCP server.
 	// Supports exact matches and RE2-compatible regular expressions for both include and exclude patterns.
 	// If not specified, all tools from the MCP server are exposed.
@@ -114,7 +165,65 @@ type MCPRouteBackendRef struct {
 	// +optional
 	ToolSelector *MCPToolFilter `json:"toolSelector,omitempty"`
 
-	// TODO: we can add resource and prompt selectors in the future.
+	// ResourceSelector filters the resources exposed by this MCP server, matched by their URI.
+	// Resource templates are matched by their URI template.
+	// Supports exact matches and RE2-compatible regular expressions for both include and exclude patterns.
+	// Resources that are not selected are not listed, and cannot be read or subscribed to.
+	// If not specified, all resources from the MCP server are exposed.
+	// +kubebuilder:validation:Optional
+	// +optional
+	ResourceSelector *MCPResourceFilter `json:"resourceSelector,omitempty"`
+
+	// PromptSelector filters the prompts exposed by this MCP server, matched by their name.
+	// Supports exact matches and RE2-compatible regular expressions for both include and exclude patterns.
+	// Prompts that are not selected are not listed, and cannot be retrieved.
+	// If not specified, all prompts from the MCP server are exposed.
+	// +kubebuilder:validation:Optional
+	// +optional
+	PromptSelector *MCPPromptFilter `json:"promptSelector,omitempty"`
+
+	// ToolPinning pins the definitions of the tools exposed by this MCP server to an approved baseline.
+	// The name, description and input schema of each listed tool are fingerprinted and compared with the approved
+	// fingerprints, to detect a compromised MCP server silently changing a tool definition to inject instructions.
+	// If not specified, the tool definitions are not checked.
+	// +kubebuilder:validation:Optional
+	// +optional
+	ToolPinning *MCPToolPinning `json:"toolPinning,omitempty"`
+
+	// SchemaValidation validates the tool calls to this MCP server against the input and output schemas of the
+	// tools advertised by the MCP server. The schemas are cached per session from the tools/list responses,
+	// and the tools are listed again before validating the calls to the tools that have not been listed in the session.
+	// If not specified, the tool calls are not validated.
+	// +kubebuilder:validation:Optional
+	// +optional
+	SchemaValidation *MCPSchemaValidation `json:"schemaValidation,omitempty"`
+
+	// ToolNamePrefix configures the prefix of the names of the tools of this MCP server as exposed to the clients.
+	// If not specified, the tool names are prefixed with the name of this backendRef followed by "__",
+	// e.g. "github__create_issue".
+	// +kubebuilder:validation:Optional
+	// +optional
+	ToolNamePrefix *MCPToolNamePrefix `json:"toolNamePrefix,omitempty"`
+
+	// ToolOverrides customizes the tools of this MCP server as exposed to the clients, e.g. to rename them, to
+	// override their descriptions, or to hide some of their parameters by injecting fixed values.
+	//
+	// The tool selector, the authorization rules and the tool pinning keep referring to the tools by their names as
+	// exposed by the MCP server.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=128
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ToolOverrides []MCPToolOverride `json:"toolOverrides,omitempty"`
+
+	// ToolApproval requires a confirmation before the calls to some of the tools of this MCP server are forwarded
+	// to it, e.g. for the tools with destructive side effects.
+	// If not specified, the tool calls are forwarded without confirmation.
+	// +kubebuilder:validation:Optional
+	// +optional
+	ToolApproval *MCPToolApproval `json:"toolApproval,omitempty"`
 
 	// SecurityPolicy is the security policy to apply to this MCP server.
 	//
@@ -191,11 +300,576 @@ type MCPToolFilter struct {
 	ExcludeRegex []string `json:"excludeRegex,omitempty"`
 }
 
+// MCPResourceFilter filters resources by URI using include and exclude patterns with exact matches or regular
+// expressions. Exclude rules take precedence over include rules (deny-wins).
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.include) && has(self.includeRegex))", message="include and includeRegex are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.exclude) && has(self.excludeRegex))", message="exclude and excludeRegex are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="has(self.include) || has(self.includeRegex) || has(self.exclude) || has(self.excludeRegex)", message="at least one of include, includeRegex, exclude, or excludeRegex must be specified"
+type MCPResourceFilter struct {
+	// Include is a list of resource URIs to include. Only the specified resources will be available.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// IncludeRegex is a list of RE2-compatible regular expressions that, when matched, include the resource.
+	// Only resources whose URI matches these patterns will be available.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	IncludeRegex []string `json:"includeRegex,omitempty"`
+
+	// Exclude is a list of resource URIs to exclude. The specified resources will not be available.
+	// Exclude rules take precedence over include rules.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// ExcludeRegex is a list of RE2-compatible regular expressions that, when matched, exclude the resource.
+	// Resources whose URI matches these patterns will not be available. Exclude rules take precedence over include rules.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	ExcludeRegex []string `json:"excludeRegex,omitempty"`
+}
+
+// MCPPromptFilter filters prompts by name using include and exclude patterns with exact matches or regular
+// expressions. Exclude rules take precedence over include rules (deny-wins).
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.include) && has(self.includeRegex))", message="include and includeRegex are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.exclude) && has(self.excludeRegex))", message="exclude and excludeRegex are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="has(self.include) || has(self.includeRegex) || has(self.exclude) || has(self.excludeRegex)", message="at least one of include, includeRegex, exclude, or excludeRegex must be specified"
+type MCPPromptFilter struct {
+	// Include is a list of prompt names to include. Only the specified prompts will be available.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// IncludeRegex is a list of RE2-compatible regular expressions that, when matched, include the prompt.
+	// Only prompts matching these patterns will be available.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	IncludeRegex []string `json:"includeRegex,omitempty"`
+
+	// Exclude is a list of prompt names to exclude. The specified prompts will not be available.
+	// Exclude rules take precedence over include rules.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// ExcludeRegex is a list of RE2-compatible regular expressions that, when matched, exclude the prompt.
+	// Prompts matching these patterns will not be available. Exclude rules take precedence over include rules.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	ExcludeRegex []string `json:"excludeRegex,omitempty"`
+}
+
+// MCPToolPinningAction is the action taken on the tools whose definition doesn't match the approved fingerprint.
+//
+// +kubebuilder:validation:Enum=Drop;Flag;Block
+type MCPToolPinningAction string
+
+const (
+	// MCPToolPinningActionDrop removes the changed tools from the tools/list responses.
+	MCPToolPinningActionDrop MCPToolPinningAction = "Drop"
+	// MCPToolPinningActionFlag keeps the changed tools in the tools/list responses, and flags them in their "_meta" field.
+	MCPToolPinningActionFlag MCPToolPinningAction = "Flag"
+	// MCPToolPinningActionBlock removes the changed tools from the tools/list responses, and also rejects the calls
+	// to them until their definition matches the approved fingerprint again.
+	MCPToolPinningActionBlock MCPToolPinningAction = "Block"
+)
+
+// MCPRouteConfigMapLabel is the label of the ConfigMaps referenced by the MCPRoutes, such as the tool pinning
+// baselines and the OpenAPI documents. The controller only watches the ConfigMaps with this label, so the changes to
+// the ConfigMaps without it are not picked up until the MCPRoute itself changes.
+const MCPRouteConfigMapLabel = "aigateway.envoyproxy.io/mcp-route-config"
+
+// MCPToolPinning defines the approved baseline of the tool definitions of a backend MCP server.
+type MCPToolPinning struct {
+	// BaselineConfigMapName is the name of the ConfigMap, in the namespace of the MCPRoute, that holds the approved
+	// fingerprints. Each key is the name of a tool as exposed by the MCP server, and each value is the fingerprint of
+	// its approved definition. The ConfigMap can be generated with the "aigw mcp-fingerprint" command.
+	//
+	// Tools that are not in the ConfigMap are not approved. If the ConfigMap doesn't exist, no tool is approved.
+	//
+	// The changes to the ConfigMap are only watched when it has the "aigateway.envoyproxy.io/mcp-route-config" label,
+	// which the "aigw mcp-fingerprint" command sets.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	BaselineConfigMapName string `json:"baselineConfigMapName"`
+
+	// Action is the action taken on the tools whose definition doesn't match the approved fingerprint.
+	// Defaults to "Drop".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Drop
+	// +optional
+	Action *MCPToolPinningAction `json:"action,omitempty"`
+}
+
+// MCPSchemaValidation defines the validation of the tool calls against the schemas of the tools.
+type MCPSchemaValidation struct {
+	// Arguments enables the validation of the tools/call arguments against the input schema of the tool.
+	// Calls with invalid arguments are rejected with a JSON-RPC invalid params error without reaching the MCP server.
+	// Defaults to true.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// +optional
+	Arguments *bool `json:"arguments,omitempty"`
+
+	// StructuredContent is the action taken on the structured content of the tools/call results that doesn't match
+	// the output schema of the tool. If not specified, the results are not validated.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	StructuredContent *MCPStructuredContentValidationAction `json:"structuredContent,omitempty"`
+}
+
+// MCPStructuredContentValidationAction is the action taken on the structured content of a tool call result that
+// doesn't match the output schema of the tool.
+//
+// +kubebuilder:validation:Enum=Flag;Strip
+type MCPStructuredContentValidationAction string
+
+const (
+	// MCPStructuredContentValidationActionFlag keeps the invalid structured content, and flags it in the "_meta" field
+	// of the result.
+	MCPStructuredContentValidationActionFlag MCPStructuredContentValidationAction = "Flag"
+	// MCPStructuredContentValidationActionStrip removes the invalid structured content from the result, leaving only
+	// the unstructured content.
+	MCPStructuredContentValidationActionStrip MCPStructuredContentValidationAction = "Strip"
+)
+
+// MCPToolNamePrefix defines the prefix of the tool names of a backend MCP server.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.disabled) && self.disabled && has(self.separator))", message="separator cannot be set when the prefix is disabled"
+type MCPToolNamePrefix struct {
+	// Disabled exposes the tool names without the prefix. The tool names of this MCP server must then not clash with
+	// the tool names of the other MCP servers of the MCPRoute. At most one backendRef of an MCPRoute can disable the
+	// prefix, so that the tool calls can always be routed to the right MCP server.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Separator separates the name of the backendRef and the tool name. Defaults to "__".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]{1,8}$`
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+}
+
+// MCPToolOverride customizes a tool of a backend MCP server.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.description) && has(self.appendDescription))", message="description and appendDescription are mutually exclusive"
+type MCPToolOverride struct {
+	// Name is the name of the tool as exposed by the MCP server.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Rename is the name of the tool exposed to the clients instead of its original name. The prefix of the
+	// backendRef is still prepended to it unless disabled. It must be unique among the tools of the MCP server.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]{1,128}$`
+	// +optional
+	Rename *string `json:"rename,omitempty"`
+
+	// Description replaces the description of the tool.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Description *string `json:"description,omitempty"`
+
+	// AppendDescription is appended to the description of the tool, separated by a new line.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	AppendDescription *string `json:"appendDescription,omitempty"`
+
+	// FixedArguments are injected into the calls to the tool, replacing the values set by the clients.
+	// The parameters with fixed arguments are removed from the input schema of the tool exposed to the clients.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=64
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	FixedArguments []MCPToolFixedArgument `json:"fixedArguments,omitempty"`
+}
+
+// MCPToolFixedArgument is an argument of a tool call with a fixed value.
+type MCPToolFixedArgument struct {
+	// Name is the name of the parameter of the tool.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Value is the JSON value of the argument.
+	//
+	// +kubebuilder:validation:Required
+	Value apiextensionsv1.JSON `json:"value"`
+}
+
+// MCPToolApproval defines the tools of a backend whose calls must be approved before being forwarded to it.
+//
+// When the client declared the elicitation capability, the gateway sends it an elicitation request showing the
+// name and the arguments of the tool call, and forwards the call only if the user accepts it. Otherwise, the
+// approval is requested from the webhook, if any, and the call is rejected if there is none.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.timeout) || duration(self.timeout) > duration('0s')", message="timeout must be positive"
+type MCPToolApproval struct {
+	// RequiresApproval is the list of the names of the tools, as exposed by the MCP server, whose calls must be
+	// approved.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=128
+	RequiresApproval []string `json:"requiresApproval"`
+
+	// Timeout is the maximum time to wait for the approval, after which the call is rejected.
+	// If not specified, the default is 60s.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Timeout *gwapiv1.Duration `json:"timeout,omitempty"`
+
+	// Webhook is the external service asked to approve the calls of the clients that don't support elicitation.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Webhook *MCPToolApprovalWebhook `json:"webhook,omitempty"`
+}
+
+// MCPToolApprovalWebhook defines an external service approving tool calls.
+//
+// The gateway sends it a POST request with a JSON body containing the "route", "backend", "tool" and "arguments"
+// of the call, along with the "subject" of the client JWT, if any. The call is approved if the service responds
+// with a 2xx status and a JSON body whose "approved" field is true. An optional "reason" field is returned to the
+// client when the call is denied.
+type MCPToolApprovalWebhook struct {
+	// URL is the http or https URL of the webhook.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="isURL(self) && url(self).getScheme() in ['http', 'https']", message="url must be an http or https URL"
+	URL string `json:"url"`
+}
+
+// MCPToolRateLimit limits the number of calls to the tools of a backend per client in a fixed time window.
+//
+// +kubebuilder:validation:XValidation:rule="duration(self.window) > duration('0s')", message="window must be positive"
+type MCPToolRateLimit struct {
+	// Backend is the name of the backendRef whose tools are limited.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Backend string `json:"backend"`
+
+	// Tools are the names of the limited tools, as exposed by the backend before any rename. Each tool is counted
+	// separately. If not specified, the limit applies to each of the tools of the backend.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	Tools []string `json:"tools,omitempty"`
+
+	// Calls is the maximum number of calls to each tool allowed per client in a window.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Calls uint32 `json:"calls"`
+
+	// Window is the duration of the window, for example "1m" or "24h". The counters are reset at the end of
+	// each window, which starts with the first call of the client.
+	//
+	// +kubebuilder:validation:Required
+	Window gwapiv1.Duration `json:"window"`
+
+	// ClientKey identifies the clients that are limited separately. If not specified, the clients are identified
+	// by the subject of their JWT.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ClientKey *MCPToolRateLimitClientKey `json:"clientKey,omitempty"`
+}
+
+// MCPToolRateLimitClientKeyType specifies how the clients of a rate limit are identified.
+//
+// +kubebuilder:validation:Enum=JWTSubject;Header;Session
+type MCPToolRateLimitClientKeyType string
+
+const (
+	// MCPToolRateLimitClientKeyTypeJWTSubject identifies the clients by the "sub" claim of the bearer token
+	// in the Authorization header.
+	MCPToolRateLimitClientKeyTypeJWTSubject MCPToolRateLimitClientKeyType = "JWTSubject"
+	// MCPToolRateLimitClientKeyTypeHeader identifies the clients by the value of a request header.
+	MCPToolRateLimitClientKeyTypeHeader MCPToolRateLimitClientKeyType = "Header"
+	// MCPToolRateLimitClientKeyTypeSession identifies the clients by their MCP session.
+	MCPToolRateLimitClientKeyTypeSession MCPToolRateLimitClientKeyType = "Session"
+)
+
+// MCPToolRateLimitClientKey specifies how the clients of a rate limit are identified. The calls of the clients
+// whose key is missing, for example because they sent no bearer token, share a single counter.
+//
+// +kubebuilder:validation:XValidation:rule="self.type == 'Header' ? has(self.header) : !has(self.header)", message="header must be set if and only if type is Header"
+type MCPToolRateLimitClientKey struct {
+	// Type is the type of the key.
+	//
+	// +kubebuilder:validation:Required
+	Type MCPToolRateLimitClientKeyType `json:"type"`
+
+	// Header is the name of the request header that identifies the clients when type is Header.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Header *string `json:"header,omitempty"`
+}
+
+// MCPToolSearch configures the search of the tools of an MCPRoute.
+//
+// The tools are ranked by the BM25 relevance of their names, descriptions and parameters to the keywords of the
+// query. When embeddings are configured, the ranking is combined with the semantic similarity of the query and the
+// tools.
+type MCPToolSearch struct {
+	// MaxResults is the maximum number of tools returned by a search.
+	// If not specified, the default is 10.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxResults *int32 `json:"maxResults,omitempty"`
+
+	// Embeddings ranks the tools by the semantic similarity of their embeddings to the embedding of the query, in
+	// addition to the keywords. If the embeddings cannot be computed, the tools are ranked by keywords only.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Embeddings *MCPToolSearchEmbeddings `json:"embeddings,omitempty"`
+}
+
+// MCPToolSearchEmbeddings defines the embeddings model used to search the tools.
+type MCPToolSearchEmbeddings struct {
+	// URL is the URL of an OpenAI-compatible embeddings endpoint, such as a route of the gateway to an embeddings
+	// model, e.g. "http://envoy-ai-gateway.example.svc/v1/embeddings".
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="isURL(self) && url(self).getScheme() in ['http', 'https']", message="url must be an http or https URL"
+	URL string `json:"url"`
+
+	// Model is the name of the embeddings model.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Model string `json:"model"`
+}
+
+// MCPBackendTransport is the MCP transport spoken by a backend MCP server.
+//
+// +kubebuilder:validation:Enum=StreamableHTTP;SSE
+type MCPBackendTransport string
+
+const (
+	// MCPBackendTransportStreamableHTTP is the Streamable HTTP transport.
+	MCPBackendTransportStreamableHTTP MCPBackendTransport = "StreamableHTTP"
+	// MCPBackendTransportSSE is the legacy HTTP+SSE transport of the 2024-11-05 MCP specification.
+	MCPBackendTransportSSE MCPBackendTransport = "SSE"
+)
+
+// MCPOpenAPIBackend defines a REST service described by an OpenAPI document exposed as a virtual MCP server.
+//
+// Each selected operation is exposed as a tool named after its operationId. The path, query and header parameters
+// of the operation become the properties of the input schema of the tool, and the request body, if any, becomes the
+// "body" property. The HTTP responses are returned as text content, along with the structured content when the
+// response is a JSON object. Error responses are returned as tool errors.
+type MCPOpenAPIBackend struct {
+	// Document is the OpenAPI 3 document describing the REST service, in JSON or YAML.
+	//
+	// +kubebuilder:validation:Required
+	Document MCPOpenAPIDocument `json:"document"`
+
+	// BasePath is prepended to the paths of the operations. If not specified, the path of the first server URL
+	// of the document is used, if any.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^/[^?#]*$`
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	BasePath *string `json:"basePath,omitempty"`
+
+	// Operations is the list of the operationIds of the operations to expose as tools. If not specified, all the
+	// operations that have an operationId are exposed.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=128
+	// +optional
+	Operations []string `json:"operations,omitempty"`
+}
+
+// MCPOpenAPIDocument is the source of an OpenAPI document.
+//
+// +kubebuilder:validation:XValidation:rule="(has(self.inline) && !has(self.configMapRef)) || (!has(self.inline) && has(self.configMapRef))", message="exactly one of inline or configMapRef must be set"
+type MCPOpenAPIDocument struct {
+	// Inline is the OpenAPI document as a literal string.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=65536
+	// +optional
+	Inline *string `json:"inline,omitempty"`
+
+	// ConfigMapRef references the ConfigMap, in the namespace of the MCPRoute, that holds the OpenAPI document.
+	// The changes to the ConfigMap are only watched when it has the "aigateway.envoyproxy.io/mcp-route-config" label.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ConfigMapRef *MCPOpenAPIConfigMapRef `json:"configMapRef,omitempty"`
+}
+
+// MCPOpenAPIConfigMapRef references a key of a ConfigMap holding an OpenAPI document.
+type MCPOpenAPIConfigMapRef struct {
+	// Name is the name of the ConfigMap.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key of the ConfigMap that holds the document. Defaults to "openapi.yaml".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=openapi.yaml
+	// +optional
+	Key *string `json:"key,omitempty"`
+}
+
 // MCPBackendSecurityPolicy defines the security policy for a backend MCP server.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.apiKey) && has(self.oauthTokenExchange))", message="only one of apiKey or oauthTokenExchange can be set"
 type MCPBackendSecurityPolicy struct {
 	// APIKey is a mechanism to access a backend. The API key will be injected into the request headers.
 	// +optional
 	APIKey *MCPBackendAPIKey `json:"apiKey,omitempty"`
+
+	// OAuthTokenExchange exchanges the token of the user, validated by the OAuth configuration of the MCPRoute,
+	// for a token scoped to this backend. The exchanged token is injected into the "Authorization" header of
+	// the requests sent to the backend, and is cached per user token and backend until it expires.
+	//
+	// This requires the MCPRoute to be configured with the OAuth security policy, so that the incoming
+	// requests carry a validated bearer token.
+	//
+	// +optional
+	OAuthTokenExchange *MCPBackendOAuthTokenExchange `json:"oauthTokenExchange,omitempty"`
+}
+
+// MCPBackendOAuthTokenExchangeGrantType is the OAuth grant used to exchange the user token.
+//
+// +kubebuilder:validation:Enum=TokenExchange;OnBehalfOf
+type MCPBackendOAuthTokenExchangeGrantType string
+
+const (
+	// MCPBackendOAuthTokenExchangeGrantTypeTokenExchange is the OAuth 2.0 Token Exchange grant defined in RFC 8693.
+	MCPBackendOAuthTokenExchangeGrantTypeTokenExchange MCPBackendOAuthTokenExchangeGrantType = "TokenExchange"
+	// MCPBackendOAuthTokenExchangeGrantTypeOnBehalfOf is the on-behalf-of flow, which uses the JWT bearer grant
+	// defined in RFC 7523 with the "requested_token_use=on_behalf_of" parameter, as implemented by Microsoft Entra ID.
+	MCPBackendOAuthTokenExchangeGrantTypeOnBehalfOf MCPBackendOAuthTokenExchangeGrantType = "OnBehalfOf"
+)
+
+// MCPBackendOAuthTokenExchange defines the configuration to exchange the user token for a token of the backend MCP server.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.grantType) || self.grantType != 'OnBehalfOf' || has(self.clientAuth)", message="clientAuth is required for the OnBehalfOf grant type"
+type MCPBackendOAuthTokenExchange struct {
+	// TokenEndpoint is the URL of the token endpoint of the authorization server, or Security Token Service,
+	// that performs the exchange. It must use the https scheme.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="isURL(self) && url(self).getScheme() == 'https'", message="tokenEndpoint must be an https URL"
+	TokenEndpoint string `json:"tokenEndpoint"`
+
+	// GrantType is the OAuth grant used to exchange the user token. Defaults to "TokenExchange".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=TokenExchange
+	// +optional
+	GrantType *MCPBackendOAuthTokenExchangeGrantType `json:"grantType,omitempty"`
+
+	// SubjectTokenType is the type of the user token sent as the "subject_token" parameter.
+	// Only used by the TokenExchange grant type. Defaults to "urn:ietf:params:oauth:token-type:access_token".
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	SubjectTokenType *string `json:"subjectTokenType,omitempty"`
+
+	// RequestedTokenType is the type of the token to issue, sent as the "requested_token_type" parameter.
+	// Only used by the TokenExchange grant type. If not specified, the parameter is not sent.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	RequestedTokenType *string `json:"requestedTokenType,omitempty"`
+
+	// Audience is the logical name of the backend the token is requested for, sent as the "audience" parameter.
+	// Only used by the TokenExchange grant type.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Audience *string `json:"audience,omitempty"`
+
+	// Resource is the URI of the backend the token is requested for, sent as the "resource" parameter.
+	// Only used by the TokenExchange grant type.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Resource *string `json:"resource,omitempty"`
+
+	// Scopes is the list of scopes to request for the exchanged token.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ClientAuth is the client credentials the gateway uses to authenticate to the token endpoint.
+	// If not specified, the exchange request is not authenticated, which is only allowed by the TokenExchange grant type.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ClientAuth *MCPBackendOAuthTokenExchangeClientAuth `json:"clientAuth,omitempty"`
+}
+
+// MCPBackendOAuthTokenExchangeClientAuth defines the client credentials used to authenticate to the token endpoint.
+type MCPBackendOAuthTokenExchangeClientAuth struct {
+	// ClientID is the identifier of the gateway as a client of the authorization server.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// ClientSecretRef is the Kubernetes secret which contains the client secret.
+	// The key of the secret should be "clientSecret".
+	//
+	// +kubebuilder:validation:Required
+	ClientSecretRef gwapiv1.SecretObjectReference `json:"clientSecretRef"`
 }
 
 // MCPBackendAPIKey defines the configuration for the API Key Authentication to a backend.
@@ -364,6 +1038,8 @@ type MCPRouteAuthorizationRule struct {
 	//	* request.mcp.method: MCP method such as tools/list or tools/call. Type: string.
 	//	* request.mcp.backend: upstream backend name (for example, "kiwi" or "github"). Type: string.
 	//	* request.mcp.tool: tool name without backend prefix (for example, "list_issues"). Type: string.
+	//	* request.mcp.resource: resource URI without backend prefix (for example, "schema://users"). Type: string.
+	//	* request.mcp.prompt: prompt name without backend prefix (for example, "summarize"). Type: string.
 	//	* request.mcp.params: parameters of the MCP method, including keys like "_meta" and "arguments". Type: object.
 	//
 	// Note: The CEL expression support is experimental, and the attributes
@@ -382,14 +1058,94 @@ type MCPRouteAuthorizationRule struct {
 	Action *egv1a1.AuthorizationAction `json:"action,omitempty"`
 }
 
-// MCPAuthorizationTarget defines the target of an authorization rule.
+// MCPAuthorizationTarget defines the target of an authorization rule. The rule matches a request when any of the
+// listed tools, resources, prompts or methods matches it.
+//
+// Rules with a target apply to the tool calls, and to the other requests sent to a backend that match the target.
+// A request other than a tool call that matches no rule with a target is allowed regardless of the default action,
+// and rules without a target only apply to the tool calls.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.tools) || has(self.resources) || has(self.prompts) || has(self.methods)", message="at least one of tools, resources, prompts or methods must be specified"
 type MCPAuthorizationTarget struct {
 	// Tools defines the list of tools this rule applies to.
 	//
-	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:MaxItems=16
-	Tools []ToolCall `json:"tools"`
+	// +optional
+	Tools []ToolCall `json:"tools,omitempty"`
+
+	// Resources defines the list of resources this rule applies to. They match the "resources/read",
+	// "resources/subscribe" and "resources/unsubscribe" requests, the "completion/complete" requests
+	// referencing a resource, and the resources returned by "resources/list".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Resources []MCPResourceTarget `json:"resources,omitempty"`
+
+	// Prompts defines the list of prompts this rule applies to. They match the "prompts/get" requests, the
+	// "completion/complete" requests referencing a prompt, and the prompts returned by "prompts/list".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Prompts []MCPPromptTarget `json:"prompts,omitempty"`
+
+	// Methods defines the list of JSON-RPC methods this rule applies to. They match the requests sent to the
+	// backends with the given method, such as "completion/complete" or "resources/list".
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Methods []MCPMethodTarget `json:"methods,omitempty"`
+}
+
+// MCPResourceTarget represents the resources of a backend in the MCP authorization target.
+type MCPResourceTarget struct {
+	// Backend is the name of the backend these resources belong to.
+	//
+	// +kubebuilder:validation:Required
+	Backend string `json:"backend"`
+
+	// URI is the URI of the resources as exposed by the backend, without the backend prefix.
+	// The "*" wildcard matches any sequence of characters, for example "schema://*".
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	URI string `json:"uri"`
+}
+
+// MCPPromptTarget represents a prompt in the MCP authorization target.
+type MCPPromptTarget struct {
+	// Backend is the name of the backend this prompt belongs to.
+	//
+	// +kubebuilder:validation:Required
+	Backend string `json:"backend"`
+
+	// Prompt is the name of the prompt.
+	//
+	// +kubebuilder:validation:Required
+	Prompt string `json:"prompt"`
+}
+
+// MCPMethodTarget represents a JSON-RPC method in the MCP authorization target.
+type MCPMethodTarget struct {
+	// Method is the JSON-RPC method, for example "completion/complete".
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Method string `json:"method"`
+
+	// Backend restricts the target to the requests sent to this backend.
+	// If not specified, the requests sent to any backend match.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Backend *string `json:"backend,omitempty"`
 }
 
 // MCPAuthorizationSource defines the source of an authorization rule.
@@ -121,6 +121,65 @@ type QuotaDefinition struct {
 	//
 	// +optional
 	BucketRules []QuotaRule `json:"bucketRules,omitempty"`
+	// ConcurrencyLimits caps the number of requests of the selected clients that can be in flight
+	// for the model at the same time, including open streaming responses. Unlike the token quotas,
+	// these protect capacity-constrained backends such as self-hosted models from a single client
+	// holding many long-lived streams.
+	//
+	// A request matching multiple limits must be admitted by all of them. Rejected requests get a
+	// response with 429 HTTP status code and a "retry-after" header.
+	//
+	// The limits are enforced by the external processor before the backend is selected, so they are
+	// matched against the model in the request body, or against the models routed to the backend
+	// when the AIGatewayRoute sets a ModelNameOverride.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	ConcurrencyLimits []QuotaConcurrencyLimit `json:"concurrencyLimits,omitempty"`
+}
+
+// QuotaConcurrencyLimit limits the number of concurrent requests of the selected clients.
+type QuotaConcurrencyLimit struct {
+	// ClientSelectors holds the list of conditions to select
+	// specific clients using attributes from the traffic flow.
+	// All individual select conditions must hold True for this limit to be applied.
+	// Only header selectors are supported, as the limits are enforced by the external processor
+	// which does not see the source address of the client.
+	//
+	// A "Distinct" header match gives each distinct header value its own limit.
+	// If no client selectors, JWT claim selectors or CEL selectors are specified,
+	// the limit is shared by all traffic of the model.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:XValidation:rule="self.all(s, !has(s.sourceCIDR) && !has(s.methods) && !has(s.path) && !has(s.queryParams))", message="only header selectors are supported in concurrency limits"
+	ClientSelectors []egv1a1.RateLimitSelectCondition `json:"clientSelectors,omitempty"`
+	// JWTClaimSelectors selects clients by the claims of the JWT verified by the JWT authentication
+	// of the SecurityPolicy attached to the route. See QuotaRule.JWTClaimSelectors for details.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	JWTClaimSelectors []QuotaJWTClaimSelector `json:"jwtClaimSelectors,omitempty"`
+	// CELSelectors selects clients using CEL expressions evaluated over the request attributes.
+	// See QuotaRule.CELSelectors for details.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=4
+	CELSelectors []QuotaCELSelector `json:"celSelectors,omitempty"`
+	// MaxConcurrentRequests is the maximum number of requests of the selected clients that can be
+	// in flight at the same time. A request is counted from the moment it is received until the end of
+	// its response, or until the client disconnects.
+	//
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRequests uint32 `json:"maxConcurrentRequests"`
+	// RetryAfterSeconds is the value of the "retry-after" header of the rejected requests.
+	// Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	RetryAfterSeconds *uint32 `json:"retryAfterSeconds,omitempty"`
 }
 
 // QuotaBucketMode specifies whether the default and per request buckets values are exclusive or inclusive.
@@ -138,14 +197,32 @@ type QuotaRule struct {
 	// specific clients using attributes from the traffic flow.
 	// All individual select conditions must hold True for this rule
 	// and its limit to be applied.
-	// Currently, only header types are honored.
+	// Currently, only header and source CIDR types are honored.
+	// Inverted source CIDR matches are not supported.
 	//
-	// If no client selectors are specified, the rule applies to all traffic of
-	// the targeted AIServiceBackend.
+	// If no client selectors, JWT claim selectors or CEL selectors are specified,
+	// the rule applies to all traffic of the targeted AIServiceBackend.
 	//
 	// +optional
 	// +kubebuilder:validation:MaxItems=8
 	ClientSelectors []egv1a1.RateLimitSelectCondition `json:"clientSelectors,omitempty"`
+	// JWTClaimSelectors selects clients by the claims of the JWT verified by the JWT authentication
+	// of the Envoy Gateway SecurityPolicy attached to the route. When no token was verified, the
+	// claims are missing and the selectors do not match, so that clients cannot select a bucket
+	// with a token that was not verified.
+	//
+	// These are ANDed together with the ClientSelectors and CELSelectors.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	JWTClaimSelectors []QuotaJWTClaimSelector `json:"jwtClaimSelectors,omitempty"`
+	// CELSelectors selects clients using CEL expressions evaluated over the request attributes.
+	//
+	// These are ANDed together with the ClientSelectors and JWTClaimSelectors.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=4
+	CELSelectors []QuotaCELSelector `json:"celSelectors,omitempty"`
 	// Quota value for given client selectors.
 	// This quota is applied for traffic flows when the selectors
 	// compute to True, causing the request to be counted towards the limit.
@@ -161,6 +238,80 @@ type QuotaRule struct {
 	ShadowMode *bool `json:"shadowMode,omitempty"`
 }
 
+// QuotaJWTClaimSelector selects requests based on a claim of the verified JWT.
+type QuotaJWTClaimSelector struct {
+	// Name is the name of the claim. Nested claims can be referenced with a dot-separated
+	// path, for example "realm_access.roles".
+	// When the claim is an array, its string values are joined with "," before matching.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Name string `json:"name"`
+	// Type specifies how to match against the value of the claim.
+	// "Distinct" gives each distinct claim value its own quota bucket.
+	// Defaults to "Exact".
+	//
+	// +optional
+	// +kubebuilder:default=Exact
+	Type *egv1a1.HeaderMatchType `json:"type,omitempty"`
+	// Value of the claim to match against. Required unless Type is "Distinct".
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	Value *string `json:"value,omitempty"`
+	// Invert specifies whether the value match result will be inverted.
+	// Not applicable to "Distinct" matches.
+	//
+	// +optional
+	// +kubebuilder:default=false
+	Invert *bool `json:"invert,omitempty"`
+}
+
+// QuotaCELSelector selects requests using a CEL expression over the request attributes.
+//
+// The expression has access to the "request" variable with the following fields:
+//
+//   - request.method: the HTTP method.
+//   - request.host: the value of the ":authority" header.
+//   - request.path: the original request path.
+//   - request.headers: the request headers keyed by lower-cased name.
+//   - request.model: the model name from the request body.
+//   - request.auth.jwt.claims: the claims of the JWT verified by Envoy, if any.
+//
+// For example:
+//
+//	request.auth.jwt.claims.tier == "free" && request.headers["x-team"] != "sre"
+type QuotaCELSelector struct {
+	// Expression is the CEL expression to evaluate.
+	// It must return a bool for the "Match" type and a string for the "Distinct" type.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=4096
+	Expression string `json:"expression"`
+	// Type specifies how the result of the expression is used.
+	// "Match" applies the rule when the expression returns true.
+	// "Distinct" gives each distinct non-empty string result its own quota bucket.
+	// Defaults to "Match".
+	//
+	// +optional
+	// +kubebuilder:default=Match
+	Type *QuotaCELSelectorType `json:"type,omitempty"`
+}
+
+// QuotaCELSelectorType specifies how the result of a QuotaCELSelector expression is used.
+//
+// +kubebuilder:validation:Enum=Match;Distinct
+type QuotaCELSelectorType string
+
+const (
+	// QuotaCELSelectorTypeMatch applies the rule when the expression returns true.
+	QuotaCELSelectorTypeMatch QuotaCELSelectorType = "Match"
+	// QuotaCELSelectorTypeDistinct gives each distinct string result its own quota bucket.
+	QuotaCELSelectorTypeDistinct QuotaCELSelectorType = "Distinct"
+)
+
 // QuotaValue defines the quota limits using sliding window.
 type QuotaValue struct {
 	// The limit alloted for a specified time window.
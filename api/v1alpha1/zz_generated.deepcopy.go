@@ -76,6 +76,36 @@ func (in *AIGatewayRouteList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRoutePriorityClass) DeepCopyInto(out *AIGatewayRoutePriorityClass) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(PriorityClass)
+		**out = **in
+	}
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(string)
+		**out = **in
+	}
+	if in.JWTClaim != nil {
+		in, out := &in.JWTClaim, &out.JWTClaim
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRoutePriorityClass.
+func (in *AIGatewayRoutePriorityClass) DeepCopy() *AIGatewayRoutePriorityClass {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRoutePriorityClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIGatewayRouteRule) DeepCopyInto(out *AIGatewayRouteRule) {
 	*out = *in
@@ -220,6 +250,11 @@ func (in *AIGatewayRouteSpec) DeepCopyInto(out *AIGatewayRouteSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PriorityClass != nil {
+		in, out := &in.PriorityClass, &out.PriorityClass
+		*out = new(AIGatewayRoutePriorityClass)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteSpec.
@@ -281,6 +316,46 @@ func (in *AIServiceBackend) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIServiceBackendAdmission) DeepCopyInto(out *AIServiceBackendAdmission) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ThrottledPercentThreshold != nil {
+		in, out := &in.ThrottledPercentThreshold, &out.ThrottledPercentThreshold
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.LatencyThreshold != nil {
+		in, out := &in.LatencyThreshold, &out.LatencyThreshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxQueueSize != nil {
+		in, out := &in.MaxQueueSize, &out.MaxQueueSize
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.QueueTimeout != nil {
+		in, out := &in.QueueTimeout, &out.QueueTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIServiceBackendAdmission.
+func (in *AIServiceBackendAdmission) DeepCopy() *AIServiceBackendAdmission {
+	if in == nil {
+		return nil
+	}
+	out := new(AIServiceBackendAdmission)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIServiceBackendList) DeepCopyInto(out *AIServiceBackendList) {
 	*out = *in
@@ -328,6 +403,18 @@ func (in *AIServiceBackendSpec) DeepCopyInto(out *AIServiceBackendSpec) {
 		*out = new(HTTPBodyMutation)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LLMRequestCosts != nil {
+		in, out := &in.LLMRequestCosts, &out.LLMRequestCosts
+		*out = make([]LLMRequestCost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Admission != nil {
+		in, out := &in.Admission, &out.Admission
+		*out = new(AIServiceBackendAdmission)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIServiceBackendSpec.
@@ -1073,6 +1160,23 @@ func (in *MCPAuthorizationTarget) DeepCopyInto(out *MCPAuthorizationTarget) {
 		*out = make([]ToolCall, len(*in))
 		copy(*out, *in)
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]MCPResourceTarget, len(*in))
+		copy(*out, *in)
+	}
+	if in.Prompts != nil {
+		in, out := &in.Prompts, &out.Prompts
+		*out = make([]MCPPromptTarget, len(*in))
+		copy(*out, *in)
+	}
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]MCPMethodTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPAuthorizationTarget.
@@ -1120,6 +1224,72 @@ func (in *MCPBackendAPIKey) DeepCopy() *MCPBackendAPIKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPBackendOAuthTokenExchange) DeepCopyInto(out *MCPBackendOAuthTokenExchange) {
+	*out = *in
+	if in.GrantType != nil {
+		in, out := &in.GrantType, &out.GrantType
+		*out = new(MCPBackendOAuthTokenExchangeGrantType)
+		**out = **in
+	}
+	if in.SubjectTokenType != nil {
+		in, out := &in.SubjectTokenType, &out.SubjectTokenType
+		*out = new(string)
+		**out = **in
+	}
+	if in.RequestedTokenType != nil {
+		in, out := &in.RequestedTokenType, &out.RequestedTokenType
+		*out = new(string)
+		**out = **in
+	}
+	if in.Audience != nil {
+		in, out := &in.Audience, &out.Audience
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resource != nil {
+		in, out := &in.Resource, &out.Resource
+		*out = new(string)
+		**out = **in
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientAuth != nil {
+		in, out := &in.ClientAuth, &out.ClientAuth
+		*out = new(MCPBackendOAuthTokenExchangeClientAuth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPBackendOAuthTokenExchange.
+func (in *MCPBackendOAuthTokenExchange) DeepCopy() *MCPBackendOAuthTokenExchange {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPBackendOAuthTokenExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPBackendOAuthTokenExchangeClientAuth) DeepCopyInto(out *MCPBackendOAuthTokenExchangeClientAuth) {
+	*out = *in
+	in.ClientSecretRef.DeepCopyInto(&out.ClientSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPBackendOAuthTokenExchangeClientAuth.
+func (in *MCPBackendOAuthTokenExchangeClientAuth) DeepCopy() *MCPBackendOAuthTokenExchangeClientAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPBackendOAuthTokenExchangeClientAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPBackendSecurityPolicy) DeepCopyInto(out *MCPBackendSecurityPolicy) {
 	*out = *in
@@ -1128,6 +1298,11 @@ func (in *MCPBackendSecurityPolicy) DeepCopyInto(out *MCPBackendSecurityPolicy)
 		*out = new(MCPBackendAPIKey)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OAuthTokenExchange != nil {
+		in, out := &in.OAuthTokenExchange, &out.OAuthTokenExchange
+		*out = new(MCPBackendOAuthTokenExchange)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPBackendSecurityPolicy.
@@ -1160,6 +1335,197 @@ func (in *MCPHeaderForward) DeepCopy() *MCPHeaderForward {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPMethodTarget) DeepCopyInto(out *MCPMethodTarget) {
+	*out = *in
+	if in.Backend != nil {
+		in, out := &in.Backend, &out.Backend
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPMethodTarget.
+func (in *MCPMethodTarget) DeepCopy() *MCPMethodTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPMethodTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPOpenAPIBackend) DeepCopyInto(out *MCPOpenAPIBackend) {
+	*out = *in
+	in.Document.DeepCopyInto(&out.Document)
+	if in.BasePath != nil {
+		in, out := &in.BasePath, &out.BasePath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPOpenAPIBackend.
+func (in *MCPOpenAPIBackend) DeepCopy() *MCPOpenAPIBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPOpenAPIBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPOpenAPIConfigMapRef) DeepCopyInto(out *MCPOpenAPIConfigMapRef) {
+	*out = *in
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPOpenAPIConfigMapRef.
+func (in *MCPOpenAPIConfigMapRef) DeepCopy() *MCPOpenAPIConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPOpenAPIConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPOpenAPIDocument) DeepCopyInto(out *MCPOpenAPIDocument) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(MCPOpenAPIConfigMapRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPOpenAPIDocument.
+func (in *MCPOpenAPIDocument) DeepCopy() *MCPOpenAPIDocument {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPOpenAPIDocument)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPPromptFilter) DeepCopyInto(out *MCPPromptFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeRegex != nil {
+		in, out := &in.IncludeRegex, &out.IncludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeRegex != nil {
+		in, out := &in.ExcludeRegex, &out.ExcludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPPromptFilter.
+func (in *MCPPromptFilter) DeepCopy() *MCPPromptFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPPromptFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPPromptTarget) DeepCopyInto(out *MCPPromptTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPPromptTarget.
+func (in *MCPPromptTarget) DeepCopy() *MCPPromptTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPPromptTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPResourceFilter) DeepCopyInto(out *MCPResourceFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeRegex != nil {
+		in, out := &in.IncludeRegex, &out.IncludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeRegex != nil {
+		in, out := &in.ExcludeRegex, &out.ExcludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPResourceFilter.
+func (in *MCPResourceFilter) DeepCopy() *MCPResourceFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPResourceFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPResourceTarget) DeepCopyInto(out *MCPResourceTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPResourceTarget.
+func (in *MCPResourceTarget) DeepCopy() *MCPResourceTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPResourceTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPRoute) DeepCopyInto(out *MCPRoute) {
 	*out = *in
@@ -1258,11 +1624,58 @@ func (in *MCPRouteBackendRef) DeepCopyInto(out *MCPRouteBackendRef) {
 		*out = new(string)
 		**out = **in
 	}
-	if in.ToolSelector != nil {
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(MCPBackendTransport)
+		**out = **in
+	}
+	if in.OpenAPI != nil {
+		in, out := &in.OpenAPI, &out.OpenAPI
+		*out = new(MCPOpenAPIBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ToolSelector != nil {
 		in, out := &in.ToolSelector, &out.ToolSelector
 		*out = new(MCPToolFilter)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ResourceSelector != nil {
+		in, out := &in.ResourceSelector, &out.ResourceSelector
+		*out = new(MCPResourceFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PromptSelector != nil {
+		in, out := &in.PromptSelector, &out.PromptSelector
+		*out = new(MCPPromptFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ToolPinning != nil {
+		in, out := &in.ToolPinning, &out.ToolPinning
+		*out = new(MCPToolPinning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SchemaValidation != nil {
+		in, out := &in.SchemaValidation, &out.SchemaValidation
+		*out = new(MCPSchemaValidation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ToolNamePrefix != nil {
+		in, out := &in.ToolNamePrefix, &out.ToolNamePrefix
+		*out = new(MCPToolNamePrefix)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ToolOverrides != nil {
+		in, out := &in.ToolOverrides, &out.ToolOverrides
+		*out = make([]MCPToolOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ToolApproval != nil {
+		in, out := &in.ToolApproval, &out.ToolApproval
+		*out = new(MCPToolApproval)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SecurityPolicy != nil {
 		in, out := &in.SecurityPolicy, &out.SecurityPolicy
 		*out = new(MCPBackendSecurityPolicy)
@@ -1419,6 +1832,18 @@ func (in *MCPRouteSpec) DeepCopyInto(out *MCPRouteSpec) {
 		*out = new(MCPRouteSecurityPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RateLimits != nil {
+		in, out := &in.RateLimits, &out.RateLimits
+		*out = make([]MCPToolRateLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ToolSearch != nil {
+		in, out := &in.ToolSearch, &out.ToolSearch
+		*out = new(MCPToolSearch)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPRouteSpec.
@@ -1453,6 +1878,76 @@ func (in *MCPRouteStatus) DeepCopy() *MCPRouteStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPSchemaValidation) DeepCopyInto(out *MCPSchemaValidation) {
+	*out = *in
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StructuredContent != nil {
+		in, out := &in.StructuredContent, &out.StructuredContent
+		*out = new(MCPStructuredContentValidationAction)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPSchemaValidation.
+func (in *MCPSchemaValidation) DeepCopy() *MCPSchemaValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPSchemaValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolApproval) DeepCopyInto(out *MCPToolApproval) {
+	*out = *in
+	if in.RequiresApproval != nil {
+		in, out := &in.RequiresApproval, &out.RequiresApproval
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(MCPToolApprovalWebhook)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolApproval.
+func (in *MCPToolApproval) DeepCopy() *MCPToolApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolApprovalWebhook) DeepCopyInto(out *MCPToolApprovalWebhook) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolApprovalWebhook.
+func (in *MCPToolApprovalWebhook) DeepCopy() *MCPToolApprovalWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolApprovalWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPToolFilter) DeepCopyInto(out *MCPToolFilter) {
 	*out = *in
@@ -1488,6 +1983,184 @@ func (in *MCPToolFilter) DeepCopy() *MCPToolFilter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolFixedArgument) DeepCopyInto(out *MCPToolFixedArgument) {
+	*out = *in
+	in.Value.DeepCopyInto(&out.Value)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolFixedArgument.
+func (in *MCPToolFixedArgument) DeepCopy() *MCPToolFixedArgument {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolFixedArgument)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolNamePrefix) DeepCopyInto(out *MCPToolNamePrefix) {
+	*out = *in
+	if in.Separator != nil {
+		in, out := &in.Separator, &out.Separator
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolNamePrefix.
+func (in *MCPToolNamePrefix) DeepCopy() *MCPToolNamePrefix {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolNamePrefix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolOverride) DeepCopyInto(out *MCPToolOverride) {
+	*out = *in
+	if in.Rename != nil {
+		in, out := &in.Rename, &out.Rename
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.AppendDescription != nil {
+		in, out := &in.AppendDescription, &out.AppendDescription
+		*out = new(string)
+		**out = **in
+	}
+	if in.FixedArguments != nil {
+		in, out := &in.FixedArguments, &out.FixedArguments
+		*out = make([]MCPToolFixedArgument, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolOverride.
+func (in *MCPToolOverride) DeepCopy() *MCPToolOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolPinning) DeepCopyInto(out *MCPToolPinning) {
+	*out = *in
+	if in.Action != nil {
+		in, out := &in.Action, &out.Action
+		*out = new(MCPToolPinningAction)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolPinning.
+func (in *MCPToolPinning) DeepCopy() *MCPToolPinning {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolPinning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolRateLimit) DeepCopyInto(out *MCPToolRateLimit) {
+	*out = *in
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientKey != nil {
+		in, out := &in.ClientKey, &out.ClientKey
+		*out = new(MCPToolRateLimitClientKey)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolRateLimit.
+func (in *MCPToolRateLimit) DeepCopy() *MCPToolRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolRateLimitClientKey) DeepCopyInto(out *MCPToolRateLimitClientKey) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolRateLimitClientKey.
+func (in *MCPToolRateLimitClientKey) DeepCopy() *MCPToolRateLimitClientKey {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolRateLimitClientKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolSearch) DeepCopyInto(out *MCPToolSearch) {
+	*out = *in
+	if in.MaxResults != nil {
+		in, out := &in.MaxResults, &out.MaxResults
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Embeddings != nil {
+		in, out := &in.Embeddings, &out.Embeddings
+		*out = new(MCPToolSearchEmbeddings)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolSearch.
+func (in *MCPToolSearch) DeepCopy() *MCPToolSearch {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolSearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPToolSearchEmbeddings) DeepCopyInto(out *MCPToolSearchEmbeddings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPToolSearchEmbeddings.
+func (in *MCPToolSearchEmbeddings) DeepCopy() *MCPToolSearchEmbeddings {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPToolSearchEmbeddings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PerModelQuota) DeepCopyInto(out *PerModelQuota) {
 	*out = *in
@@ -1549,6 +2222,67 @@ func (in *ProtectedResourceMetadata) DeepCopy() *ProtectedResourceMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaCELSelector) DeepCopyInto(out *QuotaCELSelector) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(QuotaCELSelectorType)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaCELSelector.
+func (in *QuotaCELSelector) DeepCopy() *QuotaCELSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaCELSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaConcurrencyLimit) DeepCopyInto(out *QuotaConcurrencyLimit) {
+	*out = *in
+	if in.ClientSelectors != nil {
+		in, out := &in.ClientSelectors, &out.ClientSelectors
+		*out = make([]apiv1alpha1.RateLimitSelectCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JWTClaimSelectors != nil {
+		in, out := &in.JWTClaimSelectors, &out.JWTClaimSelectors
+		*out = make([]QuotaJWTClaimSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CELSelectors != nil {
+		in, out := &in.CELSelectors, &out.CELSelectors
+		*out = make([]QuotaCELSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RetryAfterSeconds != nil {
+		in, out := &in.RetryAfterSeconds, &out.RetryAfterSeconds
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaConcurrencyLimit.
+func (in *QuotaConcurrencyLimit) DeepCopy() *QuotaConcurrencyLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaConcurrencyLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QuotaDefinition) DeepCopyInto(out *QuotaDefinition) {
 	*out = *in
@@ -1565,6 +2299,13 @@ func (in *QuotaDefinition) DeepCopyInto(out *QuotaDefinition) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConcurrencyLimits != nil {
+		in, out := &in.ConcurrencyLimits, &out.ConcurrencyLimits
+		*out = make([]QuotaConcurrencyLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaDefinition.
@@ -1577,6 +2318,36 @@ func (in *QuotaDefinition) DeepCopy() *QuotaDefinition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaJWTClaimSelector) DeepCopyInto(out *QuotaJWTClaimSelector) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(apiv1alpha1.HeaderMatchType)
+		**out = **in
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+	if in.Invert != nil {
+		in, out := &in.Invert, &out.Invert
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaJWTClaimSelector.
+func (in *QuotaJWTClaimSelector) DeepCopy() *QuotaJWTClaimSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaJWTClaimSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QuotaPolicy) DeepCopyInto(out *QuotaPolicy) {
 	*out = *in
@@ -1696,6 +2467,20 @@ func (in *QuotaRule) DeepCopyInto(out *QuotaRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.JWTClaimSelectors != nil {
+		in, out := &in.JWTClaimSelectors, &out.JWTClaimSelectors
+		*out = make([]QuotaJWTClaimSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CELSelectors != nil {
+		in, out := &in.CELSelectors, &out.CELSelectors
+		*out = make([]QuotaCELSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.Quota = in.Quota
 	if in.ShadowMode != nil {
 		in, out := &in.ShadowMode, &out.ShadowMode
@@ -101,7 +101,8 @@ type AIGatewayRouteSpec struct {
 	// metadata per HTTP request. The namespaced key is "io.envoy.ai_gateway".
 	//
 	// These route-level costs override any global defaults defined in GatewayConfig.Spec.GlobalLLMRequestCosts
-	// for the same metadataKey. If a metadataKey is not defined in either place, no cost is calculated for it.
+	// for the same metadataKey, and are themselves overridden by AIServiceBackendSpec.LLMRequestCosts of the backend
+	// serving the request. If a metadataKey is not defined in any place, no cost is calculated for it.
 	//
 	// This allows you to define common cost formulas once at the gateway level (e.g., via GatewayConfig)
 	// and only override them in specific routes when needed (e.g., premium routes with different pricing).
@@ -205,8 +206,66 @@ type AIGatewayRouteSpec struct {
 	// +optional
 	// +kubebuilder:validation:MaxItems=36
 	LLMRequestCosts []LLMRequestCost `json:"llmRequestCosts,omitempty"`
+
+	// PriorityClass assigns a priority class to the requests served by this route.
+	//
+	// The priority class is used by the admission control of the referenced AIServiceBackends
+	// to decide which requests are queued or shed first when a backend is under pressure.
+	// See AIServiceBackendSpec.Admission for details. When not set, all the requests of this
+	// route are of the "Normal" priority class.
+	//
+	// +optional
+	PriorityClass *AIGatewayRoutePriorityClass `json:"priorityClass,omitempty"`
+}
+
+// AIGatewayRoutePriorityClass specifies how the priority class of a request is determined.
+//
+// The priority class is taken from the JWT claim if set and valid, then from the header
+// if set and valid, and finally falls back to the default.
+type AIGatewayRoutePriorityClass struct {
+	// Default is the priority class of the requests that do not carry a valid priority class
+	// in the header or the JWT claim.
+	//
+	// +optional
+	// +kubebuilder:default=Normal
+	Default *PriorityClass `json:"default,omitempty"`
+
+	// Header is the name of the request header carrying the priority class, e.g. "x-priority-class".
+	// The value is matched case-insensitively against "High", "Normal" and "Low". Other values are ignored.
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Header *string `json:"header,omitempty"`
+
+	// JWTClaim is the name of the JWT claim carrying the priority class. Nested claims are
+	// specified with a dot-separated path, e.g. "tier.priority". The value is matched the same
+	// way as the header value.
+	//
+	// The claim is taken from the JWT verified by the JWT authentication of a SecurityPolicy
+	// attached to the route. When no token was verified, the claim is ignored, so that clients
+	// cannot choose their priority class with a token that was not verified.
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	JWTClaim *string `json:"jwtClaim,omitempty"`
 }
 
+// PriorityClass is the priority class of a request used by the admission control of the AIServiceBackend.
+//
+// +kubebuilder:validation:Enum=High;Normal;Low
+type PriorityClass string
+
+const (
+	// PriorityClassHigh is the priority class for latency-sensitive traffic, such as interactive requests.
+	// Requests of this class are never queued nor shed by the admission control.
+	PriorityClassHigh PriorityClass = "High"
+	// PriorityClassNormal is the default priority class.
+	PriorityClassNormal PriorityClass = "Normal"
+	// PriorityClassLow is the priority class for traffic that tolerates delays, such as batch requests.
+	// Requests of this class are the first to be queued and shed by the admission control.
+	PriorityClassLow PriorityClass = "Low"
+)
+
 // AIGatewayRouteRule is a rule that defines the routing behavior of the AIGatewayRoute.
 //
 // +kubebuilder:validation:XValidation:rule="!has(self.backendRefs) || size(self.backendRefs) == 0 || (self.backendRefs.all(ref, !has(ref.group) && !has(ref.kind)) || self.backendRefs.all(ref, has(ref.group) && has(ref.kind)))", message="cannot mix InferencePool and AIServiceBackend references in the same rule"
@@ -76,6 +76,78 @@ type AIServiceBackendSpec struct {
 	// +optional
 	BodyMutation *HTTPBodyMutation `json:"bodyMutation,omitempty"`
 
-	// TODO: maybe add backend-level LLMRequestCost configuration that overrides the AIGatewayRoute-level LLMRequestCost.
-	// 	That may be useful for the backend that has a different cost calculation logic.
+	// LLMRequestCosts specifies how to capture the cost of the LLM-related requests served by this backend.
+	// See AIGatewayRouteSpec.LLMRequestCosts for how the costs are stored in the dynamic metadata.
+	//
+	// These backend-level costs take precedence over the AIGatewayRoute-level LLMRequestCosts, which in turn
+	// take precedence over GatewayConfig.Spec.GlobalLLMRequestCosts, for the same metadataKey.
+	// This is useful when the same model is priced differently depending on the provider serving it,
+	// for example, a provisioned deployment versus an on-demand one, while the route defines the rest.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=36
+	LLMRequestCosts []LLMRequestCost `json:"llmRequestCosts,omitempty"`
+
+	// Admission configures the priority based admission control of the requests sent to this backend.
+	//
+	// This is useful when a backend with a limited capacity, for example an Azure OpenAI deployment
+	// with a tokens-per-minute limit, is shared by interactive and batch traffic. The external processor
+	// tracks the recent 429 responses and latencies of this backend, and when they exceed the configured
+	// thresholds, requests of the "Low" priority class and then of the "Normal" priority class are queued
+	// until the pressure decreases. Queued requests are shed with a 503 response when the queue is full
+	// or when they have waited longer than the queue timeout. Requests of the "High" priority class are
+	// never queued. The priority class of a request is configured by AIGatewayRouteSpec.PriorityClass.
+	//
+	// The signals are tracked per AIServiceBackend across all the routes referencing it, and per
+	// external processor instance.
+	//
+	// +optional
+	Admission *AIServiceBackendAdmission `json:"admission,omitempty"`
+}
+
+// AIServiceBackendAdmission configures the admission control of an AIServiceBackend.
+//
+// The pressure of the backend is the highest of the ratio of the percentage of throttled (429) responses
+// to ThrottledPercentThreshold, and the ratio of the average latency to LatencyThreshold, over the window.
+// When the pressure reaches 1, "Low" requests are queued. When it reaches 2, "Normal" requests are queued too.
+type AIServiceBackendAdmission struct {
+	// Window is the duration over which the 429 responses and latencies of the backend are tracked.
+	// Defaults to 30s.
+	//
+	// +optional
+	// +kubebuilder:default="30s"
+	Window *gwapiv1.Duration `json:"window,omitempty"`
+
+	// ThrottledPercentThreshold is the percentage of 429 responses over the window at which the
+	// backend is considered under pressure. Defaults to 5.
+	//
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	ThrottledPercentThreshold *uint32 `json:"throttledPercentThreshold,omitempty"`
+
+	// LatencyThreshold is the average latency of the response headers over the window at which
+	// the backend is considered under pressure. When not set, latencies are not taken into account.
+	//
+	// +optional
+	LatencyThreshold *gwapiv1.Duration `json:"latencyThreshold,omitempty"`
+
+	// MaxQueueSize is the maximum number of requests waiting for admission to this backend.
+	// When the queue is full, a request of a higher priority class evicts the most recently queued
+	// request of the lowest priority class, otherwise it is shed. Zero disables queueing, so that
+	// requests are shed right away. Defaults to 100.
+	//
+	// +optional
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Maximum=10000
+	MaxQueueSize *uint32 `json:"maxQueueSize,omitempty"`
+
+	// QueueTimeout is the maximum duration a request waits for admission before being shed.
+	// This must be lower than the 10s message timeout of the external processor. Defaults to 2s.
+	//
+	// +optional
+	// +kubebuilder:default="2s"
+	// +kubebuilder:validation:XValidation:rule="duration(self) < duration('10s')",message="queueTimeout must be less than 10s"
+	QueueTimeout *gwapiv1.Duration `json:"queueTimeout,omitempty"`
 }
@@ -0,0 +1,149 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+// chatMessage is a message of the conversation of `aigw chat`.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCmd implements the `aigw chat` command. It sends each line read from the standard input to the chat completions
+// endpoint of the gateway, with the previous messages of the conversation, and streams the replies to stdout.
+//
+// The "/reset" line starts a new conversation, and "/exit" or the end of the input ends the command.
+func chatCmd(ctx context.Context, c *cmdChat, stdout, stderr io.Writer) error {
+	var system []chatMessage
+	if c.System != "" {
+		system = append(system, chatMessage{Role: "system", Content: c.System})
+	}
+	messages := system
+	endpoint := strings.TrimSuffix(c.URL, "/") + "/v1/chat/completions"
+	_, _ = fmt.Fprintf(stderr, "Chatting with %s through %s. Type /reset to start a new conversation, /exit to quit.\n",
+		c.Model, c.URL)
+
+	scanner := bufio.NewScanner(c.stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for ctx.Err() == nil {
+		_, _ = fmt.Fprint(stdout, "> ")
+		if !scanner.Scan() {
+			_, _ = fmt.Fprintln(stdout)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return nil
+		case "/reset":
+			messages = system
+			continue
+		}
+
+		messages = append(messages, chatMessage{Role: "user", Content: line})
+		reply, err := streamChatCompletion(ctx, endpoint, c.Model, messages, stdout, stderr)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			// Forget the message, so that it can be sent again.
+			messages = messages[:len(messages)-1]
+			continue
+		}
+		messages = append(messages, chatMessage{Role: "assistant", Content: reply})
+	}
+	return nil
+}
+
+// streamChatCompletion sends a streaming chat completion request with the given messages, writes the content of the
+// reply to stdout as it is received, and returns it. The response model, token usage and latency are written to
+// stderr.
+func streamChatCompletion(ctx context.Context, endpoint, model string, messages []chatMessage, stdout, stderr io.Writer) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":          model,
+		"messages":       messages,
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var (
+		reply         strings.Builder
+		responseModel string
+		usage         *openai.Usage
+	)
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, readErr := reader.ReadString('\n')
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+		data = strings.TrimSpace(data)
+		if ok && data == "[DONE]" {
+			break
+		}
+		if ok && data != "" {
+			var chunk openai.ChatCompletionResponseChunk
+			if err = json.Unmarshal([]byte(data), &chunk); err != nil {
+				return "", fmt.Errorf("failed to unmarshal the chunk %q: %w", data, err)
+			}
+			if chunk.Model != "" {
+				responseModel = chunk.Model
+			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Delta != nil && choice.Delta.Content != nil {
+					reply.WriteString(*choice.Delta.Content)
+					_, _ = fmt.Fprint(stdout, *choice.Delta.Content)
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return "", fmt.Errorf("failed to read the response: %w", readErr)
+		}
+	}
+	_, _ = fmt.Fprintln(stdout)
+
+	stats := []string{responseModel, time.Since(start).Round(time.Millisecond).String()}
+	if usage != nil {
+		stats = append(stats, fmt.Sprintf("%d input tokens", usage.PromptTokens), fmt.Sprintf("%d output tokens", usage.CompletionTokens))
+	}
+	_, _ = fmt.Fprintf(stderr, "[%s]\n", strings.Join(stats, ", "))
+	return reply.String(), nil
+}
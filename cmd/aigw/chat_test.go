@@ -0,0 +1,84 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+func TestChatCmd(t *testing.T) {
+	var requests [][]chatMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		var req struct {
+			Model    string        `json:"model"`
+			Messages []chatMessage `json:"messages"`
+			Stream   bool          `json:"stream"`
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &req))
+		require.Equal(t, "qwen3:0.6b", req.Model)
+		require.True(t, req.Stream)
+		requests = append(requests, req.Messages)
+
+		last := req.Messages[len(req.Messages)-1].Content
+		if last == "fail" {
+			http.Error(w, `{"error":{"message":"upstream unavailable"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, word := range strings.Fields("you said " + last) {
+			_, _ = fmt.Fprintf(w, "data: {\"model\":\"qwen3:0.6b\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", word+" ")
+		}
+		_, _ = fmt.Fprint(w, "data: {\"model\":\"qwen3:0.6b\",\"choices\":[],\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":3}}\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	stdin := strings.NewReader("hello\n\nfail\nagain\n/reset\nfresh\n/exit\nignored\n")
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	err := chatCmd(t.Context(), &cmdChat{Model: "qwen3:0.6b", URL: server.URL + "/", System: "Be brief.", stdin: stdin}, stdout, stderr)
+	require.NoError(t, err)
+
+	require.Equal(t, "> you said hello \n> > > you said again \n> > you said fresh \n> ", stdout.String())
+	// The latencies vary between runs.
+	errOut := regexp.MustCompile(`, [0-9.]+[µm]?s,`).ReplaceAllString(stderr.String(), ", 1ms,")
+	require.Equal(t, fmt.Sprintf(`Chatting with qwen3:0.6b through %s/. Type /reset to start a new conversation, /exit to quit.
+[qwen3:0.6b, 1ms, 10 input tokens, 3 output tokens]
+Error: unexpected status code 503: {"error":{"message":"upstream unavailable"}}
+[qwen3:0.6b, 1ms, 10 input tokens, 3 output tokens]
+[qwen3:0.6b, 1ms, 10 input tokens, 3 output tokens]
+`, server.URL), errOut)
+
+	system := chatMessage{Role: "system", Content: "Be brief."}
+	require.Equal(t, [][]chatMessage{
+		{system, {Role: "user", Content: "hello"}},
+		{system, {Role: "user", Content: "hello"}, {Role: "assistant", Content: "you said hello "}, {Role: "user", Content: "fail"}},
+		// The failed message is not part of the conversation.
+		{system, {Role: "user", Content: "hello"}, {Role: "assistant", Content: "you said hello "}, {Role: "user", Content: "again"}},
+		// The conversation is reset.
+		{system, {Role: "user", Content: "fresh"}},
+	}, requests)
+}
+
+func TestChatCmd_endOfInput(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := chatCmd(t.Context(), &cmdChat{Model: "qwen3:0.6b", URL: defaultGatewayURL, stdin: strings.NewReader("")}, stdout, io.Discard)
+	require.NoError(t, err)
+	require.Equal(t, "> \n", stdout.String())
+}
@@ -6,8 +6,10 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -16,12 +18,27 @@ import (
 	"github.com/a8m/envsubst"
 
 	"github.com/envoyproxy/ai-gateway/internal/autoconfig"
+	"github.com/envoyproxy/ai-gateway/internal/json"
 )
 
+// errNoConfig is returned when neither a configuration file nor the credentials of a provider are given.
+var errNoConfig = errors.New("you must supply at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY, " +
+	"AWS_PROFILE, AWS_ACCESS_KEY_ID, GOOGLE_APPLICATION_CREDENTIALS, or a config file path")
+
+// envConfigured returns true if the credentials of at least one provider are set in the environment, so that the
+// configuration can be generated from it.
+func envConfigured() bool {
+	return os.Getenv("OPENAI_API_KEY") != "" || os.Getenv("AZURE_OPENAI_API_KEY") != "" ||
+		os.Getenv("ANTHROPIC_API_KEY") != "" || autoconfig.AWSBedrockEnvConfigured() || autoconfig.GCPEnvConfigured()
+}
+
 // readConfig returns the configuration as a string from the given path,
-// substituting environment variables. If OPENAI_API_KEY or AZURE_OPENAI_API_KEY
-// is set, it generates the config from environment variables. Otherwise, it returns an error.
-func readConfig(path string, mcpServers *autoconfig.MCPServers, debug bool) (string, error) {
+// substituting environment variables. If the credentials of any provider are set,
+// it generates the config from environment variables. Otherwise, it returns an error.
+//
+// When models is not nil, the generated config routes the models discovered from the backends by their names.
+// The providers whose environment is incomplete, but that can be skipped, are reported to stderr.
+func readConfig(ctx context.Context, path string, mcpServers *autoconfig.MCPServers, debug bool, models *modelDiscovery, stderr io.Writer) (string, error) {
 	// If a file path is provided, prefer it.
 	if path != "" {
 		configBytes, err := envsubst.ReadFile(path)
@@ -42,21 +59,44 @@ func readConfig(path string, mcpServers *autoconfig.MCPServers, debug bool) (str
 		}
 	}
 
-	// Add OpenAI config from ENV if available (takes precedence over Anthropic)
+	// Add the config of each provider configured in ENV. When several are, the models are routed by their names.
 	if os.Getenv("OPENAI_API_KEY") != "" || os.Getenv("AZURE_OPENAI_API_KEY") != "" {
 		if err := autoconfig.PopulateOpenAIEnvConfig(&data); err != nil {
 			return "", err
 		}
-	} else if os.Getenv("ANTHROPIC_API_KEY") != "" {
-		// Add Anthropic config from ENV if available (only when OpenAI is not configured)
+	}
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
 		if err := autoconfig.PopulateAnthropicEnvConfig(&data); err != nil {
 			return "", err
 		}
 	}
+	// AWS_PROFILE is often set for other tools, so AWS Bedrock is skipped when its region is not set rather than
+	// failing the other providers.
+	var skipped error
+	if autoconfig.AWSBedrockEnvConfigured() {
+		if err := autoconfig.PopulateAWSBedrockEnvConfig(&data); errors.Is(err, autoconfig.ErrAWSRegionNotSet) {
+			skipped = err
+			_, _ = fmt.Fprintf(stderr, "Skipping AWS Bedrock: %v\n", err)
+		} else if err != nil {
+			return "", err
+		}
+	}
+	if autoconfig.GCPEnvConfigured() {
+		if err := autoconfig.PopulateGCPEnvConfig(&data); err != nil {
+			return "", err
+		}
+	}
 
 	// If we've found no config data, return an error.
 	if reflect.DeepEqual(data, autoconfig.ConfigData{Debug: debug, EnvoyVersion: os.Getenv("ENVOY_VERSION")}) {
-		return "", errors.New("you must supply at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY, or a config file path")
+		if skipped != nil {
+			return "", skipped
+		}
+		return "", errNoConfig
+	}
+
+	if models != nil {
+		models.populate(ctx, &data)
 	}
 
 	// Otel access logging is handled by Envoy directly where supported.
@@ -72,6 +112,26 @@ func readConfig(path string, mcpServers *autoconfig.MCPServers, debug bool) (str
 	return envsubst.String(config)
 }
 
+// readMCPServers returns the MCP servers configuration read from the given file path, or parsed from the given JSON
+// string when the path is empty. It returns nil when neither is set.
+func readMCPServers(path, mcpJSON string) (*autoconfig.MCPServers, error) {
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MCP config file: %w", err)
+		}
+		mcpJSON = string(raw)
+	}
+	if mcpJSON == "" {
+		return nil, nil
+	}
+	var mcpServers autoconfig.MCPServers
+	if err := json.Unmarshal([]byte(mcpJSON), &mcpServers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal MCP config: %w", err)
+	}
+	return &mcpServers, nil
+}
+
 // expandPath expands environment variables and tilde in paths, then converts to absolute path.
 // Returns empty string if input is empty.
 // Replaces ~/  with ${HOME}/ before expanding environment variables.
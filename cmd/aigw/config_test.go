@@ -6,6 +6,8 @@
 package main
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -79,14 +81,40 @@ func TestReadConfig(t *testing.T) {
 			expectPort:      "443",
 		},
 		{
-			name: "OpenAI takes precedence when both are set",
+			name: "generates config for both OpenAI and Anthropic",
 			envVars: map[string]string{
 				"OPENAI_API_KEY":    "test-key",
 				"ANTHROPIC_API_KEY": "sk-ant-test123",
 			},
-			expectHostnames: []string{"api.openai.com"},
+			expectHostnames: []string{"api.openai.com", "api.anthropic.com"},
 			expectPort:      "443",
 		},
+		{
+			name: "generates config from AWS env vars",
+			envVars: map[string]string{
+				"AWS_PROFILE": "dev",
+				"AWS_REGION":  "us-west-2",
+			},
+			expectHostnames: []string{"bedrock-runtime.us-west-2.amazonaws.com"},
+			expectPort:      "443",
+		},
+		{
+			name: "generates config from GCP env vars",
+			envVars: map[string]string{
+				"GOOGLE_APPLICATION_CREDENTIALS": "/tmp/adc.json",
+				"GOOGLE_CLOUD_PROJECT":           "my-project",
+				"GOOGLE_CLOUD_LOCATION":          "us-east5",
+			},
+			expectHostnames: []string{"us-east5-aiplatform.googleapis.com"},
+			expectPort:      "443",
+		},
+		{
+			name: "error when AWS region is missing",
+			envVars: map[string]string{
+				"AWS_ACCESS_KEY_ID": "AKIAEXAMPLE",
+			},
+			expectError: "AWS_REGION environment variable is required",
+		},
 		{
 			name: "configures OTEL access logs when OTLP endpoint is set",
 			envVars: map[string]string{
@@ -104,7 +132,7 @@ func TestReadConfig(t *testing.T) {
 				t.Setenv(k, v)
 			}
 
-			config, err := readConfig(tt.path, tt.mcpServers, false)
+			config, err := readConfig(t.Context(), tt.path, tt.mcpServers, false, nil, io.Discard)
 			if tt.expectError != "" {
 				require.Error(t, err)
 				require.Contains(t, err.Error(), tt.expectError)
@@ -118,19 +146,64 @@ func TestReadConfig(t *testing.T) {
 		})
 	}
 
+	t.Run("skips AWS when region is missing", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "test-key")
+		t.Setenv("AWS_PROFILE", "dev")
+		var stderr bytes.Buffer
+		config, err := readConfig(t.Context(), "", nil, false, nil, &stderr)
+		require.NoError(t, err)
+		require.Contains(t, config, "hostname: api.openai.com")
+		require.NotContains(t, config, "amazonaws.com")
+		require.Equal(t, "Skipping AWS Bedrock: AWS_REGION environment variable is required\n", stderr.String())
+	})
+
 	t.Run("error when file and no OPENAI_API_KEY", func(t *testing.T) {
-		_, err := readConfig("", nil, false)
+		_, err := readConfig(t.Context(), "", nil, false, nil, io.Discard)
 		require.Error(t, err)
-		require.EqualError(t, err, "you must supply at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY, or a config file path")
+		require.Equal(t, errNoConfig, err)
 	})
 
 	t.Run("error when file does not exist", func(t *testing.T) {
-		_, err := readConfig("/non/existent/file.yaml", nil, false)
+		_, err := readConfig(t.Context(), "/non/existent/file.yaml", nil, false, nil, io.Discard)
 		require.Error(t, err)
 		require.EqualError(t, err, "error reading config: open /non/existent/file.yaml: no such file or directory")
 	})
 }
 
+func TestReadMCPServers(t *testing.T) {
+	const mcpJSON = `{"mcpServers":{"dreamtap":{"type":"http","url":"https://dreamtap.xyz/mcp"}}}`
+
+	t.Run("none", func(t *testing.T) {
+		mcpServers, err := readMCPServers("", "")
+		require.NoError(t, err)
+		require.Nil(t, mcpServers)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		mcpServers, err := readMCPServers("", mcpJSON)
+		require.NoError(t, err)
+		require.Equal(t, testMcpServers, mcpServers)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mcp.json")
+		require.NoError(t, os.WriteFile(path, []byte(mcpJSON), 0o600))
+		mcpServers, err := readMCPServers(path, "")
+		require.NoError(t, err)
+		require.Equal(t, testMcpServers, mcpServers)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readMCPServers("/non/existent/mcp.json", "")
+		require.EqualError(t, err, "failed to read MCP config file: open /non/existent/mcp.json: no such file or directory")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := readMCPServers("", "{")
+		require.ErrorContains(t, err, "failed to unmarshal MCP config")
+	})
+}
+
 func TestExpandPath(t *testing.T) {
 	internaltesting.ClearTestEnv(t)
 	homeDir, err := os.UserHomeDir()
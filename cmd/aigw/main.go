@@ -18,7 +18,6 @@ import (
 
 	"github.com/envoyproxy/ai-gateway/cmd/extproc/mainlib"
 	"github.com/envoyproxy/ai-gateway/internal/autoconfig"
-	"github.com/envoyproxy/ai-gateway/internal/json"
 	"github.com/envoyproxy/ai-gateway/internal/version"
 	"github.com/envoyproxy/ai-gateway/internal/xdg"
 )
@@ -40,11 +39,19 @@ type (
 		Healthcheck cmdHealthcheck `cmd:"" help:"Docker HEALTHCHECK command."`
 		// DownloadEnvoy downloads the Envoy binary used by Envoy Gateway.
 		DownloadEnvoy cmdDownloadEnvoy `cmd:"" help:"Download Envoy binary for the Envoy Gateway default version."`
+		// MCPFingerprint is the sub-command to generate the approved tool fingerprints of an MCP server.
+		MCPFingerprint cmdMCPFingerprint `cmd:"" name:"mcp-fingerprint" help:"Generate the ConfigMap with the approved tool fingerprints of an MCP server."`
+		// Translate is the sub-command to translate the AI Gateway resources to Envoy Gateway resources.
+		Translate cmdTranslate `cmd:"" help:"Translate AI Gateway resources to Envoy Gateway and Kubernetes resources."`
+		// Test is the sub-command to smoke-test the routes of a running gateway.
+		Test cmdTest `cmd:"" help:"Send a request for each model routed by the configuration to the running AI Gateway."`
+		// Chat is the sub-command to chat with a model through a running gateway.
+		Chat cmdChat `cmd:"" help:"Chat with a model through the running AI Gateway."`
 	}
 	// cmdRun corresponds to `aigw run` command.
 	cmdRun struct {
 		Debug     bool   `env:"AIGW_DEBUG" help:"Enable debug logging emitted to stderr."`
-		Path      string `arg:"" name:"path" optional:"" help:"Path to the AI Gateway configuration yaml file. Defaults to $AIGW_CONFIG_HOME/config.yaml if exists, otherwise optional when at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY or ANTHROPIC_API_KEY is set." type:"path"`
+		Path      string `arg:"" name:"path" optional:"" help:"Path to the AI Gateway configuration yaml file. Defaults to $AIGW_CONFIG_HOME/config.yaml if exists, otherwise optional when at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY, AWS_PROFILE, AWS_ACCESS_KEY_ID or GOOGLE_APPLICATION_CREDENTIALS is set." type:"path"`
 		AdminPort int    `help:"HTTP port for the admin server (serves /metrics and /health endpoints)." default:"1064"`
 		McpConfig string `name:"mcp-config" help:"Path to MCP servers configuration file." type:"path"`
 		McpJSON   string `name:"mcp-json" help:"JSON string of MCP servers configuration."`
@@ -52,6 +59,12 @@ type (
 
 		MCPSessionEncryptionIterations int `name:"mcp-session-encryption-iterations" help:"Number of iterations for MCP session encryption key derivation." default:"100000"`
 
+		ModelDiscoveryInterval time.Duration `name:"model-discovery-interval" help:"Interval at which the models of the OpenAI compatible and Anthropic backends are listed, when the configuration is generated from the environment variables. Zero disables the model discovery." default:"5m"`
+
+		Record       string `name:"record" help:"Directory where each request sent to the AI backends and its response are recorded, in the go-vcr cassette format." type:"path"`
+		Replay       string `name:"replay" help:"Directory of the recordings of --record. The recorded responses are served instead of sending the requests to the AI backends." type:"path"`
+		ReplayTiming bool   `name:"replay-timing" help:"Preserve the recorded delays of the response chunks, such as the streamed events, when replaying."`
+
 		mcpConfig *autoconfig.MCPServers `kong:"-"` // Internal field: normalized MCP JSON data
 		dirs      *xdg.Directories       `kong:"-"` // Internal field: XDG directories, set by BeforeApply
 		runOpts   *runOpts               `kong:"-"` // Internal field: run options, set by Validate
@@ -62,6 +75,40 @@ type (
 	cmdDownloadEnvoy struct {
 		dataHome string `kong:"-"`
 	}
+	// cmdMCPFingerprint corresponds to `aigw mcp-fingerprint` command.
+	cmdMCPFingerprint struct {
+		URL       string   `arg:"" name:"url" help:"URL of the Streamable HTTP MCP server."`
+		Headers   []string `name:"header" short:"H" help:"Header to send to the MCP server in the form 'Name: value'. Can be repeated."`
+		Name      string   `help:"Name of the generated ConfigMap." default:"mcp-tool-fingerprints"`
+		Namespace string   `help:"Namespace of the generated ConfigMap."`
+	}
+	// cmdTranslate corresponds to `aigw translate` command.
+	cmdTranslate struct {
+		Paths    []string `arg:"" name:"path" optional:"" help:"Paths to the files with the AI Gateway resources. Reads the standard input if omitted or '-'."`
+		Validate bool     `help:"Fail if the reconciliation of an AI Gateway resource reports an error in its status conditions."`
+		Output   string   `short:"o" enum:"yaml,json" default:"yaml" help:"Output format: yaml or json."`
+		Diff     string   `name:"diff" help:"Path to a previous output of the command. Prints the changes of the translated resources instead of the resources." type:"path"`
+
+		stdin io.Reader `kong:"-"` // Internal field: standard input, set by main
+	}
+	// cmdTest corresponds to `aigw test` command.
+	cmdTest struct {
+		Path    string        `arg:"" name:"path" optional:"" help:"Path to the AI Gateway configuration yaml file of the running gateway. Defaults to $AIGW_CONFIG_HOME/config.yaml if exists, otherwise generated from the same environment variables as 'aigw run'." type:"path"`
+		URL     string        `name:"url" help:"URL of the running AI Gateway. Defaults to the HTTP listener of the Gateway of the configuration, or http://localhost:1975."`
+		Models  []string      `name:"model" help:"Model to test in addition to the ones matched by their exact names, through the first rule matching it. Can be repeated."`
+		Timeout time.Duration `help:"Timeout of each request." default:"60s"`
+		Output  string        `short:"o" enum:"table,json" default:"table" help:"Output format: table or json."`
+
+		dirs *xdg.Directories `kong:"-"` // Internal field: XDG directories, set by BeforeApply
+	}
+	// cmdChat corresponds to `aigw chat` command.
+	cmdChat struct {
+		Model  string `required:"" help:"Model to chat with."`
+		URL    string `name:"url" default:"http://localhost:1975" help:"URL of the running AI Gateway."`
+		System string `help:"System prompt of the conversation."`
+
+		stdin io.Reader `kong:"-"` // Internal field: standard input, set by main
+	}
 )
 
 // BeforeApply is called by Kong before applying defaults to set XDG directory defaults.
@@ -95,6 +142,7 @@ func (c *cmd) BeforeApply(_ *kong.Context) error {
 		StateHome:  c.StateHome,
 		RuntimeDir: c.RuntimeDir,
 	}
+	c.Test.dirs = c.Run.dirs
 	// Populate DownloadEnvoy dataHome with expanded data directory.
 	c.DownloadEnvoy.dataHome = c.DataHome
 
@@ -126,30 +174,22 @@ func (c *cmdRun) Validate() error {
 	if c.McpConfig != "" && c.McpJSON != "" {
 		return fmt.Errorf("mcp-config and mcp-json are mutually exclusive")
 	}
-	if c.Path == "" && os.Getenv("OPENAI_API_KEY") == "" && os.Getenv("AZURE_OPENAI_API_KEY") == "" && os.Getenv("ANTHROPIC_API_KEY") == "" && c.McpConfig == "" && c.McpJSON == "" {
-		return fmt.Errorf("you must supply at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY, or a config file path")
+	if c.Record != "" && c.Replay != "" {
+		return fmt.Errorf("record and replay are mutually exclusive")
+	}
+	if c.Path == "" && !envConfigured() && c.McpConfig == "" && c.McpJSON == "" {
+		return errNoConfig
 	}
 
 	c.McpConfig = expandPath(c.McpConfig)
+	c.Record = expandPath(c.Record)
+	c.Replay = expandPath(c.Replay)
 
-	var mcpJSON string
-	if c.McpConfig != "" {
-		raw, err := os.ReadFile(c.McpConfig)
-		if err != nil {
-			return fmt.Errorf("failed to read MCP config file: %w", err)
-		}
-		mcpJSON = string(raw)
-	} else {
-		mcpJSON = c.McpJSON
-	}
-
-	if mcpJSON != "" {
-		var mcpConfig autoconfig.MCPServers
-		if err := json.Unmarshal([]byte(mcpJSON), &mcpConfig); err != nil {
-			return fmt.Errorf("failed to unmarshal MCP config: %w", err)
-		}
-		c.mcpConfig = &mcpConfig
+	mcpConfig, err := readMCPServers(c.McpConfig, c.McpJSON)
+	if err != nil {
+		return err
 	}
+	c.mcpConfig = mcpConfig
 
 	opts, err := newRunOpts(c.dirs, c.RunID, c.Path, mainlib.Main)
 	if err != nil {
@@ -160,14 +200,39 @@ func (c *cmdRun) Validate() error {
 	return nil
 }
 
+// BeforeApply is called by Kong before applying defaults to set the default configuration path.
+func (c *cmdTest) BeforeApply(_ *kong.Context) error {
+	if c.Path == "" && c.dirs != nil {
+		defaultPath := c.dirs.ConfigHome + "/config.yaml"
+		if _, err := os.Stat(defaultPath); err == nil {
+			c.Path = defaultPath
+		}
+	}
+	c.Path = expandPath(c.Path)
+	return nil
+}
+
+// Validate is called by Kong after parsing to validate the cmdTest arguments.
+func (c *cmdTest) Validate() error {
+	if c.Path == "" && !envConfigured() {
+		return errNoConfig
+	}
+	return nil
+}
+
 type (
-	runFn           func(context.Context, *cmdRun, *runOpts, io.Writer, io.Writer) error
-	healthcheckFn   func(context.Context, io.Writer, io.Writer) error
-	downloadEnvoyFn func(context.Context, *cmdDownloadEnvoy, io.Writer, io.Writer) error
+	runFn            func(context.Context, *cmdRun, *runOpts, io.Writer, io.Writer) error
+	healthcheckFn    func(context.Context, io.Writer, io.Writer) error
+	downloadEnvoyFn  func(context.Context, *cmdDownloadEnvoy, io.Writer, io.Writer) error
+	mcpFingerprintFn func(context.Context, *cmdMCPFingerprint, io.Writer, io.Writer) error
+	translateFn      func(context.Context, *cmdTranslate, io.Writer, io.Writer) error
+	smokeTestFn      func(context.Context, *cmdTest, io.Writer, io.Writer) error
+	chatFn           func(context.Context, *cmdChat, io.Writer, io.Writer) error
 )
 
 func main() {
-	doMain(ctrl.SetupSignalHandler(), os.Stdout, os.Stderr, os.Args[1:], os.Exit, run, healthcheck, downloadEnvoyCmd)
+	doMain(ctrl.SetupSignalHandler(), os.Stdout, os.Stderr, os.Args[1:], os.Exit, run, healthcheck, downloadEnvoyCmd, mcpFingerprint, translateCmd,
+		smokeTestCmd, chatCmd)
 }
 
 // doMain is the main entry point for the CLI. It parses the command line arguments and executes the appropriate command.
@@ -181,8 +246,12 @@ func doMain(ctx context.Context, stdout, stderr io.Writer, args []string, exitFn
 	rf runFn,
 	hf healthcheckFn,
 	df downloadEnvoyFn,
+	mf mcpFingerprintFn,
+	tf translateFn,
+	stf smokeTestFn,
+	cf chatFn,
 ) {
-	var c cmd
+	c := cmd{Translate: cmdTranslate{stdin: os.Stdin}, Chat: cmdChat{stdin: os.Stdin}}
 	parser, err := kong.New(&c,
 		kong.Name("aigw"),
 		kong.Description("Envoy AI Gateway CLI"),
@@ -213,6 +282,26 @@ func doMain(ctx context.Context, stdout, stderr io.Writer, args []string, exitFn
 		if err != nil {
 			log.Fatalf("Download Envoy failed: %v", err)
 		}
+	case "mcp-fingerprint <url>":
+		err = mf(ctx, &c.MCPFingerprint, stdout, stderr)
+		if err != nil {
+			log.Fatalf("MCP fingerprint failed: %v", err)
+		}
+	case "translate", "translate <path>":
+		err = tf(ctx, &c.Translate, stdout, stderr)
+		if err != nil {
+			log.Fatalf("Translate failed: %v", err)
+		}
+	case "test", "test <path>":
+		err = stf(ctx, &c.Test, stdout, stderr)
+		if err != nil {
+			log.Fatalf("Test failed: %v", err)
+		}
+	case "chat":
+		err = cf(ctx, &c.Chat, stdout, stderr)
+		if err != nil {
+			log.Fatalf("Chat failed: %v", err)
+		}
 	default:
 		panic("unreachable")
 	}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
@@ -29,6 +30,10 @@ func Test_doMain(t *testing.T) {
 		rf           runFn
 		hf           healthcheckFn
 		df           downloadEnvoyFn
+		mf           mcpFingerprintFn
+		tf           translateFn
+		stf          smokeTestFn
+		cf           chatFn
 		expOut       string
 		expPanicCode *int
 	}{
@@ -63,6 +68,19 @@ Commands:
   download-envoy [flags]
     Download Envoy binary for the Envoy Gateway default version.
 
+  mcp-fingerprint <url> [flags]
+    Generate the ConfigMap with the approved tool fingerprints of an MCP server.
+
+  translate [<path> ...] [flags]
+    Translate AI Gateway resources to Envoy Gateway and Kubernetes resources.
+
+  test [<path>] [flags]
+    Send a request for each model routed by the configuration to the running AI
+    Gateway.
+
+  chat --model=STRING [flags]
+    Chat with a model through the running AI Gateway.
+
 Run "aigw <command> --help" for more information on a command.
 `,
 			expPanicCode: ptr.To(0),
@@ -121,7 +139,8 @@ Run the AI Gateway locally for given configuration.
 Arguments:
   [<path>]    Path to the AI Gateway configuration yaml file. Defaults to
               $AIGW_CONFIG_HOME/config.yaml if exists, otherwise optional when
-              at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY or ANTHROPIC_API_KEY
+              at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY,
+              AWS_PROFILE, AWS_ACCESS_KEY_ID or GOOGLE_APPLICATION_CREDENTIALS
               is set.
 
 Flags:
@@ -147,6 +166,21 @@ Flags:
       --mcp-session-encryption-iterations=100000
                               Number of iterations for MCP session encryption
                               key derivation.
+      --model-discovery-interval=5m
+                              Interval at which the models of the OpenAI
+                              compatible and Anthropic backends are listed,
+                              when the configuration is generated from the
+                              environment variables. Zero disables the model
+                              discovery.
+      --record=STRING         Directory where each request sent to the AI
+                              backends and its response are recorded, in the
+                              go-vcr cassette format.
+      --replay=STRING         Directory of the recordings of --record. The
+                              recorded responses are served instead of sending
+                              the requests to the AI backends.
+      --replay-timing         Preserve the recorded delays of the response
+                              chunks, such as the streamed events, when
+                              replaying.
 `,
 			expPanicCode: ptr.To(0),
 		},
@@ -169,6 +203,92 @@ Flags:
 				return nil
 			},
 		},
+		{
+			name: "mcp-fingerprint",
+			args: []string{"mcp-fingerprint", "http://localhost:8080/mcp", "-H", "Authorization: Bearer token", "--namespace", "default"},
+			mf: func(_ context.Context, c *cmdMCPFingerprint, _, _ io.Writer) error {
+				require.Equal(t, "http://localhost:8080/mcp", c.URL)
+				require.Equal(t, []string{"Authorization: Bearer token"}, c.Headers)
+				require.Equal(t, "mcp-tool-fingerprints", c.Name)
+				require.Equal(t, "default", c.Namespace)
+				return nil
+			},
+		},
+		{
+			name: "translate",
+			args: []string{"translate", "a.yaml", "-", "--validate", "-o", "json", "--diff", "./previous.json"},
+			tf: func(_ context.Context, c *cmdTranslate, _, _ io.Writer) error {
+				abs, err := filepath.Abs("./previous.json")
+				require.NoError(t, err)
+				require.Equal(t, []string{"a.yaml", "-"}, c.Paths)
+				require.True(t, c.Validate)
+				require.Equal(t, "json", c.Output)
+				require.Equal(t, abs, c.Diff)
+				require.Equal(t, os.Stdin, c.stdin)
+				return nil
+			},
+		},
+		{
+			name: "translate stdin",
+			args: []string{"translate"},
+			tf: func(_ context.Context, c *cmdTranslate, _, _ io.Writer) error {
+				require.Empty(t, c.Paths)
+				require.False(t, c.Validate)
+				require.Equal(t, "yaml", c.Output)
+				require.Empty(t, c.Diff)
+				return nil
+			},
+		},
+		{
+			name: "test",
+			args: []string{"test", "./config.yaml", "--url", "http://localhost:8080", "--model", "gpt-4.1", "--model", "o3", "-o", "json"},
+			stf: func(_ context.Context, c *cmdTest, _, _ io.Writer) error {
+				abs, err := filepath.Abs("./config.yaml")
+				require.NoError(t, err)
+				require.Equal(t, abs, c.Path)
+				require.Equal(t, "http://localhost:8080", c.URL)
+				require.Equal(t, []string{"gpt-4.1", "o3"}, c.Models)
+				require.Equal(t, 60*time.Second, c.Timeout)
+				require.Equal(t, "json", c.Output)
+				return nil
+			},
+		},
+		{
+			name: "test with OpenAI env",
+			args: []string{"test"},
+			env:  map[string]string{"OPENAI_API_KEY": "dummy-key"},
+			stf: func(_ context.Context, c *cmdTest, _, _ io.Writer) error {
+				require.Empty(t, c.URL)
+				require.Equal(t, "table", c.Output)
+				return nil
+			},
+		},
+		{
+			name:         "test no config",
+			args:         []string{"test"},
+			expPanicCode: ptr.To(80),
+		},
+		{
+			name: "chat",
+			args: []string{"chat", "--model", "qwen3:0.6b", "--system", "Be brief."},
+			cf: func(_ context.Context, c *cmdChat, _, _ io.Writer) error {
+				require.Equal(t, "qwen3:0.6b", c.Model)
+				require.Equal(t, "http://localhost:1975", c.URL)
+				require.Equal(t, "Be brief.", c.System)
+				require.Equal(t, os.Stdin, c.stdin)
+				return nil
+			},
+		},
+		{
+			name:         "chat without model",
+			args:         []string{"chat"},
+			expPanicCode: ptr.To(80),
+		},
+		{
+			name:         "translate invalid output",
+			args:         []string{"translate", "-o", "toml"},
+			expPanicCode: ptr.To(80),
+		},
 		{
 			name:         "download-envoy help",
 			args:         []string{"download-envoy", "--help"},
@@ -198,10 +318,10 @@ Flags:
 			out := &bytes.Buffer{}
 			if tt.expPanicCode != nil {
 				require.PanicsWithValue(t, *tt.expPanicCode, func() {
-					doMain(t.Context(), out, os.Stderr, tt.args, func(code int) { panic(code) }, tt.rf, tt.hf, tt.df)
+					doMain(t.Context(), out, os.Stderr, tt.args, func(code int) { panic(code) }, tt.rf, tt.hf, tt.df, tt.mf, tt.tf, tt.stf, tt.cf)
 				})
 			} else {
-				doMain(t.Context(), out, os.Stderr, tt.args, nil, tt.rf, tt.hf, tt.df)
+				doMain(t.Context(), out, os.Stderr, tt.args, nil, tt.rf, tt.hf, tt.df, tt.mf, tt.tf, tt.stf, tt.cf)
 			}
 			fmt.Println(out.String())
 			require.Equal(t, tt.expOut, out.String())
@@ -406,7 +526,7 @@ func TestCmdRun_Validate(t *testing.T) {
 			name:          "no config and no env vars",
 			path:          "",
 			envVars:       map[string]string{},
-			expectedError: "you must supply at least OPENAI_API_KEY, AZURE_OPENAI_API_KEY, ANTHROPIC_API_KEY, or a config file path",
+			expectedError: errNoConfig.Error(),
 		},
 		{
 			name:    "config path provided",
@@ -442,6 +562,20 @@ func TestCmdRun_Validate(t *testing.T) {
 				"ANTHROPIC_API_KEY": "sk-ant-test",
 			},
 		},
+		{
+			name: "AWS_PROFILE set",
+			path: "",
+			envVars: map[string]string{
+				"AWS_PROFILE": "dev",
+			},
+		},
+		{
+			name: "GOOGLE_APPLICATION_CREDENTIALS set",
+			path: "",
+			envVars: map[string]string{
+				"GOOGLE_APPLICATION_CREDENTIALS": "/tmp/adc.json",
+			},
+		},
 		{
 			name: "config path and OPENAI_API_KEY both set",
 			path: "/path/to/config.yaml",
@@ -484,3 +618,8 @@ func TestCmdRun_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdRun_Validate_recordAndReplay(t *testing.T) {
+	cmd := cmdRun{Path: "/path/to/config.yaml", Record: "/tmp/record", Replay: "/tmp/replay", dirs: newTempDirectories(t)}
+	require.EqualError(t, cmd.Validate(), "record and replay are mutually exclusive")
+}
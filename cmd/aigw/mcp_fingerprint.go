@@ -0,0 +1,89 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
+	"github.com/envoyproxy/ai-gateway/internal/mcpproxy"
+	"github.com/envoyproxy/ai-gateway/internal/version"
+)
+
+// headerRoundTripper sets the configured headers on every request to the MCP server.
+type headerRoundTripper struct {
+	headers http.Header
+	next    http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper.RoundTrip].
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range h.headers {
+		req.Header[k] = v
+	}
+	return h.next.RoundTrip(req)
+}
+
+// mcpFingerprint connects to the MCP server, fingerprints all of its tools, and writes a ConfigMap with the
+// fingerprints to stdout. The ConfigMap can be referenced as the tool pinning baseline of an MCPRoute backend.
+func mcpFingerprint(ctx context.Context, c *cmdMCPFingerprint, stdout, _ io.Writer) error {
+	headers := http.Header{}
+	for _, h := range c.Headers {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q: must be in the form 'Name: value'", h)
+		}
+		headers.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "aigw-mcp-fingerprint", Version: version.Parse()}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   c.URL,
+		HTTPClient: &http.Client{Transport: &headerRoundTripper{headers: headers, next: http.DefaultTransport}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the MCP server %s: %w", c.URL, err)
+	}
+	defer func() { _ = cs.Close() }()
+
+	fingerprints := make(map[string]string)
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("failed to list the tools of the MCP server %s: %w", c.URL, err)
+		}
+		fingerprint, err := mcpproxy.ToolFingerprint(tool)
+		if err != nil {
+			return err
+		}
+		fingerprints[tool.Name] = fingerprint
+	}
+
+	out, err := yaml.Marshal(&corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Name,
+			Namespace: c.Namespace,
+			// The controller only watches the labeled ConfigMaps.
+			Labels: map[string]string{aigv1b1.MCPRouteConfigMapLabel: "true"},
+		},
+		Data: fingerprints,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the ConfigMap: %w", err)
+	}
+	_, err = stdout.Write(out)
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
+	"github.com/envoyproxy/ai-gateway/internal/mcpproxy"
+)
+
+func Test_mcpFingerprint(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	tool := &mcp.Tool{Name: "echo", Description: "Echo the input.", InputSchema: map[string]any{"type": "object"}}
+	server.AddTool(tool, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	out := &bytes.Buffer{}
+	err := mcpFingerprint(t.Context(), &cmdMCPFingerprint{
+		URL:       ts.URL,
+		Headers:   []string{"Authorization: Bearer token"},
+		Name:      "fingerprints",
+		Namespace: "default",
+	}, out, io.Discard)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer token", authorization)
+
+	var cm corev1.ConfigMap
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &cm))
+	require.Equal(t, "ConfigMap", cm.Kind)
+	require.Equal(t, "fingerprints", cm.Name)
+	require.Equal(t, "default", cm.Namespace)
+	require.Equal(t, map[string]string{aigv1b1.MCPRouteConfigMapLabel: "true"}, cm.Labels)
+	// The fingerprint of the listed tool matches the one of the original definition.
+	expected, err := mcpproxy.ToolFingerprint(tool)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"echo": expected}, cm.Data)
+}
+
+func Test_mcpFingerprint_Errors(t *testing.T) {
+	err := mcpFingerprint(t.Context(), &cmdMCPFingerprint{URL: "http://localhost", Headers: []string{"invalid"}}, io.Discard, io.Discard)
+	require.ErrorContains(t, err, `invalid header "invalid"`)
+
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+	err = mcpFingerprint(t.Context(), &cmdMCPFingerprint{URL: ts.URL}, io.Discard, io.Discard)
+	require.ErrorContains(t, err, "failed to connect to the MCP server")
+}
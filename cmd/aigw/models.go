@@ -0,0 +1,85 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/autoconfig"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+// modelDiscovery lists the models of the backends of the auto-generated configuration, so that the generated
+// AIGatewayRoute routes them by their exact names and the "/v1/models" endpoint of the gateway lists them.
+//
+// As the configuration is read on every configuration reload check, the models are only listed again once the
+// interval elapsed or when the backends changed.
+type modelDiscovery struct {
+	client   *http.Client
+	interval time.Duration
+	logger   *slog.Logger
+	stderr   io.Writer
+	now      func() time.Time
+
+	// backends identifies the backends whose models were last listed.
+	backends string
+	// lastDiscovery is the time at which the models were last listed.
+	lastDiscovery time.Time
+	// models are the last successfully listed models, by AIServiceBackend name.
+	models map[string][]autoconfig.DiscoveredModel
+}
+
+func newModelDiscovery(interval time.Duration, logger *slog.Logger, stderr io.Writer) *modelDiscovery {
+	return &modelDiscovery{
+		client:   http.DefaultClient,
+		interval: interval,
+		logger:   logger,
+		stderr:   stderr,
+		now:      time.Now,
+		models:   make(map[string][]autoconfig.DiscoveredModel),
+	}
+}
+
+// populate sets the discovered models of the backends of the given configuration, listing them if the interval
+// elapsed since they were last listed or if the backends changed.
+//
+// Failing to list the models of a backend is reported, and the models of its last successful discovery, if any,
+// are kept. The requests for the models that are not discovered are still routed by the model name.
+func (m *modelDiscovery) populate(ctx context.Context, data *autoconfig.ConfigData) {
+	raw, _ := json.Marshal(struct {
+		Backends  []autoconfig.Backend
+		OpenAI    *autoconfig.OpenAIConfig
+		Anthropic *autoconfig.AnthropicConfig
+	}{data.Backends, data.OpenAI, data.Anthropic})
+	backends := string(raw)
+	if backends != m.backends {
+		m.backends = backends
+		m.lastDiscovery = time.Time{}
+		clear(m.models)
+	}
+
+	if now := m.now(); now.Sub(m.lastDiscovery) >= m.interval {
+		m.lastDiscovery = now
+		m.logger.Info("Discovering the models of the backends")
+		models, err := autoconfig.DiscoverModels(ctx, m.client, data)
+		if err != nil {
+			m.logger.Error("Failed to discover the models", "error", err)
+			_, _ = fmt.Fprintf(m.stderr, "Failed to discover the models, routing them by their names: %v\n", err)
+		}
+		for backend, backendModels := range models {
+			m.logger.Info("Discovered the models", "backend", backend, "count", len(backendModels))
+			m.models[backend] = backendModels
+		}
+	}
+	if len(m.models) > 0 {
+		data.DiscoveredModels = m.models
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/autoconfig"
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestModelDiscovery_populate(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	var requests atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen3:0.6b","object":"model","owned_by":"library"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_KEY", "unused")
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	stderr := &bytes.Buffer{}
+	now := time.Now()
+	m := newModelDiscovery(time.Minute, slog.New(slog.DiscardHandler), stderr)
+	m.client = server.Client()
+	m.now = func() time.Time { return now }
+	newData := func() *autoconfig.ConfigData {
+		data := &autoconfig.ConfigData{}
+		require.NoError(t, autoconfig.PopulateOpenAIEnvConfig(data))
+		return data
+	}
+	expected := map[string][]autoconfig.DiscoveredModel{"openai": {{Name: "qwen3:0.6b", OwnedBy: "library"}}}
+
+	// The models are listed on the first call.
+	data := newData()
+	m.populate(t.Context(), data)
+	require.Equal(t, expected, data.DiscoveredModels)
+	require.Equal(t, int32(1), requests.Load())
+
+	// The models are not listed again before the interval elapsed.
+	now = now.Add(30 * time.Second)
+	data = newData()
+	m.populate(t.Context(), data)
+	require.Equal(t, expected, data.DiscoveredModels)
+	require.Equal(t, int32(1), requests.Load())
+
+	// Failing to list the models keeps the previously discovered ones.
+	failing.Store(true)
+	now = now.Add(time.Minute)
+	data = newData()
+	m.populate(t.Context(), data)
+	require.Equal(t, expected, data.DiscoveredModels)
+	require.Equal(t, int32(2), requests.Load())
+	require.Contains(t, stderr.String(), "Failed to discover the models, routing them by their names: "+
+		"failed to list the OpenAI models: unexpected status code 503")
+
+	// Changing the backends lists the models again, and forgets the ones of the previous backends.
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/other/v1")
+	data = newData()
+	m.populate(t.Context(), data)
+	require.Nil(t, data.DiscoveredModels)
+	require.Equal(t, int32(3), requests.Load())
+}
+
+func TestReadConfig_discoveredModels(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen3:0.6b","object":"model","owned_by":"library"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_KEY", "unused")
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	m := newModelDiscovery(time.Minute, slog.New(slog.DiscardHandler), &bytes.Buffer{})
+	config, err := readConfig(t.Context(), "", nil, false, m, io.Discard)
+	require.NoError(t, err)
+	require.Contains(t, config, `
+    - matches:
+        - headers:
+            - type: Exact
+              name: x-ai-eg-model
+              value: "qwen3:0.6b"
+      backendRefs:
+        - name: openai
+          namespace: default
+      modelsOwnedBy: "library"`)
+
+	// A configuration file is not changed.
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("kind: AIGatewayRoute\n"), 0o600))
+	config, err = readConfig(t.Context(), configPath, nil, false, m, io.Discard)
+	require.NoError(t, err)
+	require.Equal(t, "kind: AIGatewayRoute\n", config)
+}
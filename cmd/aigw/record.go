@@ -0,0 +1,541 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+	"gopkg.in/yaml.v3" //nolint:depguard // sigs.k8s.io/yaml breaks Duration unmarshaling in cassettes
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+var (
+	// recordedRequestHeadersToRedact are the credentials and ephemeral headers that are not written to the recordings.
+	recordedRequestHeadersToRedact = []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Api-Key",              // Azure OpenAI API key header
+		"X-Api-Key",            // Anthropic API key header
+		"X-Goog-Api-Key",       // Gemini API key header
+		"X-Amz-Security-Token", // AWS session token
+		"Openai-Organization",
+		"Openai-Project",
+		"Cookie",
+		"b3", "traceparent", "tracestate", "x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-parentspanid", "x-b3-flags",
+	}
+	// recordedResponseHeadersToRedact are the sensitive headers of the responses that are not written to the recordings.
+	recordedResponseHeadersToRedact = []string{
+		"Openai-Organization",
+		"Set-Cookie",
+	}
+	// hopByHopHeaders are the headers of a single connection, which are not forwarded.
+	hopByHopHeaders = []string{
+		"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+	}
+)
+
+// upstreamRecorder implements the --record and --replay options of `aigw run`.
+//
+// It rewrites the Backends of the AIServiceBackends of the configuration to point to a local stand-in backend for
+// each upstream. When recording, the stand-in forwards the requests to the upstream and writes each request and its
+// response to the recordings directory. When replaying, it serves the recorded responses without contacting the
+// upstream.
+type upstreamRecorder struct {
+	dir    string
+	replay bool
+	// replayTiming preserves the recorded delays of the response chunks when replaying.
+	replayTiming bool
+	logger       *slog.Logger
+	stderr       io.Writer
+	running      map[upstream]*upstreamStandIn
+}
+
+// upstream is the original endpoint of a rewritten Backend.
+type upstream struct {
+	// url is the scheme and the host of the upstream, such as "https://api.openai.com".
+	url string
+	// serverName is the name verified in the TLS certificate of the upstream, if different from its hostname.
+	serverName string
+}
+
+// upstreamStandIn is a running local stand-in backend of an upstream.
+type upstreamStandIn struct {
+	port   int
+	client *http.Client
+	stop   context.CancelFunc
+}
+
+// recording is a recorded request and its response, in the go-vcr cassette format.
+type recording struct {
+	Version      int                    `yaml:"version"`
+	Interactions []*recordedInteraction `yaml:"interactions"`
+}
+
+// recordedInteraction is a go-vcr interaction with the timing of the chunks of its response body.
+type recordedInteraction struct {
+	cassette.Interaction `yaml:",inline"`
+	// Chunks are the sizes of the successive reads of the response body, with the delay of each since the previous
+	// one, or since the request was sent for the first one.
+	Chunks []recordedChunk `yaml:"chunks,omitempty"`
+}
+
+// recordedChunk is a chunk of a recorded response body.
+type recordedChunk struct {
+	Size  int           `yaml:"size"`
+	Delay time.Duration `yaml:"delay"`
+}
+
+// newUpstreamRecorder returns the recorder for the given options, or nil if neither recording nor replaying.
+func newUpstreamRecorder(c *cmdRun, logger *slog.Logger, stderr io.Writer) (*upstreamRecorder, error) {
+	switch {
+	case c.Record != "":
+		if err := os.MkdirAll(c.Record, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create the recordings directory: %w", err)
+		}
+		return &upstreamRecorder{dir: c.Record, logger: logger, stderr: stderr}, nil
+	case c.Replay != "":
+		if _, err := os.Stat(c.Replay); err != nil {
+			return nil, fmt.Errorf("failed to read the recordings directory: %w", err)
+		}
+		return &upstreamRecorder{dir: c.Replay, replay: true, replayTiming: c.ReplayTiming, logger: logger, stderr: stderr}, nil
+	}
+	return nil, nil
+}
+
+// rewriteBackends points the Backends of the AIServiceBackends of the given configuration to their local stand-ins,
+// starting the stand-ins of new upstreams and stopping the ones no longer used.
+//
+// The BackendTLSPolicies of the rewritten Backends are removed, since Envoy connects to the stand-ins in plain text.
+func (r *upstreamRecorder) rewriteBackends(ctx context.Context, config string) (string, error) {
+	var objs []*unstructured.Unstructured
+	decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(config), 4096)
+	for {
+		var rawObj runtime.RawExtension
+		if err := decoder.Decode(&rawObj); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("error decoding YAML: %w", err)
+		}
+		if len(rawObj.Raw) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if _, _, err := unstructured.UnstructuredJSONScheme.Decode(rawObj.Raw, nil, obj); err != nil {
+			return "", fmt.Errorf("error decoding unstructured object: %w", err)
+		}
+		objs = append(objs, obj)
+	}
+
+	// The Backends to rewrite are the ones of the AIServiceBackends. The others, such as the ones of the MCP servers
+	// and of the telemetry, are kept.
+	aiBackends := make(map[string]bool)
+	for _, obj := range objs {
+		if obj.GetKind() != "AIServiceBackend" {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "backendRef", "kind")
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "backendRef", "name")
+		namespace, _, _ := unstructured.NestedString(obj.Object, "spec", "backendRef", "namespace")
+		if kind == "Backend" {
+			aiBackends[objectKey(namespace, obj.GetNamespace(), name)] = true
+		}
+	}
+	// The TLS server names of the Backends targeted by a BackendTLSPolicy.
+	tlsBackends := make(map[string]string)
+	for _, obj := range objs {
+		if obj.GetKind() != "BackendTLSPolicy" {
+			continue
+		}
+		hostname, _, _ := unstructured.NestedString(obj.Object, "spec", "validation", "hostname")
+		targetRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targetRefs")
+		for _, ref := range targetRefs {
+			if ref, ok := ref.(map[string]any); ok && ref["kind"] == "Backend" {
+				name, _ := ref["name"].(string)
+				tlsBackends[objectKey("", obj.GetNamespace(), name)] = hostname
+			}
+		}
+	}
+
+	used := make(map[upstream]bool)
+	rewritten := make(map[string]bool)
+	for _, obj := range objs {
+		key := objectKey("", obj.GetNamespace(), obj.GetName())
+		if obj.GetKind() != "Backend" || !aiBackends[key] {
+			continue
+		}
+		endpoints, _, _ := unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+		if len(endpoints) == 0 {
+			continue
+		}
+		// Only the first endpoint is recorded, as all the endpoints of a Backend serve the same API.
+		endpoint, _ := endpoints[0].(map[string]any)
+		host, _, _ := unstructured.NestedString(endpoint, "fqdn", "hostname")
+		port, _, _ := unstructured.NestedInt64(endpoint, "fqdn", "port")
+		if host == "" {
+			host, _, _ = unstructured.NestedString(endpoint, "ip", "address")
+			port, _, _ = unstructured.NestedInt64(endpoint, "ip", "port")
+		}
+		if host == "" {
+			continue
+		}
+		serverName, isTLS := tlsBackends[key]
+		if _, hasTLS, _ := unstructured.NestedMap(obj.Object, "spec", "tls"); hasTLS || port == 443 {
+			isTLS = true
+		}
+		u := upstreamOf(host, int(port), isTLS)
+		if serverName == host {
+			serverName = ""
+		}
+		up := upstream{url: u, serverName: serverName}
+		standIn, err := r.standIn(ctx, up)
+		if err != nil {
+			return "", err
+		}
+		used[up] = true
+		rewritten[key] = true
+
+		if err = unstructured.SetNestedSlice(obj.Object, []any{
+			map[string]any{"ip": map[string]any{"address": "127.0.0.1", "port": int64(standIn.port)}},
+		}, "spec", "endpoints"); err != nil {
+			return "", err
+		}
+		unstructured.RemoveNestedField(obj.Object, "spec", "tls")
+	}
+	for up, standIn := range r.running {
+		if !used[up] {
+			r.logger.Info("stopping the stand-in backend", "upstream", up.url)
+			standIn.stop()
+			delete(r.running, up)
+		}
+	}
+
+	var out bytes.Buffer
+	for _, obj := range objs {
+		if obj.GetKind() == "BackendTLSPolicy" {
+			targetRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targetRefs")
+			var kept []any
+			for _, ref := range targetRefs {
+				if ref, ok := ref.(map[string]any); ok && ref["kind"] == "Backend" {
+					name, _ := ref["name"].(string)
+					if rewritten[objectKey("", obj.GetNamespace(), name)] {
+						continue
+					}
+				}
+				kept = append(kept, ref)
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			if err := unstructured.SetNestedSlice(obj.Object, kept, "spec", "targetRefs"); err != nil {
+				return "", err
+			}
+		}
+		data, err := kyaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString("---\n")
+		out.Write(data)
+	}
+	return out.String(), nil
+}
+
+// objectKey returns the namespace/name key of an object referenced from an object in the given namespace.
+func objectKey(refNamespace, namespace, name string) string {
+	if refNamespace != "" {
+		namespace = refNamespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	return namespace + "/" + name
+}
+
+// upstreamOf returns the URL of an upstream, omitting the default port of the scheme, so that it is also the Host
+// header sent to the upstream.
+func upstreamOf(host string, port int, isTLS bool) string {
+	scheme, defaultPort := "http", 80
+	if isTLS {
+		scheme, defaultPort = "https", 443
+	}
+	if port == 0 || port == defaultPort {
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		return scheme + "://" + host
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// standIn returns the running stand-in backend of the given upstream, starting it if needed.
+func (r *upstreamRecorder) standIn(ctx context.Context, up upstream) (*upstreamStandIn, error) {
+	if standIn, ok := r.running[up]; ok {
+		return standIn, nil
+	}
+	if r.running == nil {
+		r.running = make(map[upstream]*upstreamStandIn)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("getting a free port for the stand-in backend of %s: %w", up.url, err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if up.serverName != "" {
+		transport.TLSClientConfig = &tls.Config{ServerName: up.serverName, MinVersion: tls.VersionTLS12}
+	}
+	standIn := &upstreamStandIn{
+		port: listener.Addr().(*net.TCPAddr).Port,
+		client: &http.Client{
+			Transport: transport,
+			// Redirects are recorded and replayed as they are.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.serveHTTP(w, req, up.url, standIn.client)
+		}),
+		ReadHeaderTimeout: 120 * time.Second,
+	}
+	standInCtx, stop := context.WithCancel(ctx)
+	standIn.stop = stop
+	r.running[up] = standIn
+
+	go func() {
+		r.logger.Info("starting the stand-in backend", "upstream", up.url, "address", listener.Addr().String(), "replay", r.replay)
+		if serverErr := server.Serve(listener); serverErr != nil && !errors.Is(serverErr, http.ErrServerClosed) {
+			r.logger.Error("stand-in backend error", "upstream", up.url, "error", serverErr)
+		}
+	}()
+	go func() {
+		<-standInCtx.Done()
+		_ = server.Close()
+		transport.CloseIdleConnections()
+	}()
+	return standIn, nil
+}
+
+// serveHTTP records or replays a request sent by Envoy to the stand-in backend of the given upstream.
+func (r *upstreamRecorder) serveHTTP(w http.ResponseWriter, req *http.Request, upstreamURL string, client *http.Client) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read the request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	path := filepath.Join(r.dir, recordingKey(req.Method, upstreamURL, req.URL.RequestURI(), body)+".yaml")
+	if r.replay {
+		r.replayRecording(w, req, upstreamURL, path)
+		return
+	}
+
+	out, err := http.NewRequestWithContext(req.Context(), req.Method, upstreamURL+req.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out.Header = req.Header.Clone()
+	deleteHeaders(out.Header, hopByHopHeaders)
+	// Let the transport negotiate the compression and decompress the response, so that the bodies are recorded as
+	// they are read by the clients.
+	out.Header.Del("Accept-Encoding")
+
+	start := time.Now()
+	resp, err := client.Do(out)
+	if err != nil {
+		r.logger.Error("failed to send the request to the upstream", "upstream", upstreamURL, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	deleteHeaders(resp.Header, hopByHopHeaders)
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	flusher, _ := w.(http.Flusher)
+
+	var (
+		respBody bytes.Buffer
+		chunks   []recordedChunk
+		last     = start
+		buf      = make([]byte, 32*1024)
+	)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			now := time.Now()
+			chunks = append(chunks, recordedChunk{Size: n, Delay: now.Sub(last)})
+			last = now
+			respBody.Write(buf[:n])
+			if _, err = w.Write(buf[:n]); err != nil {
+				r.logger.Error("failed to write the response", "upstream", upstreamURL, "error", err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		} else if readErr != nil {
+			// An incomplete response is not recorded.
+			r.logger.Error("failed to read the response of the upstream", "upstream", upstreamURL, "error", readErr)
+			return
+		}
+	}
+
+	requestHeaders := out.Header.Clone()
+	deleteHeaders(requestHeaders, recordedRequestHeadersToRedact)
+	responseHeaders := resp.Header.Clone()
+	deleteHeaders(responseHeaders, recordedResponseHeadersToRedact)
+	rec := &recording{
+		Version: cassette.CassetteFormatVersion,
+		Interactions: []*recordedInteraction{{
+			Interaction: cassette.Interaction{
+				Request: cassette.Request{
+					Proto:         out.Proto,
+					ProtoMajor:    out.ProtoMajor,
+					ProtoMinor:    out.ProtoMinor,
+					ContentLength: int64(len(body)),
+					Host:          out.URL.Host,
+					Body:          string(body),
+					Headers:       requestHeaders,
+					URL:           out.URL.String(),
+					Method:        out.Method,
+				},
+				Response: cassette.Response{
+					Proto:         resp.Proto,
+					ProtoMajor:    resp.ProtoMajor,
+					ProtoMinor:    resp.ProtoMinor,
+					ContentLength: int64(respBody.Len()),
+					Uncompressed:  resp.Uncompressed,
+					Body:          respBody.String(),
+					Headers:       responseHeaders,
+					Status:        resp.Status,
+					Code:          resp.StatusCode,
+					Duration:      time.Since(start),
+				},
+			},
+			Chunks: chunks,
+		}},
+	}
+	data, err := yaml.Marshal(rec)
+	if err == nil {
+		err = os.WriteFile(path, data, 0o600)
+	}
+	if err != nil {
+		r.logger.Error("failed to write the recording", "path", path, "error", err)
+		return
+	}
+	r.logger.Info("recorded the upstream response", "method", out.Method, "url", out.URL.String(), "path", path)
+}
+
+// replayRecording writes the response recorded at the given path.
+func (r *upstreamRecorder) replayRecording(w http.ResponseWriter, req *http.Request, upstreamURL, path string) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		_, _ = fmt.Fprintf(r.stderr, "No recording of %s %s%s in %s\n", req.Method, upstreamURL, req.URL.RequestURI(), r.dir)
+		http.Error(w, fmt.Sprintf("no recording of %s %s%s", req.Method, upstreamURL, req.URL.RequestURI()), http.StatusNotFound)
+		return
+	}
+	var rec recording
+	if err == nil {
+		err = yaml.Unmarshal(data, &rec)
+	}
+	if err == nil && len(rec.Interactions) == 0 {
+		err = errors.New("no interaction recorded")
+	}
+	if err != nil {
+		r.logger.Error("failed to read the recording", "path", path, "error", err)
+		http.Error(w, fmt.Sprintf("failed to read the recording %s: %v", path, err), http.StatusInternalServerError)
+		return
+	}
+	resp := rec.Interactions[0].Response
+	chunks := rec.Interactions[0].Chunks
+	if !r.replayTiming {
+		chunks = nil
+	}
+
+	for k, v := range resp.Headers {
+		w.Header()[k] = v
+	}
+	flusher, _ := w.(http.Flusher)
+	body := resp.Body
+	wroteHeader := false
+	for _, chunk := range chunks {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-time.After(chunk.Delay):
+		}
+		if !wroteHeader {
+			// The headers are sent with the first chunk, to preserve the time to the first byte.
+			w.WriteHeader(resp.Code)
+			wroteHeader = true
+		}
+		size := min(chunk.Size, len(body))
+		_, _ = io.WriteString(w, body[:size])
+		body = body[size:]
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if !wroteHeader {
+		w.WriteHeader(resp.Code)
+	}
+	_, _ = io.WriteString(w, body)
+	r.logger.Info("replayed the upstream response", "method", req.Method, "upstream", upstreamURL, "path", path)
+}
+
+// recordingKey returns the name of the recording of a request: a hash of its method, upstream, path and query, and
+// body. The headers are not part of it, and the fields of a JSON body are sorted, so that the credentials and the
+// serialization of the request do not change it.
+func recordingKey(method, upstreamURL, requestURI string, body []byte) string {
+	var v any
+	if json.Unmarshal(body, &v) == nil {
+		if canonical, err := json.MarshalSortedKeys(v); err == nil {
+			body = canonical
+		}
+	}
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s %s%s\n", method, upstreamURL, requestURI)
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// deleteHeaders deletes the given headers, ignoring the case of their names.
+func deleteHeaders(header http.Header, names []string) {
+	for _, name := range names {
+		header.Del(name)
+		for k := range header {
+			if strings.EqualFold(k, name) {
+				delete(header, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+	"gopkg.in/yaml.v3" //nolint:depguard // sigs.k8s.io/yaml breaks Duration unmarshaling in cassettes
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// recordTestConfig returns a configuration with an AIServiceBackend of the given Backend endpoint, and an MCP server
+// Backend which is not rewritten.
+func recordTestConfig(endpoint string, withTLS bool) string {
+	config := `apiVersion: aigateway.envoyproxy.io/v1beta1
+kind: AIServiceBackend
+metadata:
+  name: openai
+  namespace: default
+spec:
+  schema:
+    name: OpenAI
+  backendRef:
+    name: openai
+    kind: Backend
+    group: gateway.envoyproxy.io
+---
+apiVersion: gateway.envoyproxy.io/v1alpha1
+kind: Backend
+metadata:
+  name: openai
+  namespace: default
+spec:
+  endpoints:
+    - ` + endpoint + `
+---
+apiVersion: gateway.envoyproxy.io/v1alpha1
+kind: Backend
+metadata:
+  name: github
+  namespace: default
+spec:
+  endpoints:
+    - fqdn:
+        hostname: api.githubcopilot.com
+        port: 443
+`
+	if withTLS {
+		config += `---
+apiVersion: gateway.networking.k8s.io/v1alpha3
+kind: BackendTLSPolicy
+metadata:
+  name: openai-tls
+  namespace: default
+spec:
+  targetRefs:
+    - group: gateway.envoyproxy.io
+      kind: Backend
+      name: openai
+  validation:
+    wellKnownCACertificates: System
+    hostname: api.openai.com
+`
+	}
+	return config
+}
+
+func TestUpstreamRecorder_rewriteBackends(t *testing.T) {
+	r := &upstreamRecorder{dir: t.TempDir(), logger: slog.New(slog.DiscardHandler), stderr: io.Discard}
+	config := recordTestConfig("fqdn: {hostname: api.openai.com, port: 443}", true)
+	out, err := r.rewriteBackends(t.Context(), config)
+	require.NoError(t, err)
+
+	require.Len(t, r.running, 1)
+	standIn := r.running[upstream{url: "https://api.openai.com"}]
+	require.NotNil(t, standIn)
+	objs := map[string]map[string]any{}
+	for _, doc := range strings.Split(out, "---\n") {
+		if doc == "" {
+			continue
+		}
+		obj := map[string]any{}
+		require.NoError(t, kyaml.Unmarshal([]byte(doc), &obj))
+		u := unstructured.Unstructured{Object: obj}
+		objs[u.GetKind()+"/"+u.GetName()] = obj
+	}
+	// The BackendTLSPolicy of the rewritten Backend is removed.
+	require.Len(t, objs, 3)
+	endpoints, _, _ := unstructured.NestedSlice(objs["Backend/openai"], "spec", "endpoints")
+	require.Equal(t, []any{map[string]any{"ip": map[string]any{"address": "127.0.0.1", "port": float64(standIn.port)}}}, endpoints)
+	// The Backends of the MCP servers are not rewritten.
+	endpoints, _, _ = unstructured.NestedSlice(objs["Backend/github"], "spec", "endpoints")
+	require.Equal(t, "api.githubcopilot.com", endpoints[0].(map[string]any)["fqdn"].(map[string]any)["hostname"])
+
+	t.Run("reload", func(t *testing.T) {
+		// The stand-in is kept, so that the configuration does not change.
+		again, err := r.rewriteBackends(t.Context(), config)
+		require.NoError(t, err)
+		require.Equal(t, out, again)
+		require.Same(t, standIn, r.running[upstream{url: "https://api.openai.com"}])
+
+		// The stand-in of the upstream no longer used is stopped.
+		_, err = r.rewriteBackends(t.Context(), recordTestConfig("fqdn: {hostname: api.anthropic.com, port: 443}", false))
+		require.NoError(t, err)
+		require.Len(t, r.running, 1)
+		require.NotNil(t, r.running[upstream{url: "https://api.anthropic.com"}])
+	})
+}
+
+func TestUpstreamRecorder_recordAndReplay(t *testing.T) {
+	const sse = "data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\ndata: [DONE]\n\n"
+	var upstreamRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamRequests++
+		require.Equal(t, "/v1/chat/completions?api-version=1", r.URL.RequestURI())
+		require.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Set-Cookie", "session=secret")
+		for i, event := range strings.SplitAfter(sse, "\n\n")[:2] {
+			if i > 0 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			_, _ = io.WriteString(w, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	config := recordTestConfig("ip: {address: 127.0.0.1, port: "+port+"}", false)
+	upstreamURL := "http://127.0.0.1:" + port
+
+	dir := t.TempDir()
+	send := func(t *testing.T, r *upstreamRecorder, body string) (*http.Response, string, time.Duration) {
+		_, err := r.rewriteBackends(t.Context(), config)
+		require.NoError(t, err)
+		standIn := r.running[upstream{url: upstreamURL}]
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost,
+			fmt.Sprintf("http://127.0.0.1:%d/v1/chat/completions?api-version=1", standIn.port), strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer sk-test")
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(respBody), time.Since(start)
+	}
+
+	recorder := &upstreamRecorder{dir: dir, logger: slog.New(slog.DiscardHandler), stderr: io.Discard}
+	resp, body, _ := send(t, recorder, `{"model":"gpt-4.1","stream":true}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, sse, body)
+	require.Equal(t, 1, upstreamRequests)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	key := recordingKey(http.MethodPost, upstreamURL, "/v1/chat/completions?api-version=1", []byte(`{"model":"gpt-4.1","stream":true}`))
+	require.Equal(t, key+".yaml", entries[0].Name())
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	// The recording is a go-vcr cassette without the credentials.
+	c := &cassette.Cassette{}
+	require.NoError(t, yaml.Unmarshal(data, c))
+	require.Equal(t, cassette.CassetteFormatVersion, c.Version)
+	require.Len(t, c.Interactions, 1)
+	require.Equal(t, upstreamURL+"/v1/chat/completions?api-version=1", c.Interactions[0].Request.URL)
+	require.Empty(t, c.Interactions[0].Request.Headers.Get("Authorization"))
+	require.Empty(t, c.Interactions[0].Response.Headers.Get("Set-Cookie"))
+	require.Equal(t, sse, c.Interactions[0].Response.Body)
+	require.NotContains(t, string(data), "sk-test")
+	require.Contains(t, string(data), "chunks:")
+
+	server.Close()
+	t.Run("replay", func(t *testing.T) {
+		replayer := &upstreamRecorder{dir: dir, replay: true, logger: slog.New(slog.DiscardHandler), stderr: io.Discard}
+		// The order of the fields of the body does not matter.
+		resp, body, elapsed := send(t, replayer, `{"stream":true,"model":"gpt-4.1"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		require.Equal(t, sse, body)
+		require.Less(t, elapsed, 200*time.Millisecond)
+	})
+
+	t.Run("replay with the timing", func(t *testing.T) {
+		replayer := &upstreamRecorder{dir: dir, replay: true, replayTiming: true, logger: slog.New(slog.DiscardHandler), stderr: io.Discard}
+		resp, body, elapsed := send(t, replayer, `{"model":"gpt-4.1","stream":true}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, sse, body)
+		require.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	})
+
+	t.Run("no recording", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		replayer := &upstreamRecorder{dir: dir, replay: true, logger: slog.New(slog.DiscardHandler), stderr: stderr}
+		resp, _, _ := send(t, replayer, `{"model":"gpt-5"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "No recording of POST "+upstreamURL+"/v1/chat/completions?api-version=1 in "+dir+"\n", stderr.String())
+	})
+}
+
+func TestRecordingKey(t *testing.T) {
+	key := recordingKey(http.MethodPost, "https://api.openai.com", "/v1/chat/completions", []byte(`{"a":1,"b":[1,2]}`))
+	require.Len(t, key, 32)
+	require.Equal(t, key, recordingKey(http.MethodPost, "https://api.openai.com", "/v1/chat/completions", []byte(`{ "b": [1, 2], "a": 1 }`)))
+	require.NotEqual(t, key, recordingKey(http.MethodPost, "https://api.openai.com", "/v1/chat/completions", []byte(`{"a":1,"b":[2,1]}`)))
+	require.NotEqual(t, key, recordingKey(http.MethodPost, "https://api.anthropic.com", "/v1/chat/completions", []byte(`{"a":1,"b":[1,2]}`)))
+	require.NotEqual(t, key, recordingKey(http.MethodPost, "https://api.openai.com", "/v1/embeddings", []byte(`{"a":1,"b":[1,2]}`)))
+	// Bodies that are not JSON are hashed as they are.
+	require.NotEqual(t, recordingKey(http.MethodPost, "https://api.openai.com", "/", []byte("a b")),
+		recordingKey(http.MethodPost, "https://api.openai.com", "/", []byte("a  b")))
+}
+
+func TestUpstreamOf(t *testing.T) {
+	for _, tc := range []struct {
+		host  string
+		port  int
+		isTLS bool
+		exp   string
+	}{
+		{host: "api.openai.com", port: 443, isTLS: true, exp: "https://api.openai.com"},
+		{host: "localhost", port: 11434, exp: "http://localhost:11434"},
+		{host: "127.0.0.1", port: 80, exp: "http://127.0.0.1"},
+		{host: "::1", port: 8443, isTLS: true, exp: "https://[::1]:8443"},
+		{host: "::1", port: 443, isTLS: true, exp: "https://[::1]"},
+	} {
+		t.Run(tc.host+":"+strconv.Itoa(tc.port), func(t *testing.T) {
+			require.Equal(t, tc.exp, upstreamOf(tc.host, tc.port, tc.isTLS))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// configReloadInterval is the interval at which `aigw run` checks whether the configuration changed.
+const configReloadInterval = 2 * time.Second
+
+// configReloader reloads the configuration of `aigw run` without restarting Envoy.
+//
+// It periodically reads the configuration file, the MCP servers configuration file, and the environment variables
+// used by the auto-generated configuration. When the resulting configuration changes, it translates it again and
+// rewrites the Envoy Gateway resources and the external processor configuration, which are both watched by their
+// respective processes. When the new configuration is invalid, the error is reported and the previous configuration
+// is kept running.
+type configReloader struct {
+	runCtx *runCmdContext
+	// configPath is the path to the AI Gateway configuration file, or empty if the configuration is auto-generated.
+	configPath string
+	// mcpConfigPath is the path to the MCP servers configuration file, if any.
+	mcpConfigPath string
+	// mcpJSON is the MCP servers configuration given on the command line, if any.
+	mcpJSON string
+	debug   bool
+	// egResourcesPath is the path to the Envoy Gateway resources file watched by Envoy Gateway.
+	egResourcesPath string
+	// k8sClient is the client used by the extension server, updated on each successful reload.
+	k8sClient    *reloadableClient
+	stdioProxies *stdioMCPProxies
+	// models discovers the models of the backends of the auto-generated configuration, or is nil if disabled.
+	models *modelDiscovery
+	// recorder records or replays the traffic of the AI backends, or is nil if disabled.
+	recorder *upstreamRecorder
+	logger   *slog.Logger
+	stderr   io.Writer
+
+	// lastConfig is the last configuration that was applied or attempted to be applied.
+	lastConfig string
+	// lastErr is the last reported error reading the configuration, used to not report the same error on every check.
+	lastErr string
+	// lastWarnings are the last reported warnings reading the configuration, for the same purpose.
+	lastWarnings string
+}
+
+// readConfig reads the AI Gateway configuration, starting or stopping the stdio MCP server proxies, and the stand-in
+// backends of --record and --replay, as needed.
+func (r *configReloader) readConfig(ctx context.Context) (string, error) {
+	mcpServers, err := readMCPServers(r.mcpConfigPath, r.mcpJSON)
+	if err != nil {
+		return "", err
+	}
+	// If any of the configured MCP servers is using stdio, set up the streamable HTTP proxies for them
+	if err = r.stdioProxies.proxyStdioMCPServers(ctx, r.logger, mcpServers); err != nil {
+		return "", fmt.Errorf("failed to proxy stdio for MCP servers: %w", err)
+	}
+	// The warnings are only reported when they change, as the configuration is read on every check.
+	var warnings bytes.Buffer
+	config, err := readConfig(ctx, r.configPath, mcpServers, r.debug, r.models, &warnings)
+	if warnings.String() != r.lastWarnings {
+		r.lastWarnings = warnings.String()
+		_, _ = r.stderr.Write(warnings.Bytes())
+	}
+	if err != nil || r.recorder == nil {
+		return config, err
+	}
+	return r.recorder.rewriteBackends(ctx, config)
+}
+
+// watch periodically checks the configuration for changes until the context is done.
+func (r *configReloader) watch(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.maybeReload(ctx)
+		}
+	}
+}
+
+// maybeReload reloads the configuration if it changed since the last check, and reports the result to stderr.
+func (r *configReloader) maybeReload(ctx context.Context) {
+	config, err := r.readConfig(ctx)
+	if err != nil {
+		// Reset the last configuration so that reverting the change applies it again.
+		r.lastConfig = ""
+		if err.Error() != r.lastErr {
+			r.lastErr = err.Error()
+			r.reportError(err)
+		}
+		return
+	}
+	r.lastErr = ""
+	if config == r.lastConfig {
+		return
+	}
+	r.lastConfig = config
+
+	r.logger.Info("Reloading the configuration")
+	if err = r.apply(ctx, config); err != nil {
+		r.reportError(err)
+		return
+	}
+	r.logger.Info("Reloaded the configuration")
+	_, _ = fmt.Fprintln(r.stderr, "Reloaded the Envoy AI Gateway configuration")
+}
+
+func (r *configReloader) reportError(err error) {
+	r.logger.Error("Failed to reload the configuration", "error", err)
+	_, _ = fmt.Fprintf(r.stderr, "Failed to reload the Envoy AI Gateway configuration, keeping the previous one: %v\n", err)
+}
+
+// apply translates the configuration and writes the resulting resources to the files watched by Envoy Gateway and the
+// external processor. Nothing is written if the configuration is invalid.
+func (r *configReloader) apply(ctx context.Context, config string) error {
+	resourcesBuf := &bytes.Buffer{}
+	runCtx := *r.runCtx
+	runCtx.envoyGatewayResourcesOut = resourcesBuf
+	fakeClient, fc, _, err := runCtx.writeEnvoyResources(ctx, config, true)
+	if err != nil {
+		return err
+	}
+	// Update the extension server client first, as the Envoy Gateway translation triggered by the new resources calls
+	// the extension server.
+	r.k8sClient.set(fakeClient)
+	if _, err = runCtx.writeExtProcConfig(fc); err != nil {
+		return err
+	}
+	if err = writeFileAtomically(r.egResourcesPath, runCtx.tmpdir, resourcesBuf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", r.egResourcesPath, err)
+	}
+	return nil
+}
+
+// reloadableClient is a client.Client whose reads are served by the client holding the resources of the latest
+// configuration. Only the reads are served by the latest client since the extension server doesn't write resources.
+type reloadableClient struct {
+	client.Client
+	current atomic.Pointer[client.Client]
+}
+
+func newReloadableClient(c client.Client) *reloadableClient {
+	r := &reloadableClient{Client: c}
+	r.set(c)
+	return r
+}
+
+// set replaces the client serving the reads.
+func (r *reloadableClient) set(c client.Client) {
+	r.current.Store(&c)
+}
+
+// Get implements [client.Reader.Get].
+func (r *reloadableClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return (*r.current.Load()).Get(ctx, key, obj, opts...)
+}
+
+// List implements [client.Reader.List].
+func (r *reloadableClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return (*r.current.Load()).List(ctx, list, opts...)
+}
@@ -0,0 +1,150 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
+	"github.com/envoyproxy/ai-gateway/internal/controller"
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestConfigReloader_maybeReload(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	t.Setenv("OPENAI_BASE_URL", "http://localhost:11434/v1")
+	t.Setenv("OPENAI_API_KEY", "unused")
+
+	runDir := t.TempDir()
+	configPath := filepath.Join(runDir, "config.yaml")
+	egResourcesPath := filepath.Join(runDir, "envoy-ai-gateway-resources", "config.yaml")
+	extProcConfigPath := filepath.Join(runDir, "extproc-config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(egResourcesPath), 0o755))
+
+	original := readFileFromProjectRoot(t, "examples/aigw/ollama.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o600))
+
+	stderr := &bytes.Buffer{}
+	initialClient := fake.NewClientBuilder().Build()
+	r := &configReloader{
+		runCtx: &runCmdContext{
+			stderrLogger: slog.New(slog.DiscardHandler),
+			stderr:       io.Discard,
+			tmpdir:       runDir,
+		},
+		configPath:      configPath,
+		egResourcesPath: egResourcesPath,
+		k8sClient:       newReloadableClient(initialClient),
+		stdioProxies:    &stdioMCPProxies{},
+		logger:          slog.New(slog.DiscardHandler),
+		stderr:          stderr,
+	}
+	requireRouteTimeout := func(expected gwapiv1.Duration) {
+		var route aigv1b1.AIGatewayRoute
+		require.NoError(t, r.k8sClient.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "aigw-run"}, &route))
+		require.Equal(t, expected, *route.Spec.Rules[0].Timeouts.Request)
+	}
+
+	// The first check applies the configuration.
+	r.maybeReload(t.Context())
+	require.Equal(t, "Reloaded the Envoy AI Gateway configuration\n", stderr.String())
+	requireRouteTimeout("120s")
+	resources, err := os.ReadFile(egResourcesPath)
+	require.NoError(t, err)
+	require.Contains(t, string(resources), "kind: HTTPRoute")
+	fc, err := filterapi.UnmarshalConfigYaml(extProcConfigPath)
+	require.NoError(t, err)
+	require.NotEmpty(t, fc.Backends)
+
+	// Nothing is written when nothing changed.
+	stderr.Reset()
+	require.NoError(t, os.Remove(egResourcesPath))
+	r.maybeReload(t.Context())
+	require.Empty(t, stderr.String())
+	require.NoFileExists(t, egResourcesPath)
+
+	// A changed configuration is applied.
+	changed := strings.Replace(original, "request: 120s", "request: 60s", 1)
+	require.NoError(t, os.WriteFile(configPath, []byte(changed), 0o600))
+	r.maybeReload(t.Context())
+	require.Equal(t, "Reloaded the Envoy AI Gateway configuration\n", stderr.String())
+	requireRouteTimeout("60s")
+	resources, err = os.ReadFile(egResourcesPath)
+	require.NoError(t, err)
+
+	// An invalid configuration is reported once, and the previous configuration is kept.
+	stderr.Reset()
+	invalid := changed + `
+apiVersion: aigateway.envoyproxy.io/v1beta1
+kind: BackendSecurityPolicy
+metadata:
+  name: openai-apikey-duplicate
+  namespace: default
+spec:
+  targetRefs:
+    - group: aigateway.envoyproxy.io
+      kind: AIServiceBackend
+      name: openai
+  type: APIKey
+  apiKey:
+    secretRef:
+      name: openai-apikey
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(invalid), 0o600))
+	r.maybeReload(t.Context())
+	r.maybeReload(t.Context())
+	require.Equal(t, "Failed to reload the Envoy AI Gateway configuration, keeping the previous one: invalid resources:\n"+
+		"AIServiceBackend default/openai: multiple BackendSecurityPolicies found for AIServiceBackend openai: "+
+		"[openai-apikey openai-apikey-duplicate]\n", stderr.String())
+	requireRouteTimeout("60s")
+	current, err := os.ReadFile(egResourcesPath)
+	require.NoError(t, err)
+	require.Equal(t, string(resources), string(current))
+
+	// A missing configuration file is reported once.
+	stderr.Reset()
+	require.NoError(t, os.Remove(configPath))
+	r.maybeReload(t.Context())
+	r.maybeReload(t.Context())
+	require.Equal(t, 1, strings.Count(stderr.String(), "Failed to reload"))
+	require.Contains(t, stderr.String(), "error reading config")
+
+	// Restoring the configuration applies it again.
+	stderr.Reset()
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o600))
+	r.maybeReload(t.Context())
+	require.Equal(t, "Reloaded the Envoy AI Gateway configuration\n", stderr.String())
+	requireRouteTimeout("120s")
+}
+
+func TestReloadableClient(t *testing.T) {
+	route := &aigv1b1.AIGatewayRoute{}
+	route.Name, route.Namespace = "route", "default"
+	first := fake.NewClientBuilder().WithScheme(controller.Scheme).Build()
+	second := fake.NewClientBuilder().WithScheme(controller.Scheme).WithObjects(route).Build()
+
+	c := newReloadableClient(first)
+	key := client.ObjectKey{Namespace: "default", Name: "route"}
+	require.Error(t, c.Get(t.Context(), key, &aigv1b1.AIGatewayRoute{}))
+
+	c.set(second)
+	require.NoError(t, c.Get(t.Context(), key, &aigv1b1.AIGatewayRoute{}))
+	var routes aigv1b1.AIGatewayRouteList
+	require.NoError(t, c.List(t.Context(), &routes))
+	require.Len(t, routes.Items, 1)
+}
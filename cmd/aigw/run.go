@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -149,14 +150,29 @@ func run(ctx context.Context, c *cmdRun, o *runOpts, stdout, stderr io.Writer) e
 		extProcLauncher:                o.extProcLauncher,
 		mcpSessionEncryptionIterations: c.MCPSessionEncryptionIterations,
 	}
-	// If any of the configured MCP servers is using stdio, set up the streamable HTTP proxies for them
-	if err = proxyStdioMCPServers(ctx, debugLogger, c.mcpConfig); err != nil {
-		return fmt.Errorf("failed to proxy stdio for MCP servers: %w", err)
+	reloader := &configReloader{
+		runCtx:          runCtx,
+		configPath:      o.configPath,
+		mcpConfigPath:   c.McpConfig,
+		mcpJSON:         c.McpJSON,
+		debug:           c.Debug,
+		egResourcesPath: o.egResourcesPath,
+		stdioProxies:    &stdioMCPProxies{logDir: filepath.Dir(o.logPath)},
+		logger:          debugLogger,
+		stderr:          stderr,
+	}
+	if reloader.recorder, err = newUpstreamRecorder(c, debugLogger, stderr); err != nil {
+		return err
+	}
+	// The models are not discovered when replaying, as the backends are not contacted.
+	if c.ModelDiscoveryInterval > 0 && c.Replay == "" {
+		reloader.models = newModelDiscovery(c.ModelDiscoveryInterval, debugLogger, stderr)
 	}
-	aiGatewayResourcesYaml, err := readConfig(o.configPath, c.mcpConfig, c.Debug)
+	aiGatewayResourcesYaml, err := reloader.readConfig(ctx)
 	if err != nil {
 		return err
 	}
+	reloader.lastConfig = aiGatewayResourcesYaml
 	fakeClient, extProxDone, listenerPort, err := runCtx.writeEnvoyResourcesAndRunExtProc(ctx, aiGatewayResourcesYaml)
 	if err != nil {
 		return fmt.Errorf("failed to write envoy resources and run extproc: %w", err)
@@ -180,7 +196,9 @@ func run(ctx context.Context, c *cmdRun, o *runOpts, stdout, stderr io.Writer) e
 	quotaRateLimitServiceAddr := "envoy-ai-gateway-ratelimit.envoy-gateway-system"
 	const quotaRateLimitTimeout = 5
 	const quotaRateLimitFailureModeDeny = false
-	extSrv, err := extensionserver.New(fakeClient, ctrl.Log, o.extprocUDSPath, true, requestHeaderAttributes, logRequestHeaderAttributes, quotaRateLimitServiceAddr, quotaRateLimitTimeout, quotaRateLimitFailureModeDeny)
+	// The extension server reads the AI Gateway resources of the latest configuration when it is reloaded.
+	reloader.k8sClient = newReloadableClient(fakeClient)
+	extSrv, err := extensionserver.New(reloader.k8sClient, ctrl.Log, o.extprocUDSPath, true, requestHeaderAttributes, logRequestHeaderAttributes, quotaRateLimitServiceAddr, quotaRateLimitTimeout, quotaRateLimitFailureModeDeny)
 	if err != nil {
 		return err
 	}
@@ -203,6 +221,7 @@ func run(ctx context.Context, c *cmdRun, o *runOpts, stdout, stderr io.Writer) e
 			debugLogger.Error("Failed to run extension server", "error", err)
 		}
 	}()
+	go reloader.watch(serverCtx, configReloadInterval)
 
 	// At this point, we have two things prepared:
 	//  1. The Envoy Gateway config in egConfigPath.
@@ -278,32 +297,53 @@ func recreateDir(path string) error {
 // writeEnvoyResourcesAndRunExtProc reads all resources from the given string, writes them to the output file, and runs
 // external processes for EnvoyExtensionPolicy resources.
 func (runCtx *runCmdContext) writeEnvoyResourcesAndRunExtProc(ctx context.Context, original string) (client.Client, <-chan error, int, error) {
+	fakeClient, fc, gw, err := runCtx.writeEnvoyResources(ctx, original, false)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	runCtx.stderrLogger.Info("Running external process", "config", fc)
+	done := runCtx.mustStartExtProc(ctx, fc)
+	return fakeClient, done, runCtx.tryFindEnvoyListenerPort(gw), nil
+}
+
+// writeEnvoyResources reads all resources from the given string, translates them and writes the Envoy Gateway
+// resources to the output file. It returns the client holding the translated resources, the filter configuration of the
+// external processor, and the Gateway.
+//
+// When validate is true, the errors reported in the status conditions of the AI Gateway resources are returned instead
+// of panicking on the first reconciliation error.
+func (runCtx *runCmdContext) writeEnvoyResources(ctx context.Context, original string, validate bool) (client.Client, *filterapi.Config, *gwapiv1.Gateway, error) {
 	aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSPolicies, gateways, secrets, _, err := collectObjects(original, runCtx.envoyGatewayResourcesOut, runCtx.stderrLogger)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error collecting: %w", err)
+		return nil, nil, nil, fmt.Errorf("error collecting: %w", err)
 	}
 	if len(gateways) > 1 {
-		return nil, nil, 0, fmt.Errorf("multiple gateways are not supported: %s", gateways[0].Name)
+		return nil, nil, nil, fmt.Errorf("multiple gateways are not supported: %s", gateways[0].Name)
 	}
 	for _, bsp := range backendSecurityPolicies {
 		spec := bsp.Spec
 		if spec.AWSCredentials != nil && spec.AWSCredentials.OIDCExchangeToken != nil {
 			// TODO: We can make it work by generalizing the rotation logic.
-			return nil, nil, 0, fmt.Errorf("OIDC exchange token is not supported: %s", bsp.Name)
+			return nil, nil, nil, fmt.Errorf("OIDC exchange token is not supported: %s", bsp.Name)
 		}
 	}
 
 	// Do the substitution for the secrets.
 	for _, s := range secrets {
 		if err = runCtx.rewriteSecretWithAnnotatedLocation(s); err != nil {
-			return nil, nil, 0, fmt.Errorf("failed to rewrite secret %s: %w", s.Name, err)
+			return nil, nil, nil, fmt.Errorf("failed to rewrite secret %s: %w", s.Name, err)
 		}
 	}
 
 	var secretList *corev1.SecretList
-	fakeClient, _fakeClientSet, httpRoutes, eps, httpRouteFilters, backends, secretList, backendTrafficPolicies, securityPolicies, err := translateCustomResourceObjects(ctx, aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSPolicies, gateways, secrets, runCtx.stderrLogger)
+	fakeClient, _fakeClientSet, httpRoutes, eps, httpRouteFilters, backends, secretList, backendTrafficPolicies, securityPolicies, err := translateCustomResourceObjects(ctx, aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSPolicies, gateways, secrets, runCtx.stderrLogger, validate)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error translating: %w", err)
+		return nil, nil, nil, fmt.Errorf("error translating: %w", err)
+	}
+	if validate {
+		if err = validateTranslatedResources(ctx, fakeClient); err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid resources:\n%w", err)
+		}
 	}
 	runCtx.fakeClientSet = _fakeClientSet
 
@@ -331,9 +371,12 @@ func (runCtx *runCmdContext) writeEnvoyResourcesAndRunExtProc(ctx context.Contex
 		sp := &securityPolicies.Items[i]
 		runCtx.mustClearSetOwnerReferencesAndStatusAndWriteObj(&sp.TypeMeta, sp)
 	}
+	if len(gateways) == 0 {
+		return nil, nil, nil, errors.New("no gateway is configured")
+	}
 	gw := gateways[0]
 	if len(gw.Spec.Listeners) == 0 {
-		return nil, nil, 0, fmt.Errorf("gateway %s has no listeners configured", gw.Name)
+		return nil, nil, nil, fmt.Errorf("gateway %s has no listeners configured", gw.Name)
 	}
 	runCtx.mustClearSetOwnerReferencesAndStatusAndWriteObj(&gw.TypeMeta, gw)
 	for i := range eps.Items {
@@ -345,20 +388,18 @@ func (runCtx *runCmdContext) writeEnvoyResourcesAndRunExtProc(ctx context.Contex
 		Secrets("").Get(ctx,
 		controller.FilterConfigSecretPerGatewayName(gw.Name, gw.Namespace), metav1.GetOptions{})
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("failed to get filter config secret: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get filter config secret: %w", err)
 	}
 
 	rawConfig, ok := filterConfigSecret.StringData[controller.FilterConfigKeyInSecret]
 	if !ok {
-		return nil, nil, 0, fmt.Errorf("failed to get filter config from secret: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get filter config from secret: %w", err)
 	}
 	var fc filterapi.Config
 	if err = yaml.Unmarshal([]byte(rawConfig), &fc); err != nil {
-		return nil, nil, 0, fmt.Errorf("failed to unmarshal filter config: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to unmarshal filter config: %w", err)
 	}
-	runCtx.stderrLogger.Info("Running external process", "config", fc)
-	done := runCtx.mustStartExtProc(ctx, &fc)
-	return fakeClient, done, runCtx.tryFindEnvoyListenerPort(gw), nil
+	return fakeClient, &fc, gw, nil
 }
 
 // mustStartExtProc starts the external process with the given working directory, port, and filter configuration.
@@ -366,15 +407,9 @@ func (runCtx *runCmdContext) mustStartExtProc(
 	ctx context.Context,
 	filterCfg *filterapi.Config,
 ) <-chan error {
-	marshaled, err := yaml.Marshal(filterCfg)
-	if err != nil {
-		panic(fmt.Sprintf("BUG: failed to marshal filter config: %v", err))
-	}
-	configPath := filepath.Join(runCtx.tmpdir, "extproc-config.yaml")
-	_ = os.Remove(configPath)
-	err = os.WriteFile(configPath, marshaled, 0o600)
+	configPath, err := runCtx.writeExtProcConfig(filterCfg)
 	if err != nil {
-		panic(fmt.Sprintf("BUG: failed to write extension proc config: %v", err))
+		panic(fmt.Sprintf("BUG: %v", err))
 	}
 	args := []string{
 		"--configPath", configPath,
@@ -413,6 +448,38 @@ func (runCtx *runCmdContext) mustStartExtProc(
 	return done
 }
 
+// writeExtProcConfig writes the filter configuration to the file watched by the external processor, and returns the
+// path of the file.
+func (runCtx *runCmdContext) writeExtProcConfig(filterCfg *filterapi.Config) (string, error) {
+	marshaled, err := yaml.Marshal(filterCfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal filter config: %w", err)
+	}
+	configPath := filepath.Join(runCtx.tmpdir, "extproc-config.yaml")
+	if err = writeFileAtomically(configPath, runCtx.tmpdir, marshaled); err != nil {
+		return "", fmt.Errorf("failed to write extension proc config: %w", err)
+	}
+	return configPath, nil
+}
+
+// writeFileAtomically writes the data to a temporary file in tmpdir and renames it to path, so that the watchers of
+// path never read a partially written file. tmpdir must be on the same file system as path.
+func writeFileAtomically(path, tmpdir string, data []byte) error {
+	f, err := os.CreateTemp(tmpdir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
 func envOptional(name string) *string {
 	if value, ok := os.LookupEnv(name); ok {
 		return &value
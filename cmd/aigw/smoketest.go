@@ -0,0 +1,382 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+// defaultGatewayURL is the URL of the gateway started by `aigw run` with the default configuration.
+const defaultGatewayURL = "http://localhost:1975"
+
+// smokeTestOutputJSON is the JSON output format of `aigw test`. The default output format is a table.
+const smokeTestOutputJSON = "json"
+
+// smokeTestEndpoint is an endpoint of the gateway tested by `aigw test`.
+type smokeTestEndpoint struct {
+	name string
+	path string
+	// headers are the headers required by the endpoint, if any.
+	headers map[string]string
+	// body returns the minimal request body for the given model.
+	body func(model string) any
+}
+
+var (
+	smokeTestChat = smokeTestEndpoint{name: "chat", path: "/v1/chat/completions", body: func(model string) any {
+		return map[string]any{
+			"model":    model,
+			"messages": []map[string]string{{"role": "user", "content": "Reply with the single word: pong"}},
+		}
+	}}
+	smokeTestMessages = smokeTestEndpoint{
+		name:    "messages",
+		path:    "/anthropic/v1/messages",
+		headers: map[string]string{"anthropic-version": "2023-06-01"},
+		body: func(model string) any {
+			return map[string]any{
+				"model":      model,
+				"max_tokens": 16,
+				"messages":   []map[string]string{{"role": "user", "content": "Reply with the single word: pong"}},
+			}
+		},
+	}
+	smokeTestEmbeddings = smokeTestEndpoint{name: "embeddings", path: "/v1/embeddings", body: func(model string) any {
+		return map[string]any{"model": model, "input": "pong"}
+	}}
+	smokeTestRerank = smokeTestEndpoint{name: "rerank", path: "/cohere/v2/rerank", body: func(model string) any {
+		return map[string]any{
+			"model":     model,
+			"query":     "What is Envoy?",
+			"documents": []string{"Envoy is an edge and service proxy.", "Go is a programming language."},
+		}
+	}}
+)
+
+// smokeTestTarget is a model routed by a rule of an AIGatewayRoute, and the endpoint used to test it.
+type smokeTestTarget struct {
+	route    string
+	backends []string
+	model    string
+	endpoint smokeTestEndpoint
+}
+
+// smokeTestResult is the result of the request sent for a smokeTestTarget.
+type smokeTestResult struct {
+	Route         string   `json:"route"`
+	Backends      []string `json:"backends"`
+	Model         string   `json:"model"`
+	Endpoint      string   `json:"endpoint"`
+	Status        int      `json:"status,omitempty"`
+	LatencyMs     int64    `json:"latencyMs"`
+	InputTokens   int      `json:"inputTokens"`
+	OutputTokens  int      `json:"outputTokens"`
+	ResponseModel string   `json:"responseModel,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// smokeTestResponse holds the fields of the responses of all the tested endpoints needed for the results.
+type smokeTestResponse struct {
+	Model string `json:"model"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		InputTokens      int `json:"input_tokens"`
+		OutputTokens     int `json:"output_tokens"`
+	} `json:"usage"`
+	Meta struct {
+		Tokens struct {
+			InputTokens  float64 `json:"input_tokens"`
+			OutputTokens float64 `json:"output_tokens"`
+		} `json:"tokens"`
+	} `json:"meta"`
+}
+
+// smokeTestCmd implements the `aigw test` command. It reads the configuration of the running gateway, sends a minimal
+// request for each model routed by its AIGatewayRoutes, and writes the results in the requested format.
+//
+// It returns an error when any request failed, so that it can be used in CI.
+func smokeTestCmd(ctx context.Context, c *cmdTest, stdout, stderr io.Writer) error {
+	// The backend models are not discovered: only the models routed by their exact names and the --model ones are tested.
+	config, err := readConfig(ctx, c.Path, nil, false, nil, stderr)
+	if err != nil {
+		return err
+	}
+	routes, _, backends, _, _, gateways, _, _, err := collectObjects(config, io.Discard, slog.New(slog.DiscardHandler))
+	if err != nil {
+		return fmt.Errorf("error reading the configuration: %w", err)
+	}
+	targets, err := smokeTestTargets(routes, backends, c.Models, stderr)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no model to test: the AIGatewayRoutes match no model by its exact name, use --model to test one")
+	}
+
+	baseURL := c.URL
+	if baseURL == "" {
+		baseURL = gatewayURL(gateways)
+	}
+	client := &http.Client{Timeout: c.Timeout}
+	results := make([]smokeTestResult, 0, len(targets))
+	var failed int
+	for _, target := range targets {
+		result := runSmokeTest(ctx, client, strings.TrimSuffix(baseURL, "/"), target)
+		if result.Error != "" {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if err = writeSmokeTestResults(stdout, results, c.Output); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d requests failed", failed, len(results))
+	}
+	return nil
+}
+
+// smokeTestTargets returns the models routed by the rules of the given AIGatewayRoutes, in order: the ones each rule
+// matches by their exact names, and the given extra models, which are tested through the first rule matching them.
+//
+// The rules not matching any model are reported to stderr.
+func smokeTestTargets(routes []*aigv1b1.AIGatewayRoute, backends []*aigv1b1.AIServiceBackend, extraModels []string, stderr io.Writer) ([]smokeTestTarget, error) {
+	schemas := make(map[string]aigv1b1.APISchema, len(backends))
+	for _, b := range backends {
+		schemas[b.Namespace+"/"+b.Name] = b.Spec.APISchema.Name
+	}
+
+	// The rules each extra model is routed to, by rule.
+	extraByRule := make(map[*aigv1b1.AIGatewayRouteRule][]string)
+	for _, model := range extraModels {
+		rule, err := matchingRule(routes, model)
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil {
+			return nil, fmt.Errorf("no AIGatewayRoute rule matches the model %q", model)
+		}
+		extraByRule[rule] = append(extraByRule[rule], model)
+	}
+
+	var targets []smokeTestTarget
+	seen := make(map[string]struct{})
+	for _, route := range routes {
+		for i := range route.Spec.Rules {
+			rule := &route.Spec.Rules[i]
+			ruleBackends := make([]string, 0, len(rule.BackendRefs))
+			var schema aigv1b1.APISchema
+			for j, ref := range rule.BackendRefs {
+				ruleBackends = append(ruleBackends, ref.Name)
+				if j == 0 && (ref.Kind == nil || *ref.Kind == "AIServiceBackend") {
+					namespace := route.Namespace
+					if ref.Namespace != nil {
+						namespace = string(*ref.Namespace)
+					}
+					schema = schemas[namespace+"/"+ref.Name]
+				}
+			}
+
+			models := append(exactModels(rule), extraByRule[rule]...)
+			if len(models) == 0 {
+				_, _ = fmt.Fprintf(stderr, "Skipping the rule %d of the AIGatewayRoute %s/%s, which matches no model by its "+
+					"exact name. Use --model to test it.\n", i, route.Namespace, route.Name)
+			}
+			for _, model := range models {
+				// A model matched by several rules is only routed to the first one.
+				if _, ok := seen[model]; ok {
+					continue
+				}
+				seen[model] = struct{}{}
+				targets = append(targets, smokeTestTarget{
+					route:    route.Namespace + "/" + route.Name,
+					backends: ruleBackends,
+					model:    model,
+					endpoint: endpointForSchema(schema, model),
+				})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// exactModels returns the models the given rule matches by their exact names.
+func exactModels(rule *aigv1b1.AIGatewayRouteRule) []string {
+	var models []string
+	for _, match := range rule.Matches {
+		for _, h := range match.Headers {
+			if string(h.Name) == aigv1b1.AIModelHeaderKey && (h.Type == nil || *h.Type == gwapiv1.HeaderMatchExact) {
+				models = append(models, h.Value)
+			}
+		}
+	}
+	return models
+}
+
+// matchingRule returns the first rule of the given AIGatewayRoutes whose model header match matches the given model,
+// or nil if none does.
+func matchingRule(routes []*aigv1b1.AIGatewayRoute, model string) (*aigv1b1.AIGatewayRouteRule, error) {
+	for _, route := range routes {
+		for i := range route.Spec.Rules {
+			rule := &route.Spec.Rules[i]
+			for _, match := range rule.Matches {
+				for _, h := range match.Headers {
+					if string(h.Name) != aigv1b1.AIModelHeaderKey {
+						continue
+					}
+					if h.Type != nil && *h.Type == gwapiv1.HeaderMatchRegularExpression {
+						// Envoy matches the whole header value.
+						re, err := regexp.Compile("^(?:" + h.Value + ")$")
+						if err != nil {
+							return nil, fmt.Errorf("invalid model regular expression in the AIGatewayRoute %s/%s: %w",
+								route.Namespace, route.Name, err)
+						}
+						if re.MatchString(model) {
+							return rule, nil
+						}
+					} else if h.Value == model {
+						return rule, nil
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// endpointForSchema returns the endpoint to test a model routed to a backend with the given schema.
+func endpointForSchema(schema aigv1b1.APISchema, model string) smokeTestEndpoint {
+	switch {
+	case schema == aigv1b1.APISchemaAnthropic || schema == aigv1b1.APISchemaGCPAnthropic || schema == aigv1b1.APISchemaAWSAnthropic:
+		return smokeTestMessages
+	case schema == aigv1b1.APISchemaCohere:
+		return smokeTestRerank
+	case strings.Contains(strings.ToLower(model), "embed"):
+		return smokeTestEmbeddings
+	default:
+		return smokeTestChat
+	}
+}
+
+// gatewayURL returns the URL of the first HTTP listener of the given Gateways, or the default one.
+func gatewayURL(gateways []*gwapiv1.Gateway) string {
+	for _, gw := range gateways {
+		for _, l := range gw.Spec.Listeners {
+			if l.Protocol == gwapiv1.HTTPProtocolType {
+				return fmt.Sprintf("http://localhost:%d", l.Port)
+			}
+		}
+	}
+	return defaultGatewayURL
+}
+
+// runSmokeTest sends the minimal request of the endpoint of the given target and returns its result.
+func runSmokeTest(ctx context.Context, client *http.Client, baseURL string, target smokeTestTarget) smokeTestResult {
+	result := smokeTestResult{
+		Route:    target.route,
+		Backends: target.backends,
+		Model:    target.model,
+		Endpoint: target.endpoint.name,
+	}
+	body, err := json.Marshal(target.endpoint.body(target.model))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+target.endpoint.path, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range target.endpoint.headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.LatencyMs = time.Since(start).Milliseconds()
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.Status = resp.StatusCode
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read the response: %v", err)
+		return result
+	}
+	if resp.StatusCode != http.StatusOK {
+		result.Error = fmt.Sprintf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+		return result
+	}
+
+	var parsed smokeTestResponse
+	if err = json.Unmarshal(respBody, &parsed); err != nil {
+		result.Error = fmt.Sprintf("failed to unmarshal the response: %v", err)
+		return result
+	}
+	result.ResponseModel = parsed.Model
+	result.InputTokens = parsed.Usage.PromptTokens + parsed.Usage.InputTokens + int(parsed.Meta.Tokens.InputTokens)
+	result.OutputTokens = parsed.Usage.CompletionTokens + parsed.Usage.OutputTokens + int(parsed.Meta.Tokens.OutputTokens)
+	return result
+}
+
+// writeSmokeTestResults writes the results as a table, followed by the errors of the failed requests, or as JSON.
+func writeSmokeTestResults(w io.Writer, results []smokeTestResult, output string) error {
+	if output == smokeTestOutputJSON {
+		out, err := json.MarshalIndentSortedKeys(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the results: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ROUTE\tBACKENDS\tMODEL\tENDPOINT\tSTATUS\tLATENCY\tINPUT TOKENS\tOUTPUT TOKENS\tRESPONSE MODEL")
+	for _, r := range results {
+		status, responseModel := "error", "-"
+		if r.Status != 0 {
+			status = fmt.Sprint(r.Status)
+		}
+		if r.ResponseModel != "" {
+			responseModel = r.ResponseModel
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%dms\t%d\t%d\t%s\n", r.Route, strings.Join(r.Backends, ","), r.Model,
+			r.Endpoint, status, r.LatencyMs, r.InputTokens, r.OutputTokens, responseModel)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	failed := slices.DeleteFunc(slices.Clone(results), func(r smokeTestResult) bool { return r.Error == "" })
+	if len(failed) > 0 {
+		_, _ = fmt.Fprintln(w, "\nErrors:")
+	}
+	for _, r := range failed {
+		_, _ = fmt.Fprintf(w, "  %s (%s): %s\n", r.Model, r.Endpoint, r.Error)
+	}
+	return nil
+}
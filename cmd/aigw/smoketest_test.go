@@ -0,0 +1,248 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+const smokeTestConfig = `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: aigw-run
+  namespace: default
+spec:
+  gatewayClassName: aigw-run
+  listeners:
+    - name: http
+      protocol: HTTP
+      port: 1976
+---
+apiVersion: aigateway.envoyproxy.io/v1beta1
+kind: AIGatewayRoute
+metadata:
+  name: aigw-run
+  namespace: default
+spec:
+  parentRefs:
+    - name: aigw-run
+  rules:
+    - matches:
+        - headers:
+            - type: Exact
+              name: x-ai-eg-model
+              value: gpt-4.1
+        - headers:
+            - type: Exact
+              name: x-ai-eg-model
+              value: text-embedding-3-small
+      backendRefs:
+        - name: openai
+    - matches:
+        - headers:
+            - type: Exact
+              name: x-ai-eg-model
+              value: claude-sonnet-4-5
+      backendRefs:
+        - name: anthropic
+    - matches:
+        - headers:
+            - type: Exact
+              name: x-ai-eg-model
+              value: rerank-v3.5
+      backendRefs:
+        - name: cohere
+    - matches:
+        - headers:
+            - type: RegularExpression
+              name: x-ai-eg-model
+              value: o[0-9]+
+      backendRefs:
+        - name: openai
+---
+apiVersion: aigateway.envoyproxy.io/v1beta1
+kind: AIServiceBackend
+metadata:
+  name: openai
+  namespace: default
+spec:
+  schema:
+    name: OpenAI
+---
+apiVersion: aigateway.envoyproxy.io/v1beta1
+kind: AIServiceBackend
+metadata:
+  name: anthropic
+  namespace: default
+spec:
+  schema:
+    name: Anthropic
+---
+apiVersion: aigateway.envoyproxy.io/v1beta1
+kind: AIServiceBackend
+metadata:
+  name: cohere
+  namespace: default
+spec:
+  schema:
+    name: Cohere
+`
+
+// newSmokeTestGateway returns a server responding to the requests of `aigw test` like the gateway would.
+func newSmokeTestGateway(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &req))
+		if req.Model == "o3" {
+			http.Error(w, `{"error":{"message":"model not found"}}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"model":"gpt-4.1-2025-04-14","choices":[{"message":{"role":"assistant","content":"pong"}}],
+			"usage":{"prompt_tokens":12,"completion_tokens":2,"total_tokens":14}}`))
+	})
+	mux.HandleFunc("POST /v1/embeddings", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"list","model":"text-embedding-3-small","data":[],"usage":{"prompt_tokens":1,"total_tokens":1}}`))
+	})
+	mux.HandleFunc("POST /anthropic/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			MaxTokens int `json:"max_tokens"`
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &req))
+		require.Positive(t, req.MaxTokens)
+		// The Anthropic backend of the generated configuration is only routed the requests with this header.
+		require.NotEmpty(t, r.Header.Get("anthropic-version"))
+		_, _ = w.Write([]byte(`{"model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"pong"}],
+			"usage":{"input_tokens":15,"output_tokens":4}}`))
+	})
+	mux.HandleFunc("POST /cohere/v2/rerank", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"index":0,"relevance_score":0.9}],"meta":{"tokens":{"input_tokens":20}}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSmokeTestCmd(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	server := newSmokeTestGateway(t)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(smokeTestConfig), 0o600))
+
+	t.Run("table", func(t *testing.T) {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		err := smokeTestCmd(t.Context(), &cmdTest{Path: configPath, URL: server.URL + "/", Timeout: time.Minute, Output: "table"}, stdout, stderr)
+		require.NoError(t, err)
+		// The latencies vary between runs, and so does their padding.
+		out := regexp.MustCompile(`\d+ms +`).ReplaceAllString(stdout.String(), "1ms      ")
+		require.Equal(t, `ROUTE             BACKENDS   MODEL                   ENDPOINT    STATUS  LATENCY  INPUT TOKENS  OUTPUT TOKENS  RESPONSE MODEL
+default/aigw-run  openai     gpt-4.1                 chat        200     1ms      12            2              gpt-4.1-2025-04-14
+default/aigw-run  openai     text-embedding-3-small  embeddings  200     1ms      1             0              text-embedding-3-small
+default/aigw-run  anthropic  claude-sonnet-4-5       messages    200     1ms      15            4              claude-sonnet-4-5-20250929
+default/aigw-run  cohere     rerank-v3.5             rerank      200     1ms      20            0              -
+`, out)
+		require.Equal(t, "Skipping the rule 3 of the AIGatewayRoute default/aigw-run, which matches no model by its exact name. "+
+			"Use --model to test it.\n", stderr.String())
+	})
+
+	t.Run("json with a failed model", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		err := smokeTestCmd(t.Context(), &cmdTest{Path: configPath, URL: server.URL, Models: []string{"o3"}, Timeout: time.Minute, Output: "json"}, stdout, io.Discard)
+		require.EqualError(t, err, "1 of the 5 requests failed")
+		var results []smokeTestResult
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+		require.Len(t, results, 5)
+		failed := results[4]
+		failed.LatencyMs = 0
+		require.Equal(t, smokeTestResult{
+			Route:    "default/aigw-run",
+			Backends: []string{"openai"},
+			Model:    "o3",
+			Endpoint: "chat",
+			Status:   http.StatusNotFound,
+			Error:    `unexpected status code 404: {"error":{"message":"model not found"}}`,
+		}, failed)
+	})
+
+	t.Run("errors in the table", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		err := smokeTestCmd(t.Context(), &cmdTest{Path: configPath, URL: server.URL, Models: []string{"o3"}, Timeout: time.Minute}, stdout, io.Discard)
+		require.Error(t, err)
+		require.Contains(t, stdout.String(), "\nErrors:\n  o3 (chat): unexpected status code 404: "+
+			`{"error":{"message":"model not found"}}`+"\n")
+	})
+
+	t.Run("configuration generated from the environment", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "unused")
+		t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+		stdout := &bytes.Buffer{}
+		err := smokeTestCmd(t.Context(), &cmdTest{URL: server.URL, Timeout: time.Minute, Output: "json"}, stdout, io.Discard)
+		// The backend models are not discovered, so the generated rules match no model by its exact name.
+		require.EqualError(t, err, "no model to test: the AIGatewayRoutes match no model by its exact name, use --model to test one")
+
+		stdout.Reset()
+		err = smokeTestCmd(t.Context(), &cmdTest{URL: server.URL, Timeout: time.Minute, Output: "json", Models: []string{"gpt-4.1"}}, stdout, io.Discard)
+		require.NoError(t, err)
+		var results []smokeTestResult
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+		require.Len(t, results, 1)
+		require.Equal(t, "gpt-4.1", results[0].Model)
+		require.Equal(t, []string{"openai"}, results[0].Backends)
+	})
+
+	t.Run("unrouted model", func(t *testing.T) {
+		err := smokeTestCmd(t.Context(), &cmdTest{Path: configPath, URL: server.URL, Models: []string{"gemini-2.5-pro"}}, io.Discard, io.Discard)
+		require.EqualError(t, err, `no AIGatewayRoute rule matches the model "gemini-2.5-pro"`)
+	})
+
+	t.Run("unreachable gateway", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		err := smokeTestCmd(t.Context(), &cmdTest{Path: configPath, Timeout: time.Second, Output: "json"}, stdout, io.Discard)
+		require.EqualError(t, err, "4 of the 4 requests failed")
+		var results []smokeTestResult
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+		// The requests are sent to the listener of the Gateway.
+		require.Contains(t, results[0].Error, "http://localhost:1976/v1/chat/completions")
+		require.Zero(t, results[0].Status)
+	})
+}
+
+func TestEndpointForSchema(t *testing.T) {
+	require.Equal(t, "messages", endpointForSchema("AWSAnthropic", "claude-sonnet-4-5").name)
+	require.Equal(t, "rerank", endpointForSchema("Cohere", "rerank-v3.5").name)
+	require.Equal(t, "embeddings", endpointForSchema("OpenAI", "nomic-embed-text").name)
+	require.Equal(t, "chat", endpointForSchema("AWSBedrock", "amazon.nova-pro-v1:0").name)
+	require.Equal(t, "chat", endpointForSchema("", "qwen3:0.6b").name)
+}
+
+func TestGatewayURL(t *testing.T) {
+	require.Equal(t, "http://localhost:1975", gatewayURL(nil))
+	gw := &gwapiv1.Gateway{Spec: gwapiv1.GatewaySpec{Listeners: []gwapiv1.Listener{
+		{Protocol: gwapiv1.HTTPSProtocolType, Port: 443},
+		{Protocol: gwapiv1.HTTPProtocolType, Port: 8080},
+	}}}
+	require.Equal(t, "http://localhost:8080", gatewayURL([]*gwapiv1.Gateway{gw}))
+}
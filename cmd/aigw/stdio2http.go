@@ -9,74 +9,141 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
+	"maps"
 	"net"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/envoyproxy/ai-gateway/internal/autoconfig"
+	"github.com/envoyproxy/ai-gateway/internal/json"
 )
 
+// stdioMCPProxies keeps track of the running stdio2http proxies, so that the stdio MCP servers that didn't change are
+// kept running when the MCP servers configuration is reloaded.
+type stdioMCPProxies struct {
+	// logDir is the directory where the stderr of the stdio MCP servers is written, or empty to discard it.
+	logDir  string
+	running map[string]*stdioMCPProxy
+}
+
+// stdioMCPProxy is a running stdio2http proxy of a stdio MCP server.
+type stdioMCPProxy struct {
+	config  autoconfig.MCPServer
+	address string
+	stop    context.CancelFunc
+}
+
 // proxyStdioMCPServers runs the configured stdio MCP servers and starts a Streamable HTTP proxy
 // for each, updating the MCPServers in place.
-func proxyStdioMCPServers(ctx context.Context, logger *slog.Logger, mcpServers *autoconfig.MCPServers) error {
-	if mcpServers == nil {
-		return nil
+//
+// The proxies of the servers that are no longer configured, or whose command changed, are stopped.
+func (p *stdioMCPProxies) proxyStdioMCPServers(ctx context.Context, logger *slog.Logger, mcpServers *autoconfig.MCPServers) error {
+	if p.running == nil {
+		p.running = make(map[string]*stdioMCPProxy)
 	}
-	for name, mcpServer := range mcpServers.McpServers {
-		if mcpServer.Command != "" {
-			address, err := runStdio2HTTPProxy(ctx, logger, name, mcpServer.Command, mcpServer.Args...)
+	var servers map[string]autoconfig.MCPServer
+	if mcpServers != nil {
+		servers = mcpServers.McpServers
+	}
+	for name, proxy := range p.running {
+		if s, ok := servers[name]; !ok || !sameStdioCommand(s, proxy.config) {
+			logger.Info("stopping stdio2http MCP proxy", "name", name)
+			proxy.stop()
+			delete(p.running, name)
+		}
+	}
+	for name, mcpServer := range servers {
+		if mcpServer.Command == "" {
+			continue
+		}
+		proxy, ok := p.running[name]
+		if !ok {
+			var stderrPath string
+			if p.logDir != "" {
+				stderrPath = filepath.Join(p.logDir, "mcp-"+name+".stderr.log")
+			}
+			proxyCtx, stop := context.WithCancel(ctx)
+			address, err := runStdio2HTTPProxy(proxyCtx, logger, name, mcpServer, stderrPath)
 			if err != nil {
+				stop()
 				return err
 			}
-			mcpServers.McpServers[name] = autoconfig.MCPServer{
-				Type:         "http",
-				URL:          address,
-				Headers:      mcpServer.Headers,
-				IncludeTools: mcpServer.IncludeTools,
-			}
+			proxy = &stdioMCPProxy{config: mcpServer, address: address, stop: stop}
+			p.running[name] = proxy
+		}
+		mcpServers.McpServers[name] = autoconfig.MCPServer{
+			Type:         "http",
+			URL:          proxy.address,
+			Headers:      mcpServer.Headers,
+			IncludeTools: mcpServer.IncludeTools,
 		}
 	}
 	return nil
 }
 
+// sameStdioCommand returns true if the given stdio MCP servers run the same command in the same environment.
+func sameStdioCommand(a, b autoconfig.MCPServer) bool {
+	return a.Command == b.Command && slices.Equal(a.Args, b.Args) && maps.Equal(a.Env, b.Env) && a.Cwd == b.Cwd
+}
+
+// The delays before restarting a stdio MCP server that exited. The delay doubles on each consecutive restart, up to
+// the maximum, and is reset once the server ran for longer than the maximum.
+var (
+	stdioMCPInitialRestartBackoff = time.Second
+	stdioMCPMaxRestartBackoff     = 30 * time.Second
+)
+
 // runStdio2HTTPProxy runs a Streamable HTTP MCP proxy that connects to a stdio MCP server.
 // It starts the command, connects to its stdio as an MCP transport, and
 // exposes a Streamable HTTP server that proxies requests to the stdio MCP session.
-func runStdio2HTTPProxy(ctx context.Context, logger *slog.Logger, name, command string, args ...string) (string, error) {
-	// Initialize the command to run the stdio MCP server.
-	cmd := exec.Command(command, args...)
-	transport := &mcp.CommandTransport{Command: cmd}
-	client := mcp.NewClient(&mcp.Implementation{Name: "stdio2http-" + name}, nil)
+//
+// The proxy is bidirectional: the notifications and the requests of the stdio MCP server, such as progress, logging,
+// sampling, and elicitation, are relayed to the clients of the Streamable HTTP server.
+//
+// The command is restarted when it exits, and the stderr of the command is appended to the file at stderrPath, if set.
+func runStdio2HTTPProxy(ctx context.Context, logger *slog.Logger, name string, config autoconfig.MCPServer, stderrPath string) (string, error) {
+	s := &stdioMCPServer{
+		name:       name,
+		config:     config,
+		stderrPath: stderrPath,
+		logger:     logger,
+		backoff:    stdioMCPInitialRestartBackoff,
+	}
+	// Create an MCP server that proxies requests to the MCP session.
+	s.server = mcp.NewServer(&mcp.Implementation{Name: "stdio2http-" + name}, &mcp.ServerOptions{
+		SubscribeHandler:   s.subscribe,
+		UnsubscribeHandler: s.unsubscribe,
+	})
 	// This will start the configured command in the background and connect to its
 	// stdio as an MCP transport.
-	logger.Info("starting stdio2http MCP proxy command", "name", name, "command", command, "args", args)
-	cs, err := client.Connect(ctx, transport, nil)
+	cs, err := s.start(ctx)
 	if err != nil {
-		return "", fmt.Errorf("running the %s stdio2http proxy command: %w", name, err)
-	}
-
-	// Create an HTTP server that proxies requests to the MCP session.
-	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "stdio2http-" + name}, nil)
-	if err = errors.Join(
-		proxyTools(ctx, cs, mcpServer),
-		proxyResources(ctx, cs, mcpServer),
-		proxyPrompts(ctx, cs, mcpServer),
-	); err != nil {
-		return "", fmt.Errorf("proxying features: %w", err)
+		return "", err
 	}
+	go s.supervise(ctx, cs)
 
 	// Find a free port to listen on.
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
+		_ = cs.Close()
 		return "", fmt.Errorf("getting a free port for the %s stdio2http proxy: %w", name, err)
 	}
 	mcpAddress := fmt.Sprintf("http://localhost:%d/mcp", listener.Addr().(*net.TCPAddr).Port)
 
-	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return mcpServer }, nil)
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s.server }, nil)
 	server := &http.Server{
 		Handler:           handler,
 		ReadHeaderTimeout: 120 * time.Second,
@@ -94,13 +161,15 @@ func runStdio2HTTPProxy(ctx context.Context, logger *slog.Logger, name, command
 		<-ctx.Done()
 		logger.Info("shutting down stdio2http MCP proxy", "name", name)
 		// Terminate the command process.
-		if err = cs.Close(); err != nil {
-			logger.Error("stdio2http MCP proxy command shutdown error", "name", name, "error", err)
+		if cs := s.session.Load(); cs != nil {
+			if err := cs.Close(); err != nil {
+				logger.Error("stdio2http MCP proxy command shutdown error", "name", name, "error", err)
+			}
 		}
 		// Shutdown the HTTP server.
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		if err = server.Shutdown(shutdownCtx); err != nil {
+		if err := server.Shutdown(shutdownCtx); err != nil {
 			logger.Error("stdio2http MCP proxy server shutdown error", "name", name, "error", err)
 		}
 	}()
@@ -108,75 +177,510 @@ func runStdio2HTTPProxy(ctx context.Context, logger *slog.Logger, name, command
 	return mcpAddress, nil
 }
 
-// proxyTools proxies tool calls to the stdio MCP client session.
-func proxyTools(ctx context.Context, cs *mcp.ClientSession, server *mcp.Server) error {
-	if cs.InitializeResult().Capabilities.Tools == nil {
-		return nil
-	}
+// stdioMCPServer runs the command of a stdio MCP server and proxies the features of its current session to an MCP
+// server. The features are synced when the command starts, and when it notifies that they changed.
+type stdioMCPServer struct {
+	name       string
+	config     autoconfig.MCPServer
+	stderrPath string
+	logger     *slog.Logger
+	// backoff is the initial delay before restarting the command.
+	backoff time.Duration
+	server  *mcp.Server
+	// session is the session of the running command, or nil while the command is restarting.
+	session atomic.Pointer[mcp.ClientSession]
+
+	// featuresMu guards the names of the features currently proxied to the server.
+	featuresMu        sync.Mutex
+	tools             map[string]struct{}
+	prompts           map[string]struct{}
+	resources         map[string]struct{}
+	resourceTemplates map[string]struct{}
+
+	// requestsMu guards the requests of the clients being forwarded to the command.
+	requestsMu    sync.Mutex
+	lastRequestID uint64
+	requests      map[uint64]*forwardedRequest
+
+	// subscriptionsMu guards the client sessions subscribed to each resource URI.
+	subscriptionsMu sync.Mutex
+	subscriptions   map[string]map[*mcp.ServerSession]struct{}
+}
 
-	for tool, err := range cs.Tools(ctx, nil) {
+// forwardedRequest is a request of a client being forwarded to the command.
+type forwardedRequest struct {
+	// ctx is the context of the request handler, which relates the messages sent to the client to the request.
+	ctx     context.Context
+	session *mcp.ServerSession
+	// progressToken is the progress token of the request, replaced by one unique to the proxy when forwarded.
+	progressToken any
+}
+
+// start starts the command, initializes the MCP session, and syncs its features to the server.
+func (s *stdioMCPServer) start(ctx context.Context) (*mcp.ClientSession, error) {
+	cmd := exec.Command(s.config.Command, s.config.Args...)
+	if s.config.Cwd != "" {
+		cmd.Dir = expandPath(s.config.Cwd)
+	}
+	if len(s.config.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, k := range slices.Sorted(maps.Keys(s.config.Env)) {
+			cmd.Env = append(cmd.Env, k+"="+s.config.Env[k])
+		}
+	}
+	if s.stderrPath != "" {
+		stderr, err := os.OpenFile(s.stderrPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("opening the stderr file of the %s stdio2http proxy command: %w", s.name, err)
 		}
-		server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return cs.CallTool(ctx, &mcp.CallToolParams{
-				Meta:      req.Params.Meta,
-				Name:      req.Params.Name,
-				Arguments: req.Params.Arguments,
-			})
-		})
+		// The command process inherits the file, so it can be closed once the process is started.
+		defer func() { _ = stderr.Close() }()
+		cmd.Stderr = stderr
 	}
-	return nil
+
+	// Sync the features in the background, since the notifications are handled while reading the messages of the
+	// session, which are needed to list the features.
+	client := mcp.NewClient(&mcp.Implementation{Name: "stdio2http-" + s.name}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			go s.logSyncError(s.syncTools(context.WithoutCancel(ctx), req.Session))
+		},
+		PromptListChangedHandler: func(ctx context.Context, req *mcp.PromptListChangedRequest) {
+			go s.logSyncError(s.syncPrompts(context.WithoutCancel(ctx), req.Session))
+		},
+		ResourceListChangedHandler: func(ctx context.Context, req *mcp.ResourceListChangedRequest) {
+			go s.logSyncError(s.syncResources(context.WithoutCancel(ctx), req.Session))
+		},
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			if err := s.server.ResourceUpdated(ctx, req.Params); err != nil {
+				s.logger.Error("failed to relay the resource update of the stdio MCP server", "name", s.name, "error", err)
+			}
+		},
+		LoggingMessageHandler: s.relayLog,
+		CreateMessageHandler:  s.relayCreateMessage,
+		ElicitationHandler:    s.relayElicit,
+	})
+	s.logger.Info("starting stdio2http MCP proxy command", "name", s.name, "command", s.config.Command, "args", s.config.Args)
+	transport := &progressRelayTransport{Transport: &mcp.CommandTransport{Command: cmd}, relay: s.relayProgress}
+	cs, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("running the %s stdio2http proxy command: %w", s.name, err)
+	}
+	if err = errors.Join(
+		s.syncTools(ctx, cs),
+		s.syncResources(ctx, cs),
+		s.syncPrompts(ctx, cs),
+	); err != nil {
+		_ = cs.Close()
+		return nil, fmt.Errorf("proxying features: %w", err)
+	}
+	if err = s.resubscribe(ctx, cs); err != nil {
+		s.logger.Error("failed to resubscribe to the resources of the stdio MCP server", "name", s.name, "error", err)
+	}
+	// Receive all the log messages, which are filtered by the level set by each client when relayed.
+	if cs.InitializeResult().Capabilities.Logging != nil {
+		if err = cs.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+			s.logger.Error("failed to set the logging level of the stdio MCP server", "name", s.name, "error", err)
+		}
+	}
+	s.session.Store(cs)
+	return cs, nil
 }
 
-// proxyResources proxies resource requests to the stdio MCP client session.
-func proxyResources(ctx context.Context, cs *mcp.ClientSession, server *mcp.Server) error {
-	if cs.InitializeResult().Capabilities.Resources == nil {
-		return nil
+// supervise restarts the command when it exits, with an exponential backoff, until the context is done.
+func (s *stdioMCPServer) supervise(ctx context.Context, cs *mcp.ClientSession) {
+	backoff := s.backoff
+	for {
+		started := time.Now()
+		err := cs.Wait()
+		s.session.Store(nil)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > stdioMCPMaxRestartBackoff {
+			backoff = s.backoff
+		}
+		s.logger.Error("stdio2http MCP proxy command exited, restarting", "name", s.name, "error", err, "backoff", backoff)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, stdioMCPMaxRestartBackoff)
+			if cs, err = s.start(ctx); err == nil {
+				break
+			}
+			s.logger.Error("failed to restart stdio2http MCP proxy command", "name", s.name, "error", err, "backoff", backoff)
+		}
+		// The proxy may have been stopped while the command was restarting.
+		if ctx.Err() != nil {
+			_ = cs.Close()
+			return
+		}
+		s.logger.Info("restarted stdio2http MCP proxy command", "name", s.name)
+	}
+}
+
+// currentSession returns the session of the running command.
+func (s *stdioMCPServer) currentSession() (*mcp.ClientSession, error) {
+	if cs := s.session.Load(); cs != nil {
+		return cs, nil
+	}
+	return nil, fmt.Errorf("the %s stdio MCP server is restarting", s.name)
+}
+
+func (s *stdioMCPServer) logSyncError(err error) {
+	if err != nil {
+		s.logger.Error("failed to sync the features of the stdio MCP server", "name", s.name, "error", err)
+	}
+}
+
+// syncTools proxies the tools of the stdio MCP client session, removing the ones that are no longer listed.
+func (s *stdioMCPServer) syncTools(ctx context.Context, cs *mcp.ClientSession) error {
+	s.featuresMu.Lock()
+	defer s.featuresMu.Unlock()
+	var tools iter.Seq2[*mcp.Tool, error]
+	if cs.InitializeResult().Capabilities.Tools != nil {
+		tools = cs.Tools(ctx, nil)
+	}
+	var err error
+	s.tools, err = syncFeatures(s.tools, tools, func(tool *mcp.Tool) string {
+		s.server.AddTool(tool, s.callTool)
+		return tool.Name
+	}, s.server.RemoveTools)
+	return err
+}
+
+func (s *stdioMCPServer) callTool(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cs, err := s.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	params := &mcp.CallToolParams{
+		Meta:      maps.Clone(req.Params.Meta),
+		Name:      req.Params.Name,
+		Arguments: req.Params.Arguments,
+	}
+	defer s.forwardRequest(ctx, req.Session, params)()
+	return cs.CallTool(ctx, params)
+}
+
+// syncResources proxies the resources and resource templates of the stdio MCP client session, removing the ones that
+// are no longer listed.
+func (s *stdioMCPServer) syncResources(ctx context.Context, cs *mcp.ClientSession) error {
+	s.featuresMu.Lock()
+	defer s.featuresMu.Unlock()
+	var resources iter.Seq2[*mcp.Resource, error]
+	var templates iter.Seq2[*mcp.ResourceTemplate, error]
+	if cs.InitializeResult().Capabilities.Resources != nil {
+		resources = cs.Resources(ctx, nil)
+		templates = cs.ResourceTemplates(ctx, nil)
+	}
+	var resourcesErr, templatesErr error
+	s.resources, resourcesErr = syncFeatures(s.resources, resources, func(resource *mcp.Resource) string {
+		s.server.AddResource(resource, s.readResource)
+		return resource.URI
+	}, s.server.RemoveResources)
+	s.resourceTemplates, templatesErr = syncFeatures(s.resourceTemplates, templates, func(template *mcp.ResourceTemplate) string {
+		s.server.AddResourceTemplate(template, s.readResource)
+		return template.URITemplate
+	}, s.server.RemoveResourceTemplates)
+	return errors.Join(resourcesErr, templatesErr)
+}
+
+func (s *stdioMCPServer) readResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	cs, err := s.currentSession()
+	if err != nil {
+		return nil, err
 	}
+	params := &mcp.ReadResourceParams{
+		Meta: maps.Clone(req.Params.Meta),
+		URI:  req.Params.URI,
+	}
+	defer s.forwardRequest(ctx, req.Session, params)()
+	return cs.ReadResource(ctx, params)
+}
+
+// syncPrompts proxies the prompts of the stdio MCP client session, removing the ones that are no longer listed.
+func (s *stdioMCPServer) syncPrompts(ctx context.Context, cs *mcp.ClientSession) error {
+	s.featuresMu.Lock()
+	defer s.featuresMu.Unlock()
+	var prompts iter.Seq2[*mcp.Prompt, error]
+	if cs.InitializeResult().Capabilities.Prompts != nil {
+		prompts = cs.Prompts(ctx, nil)
+	}
+	var err error
+	s.prompts, err = syncFeatures(s.prompts, prompts, func(prompt *mcp.Prompt) string {
+		s.server.AddPrompt(prompt, s.getPrompt)
+		return prompt.Name
+	}, s.server.RemovePrompts)
+	return err
+}
+
+func (s *stdioMCPServer) getPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	cs, err := s.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	params := &mcp.GetPromptParams{
+		Meta:      maps.Clone(req.Params.Meta),
+		Name:      req.Params.Name,
+		Arguments: req.Params.Arguments,
+	}
+	defer s.forwardRequest(ctx, req.Session, params)()
+	return cs.GetPrompt(ctx, params)
+}
+
+// progressTokenPrefix is the prefix of the progress tokens of the requests forwarded to the command, followed by the
+// ID of the forwarded request.
+const progressTokenPrefix = "stdio2http-"
+
+// forwardRequest registers a request of a client being forwarded to the command, until the returned function is
+// called. When the request has a progress token, it is replaced by one unique to the proxy, so that the progress
+// notifications of the command are relayed to the client that sent the request. The params must not share their
+// metadata with the request of the client.
+func (s *stdioMCPServer) forwardRequest(ctx context.Context, session *mcp.ServerSession, params interface {
+	GetProgressToken() any
+	SetProgressToken(any)
+},
+) func() {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if s.requests == nil {
+		s.requests = make(map[uint64]*forwardedRequest)
+	}
+	s.lastRequestID++
+	id := s.lastRequestID
+	r := &forwardedRequest{ctx: ctx, session: session, progressToken: params.GetProgressToken()}
+	if r.progressToken != nil {
+		params.SetProgressToken(progressTokenPrefix + strconv.FormatUint(id, 10))
+	}
+	s.requests[id] = r
+	return func() {
+		s.requestsMu.Lock()
+		defer s.requestsMu.Unlock()
+		delete(s.requests, id)
+	}
+}
+
+// soleClientRequest returns the latest request being forwarded to the command, provided that all the requests being
+// forwarded are from the same client.
+//
+// The stdio transport doesn't relate the requests of the command to the request it is handling, so they can only be
+// relayed when a single client has requests in progress. Otherwise, they could be relayed to a client that didn't
+// cause them, which would then see their content.
+func (s *stdioMCPServer) soleClientRequest() (*forwardedRequest, error) {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if len(s.requests) == 0 {
+		return nil, fmt.Errorf("no client request of the %s stdio MCP server is in progress", s.name)
+	}
+	var latestID uint64
+	var latest *forwardedRequest
+	for id, r := range s.requests {
+		if latest != nil && r.session != latest.session {
+			return nil, fmt.Errorf("requests of several clients of the %s stdio MCP server are in progress", s.name)
+		}
+		if latest == nil || id > latestID {
+			latestID, latest = id, r
+		}
+	}
+	return latest, nil
+}
+
+// relayProgress relays a progress notification of the command to the client of the related request, returning
+// whether the notification relates to a forwarded request.
+func (s *stdioMCPServer) relayProgress(ctx context.Context, params *mcp.ProgressNotificationParams) bool {
+	token, ok := params.ProgressToken.(string)
+	if !ok || !strings.HasPrefix(token, progressTokenPrefix) {
+		return false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(token, progressTokenPrefix), 10, 64)
+	if err != nil {
+		return false
+	}
+	s.requestsMu.Lock()
+	r, ok := s.requests[id]
+	s.requestsMu.Unlock()
+	if !ok {
+		return false
+	}
+	relayed := *params
+	relayed.ProgressToken = r.progressToken
+	ctx, cancel := r.relatedContext(ctx)
+	defer cancel()
+	if err = r.session.NotifyProgress(ctx, &relayed); err != nil {
+		s.logger.Error("failed to relay the progress of the stdio MCP server", "name", s.name, "error", err)
+	}
+	return true
+}
 
-	for resource, err := range cs.Resources(ctx, nil) {
+// progressRelayTransport relays the progress notifications of the command as soon as they are read.
+//
+// The client session handles the notifications asynchronously to the responses, so a progress notification could
+// otherwise be handled after the response of its request, when it can no longer be sent to the client.
+type progressRelayTransport struct {
+	mcp.Transport
+	relay func(context.Context, *mcp.ProgressNotificationParams) bool
+}
+
+// Connect implements [mcp.Transport].
+func (t *progressRelayTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &progressRelayConn{Connection: conn, relay: t.relay}, nil
+}
+
+// progressRelayConn is a connection of a progressRelayTransport.
+type progressRelayConn struct {
+	mcp.Connection
+	relay func(context.Context, *mcp.ProgressNotificationParams) bool
+}
+
+// Read implements [mcp.Connection], skipping the progress notifications that were relayed.
+func (c *progressRelayConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		msg, err := c.Connection.Read(ctx)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		server.AddResource(resource, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-			return cs.ReadResource(ctx, &mcp.ReadResourceParams{
-				Meta: req.Params.Meta,
-				URI:  req.Params.URI,
-			})
-		})
+		if req, ok := msg.(*jsonrpc.Request); ok && req.Method == "notifications/progress" {
+			var params mcp.ProgressNotificationParams
+			if json.Unmarshal(req.Params, &params) == nil && c.relay(ctx, &params) {
+				continue
+			}
+		}
+		return msg, nil
+	}
+}
+
+// relayLog relays a log message of the command to all the clients, which only receive the messages at or above the
+// level they set.
+func (s *stdioMCPServer) relayLog(ctx context.Context, req *mcp.LoggingMessageRequest) {
+	for session := range s.server.Sessions() {
+		if err := session.Log(ctx, req.Params); err != nil {
+			s.logger.Error("failed to relay the log message of the stdio MCP server", "name", s.name, "error", err)
+		}
+	}
+}
+
+// relayCreateMessage relays a sampling request of the command to the client of the forwarded requests.
+func (s *stdioMCPServer) relayCreateMessage(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	r, err := s.soleClientRequest()
+	if err != nil {
+		return nil, err
 	}
-	for template, err := range cs.ResourceTemplates(ctx, nil) {
+	if params := r.session.InitializeParams(); params == nil || params.Capabilities == nil || params.Capabilities.Sampling == nil {
+		return nil, fmt.Errorf("client does not support sampling")
+	}
+	ctx, cancel := r.relatedContext(ctx)
+	defer cancel()
+	return r.session.CreateMessage(ctx, req.Params)
+}
+
+// relayElicit relays an elicitation request of the command to the client of the forwarded requests.
+func (s *stdioMCPServer) relayElicit(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	r, err := s.soleClientRequest()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.relatedContext(ctx)
+	defer cancel()
+	return r.session.Elicit(ctx, req.Params)
+}
+
+// relatedContext returns a context relating a request sent to the client to the forwarded request, which is canceled
+// when either the given context or the forwarded request is done.
+func (r *forwardedRequest) relatedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	related, cancel := context.WithCancel(r.ctx)
+	stop := context.AfterFunc(ctx, cancel)
+	return related, func() {
+		stop()
+		cancel()
+	}
+}
+
+// subscribe subscribes to the updates of a resource of the command, on behalf of the client sessions.
+func (s *stdioMCPServer) subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	s.subscriptionsMu.Lock()
+	defer s.subscriptionsMu.Unlock()
+	sessions := s.subscriptions[req.Params.URI]
+	if len(sessions) == 0 {
+		cs, err := s.currentSession()
 		if err != nil {
 			return err
 		}
-		server.AddResourceTemplate(template, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-			return cs.ReadResource(ctx, &mcp.ReadResourceParams{
-				Meta: req.Params.Meta,
-				URI:  req.Params.URI,
-			})
-		})
+		if err = cs.Subscribe(ctx, &mcp.SubscribeParams{URI: req.Params.URI}); err != nil {
+			return err
+		}
+		if s.subscriptions == nil {
+			s.subscriptions = make(map[string]map[*mcp.ServerSession]struct{})
+		}
+		sessions = make(map[*mcp.ServerSession]struct{})
+		s.subscriptions[req.Params.URI] = sessions
 	}
+	sessions[req.Session] = struct{}{}
 	return nil
 }
 
-// proxyPrompts proxies prompt requests to the stdio MCP client session.
-func proxyPrompts(ctx context.Context, cs *mcp.ClientSession, server *mcp.Server) error {
-	if cs.InitializeResult().Capabilities.Prompts == nil {
+// unsubscribe unsubscribes from the updates of a resource of the command once no client session is subscribed.
+func (s *stdioMCPServer) unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	s.subscriptionsMu.Lock()
+	defer s.subscriptionsMu.Unlock()
+	sessions := s.subscriptions[req.Params.URI]
+	delete(sessions, req.Session)
+	if len(sessions) > 0 {
+		return nil
+	}
+	delete(s.subscriptions, req.Params.URI)
+	cs, err := s.currentSession()
+	if err != nil {
+		// The restarted command is not subscribed to the resource.
 		return nil
 	}
+	return cs.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: req.Params.URI})
+}
 
-	for prompt, err := range cs.Prompts(ctx, nil) {
-		if err != nil {
-			return err
+// resubscribe subscribes the restarted command to the resources the client sessions are subscribed to.
+func (s *stdioMCPServer) resubscribe(ctx context.Context, cs *mcp.ClientSession) error {
+	s.subscriptionsMu.Lock()
+	defer s.subscriptionsMu.Unlock()
+	var errs []error
+	for uri := range s.subscriptions {
+		errs = append(errs, cs.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}))
+	}
+	return errors.Join(errs...)
+}
+
+// syncFeatures adds each listed feature with the add function, which returns its key, and removes the previously
+// synced features that are no longer listed. A nil list removes all the features.
+//
+// It returns the keys of the synced features. When listing fails, nothing is removed and the previously synced
+// features are returned along with the error.
+func syncFeatures[T any](synced map[string]struct{}, list iter.Seq2[T, error], add func(T) string, remove func(...string)) (map[string]struct{}, error) {
+	listed := make(map[string]struct{})
+	if list != nil {
+		for feature, err := range list {
+			if err != nil {
+				if synced == nil {
+					synced = make(map[string]struct{})
+				}
+				maps.Copy(synced, listed)
+				return synced, err
+			}
+			listed[add(feature)] = struct{}{}
 		}
-		server.AddPrompt(prompt, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-			return cs.GetPrompt(ctx, &mcp.GetPromptParams{
-				Meta:      req.Params.Meta,
-				Name:      req.Params.Name,
-				Arguments: req.Params.Arguments,
-			})
-		})
 	}
-	return nil
+	var removed []string
+	for key := range synced {
+		if _, ok := listed[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	if len(removed) > 0 {
+		remove(removed...)
+	}
+	return listed, nil
 }
@@ -7,12 +7,22 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/autoconfig"
 )
 
 const runMCPTestServer = "__RUN_MCP_TEST_SERVER__"
@@ -38,7 +48,7 @@ func TestStdio2HTTP(t *testing.T) {
 	// Run the stdio2http proxy; this will run the test binary as a subprocess in a separate goroutine.
 	// Since it is bound to the test context, when the test is completed the command will be aborted.
 	logger := slog.New(slog.DiscardHandler)
-	addr, err := runStdio2HTTPProxy(t.Context(), logger, "test-stdio", cmd)
+	addr, err := runStdio2HTTPProxy(t.Context(), logger, "test-stdio", autoconfig.MCPServer{Command: cmd}, "")
 	require.NoError(t, err)
 
 	// run a streamable HTTP client against the proxy.
@@ -58,22 +68,458 @@ func TestStdio2HTTP(t *testing.T) {
 	require.Equal(t, "test stdio proxy", res.Content[0].(*mcp.TextContent).Text)
 }
 
-// runTestStdioServer runs a simple MCP stdio server that implements an "echo" tool.
+func TestStdioMCPProxies_proxyStdioMCPServers(t *testing.T) {
+	t.Setenv(runMCPTestServer, "true")
+	cmd, err := os.Executable()
+	require.NoError(t, err)
+	logger := slog.New(slog.DiscardHandler)
+	servers := func(args ...string) *autoconfig.MCPServers {
+		return &autoconfig.MCPServers{McpServers: map[string]autoconfig.MCPServer{
+			"stdio":  {Command: cmd, Args: args, IncludeTools: []string{"echo"}},
+			"remote": {Type: "http", URL: "https://example.com/mcp"},
+		}}
+	}
+	requireEcho := func(addr string) {
+		client := mcp.NewClient(&mcp.Implementation{Name: t.Name()}, nil)
+		cs, err := client.Connect(t.Context(), &mcp.StreamableClientTransport{Endpoint: addr}, nil)
+		require.NoError(t, err)
+		defer cs.Close()
+		res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": "hello"}})
+		require.NoError(t, err)
+		require.False(t, res.IsError)
+	}
+
+	p := &stdioMCPProxies{}
+	first := servers()
+	require.NoError(t, p.proxyStdioMCPServers(t.Context(), logger, first))
+	stdio := first.McpServers["stdio"]
+	require.Equal(t, "http", stdio.Type)
+	require.Equal(t, []string{"echo"}, stdio.IncludeTools)
+	require.Equal(t, autoconfig.MCPServer{Type: "http", URL: "https://example.com/mcp"}, first.McpServers["remote"])
+	requireEcho(stdio.URL)
+
+	// The proxy of an unchanged server is kept running.
+	second := servers()
+	require.NoError(t, p.proxyStdioMCPServers(t.Context(), logger, second))
+	require.Equal(t, stdio.URL, second.McpServers["stdio"].URL)
+
+	// The proxy of a changed server is restarted.
+	third := servers("--changed")
+	require.NoError(t, p.proxyStdioMCPServers(t.Context(), logger, third))
+	require.NotEqual(t, stdio.URL, third.McpServers["stdio"].URL)
+	requireEcho(third.McpServers["stdio"].URL)
+
+	// The proxy of a removed server is stopped.
+	require.NoError(t, p.proxyStdioMCPServers(t.Context(), logger, nil))
+	require.Empty(t, p.running)
+}
+
+func TestStdio2HTTP_supervision(t *testing.T) {
+	t.Setenv(runMCPTestServer, "true")
+	cmd, err := os.Executable()
+	require.NoError(t, err)
+	initialBackoff := stdioMCPInitialRestartBackoff
+	stdioMCPInitialRestartBackoff = 10 * time.Millisecond
+	t.Cleanup(func() { stdioMCPInitialRestartBackoff = initialBackoff })
+
+	dir := t.TempDir()
+	stderrPath := filepath.Join(dir, "mcp-test-stdio.stderr.log")
+	addr, err := runStdio2HTTPProxy(t.Context(), slog.New(slog.DiscardHandler), "test-stdio", autoconfig.MCPServer{
+		Command: cmd,
+		Env:     map[string]string{"TEST_STDIO_VAR": "from-config"},
+		Cwd:     dir,
+	}, stderrPath)
+	require.NoError(t, err)
+
+	toolsChanged := make(chan struct{}, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: t.Name()}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) { toolsChanged <- struct{}{} },
+	})
+	cs, err := client.Connect(t.Context(), &mcp.StreamableClientTransport{Endpoint: addr}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+	callTool := func(name string, args map[string]any) (string, error) {
+		res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			return "", err
+		}
+		if res.IsError {
+			return "", fmt.Errorf("tool error: %v", res.Content)
+		}
+		return res.Content[0].(*mcp.TextContent).Text, nil
+	}
+	toolNames := func() []string {
+		var names []string
+		for tool, err := range cs.Tools(t.Context(), nil) {
+			require.NoError(t, err)
+			names = append(names, tool.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	// The command runs with the configured environment and working directory.
+	text, err := callTool("env", map[string]any{"text": "TEST_STDIO_VAR"})
+	require.NoError(t, err)
+	require.Equal(t, "from-config", text)
+	text, err = callTool("cwd", nil)
+	require.NoError(t, err)
+	expectedDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	require.Equal(t, expectedDir, text)
+
+	// The tools/list_changed notifications of the command are forwarded.
+	_, err = callTool("add_tool", nil)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return slices.Contains(toolNames(), "added")
+	}, 10*time.Second, 50*time.Millisecond)
+	require.NotEmpty(t, toolsChanged)
+
+	// The command is restarted when it crashes, and the tools it no longer lists are removed.
+	_, _ = callTool("crash", nil)
+	require.Eventually(t, func() bool {
+		text, err := callTool("echo", map[string]any{"text": "restarted"})
+		return err == nil && text == "restarted"
+	}, 10*time.Second, 50*time.Millisecond)
+	require.Equal(t, []string{"add_tool", "block", "crash", "cwd", "echo", "elicit", "env", "log", "progress", "sample", "update_resource"}, toolNames())
+
+	// The stderr of each run of the command is captured.
+	stderr, err := os.ReadFile(stderrPath)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(stderr), "test stdio server started\n"))
+}
+
+func TestStdio2HTTP_bidirectional(t *testing.T) {
+	t.Setenv(runMCPTestServer, "true")
+	cmd, err := os.Executable()
+	require.NoError(t, err)
+	addr, err := runStdio2HTTPProxy(t.Context(), slog.New(slog.DiscardHandler), "test-stdio", autoconfig.MCPServer{Command: cmd}, "")
+	require.NoError(t, err)
+
+	progress := make(chan *mcp.ProgressNotificationParams, 10)
+	logs := make(chan *mcp.LoggingMessageParams, 10)
+	updates := make(chan *mcp.ResourceUpdatedNotificationParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: t.Name()}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params
+		},
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			logs <- req.Params
+		},
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params
+		},
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{Model: "test", Role: "assistant", Content: &mcp.TextContent{Text: "sampled " + prompt}}, nil
+		},
+		ElicitationHandler: func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"name": "aigw"}}, nil
+		},
+	})
+	cs, err := client.Connect(t.Context(), &mcp.StreamableClientTransport{Endpoint: addr}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+	callTool := func(params *mcp.CallToolParams) string {
+		res, err := cs.CallTool(t.Context(), params)
+		require.NoError(t, err)
+		require.False(t, res.IsError, res.Content)
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+
+	t.Run("progress", func(t *testing.T) {
+		params := &mcp.CallToolParams{Name: "progress"}
+		params.SetProgressToken("client-token")
+		require.Equal(t, "done", callTool(params))
+		select {
+		case p := <-progress:
+			require.Equal(t, "client-token", p.ProgressToken)
+			require.Equal(t, "halfway", p.Message)
+			require.Equal(t, 1.0, p.Progress)
+			require.Equal(t, 2.0, p.Total)
+		case <-time.After(10 * time.Second):
+			t.Fatal("progress notification not relayed")
+		}
+		// The metadata of the client request is not changed.
+		require.Equal(t, "client-token", params.GetProgressToken())
+	})
+
+	t.Run("logging", func(t *testing.T) {
+		require.NoError(t, cs.SetLoggingLevel(t.Context(), &mcp.SetLoggingLevelParams{Level: "info"}))
+		require.Equal(t, "logged", callTool(&mcp.CallToolParams{Name: "log"}))
+		select {
+		case l := <-logs:
+			require.Equal(t, mcp.LoggingLevel("warning"), l.Level)
+			require.Equal(t, "from the stdio server", l.Data)
+		case <-time.After(10 * time.Second):
+			t.Fatal("log message not relayed")
+		}
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		require.Equal(t, "sampled hello", callTool(&mcp.CallToolParams{Name: "sample", Arguments: map[string]any{"text": "hello"}}))
+	})
+
+	t.Run("elicitation", func(t *testing.T) {
+		require.Equal(t, "accept aigw", callTool(&mcp.CallToolParams{Name: "elicit"}))
+	})
+
+	t.Run("resource subscription", func(t *testing.T) {
+		require.NoError(t, cs.Subscribe(t.Context(), &mcp.SubscribeParams{URI: "test://counter"}))
+		callTool(&mcp.CallToolParams{Name: "update_resource"})
+		select {
+		case u := <-updates:
+			require.Equal(t, "test://counter", u.URI)
+		case <-time.After(10 * time.Second):
+			t.Fatal("resource update not relayed")
+		}
+		require.NoError(t, cs.Unsubscribe(t.Context(), &mcp.UnsubscribeParams{URI: "test://counter"}))
+	})
+}
+
+func TestStdio2HTTP_severalClients(t *testing.T) {
+	t.Setenv(runMCPTestServer, "true")
+	cmd, err := os.Executable()
+	require.NoError(t, err)
+	addr, err := runStdio2HTTPProxy(t.Context(), slog.New(slog.DiscardHandler), "test-stdio", autoconfig.MCPServer{Command: cmd}, "")
+	require.NoError(t, err)
+
+	sampled := make(chan string, 10)
+	blocking := make(chan struct{}, 1)
+	connect := func() *mcp.ClientSession {
+		client := mcp.NewClient(&mcp.Implementation{Name: t.Name()}, &mcp.ClientOptions{
+			ProgressNotificationHandler: func(context.Context, *mcp.ProgressNotificationClientRequest) {
+				blocking <- struct{}{}
+			},
+			CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+				prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+				sampled <- prompt
+				return &mcp.CreateMessageResult{Model: "test", Role: "assistant", Content: &mcp.TextContent{Text: "sampled " + prompt}}, nil
+			},
+		})
+		cs, err := client.Connect(t.Context(), &mcp.StreamableClientTransport{Endpoint: addr}, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = cs.Close() })
+		return cs
+	}
+	alice, bob := connect(), connect()
+
+	// Alice has a request in progress while Bob calls a tool that samples a message.
+	blockCtx, cancelBlock := context.WithCancel(t.Context())
+	blockDone := make(chan struct{})
+	go func() {
+		defer close(blockDone)
+		params := &mcp.CallToolParams{Name: "block"}
+		params.SetProgressToken("alice")
+		_, _ = alice.CallTool(blockCtx, params)
+	}()
+	select {
+	case <-blocking:
+	case <-time.After(10 * time.Second):
+		t.Fatal("blocking request not in progress")
+	}
+
+	// The sampling request can't be related to the request of either client, so it is relayed to neither of them.
+	res, err := bob.CallTool(t.Context(), &mcp.CallToolParams{Name: "sample", Arguments: map[string]any{"text": "secret"}})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	require.Contains(t, res.Content[0].(*mcp.TextContent).Text, "requests of several clients")
+	require.Empty(t, sampled)
+
+	// Once Alice's request is done, Bob's requests are relayed to Bob again.
+	cancelBlock()
+	<-blockDone
+	require.Eventually(t, func() bool {
+		res, err := bob.CallTool(t.Context(), &mcp.CallToolParams{Name: "sample", Arguments: map[string]any{"text": "hello"}})
+		return err == nil && !res.IsError
+	}, 10*time.Second, 100*time.Millisecond)
+	require.Equal(t, "hello", <-sampled)
+}
+
+func TestSameStdioCommand(t *testing.T) {
+	base := autoconfig.MCPServer{Command: "npx", Args: []string{"server"}, Env: map[string]string{"A": "1"}, Cwd: "/tmp"}
+	for _, tc := range []struct {
+		name     string
+		other    autoconfig.MCPServer
+		expected bool
+	}{
+		{name: "same", other: base, expected: true},
+		{name: "different headers", other: autoconfig.MCPServer{Command: "npx", Args: []string{"server"}, Env: map[string]string{"A": "1"}, Cwd: "/tmp", Headers: map[string]string{"X": "y"}}, expected: true},
+		{name: "different command", other: autoconfig.MCPServer{Command: "uvx", Args: []string{"server"}, Env: map[string]string{"A": "1"}, Cwd: "/tmp"}},
+		{name: "different args", other: autoconfig.MCPServer{Command: "npx", Args: []string{"other"}, Env: map[string]string{"A": "1"}, Cwd: "/tmp"}},
+		{name: "different env", other: autoconfig.MCPServer{Command: "npx", Args: []string{"server"}, Env: map[string]string{"A": "2"}, Cwd: "/tmp"}},
+		{name: "different cwd", other: autoconfig.MCPServer{Command: "npx", Args: []string{"server"}, Env: map[string]string{"A": "1"}, Cwd: "/"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, sameStdioCommand(base, tc.other))
+		})
+	}
+}
+
+func TestSyncFeatures(t *testing.T) {
+	list := func(keys ...string) iter.Seq2[string, error] {
+		return func(yield func(string, error) bool) {
+			for _, k := range keys {
+				if k == "error" {
+					yield("", errors.New("list error"))
+					return
+				}
+				if !yield(k, nil) {
+					return
+				}
+			}
+		}
+	}
+	var added, removed []string
+	add := func(k string) string {
+		added = append(added, k)
+		return k
+	}
+	remove := func(keys ...string) {
+		removed = append(removed, keys...)
+	}
+
+	synced, err := syncFeatures(nil, list("a", "b"), add, remove)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, synced)
+	require.Equal(t, []string{"a", "b"}, added)
+	require.Empty(t, removed)
+
+	added = nil
+	synced, err = syncFeatures(synced, list("b", "c"), add, remove)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"b": {}, "c": {}}, synced)
+	require.Equal(t, []string{"b", "c"}, added)
+	require.Equal(t, []string{"a"}, removed)
+
+	// Nothing is removed when listing fails.
+	removed = nil
+	synced, err = syncFeatures(synced, list("d", "error"), add, remove)
+	require.EqualError(t, err, "list error")
+	require.Equal(t, map[string]struct{}{"b": {}, "c": {}, "d": {}}, synced)
+	require.Empty(t, removed)
+
+	// A nil list removes all the features.
+	synced, err = syncFeatures(synced, nil, add, remove)
+	require.NoError(t, err)
+	require.Empty(t, synced)
+	slices.Sort(removed)
+	require.Equal(t, []string{"b", "c", "d"}, removed)
+}
+
+// runTestStdioServer runs a simple MCP stdio server that implements an "echo" tool, and tools to test the supervision
+// of the server by the stdio2http proxy.
 // This method will be run in a subprocess via TestMain, which will be executed by the
 // stdio2http proxy.
 func runTestStdioServer() {
 	type echoArgs struct {
 		Text string `json:"text"`
 	}
-	server := mcp.NewServer(&mcp.Implementation{Name: "test-stdio"}, nil)
+	textResult := func(text string) *mcp.CallToolResult {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "test stdio server started")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-stdio"}, &mcp.ServerOptions{
+		SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+	})
+	server.AddResource(&mcp.Resource{URI: "test://counter", Name: "counter"},
+		func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "1"}}}, nil
+		})
 	mcp.AddTool(server,
 		&mcp.Tool{Name: "echo", Description: "echo tool"},
 		func(_ context.Context, _ *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: args.Text},
+			return textResult(args.Text), nil, nil
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "env", Description: "returns the value of the environment variable named by text"},
+		func(_ context.Context, _ *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+			return textResult(os.Getenv(args.Text)), nil, nil
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "cwd", Description: "returns the working directory"},
+		func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+			wd, err := os.Getwd()
+			return textResult(wd), nil, err
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "add_tool", Description: "adds the \"added\" tool"},
+		func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+			server.AddTool(&mcp.Tool{Name: "added", InputSchema: &jsonschema.Schema{Type: "object"}},
+				func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					return textResult("added"), nil
+				})
+			return textResult("ok"), nil, nil
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "progress", Description: "notifies the progress of the request"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: req.Params.GetProgressToken(), Message: "halfway", Progress: 1, Total: 2,
+			})
+			return textResult("done"), nil, err
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "log", Description: "sends a log message"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			// Not sent to the client, which set the "info" level.
+			if err := req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "debug", Data: "debug"}); err != nil {
+				return nil, nil, err
+			}
+			err := req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "warning", Data: "from the stdio server"})
+			return textResult("logged"), nil, err
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "sample", Description: "samples a message from the client"},
+		func(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+			res, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+				Messages:  []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: args.Text}}},
+				MaxTokens: 10,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return textResult(res.Content.(*mcp.TextContent).Text), nil, nil
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "elicit", Description: "elicits a name from the user"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			res, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+				Message: "What is your name?",
+				RequestedSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{"name": {Type: "string"}},
 				},
-			}, nil, nil
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return textResult(fmt.Sprintf("%s %v", res.Action, res.Content["name"])), nil, nil
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "block", Description: "notifies its progress, then blocks until the request is canceled"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			if err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: req.Params.GetProgressToken(), Message: "blocking",
+			}); err != nil {
+				return nil, nil, err
+			}
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "update_resource", Description: "notifies that the counter resource was updated"},
+		func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "test://counter"})
+			return textResult("updated"), nil, err
+		})
+	mcp.AddTool(server,
+		&mcp.Tool{Name: "crash", Description: "exits the server"},
+		func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+			os.Exit(1)
+			return nil, nil, nil
 		})
 
 	_ = server.Run(context.Background(), &mcp.StdioTransport{})
@@ -12,11 +12,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
 	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,69 +37,297 @@ import (
 
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 	"github.com/envoyproxy/ai-gateway/internal/controller"
+	"github.com/envoyproxy/ai-gateway/internal/json"
 )
 
-// translate reads the input files, collects the AI Gateway custom resources,
-// translates them to Envoy Gateway and Kubernetes objects, and writes the translated objects to the output writer.
-func translate(ctx context.Context, paths []string, output, stderr io.Writer) error {
-	stderrLogger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{}))
-	yaml, err := readYamlsAsString(paths)
+// translateOutputJSON is the JSON output format of `aigw translate`. The default output format is YAML.
+const translateOutputJSON = "json"
+
+// translateCmd implements the `aigw translate` command. It translates the AI Gateway resources read from the given
+// files or the standard input, and writes the translated resources, or their changes since a previous output, in the
+// requested format.
+func translateCmd(ctx context.Context, c *cmdTranslate, stdout, stderr io.Writer) error {
+	input, err := readYamlsAsString(c.Paths, c.stdin)
+	if err != nil {
+		return err
+	}
+	var translated bytes.Buffer
+	if err = translate(ctx, input, &translated, stderr, c.Validate); err != nil {
+		return err
+	}
+	objs, err := decodeTranslatedObjects(translated.Bytes())
 	if err != nil {
 		return err
 	}
-	aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSConfigs, originalGateways, originalSecrets, _, err := collectObjects(yaml, output, stderrLogger)
+	if c.Diff == "" {
+		return writeTranslatedObjects(stdout, objs, c.Output)
+	}
+
+	previous, err := os.ReadFile(c.Diff)
+	if err != nil {
+		return fmt.Errorf("error reading previous output %s: %w", c.Diff, err)
+	}
+	previousObjs, err := decodeTranslatedObjects(previous)
+	if err != nil {
+		return fmt.Errorf("error decoding previous output %s: %w", c.Diff, err)
+	}
+	return writeTranslatedObjectsDiff(stdout, previousObjs, objs, c.Output)
+}
+
+// translate collects the AI Gateway custom resources of the YAML input, translates them to Envoy Gateway and
+// Kubernetes objects, and writes the translated objects to the output writer.
+//
+// When validate is true, the errors reported in the status conditions of the AI Gateway resources by the
+// controllers are returned instead of writing the objects.
+func translate(ctx context.Context, yamlInput string, output, stderr io.Writer, validate bool) error {
+	stderrLogger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{}))
+	// The objects are buffered so that nothing is written when the validation fails.
+	var buf bytes.Buffer
+	aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSConfigs, originalGateways, originalSecrets, _, err := collectObjects(yamlInput, &buf, stderrLogger)
 	if err != nil {
 		return fmt.Errorf("error translating: %w", err)
 	}
 
-	_, _, httpRoutes, extensionPolicies, httpRouteFilter, backends, secrets, backendTrafficPolicies, securityPolicies, err := translateCustomResourceObjects(ctx, aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSConfigs, originalGateways, originalSecrets, stderrLogger)
+	fakeClient, _, httpRoutes, extensionPolicies, httpRouteFilter, backends, secrets, backendTrafficPolicies, securityPolicies, err := translateCustomResourceObjects(ctx, aigwRoutes, mcpRoutes, aigwBackends, backendSecurityPolicies, backendTLSConfigs, originalGateways, originalSecrets, stderrLogger, validate)
 	if err != nil {
 		return fmt.Errorf("error emitting: %w", err)
 	}
+	if validate {
+		if err = validateTranslatedResources(ctx, fakeClient); err != nil {
+			return fmt.Errorf("invalid resources:\n%w", err)
+		}
+	}
 
 	// Emit the translated objects.
 	for i := range httpRoutes.Items {
 		httpRoute := &httpRoutes.Items[i]
-		mustWriteObj(&httpRoute.TypeMeta, httpRoute, output)
+		mustWriteObj(&httpRoute.TypeMeta, httpRoute, &buf)
 	}
 	for i := range extensionPolicies.Items {
 		extensionPolicy := &extensionPolicies.Items[i]
-		mustWriteObj(&extensionPolicy.TypeMeta, extensionPolicy, output)
+		mustWriteObj(&extensionPolicy.TypeMeta, extensionPolicy, &buf)
 	}
 	for i := range backends.Items {
 		backend := &backends.Items[i]
-		mustWriteObj(&backend.TypeMeta, backend, output)
+		mustWriteObj(&backend.TypeMeta, backend, &buf)
 	}
 	for i := range httpRouteFilter.Items {
 		filter := &httpRouteFilter.Items[i]
-		mustWriteObj(&filter.TypeMeta, filter, output)
+		mustWriteObj(&filter.TypeMeta, filter, &buf)
 	}
 	for i := range secrets.Items {
 		secret := &secrets.Items[i]
-		mustWriteObj(&secret.TypeMeta, secret, output)
+		mustWriteObj(&secret.TypeMeta, secret, &buf)
 	}
 	for _, secret := range originalSecrets {
-		mustWriteObj(&secret.TypeMeta, secret, output)
+		mustWriteObj(&secret.TypeMeta, secret, &buf)
 	}
 	for _, gateway := range originalGateways {
-		mustWriteObj(&gateway.TypeMeta, gateway, output)
+		mustWriteObj(&gateway.TypeMeta, gateway, &buf)
 	}
 	for i := range backendTrafficPolicies.Items {
 		btp := &backendTrafficPolicies.Items[i]
-		mustWriteObj(&btp.TypeMeta, btp, output)
+		mustWriteObj(&btp.TypeMeta, btp, &buf)
 	}
 	for i := range securityPolicies.Items {
 		sp := &securityPolicies.Items[i]
-		mustWriteObj(&sp.TypeMeta, sp, output)
+		mustWriteObj(&sp.TypeMeta, sp, &buf)
+	}
+	_, err = output.Write(buf.Bytes())
+	return err
+}
+
+// validateTranslatedResources returns the errors reported in the status conditions of the AI Gateway resources
+// reconciled by the controllers.
+func validateTranslatedResources(ctx context.Context, c client.Client) error {
+	var errs []error
+	check := func(kind string, obj metav1.Object, conditions []metav1.Condition) {
+		for _, cond := range conditions {
+			if cond.Type == aigv1b1.ConditionTypeNotAccepted {
+				errs = append(errs, fmt.Errorf("%s %s/%s: %s", kind, obj.GetNamespace(), obj.GetName(), cond.Message))
+			}
+		}
+	}
+
+	var backendSecurityPolicies aigv1b1.BackendSecurityPolicyList
+	if err := c.List(ctx, &backendSecurityPolicies); err != nil {
+		return fmt.Errorf("error listing BackendSecurityPolicies: %w", err)
+	}
+	for i := range backendSecurityPolicies.Items {
+		bsp := &backendSecurityPolicies.Items[i]
+		check("BackendSecurityPolicy", bsp, bsp.Status.Conditions)
+	}
+	var backends aigv1b1.AIServiceBackendList
+	if err := c.List(ctx, &backends); err != nil {
+		return fmt.Errorf("error listing AIServiceBackends: %w", err)
+	}
+	for i := range backends.Items {
+		backend := &backends.Items[i]
+		check("AIServiceBackend", backend, backend.Status.Conditions)
+	}
+	var routes aigv1b1.AIGatewayRouteList
+	if err := c.List(ctx, &routes); err != nil {
+		return fmt.Errorf("error listing AIGatewayRoutes: %w", err)
+	}
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		check("AIGatewayRoute", route, route.Status.Conditions)
+	}
+	var mcpRoutes aigv1b1.MCPRouteList
+	if err := c.List(ctx, &mcpRoutes); err != nil {
+		return fmt.Errorf("error listing MCPRoutes: %w", err)
+	}
+	for i := range mcpRoutes.Items {
+		route := &mcpRoutes.Items[i]
+		check("MCPRoute", route, route.Status.Conditions)
+	}
+	return errors.Join(errs...)
+}
+
+// decodeTranslatedObjects decodes the objects of an output of `aigw translate`, in YAML or JSON.
+func decodeTranslatedObjects(output []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(output), 4096)
+	for {
+		var rawObj runtime.RawExtension
+		if err := decoder.Decode(&rawObj); errors.Is(err, io.EOF) {
+			return objs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error decoding YAML: %w", err)
+		}
+		if len(rawObj.Raw) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if _, _, err := unstructured.UnstructuredJSONScheme.Decode(rawObj.Raw, nil, obj); err != nil {
+			return nil, fmt.Errorf("error decoding unstructured object: %w", err)
+		}
+		if !obj.IsList() {
+			objs = append(objs, obj)
+			continue
+		}
+		// The JSON output is a List of the objects.
+		if err := obj.EachListItem(func(item runtime.Object) error {
+			objs = append(objs, item.(*unstructured.Unstructured))
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("error decoding list: %w", err)
+		}
+	}
+}
+
+// marshalTranslatedObject marshals the object in the given output format.
+func marshalTranslatedObject(obj *unstructured.Unstructured, format string) []byte {
+	if format == translateOutputJSON {
+		marshaled, err := json.MarshalIndentSortedKeys(obj.Object, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		return append(marshaled, '\n')
+	}
+	marshaled, err := kyaml.Marshal(obj.Object)
+	if err != nil {
+		panic(err)
+	}
+	return marshaled
+}
+
+// writeTranslatedObjects writes the objects in the given output format: a stream of YAML documents, or a JSON List.
+func writeTranslatedObjects(w io.Writer, objs []*unstructured.Unstructured, format string) error {
+	if format == translateOutputJSON {
+		items := make([]any, len(objs))
+		for i, obj := range objs {
+			items[i] = obj.Object
+		}
+		marshaled, err := json.MarshalIndentSortedKeys(map[string]any{"apiVersion": "v1", "kind": "List", "items": items}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling objects: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", marshaled)
+		return err
+	}
+	for _, obj := range objs {
+		if _, err := fmt.Fprintf(w, "---\n%s", marshalTranslatedObject(obj, format)); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// readYamlsAsString reads the files at the given paths and combines them into a single string.
-func readYamlsAsString(paths []string) (string, error) {
+// translatedObjectKey identifies an object in the diff of the translated objects.
+func translatedObjectKey(obj *unstructured.Unstructured) string {
+	if ns := obj.GetNamespace(); ns != "" {
+		return fmt.Sprintf("%s/%s/%s", obj.GetKind(), ns, obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+}
+
+// writeTranslatedObjectsDiff writes the unified diff of each object added, removed or changed between the previous
+// and the current objects, sorted by kind, namespace and name. Nothing is written if the objects are the same.
+func writeTranslatedObjectsDiff(w io.Writer, previous, current []*unstructured.Unstructured, format string) error {
+	index := func(objs []*unstructured.Unstructured) map[string][]byte {
+		ret := make(map[string][]byte, len(objs))
+		for _, obj := range objs {
+			ret[translatedObjectKey(obj)] = marshalTranslatedObject(obj, format)
+		}
+		return ret
+	}
+	previousObjs, currentObjs := index(previous), index(current)
+	keys := slices.Collect(maps.Keys(previousObjs))
+	for key := range currentObjs {
+		if _, ok := previousObjs[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		before, hadBefore := previousObjs[key]
+		after, hasAfter := currentObjs[key]
+		if bytes.Equal(before, after) {
+			continue
+		}
+		fromFile, toFile := "a/"+key, "b/"+key
+		if !hadBefore {
+			fromFile = "/dev/null"
+		}
+		if !hasAfter {
+			toFile = "/dev/null"
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(before)),
+			B:        difflib.SplitLines(string(after)),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("error computing the diff of %s: %w", key, err)
+		}
+		if _, err = io.WriteString(w, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readYamlsAsString reads the files at the given paths and combines them into a single string. The path "-", or no
+// path at all, reads the given standard input.
+func readYamlsAsString(paths []string, stdin io.Reader) (string, error) {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
 	var buf strings.Builder
 	for _, path := range paths {
-		content, err := os.ReadFile(path)
+		var (
+			content []byte
+			err     error
+		)
+		if path == "-" {
+			content, err = io.ReadAll(stdin)
+		} else {
+			content, err = os.ReadFile(expandPath(path))
+		}
 		if err != nil {
 			return "", fmt.Errorf("error reading file %s: %w", path, err)
 		}
@@ -189,6 +420,9 @@ func collectObjects(yamlInput string, out io.Writer, logger *slog.Logger) (
 }
 
 // translateCustomResourceObjects translates the AI Gateway custom resources to Envoy Gateway and Kubernetes objects.
+//
+// When tolerateReconcileErrors is true, the reconciliation errors are logged instead of panicking, so that they can
+// be found in the status conditions of the resources.
 func translateCustomResourceObjects(
 	ctx context.Context,
 	aigwRoutes []*aigv1b1.AIGatewayRoute,
@@ -199,6 +433,7 @@ func translateCustomResourceObjects(
 	gws []*gwapiv1.Gateway,
 	usedDefinedSecrets []*corev1.Secret,
 	logger *slog.Logger,
+	tolerateReconcileErrors bool,
 ) (
 	fakeClient client.Client,
 	fakeClientSet *fake2.Clientset,
@@ -265,19 +500,19 @@ func translateCustomResourceObjects(
 		mustCreate(ctx, fakeClient, btp, logger)
 	}
 	for _, bsp := range backendSecurityPolicies {
-		mustCreateAndReconcile(ctx, fakeClient, bsp, bspC, logger)
+		mustCreateAndReconcile(ctx, fakeClient, bsp, bspC, logger, tolerateReconcileErrors)
 	}
 	for _, backend := range aigwBackends {
-		mustCreateAndReconcile(ctx, fakeClient, backend, aisbC, logger)
+		mustCreateAndReconcile(ctx, fakeClient, backend, aisbC, logger, tolerateReconcileErrors)
 	}
 	for _, route := range aigwRoutes {
-		mustCreateAndReconcile(ctx, fakeClient, route, airC, logger)
+		mustCreateAndReconcile(ctx, fakeClient, route, airC, logger, tolerateReconcileErrors)
 	}
 	for _, mcpRoute := range mcpRoutes {
-		mustCreateAndReconcile(ctx, fakeClient, mcpRoute, mcpC, logger)
+		mustCreateAndReconcile(ctx, fakeClient, mcpRoute, mcpC, logger, tolerateReconcileErrors)
 	}
 	for _, gw := range gws {
-		mustReconcile(ctx, gw, gwC, logger)
+		mustReconcile(ctx, gw, gwC, logger, tolerateReconcileErrors)
 	}
 
 	// Now you can retrieve the translated objects from the fake client.
@@ -355,21 +590,28 @@ func mustCreateAndReconcile(
 	fakeClient client.Client, obj client.Object,
 	c reconcile.TypedReconciler[reconcile.Request],
 	logger *slog.Logger,
+	tolerateErrors bool,
 ) {
 	mustCreate(ctx, fakeClient, obj, logger)
-	mustReconcile(ctx, obj, c, logger)
+	mustReconcile(ctx, obj, c, logger, tolerateErrors)
 }
 
-// mustReconcile reconciles the object using the provided reconciler.
+// mustReconcile reconciles the object using the provided reconciler. The reconciliation errors are logged if
+// tolerateErrors is true, and panic otherwise.
 func mustReconcile(
 	ctx context.Context,
 	obj client.Object,
 	c reconcile.TypedReconciler[reconcile.Request],
 	logger *slog.Logger,
+	tolerateErrors bool,
 ) {
 	logger.Info("Fake reconciling", "kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
 	_, err := c.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}})
 	if err != nil {
+		if tolerateErrors {
+			logger.Error("Failed to reconcile", "kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName(), "error", err)
+			return
+		}
 		panic(err)
 	}
 }
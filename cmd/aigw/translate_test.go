@@ -10,6 +10,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
@@ -22,6 +23,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gwapiv1a3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
 )
 
 func Test_translate(t *testing.T) {
@@ -48,7 +51,9 @@ func Test_translate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			buf := &bytes.Buffer{}
 			// Multiple files should be supported and duplicated resources should be deduplicated.
-			err := translate(t.Context(), []string{tc.in, tc.in}, buf, os.Stderr)
+			input, err := readYamlsAsString([]string{tc.in, tc.in}, nil)
+			require.NoError(t, err)
+			err = translate(t.Context(), input, buf, os.Stderr, true)
 			require.NoError(t, err)
 			outBuf, err := os.ReadFile(tc.out)
 			require.NoError(t, err)
@@ -77,6 +82,79 @@ func Test_translate(t *testing.T) {
 	}
 }
 
+func Test_translateCmd(t *testing.T) {
+	input, err := os.ReadFile("testdata/translate_basic.in.yaml")
+	require.NoError(t, err)
+	expected := &bytes.Buffer{}
+	require.NoError(t, translate(t.Context(), string(input), expected, io.Discard, false))
+	expectedObjs, err := decodeTranslatedObjects(expected.Bytes())
+	require.NoError(t, err)
+	require.NotEmpty(t, expectedObjs)
+
+	t.Run("yaml from stdin", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		c := &cmdTranslate{Output: "yaml", stdin: bytes.NewReader(input)}
+		require.NoError(t, translateCmd(t.Context(), c, out, io.Discard))
+		objs, err := decodeTranslatedObjects(out.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, expectedObjs, objs)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		c := &cmdTranslate{Paths: []string{"testdata/translate_basic.in.yaml"}, Output: "json"}
+		require.NoError(t, translateCmd(t.Context(), c, out, io.Discard))
+		var list map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &list))
+		require.Equal(t, "List", list["kind"])
+		require.Len(t, list["items"], len(expectedObjs))
+		objs, err := decodeTranslatedObjects(out.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, expectedObjs, objs)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		// The previous output lacks the first object, and has a different label on the second one.
+		previousObjs := []*unstructured.Unstructured{expectedObjs[1].DeepCopy()}
+		previousObjs[0].SetLabels(map[string]string{"previous": "true"})
+		previousObjs = append(previousObjs, expectedObjs[2:]...)
+		previous := &bytes.Buffer{}
+		require.NoError(t, writeTranslatedObjects(previous, previousObjs, "yaml"))
+		previousPath := t.TempDir() + "/previous.yaml"
+		require.NoError(t, os.WriteFile(previousPath, previous.Bytes(), 0o600))
+
+		out := &bytes.Buffer{}
+		c := &cmdTranslate{Paths: []string{"testdata/translate_basic.in.yaml"}, Output: "yaml", Diff: previousPath}
+		require.NoError(t, translateCmd(t.Context(), c, out, io.Discard))
+		added, changed := translatedObjectKey(expectedObjs[0]), translatedObjectKey(expectedObjs[1])
+		require.Contains(t, out.String(), "--- /dev/null\n+++ b/"+added+"\n")
+		require.Contains(t, out.String(), "--- a/"+changed+"\n+++ b/"+changed+"\n")
+		require.Contains(t, out.String(), "-    previous: \"true\"\n")
+		require.Equal(t, 2, strings.Count(out.String(), "\n+++ "))
+
+		// Nothing is printed when the output didn't change.
+		out.Reset()
+		c.Diff = t.TempDir() + "/current.json"
+		current := &bytes.Buffer{}
+		require.NoError(t, writeTranslatedObjects(current, expectedObjs, "json"))
+		require.NoError(t, os.WriteFile(c.Diff, current.Bytes(), 0o600))
+		require.NoError(t, translateCmd(t.Context(), c, out, io.Discard))
+		require.Empty(t, out.String())
+
+		c.Diff = t.TempDir() + "/missing.yaml"
+		require.ErrorContains(t, translateCmd(t.Context(), c, out, io.Discard), "error reading previous output")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		c := &cmdTranslate{Paths: []string{"testdata/translate_invalid.in.yaml"}, Output: "yaml", Validate: true}
+		err := translateCmd(t.Context(), c, out, io.Discard)
+		require.EqualError(t, err, "invalid resources:\nAIServiceBackend default/openai: multiple BackendSecurityPolicies "+
+			"found for AIServiceBackend openai: [openai-apikey openai-apikey-duplicate]")
+		require.Empty(t, out.String())
+	})
+}
+
 func requireCollectTranslatedObjects(t *testing.T, yamlInput string) (
 	outHTTPRoutes []gwapiv1.HTTPRoute,
 	outEnvoyExtensionPolicy []egv1a1.EnvoyExtensionPolicy,
@@ -154,11 +232,21 @@ func Test_readYamlsAsString(t *testing.T) {
 	err = os.WriteFile(p2, []byte("bar"), 0o600)
 	require.NoError(t, err)
 
-	got, err := readYamlsAsString([]string{p1, p2})
+	got, err := readYamlsAsString([]string{p1, "-", p2}, strings.NewReader("baz"))
 	require.NoError(t, err)
 	assert.Equal(t, `foo
 ---
+baz
+---
 bar
 ---
 `, got)
+
+	// The standard input is read when no path is given.
+	got, err = readYamlsAsString(nil, strings.NewReader("baz"))
+	require.NoError(t, err)
+	assert.Equal(t, "baz\n---\n", got)
+
+	_, err = readYamlsAsString([]string{tmpDir + "/missing.yaml"}, nil)
+	require.ErrorContains(t, err, "error reading file")
 }
@@ -23,6 +23,8 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 	"github.com/envoyproxy/ai-gateway/internal/controller"
 	"github.com/envoyproxy/ai-gateway/internal/extensionserver"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
@@ -382,10 +385,16 @@ func main() {
 
 	setupLog.Info("configuring kubernetes cache", "watch-namespaces", parsedFlags.watchNamespaces, "sync-timeout", parsedFlags.cacheSyncTimeout)
 
+	cacheOpts, err := setupCache(parsedFlags)
+	if err != nil {
+		setupLog.Error(err, "failed to set up the kubernetes cache")
+		os.Exit(1)
+	}
+
 	ctx := ctrl.SetupSignalHandler()
 	pprof.Run(ctx)
 	mgrOpts := ctrl.Options{
-		Cache:            setupCache(parsedFlags),
+		Cache:            cacheOpts,
 		Controller:       config.Controller{CacheSyncTimeout: parsedFlags.cacheSyncTimeout},
 		Scheme:           controller.Scheme,
 		LeaderElection:   parsedFlags.enableLeaderElection,
@@ -501,7 +510,7 @@ func maybePatchAdmissionWebhook(ctx context.Context, cli client.Client, bundlePa
 }
 
 // setupCache sets up the cache options based on the provided flags.
-func setupCache(f *flags) cache.Options {
+func setupCache(f *flags) (cache.Options, error) {
 	var namespaceCacheConfig map[string]cache.Config
 	if len(f.watchNamespaces) > 0 {
 		namespaceCacheConfig = make(map[string]cache.Config, len(f.watchNamespaces))
@@ -510,8 +519,16 @@ func setupCache(f *flags) cache.Options {
 		}
 	}
 
+	// Only the ConfigMaps referenced by the MCPRoutes are watched, so the others are kept out of the cache.
+	mcpRouteConfigMap, err := labels.NewRequirement(aigv1b1.MCPRouteConfigMapLabel, selection.Exists, nil)
+	if err != nil {
+		return cache.Options{}, fmt.Errorf("failed to create the ConfigMap label requirement: %w", err)
+	}
 	return cache.Options{
 		DefaultNamespaces: namespaceCacheConfig,
 		DefaultTransform:  cache.TransformStripManagedFields(),
-	}
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {Label: labels.NewSelector().Add(*mcpRouteConfigMap)},
+		},
+	}, nil
 }
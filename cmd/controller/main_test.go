@@ -14,9 +14,12 @@ import (
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 )
 
 func Test_parseAndValidateFlags(t *testing.T) {
@@ -319,21 +322,24 @@ func Test_parseAndValidateFlags_watchNamespaces(t *testing.T) {
 
 func TestSetupCache(t *testing.T) {
 	t.Run("default", func(t *testing.T) {
-		c := setupCache(&flags{})
+		c, err := setupCache(&flags{})
+		require.NoError(t, err)
 
 		require.NotNil(t, c.DefaultTransform)
 		require.Nil(t, c.DefaultNamespaces)
 	})
 
 	t.Run("empty watch namespaces", func(t *testing.T) {
-		c := setupCache(&flags{watchNamespaces: []string{}})
+		c, err := setupCache(&flags{watchNamespaces: []string{}})
+		require.NoError(t, err)
 
 		require.NotNil(t, c.DefaultTransform)
 		require.Nil(t, c.DefaultNamespaces)
 	})
 
 	t.Run("watch namespaces", func(t *testing.T) {
-		c := setupCache(&flags{watchNamespaces: []string{"default", "envoy-ai-gateway-system"}})
+		c, err := setupCache(&flags{watchNamespaces: []string{"default", "envoy-ai-gateway-system"}})
+		require.NoError(t, err)
 
 		require.NotNil(t, c.DefaultTransform)
 		require.Equal(t, map[string]cache.Config{
@@ -341,4 +347,16 @@ func TestSetupCache(t *testing.T) {
 			"envoy-ai-gateway-system": {},
 		}, c.DefaultNamespaces)
 	})
+
+	t.Run("configmaps", func(t *testing.T) {
+		c, err := setupCache(&flags{})
+		require.NoError(t, err)
+
+		require.Len(t, c.ByObject, 1)
+		for obj, byObject := range c.ByObject {
+			require.IsType(t, &corev1.ConfigMap{}, obj)
+			require.True(t, byObject.Label.Matches(labels.Set{aigv1b1.MCPRouteConfigMapLabel: "true"}))
+			require.False(t, byObject.Label.Matches(labels.Set{"app": "foo"}))
+		}
+	})
 }
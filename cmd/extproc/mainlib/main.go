@@ -26,14 +26,18 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
 	"github.com/envoyproxy/ai-gateway/internal/endpointspec"
 	"github.com/envoyproxy/ai-gateway/internal/extproc"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/limitbackend"
 	"github.com/envoyproxy/ai-gateway/internal/mcpproxy"
 	"github.com/envoyproxy/ai-gateway/internal/metrics"
 	"github.com/envoyproxy/ai-gateway/internal/requestheaderattrs"
+	"github.com/envoyproxy/ai-gateway/internal/semaphore"
 	"github.com/envoyproxy/ai-gateway/internal/tracing"
+	"github.com/envoyproxy/ai-gateway/internal/usagerecord"
 	"github.com/envoyproxy/ai-gateway/internal/version"
 )
 
@@ -60,6 +64,12 @@ type extProcFlags struct {
 	maxRecvMsgSize int
 	// endpointPrefixes is the comma-separated key-value pairs for endpoint prefixes.
 	endpointPrefixes string
+	// usageRecord is the configuration of the per-request usage record export.
+	usageRecord usagerecord.Config
+	// concurrencyLimit is the configuration of the semaphores of the QuotaPolicy concurrency limits.
+	concurrencyLimit semaphore.Config
+	// mcpRateLimit is the configuration of the counters of the MCPRoute tool rate limits.
+	mcpRateLimit limitbackend.Config
 }
 
 func setOptionalString(dst **string) func(string) error {
@@ -138,10 +148,60 @@ func parseAndValidateFlags(args []string) (extProcFlags, error) {
 		"Number of iterations used in the fallback PBKDF2 key derivation for MCP session encryption.")
 	fs.DurationVar(&flags.mcpWriteTimeout, "mcpWriteTimeout", 120*time.Second,
 		"The maximum duration before timing out writes of the MCP response")
+	fs.Func("usageRecordSink",
+		"The sink of per-request usage records. One of 'file', 'otlp', or 'webhook'. Usage records are disabled when unset.",
+		func(value string) error {
+			flags.usageRecord.Sink = usagerecord.SinkType(value)
+			return nil
+		},
+	)
+	fs.StringVar(&flags.usageRecord.FilePath, "usageRecordFilePath", "",
+		"Path of the JSON lines file that usage records are appended to when usageRecordSink is 'file'.")
+	usageRecordFileMaxSizeMB := fs.Int64("usageRecordFileMaxSizeMB", 100,
+		"Size in megabytes at which the usage record file is rotated. Set to 0 to disable rotation.")
+	fs.IntVar(&flags.usageRecord.FileMaxBackups, "usageRecordFileMaxBackups", 5,
+		"Number of rotated usage record files to keep.")
+	fs.StringVar(&flags.usageRecord.WebhookURL, "usageRecordWebhookURL", "",
+		"URL that batches of usage records are POSTed to when usageRecordSink is 'webhook'.")
+	fs.IntVar(&flags.usageRecord.WebhookMaxRetries, "usageRecordWebhookMaxRetries", 3,
+		"Number of retries of a usage record batch that failed to be delivered to the webhook.")
+	fs.IntVar(&flags.usageRecord.BatchSize, "usageRecordBatchSize", 100,
+		"Maximum number of usage records written to the sink at once.")
+	fs.IntVar(&flags.usageRecord.BufferSize, "usageRecordBufferSize", 10_000,
+		"Number of usage records buffered in memory before new records are dropped.")
+	fs.DurationVar(&flags.usageRecord.FlushInterval, "usageRecordFlushInterval", 5*time.Second,
+		"Maximum duration a usage record is buffered before it is written to the sink.")
+	fs.Func("concurrencyLimitBackend",
+		"The backend of the QuotaPolicy concurrency limits. One of 'local' or 'redis'. With 'local', the limits are enforced per replica.",
+		func(value string) error {
+			flags.concurrencyLimit.Backend = limitbackend.Type(value)
+			return nil
+		},
+	)
+	fs.StringVar(&flags.concurrencyLimit.RedisURL, "concurrencyLimitRedisURL", "",
+		"URL of the Redis server shared by the replicas when concurrencyLimitBackend is 'redis', e.g. 'redis://localhost:6379/0'.")
+	fs.DurationVar(&flags.concurrencyLimit.LeaseTTL, "concurrencyLimitLeaseTTL", 30*time.Second,
+		"Duration after which the concurrency limit slots held by a replica that stopped renewing them are released.")
+	fs.Func("mcpRateLimitBackend",
+		"The backend of the MCPRoute tool rate limits. One of 'local' or 'redis'. With 'local', the limits are enforced per replica.",
+		func(value string) error {
+			flags.mcpRateLimit.Backend = limitbackend.Type(value)
+			return nil
+		},
+	)
+	fs.StringVar(&flags.mcpRateLimit.RedisURL, "mcpRateLimitRedisURL", "",
+		"URL of the Redis server shared by the replicas when mcpRateLimitBackend is 'redis', e.g. 'redis://localhost:6379/0'.")
 
 	if err := fs.Parse(args); err != nil {
 		return extProcFlags{}, fmt.Errorf("failed to parse extProcFlags: %w", err)
 	}
+	flags.usageRecord.FileMaxSizeBytes = *usageRecordFileMaxSizeMB * 1024 * 1024
+	if flags.concurrencyLimit.Backend == "" {
+		flags.concurrencyLimit.Backend = limitbackend.TypeLocal
+	}
+	if flags.mcpRateLimit.Backend == "" {
+		flags.mcpRateLimit.Backend = limitbackend.TypeLocal
+	}
 
 	if flags.configPath == "" {
 		errs = append(errs, fmt.Errorf("configPath must be provided"))
@@ -174,6 +234,15 @@ func parseAndValidateFlags(args []string) (extProcFlags, error) {
 			errs = append(errs, fmt.Errorf("failed to parse endpoint prefixes: %w", err))
 		}
 	}
+	if err := flags.usageRecord.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid usage record configuration: %w", err))
+	}
+	if err := flags.concurrencyLimit.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid concurrency limit configuration: %w", err))
+	}
+	if err := flags.mcpRateLimit.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid MCP rate limit configuration: %w", err))
+	}
 
 	return flags, errors.Join(errs...)
 }
@@ -289,6 +358,22 @@ func Main(ctx context.Context, args []string, stderr io.Writer) (err error) {
 	mcpMetrics := metrics.NewMCP(meter, metricsRequestHeaderAttributes)
 
 	extproc.LogRequestHeaderAttributes = logRequestHeaderAttributes
+	extproc.AdmissionMetrics = metrics.NewAdmission(meter)
+
+	usageRecordExporter, err := usagerecord.NewExporter(ctx, l.With("component", "usage-record"), flags.usageRecord)
+	if err != nil {
+		return fmt.Errorf("failed to create usage record exporter: %w", err)
+	}
+	if usageRecordExporter != nil {
+		l.Info("usage records are enabled", slog.String("sink", string(flags.usageRecord.Sink)))
+		extproc.UsageRecordExporter = usageRecordExporter
+	}
+
+	concurrencyLimiter, err := semaphore.New(l.With("component", "concurrency-limit"), flags.concurrencyLimit)
+	if err != nil {
+		return fmt.Errorf("failed to create concurrency limiter: %w", err)
+	}
+	extproc.ConcurrencyLimiter = concurrencyLimiter
 
 	server, err := extproc.NewServer(l, flags.enableRedaction)
 	if err != nil {
@@ -322,6 +407,7 @@ func Main(ctx context.Context, args []string, stderr io.Writer) (err error) {
 	}
 
 	var mcpServer *http.Server
+	var mcpRateLimiter callcounter.Limiter
 	if mcpLis != nil {
 		mcpSessionCrypto := mcpproxy.NewPBKDF2AesGcmSessionCrypto(flags.mcpSessionEncryptionSeed, flags.mcpSessionEncryptionIterations)
 		if flags.mcpFallbackSessionEncryptionSeed != "" {
@@ -334,10 +420,15 @@ func Main(ctx context.Context, args []string, stderr io.Writer) (err error) {
 			}
 		}
 
+		mcpRateLimiter, err = callcounter.New(flags.mcpRateLimit)
+		if err != nil {
+			return fmt.Errorf("failed to create MCP rate limiter: %w", err)
+		}
+
 		var mcpProxyMux *http.ServeMux
 		var mcpProxyConfig *mcpproxy.ProxyConfig
 		mcpProxyConfig, mcpProxyMux, err = mcpproxy.NewMCPProxy(l.With("component", "mcp-proxy"), mcpMetrics,
-			tracing.MCPTracer(), mcpSessionCrypto, logRequestHeaderAttributes)
+			tracing.MCPTracer(), mcpSessionCrypto, logRequestHeaderAttributes, mcpRateLimiter)
 		if err != nil {
 			return fmt.Errorf("failed to create MCP proxy: %w", err)
 		}
@@ -397,6 +488,19 @@ func Main(ctx context.Context, args []string, stderr io.Writer) (err error) {
 				l.Error("Failed to shutdown mcp proxy server gracefully", "error", err)
 			}
 		}
+		if usageRecordExporter != nil {
+			if err := usageRecordExporter.Shutdown(shutdownCtx); err != nil {
+				l.Error("Failed to shutdown usage record exporter gracefully", "error", err)
+			}
+		}
+		if err := concurrencyLimiter.Close(); err != nil {
+			l.Error("Failed to close concurrency limiter", "error", err)
+		}
+		if mcpRateLimiter != nil {
+			if err := mcpRateLimiter.Close(); err != nil {
+				l.Error("Failed to close MCP rate limiter", "error", err)
+			}
+		}
 	}()
 
 	// Emit startup message to stderr when all listeners are ready.
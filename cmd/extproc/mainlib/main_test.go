@@ -18,6 +18,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/limitbackend"
+	"github.com/envoyproxy/ai-gateway/internal/semaphore"
+	"github.com/envoyproxy/ai-gateway/internal/usagerecord"
 )
 
 func Test_parseAndValidateFlags(t *testing.T) {
@@ -170,6 +174,66 @@ func Test_parseAndValidateFlags(t *testing.T) {
 		}
 	})
 
+	t.Run("usage record flags", func(t *testing.T) {
+		flags, err := parseAndValidateFlags([]string{
+			"-configPath", "/path/to/config.yaml",
+			"-usageRecordSink", "file",
+			"-usageRecordFilePath", "/var/log/usage.jsonl",
+			"-usageRecordFileMaxSizeMB", "10",
+			"-usageRecordFlushInterval", "1s",
+		})
+		require.NoError(t, err)
+		require.Equal(t, usagerecord.Config{
+			Sink:              usagerecord.SinkTypeFile,
+			FilePath:          "/var/log/usage.jsonl",
+			FileMaxSizeBytes:  10 * 1024 * 1024,
+			FileMaxBackups:    5,
+			WebhookMaxRetries: 3,
+			BatchSize:         100,
+			BufferSize:        10_000,
+			FlushInterval:     time.Second,
+		}, flags.usageRecord)
+
+		flags, err = parseAndValidateFlags([]string{"-configPath", "/path/to/config.yaml"})
+		require.NoError(t, err)
+		require.Equal(t, usagerecord.SinkTypeNone, flags.usageRecord.Sink)
+	})
+
+	t.Run("concurrency limit flags", func(t *testing.T) {
+		flags, err := parseAndValidateFlags([]string{"-configPath", "/path/to/config.yaml"})
+		require.NoError(t, err)
+		require.Equal(t, semaphore.Config{
+			Config:   limitbackend.Config{Backend: limitbackend.TypeLocal},
+			LeaseTTL: 30 * time.Second,
+		}, flags.concurrencyLimit)
+
+		flags, err = parseAndValidateFlags([]string{
+			"-configPath", "/path/to/config.yaml",
+			"-concurrencyLimitBackend", "redis",
+			"-concurrencyLimitRedisURL", "redis://localhost:6379/0",
+			"-concurrencyLimitLeaseTTL", "10s",
+		})
+		require.NoError(t, err)
+		require.Equal(t, semaphore.Config{
+			Config:   limitbackend.Config{Backend: limitbackend.TypeRedis, RedisURL: "redis://localhost:6379/0"},
+			LeaseTTL: 10 * time.Second,
+		}, flags.concurrencyLimit)
+	})
+
+	t.Run("mcp rate limit flags", func(t *testing.T) {
+		flags, err := parseAndValidateFlags([]string{"-configPath", "/path/to/config.yaml"})
+		require.NoError(t, err)
+		require.Equal(t, limitbackend.Config{Backend: limitbackend.TypeLocal}, flags.mcpRateLimit)
+
+		flags, err = parseAndValidateFlags([]string{
+			"-configPath", "/path/to/config.yaml",
+			"-mcpRateLimitBackend", "redis",
+			"-mcpRateLimitRedisURL", "redis://localhost:6379/1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, limitbackend.Config{Backend: limitbackend.TypeRedis, RedisURL: "redis://localhost:6379/1"}, flags.mcpRateLimit)
+	})
+
 	t.Run("invalid extProcFlags", func(t *testing.T) {
 		tests := []struct {
 			name          string
@@ -201,6 +265,26 @@ func Test_parseAndValidateFlags(t *testing.T) {
 				args:          []string{"-configPath", "/path/to/config.yaml", "-spanRequestHeaderAttributes", ":session.id"},
 				expectedError: "failed to parse tracing header mapping: empty header or attribute at position 1: \":session.id\"",
 			},
+			{
+				name:          "invalid usage record sink",
+				args:          []string{"-configPath", "/path/to/config.yaml", "-usageRecordSink", "kafka"},
+				expectedError: "invalid usage record configuration: unknown usage record sink \"kafka\": must be one of file, otlp or webhook",
+			},
+			{
+				name:          "usage record file sink without path",
+				args:          []string{"-configPath", "/path/to/config.yaml", "-usageRecordSink", "file", "-usageRecordBatchSize", "0"},
+				expectedError: "invalid usage record configuration: a file path is required for the \"file\" usage record sink\nusage record batch size must be positive, got 0",
+			},
+			{
+				name:          "concurrency limit redis backend without URL",
+				args:          []string{"-configPath", "/path/to/config.yaml", "-concurrencyLimitBackend", "redis"},
+				expectedError: "invalid concurrency limit configuration: a Redis URL is required for the \"redis\" backend",
+			},
+			{
+				name:          "mcp rate limit unknown backend",
+				args:          []string{"-configPath", "/path/to/config.yaml", "-mcpRateLimitBackend", "memcached"},
+				expectedError: "invalid MCP rate limit configuration: unknown backend \"memcached\": must be one of local or redis",
+			},
 		}
 
 		for _, tt := range tests {
@@ -0,0 +1,323 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package admission implements the priority based admission control of the requests sent to
+// the AIServiceBackends configured with AIServiceBackendSpec.Admission.
+//
+// A [Controller] tracks the recent 429 responses and latencies of each backend. When they indicate
+// that a backend is under pressure, requests of the lower priority classes wait in a bounded queue
+// until the pressure decreases, so that the requests of the higher priority classes keep being served.
+// Waiting requests are shed when the queue is full or when they time out.
+package admission
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+// Decision is the outcome of the admission of a request.
+type Decision string
+
+const (
+	// DecisionAdmitted is the decision for a request admitted without waiting.
+	DecisionAdmitted Decision = "admitted"
+	// DecisionQueued is the decision for a request admitted after waiting in the queue.
+	DecisionQueued Decision = "queued"
+	// DecisionShed is the decision for a request rejected because the queue is full, including
+	// a queued request evicted by a request of a higher priority class.
+	DecisionShed Decision = "shed"
+	// DecisionTimeout is the decision for a request rejected after waiting for the queue timeout.
+	DecisionTimeout Decision = "timeout"
+	// DecisionCanceled is the decision for a request whose context was canceled while waiting.
+	DecisionCanceled Decision = "canceled"
+)
+
+const (
+	// numBuckets is the number of buckets the window is divided into.
+	numBuckets = 10
+	// minSamples is the number of responses in the window below which the backend is never under pressure,
+	// so that a single 429 response on an idle backend does not trigger the queueing.
+	minSamples = 10
+	// pollInterval is the interval at which waiting requests re-evaluate the pressure as the old signals
+	// leave the window, in addition to being notified of new signals.
+	pollInterval = 100 * time.Millisecond
+)
+
+// Result is the result of [Controller.Admit].
+type Result struct {
+	// Decision is the admission decision.
+	Decision Decision
+	// Wait is the time the request spent in the queue.
+	Wait time.Duration
+	// Pressure is the pressure of the backend at the time of the decision. See [Controller.Pressure].
+	Pressure float64
+}
+
+// Admitted returns true if the request can be sent to the backend.
+func (r Result) Admitted() bool {
+	return r.Decision == DecisionAdmitted || r.Decision == DecisionQueued
+}
+
+// Controller tracks the signals of the backends and decides on the admission of the requests.
+// It is safe for concurrent use.
+type Controller struct {
+	mu       sync.Mutex
+	backends map[string]*backend
+	// now is replaceable for testing.
+	now func() time.Time
+}
+
+type (
+	// backend is the state of one AIServiceBackend, identified by filterapi.BackendAdmission.Key.
+	backend struct {
+		// width is the duration of each bucket. The buckets are reset when the window changes.
+		width   time.Duration
+		buckets [numBuckets]bucket
+		// waiters are the queued requests, in the order they were queued.
+		waiters []*waiter
+		// notify is closed and replaced whenever the waiters should re-evaluate the admission.
+		notify chan struct{}
+	}
+	// bucket aggregates the signals of the responses received within one bucket of the window.
+	bucket struct {
+		// index is the number of bucket widths since the Unix epoch.
+		index     int64
+		total     uint64
+		throttled uint64
+		latency   time.Duration
+	}
+	// waiter is a request waiting in the queue.
+	waiter struct {
+		rank int
+		// evicted is closed when the waiter is evicted by a request of a higher priority class.
+		evicted chan struct{}
+	}
+)
+
+// NewController creates a new [Controller].
+func NewController() *Controller {
+	return &Controller{backends: make(map[string]*backend), now: time.Now}
+}
+
+// The ranks of the priority classes.
+const (
+	rankLow = iota
+	rankNormal
+	rankHigh
+)
+
+// rank orders the priority classes. Unknown priority classes are treated as [filterapi.PriorityClassNormal].
+func rank(p filterapi.PriorityClass) int {
+	switch p {
+	case filterapi.PriorityClassHigh:
+		return rankHigh
+	case filterapi.PriorityClassLow:
+		return rankLow
+	default:
+		return rankNormal
+	}
+}
+
+// allows returns true if a request of the given rank can be admitted under the given pressure:
+// "Low" requests below 1, "Normal" requests below 2, and "High" requests always.
+func allows(rank int, pressure float64) bool {
+	return rank == rankHigh || pressure < float64(rank+1)
+}
+
+// Record records the response of the backend to a request admitted with the given configuration.
+func (c *Controller) Record(cfg *filterapi.BackendAdmission, statusCode int, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.backendLocked(cfg)
+	bk := b.bucketLocked(c.now())
+	bk.total++
+	if statusCode == http.StatusTooManyRequests {
+		bk.throttled++
+	}
+	bk.latency += latency
+	b.notifyLocked()
+}
+
+// Pressure returns the current pressure of the backend.
+//
+// The pressure is the highest of the ratio of the percentage of 429 responses to the threshold, and
+// the ratio of the average latency to the threshold, over the window. "Low" requests are queued from 1,
+// and "Normal" requests from 2. "High" requests are never queued.
+func (c *Controller) Pressure(cfg *filterapi.BackendAdmission) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.backendLocked(cfg).pressureLocked(c.now(), cfg)
+}
+
+// Admit decides on the admission of a request of the given priority class to the backend.
+//
+// When the backend is under pressure, this blocks until the request is admitted, evicted by a request of
+// a higher priority class, times out after cfg.QueueTimeout, or ctx is canceled.
+func (c *Controller) Admit(ctx context.Context, cfg *filterapi.BackendAdmission, priority filterapi.PriorityClass) Result {
+	start := c.now()
+	r := rank(priority)
+
+	c.mu.Lock()
+	b := c.backendLocked(cfg)
+	pressure := b.pressureLocked(start, cfg)
+	if allows(r, pressure) && !b.hasWaiterAboveLocked(r) {
+		c.mu.Unlock()
+		return Result{Decision: DecisionAdmitted, Pressure: pressure}
+	}
+	if cfg.MaxQueueSize == 0 {
+		c.mu.Unlock()
+		return Result{Decision: DecisionShed, Pressure: pressure}
+	}
+	if len(b.waiters) >= int(cfg.MaxQueueSize) {
+		victim := b.lowestNewestWaiterLocked()
+		if victim.rank >= r {
+			c.mu.Unlock()
+			return Result{Decision: DecisionShed, Pressure: pressure}
+		}
+		b.removeWaiterLocked(victim)
+		close(victim.evicted)
+	}
+	w := &waiter{rank: r, evicted: make(chan struct{})}
+	b.waiters = append(b.waiters, w)
+	notify := b.notify
+	c.mu.Unlock()
+
+	timer := time.NewTimer(cfg.QueueTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.evicted:
+			return Result{Decision: DecisionShed, Wait: c.now().Sub(start), Pressure: pressure}
+		case <-timer.C:
+			return c.leave(b, w, DecisionTimeout, start, pressure)
+		case <-ctx.Done():
+			return c.leave(b, w, DecisionCanceled, start, pressure)
+		case <-notify:
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		select {
+		case <-w.evicted:
+			c.mu.Unlock()
+			return Result{Decision: DecisionShed, Wait: c.now().Sub(start), Pressure: pressure}
+		default:
+		}
+		now := c.now()
+		pressure = b.pressureLocked(now, cfg)
+		if allows(r, pressure) && !b.hasWaiterAboveLocked(r) {
+			b.removeWaiterLocked(w)
+			b.notifyLocked()
+			c.mu.Unlock()
+			return Result{Decision: DecisionQueued, Wait: now.Sub(start), Pressure: pressure}
+		}
+		notify = b.notify
+		c.mu.Unlock()
+	}
+}
+
+// leave removes the waiter from the queue unless it has been evicted in the meantime.
+func (c *Controller) leave(b *backend, w *waiter, decision Decision, start time.Time, pressure float64) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-w.evicted:
+		decision = DecisionShed
+	default:
+		b.removeWaiterLocked(w)
+		b.notifyLocked()
+	}
+	return Result{Decision: decision, Wait: c.now().Sub(start), Pressure: pressure}
+}
+
+// backendLocked returns the state of the backend, creating it if needed.
+func (c *Controller) backendLocked(cfg *filterapi.BackendAdmission) *backend {
+	b, ok := c.backends[cfg.Key]
+	if !ok {
+		b = &backend{notify: make(chan struct{})}
+		c.backends[cfg.Key] = b
+	}
+	if width := max(cfg.Window/numBuckets, time.Millisecond); b.width != width {
+		b.width = width
+		b.buckets = [numBuckets]bucket{}
+	}
+	return b
+}
+
+// bucketLocked returns the bucket for the given time, resetting it if it holds signals of a previous window.
+func (b *backend) bucketLocked(now time.Time) *bucket {
+	index := now.UnixNano() / int64(b.width)
+	bk := &b.buckets[index%numBuckets]
+	if bk.index != index {
+		*bk = bucket{index: index}
+	}
+	return bk
+}
+
+// pressureLocked implements [Controller.Pressure].
+func (b *backend) pressureLocked(now time.Time, cfg *filterapi.BackendAdmission) float64 {
+	index := now.UnixNano() / int64(b.width)
+	var total, throttled uint64
+	var latency time.Duration
+	for i := range b.buckets {
+		bk := &b.buckets[i]
+		if bk.index <= index-numBuckets || bk.index > index {
+			continue
+		}
+		total += bk.total
+		throttled += bk.throttled
+		latency += bk.latency
+	}
+	if total < minSamples {
+		return 0
+	}
+	var pressure float64
+	if cfg.ThrottledPercentThreshold > 0 {
+		pressure = float64(throttled) * 100 / float64(total) / float64(cfg.ThrottledPercentThreshold)
+	}
+	if cfg.LatencyThreshold > 0 {
+		average := latency / time.Duration(total) //nolint:gosec
+		pressure = max(pressure, float64(average)/float64(cfg.LatencyThreshold))
+	}
+	return pressure
+}
+
+// hasWaiterAboveLocked returns true if a request of a higher priority class than the given rank is queued.
+func (b *backend) hasWaiterAboveLocked(rank int) bool {
+	return slices.ContainsFunc(b.waiters, func(w *waiter) bool { return w.rank > rank })
+}
+
+// lowestNewestWaiterLocked returns the most recently queued waiter of the lowest priority class.
+func (b *backend) lowestNewestWaiterLocked() *waiter {
+	var victim *waiter
+	for _, w := range b.waiters {
+		if victim == nil || w.rank <= victim.rank {
+			victim = w
+		}
+	}
+	return victim
+}
+
+func (b *backend) removeWaiterLocked(w *waiter) {
+	if i := slices.Index(b.waiters, w); i >= 0 {
+		b.waiters = slices.Delete(b.waiters, i, i+1)
+	}
+}
+
+// notifyLocked wakes up the waiters so that they re-evaluate the admission.
+func (b *backend) notifyLocked() {
+	if len(b.waiters) == 0 {
+		return
+	}
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
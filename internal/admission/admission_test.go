@@ -0,0 +1,251 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package admission
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+func newTestController(now time.Time) (*Controller, func(time.Duration)) {
+	c := NewController()
+	var mu sync.Mutex
+	c.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	return c, func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+}
+
+func testConfig() *filterapi.BackendAdmission {
+	return &filterapi.BackendAdmission{
+		Key:                       "default/azure",
+		Window:                    10 * time.Second,
+		ThrottledPercentThreshold: 10,
+		LatencyThreshold:          2 * time.Second,
+		MaxQueueSize:              2,
+		QueueTimeout:              5 * time.Second,
+	}
+}
+
+// recordN records n responses with the given status code and latency.
+func recordN(c *Controller, cfg *filterapi.BackendAdmission, n, statusCode int, latency time.Duration) {
+	for range n {
+		c.Record(cfg, statusCode, latency)
+	}
+}
+
+// waitForWaiters waits until the given number of requests are queued for the backend.
+func waitForWaiters(t *testing.T, c *Controller, cfg *filterapi.BackendAdmission, n int) {
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.backends[cfg.Key].waiters) == n
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestController_Pressure(t *testing.T) {
+	c, advance := newTestController(time.Unix(1000, 0))
+	cfg := testConfig()
+
+	t.Run("no samples", func(t *testing.T) {
+		require.Zero(t, c.Pressure(cfg))
+	})
+
+	t.Run("below minimum samples", func(t *testing.T) {
+		recordN(c, cfg, minSamples-1, http.StatusTooManyRequests, 0)
+		require.Zero(t, c.Pressure(cfg))
+	})
+
+	t.Run("throttled", func(t *testing.T) {
+		// 10 throttled out of 50 is 20%, twice the threshold.
+		recordN(c, cfg, 40, http.StatusOK, 0)
+		recordN(c, cfg, 1, http.StatusTooManyRequests, 0)
+		require.InDelta(t, 2.0, c.Pressure(cfg), 1e-9)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		other := testConfig()
+		other.Key = "default/other"
+		recordN(c, other, 10, http.StatusOK, 3*time.Second)
+		require.InDelta(t, 1.5, c.Pressure(other), 1e-9)
+
+		other.LatencyThreshold = 0
+		require.Zero(t, c.Pressure(other))
+	})
+
+	t.Run("signals leave the window", func(t *testing.T) {
+		advance(5 * time.Second)
+		recordN(c, cfg, 50, http.StatusOK, 0)
+		// 10 throttled out of 100 is 10%.
+		require.InDelta(t, 1.0, c.Pressure(cfg), 1e-9)
+		advance(6 * time.Second)
+		// Only the last 50 responses remain.
+		require.Zero(t, c.Pressure(cfg))
+		advance(10 * time.Second)
+		require.Zero(t, c.Pressure(cfg))
+	})
+
+	t.Run("window change resets the signals", func(t *testing.T) {
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+		require.InDelta(t, 10.0, c.Pressure(cfg), 1e-9)
+		changed := testConfig()
+		changed.Window = time.Minute
+		require.Zero(t, c.Pressure(changed))
+	})
+}
+
+func TestController_Admit(t *testing.T) {
+	t.Run("not under pressure", func(t *testing.T) {
+		c, _ := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		recordN(c, cfg, 20, http.StatusOK, 0)
+		for _, p := range []filterapi.PriorityClass{filterapi.PriorityClassLow, filterapi.PriorityClassNormal, filterapi.PriorityClassHigh, ""} {
+			res := c.Admit(t.Context(), cfg, p)
+			require.Equal(t, DecisionAdmitted, res.Decision, p)
+			require.True(t, res.Admitted())
+		}
+	})
+
+	t.Run("priority classes under pressure", func(t *testing.T) {
+		c, _ := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		cfg.MaxQueueSize = 0
+		// 15% throttled: pressure 1.5 queues only Low.
+		recordN(c, cfg, 17, http.StatusOK, 0)
+		recordN(c, cfg, 3, http.StatusTooManyRequests, 0)
+		require.Equal(t, DecisionShed, c.Admit(t.Context(), cfg, filterapi.PriorityClassLow).Decision)
+		require.Equal(t, DecisionAdmitted, c.Admit(t.Context(), cfg, filterapi.PriorityClassNormal).Decision)
+		require.Equal(t, DecisionAdmitted, c.Admit(t.Context(), cfg, filterapi.PriorityClassHigh).Decision)
+
+		// 50% throttled: pressure 5 queues Normal too, but never High.
+		recordN(c, cfg, 14, http.StatusTooManyRequests, 0)
+		res := c.Admit(t.Context(), cfg, filterapi.PriorityClassNormal)
+		require.Equal(t, DecisionShed, res.Decision)
+		require.False(t, res.Admitted())
+		require.InDelta(t, 5.0, res.Pressure, 1e-9)
+		require.Equal(t, DecisionAdmitted, c.Admit(t.Context(), cfg, filterapi.PriorityClassHigh).Decision)
+	})
+
+	t.Run("queued until the pressure decreases", func(t *testing.T) {
+		c, advance := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+
+		done := make(chan Result)
+		go func() { done <- c.Admit(t.Context(), cfg, filterapi.PriorityClassLow) }()
+		waitForWaiters(t, c, cfg, 1)
+
+		advance(time.Second)
+		// Successful responses bring the throttled percentage below the threshold.
+		recordN(c, cfg, 100, http.StatusOK, 0)
+		res := <-done
+		require.Equal(t, DecisionQueued, res.Decision)
+		require.True(t, res.Admitted())
+		require.Equal(t, time.Second, res.Wait)
+	})
+
+	t.Run("queued until the signals leave the window", func(t *testing.T) {
+		c, advance := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+
+		done := make(chan Result)
+		go func() { done <- c.Admit(t.Context(), cfg, filterapi.PriorityClassLow) }()
+		waitForWaiters(t, c, cfg, 1)
+
+		advance(cfg.Window)
+		require.Equal(t, DecisionQueued, (<-done).Decision)
+	})
+
+	t.Run("low waits for normal", func(t *testing.T) {
+		c, advance := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		cfg.MaxQueueSize = 10
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+
+		low := make(chan Result)
+		go func() { low <- c.Admit(t.Context(), cfg, filterapi.PriorityClassLow) }()
+		waitForWaiters(t, c, cfg, 1)
+		normal := make(chan Result)
+		go func() { normal <- c.Admit(t.Context(), cfg, filterapi.PriorityClassNormal) }()
+		waitForWaiters(t, c, cfg, 2)
+
+		advance(cfg.Window)
+		require.Equal(t, DecisionQueued, (<-normal).Decision)
+		require.Equal(t, DecisionQueued, (<-low).Decision)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		c, _ := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		cfg.QueueTimeout = 50 * time.Millisecond
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+
+		res := c.Admit(t.Context(), cfg, filterapi.PriorityClassNormal)
+		require.Equal(t, DecisionTimeout, res.Decision)
+		require.False(t, res.Admitted())
+		require.Empty(t, c.backends[cfg.Key].waiters)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		c, _ := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan Result)
+		go func() { done <- c.Admit(ctx, cfg, filterapi.PriorityClassLow) }()
+		waitForWaiters(t, c, cfg, 1)
+		cancel()
+		require.Equal(t, DecisionCanceled, (<-done).Decision)
+		require.Empty(t, c.backends[cfg.Key].waiters)
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		c, advance := newTestController(time.Unix(1000, 0))
+		cfg := testConfig()
+		recordN(c, cfg, 10, http.StatusTooManyRequests, 0)
+
+		first, second := make(chan Result), make(chan Result)
+		go func() { first <- c.Admit(t.Context(), cfg, filterapi.PriorityClassLow) }()
+		waitForWaiters(t, c, cfg, 1)
+		go func() { second <- c.Admit(t.Context(), cfg, filterapi.PriorityClassLow) }()
+		waitForWaiters(t, c, cfg, 2)
+
+		// A request of the same priority class is shed.
+		require.Equal(t, DecisionShed, c.Admit(t.Context(), cfg, filterapi.PriorityClassLow).Decision)
+
+		// A request of a higher priority class evicts the most recently queued Low request.
+		normal := make(chan Result)
+		go func() { normal <- c.Admit(t.Context(), cfg, filterapi.PriorityClassNormal) }()
+		require.Equal(t, DecisionShed, (<-second).Decision)
+		waitForWaiters(t, c, cfg, 2)
+
+		advance(cfg.Window)
+		require.Equal(t, DecisionQueued, (<-normal).Decision)
+		require.Equal(t, DecisionQueued, (<-first).Decision)
+	})
+}
+
+func TestRank(t *testing.T) {
+	require.Equal(t, rankHigh, rank(filterapi.PriorityClassHigh))
+	require.Equal(t, rankNormal, rank(filterapi.PriorityClassNormal))
+	require.Equal(t, rankLow, rank(filterapi.PriorityClassLow))
+	require.Equal(t, rankNormal, rank("unknown"))
+}
@@ -27,13 +27,7 @@ func PopulateAnthropicEnvConfig(data *ConfigData) error {
 		return fmt.Errorf("ANTHROPIC_API_KEY environment variable is required")
 	}
 
-	// Get base URL, defaulting to the official Anthropic API endpoint
-	baseURL := os.Getenv("ANTHROPIC_BASE_URL")
-	if baseURL == "" {
-		baseURL = "https://api.anthropic.com/v1"
-	}
-
-	parsed, err := parseURL(baseURL)
+	parsed, err := parseURL(anthropicBaseURL())
 	if err != nil {
 		return err
 	}
@@ -60,3 +54,12 @@ func PopulateAnthropicEnvConfig(data *ConfigData) error {
 
 	return nil
 }
+
+// anthropicBaseURL returns the base URL of the Anthropic API from ANTHROPIC_BASE_URL, defaulting to the official
+// endpoint.
+func anthropicBaseURL() string {
+	if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	return "https://api.anthropic.com/v1"
+}
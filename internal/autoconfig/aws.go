@@ -0,0 +1,61 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package autoconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrAWSRegionNotSet is returned by PopulateAWSBedrockEnvConfig when the AWS credentials are set, but not the region.
+var ErrAWSRegionNotSet = errors.New("AWS_REGION environment variable is required")
+
+// AWSBedrockEnvConfigured returns true if AWS credentials are configured with the standard AWS SDK environment
+// variables, either with a profile (AWS_PROFILE) or with access keys (AWS_ACCESS_KEY_ID).
+func AWSBedrockEnvConfigured() bool {
+	return os.Getenv("AWS_PROFILE") != "" || os.Getenv("AWS_ACCESS_KEY_ID") != ""
+}
+
+// PopulateAWSBedrockEnvConfig populates ConfigData with AWS Bedrock backend configuration
+// from standard AWS SDK environment variables.
+//
+// The credentials are not written to the configuration. Instead, they are resolved by the AWS SDK default
+// credential chain, which reads the same environment variables, shared config files, or SSO cache.
+//
+// This errs if neither AWS_PROFILE nor AWS_ACCESS_KEY_ID is set, or with ErrAWSRegionNotSet if the region can't be
+// determined from AWS_REGION or AWS_DEFAULT_REGION.
+//
+// See https://docs.aws.amazon.com/sdkref/latest/guide/environment-variables.html
+func PopulateAWSBedrockEnvConfig(data *ConfigData) error {
+	if data == nil {
+		return fmt.Errorf("ConfigData cannot be nil")
+	}
+
+	if !AWSBedrockEnvConfigured() {
+		return fmt.Errorf("AWS_PROFILE or AWS_ACCESS_KEY_ID environment variable is required")
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return ErrAWSRegionNotSet
+	}
+
+	data.Backends = append(data.Backends, Backend{
+		Name:     "aws-bedrock",
+		Hostname: fmt.Sprintf("bedrock-runtime.%s.amazonaws.com", region),
+		Port:     443,
+		NeedsTLS: true,
+	})
+	data.AWSBedrock = &AWSBedrockConfig{
+		BackendName: "aws-bedrock",
+		Region:      region,
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package autoconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestPopulateAWSBedrockEnvConfig(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		expected      ConfigData
+		expectedError string
+	}{
+		{
+			name: "profile",
+			envVars: map[string]string{
+				"AWS_PROFILE": "dev",
+				"AWS_REGION":  "us-west-2",
+			},
+			expected: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "aws-bedrock",
+						Hostname: "bedrock-runtime.us-west-2.amazonaws.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				AWSBedrock: &AWSBedrockConfig{
+					BackendName: "aws-bedrock",
+					Region:      "us-west-2",
+				},
+			},
+		},
+		{
+			name: "access keys with default region",
+			envVars: map[string]string{
+				"AWS_ACCESS_KEY_ID":  "AKIAEXAMPLE",
+				"AWS_DEFAULT_REGION": "eu-central-1",
+			},
+			expected: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "aws-bedrock",
+						Hostname: "bedrock-runtime.eu-central-1.amazonaws.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				AWSBedrock: &AWSBedrockConfig{
+					BackendName: "aws-bedrock",
+					Region:      "eu-central-1",
+				},
+			},
+		},
+		{
+			name: "AWS_REGION takes precedence over AWS_DEFAULT_REGION",
+			envVars: map[string]string{
+				"AWS_PROFILE":        "dev",
+				"AWS_REGION":         "us-east-1",
+				"AWS_DEFAULT_REGION": "eu-central-1",
+			},
+			expected: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "aws-bedrock",
+						Hostname: "bedrock-runtime.us-east-1.amazonaws.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				AWSBedrock: &AWSBedrockConfig{
+					BackendName: "aws-bedrock",
+					Region:      "us-east-1",
+				},
+			},
+		},
+		{
+			name:          "missing credentials",
+			envVars:       map[string]string{"AWS_REGION": "us-east-1"},
+			expectedError: "AWS_PROFILE or AWS_ACCESS_KEY_ID environment variable is required",
+		},
+		{
+			name:          "missing region",
+			envVars:       map[string]string{"AWS_PROFILE": "dev"},
+			expectedError: "AWS_REGION environment variable is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			var data ConfigData
+			err := PopulateAWSBedrockEnvConfig(&data)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, data)
+		})
+	}
+
+	t.Run("missing region is ErrAWSRegionNotSet", func(t *testing.T) {
+		t.Setenv("AWS_PROFILE", "dev")
+		require.ErrorIs(t, PopulateAWSBedrockEnvConfig(&ConfigData{}), ErrAWSRegionNotSet)
+	})
+
+	t.Run("nil ConfigData", func(t *testing.T) {
+		require.EqualError(t, PopulateAWSBedrockEnvConfig(nil), "ConfigData cannot be nil")
+	})
+}
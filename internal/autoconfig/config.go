@@ -48,6 +48,31 @@ type AnthropicConfig struct {
 	Version     string // API version (Anthropic path prefix)
 }
 
+// AWSBedrockConfig holds AWS Bedrock-specific configuration for generating AIServiceBackend resources.
+// This is nil when no AWS configuration is present.
+type AWSBedrockConfig struct {
+	BackendName string // References a Backend.Name (typically "aws-bedrock")
+	Region      string // AWS region of the Bedrock runtime endpoint
+}
+
+// GCPConfig holds GCP Vertex AI-specific configuration for generating the GCPVertexAI and GCPAnthropic
+// AIServiceBackend resources. This is nil when no GCP configuration is present.
+type GCPConfig struct {
+	BackendName          string // References a Backend.Name (typically "gcp-vertexai")
+	AnthropicBackendName string // Name of the GCPAnthropic AIServiceBackend (typically "gcp-anthropic")
+	ProjectName          string // GCP project name
+	Region               string // GCP location, e.g. "us-central1" or "global"
+}
+
+// ModelRoute is a rule of the generated AIGatewayRoute, routing the requests for the models matching a
+// regular expression to an AIServiceBackend.
+type ModelRoute struct {
+	BackendName    string // References an AIServiceBackend name
+	Provider       string // Human-readable provider name, e.g. "OpenAI"
+	Models         string // Regular expression matching the model names
+	RequiredHeader string // Name of a header that the requests must have, if any
+}
+
 // MCPBackendRef references a backend with MCP-specific routing configuration.
 // Used to generate MCPRoute backendRefs with path, tool filtering, and authentication.
 type MCPBackendRef struct {
@@ -59,15 +84,72 @@ type MCPBackendRef struct {
 }
 
 // ConfigData holds all template data for generating the AI Gateway configuration.
-// It supports OpenAI-only, Anthropic-only, MCP-only, or combined configurations.
+// It supports any combination of the OpenAI, Anthropic, AWS Bedrock, GCP, and MCP configurations.
 type ConfigData struct {
-	Backends       []Backend        // All backend endpoints (e.g. OpenAI, Anthropic, MCP, and OTEL)
-	OpenAI         *OpenAIConfig    // OpenAI-specific configuration (nil when not present)
-	Anthropic      *AnthropicConfig // Anthropic-specific configuration (nil when not present)
-	MCPBackendRefs []MCPBackendRef  // MCP routing configuration (nil/empty for OpenAI-only or Anthropic-only mode)
-	Debug          bool             // Enable debug logging for Envoy (includes component-level logging for ext_proc, http, connection)
-	EnvoyVersion   string           // Explicitly configure the version of Envoy to use.
-	OTELLog        *otelLogConfig   // OpenTelemetry access log configuration (nil => file sink).
+	Backends       []Backend         // All backend endpoints (e.g. OpenAI, Anthropic, MCP, and OTEL)
+	OpenAI         *OpenAIConfig     // OpenAI-specific configuration (nil when not present)
+	Anthropic      *AnthropicConfig  // Anthropic-specific configuration (nil when not present)
+	AWSBedrock     *AWSBedrockConfig // AWS Bedrock-specific configuration (nil when not present)
+	GCP            *GCPConfig        // GCP Vertex AI-specific configuration (nil when not present)
+	MCPBackendRefs []MCPBackendRef   // MCP routing configuration (nil/empty for LLM-only mode)
+	Debug          bool              // Enable debug logging for Envoy (includes component-level logging for ext_proc, http, connection)
+	EnvoyVersion   string            // Explicitly configure the version of Envoy to use.
+	OTELLog        *otelLogConfig    // OpenTelemetry access log configuration (nil => file sink).
+	// DiscoveredModels are the models listed by the backends, by AIServiceBackend name (nil when not discovered).
+	DiscoveredModels map[string][]DiscoveredModel
+}
+
+// Regular expressions matching the model names of each provider, used when several providers are configured.
+const (
+	// gcpAnthropicModels matches the Anthropic models on Vertex AI, which are versioned with "@", e.g.
+	// "claude-sonnet-4@20250514".
+	gcpAnthropicModels = "claude-.*@.*"
+	anthropicModels    = "claude-.*"
+	// awsBedrockModels matches the Bedrock model IDs, prefixed by the model provider and optionally by the
+	// cross-region inference profile, e.g. "anthropic.claude-3-5-sonnet-20241022-v2:0" or "us.amazon.nova-lite-v1:0".
+	awsBedrockModels  = `((us|us-gov|eu|apac|global)\.)?(anthropic|amazon|meta|mistral|cohere|ai21|deepseek|openai|qwen|writer)\..*`
+	gcpVertexAIModels = "gemini-.*"
+	// allModels matches any model. OpenAI compatible endpoints serve arbitrary model names, so they receive the
+	// requests that no other provider matched.
+	allModels = ".*"
+)
+
+// anthropicVersionHeader is required by the Anthropic Messages API, and not sent by the OpenAI clients. Requiring it
+// limits the rule of the Anthropic backend to the messages endpoint, as its chat completions can't be translated.
+const anthropicVersionHeader = "anthropic-version"
+
+// ModelRoutes returns the rules of the generated AIGatewayRoute, in order of precedence.
+//
+// When a single provider is configured, all the models are routed to it. Otherwise, each provider is routed the
+// models matching its model naming scheme, and the OpenAI compatible endpoint, if any, receives the other models.
+// The Anthropic backend is then only routed the requests of the messages endpoint, so that the chat completions of
+// the Claude models reach the other providers.
+func (d *ConfigData) ModelRoutes() []ModelRoute {
+	var routes []ModelRoute
+	if d.GCP != nil {
+		routes = append(routes, ModelRoute{BackendName: d.GCP.AnthropicBackendName, Provider: "GCP Anthropic", Models: gcpAnthropicModels})
+	}
+	if d.Anthropic != nil {
+		routes = append(routes, ModelRoute{
+			BackendName:    d.Anthropic.BackendName,
+			Provider:       "Anthropic",
+			Models:         anthropicModels,
+			RequiredHeader: anthropicVersionHeader,
+		})
+	}
+	if d.AWSBedrock != nil {
+		routes = append(routes, ModelRoute{BackendName: d.AWSBedrock.BackendName, Provider: "AWS Bedrock", Models: awsBedrockModels})
+	}
+	if d.GCP != nil {
+		routes = append(routes, ModelRoute{BackendName: d.GCP.BackendName, Provider: "GCP Vertex AI", Models: gcpVertexAIModels})
+	}
+	if d.OpenAI != nil {
+		routes = append(routes, ModelRoute{BackendName: d.OpenAI.BackendName, Provider: "OpenAI", Models: allModels})
+	}
+	if len(routes) == 1 {
+		routes[0] = ModelRoute{BackendName: routes[0].BackendName, Provider: routes[0].Provider, Models: allModels}
+	}
+	return routes
 }
 
 // WriteConfig generates the AI Gateway configuration.
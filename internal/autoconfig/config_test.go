@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"testing"
 
@@ -75,6 +76,18 @@ var (
 
 	//go:embed testdata/openai-otel-ip.yaml
 	openaaiOTELIPYAML string
+
+	//go:embed testdata/aws-bedrock.yaml
+	awsBedrockYAML string
+
+	//go:embed testdata/gcp-vertexai.yaml
+	gcpVertexAIYAML string
+
+	//go:embed testdata/multiple-providers.yaml
+	multipleProvidersYAML string
+
+	//go:embed testdata/discovered-models.yaml
+	discoveredModelsYAML string
 )
 
 func TestWriteConfig(t *testing.T) {
@@ -456,6 +469,135 @@ func TestWriteConfig(t *testing.T) {
 			},
 			expected: anthropicYAML,
 		},
+		{
+			name: "default (AWS Bedrock)",
+			input: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "aws-bedrock",
+						Hostname: "bedrock-runtime.us-east-1.amazonaws.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				AWSBedrock: &AWSBedrockConfig{
+					BackendName: "aws-bedrock",
+					Region:      "us-east-1",
+				},
+				OTELLog: &otelLogConfig{Exporter: "console"},
+			},
+			expected: awsBedrockYAML,
+		},
+		{
+			name: "default (GCP Vertex AI)",
+			input: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "gcp-vertexai",
+						Hostname: "aiplatform.googleapis.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				GCP: &GCPConfig{
+					BackendName:          "gcp-vertexai",
+					AnthropicBackendName: "gcp-anthropic",
+					ProjectName:          "my-project",
+					Region:               "global",
+				},
+				OTELLog: &otelLogConfig{Exporter: "console"},
+			},
+			expected: gcpVertexAIYAML,
+		},
+		{
+			name: "OpenAI, Anthropic, AWS Bedrock and GCP Vertex AI",
+			input: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "openai",
+						Hostname: "api.openai.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+					{
+						Name:     "anthropic",
+						Hostname: "api.anthropic.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+					{
+						Name:     "aws-bedrock",
+						Hostname: "bedrock-runtime.us-east-1.amazonaws.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+					{
+						Name:     "gcp-vertexai",
+						Hostname: "us-east5-aiplatform.googleapis.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				OpenAI: &OpenAIConfig{
+					BackendName: "openai",
+					SchemaName:  "OpenAI",
+				},
+				Anthropic: &AnthropicConfig{
+					BackendName: "anthropic",
+					SchemaName:  "Anthropic",
+				},
+				AWSBedrock: &AWSBedrockConfig{
+					BackendName: "aws-bedrock",
+					Region:      "us-east-1",
+				},
+				GCP: &GCPConfig{
+					BackendName:          "gcp-vertexai",
+					AnthropicBackendName: "gcp-anthropic",
+					ProjectName:          "my-project",
+					Region:               "us-east5",
+				},
+				OTELLog: &otelLogConfig{Exporter: "console"},
+			},
+			expected: multipleProvidersYAML,
+		},
+		{
+			name: "OpenAI and Anthropic with discovered models",
+			input: ConfigData{
+				Backends: []Backend{
+					{
+						Name: "openai",
+						IP:   "127.0.0.1",
+						Port: 11434,
+					},
+					{
+						Name:     "anthropic",
+						Hostname: "api.anthropic.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				OpenAI: &OpenAIConfig{
+					BackendName: "openai",
+					SchemaName:  "OpenAI",
+				},
+				Anthropic: &AnthropicConfig{
+					BackendName: "anthropic",
+					SchemaName:  "Anthropic",
+				},
+				DiscoveredModels: map[string][]DiscoveredModel{
+					"openai": {
+						{Name: "qwen3:0.6b", OwnedBy: "library"},
+						{Name: "llama3.2:latest", OwnedBy: "library"},
+					},
+					"anthropic": {
+						{Name: "claude-sonnet-4-5-20250929", OwnedBy: "Anthropic"},
+						{Name: "claude-haiku-4-5-20251001", OwnedBy: "Anthropic"},
+					},
+				},
+				OTELLog: &otelLogConfig{Exporter: "console"},
+			},
+			expected: discoveredModelsYAML,
+		},
 	}
 
 	for _, tt := range tests {
@@ -465,6 +607,78 @@ func TestWriteConfig(t *testing.T) {
 			require.Equal(t, tt.expected, got)
 		})
 	}
+
+	t.Run("discovered model owner is escaped", func(t *testing.T) {
+		// The owners are listed by the backends, so they must not be able to inject YAML.
+		ownedBy := "evil\n    - matches: []\n      backendRefs: []"
+		got, err := WriteConfig(&ConfigData{
+			Backends: []Backend{{Name: "openai", IP: "127.0.0.1", Port: 11434}},
+			OpenAI:   &OpenAIConfig{BackendName: "openai", SchemaName: "OpenAI"},
+			DiscoveredModels: map[string][]DiscoveredModel{
+				"openai": {{Name: "qwen3:0.6b", OwnedBy: ownedBy}},
+			},
+		})
+		require.NoError(t, err)
+		require.NotContains(t, got, ownedBy)
+		require.Contains(t, got, `# OpenAI models owned by "evil\n    - matches: []\n      backendRefs: []"`)
+		require.Contains(t, got, `modelsOwnedBy: "evil\n    - matches: []\n      backendRefs: []"`)
+	})
+}
+
+func TestConfigData_ModelRoutes(t *testing.T) {
+	data := ConfigData{
+		OpenAI:     &OpenAIConfig{BackendName: "openai"},
+		Anthropic:  &AnthropicConfig{BackendName: "anthropic"},
+		AWSBedrock: &AWSBedrockConfig{BackendName: "aws-bedrock"},
+		GCP:        &GCPConfig{BackendName: "gcp-vertexai", AnthropicBackendName: "gcp-anthropic"},
+	}
+	routes := data.ModelRoutes()
+
+	// route returns the backend of the first route matching the model, the same way as the AIGatewayRoute rules.
+	// Only the requests of the Anthropic messages endpoint have the anthropic-version header.
+	route := func(model string, messages bool) string {
+		for _, r := range routes {
+			if r.RequiredHeader != "" && !messages {
+				continue
+			}
+			if regexp.MustCompile("^(?:" + r.Models + ")$").MatchString(model) {
+				return r.BackendName
+			}
+		}
+		return ""
+	}
+	for model, expected := range map[string]string{
+		"claude-sonnet-4@20250514":                  "gcp-anthropic",
+		"claude-sonnet-4-20250514":                  "anthropic",
+		"anthropic.claude-3-5-sonnet-20241022-v2:0": "aws-bedrock",
+		"us.amazon.nova-lite-v1:0":                  "aws-bedrock",
+		"us-gov.meta.llama3-2-1b-instruct-v1:0":     "aws-bedrock",
+		"gemini-2.5-flash":                          "gcp-vertexai",
+		"gpt-4.1":                                   "openai",
+		"o3-mini":                                   "openai",
+	} {
+		require.Equal(t, expected, route(model, true), model)
+	}
+
+	t.Run("chat completions of the Anthropic models", func(t *testing.T) {
+		// The Anthropic backend can't serve them, unlike the Claude models of GCP.
+		require.Equal(t, "openai", route("claude-sonnet-4-20250514", false))
+		require.Equal(t, "gcp-anthropic", route("claude-sonnet-4@20250514", false))
+	})
+
+	t.Run("single provider routes all models", func(t *testing.T) {
+		routes := (&ConfigData{AWSBedrock: &AWSBedrockConfig{BackendName: "aws-bedrock"}}).ModelRoutes()
+		require.Equal(t, []ModelRoute{{BackendName: "aws-bedrock", Provider: "AWS Bedrock", Models: ".*"}}, routes)
+	})
+
+	t.Run("single Anthropic provider routes all requests", func(t *testing.T) {
+		routes := (&ConfigData{Anthropic: &AnthropicConfig{BackendName: "anthropic"}}).ModelRoutes()
+		require.Equal(t, []ModelRoute{{BackendName: "anthropic", Provider: "Anthropic", Models: ".*"}}, routes)
+	})
+
+	t.Run("no provider", func(t *testing.T) {
+		require.Empty(t, (&ConfigData{}).ModelRoutes())
+	})
 }
 
 func TestParseURL(t *testing.T) {
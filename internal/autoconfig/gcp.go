@@ -0,0 +1,64 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package autoconfig
+
+import (
+	"fmt"
+	"os"
+)
+
+// GCPEnvConfigured returns true if GCP Application Default Credentials are configured with the
+// GOOGLE_APPLICATION_CREDENTIALS environment variable.
+func GCPEnvConfigured() bool {
+	return os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") != ""
+}
+
+// PopulateGCPEnvConfig populates ConfigData with GCP Vertex AI backend configuration, serving both the
+// Gemini models and the Anthropic models, from standard Google Cloud environment variables.
+//
+// The credentials are not written to the configuration. Instead, they are resolved as Application Default
+// Credentials, which read the file pointed by GOOGLE_APPLICATION_CREDENTIALS.
+//
+// This errs if GOOGLE_APPLICATION_CREDENTIALS or GOOGLE_CLOUD_PROJECT is not set. GOOGLE_CLOUD_LOCATION
+// defaults to "global".
+//
+// See https://cloud.google.com/docs/authentication/application-default-credentials
+func PopulateGCPEnvConfig(data *ConfigData) error {
+	if data == nil {
+		return fmt.Errorf("ConfigData cannot be nil")
+	}
+
+	if !GCPEnvConfigured() {
+		return fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS environment variable is required")
+	}
+	project := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if project == "" {
+		return fmt.Errorf("GOOGLE_CLOUD_PROJECT environment variable is required")
+	}
+	location := os.Getenv("GOOGLE_CLOUD_LOCATION")
+	if location == "" {
+		location = "global"
+	}
+
+	// The global location has no regional prefix in the endpoint hostname.
+	hostname := "aiplatform.googleapis.com"
+	if location != "global" {
+		hostname = location + "-" + hostname
+	}
+	data.Backends = append(data.Backends, Backend{
+		Name:     "gcp-vertexai",
+		Hostname: hostname,
+		Port:     443,
+		NeedsTLS: true,
+	})
+	data.GCP = &GCPConfig{
+		BackendName:          "gcp-vertexai",
+		AnthropicBackendName: "gcp-anthropic",
+		ProjectName:          project,
+		Region:               location,
+	}
+	return nil
+}
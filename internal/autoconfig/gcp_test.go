@@ -0,0 +1,103 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package autoconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestPopulateGCPEnvConfig(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		expected      ConfigData
+		expectedError string
+	}{
+		{
+			name: "default location",
+			envVars: map[string]string{
+				"GOOGLE_APPLICATION_CREDENTIALS": "/tmp/adc.json",
+				"GOOGLE_CLOUD_PROJECT":           "my-project",
+			},
+			expected: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "gcp-vertexai",
+						Hostname: "aiplatform.googleapis.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				GCP: &GCPConfig{
+					BackendName:          "gcp-vertexai",
+					AnthropicBackendName: "gcp-anthropic",
+					ProjectName:          "my-project",
+					Region:               "global",
+				},
+			},
+		},
+		{
+			name: "regional location",
+			envVars: map[string]string{
+				"GOOGLE_APPLICATION_CREDENTIALS": "/tmp/adc.json",
+				"GOOGLE_CLOUD_PROJECT":           "my-project",
+				"GOOGLE_CLOUD_LOCATION":          "us-east5",
+			},
+			expected: ConfigData{
+				Backends: []Backend{
+					{
+						Name:     "gcp-vertexai",
+						Hostname: "us-east5-aiplatform.googleapis.com",
+						Port:     443,
+						NeedsTLS: true,
+					},
+				},
+				GCP: &GCPConfig{
+					BackendName:          "gcp-vertexai",
+					AnthropicBackendName: "gcp-anthropic",
+					ProjectName:          "my-project",
+					Region:               "us-east5",
+				},
+			},
+		},
+		{
+			name:          "missing credentials",
+			envVars:       map[string]string{"GOOGLE_CLOUD_PROJECT": "my-project"},
+			expectedError: "GOOGLE_APPLICATION_CREDENTIALS environment variable is required",
+		},
+		{
+			name:          "missing project",
+			envVars:       map[string]string{"GOOGLE_APPLICATION_CREDENTIALS": "/tmp/adc.json"},
+			expectedError: "GOOGLE_CLOUD_PROJECT environment variable is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			var data ConfigData
+			err := PopulateGCPEnvConfig(&data)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, data)
+		})
+	}
+
+	t.Run("nil ConfigData", func(t *testing.T) {
+		require.EqualError(t, PopulateGCPEnvConfig(nil), "ConfigData cannot be nil")
+	})
+}
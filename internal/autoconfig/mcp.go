@@ -55,7 +55,7 @@ type MCPServer struct {
 	// IncludeTools specifies which tools will be available from the server.
 	IncludeTools []string `json:"includeTools,omitempty"`
 
-	// Command and Args are used for stdio MCP servers.
+	// Command, Args, Env and Cwd are used for stdio MCP servers.
 	// These values are only used during configuration parsing and are never used to render
 	// the final configuration.
 	// When stdio MCP servers are configured, we will run local Streamable HTTP proxies for
@@ -65,6 +65,10 @@ type MCPServer struct {
 	Command string `json:"command,omitempty"`
 	// Args are the command-line arguments.
 	Args []string `json:"args,omitempty"`
+	// Env contains the environment variables set for the command, in addition to the ones of aigw.
+	Env map[string]string `json:"env,omitempty"`
+	// Cwd is the working directory of the command. Defaults to the working directory of aigw.
+	Cwd string `json:"cwd,omitempty"`
 }
 
 // AddMCPServers adds MCP server configurations to the ConfigData.
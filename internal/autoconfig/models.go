@@ -0,0 +1,194 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package autoconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+// DiscoveredModel is a model listed by the models endpoint of a backend.
+type DiscoveredModel struct {
+	Name    string // Model name, as sent in the "model" field of the requests
+	OwnedBy string // Organization owning the model, as listed by the "/v1/models" endpoint of the gateway
+}
+
+// DiscoveredModelRoute is a rule of the generated AIGatewayRoute, routing the requests for the models discovered
+// from a backend by their exact names, so that the "/v1/models" endpoint of the gateway lists them.
+type DiscoveredModelRoute struct {
+	BackendName    string   // References an AIServiceBackend name
+	Provider       string   // Human-readable provider name, e.g. "OpenAI"
+	OwnedBy        string   // Organization owning the models
+	Models         []string // Model names
+	RequiredHeader string   // Name of a header that the requests must have, if any
+}
+
+// maxDiscoveredModelsPerRoute is the maximum number of models of a DiscoveredModelRoute, which is the maximum number
+// of matches of an AIGatewayRoute rule.
+const maxDiscoveredModelsPerRoute = 128
+
+// DiscoveredModelRoutes returns the rules of the generated AIGatewayRoute for the discovered models, which precede
+// the rules returned by ModelRoutes.
+//
+// The models are grouped by backend and owner, in the order of the backends in ModelRoutes. A model discovered from
+// several backends is routed to the first one.
+func (d *ConfigData) DiscoveredModelRoutes() []DiscoveredModelRoute {
+	var routes []DiscoveredModelRoute
+	seen := make(map[string]struct{})
+	for _, modelRoute := range d.ModelRoutes() {
+		// The index of the route being filled with the models of this backend, by owner.
+		filling := make(map[string]int)
+		for _, m := range d.DiscoveredModels[modelRoute.BackendName] {
+			if _, ok := seen[m.Name]; ok {
+				continue
+			}
+			seen[m.Name] = struct{}{}
+			i, ok := filling[m.OwnedBy]
+			if !ok || len(routes[i].Models) == maxDiscoveredModelsPerRoute {
+				routes = append(routes, DiscoveredModelRoute{
+					BackendName:    modelRoute.BackendName,
+					Provider:       modelRoute.Provider,
+					OwnedBy:        m.OwnedBy,
+					RequiredHeader: modelRoute.RequiredHeader,
+				})
+				i = len(routes) - 1
+				filling[m.OwnedBy] = i
+			}
+			routes[i].Models = append(routes[i].Models, m.Name)
+		}
+	}
+	return routes
+}
+
+// modelsRequestTimeout is the timeout to list the models of a backend, so that an unreachable backend doesn't delay
+// the start of the gateway.
+const modelsRequestTimeout = 10 * time.Second
+
+// DiscoverModels lists the models served by the OpenAI and Anthropic backends of the given configuration by querying
+// their models endpoint with the credentials of the environment variables. Other backends, such as Azure OpenAI,
+// AWS Bedrock, or GCP Vertex AI, are not queried.
+//
+// It returns the models of each backend that could be listed by AIServiceBackend name, and the errors of the others.
+func DiscoverModels(ctx context.Context, client *http.Client, data *ConfigData) (map[string][]DiscoveredModel, error) {
+	models := make(map[string][]DiscoveredModel)
+	var errs []error
+	if data.OpenAI != nil && data.OpenAI.SchemaName == "OpenAI" {
+		m, err := listOpenAIModels(ctx, client, data.OpenAI)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list the OpenAI models: %w", err))
+		} else {
+			models[data.OpenAI.BackendName] = m
+		}
+	}
+	if data.Anthropic != nil {
+		m, err := listAnthropicModels(ctx, client)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list the Anthropic models: %w", err))
+		} else {
+			models[data.Anthropic.BackendName] = m
+		}
+	}
+	return models, errors.Join(errs...)
+}
+
+// listOpenAIModels lists the models of an OpenAI compatible backend.
+//
+// See https://platform.openai.com/docs/api-reference/models/list
+func listOpenAIModels(ctx context.Context, client *http.Client, cfg *OpenAIConfig) ([]DiscoveredModel, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+os.Getenv("OPENAI_API_KEY"))
+	if cfg.OrganizationID != "" {
+		header.Set("OpenAI-Organization", cfg.OrganizationID)
+	}
+	if cfg.ProjectID != "" {
+		header.Set("OpenAI-Project", cfg.ProjectID)
+	}
+	var list openai.ModelList
+	if err := getJSON(ctx, client, strings.TrimSuffix(openAIBaseURL(), "/")+"/models", header, &list); err != nil {
+		return nil, err
+	}
+	models := make([]DiscoveredModel, 0, len(list.Data))
+	for _, m := range list.Data {
+		ownedBy := m.OwnedBy
+		if ownedBy == "" {
+			ownedBy = "OpenAI"
+		}
+		models = append(models, DiscoveredModel{Name: m.ID, OwnedBy: ownedBy})
+	}
+	return models, nil
+}
+
+// anthropicModelList is a page of the response of the Anthropic models endpoint.
+type anthropicModelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+// listAnthropicModels lists the models of the Anthropic backend, following the pages of the response.
+//
+// See https://docs.anthropic.com/en/api/models-list
+func listAnthropicModels(ctx context.Context, client *http.Client) ([]DiscoveredModel, error) {
+	header := http.Header{}
+	header.Set("x-api-key", os.Getenv("ANTHROPIC_API_KEY"))
+	header.Set("anthropic-version", "2023-06-01")
+	modelsURL := strings.TrimSuffix(anthropicBaseURL(), "/") + "/models"
+
+	var models []DiscoveredModel
+	query := url.Values{"limit": {"1000"}}
+	for {
+		var page anthropicModelList
+		if err := getJSON(ctx, client, modelsURL+"?"+query.Encode(), header, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Data {
+			models = append(models, DiscoveredModel{Name: m.ID, OwnedBy: "Anthropic"})
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		query.Set("after_id", page.LastID)
+	}
+}
+
+// getJSON sends a GET request to the given URL and unmarshals the JSON response body into out.
+func getJSON(ctx context.Context, client *http.Client, reqURL string, header http.Header, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, modelsRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response of %s: %w", reqURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, reqURL, body)
+	}
+	if err = json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal the response of %s: %w", reqURL, err)
+	}
+	return nil
+}
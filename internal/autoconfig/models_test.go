@@ -0,0 +1,137 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package autoconfig
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestDiscoverModels(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openai/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.Equal(t, "org-test", r.Header.Get("OpenAI-Organization"))
+		_, _ = w.Write([]byte(`{"object":"list","data":[
+			{"id":"gpt-4.1","object":"model","created":1744316542,"owned_by":"system"},
+			{"id":"local-model","object":"model","created":1744316542,"owned_by":""}
+		]}`))
+	})
+	mux.HandleFunc("GET /anthropic/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "sk-ant-test", r.Header.Get("x-api-key"))
+		require.Equal(t, "2023-06-01", r.Header.Get("anthropic-version"))
+		require.Equal(t, "1000", r.URL.Query().Get("limit"))
+		// Return two pages to test the pagination.
+		if r.URL.Query().Get("after_id") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-sonnet-4-5-20250929","type":"model"}],"has_more":true,"last_id":"claude-sonnet-4-5-20250929"}`))
+			return
+		}
+		require.Equal(t, "claude-sonnet-4-5-20250929", r.URL.Query().Get("after_id"))
+		_, _ = w.Write([]byte(`{"data":[{"id":"claude-haiku-4-5-20251001","type":"model"}],"has_more":false,"last_id":"claude-haiku-4-5-20251001"}`))
+	})
+	mux.HandleFunc("GET /unauthorized/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+	data := &ConfigData{
+		OpenAI:     &OpenAIConfig{BackendName: "openai", SchemaName: "OpenAI", OrganizationID: "org-test"},
+		Anthropic:  &AnthropicConfig{BackendName: "anthropic", SchemaName: "Anthropic"},
+		AWSBedrock: &AWSBedrockConfig{BackendName: "aws-bedrock", Region: "us-east-1"},
+	}
+
+	t.Run("all backends", func(t *testing.T) {
+		t.Setenv("OPENAI_BASE_URL", server.URL+"/openai/v1")
+		t.Setenv("ANTHROPIC_BASE_URL", server.URL+"/anthropic/v1/")
+		models, err := DiscoverModels(t.Context(), server.Client(), data)
+		require.NoError(t, err)
+		require.Equal(t, map[string][]DiscoveredModel{
+			"openai": {
+				{Name: "gpt-4.1", OwnedBy: "system"},
+				{Name: "local-model", OwnedBy: "OpenAI"},
+			},
+			"anthropic": {
+				{Name: "claude-sonnet-4-5-20250929", OwnedBy: "Anthropic"},
+				{Name: "claude-haiku-4-5-20251001", OwnedBy: "Anthropic"},
+			},
+		}, models)
+	})
+
+	t.Run("failed backend", func(t *testing.T) {
+		t.Setenv("OPENAI_BASE_URL", server.URL+"/unauthorized/v1")
+		t.Setenv("ANTHROPIC_BASE_URL", server.URL+"/anthropic/v1")
+		models, err := DiscoverModels(t.Context(), server.Client(), data)
+		require.EqualError(t, err, fmt.Sprintf("failed to list the OpenAI models: unexpected status code 401 from "+
+			"%s/unauthorized/v1/models: invalid api key\n", server.URL))
+		require.Len(t, models["anthropic"], 2)
+		require.NotContains(t, models, "openai")
+	})
+
+	t.Run("Azure OpenAI is not queried", func(t *testing.T) {
+		models, err := DiscoverModels(t.Context(), server.Client(), &ConfigData{
+			OpenAI: &OpenAIConfig{BackendName: "openai", SchemaName: "AzureOpenAI"},
+		})
+		require.NoError(t, err)
+		require.Empty(t, models)
+	})
+}
+
+func TestConfigData_DiscoveredModelRoutes(t *testing.T) {
+	t.Run("grouped by backend and owner", func(t *testing.T) {
+		data := ConfigData{
+			OpenAI:    &OpenAIConfig{BackendName: "openai"},
+			Anthropic: &AnthropicConfig{BackendName: "anthropic"},
+			DiscoveredModels: map[string][]DiscoveredModel{
+				"openai": {
+					{Name: "gpt-4.1", OwnedBy: "system"},
+					{Name: "o3", OwnedBy: "openai"},
+					{Name: "gpt-4.1-mini", OwnedBy: "system"},
+					// Also served by the Anthropic backend, which precedes the OpenAI one.
+					{Name: "claude-sonnet-4-5-20250929", OwnedBy: "system"},
+				},
+				"anthropic": {
+					{Name: "claude-sonnet-4-5-20250929", OwnedBy: "Anthropic"},
+				},
+			},
+		}
+		require.Equal(t, []DiscoveredModelRoute{
+			{
+				BackendName:    "anthropic",
+				Provider:       "Anthropic",
+				OwnedBy:        "Anthropic",
+				Models:         []string{"claude-sonnet-4-5-20250929"},
+				RequiredHeader: "anthropic-version",
+			},
+			{BackendName: "openai", Provider: "OpenAI", OwnedBy: "system", Models: []string{"gpt-4.1", "gpt-4.1-mini"}},
+			{BackendName: "openai", Provider: "OpenAI", OwnedBy: "openai", Models: []string{"o3"}},
+		}, data.DiscoveredModelRoutes())
+	})
+
+	t.Run("split at the maximum number of matches", func(t *testing.T) {
+		data := ConfigData{OpenAI: &OpenAIConfig{BackendName: "openai"}, DiscoveredModels: map[string][]DiscoveredModel{}}
+		for i := range maxDiscoveredModelsPerRoute + 1 {
+			data.DiscoveredModels["openai"] = append(data.DiscoveredModels["openai"], DiscoveredModel{Name: fmt.Sprintf("model-%d", i), OwnedBy: "library"})
+		}
+		routes := data.DiscoveredModelRoutes()
+		require.Len(t, routes, 2)
+		require.Len(t, routes[0].Models, maxDiscoveredModelsPerRoute)
+		require.Equal(t, []string{fmt.Sprintf("model-%d", maxDiscoveredModelsPerRoute)}, routes[1].Models)
+	})
+
+	t.Run("no discovered models", func(t *testing.T) {
+		require.Empty(t, (&ConfigData{OpenAI: &OpenAIConfig{BackendName: "openai"}}).DiscoveredModelRoutes())
+	})
+}
@@ -53,12 +53,7 @@ func PopulateOpenAIEnvConfig(data *ConfigData) error {
 		version = apiVersion
 	case openaiAPIKey != "":
 		// Standard OpenAI mode
-		baseURL := os.Getenv("OPENAI_BASE_URL")
-		if baseURL == "" {
-			baseURL = "https://api.openai.com/v1"
-		}
-
-		parsed, err = parseURL(baseURL)
+		parsed, err = parseURL(openAIBaseURL())
 		if err != nil {
 			return err
 		}
@@ -92,3 +87,11 @@ func PopulateOpenAIEnvConfig(data *ConfigData) error {
 
 	return nil
 }
+
+// openAIBaseURL returns the base URL of the OpenAI API from OPENAI_BASE_URL, defaulting to the official endpoint.
+func openAIBaseURL() string {
+	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	return "https://api.openai.com/v1"
+}
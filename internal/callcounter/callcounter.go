@@ -0,0 +1,76 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package callcounter provides fixed window counters of calls keyed by an arbitrary string, used to enforce
+// the MCPRoute tool call rate limits.
+package callcounter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a set of fixed window rate limiters identified by key.
+type Limiter interface {
+	// Allow records a hit of the limiter identified by key, given that at most limit hits are allowed
+	// in each window.
+	//
+	// It returns true when the hit is allowed. Otherwise, it returns false along with the time left until
+	// the current window ends.
+	Allow(ctx context.Context, key string, limit uint32, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+	// Close releases the resources held by the Limiter.
+	Close() error
+}
+
+// NewLocal returns an in-memory Limiter. The limits are only enforced within the current process.
+func NewLocal() Limiter {
+	return &local{windows: make(map[string]*localWindow), now: time.Now}
+}
+
+type local struct {
+	mu      sync.Mutex
+	windows map[string]*localWindow
+	// nextSweep is the time after which the expired windows are evicted on the next call.
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type localWindow struct {
+	hits uint32
+	end  time.Time
+}
+
+// localSweepInterval is the minimum interval between two evictions of the expired windows.
+const localSweepInterval = time.Minute
+
+// Allow implements [Limiter.Allow].
+func (l *local) Allow(_ context.Context, key string, limit uint32, window time.Duration) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.After(l.nextSweep) {
+		for k, w := range l.windows {
+			if !now.Before(w.end) {
+				delete(l.windows, k)
+			}
+		}
+		l.nextSweep = now.Add(localSweepInterval)
+	}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.end) {
+		w = &localWindow{end: now.Add(window)}
+		l.windows[key] = w
+	}
+	if w.hits >= limit {
+		return false, w.end.Sub(now), nil
+	}
+	w.hits++
+	return true, 0, nil
+}
+
+// Close implements [Limiter.Close].
+func (l *local) Close() error { return nil }
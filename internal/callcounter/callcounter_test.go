@@ -0,0 +1,51 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package callcounter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_Allow(t *testing.T) {
+	l := NewLocal().(*local)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	for range 2 {
+		allowed, retryAfter, err := l.Allow(t.Context(), "a", 2, time.Minute)
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Zero(t, retryAfter)
+	}
+	now = now.Add(20 * time.Second)
+	allowed, retryAfter, err := l.Allow(t.Context(), "a", 2, time.Minute)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, 40*time.Second, retryAfter)
+
+	// The other keys have their own windows.
+	allowed, _, err = l.Allow(t.Context(), "b", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// A new window starts once the current one ends.
+	now = now.Add(40 * time.Second)
+	allowed, _, err = l.Allow(t.Context(), "a", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, uint32(1), l.windows["a"].hits)
+
+	// The expired windows are evicted.
+	now = now.Add(2 * time.Minute)
+	allowed, _, err = l.Allow(t.Context(), "c", 1, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Len(t, l.windows, 1)
+	require.NoError(t, l.Close())
+}
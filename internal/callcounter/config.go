@@ -0,0 +1,25 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package callcounter
+
+import (
+	"github.com/envoyproxy/ai-gateway/internal/limitbackend"
+)
+
+// New creates the Limiter described by the configuration.
+func New(cfg limitbackend.Config) (Limiter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Backend == limitbackend.TypeRedis {
+		client, err := cfg.NewRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewRedis(client), nil
+	}
+	return NewLocal(), nil
+}
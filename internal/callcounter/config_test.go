@@ -0,0 +1,30 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package callcounter
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/limitbackend"
+)
+
+func TestNew(t *testing.T) {
+	l, err := New(limitbackend.Config{Backend: limitbackend.TypeLocal})
+	require.NoError(t, err)
+	require.IsType(t, &local{}, l)
+
+	mr := miniredis.RunT(t)
+	l, err = New(limitbackend.Config{Backend: limitbackend.TypeRedis, RedisURL: "redis://" + mr.Addr()})
+	require.NoError(t, err)
+	require.IsType(t, &redisLimiter{}, l)
+	require.NoError(t, l.Close())
+
+	_, err = New(limitbackend.Config{Backend: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,63 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package callcounter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix is the prefix of the Redis keys of the limiters.
+const redisKeyPrefix = "ai-gateway:ratelimit:"
+
+// allowScript increments the counter of the current window, which expires after ARGV[2] milliseconds, and
+// returns whether the counter is within ARGV[1] along with the time left in the window in milliseconds.
+// Relying on the key expiry means that the windows are timed by the Redis server, so that the clocks of
+// the replicas do not need to be synchronized.
+var allowScript = redis.NewScript(`
+local hits = redis.call('INCR', KEYS[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if hits == 1 or ttl < 0 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  ttl = tonumber(ARGV[2])
+end
+if hits > tonumber(ARGV[1]) then
+  return {0, ttl}
+end
+return {1, ttl}
+`)
+
+// NewRedis returns a Limiter shared by all the replicas connected to the same Redis.
+func NewRedis(client redis.UniversalClient) Limiter {
+	return &redisLimiter{client: client}
+}
+
+type redisLimiter struct {
+	client redis.UniversalClient
+}
+
+// Allow implements [Limiter.Allow].
+func (r *redisLimiter) Allow(ctx context.Context, key string, limit uint32, window time.Duration) (bool, time.Duration, error) {
+	res, err := allowScript.Run(ctx, r.client, []string{redisKeyPrefix + key}, limit, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check rate limit %s: %w", key, err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("failed to check rate limit %s: unexpected result %v", key, res)
+	}
+	if res[0] == 1 {
+		return true, 0, nil
+	}
+	return false, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// Close implements [Limiter.Close].
+func (r *redisLimiter) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,62 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package callcounter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisLimiter(t *testing.T, mr *miniredis.Miniredis) Limiter {
+	l := NewRedis(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	t.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+func TestRedis_Allow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	// Two replicas sharing the same Redis.
+	l1 := newTestRedisLimiter(t, mr)
+	l2 := newTestRedisLimiter(t, mr)
+
+	allowed, _, err := l1.Allow(t.Context(), "a", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	allowed, _, err = l2.Allow(t.Context(), "a", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	mr.FastForward(20 * time.Second)
+	allowed, retryAfter, err := l1.Allow(t.Context(), "a", 2, time.Minute)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, 40*time.Second, retryAfter)
+	require.Equal(t, 40*time.Second, mr.TTL(redisKeyPrefix+"a"))
+
+	// The other keys have their own windows.
+	allowed, _, err = l2.Allow(t.Context(), "b", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// A new window starts once the current one ends.
+	mr.FastForward(40 * time.Second)
+	require.False(t, mr.Exists(redisKeyPrefix+"a"))
+	allowed, _, err = l2.Allow(t.Context(), "a", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestRedis_Allow_Error(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := newTestRedisLimiter(t, mr)
+	mr.Close()
+	_, _, err := l.Allow(t.Context(), "a", 1, time.Minute)
+	require.ErrorContains(t, err, "failed to check rate limit a")
+}
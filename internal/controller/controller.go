@@ -223,6 +223,8 @@ func StartControllers(ctx context.Context, mgr manager.Manager, config *rest.Con
 	if err = TypedControllerBuilderForCRD(mgr, &aigv1b1.MCPRoute{}).
 		Owns(&gwapiv1.HTTPRoute{}).
 		Owns(&egv1a1.Backend{}).
+		// The manager cache only holds the ConfigMaps with the aigv1b1.MCPRouteConfigMapLabel label.
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(mcpRouteC.configMapEventHandler)).
 		WatchesRawSource(source.Channel(
 			mcpRouteEventChan,
 			&handler.EnqueueRequestForObject{},
@@ -311,6 +313,9 @@ const (
 	// k8sClientIndexSecretToReferencingMCPRoute is the index name that maps
 	// from a Secret to the MCPRoute that references it.
 	k8sClientIndexSecretToReferencingMCPRoute = "SecretToReferencingMCPRoute"
+	// k8sClientIndexConfigMapToReferencingMCPRoute is the index name that maps
+	// from a ConfigMap to the MCPRoute that references it.
+	k8sClientIndexConfigMapToReferencingMCPRoute = "ConfigMapToReferencingMCPRoute"
 	// k8sClientIndexBackendToReferencingAIGatewayRoute is the index name that maps from a Backend to the
 	// AIGatewayRoute that references it.
 	k8sClientIndexBackendToReferencingAIGatewayRoute = "BackendToReferencingAIGatewayRoute"
@@ -391,6 +396,11 @@ func ApplyIndexing(ctx context.Context, indexer func(ctx context.Context, obj cl
 	if err != nil {
 		return fmt.Errorf("failed to create index from Gateway to MCPRoute: %w", err)
 	}
+	err = indexer(ctx, &aigv1b1.MCPRoute{},
+		k8sClientIndexConfigMapToReferencingMCPRoute, mcpRouteToReferencedConfigMap)
+	if err != nil {
+		return fmt.Errorf("failed to create index from ConfigMap to MCPRoute: %w", err)
+	}
 	err = indexer(ctx, &gwapiv1.HTTPRoute{},
 		k8sClientIndexMCPRouteToOwnedHTTPRoute, httpRouteToOwnerMCPRouteIndexFunc)
 	if err != nil {
@@ -417,20 +427,47 @@ func mcpRouteToReferencedSecret(o client.Object) []string {
 	mcpRoute := o.(*aigv1b1.MCPRoute)
 	var ret []string
 	for _, ref := range mcpRoute.Spec.BackendRefs {
-		if ref.SecurityPolicy == nil || ref.SecurityPolicy.APIKey == nil || ref.SecurityPolicy.APIKey.SecretRef == nil {
+		if ref.SecurityPolicy == nil {
 			continue
 		}
-		apiKeyRef := ref.SecurityPolicy.APIKey.SecretRef
-		// Use the namespace from parentRef if specified, otherwise use the route's namespace.
-		namespace := mcpRoute.Namespace
-		if apiKeyRef.Namespace != nil && *apiKeyRef.Namespace != "" {
-			namespace = string(*apiKeyRef.Namespace)
+		var secretRefs []*gwapiv1.SecretObjectReference
+		if ref.SecurityPolicy.APIKey != nil && ref.SecurityPolicy.APIKey.SecretRef != nil {
+			secretRefs = append(secretRefs, ref.SecurityPolicy.APIKey.SecretRef)
+		}
+		if te := ref.SecurityPolicy.OAuthTokenExchange; te != nil && te.ClientAuth != nil {
+			secretRefs = append(secretRefs, &te.ClientAuth.ClientSecretRef)
+		}
+		for _, secretRef := range secretRefs {
+			ret = append(ret, fmt.Sprintf("%s.%s", secretRef.Name, mcpSecretRefNamespace(mcpRoute, secretRef)))
 		}
-		ret = append(ret, fmt.Sprintf("%s.%s", apiKeyRef.Name, namespace))
 	}
 	return ret
 }
 
+// mcpRouteToReferencedConfigMap returns the ConfigMaps referenced by the given MCPRoute as the tool pinning baselines.
+func mcpRouteToReferencedConfigMap(o client.Object) []string {
+	mcpRoute := o.(*aigv1b1.MCPRoute)
+	var ret []string
+	for _, ref := range mcpRoute.Spec.BackendRefs {
+		if ref.ToolPinning != nil {
+			ret = append(ret, fmt.Sprintf("%s.%s", ref.ToolPinning.BaselineConfigMapName, mcpRoute.Namespace))
+		}
+		if ref.OpenAPI != nil && ref.OpenAPI.Document.ConfigMapRef != nil {
+			ret = append(ret, fmt.Sprintf("%s.%s", ref.OpenAPI.Document.ConfigMapRef.Name, mcpRoute.Namespace))
+		}
+	}
+	return ret
+}
+
+// mcpSecretRefNamespace returns the namespace of the secret referenced by the given MCPRoute.
+// The namespace from the reference is used if specified, otherwise the route's namespace.
+func mcpSecretRefNamespace(mcpRoute *aigv1b1.MCPRoute, secretRef *gwapiv1.SecretObjectReference) string {
+	if secretRef.Namespace != nil && *secretRef.Namespace != "" {
+		return string(*secretRef.Namespace)
+	}
+	return mcpRoute.Namespace
+}
+
 func httpRouteToOwnerMCPRouteIndexFunc(o client.Object) []string {
 	owner := metav1.GetControllerOf(o)
 	if owner == nil || owner.Kind != "MCPRoute" {
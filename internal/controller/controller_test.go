@@ -797,6 +797,45 @@ func Test_mcpRouteToAttachedGatewayIndexFunc(t *testing.T) {
 	}
 }
 
+func Test_mcpRouteToReferencedSecret(t *testing.T) {
+	route := &aigv1b1.MCPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp-route", Namespace: "default"},
+		Spec: aigv1b1.MCPRouteSpec{
+			BackendRefs: []aigv1b1.MCPRouteBackendRef{
+				{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "no-policy"}},
+				{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "api-key"},
+					SecurityPolicy: &aigv1b1.MCPBackendSecurityPolicy{
+						APIKey: &aigv1b1.MCPBackendAPIKey{SecretRef: &gwapiv1.SecretObjectReference{Name: "api-key-secret"}},
+					},
+				},
+				{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "inline-api-key"},
+					SecurityPolicy: &aigv1b1.MCPBackendSecurityPolicy{
+						APIKey: &aigv1b1.MCPBackendAPIKey{Inline: ptr.To("key")},
+					},
+				},
+				{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "token-exchange"},
+					SecurityPolicy: &aigv1b1.MCPBackendSecurityPolicy{
+						OAuthTokenExchange: &aigv1b1.MCPBackendOAuthTokenExchange{
+							TokenEndpoint: "https://sts.example.com/token",
+							ClientAuth: &aigv1b1.MCPBackendOAuthTokenExchangeClientAuth{
+								ClientID: "gateway",
+								ClientSecretRef: gwapiv1.SecretObjectReference{
+									Name:      "sts-secret",
+									Namespace: ptr.To(gwapiv1.Namespace("sts")),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.Equal(t, []string{"api-key-secret.default", "sts-secret.sts"}, mcpRouteToReferencedSecret(route))
+}
+
 func Test_isKubernetes133OrLater(t *testing.T) {
 	require.False(t, isKubernetes133OrLater(&version.Info{}, logr.Discard()))
 	require.False(t, isKubernetes133OrLater(&version.Info{Major: "invalid"}, logr.Discard()))
@@ -8,7 +8,10 @@ package controller
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -34,6 +37,8 @@ import (
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
+	"github.com/envoyproxy/ai-gateway/internal/ratelimit/translator"
 	"github.com/envoyproxy/ai-gateway/internal/version"
 )
 
@@ -203,6 +208,52 @@ func bodyMutationToFilterAPI(m *aigv1b1.HTTPBodyMutation) *filterapi.HTTPBodyMut
 	return ret
 }
 
+// admissionToFilterAPI converts the admission control of an AIServiceBackend to filter API form, applying the
+// defaults of the fields not set. The backendKey is "namespace/name" of the AIServiceBackend.
+func admissionToFilterAPI(a *aigv1b1.AIServiceBackendAdmission, backendKey string) (*filterapi.BackendAdmission, error) {
+	if a == nil {
+		return nil, nil
+	}
+	parse := func(field string, d *gwapiv1.Duration, def time.Duration) (time.Duration, error) {
+		if d == nil {
+			return def, nil
+		}
+		parsed, err := time.ParseDuration(string(*d))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %w", field, *d, err)
+		}
+		return parsed, nil
+	}
+	ret := &filterapi.BackendAdmission{
+		Key:                       backendKey,
+		ThrottledPercentThreshold: ptr.Deref(a.ThrottledPercentThreshold, 5),
+		MaxQueueSize:              ptr.Deref(a.MaxQueueSize, 100),
+	}
+	var err error
+	if ret.Window, err = parse("window", a.Window, 30*time.Second); err != nil {
+		return nil, err
+	}
+	if ret.LatencyThreshold, err = parse("latencyThreshold", a.LatencyThreshold, 0); err != nil {
+		return nil, err
+	}
+	if ret.QueueTimeout, err = parse("queueTimeout", a.QueueTimeout, 2*time.Second); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// priorityClassToFilterAPI converts the priority class of an AIGatewayRoute to filter API form.
+func priorityClassToFilterAPI(p *aigv1b1.AIGatewayRoutePriorityClass) *filterapi.PriorityClassConfig {
+	if p == nil {
+		return nil
+	}
+	return &filterapi.PriorityClassConfig{
+		Default:  filterapi.PriorityClass(ptr.Deref(p.Default, aigv1b1.PriorityClassNormal)),
+		Header:   strings.ToLower(ptr.Deref(p.Header, "")),
+		JWTClaim: ptr.Deref(p.JWTClaim, ""),
+	}
+}
+
 // validateCELExpression validates and returns a CEL expression for cost calculation.
 func validateCELExpression(cost aigv1b1.LLMRequestCost) (string, error) {
 	if cost.CEL == nil {
@@ -248,6 +299,28 @@ func aigwLLMRequestCostToFilterAPI(cost aigv1b1.LLMRequestCost, routeName string
 	return out, nil
 }
 
+// backendLLMRequestCostsToFilterAPI converts the LLMRequestCosts of an AIServiceBackend to filter API form
+// scoped to the given AIGatewayRoute (routeName is "namespace/name") and backend (backendKey is "namespace/name").
+// When the same metadataKey is defined multiple times, the last definition wins.
+func backendLLMRequestCostsToFilterAPI(costs []aigv1b1.LLMRequestCost, routeName, backendKey string) ([]filterapi.LLMRequestCost, error) {
+	out := make([]filterapi.LLMRequestCost, 0, len(costs))
+	indexes := make(map[string]int, len(costs))
+	for _, cost := range costs {
+		fc, err := aigwLLMRequestCostToFilterAPI(cost, routeName)
+		if err != nil {
+			return nil, err
+		}
+		fc.Backend = backendKey
+		if i, ok := indexes[fc.MetadataKey]; ok {
+			out[i] = fc
+			continue
+		}
+		indexes[fc.MetadataKey] = len(out)
+		out = append(out, fc)
+	}
+	return out, nil
+}
+
 // mergeBodyMutations merges route-level and backend-level BodyMutation with route-level taking precedence.
 // Returns the merged BodyMutation where route-level operations override backend-level operations for conflicting body fields.
 func mergeBodyMutations(routeLevel, backendLevel *aigv1b1.HTTPBodyMutation) *aigv1b1.HTTPBodyMutation {
@@ -386,6 +459,9 @@ func (c *GatewayController) reconcileFilterConfigSecret(
 		routeBackendNamesSet := map[string]struct{}{}
 		routeBackendNames := []string{}
 		injectedQuotaCosts := make(map[string]struct{})
+		// Backend-level costs of the AIServiceBackends referenced by this route, deduped per backend.
+		var backendCosts []filterapi.LLMRequestCost
+		backendCostsAdded := make(map[string]struct{})
 		for ruleIndex := range spec.Rules {
 			rule := &spec.Rules[ruleIndex]
 			for _, m := range rule.Matches {
@@ -469,6 +545,23 @@ func (c *GatewayController) reconcileFilterConfigSecret(
 					b.BodyMutation = bodyMutationToFilterAPI(mergedBodyMutation)
 
 					b.Schema = schemaToFilterAPI(backendObj.Spec.APISchema)
+
+					b.Admission, err = admissionToFilterAPI(backendObj.Spec.Admission, backendNamespace+"/"+backendRef.Name)
+					if err != nil {
+						return false, fmt.Errorf("failed to convert admission for backend %s: %w", backendObj.Name, err)
+					}
+					b.PriorityClass = priorityClassToFilterAPI(spec.PriorityClass)
+
+					// The backend filter must match the short name derived from b.Name at runtime.
+					backendKey := aiGatewayRoute.Namespace + "/" + backendRef.Name
+					if _, added := backendCostsAdded[backendKey]; !added && len(backendObj.Spec.LLMRequestCosts) > 0 {
+						costs, convErr := backendLLMRequestCostsToFilterAPI(backendObj.Spec.LLMRequestCosts, routeName, backendKey)
+						if convErr != nil {
+							return false, fmt.Errorf("failed to convert LLMRequestCosts for backend %s: %w", backendObj.Name, convErr)
+						}
+						backendCosts = append(backendCosts, costs...)
+						backendCostsAdded[backendKey] = struct{}{}
+					}
 				}
 
 				if bsp != nil {
@@ -506,6 +599,7 @@ func (c *GatewayController) reconcileFilterConfigSecret(
 			for _, fc := range dedup {
 				ec.LLMRequestCosts = append(ec.LLMRequestCosts, fc)
 			}
+			ec.LLMRequestCosts = append(ec.LLMRequestCosts, backendCosts...)
 		}
 	}
 
@@ -524,6 +618,15 @@ func (c *GatewayController) reconcileFilterConfigSecret(
 	// Configuration for MCP processor.
 	var effectiveMCPRoute bool
 	ec.MCPConfig, effectiveMCPRoute = mcpConfig(mcpRoutes)
+	if err := c.resolveMCPTokenExchangeClientSecrets(ctx, mcpRoutes, ec.MCPConfig); err != nil {
+		return false, err
+	}
+	if err := c.resolveMCPToolPinningBaselines(ctx, mcpRoutes, ec.MCPConfig); err != nil {
+		return false, err
+	}
+	if err := c.resolveMCPOpenAPIDocuments(ctx, mcpRoutes, ec.MCPConfig); err != nil {
+		return false, err
+	}
 	hasEffectiveRoute = hasEffectiveRoute || effectiveMCPRoute
 
 	marshaled, err := yaml.Marshal(ec)
@@ -579,6 +682,18 @@ func mcpConfig(mcpRoutes []aigv1b1.MCPRoute) (_ *filterapi.MCPConfig, hasEffecti
 				// MCPRoute doesn't support cross-namespace backend reference so just use the name.
 				Name: filterapi.MCPBackendName(b.Name),
 			}
+			if ptr.Deref(b.Transport, aigv1b1.MCPBackendTransportStreamableHTTP) == aigv1b1.MCPBackendTransportSSE {
+				mcpBackend.Transport = filterapi.MCPBackendTransportSSE
+				mcpBackend.Path = ptr.Deref(b.Path, defaultMCPPath)
+			}
+			if b.OpenAPI != nil {
+				mcpBackend.OpenAPI = &filterapi.MCPOpenAPIBackend{
+					// Documents from ConfigMaps are resolved by resolveMCPOpenAPIDocuments.
+					Document:   ptr.Deref(b.OpenAPI.Document.Inline, ""),
+					BasePath:   ptr.Deref(b.OpenAPI.BasePath, ""),
+					Operations: b.OpenAPI.Operations,
+				}
+			}
 			if b.ToolSelector != nil {
 				mcpBackend.ToolSelector = &filterapi.MCPToolSelector{
 					Include:      b.ToolSelector.Include,
@@ -587,6 +702,49 @@ func mcpConfig(mcpRoutes []aigv1b1.MCPRoute) (_ *filterapi.MCPConfig, hasEffecti
 					ExcludeRegex: b.ToolSelector.ExcludeRegex,
 				}
 			}
+			if b.ResourceSelector != nil {
+				mcpBackend.ResourceSelector = &filterapi.MCPResourceSelector{
+					Include:      b.ResourceSelector.Include,
+					IncludeRegex: b.ResourceSelector.IncludeRegex,
+					Exclude:      b.ResourceSelector.Exclude,
+					ExcludeRegex: b.ResourceSelector.ExcludeRegex,
+				}
+			}
+			if b.PromptSelector != nil {
+				mcpBackend.PromptSelector = &filterapi.MCPPromptSelector{
+					Include:      b.PromptSelector.Include,
+					IncludeRegex: b.PromptSelector.IncludeRegex,
+					Exclude:      b.PromptSelector.Exclude,
+					ExcludeRegex: b.PromptSelector.ExcludeRegex,
+				}
+			}
+			if b.ToolPinning != nil {
+				mcpBackend.ToolPinning = &filterapi.MCPToolPinning{
+					Action: filterapi.MCPToolPinningAction(ptr.Deref(b.ToolPinning.Action, aigv1b1.MCPToolPinningActionDrop)),
+				}
+			}
+			if b.SchemaValidation != nil {
+				mcpBackend.SchemaValidation = &filterapi.MCPSchemaValidation{
+					Arguments: ptr.Deref(b.SchemaValidation.Arguments, true),
+					StructuredContent: filterapi.MCPStructuredContentValidationAction(
+						ptr.Deref(b.SchemaValidation.StructuredContent, "")),
+				}
+			}
+			if b.ToolNamePrefix != nil {
+				mcpBackend.ToolNamePrefix = &filterapi.MCPToolNamePrefix{
+					Disabled:  b.ToolNamePrefix.Disabled,
+					Separator: ptr.Deref(b.ToolNamePrefix.Separator, ""),
+				}
+			}
+			for i := range b.ToolOverrides {
+				mcpBackend.ToolOverrides = append(mcpBackend.ToolOverrides, mcpToolOverride(&b.ToolOverrides[i]))
+			}
+			if b.ToolApproval != nil {
+				mcpBackend.ToolApproval = mcpToolApproval(b.ToolApproval)
+			}
+			if b.SecurityPolicy != nil && b.SecurityPolicy.OAuthTokenExchange != nil {
+				mcpBackend.OAuthTokenExchange = mcpBackendOAuthTokenExchange(b.SecurityPolicy.OAuthTokenExchange)
+			}
 			for _, fh := range b.ForwardHeaders {
 				hf := filterapi.MCPHeaderForward{Name: fh.Name}
 				if fh.BackendHeader != nil {
@@ -652,11 +810,36 @@ func mcpConfig(mcpRoutes []aigv1b1.MCPRoute) (_ *filterapi.MCPConfig, hasEffecti
 					mcpRule.Target = &filterapi.MCPAuthorizationTarget{
 						Tools: tools,
 					}
+					for _, resource := range rule.Target.Resources {
+						mcpRule.Target.Resources = append(mcpRule.Target.Resources, filterapi.ResourceTarget{
+							Backend: resource.Backend,
+							URI:     resource.URI,
+						})
+					}
+					for _, prompt := range rule.Target.Prompts {
+						mcpRule.Target.Prompts = append(mcpRule.Target.Prompts, filterapi.PromptTarget{
+							Backend: prompt.Backend,
+							Prompt:  prompt.Prompt,
+						})
+					}
+					for _, method := range rule.Target.Methods {
+						mcpRule.Target.Methods = append(mcpRule.Target.Methods, filterapi.MethodTarget{
+							Method:  method.Method,
+							Backend: ptr.Deref(method.Backend, ""),
+						})
+					}
 				}
 
 				mcpRoute.Authorization.Rules = append(mcpRoute.Authorization.Rules, mcpRule)
 			}
 		}
+		mcpRoute.RateLimits = mcpToolRateLimits(route.Spec.RateLimits)
+		if ts := route.Spec.ToolSearch; ts != nil {
+			mcpRoute.ToolSearch = &filterapi.MCPToolSearch{MaxResults: int(ptr.Deref(ts.MaxResults, 0))}
+			if ts.Embeddings != nil {
+				mcpRoute.ToolSearch.Embeddings = &filterapi.MCPToolSearchEmbeddings{URL: ts.Embeddings.URL, Model: ts.Embeddings.Model}
+			}
+		}
 		// Forward OAuth claim-to-header mappings to all backends in this route.
 		if route.Spec.SecurityPolicy != nil && route.Spec.SecurityPolicy.OAuth != nil {
 			for _, ctoh := range route.Spec.SecurityPolicy.OAuth.ClaimToHeaders {
@@ -668,6 +851,183 @@ func mcpConfig(mcpRoutes []aigv1b1.MCPRoute) (_ *filterapi.MCPConfig, hasEffecti
 	return mc, hasEffectiveRoute
 }
 
+// mcpToolRateLimits converts the tool rate limits of a MCPRoute to the filter config. The limits with an invalid
+// window, which are rejected by the CRD validation, are skipped.
+func mcpToolRateLimits(limits []aigv1b1.MCPToolRateLimit) []filterapi.MCPToolRateLimit {
+	var ret []filterapi.MCPToolRateLimit
+	for i := range limits {
+		l := &limits[i]
+		window, err := time.ParseDuration(string(l.Window))
+		if err != nil || window <= 0 {
+			continue
+		}
+		rl := filterapi.MCPToolRateLimit{
+			Backend:       filterapi.MCPBackendName(l.Backend),
+			Tools:         l.Tools,
+			Calls:         l.Calls,
+			Window:        window,
+			ClientKeyType: filterapi.MCPToolRateLimitClientKeyTypeJWTSubject,
+		}
+		if l.ClientKey != nil {
+			rl.ClientKeyType = filterapi.MCPToolRateLimitClientKeyType(l.ClientKey.Type)
+			rl.ClientKeyHeader = ptr.Deref(l.ClientKey.Header, "")
+		}
+		ret = append(ret, rl)
+	}
+	return ret
+}
+
+// mcpToolApproval converts the tool approval of a MCPRoute backend to the filter config. An invalid timeout, which
+// is rejected by the CRD validation, falls back to the default of the proxy.
+func mcpToolApproval(a *aigv1b1.MCPToolApproval) *filterapi.MCPToolApproval {
+	ret := &filterapi.MCPToolApproval{Tools: a.RequiresApproval}
+	if a.Timeout != nil {
+		if timeout, err := time.ParseDuration(string(*a.Timeout)); err == nil && timeout > 0 {
+			ret.Timeout = timeout
+		}
+	}
+	if a.Webhook != nil {
+		ret.WebhookURL = a.Webhook.URL
+	}
+	return ret
+}
+
+// mcpBackendOAuthTokenExchange converts the OAuth token exchange of a MCPRoute backend to the filter config.
+// The client secret is resolved separately by [GatewayController.resolveMCPTokenExchangeClientSecrets].
+func mcpBackendOAuthTokenExchange(te *aigv1b1.MCPBackendOAuthTokenExchange) *filterapi.MCPBackendOAuthTokenExchange {
+	ret := &filterapi.MCPBackendOAuthTokenExchange{
+		TokenEndpoint:      te.TokenEndpoint,
+		GrantType:          filterapi.MCPOAuthGrantType(ptr.Deref(te.GrantType, aigv1b1.MCPBackendOAuthTokenExchangeGrantTypeTokenExchange)),
+		SubjectTokenType:   ptr.Deref(te.SubjectTokenType, ""),
+		RequestedTokenType: ptr.Deref(te.RequestedTokenType, ""),
+		Audience:           ptr.Deref(te.Audience, ""),
+		Resource:           ptr.Deref(te.Resource, ""),
+		Scopes:             te.Scopes,
+	}
+	if te.ClientAuth != nil {
+		ret.ClientID = te.ClientAuth.ClientID
+	}
+	return ret
+}
+
+// forEachMCPBackendRef calls fn with each backend reference of the given MCPRoutes and the corresponding backend
+// in the MCP filter config built from them by [mcpConfig].
+func forEachMCPBackendRef(mcpRoutes []aigv1b1.MCPRoute, mc *filterapi.MCPConfig,
+	fn func(route *aigv1b1.MCPRoute, ref *aigv1b1.MCPRouteBackendRef, backend *filterapi.MCPBackend) error,
+) error {
+	if mc == nil {
+		return nil
+	}
+	routes := make(map[filterapi.MCPRouteName]*filterapi.MCPRoute, len(mc.Routes))
+	for i := range mc.Routes {
+		routes[mc.Routes[i].Name] = &mc.Routes[i]
+	}
+	for i := range mcpRoutes {
+		route := &mcpRoutes[i]
+		mcpRoute, ok := routes[fmt.Sprintf("%s/%s", route.Namespace, route.Name)]
+		if !ok {
+			continue
+		}
+		// Backends of the filter config are in the same order as the backend references of the route.
+		for j := range route.Spec.BackendRefs {
+			if err := fn(route, &route.Spec.BackendRefs[j], &mcpRoute.Backends[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveMCPTokenExchangeClientSecrets reads the client secrets of the OAuth token exchanges configured on the
+// backends of the given MCPRoutes, and sets them in the corresponding backends of the MCP filter config.
+func (c *GatewayController) resolveMCPTokenExchangeClientSecrets(ctx context.Context, mcpRoutes []aigv1b1.MCPRoute, mc *filterapi.MCPConfig) error {
+	return forEachMCPBackendRef(mcpRoutes, mc, func(route *aigv1b1.MCPRoute, ref *aigv1b1.MCPRouteBackendRef, backend *filterapi.MCPBackend) error {
+		if ref.SecurityPolicy == nil || ref.SecurityPolicy.OAuthTokenExchange == nil || ref.SecurityPolicy.OAuthTokenExchange.ClientAuth == nil {
+			return nil
+		}
+		secretRef := &ref.SecurityPolicy.OAuthTokenExchange.ClientAuth.ClientSecretRef
+		clientSecret, err := c.getSecretData(ctx, mcpSecretRefNamespace(route, secretRef), string(secretRef.Name), "clientSecret")
+		if err != nil {
+			return fmt.Errorf("failed to get the token exchange client secret of backend %s in MCPRoute %s/%s: %w",
+				ref.Name, route.Namespace, route.Name, err)
+		}
+		backend.OAuthTokenExchange.ClientSecret = clientSecret
+		return nil
+	})
+}
+
+// mcpToolOverride converts the given tool override of an MCPRoute backend to the filter config.
+func mcpToolOverride(o *aigv1b1.MCPToolOverride) filterapi.MCPToolOverride {
+	override := filterapi.MCPToolOverride{
+		Name:              o.Name,
+		Rename:            ptr.Deref(o.Rename, ""),
+		Description:       ptr.Deref(o.Description, ""),
+		AppendDescription: ptr.Deref(o.AppendDescription, ""),
+	}
+	if len(o.FixedArguments) > 0 {
+		override.FixedArguments = make(map[string]string, len(o.FixedArguments))
+		for _, arg := range o.FixedArguments {
+			override.FixedArguments[arg.Name] = string(arg.Value.Raw)
+		}
+	}
+	return override
+}
+
+// resolveMCPToolPinningBaselines reads the approved tool fingerprints from the baseline ConfigMaps referenced by the
+// backends of the given MCPRoutes, and sets them in the corresponding backends of the MCP filter config.
+// A missing ConfigMap results in an empty baseline, so that no tool is approved.
+func (c *GatewayController) resolveMCPToolPinningBaselines(ctx context.Context, mcpRoutes []aigv1b1.MCPRoute, mc *filterapi.MCPConfig) error {
+	return forEachMCPBackendRef(mcpRoutes, mc, func(route *aigv1b1.MCPRoute, ref *aigv1b1.MCPRouteBackendRef, backend *filterapi.MCPBackend) error {
+		if ref.ToolPinning == nil {
+			return nil
+		}
+		configMap, err := c.kube.CoreV1().ConfigMaps(route.Namespace).Get(ctx, ref.ToolPinning.BaselineConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				c.logger.Info("tool pinning baseline ConfigMap not found, no tool is approved",
+					"mcproute", route.Name, "namespace", route.Namespace, "backend", ref.Name,
+					"configmap", ref.ToolPinning.BaselineConfigMapName)
+				return nil
+			}
+			return fmt.Errorf("failed to get the tool pinning baseline of backend %s in MCPRoute %s/%s: %w",
+				ref.Name, route.Namespace, route.Name, err)
+		}
+		backend.ToolPinning.Fingerprints = configMap.Data
+		return nil
+	})
+}
+
+// resolveMCPOpenAPIDocuments reads the OpenAPI documents from the ConfigMaps referenced by the OpenAPI backends of
+// the given MCPRoutes, and sets them in the corresponding backends of the MCP filter config.
+// A missing ConfigMap or key results in an empty document, so that the backend exposes no tool.
+func (c *GatewayController) resolveMCPOpenAPIDocuments(ctx context.Context, mcpRoutes []aigv1b1.MCPRoute, mc *filterapi.MCPConfig) error {
+	return forEachMCPBackendRef(mcpRoutes, mc, func(route *aigv1b1.MCPRoute, ref *aigv1b1.MCPRouteBackendRef, backend *filterapi.MCPBackend) error {
+		if ref.OpenAPI == nil || ref.OpenAPI.Document.ConfigMapRef == nil {
+			return nil
+		}
+		configMapRef := ref.OpenAPI.Document.ConfigMapRef
+		key := ptr.Deref(configMapRef.Key, defaultMCPOpenAPIDocumentKey)
+		configMap, err := c.kube.CoreV1().ConfigMaps(route.Namespace).Get(ctx, configMapRef.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				c.logger.Info("OpenAPI document ConfigMap not found, no tool is exposed",
+					"mcproute", route.Name, "namespace", route.Namespace, "backend", ref.Name, "configmap", configMapRef.Name)
+				return nil
+			}
+			return fmt.Errorf("failed to get the OpenAPI document of backend %s in MCPRoute %s/%s: %w",
+				ref.Name, route.Namespace, route.Name, err)
+		}
+		document, ok := configMap.Data[key]
+		if !ok {
+			c.logger.Info("OpenAPI document key not found in ConfigMap, no tool is exposed",
+				"mcproute", route.Name, "namespace", route.Namespace, "backend", ref.Name,
+				"configmap", configMapRef.Name, "key", key)
+		}
+		backend.OpenAPI.Document = document
+		return nil
+	})
+}
+
 func (c *GatewayController) bspToFilterAPIBackendAuth(ctx context.Context, backendSecurityPolicy *aigv1b1.BackendSecurityPolicy) (*filterapi.BackendAuth, error) {
 	namespace := backendSecurityPolicy.Namespace
 	switch backendSecurityPolicy.Spec.Type {
@@ -785,6 +1145,10 @@ func (c *GatewayController) getSecretData(ctx context.Context, namespace, name,
 // on this route and injects their CostExpression as LLMRequestCost entries into
 // the ext_proc config. This allows ext_proc to compute and store quota costs in
 // dynamic metadata for the rate limit filter's HitsAddend to read.
+//
+// The JWT claim and CEL selectors of the bucket rules are injected as well, so that
+// ext_proc exposes them as request headers for the rate limit actions to match on,
+// together with the concurrency limits enforced by ext_proc itself.
 func (c *GatewayController) injectQuotaPolicyCostExpressions(
 	ctx context.Context,
 	route *aigv1b1.AIGatewayRoute,
@@ -832,6 +1196,8 @@ func (c *GatewayController) injectQuotaPolicyCostExpressions(
 			if len(routeModels) > 0 && !routeModels[*pmq.ModelName] {
 				continue
 			}
+			c.injectQuotaPolicySelectors(ec, qp, &pmq.Quota)
+			c.injectQuotaPolicyConcurrencyLimits(ec, qp, route, *pmq.ModelName, &pmq.Quota)
 			expr := "total_tokens"
 			if pmq.Quota.CostExpression != nil {
 				expr = *pmq.Quota.CostExpression
@@ -870,6 +1236,168 @@ func (c *GatewayController) injectQuotaPolicyCostExpressions(
 // writing to this key.
 const QuotaCostMetadataKey = "quota_cost"
 
+// injectQuotaPolicySelectors adds the JWT claims and CEL expressions referenced by the bucket rules
+// of the given quota to the ext_proc config. The lists are kept sorted and deduplicated so that
+// the generated config is deterministic.
+func (c *GatewayController) injectQuotaPolicySelectors(ec *filterapi.Config, qp *aigv1a1.QuotaPolicy, quota *aigv1a1.QuotaDefinition) {
+	insert := func(list []string, v string) []string {
+		if i, found := slices.BinarySearch(list, v); !found {
+			list = slices.Insert(list, i, v)
+		}
+		return list
+	}
+	rules := slices.Clone(quota.BucketRules)
+	for _, l := range quota.ConcurrencyLimits {
+		rules = append(rules, aigv1a1.QuotaRule{JWTClaimSelectors: l.JWTClaimSelectors, CELSelectors: l.CELSelectors})
+	}
+	for _, rule := range rules {
+		if len(rule.JWTClaimSelectors) == 0 && len(rule.CELSelectors) == 0 {
+			continue
+		}
+		if ec.QuotaSelectors == nil {
+			ec.QuotaSelectors = &filterapi.QuotaSelectors{}
+		}
+		for _, sel := range rule.JWTClaimSelectors {
+			ec.QuotaSelectors.JWTClaims = insert(ec.QuotaSelectors.JWTClaims, sel.Name)
+		}
+		for _, sel := range rule.CELSelectors {
+			// An invalid expression would prevent ext_proc from loading the whole config.
+			if _, err := quotaselector.NewCELProgram(sel.Expression); err != nil {
+				c.logger.Error(err, "invalid QuotaPolicy CEL selector, skipping",
+					"policy", qp.Name, "expression", sel.Expression)
+				continue
+			}
+			ec.QuotaSelectors.CELExpressions = insert(ec.QuotaSelectors.CELExpressions, sel.Expression)
+		}
+	}
+}
+
+// injectQuotaPolicyConcurrencyLimits adds the concurrency limits of the given model quota to the ext_proc config.
+//
+// ext_proc enforces the limits before the backend is selected, so each limit applies to the request models
+// that the route sends to the targeted backends as the given model. A policy referenced by multiple routes
+// results in a single limit covering the request models of all of them.
+func (c *GatewayController) injectQuotaPolicyConcurrencyLimits(
+	ec *filterapi.Config,
+	qp *aigv1a1.QuotaPolicy,
+	route *aigv1b1.AIGatewayRoute,
+	model string,
+	quota *aigv1a1.QuotaDefinition,
+) {
+	if len(quota.ConcurrencyLimits) == 0 {
+		return
+	}
+	models := quotaPolicyRequestModels(qp, route, model)
+	if len(models) == 0 {
+		return
+	}
+	for i := range quota.ConcurrencyLimits {
+		l := &quota.ConcurrencyLimits[i]
+		name := fmt.Sprintf("%s/%s/%s/%d", qp.Namespace, qp.Name, model, i)
+		if j := slices.IndexFunc(ec.ConcurrencyLimits, func(existing filterapi.ConcurrencyLimit) bool {
+			return existing.Name == name
+		}); j >= 0 {
+			merged := slices.Concat(ec.ConcurrencyLimits[j].Models, models)
+			slices.Sort(merged)
+			ec.ConcurrencyLimits[j].Models = slices.Compact(merged)
+			continue
+		}
+		headers, err := concurrencyLimitHeaderMatches(l)
+		if err != nil {
+			c.logger.Error(err, "invalid QuotaPolicy concurrency limit, skipping",
+				"policy", qp.Name, "model", model, "index", i)
+			continue
+		}
+		ec.ConcurrencyLimits = append(ec.ConcurrencyLimits, filterapi.ConcurrencyLimit{
+			Name:                  name,
+			Models:                models,
+			Headers:               headers,
+			MaxConcurrentRequests: l.MaxConcurrentRequests,
+			RetryAfterSeconds:     ptr.Deref(l.RetryAfterSeconds, 1),
+		})
+	}
+}
+
+// quotaPolicyRequestModels returns the sorted request models that the route sends as the given model
+// to the backends targeted by the policy, either directly or through a ModelNameOverride.
+func quotaPolicyRequestModels(qp *aigv1a1.QuotaPolicy, route *aigv1b1.AIGatewayRoute, model string) []string {
+	targets := make(map[string]bool, len(qp.Spec.TargetRefs))
+	for _, ref := range qp.Spec.TargetRefs {
+		targets[string(ref.Name)] = true
+	}
+	var models []string
+	for i := range route.Spec.Rules {
+		rule := &route.Spec.Rules[i]
+		var ruleModels []string
+		for _, m := range rule.Matches {
+			for _, h := range m.Headers {
+				if (h.Type == nil || *h.Type == gwapiv1.HeaderMatchExact) && string(h.Name) == internalapi.ModelNameHeaderKeyDefault {
+					ruleModels = append(ruleModels, h.Value)
+				}
+			}
+		}
+		for _, br := range rule.BackendRefs {
+			if !targets[br.Name] {
+				continue
+			}
+			switch {
+			case br.ModelNameOverride == model && len(ruleModels) > 0:
+				models = append(models, ruleModels...)
+			case br.ModelNameOverride == model,
+				br.ModelNameOverride == "" && (len(ruleModels) == 0 || slices.Contains(ruleModels, model)):
+				models = append(models, model)
+			}
+		}
+	}
+	slices.Sort(models)
+	return slices.Compact(models)
+}
+
+// concurrencyLimitHeaderMatches converts the selectors of the concurrency limit into the filterapi header matches.
+func concurrencyLimitHeaderMatches(l *aigv1a1.QuotaConcurrencyLimit) ([]filterapi.ConcurrencyLimitHeaderMatch, error) {
+	// Rejected by the CRD validation, but checked here too so that a limit is never silently widened.
+	for _, sel := range l.ClientSelectors {
+		if sel.SourceCIDR != nil || len(sel.Methods) > 0 || sel.Path != nil || len(sel.QueryParams) > 0 {
+			return nil, errors.New("only header selectors are supported")
+		}
+	}
+	for _, sel := range l.CELSelectors {
+		// The selector is not injected when invalid, so the limit would never match.
+		if _, err := quotaselector.NewCELProgram(sel.Expression); err != nil {
+			return nil, fmt.Errorf("invalid CEL selector %q: %w", sel.Expression, err)
+		}
+	}
+	var headers []filterapi.ConcurrencyLimitHeaderMatch
+	for _, h := range translator.QuotaRuleHeaderMatches(&aigv1a1.QuotaRule{
+		ClientSelectors:   l.ClientSelectors,
+		JWTClaimSelectors: l.JWTClaimSelectors,
+		CELSelectors:      l.CELSelectors,
+	}) {
+		match := filterapi.ConcurrencyLimitHeaderMatch{
+			Name:   strings.ToLower(h.Name),
+			Type:   filterapi.ConcurrencyLimitHeaderMatchTypeExact,
+			Value:  ptr.Deref(h.Value, ""),
+			Invert: ptr.Deref(h.Invert, false),
+		}
+		switch ptr.Deref(h.Type, egv1a1.HeaderMatchExact) {
+		case egv1a1.HeaderMatchExact:
+		case egv1a1.HeaderMatchRegularExpression:
+			// An invalid expression would prevent ext_proc from loading the whole config.
+			if _, err := regexp.Compile(match.Value); err != nil {
+				return nil, fmt.Errorf("invalid regular expression for header %s: %w", h.Name, err)
+			}
+			match.Type = filterapi.ConcurrencyLimitHeaderMatchTypeRegularExpression
+		case egv1a1.HeaderMatchDistinct:
+			match.Type = filterapi.ConcurrencyLimitHeaderMatchTypeDistinct
+			match.Value, match.Invert = "", false
+		default:
+			return nil, fmt.Errorf("unsupported header match type %q for header %s", *h.Type, h.Name)
+		}
+		headers = append(headers, match)
+	}
+	return headers, nil
+}
+
 // backendWithMaybeBSP retrieves the AIServiceBackend and its associated BackendSecurityPolicy if it exists.
 func (c *GatewayController) backendWithMaybeBSP(ctx context.Context, namespace, name string) (backend *aigv1b1.AIServiceBackend, bsp *aigv1b1.BackendSecurityPolicy, err error) {
 	backend = &aigv1b1.AIServiceBackend{}
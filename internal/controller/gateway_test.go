@@ -11,12 +11,14 @@ import (
 	"testing"
 	"time"
 
+	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zapcore"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fake2 "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/ptr"
@@ -27,6 +29,7 @@ import (
 	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/yaml"
 
+	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 	"github.com/envoyproxy/ai-gateway/internal/controller/rotators"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
@@ -42,7 +45,10 @@ func requireLLMRequestCostsEqual(t *testing.T, want, got []filterapi.LLMRequestC
 		if a.RouteName != b.RouteName {
 			return a.RouteName < b.RouteName
 		}
-		return a.MetadataKey < b.MetadataKey
+		if a.MetadataKey != b.MetadataKey {
+			return a.MetadataKey < b.MetadataKey
+		}
+		return a.Backend < b.Backend
 	}
 	if diff := cmp.Diff(want, got, cmpopts.SortSlices(less)); diff != "" {
 		t.Fatalf("LLMRequestCosts not equal (-want +got):\n%s", diff)
@@ -630,6 +636,235 @@ func TestGatewayController_reconcileFilterConfigSecret_RouteLevelLLMRequestCostA
 	requireLLMRequestCostsEqual(t, wantLLMRequestCosts, fc.LLMRequestCosts)
 }
 
+// TestGatewayController_reconcileFilterConfigSecret_BackendLevelLLMRequestCosts verifies that the costs of
+// an AIServiceBackend are carried per route with the Backend filter set, alongside the route-level costs.
+func TestGatewayController_reconcileFilterConfigSecret_BackendLevelLLMRequestCosts(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	kube := fake2.NewClientset()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zap.Options{Development: true, Level: zapcore.DebugLevel})))
+	c := NewGatewayController(fakeClient, kube, ctrl.Log,
+		"docker.io/envoyproxy/ai-gateway-extproc:latest", "info", false, nil, true)
+
+	const gwNamespace = "ns"
+	routes := []aigv1b1.AIGatewayRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: gwNamespace},
+			Spec: aigv1b1.AIGatewayRouteSpec{
+				Rules: []aigv1b1.AIGatewayRouteRule{
+					{BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "ptu-backend"}, {Name: "on-demand-backend"}}},
+					// The same backend referenced again must not duplicate its costs.
+					{BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "ptu-backend"}}},
+				},
+				LLMRequestCosts: []aigv1b1.LLMRequestCost{
+					{MetadataKey: "billing_charges", Type: aigv1b1.LLMRequestCostTypeCEL, CEL: ptr.To("input_tokens + output_tokens")},
+				},
+			},
+		},
+	}
+
+	for _, backend := range []*aigv1b1.AIServiceBackend{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ptu-backend", Namespace: gwNamespace},
+			Spec: aigv1b1.AIServiceBackendSpec{
+				BackendRef: gwapiv1.BackendObjectReference{Name: "some-backend", Namespace: ptr.To[gwapiv1.Namespace](gwNamespace)},
+				LLMRequestCosts: []aigv1b1.LLMRequestCost{
+					{MetadataKey: "billing_charges", Type: aigv1b1.LLMRequestCostTypeInputToken},
+					// Last definition wins.
+					{MetadataKey: "billing_charges", Type: aigv1b1.LLMRequestCostTypeCEL, CEL: ptr.To("0")},
+					{MetadataKey: "ptu_tokens", Type: aigv1b1.LLMRequestCostTypeTotalToken},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "on-demand-backend", Namespace: gwNamespace},
+			Spec: aigv1b1.AIServiceBackendSpec{
+				BackendRef: gwapiv1.BackendObjectReference{Name: "some-backend", Namespace: ptr.To[gwapiv1.Namespace](gwNamespace)},
+			},
+		},
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), backend))
+	}
+
+	const someNamespace = "some-namespace"
+	configName := FilterConfigSecretPerGatewayName("gw", gwNamespace)
+	effective, err := c.reconcileFilterConfigSecret(t.Context(), configName, someNamespace, routes, nil, "foouuid", nil)
+	require.NoError(t, err)
+	require.True(t, effective, "expected filter config to be effective")
+
+	secret, err := kube.CoreV1().Secrets(someNamespace).Get(t.Context(), configName, metav1.GetOptions{})
+	require.NoError(t, err)
+	configStr, ok := secret.StringData[FilterConfigKeyInSecret]
+	require.True(t, ok)
+	var fc filterapi.Config
+	require.NoError(t, yaml.Unmarshal([]byte(configStr), &fc))
+	requireLLMRequestCostsEqual(t, []filterapi.LLMRequestCost{
+		{
+			MetadataKey: "billing_charges",
+			RouteName:   "ns/route",
+			Type:        filterapi.LLMRequestCostTypeCEL,
+			CEL:         "input_tokens + output_tokens",
+		},
+		{
+			MetadataKey: "billing_charges",
+			RouteName:   "ns/route",
+			Backend:     "ns/ptu-backend",
+			Type:        filterapi.LLMRequestCostTypeCEL,
+			CEL:         "0",
+		},
+		{
+			MetadataKey: "ptu_tokens",
+			RouteName:   "ns/route",
+			Backend:     "ns/ptu-backend",
+			Type:        filterapi.LLMRequestCostTypeTotalToken,
+		},
+	}, fc.LLMRequestCosts)
+
+	t.Run("invalid CEL expression", func(t *testing.T) {
+		var backend aigv1b1.AIServiceBackend
+		require.NoError(t, fakeClient.Get(t.Context(), client.ObjectKey{Name: "on-demand-backend", Namespace: gwNamespace}, &backend))
+		backend.Spec.LLMRequestCosts = []aigv1b1.LLMRequestCost{
+			{MetadataKey: "cost", Type: aigv1b1.LLMRequestCostTypeCEL, CEL: ptr.To("invalid syntax (((")},
+		}
+		require.NoError(t, fakeClient.Update(t.Context(), &backend))
+		_, err := c.reconcileFilterConfigSecret(t.Context(), configName, someNamespace, routes, nil, "foouuid", nil)
+		require.ErrorContains(t, err, "failed to convert LLMRequestCosts for backend on-demand-backend: invalid CEL expression")
+	})
+}
+
+func TestGatewayController_reconcileFilterConfigSecret_Admission(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	kube := fake2.NewClientset()
+	c := NewGatewayController(fakeClient, kube, ctrl.Log,
+		"docker.io/envoyproxy/ai-gateway-extproc:latest", "info", false, nil, true)
+
+	const gwNamespace = "ns"
+	routes := []aigv1b1.AIGatewayRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "interactive", Namespace: gwNamespace},
+			Spec: aigv1b1.AIGatewayRouteSpec{
+				Rules: []aigv1b1.AIGatewayRouteRule{
+					{BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "azure"}, {Name: "openai"}}},
+				},
+				PriorityClass: &aigv1b1.AIGatewayRoutePriorityClass{
+					Default:  ptr.To(aigv1b1.PriorityClassHigh),
+					Header:   ptr.To("X-Priority-Class"),
+					JWTClaim: ptr.To("tier.priority"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: gwNamespace},
+			Spec: aigv1b1.AIGatewayRouteSpec{
+				Rules: []aigv1b1.AIGatewayRouteRule{
+					{BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "azure"}}},
+				},
+			},
+		},
+	}
+
+	for _, backend := range []*aigv1b1.AIServiceBackend{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: gwNamespace},
+			Spec: aigv1b1.AIServiceBackendSpec{
+				BackendRef: gwapiv1.BackendObjectReference{Name: "some-backend", Namespace: ptr.To[gwapiv1.Namespace](gwNamespace)},
+				Admission: &aigv1b1.AIServiceBackendAdmission{
+					LatencyThreshold: ptr.To[gwapiv1.Duration]("8s"),
+					MaxQueueSize:     ptr.To[uint32](0),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "openai", Namespace: gwNamespace},
+			Spec: aigv1b1.AIServiceBackendSpec{
+				BackendRef: gwapiv1.BackendObjectReference{Name: "some-backend", Namespace: ptr.To[gwapiv1.Namespace](gwNamespace)},
+			},
+		},
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), backend))
+	}
+
+	const someNamespace = "some-namespace"
+	configName := FilterConfigSecretPerGatewayName("gw", gwNamespace)
+	_, err := c.reconcileFilterConfigSecret(t.Context(), configName, someNamespace, routes, nil, "foouuid", nil)
+	require.NoError(t, err)
+
+	secret, err := kube.CoreV1().Secrets(someNamespace).Get(t.Context(), configName, metav1.GetOptions{})
+	require.NoError(t, err)
+	var fc filterapi.Config
+	require.NoError(t, yaml.Unmarshal([]byte(secret.StringData[FilterConfigKeyInSecret]), &fc))
+	require.Len(t, fc.Backends, 3)
+
+	// Both routes share the admission signals of the azure backend.
+	expAdmission := &filterapi.BackendAdmission{
+		Key:                       "ns/azure",
+		Window:                    30 * time.Second,
+		ThrottledPercentThreshold: 5,
+		LatencyThreshold:          8 * time.Second,
+		MaxQueueSize:              0,
+		QueueTimeout:              2 * time.Second,
+	}
+	require.Equal(t, expAdmission, fc.Backends[0].Admission)
+	require.Equal(t, &filterapi.PriorityClassConfig{
+		Default: filterapi.PriorityClassHigh, Header: "x-priority-class", JWTClaim: "tier.priority",
+	}, fc.Backends[0].PriorityClass)
+	require.Nil(t, fc.Backends[1].Admission)
+	require.NotNil(t, fc.Backends[1].PriorityClass)
+	require.Equal(t, expAdmission, fc.Backends[2].Admission)
+	require.Nil(t, fc.Backends[2].PriorityClass)
+
+	t.Run("invalid duration", func(t *testing.T) {
+		var backend aigv1b1.AIServiceBackend
+		require.NoError(t, fakeClient.Get(t.Context(), client.ObjectKey{Name: "openai", Namespace: gwNamespace}, &backend))
+		backend.Spec.Admission = &aigv1b1.AIServiceBackendAdmission{Window: ptr.To[gwapiv1.Duration]("forever")}
+		require.NoError(t, fakeClient.Update(t.Context(), &backend))
+		_, err := c.reconcileFilterConfigSecret(t.Context(), configName, someNamespace, routes, nil, "foouuid", nil)
+		require.ErrorContains(t, err, `failed to convert admission for backend openai: invalid window "forever"`)
+	})
+}
+
+func Test_admissionToFilterAPI(t *testing.T) {
+	a, err := admissionToFilterAPI(nil, "ns/backend")
+	require.NoError(t, err)
+	require.Nil(t, a)
+
+	a, err = admissionToFilterAPI(&aigv1b1.AIServiceBackendAdmission{
+		Window:                    ptr.To[gwapiv1.Duration]("1m"),
+		ThrottledPercentThreshold: ptr.To[uint32](20),
+		MaxQueueSize:              ptr.To[uint32](10),
+		QueueTimeout:              ptr.To[gwapiv1.Duration]("500ms"),
+	}, "ns/backend")
+	require.NoError(t, err)
+	require.Equal(t, &filterapi.BackendAdmission{
+		Key:                       "ns/backend",
+		Window:                    time.Minute,
+		ThrottledPercentThreshold: 20,
+		MaxQueueSize:              10,
+		QueueTimeout:              500 * time.Millisecond,
+	}, a)
+
+	for _, tc := range []struct {
+		name   string
+		in     *aigv1b1.AIServiceBackendAdmission
+		expErr string
+	}{
+		{name: "latency threshold", in: &aigv1b1.AIServiceBackendAdmission{LatencyThreshold: ptr.To[gwapiv1.Duration]("x")}, expErr: `invalid latencyThreshold "x"`},
+		{name: "queue timeout", in: &aigv1b1.AIServiceBackendAdmission{QueueTimeout: ptr.To[gwapiv1.Duration]("x")}, expErr: `invalid queueTimeout "x"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := admissionToFilterAPI(tc.in, "ns/backend")
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func Test_priorityClassToFilterAPI(t *testing.T) {
+	require.Nil(t, priorityClassToFilterAPI(nil))
+	require.Equal(t, &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassNormal},
+		priorityClassToFilterAPI(&aigv1b1.AIGatewayRoutePriorityClass{}))
+	require.Equal(t, &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassLow, Header: "x-priority"},
+		priorityClassToFilterAPI(&aigv1b1.AIGatewayRoutePriorityClass{Default: ptr.To(aigv1b1.PriorityClassLow), Header: ptr.To("X-Priority")}))
+}
+
 // TestGatewayController_reconcileFilterConfigSecret_InvalidCELExpression tests that invalid CEL
 // expressions in LLMRequestCosts cause an error during reconciliation.
 func TestGatewayController_reconcileFilterConfigSecret_InvalidCELExpression(t *testing.T) {
@@ -2196,6 +2431,526 @@ func Test_mcpConfig_ToolSelectorExclude(t *testing.T) {
 	require.Equal(t, []string{"^secret.*"}, ts.ExcludeRegex)
 }
 
+func Test_mcpConfig_ResourceAndPromptSelectors(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{
+					BackendObjectReference: gwapiv1.BackendObjectReference{
+						Name: gwapiv1.ObjectName("backend"),
+					},
+					ResourceSelector: &aigv1b1.MCPResourceFilter{
+						IncludeRegex: []string{"^file:///public/.*"},
+						Exclude:      []string{"file:///public/secret.txt"},
+					},
+					PromptSelector: &aigv1b1.MCPPromptFilter{
+						Include: []string{"summarize"},
+					},
+				}},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 1)
+	require.Len(t, mc.Routes[0].Backends, 1)
+	backend := mc.Routes[0].Backends[0]
+	require.Nil(t, backend.ToolSelector)
+	require.Equal(t, &filterapi.MCPResourceSelector{
+		IncludeRegex: []string{"^file:///public/.*"},
+		Exclude:      []string{"file:///public/secret.txt"},
+	}, backend.ResourceSelector)
+	require.Equal(t, &filterapi.MCPPromptSelector{Include: []string{"summarize"}}, backend.PromptSelector)
+}
+
+func Test_mcpConfig_SchemaValidation(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "none"}},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "defaults"},
+						SchemaValidation:       &aigv1b1.MCPSchemaValidation{},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "results-only"},
+						SchemaValidation: &aigv1b1.MCPSchemaValidation{
+							Arguments:         ptr.To(false),
+							StructuredContent: ptr.To(aigv1b1.MCPStructuredContentValidationActionStrip),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mc, _ := mcpConfig(mcpRoutes)
+	backends := mc.Routes[0].Backends
+	require.Nil(t, backends[0].SchemaValidation)
+	require.Equal(t, &filterapi.MCPSchemaValidation{Arguments: true}, backends[1].SchemaValidation)
+	require.Equal(t, &filterapi.MCPSchemaValidation{
+		StructuredContent: filterapi.MCPStructuredContentValidationActionStrip,
+	}, backends[2].SchemaValidation)
+}
+
+func Test_mcpConfig_ToolNaming(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "default"}},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"},
+						ToolNamePrefix:         &aigv1b1.MCPToolNamePrefix{Separator: ptr.To("_")},
+						ToolOverrides: []aigv1b1.MCPToolOverride{
+							{
+								Name:              "create_issue",
+								Rename:            ptr.To("open_ticket"),
+								AppendDescription: ptr.To("Only for the envoyproxy organization."),
+								FixedArguments: []aigv1b1.MCPToolFixedArgument{
+									{Name: "owner", Value: apiextensionsv1.JSON{Raw: []byte(`"envoyproxy"`)}},
+									{Name: "labels", Value: apiextensionsv1.JSON{Raw: []byte(`["bug"]`)}},
+								},
+							},
+							{Name: "search", Description: ptr.To("Search the code.")},
+						},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "jira"},
+						ToolNamePrefix:         &aigv1b1.MCPToolNamePrefix{Disabled: true},
+					},
+				},
+			},
+		},
+	}
+
+	mc, _ := mcpConfig(mcpRoutes)
+	backends := mc.Routes[0].Backends
+	require.Nil(t, backends[0].ToolNamePrefix)
+	require.Nil(t, backends[0].ToolOverrides)
+	require.Equal(t, &filterapi.MCPToolNamePrefix{Separator: "_"}, backends[1].ToolNamePrefix)
+	require.Equal(t, []filterapi.MCPToolOverride{
+		{
+			Name:              "create_issue",
+			Rename:            "open_ticket",
+			AppendDescription: "Only for the envoyproxy organization.",
+			FixedArguments:    map[string]string{"owner": `"envoyproxy"`, "labels": `["bug"]`},
+		},
+		{Name: "search", Description: "Search the code."},
+	}, backends[1].ToolOverrides)
+	require.Equal(t, &filterapi.MCPToolNamePrefix{Disabled: true}, backends[2].ToolNamePrefix)
+}
+
+func Test_mcpConfig_AuthorizationTargets(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "postgres"},
+				}},
+				SecurityPolicy: &aigv1b1.MCPRouteSecurityPolicy{
+					Authorization: &aigv1b1.MCPRouteAuthorization{
+						Rules: []aigv1b1.MCPRouteAuthorizationRule{{
+							Target: &aigv1b1.MCPAuthorizationTarget{
+								Resources: []aigv1b1.MCPResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+								Prompts:   []aigv1b1.MCPPromptTarget{{Backend: "postgres", Prompt: "explain"}},
+								Methods: []aigv1b1.MCPMethodTarget{
+									{Method: "completion/complete", Backend: ptr.To("postgres")},
+									{Method: "resources/subscribe"},
+								},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 1)
+	require.NotNil(t, mc.Routes[0].Authorization)
+	require.Len(t, mc.Routes[0].Authorization.Rules, 1)
+	require.Equal(t, &filterapi.MCPAuthorizationTarget{
+		Tools:     []filterapi.ToolCall{},
+		Resources: []filterapi.ResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+		Prompts:   []filterapi.PromptTarget{{Backend: "postgres", Prompt: "explain"}},
+		Methods: []filterapi.MethodTarget{
+			{Method: "completion/complete", Backend: "postgres"},
+			{Method: "resources/subscribe"},
+		},
+	}, mc.Routes[0].Authorization.Rules[0].Target)
+}
+
+func Test_mcpConfig_RateLimits(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "browser"},
+				}},
+				RateLimits: []aigv1b1.MCPToolRateLimit{
+					{Backend: "browser", Tools: []string{"navigate"}, Calls: 10, Window: "1m"},
+					{
+						Backend:   "browser",
+						Calls:     100,
+						Window:    "24h",
+						ClientKey: &aigv1b1.MCPToolRateLimitClientKey{Type: aigv1b1.MCPToolRateLimitClientKeyTypeHeader, Header: ptr.To("x-user-id")},
+					},
+					{
+						Backend:   "browser",
+						Calls:     5,
+						Window:    "1s",
+						ClientKey: &aigv1b1.MCPToolRateLimitClientKey{Type: aigv1b1.MCPToolRateLimitClientKeyTypeSession},
+					},
+					// Invalid windows are skipped.
+					{Backend: "browser", Calls: 1, Window: "0s"},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 1)
+	require.Equal(t, []filterapi.MCPToolRateLimit{
+		{
+			Backend:       "browser",
+			Tools:         []string{"navigate"},
+			Calls:         10,
+			Window:        time.Minute,
+			ClientKeyType: filterapi.MCPToolRateLimitClientKeyTypeJWTSubject,
+		},
+		{
+			Backend:         "browser",
+			Calls:           100,
+			Window:          24 * time.Hour,
+			ClientKeyType:   filterapi.MCPToolRateLimitClientKeyTypeHeader,
+			ClientKeyHeader: "x-user-id",
+		},
+		{
+			Backend:       "browser",
+			Calls:         5,
+			Window:        time.Second,
+			ClientKeyType: filterapi.MCPToolRateLimitClientKeyTypeSession,
+		},
+	}, mc.Routes[0].RateLimits)
+}
+
+func Test_mcpConfig_ToolSearch(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-search", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"}}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "keywords", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"}}},
+				ToolSearch:  &aigv1b1.MCPToolSearch{},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "embeddings", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"}}},
+				ToolSearch: &aigv1b1.MCPToolSearch{
+					MaxResults: ptr.To[int32](5),
+					Embeddings: &aigv1b1.MCPToolSearchEmbeddings{URL: "http://gateway.example.com/v1/embeddings", Model: "text-embedding-3-small"},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 3)
+	require.Nil(t, mc.Routes[0].ToolSearch)
+	require.Equal(t, &filterapi.MCPToolSearch{}, mc.Routes[1].ToolSearch)
+	require.Equal(t, &filterapi.MCPToolSearch{
+		MaxResults: 5,
+		Embeddings: &filterapi.MCPToolSearchEmbeddings{URL: "http://gateway.example.com/v1/embeddings", Model: "text-embedding-3-small"},
+	}, mc.Routes[2].ToolSearch)
+}
+
+func Test_mcpConfig_ToolApproval(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"},
+						ToolApproval: &aigv1b1.MCPToolApproval{
+							RequiresApproval: []string{"delete_repository"},
+							Timeout:          ptr.To(gwapiv1.Duration("2m")),
+							Webhook:          &aigv1b1.MCPToolApprovalWebhook{URL: "https://approvals.example.com/mcp"},
+						},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "filesystem"},
+						ToolApproval: &aigv1b1.MCPToolApproval{
+							RequiresApproval: []string{"write_file", "delete_file"},
+							// Invalid timeouts fall back to the default.
+							Timeout: ptr.To(gwapiv1.Duration("0s")),
+						},
+					},
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "search"}},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 1)
+	require.Len(t, mc.Routes[0].Backends, 3)
+	require.Equal(t, &filterapi.MCPToolApproval{
+		Tools:      []string{"delete_repository"},
+		Timeout:    2 * time.Minute,
+		WebhookURL: "https://approvals.example.com/mcp",
+	}, mc.Routes[0].Backends[0].ToolApproval)
+	require.Equal(t, &filterapi.MCPToolApproval{
+		Tools: []string{"write_file", "delete_file"},
+	}, mc.Routes[0].Backends[1].ToolApproval)
+	require.Nil(t, mc.Routes[0].Backends[2].ToolApproval)
+}
+
+func Test_mcpConfig_Transport(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "legacy"},
+						Path:                   ptr.To("/v1/sse"),
+						Transport:              ptr.To(aigv1b1.MCPBackendTransportSSE),
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "legacy-default-path"},
+						Transport:              ptr.To(aigv1b1.MCPBackendTransportSSE),
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "streamable"},
+						Path:                   ptr.To("/mcp"),
+						Transport:              ptr.To(aigv1b1.MCPBackendTransportStreamableHTTP),
+					},
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "default"}},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 1)
+	backends := mc.Routes[0].Backends
+	require.Len(t, backends, 4)
+	require.Equal(t, filterapi.MCPBackendTransportSSE, backends[0].Transport)
+	require.Equal(t, "/v1/sse", backends[0].Path)
+	require.Equal(t, filterapi.MCPBackendTransportSSE, backends[1].Transport)
+	require.Equal(t, "/mcp", backends[1].Path)
+	// The paths of the Streamable HTTP backends are rewritten by Envoy, so they are not needed by the MCP proxy.
+	for _, b := range backends[2:] {
+		require.Empty(t, b.Transport)
+		require.Empty(t, b.Path)
+	}
+}
+
+func TestGatewayController_resolveMCPTokenExchangeClientSecrets(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "plain"}},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"},
+						SecurityPolicy: &aigv1b1.MCPBackendSecurityPolicy{
+							OAuthTokenExchange: &aigv1b1.MCPBackendOAuthTokenExchange{
+								TokenEndpoint: "https://sts.example.com/token",
+								Audience:      ptr.To("https://api.github.com"),
+								Scopes:        []string{"repo"},
+								ClientAuth: &aigv1b1.MCPBackendOAuthTokenExchangeClientAuth{
+									ClientID:        "gateway",
+									ClientSecretRef: gwapiv1.SecretObjectReference{Name: "sts-secret"},
+								},
+							},
+						},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "jira"},
+						SecurityPolicy: &aigv1b1.MCPBackendSecurityPolicy{
+							OAuthTokenExchange: &aigv1b1.MCPBackendOAuthTokenExchange{
+								TokenEndpoint: "https://login.example.com/token",
+								GrantType:     ptr.To(aigv1b1.MCPBackendOAuthTokenExchangeGrantTypeOnBehalfOf),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	require.Len(t, mc.Routes, 1)
+	backends := mc.Routes[0].Backends
+	require.Len(t, backends, 3)
+	require.Nil(t, backends[0].OAuthTokenExchange)
+	require.Equal(t, &filterapi.MCPBackendOAuthTokenExchange{
+		TokenEndpoint: "https://sts.example.com/token",
+		GrantType:     filterapi.MCPOAuthGrantTypeTokenExchange,
+		Audience:      "https://api.github.com",
+		Scopes:        []string{"repo"},
+		ClientID:      "gateway",
+	}, backends[1].OAuthTokenExchange)
+	require.Equal(t, &filterapi.MCPBackendOAuthTokenExchange{
+		TokenEndpoint: "https://login.example.com/token",
+		GrantType:     filterapi.MCPOAuthGrantTypeOnBehalfOf,
+	}, backends[2].OAuthTokenExchange)
+
+	kube := fake2.NewClientset()
+	c := NewGatewayController(requireNewFakeClientWithIndexes(t), kube, ctrl.Log,
+		"docker.io/envoyproxy/ai-gateway-extproc:latest", "info", false, nil, true)
+
+	err := c.resolveMCPTokenExchangeClientSecrets(t.Context(), mcpRoutes, mc)
+	require.ErrorContains(t, err, "failed to get the token exchange client secret of backend github in MCPRoute ns/route")
+
+	_, err = kube.CoreV1().Secrets("ns").Create(t.Context(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts-secret", Namespace: "ns"},
+		Data:       map[string][]byte{"clientSecret": []byte("s3cr3t")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, c.resolveMCPTokenExchangeClientSecrets(t.Context(), mcpRoutes, mc))
+	require.Equal(t, "s3cr3t", backends[1].OAuthTokenExchange.ClientSecret)
+	require.Empty(t, backends[2].OAuthTokenExchange.ClientSecret)
+}
+
+func TestGatewayController_resolveMCPToolPinningBaselines(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "plain"}},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"},
+						ToolPinning:            &aigv1b1.MCPToolPinning{BaselineConfigMapName: "github-tools"},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "jira"},
+						ToolPinning: &aigv1b1.MCPToolPinning{
+							BaselineConfigMapName: "missing",
+							Action:                ptr.To(aigv1b1.MCPToolPinningActionBlock),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	backends := mc.Routes[0].Backends
+	require.Nil(t, backends[0].ToolPinning)
+	require.Equal(t, &filterapi.MCPToolPinning{Action: filterapi.MCPToolPinningActionDrop}, backends[1].ToolPinning)
+	require.Equal(t, &filterapi.MCPToolPinning{Action: filterapi.MCPToolPinningActionBlock}, backends[2].ToolPinning)
+
+	kube := fake2.NewClientset()
+	_, err := kube.CoreV1().ConfigMaps("ns").Create(t.Context(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-tools", Namespace: "ns"},
+		Data:       map[string]string{"create_issue": "abc"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	c := NewGatewayController(requireNewFakeClientWithIndexes(t), kube, ctrl.Log,
+		"docker.io/envoyproxy/ai-gateway-extproc:latest", "info", false, nil, true)
+
+	require.NoError(t, c.resolveMCPToolPinningBaselines(t.Context(), mcpRoutes, mc))
+	require.Equal(t, map[string]string{"create_issue": "abc"}, backends[1].ToolPinning.Fingerprints)
+	// A missing baseline approves no tool.
+	require.Empty(t, backends[2].ToolPinning.Fingerprints)
+}
+
+func TestGatewayController_resolveMCPOpenAPIDocuments(t *testing.T) {
+	mcpRoutes := []aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "ns"},
+			Spec: aigv1b1.MCPRouteSpec{
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{
+					{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "plain"}},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "inline"},
+						OpenAPI: &aigv1b1.MCPOpenAPIBackend{
+							Document:   aigv1b1.MCPOpenAPIDocument{Inline: ptr.To("openapi: 3.0.0")},
+							BasePath:   ptr.To("/v1"),
+							Operations: []string{"listPets"},
+						},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "configmap"},
+						OpenAPI: &aigv1b1.MCPOpenAPIBackend{
+							Document: aigv1b1.MCPOpenAPIDocument{ConfigMapRef: &aigv1b1.MCPOpenAPIConfigMapRef{Name: "petstore"}},
+						},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "custom-key"},
+						OpenAPI: &aigv1b1.MCPOpenAPIBackend{
+							Document: aigv1b1.MCPOpenAPIDocument{ConfigMapRef: &aigv1b1.MCPOpenAPIConfigMapRef{
+								Name: "petstore",
+								Key:  ptr.To("openapi.json"),
+							}},
+						},
+					},
+					{
+						BackendObjectReference: gwapiv1.BackendObjectReference{Name: "missing"},
+						OpenAPI: &aigv1b1.MCPOpenAPIBackend{
+							Document: aigv1b1.MCPOpenAPIDocument{ConfigMapRef: &aigv1b1.MCPOpenAPIConfigMapRef{Name: "missing"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mc, effective := mcpConfig(mcpRoutes)
+	require.True(t, effective)
+	backends := mc.Routes[0].Backends
+	require.Nil(t, backends[0].OpenAPI)
+	require.Equal(t, &filterapi.MCPOpenAPIBackend{
+		Document:   "openapi: 3.0.0",
+		BasePath:   "/v1",
+		Operations: []string{"listPets"},
+	}, backends[1].OpenAPI)
+	require.Equal(t, &filterapi.MCPOpenAPIBackend{}, backends[2].OpenAPI)
+
+	kube := fake2.NewClientset()
+	_, err := kube.CoreV1().ConfigMaps("ns").Create(t.Context(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "petstore", Namespace: "ns"},
+		Data:       map[string]string{"openapi.yaml": "openapi: 3.1.0", "openapi.json": `{"openapi":"3.1.0"}`},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	c := NewGatewayController(requireNewFakeClientWithIndexes(t), kube, ctrl.Log,
+		"docker.io/envoyproxy/ai-gateway-extproc:latest", "info", false, nil, true)
+
+	require.NoError(t, c.resolveMCPOpenAPIDocuments(t.Context(), mcpRoutes, mc))
+	require.Equal(t, "openapi: 3.0.0", backends[1].OpenAPI.Document)
+	require.Equal(t, "openapi: 3.1.0", backends[2].OpenAPI.Document)
+	require.JSONEq(t, `{"openapi":"3.1.0"}`, backends[3].OpenAPI.Document)
+	// A missing document exposes no tool.
+	require.Empty(t, backends[4].OpenAPI.Document)
+}
+
 func Test_mcpConfig_ForwardHeaders(t *testing.T) {
 	renamed := "X-Backend-Auth"
 	mcpRoutes := []aigv1b1.MCPRoute{
@@ -2812,3 +3567,182 @@ func Test_mergeBodyMutations(t *testing.T) {
 		})
 	}
 }
+
+func TestGatewayController_injectQuotaPolicySelectors(t *testing.T) {
+	c := &GatewayController{logger: ctrl.Log}
+	ec := &filterapi.Config{}
+	qp := &aigv1a1.QuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "qp", Namespace: "ns"}}
+
+	c.injectQuotaPolicySelectors(ec, qp, &aigv1a1.QuotaDefinition{
+		BucketRules: []aigv1a1.QuotaRule{{Quota: aigv1a1.QuotaValue{Limit: 1, Duration: "1m"}}},
+	})
+	require.Nil(t, ec.QuotaSelectors, "rules without JWT claim or CEL selectors must not configure quota selectors")
+
+	quota := &aigv1a1.QuotaDefinition{
+		BucketRules: []aigv1a1.QuotaRule{
+			{
+				JWTClaimSelectors: []aigv1a1.QuotaJWTClaimSelector{{Name: "sub"}, {Name: "org.id"}},
+				CELSelectors: []aigv1a1.QuotaCELSelector{
+					{Expression: `request.model == "gpt-4o"`},
+					{Expression: "invalid =="},
+				},
+			},
+			{
+				JWTClaimSelectors: []aigv1a1.QuotaJWTClaimSelector{{Name: "sub"}},
+				CELSelectors:      []aigv1a1.QuotaCELSelector{{Expression: `request.headers["x-team"]`}},
+			},
+		},
+	}
+	c.injectQuotaPolicySelectors(ec, qp, quota)
+	// Injecting the same quota twice (e.g. from another route) must not duplicate entries.
+	c.injectQuotaPolicySelectors(ec, qp, quota)
+	require.Equal(t, &filterapi.QuotaSelectors{
+		JWTClaims:      []string{"org.id", "sub"},
+		CELExpressions: []string{`request.headers["x-team"]`, `request.model == "gpt-4o"`},
+	}, ec.QuotaSelectors)
+
+	c.injectQuotaPolicySelectors(ec, qp, &aigv1a1.QuotaDefinition{
+		ConcurrencyLimits: []aigv1a1.QuotaConcurrencyLimit{{
+			JWTClaimSelectors: []aigv1a1.QuotaJWTClaimSelector{{Name: "tenant"}},
+			CELSelectors:      []aigv1a1.QuotaCELSelector{{Expression: `request.headers["x-tier"]`}},
+		}},
+	})
+	require.Equal(t, &filterapi.QuotaSelectors{
+		JWTClaims:      []string{"org.id", "sub", "tenant"},
+		CELExpressions: []string{`request.headers["x-team"]`, `request.headers["x-tier"]`, `request.model == "gpt-4o"`},
+	}, ec.QuotaSelectors)
+}
+
+func TestGatewayController_injectQuotaPolicyConcurrencyLimits(t *testing.T) {
+	c := &GatewayController{logger: ctrl.Log}
+	qp := &aigv1a1.QuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "qp", Namespace: "ns"},
+		Spec: aigv1a1.QuotaPolicySpec{
+			TargetRefs: []gwapiv1a2.LocalPolicyTargetReference{{Name: "llama-backend"}},
+		},
+	}
+	newRoute := func(name string, requestModels []string, modelNameOverride string) *aigv1b1.AIGatewayRoute {
+		var headers []gwapiv1.HTTPHeaderMatch
+		for _, m := range requestModels {
+			headers = append(headers, gwapiv1.HTTPHeaderMatch{Name: internalapi.ModelNameHeaderKeyDefault, Value: m})
+		}
+		return &aigv1b1.AIGatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: aigv1b1.AIGatewayRouteSpec{
+				Rules: []aigv1b1.AIGatewayRouteRule{
+					{
+						Matches:     []aigv1b1.AIGatewayRouteRuleMatch{{Headers: headers}},
+						BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "llama-backend", ModelNameOverride: modelNameOverride}},
+					},
+					{
+						Matches:     []aigv1b1.AIGatewayRouteRuleMatch{{Headers: []gwapiv1.HTTPHeaderMatch{{Name: internalapi.ModelNameHeaderKeyDefault, Value: "gpt-4o"}}}},
+						BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "openai-backend"}},
+					},
+				},
+			},
+		}
+	}
+	distinct, regex := egv1a1.HeaderMatchDistinct, egv1a1.HeaderMatchRegularExpression
+	quota := &aigv1a1.QuotaDefinition{
+		ConcurrencyLimits: []aigv1a1.QuotaConcurrencyLimit{
+			{MaxConcurrentRequests: 100},
+			{
+				ClientSelectors: []egv1a1.RateLimitSelectCondition{{
+					Headers: []egv1a1.HeaderMatch{
+						{Name: "X-Tenant", Type: &distinct},
+						{Name: "x-tier", Type: &regex, Value: ptr.To("^free-.*"), Invert: ptr.To(true)},
+					},
+				}},
+				JWTClaimSelectors:     []aigv1a1.QuotaJWTClaimSelector{{Name: "plan", Value: ptr.To("trial")}},
+				MaxConcurrentRequests: 2,
+				RetryAfterSeconds:     ptr.To[uint32](10),
+			},
+			{
+				CELSelectors:          []aigv1a1.QuotaCELSelector{{Expression: "invalid =="}},
+				MaxConcurrentRequests: 1,
+			},
+		},
+	}
+
+	ec := &filterapi.Config{}
+	c.injectQuotaPolicyConcurrencyLimits(ec, qp, newRoute("r1", []string{"llama-3"}, ""), "llama-3", quota)
+	// Another route sending other request models to the backend as the same model extends the same limits.
+	c.injectQuotaPolicyConcurrencyLimits(ec, qp, newRoute("r2", []string{"llama", "llama-latest"}, "llama-3"), "llama-3", quota)
+	// A route that does not send the model to the backend does not contribute.
+	c.injectQuotaPolicyConcurrencyLimits(ec, qp, newRoute("r3", []string{"mistral"}, ""), "llama-3", quota)
+
+	require.Equal(t, []filterapi.ConcurrencyLimit{
+		{
+			Name:                  "ns/qp/llama-3/0",
+			Models:                []string{"llama", "llama-3", "llama-latest"},
+			MaxConcurrentRequests: 100,
+			RetryAfterSeconds:     1,
+		},
+		{
+			Name:   "ns/qp/llama-3/1",
+			Models: []string{"llama", "llama-3", "llama-latest"},
+			Headers: []filterapi.ConcurrencyLimitHeaderMatch{
+				{Name: "x-tenant", Type: filterapi.ConcurrencyLimitHeaderMatchTypeDistinct},
+				{Name: "x-ai-eg-quota-claim-plan", Type: filterapi.ConcurrencyLimitHeaderMatchTypeExact, Value: "trial"},
+				{Name: "x-tier", Type: filterapi.ConcurrencyLimitHeaderMatchTypeRegularExpression, Value: "^free-.*", Invert: true},
+			},
+			MaxConcurrentRequests: 2,
+			RetryAfterSeconds:     10,
+		},
+	}, ec.ConcurrencyLimits)
+
+	t.Run("invalid regular expression", func(t *testing.T) {
+		ec := &filterapi.Config{}
+		c.injectQuotaPolicyConcurrencyLimits(ec, qp, newRoute("r1", []string{"llama-3"}, ""), "llama-3", &aigv1a1.QuotaDefinition{
+			ConcurrencyLimits: []aigv1a1.QuotaConcurrencyLimit{{
+				ClientSelectors:       []egv1a1.RateLimitSelectCondition{{Headers: []egv1a1.HeaderMatch{{Name: "x-tier", Type: &regex, Value: ptr.To("(")}}}},
+				MaxConcurrentRequests: 1,
+			}},
+		})
+		require.Empty(t, ec.ConcurrencyLimits)
+	})
+
+	t.Run("source CIDR selector", func(t *testing.T) {
+		ec := &filterapi.Config{}
+		c.injectQuotaPolicyConcurrencyLimits(ec, qp, newRoute("r1", []string{"llama-3"}, ""), "llama-3", &aigv1a1.QuotaDefinition{
+			ConcurrencyLimits: []aigv1a1.QuotaConcurrencyLimit{{
+				ClientSelectors:       []egv1a1.RateLimitSelectCondition{{SourceCIDR: &egv1a1.SourceMatch{Value: "10.0.0.0/8"}}},
+				MaxConcurrentRequests: 1,
+			}},
+		})
+		require.Empty(t, ec.ConcurrencyLimits)
+	})
+}
+
+func Test_quotaPolicyRequestModels(t *testing.T) {
+	qp := &aigv1a1.QuotaPolicy{Spec: aigv1a1.QuotaPolicySpec{
+		TargetRefs: []gwapiv1a2.LocalPolicyTargetReference{{Name: "backend"}},
+	}}
+	rule := func(override string, models ...string) aigv1b1.AIGatewayRouteRule {
+		var headers []gwapiv1.HTTPHeaderMatch
+		for _, m := range models {
+			headers = append(headers, gwapiv1.HTTPHeaderMatch{Name: internalapi.ModelNameHeaderKeyDefault, Value: m})
+		}
+		return aigv1b1.AIGatewayRouteRule{
+			Matches:     []aigv1b1.AIGatewayRouteRuleMatch{{Headers: headers}},
+			BackendRefs: []aigv1b1.AIGatewayRouteRuleBackendRef{{Name: "backend", ModelNameOverride: override}},
+		}
+	}
+	for _, tc := range []struct {
+		name  string
+		rules []aigv1b1.AIGatewayRouteRule
+		exp   []string
+	}{
+		{name: "direct", rules: []aigv1b1.AIGatewayRouteRule{rule("", "m")}, exp: []string{"m"}},
+		{name: "other model", rules: []aigv1b1.AIGatewayRouteRule{rule("", "other")}},
+		{name: "catch-all rule", rules: []aigv1b1.AIGatewayRouteRule{rule("")}, exp: []string{"m"}},
+		{name: "override", rules: []aigv1b1.AIGatewayRouteRule{rule("m", "b", "a"), rule("m", "a")}, exp: []string{"a", "b"}},
+		{name: "override to other model", rules: []aigv1b1.AIGatewayRouteRule{rule("other", "m")}},
+		{name: "catch-all override", rules: []aigv1b1.AIGatewayRouteRule{rule("m")}, exp: []string{"m"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			route := &aigv1b1.AIGatewayRoute{Spec: aigv1b1.AIGatewayRouteSpec{Rules: tc.rules}}
+			require.Equal(t, tc.exp, quotaPolicyRequestModels(qp, route, "m"))
+		})
+	}
+}
@@ -30,8 +30,9 @@ import (
 )
 
 const (
-	defaultMCPPath         = "/mcp"
-	mcpProxyBackendDummyIP = "192.0.2.42" // RFC 5737 TEST-NET-2, used as a dummy IP.
+	defaultMCPPath               = "/mcp"
+	defaultMCPOpenAPIDocumentKey = "openapi.yaml"
+	mcpProxyBackendDummyIP       = "192.0.2.42" // RFC 5737 TEST-NET-2, used as a dummy IP.
 )
 
 // MCPRouteController implements [reconcile.TypedReconciler].
@@ -270,16 +271,25 @@ func (c *MCPRouteController) deleteOrphanedPerBackendResources(ctx context.Conte
 func (c *MCPRouteController) newMainHTTPRoute(dst *gwapiv1.HTTPRoute, mcpRoute *aigv1b1.MCPRoute) error {
 	// This routes incoming MCP client requests to the MCP proxy in the ext proc.
 	servingPath := ptr.Deref(mcpRoute.Spec.Path, defaultMCPPath)
-	rules := []gwapiv1.HTTPRouteRule{{
-		Matches: []gwapiv1.HTTPRouteMatch{
-			{
-				Path: &gwapiv1.HTTPPathMatch{
-					Type:  ptr.To(gwapiv1.PathMatchExact),
-					Value: ptr.To(servingPath),
-				},
-				Headers: mcpRoute.Spec.Headers,
+	// The clients using the legacy HTTP+SSE transport open the SSE stream and post their messages to the
+	// sub-paths of the serving path.
+	legacyPathPrefix := strings.TrimSuffix(servingPath, "/")
+	var matches []gwapiv1.HTTPRouteMatch
+	for _, path := range []string{
+		servingPath,
+		legacyPathPrefix + internalapi.MCPLegacySSEPathSuffix,
+		legacyPathPrefix + internalapi.MCPLegacyMessagesPathSuffix,
+	} {
+		matches = append(matches, gwapiv1.HTTPRouteMatch{
+			Path: &gwapiv1.HTTPPathMatch{
+				Type:  ptr.To(gwapiv1.PathMatchExact),
+				Value: ptr.To(path),
 			},
-		},
+			Headers: mcpRoute.Spec.Headers,
+		})
+	}
+	rules := []gwapiv1.HTTPRouteRule{{
+		Matches: matches,
 		BackendRefs: []gwapiv1.HTTPBackendRef{
 			{
 				BackendRef: gwapiv1.BackendRef{
@@ -465,6 +475,28 @@ func (c *MCPRouteController) newPerBackendRefHTTPRoute(ctx context.Context, dst
 	return nil
 }
 
+// configMapEventHandler returns the MCPRoutes referencing the given ConfigMap as a tool pinning baseline or as an
+// OpenAPI document, so that the changes of the ConfigMap are propagated to the gateways.
+func (c *MCPRouteController) configMapEventHandler(ctx context.Context, obj client.Object) []reconcile.Request {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return nil
+	}
+	var mcpRoutes aigv1b1.MCPRouteList
+	if err := c.client.List(ctx, &mcpRoutes, client.MatchingFields{
+		k8sClientIndexConfigMapToReferencingMCPRoute: fmt.Sprintf("%s.%s", configMap.Name, configMap.Namespace),
+	}); err != nil {
+		c.logger.Error(err, "failed to list MCPRoutes for ConfigMap event", "configmap", configMap.Name, "namespace", configMap.Namespace)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(mcpRoutes.Items))
+	for i := range mcpRoutes.Items {
+		mcpRoute := &mcpRoutes.Items[i]
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mcpRoute)})
+	}
+	return requests
+}
+
 // syncGateways synchronizes the gateways referenced by the MCPRoute by sending events to the gateway controller.
 func (c *MCPRouteController) syncGateways(ctx context.Context, mcpRoute *aigv1b1.MCPRoute) error {
 	for _, p := range mcpRoute.Spec.ParentRefs {
@@ -648,15 +680,19 @@ func (c *MCPRouteController) mcpBackendRefToHTTPRouteRule(ctx context.Context, m
 	if inlineHeaderFilter != nil {
 		filters = append(filters, *inlineHeaderFilter)
 	}
-	filters = append(filters, gwapiv1.HTTPRouteFilter{
-		Type: gwapiv1.HTTPRouteFilterURLRewrite,
-		URLRewrite: &gwapiv1.HTTPURLRewriteFilter{
-			Path: &gwapiv1.HTTPPathModifier{
-				Type:            gwapiv1.FullPathHTTPPathModifier,
-				ReplaceFullPath: ptr.To(fullPathPtr),
+	// The MCP proxy sends the requests to the OpenAPI backends with the paths of the operations, and to the SSE
+	// backends with the paths of the SSE and message endpoints, which must be kept.
+	if ref.OpenAPI == nil && ptr.Deref(ref.Transport, aigv1b1.MCPBackendTransportStreamableHTTP) != aigv1b1.MCPBackendTransportSSE {
+		filters = append(filters, gwapiv1.HTTPRouteFilter{
+			Type: gwapiv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gwapiv1.HTTPURLRewriteFilter{
+				Path: &gwapiv1.HTTPPathModifier{
+					Type:            gwapiv1.FullPathHTTPPathModifier,
+					ReplaceFullPath: ptr.To(fullPathPtr),
+				},
 			},
-		},
-	})
+		})
+	}
 
 	return gwapiv1.HTTPRouteRule{
 		Matches: []gwapiv1.HTTPRouteMatch{
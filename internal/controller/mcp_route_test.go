@@ -203,7 +203,14 @@ func Test_newHTTPRoute_MCP_PathAndBackendsAndMetadata(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Len(t, httpRoute.Spec.Rules, 1)
-	require.Equal(t, "/custom/", *httpRoute.Spec.Rules[0].Matches[0].Path.Value)
+	// The serving path, and the SSE and message endpoints of the legacy HTTP+SSE transport.
+	require.Len(t, httpRoute.Spec.Rules[0].Matches, 3)
+	for i, path := range []string{"/custom/", "/custom/sse", "/custom/messages"} {
+		match := httpRoute.Spec.Rules[0].Matches[i]
+		require.Equal(t, gwapiv1.PathMatchExact, *match.Path.Type)
+		require.Equal(t, path, *match.Path.Value)
+		require.Equal(t, mcpRoute.Spec.Headers, match.Headers)
+	}
 	require.Len(t, httpRoute.Spec.Rules[0].BackendRefs, 1)
 	require.Equal(t, gwapiv1.ObjectName("ns-mcp-route-mcp-proxy"), httpRoute.Spec.Rules[0].BackendRefs[0].Name)
 
@@ -277,6 +284,64 @@ func TestMCPRouteController_syncGateway_notFound(t *testing.T) { // coverage for
 	require.Contains(t, err.Error(), "not found")
 }
 
+func TestMCPRouteController_configMapEventHandler(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexesForMCP(t)
+	eventCh := internaltesting.NewControllerEventChan[*gwapiv1.Gateway]()
+	c := NewMCPRouteController(fakeClient, fakekube.NewClientset(), logr.Discard(), eventCh.Ch)
+
+	for _, route := range []*aigv1b1.MCPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "default"},
+			Spec: aigv1b1.MCPRouteSpec{
+				ParentRefs: []gwapiv1.ParentReference{{Name: "gtw"}},
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"},
+					ToolPinning:            &aigv1b1.MCPToolPinning{BaselineConfigMapName: "github-tools"},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "openapi", Namespace: "default"},
+			Spec: aigv1b1.MCPRouteSpec{
+				ParentRefs: []gwapiv1.ParentReference{{Name: "gtw"}},
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "petstore"},
+					OpenAPI: &aigv1b1.MCPOpenAPIBackend{
+						Document: aigv1b1.MCPOpenAPIDocument{ConfigMapRef: &aigv1b1.MCPOpenAPIConfigMapRef{Name: "petstore-openapi"}},
+					},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-pinned", Namespace: "default"},
+			Spec: aigv1b1.MCPRouteSpec{
+				ParentRefs: []gwapiv1.ParentReference{{Name: "gtw"}},
+				BackendRefs: []aigv1b1.MCPRouteBackendRef{{
+					BackendObjectReference: gwapiv1.BackendObjectReference{Name: "github"},
+				}},
+			},
+		},
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), route))
+	}
+
+	requests := c.configMapEventHandler(t.Context(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-tools", Namespace: "default"},
+	})
+	require.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKey{Name: "pinned", Namespace: "default"}}}, requests)
+
+	requests = c.configMapEventHandler(t.Context(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "petstore-openapi", Namespace: "default"},
+	})
+	require.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKey{Name: "openapi", Namespace: "default"}}}, requests)
+
+	// ConfigMaps with the same name in other namespaces are not referenced.
+	require.Empty(t, c.configMapEventHandler(t.Context(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-tools", Namespace: "other"},
+	}))
+	require.Nil(t, c.configMapEventHandler(t.Context(), &corev1.Secret{}))
+}
+
 func TestMCPRouteController_mcpRuleWithAPIKeyBackendSecurity(t *testing.T) {
 	c := requireNewFakeClientWithIndexesForMCP(t)
 	eventCh := internaltesting.NewControllerEventChan[*gwapiv1.Gateway]()
@@ -429,6 +494,49 @@ func TestMCPRouteController_mcpRuleWithAPIKeyBackendSecurity(t *testing.T) {
 	}
 }
 
+func TestMCPRouteController_mcpRuleWithOpenAPIBackend(t *testing.T) {
+	c := requireNewFakeClientWithIndexesForMCP(t)
+	eventCh := internaltesting.NewControllerEventChan[*gwapiv1.Gateway]()
+	ctrlr := NewMCPRouteController(c, fakekube.NewClientset(), logr.Discard(), eventCh.Ch)
+
+	mcpRoute := &aigv1b1.MCPRoute{ObjectMeta: metav1.ObjectMeta{Name: "route-a", Namespace: "default"}}
+	httpRule, err := ctrlr.mcpBackendRefToHTTPRouteRule(t.Context(), mcpRoute, &aigv1b1.MCPRouteBackendRef{
+		BackendObjectReference: gwapiv1.BackendObjectReference{Name: "petstore"},
+		Path:                   ptr.To("/mcp"),
+		OpenAPI: &aigv1b1.MCPOpenAPIBackend{
+			Document: aigv1b1.MCPOpenAPIDocument{Inline: ptr.To("openapi: 3.0.0")},
+		},
+		SecurityPolicy: &aigv1b1.MCPBackendSecurityPolicy{
+			APIKey: &aigv1b1.MCPBackendAPIKey{Inline: ptr.To("inline-key"), Header: ptr.To("X-API-KEY")},
+		},
+	})
+	require.NoError(t, err)
+	// The paths of the operations are kept, and the API key is still injected.
+	require.Len(t, httpRule.Filters, 2)
+	require.Equal(t, gwapiv1.HTTPRouteFilterExtensionRef, httpRule.Filters[0].Type)
+	require.Equal(t, gwapiv1.HTTPRouteFilterRequestHeaderModifier, httpRule.Filters[1].Type)
+	for _, f := range httpRule.Filters {
+		require.Nil(t, f.URLRewrite)
+	}
+}
+
+func TestMCPRouteController_mcpRuleWithSSEBackend(t *testing.T) {
+	c := requireNewFakeClientWithIndexesForMCP(t)
+	eventCh := internaltesting.NewControllerEventChan[*gwapiv1.Gateway]()
+	ctrlr := NewMCPRouteController(c, fakekube.NewClientset(), logr.Discard(), eventCh.Ch)
+
+	mcpRoute := &aigv1b1.MCPRoute{ObjectMeta: metav1.ObjectMeta{Name: "route-a", Namespace: "default"}}
+	httpRule, err := ctrlr.mcpBackendRefToHTTPRouteRule(t.Context(), mcpRoute, &aigv1b1.MCPRouteBackendRef{
+		BackendObjectReference: gwapiv1.BackendObjectReference{Name: "legacy"},
+		Path:                   ptr.To("/sse"),
+		Transport:              ptr.To(aigv1b1.MCPBackendTransportSSE),
+	})
+	require.NoError(t, err)
+	// The paths of the SSE and message endpoints are kept.
+	require.Len(t, httpRule.Filters, 1)
+	require.Equal(t, gwapiv1.HTTPRouteFilterExtensionRef, httpRule.Filters[0].Type)
+}
+
 func TestMCPRouteController_staleCredentialSecretCleanup(t *testing.T) {
 	c := requireNewFakeClientWithIndexesForMCP(t)
 	eventCh := internaltesting.NewControllerEventChan[*gwapiv1.Gateway]()
@@ -36,6 +36,7 @@ import (
 
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 )
 
 const (
@@ -649,6 +650,11 @@ func (s *Server) insertRouterLevelAIGatewayExtProc(listener *listenerv3.Listener
 				ReceivingNamespaces: &extprocv3.MetadataOptions_MetadataNamespaces{
 					Untyped: []string{aigv1b1.AIGatewayFilterMetadataNamespace},
 				},
+				// The payload of the JWTs verified by a SecurityPolicy, used by the QuotaPolicy JWT claim selectors
+				// and the priority classes.
+				ForwardingNamespaces: &extprocv3.MetadataOptions_MetadataNamespaces{
+					Untyped: []string{quotaselector.JWTAuthnMetadataNamespace},
+				},
 			},
 			ProcessingMode: &extprocv3.ProcessingMode{
 				RequestHeaderMode:   extprocv3.ProcessingMode_SEND,
@@ -11,6 +11,7 @@ import (
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
 	httpconnectionmanagerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/stretchr/testify/require"
@@ -19,6 +20,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 )
 
 func TestInsertAIGatewayExtProcFilter(t *testing.T) {
@@ -403,6 +405,11 @@ func TestServer_insertRouterLevelAIGatewayExtProc_setsSchemeHeaderTransformation
 	require.NoError(t, err)
 	require.True(t, updatedHCM.GetSchemeHeaderTransformation().GetMatchUpstream(),
 		"SchemeHeaderTransformation.MatchUpstream must be true so :scheme matches upstream TLS transport")
+
+	// The payload of the verified JWTs is forwarded for the QuotaPolicy JWT claim selectors.
+	var extProc extprocv3.ExternalProcessor
+	require.NoError(t, updatedHCM.HttpFilters[0].GetTypedConfig().UnmarshalTo(&extProc))
+	require.Equal(t, []string{quotaselector.JWTAuthnMetadataNamespace}, extProc.GetMetadataOptions().GetForwardingNamespaces().GetUntyped())
 }
 
 func Test_findListenerRouteConfigs(t *testing.T) {
@@ -7,8 +7,8 @@ package extensionserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -516,8 +516,12 @@ func (s *Server) resolveRouteModelInfo(ctx context.Context, route *routev3.Route
 // modelInfo provides the backend→ModelNameOverride mapping used for filtering (a policy's
 // target and modelName must match a backend override) and for request-time descriptors.
 // If nil, all models are included.
+//
+// Policies whose client selectors cannot be translated are skipped as a whole and reported in the returned
+// error, while the limits of the other policies are still configured.
 func enableQuotaRateLimitOnRoute(_ logr.Logger, route *routev3.Route, policies []aigv1a1.QuotaPolicy, modelInfo *routeModelInfo) error {
 	var rateLimitActions []*routev3.RateLimit
+	var errs []error
 
 	// streamDoneActions collects the stream-done RateLimit entries built inline during
 	// the policy loop. They are appended to rateLimitActions at the end so all
@@ -533,6 +537,12 @@ func enableQuotaRateLimitOnRoute(_ logr.Logger, route *routev3.Route, policies [
 
 	for i := range policies {
 		policy := &policies[i]
+		// The QuotaPolicy controller rejects such a policy. Dropping only its invalid bucket rules
+		// would let the matching requests fall into the other buckets and silently widen the limits.
+		if err := validateQuotaPolicyClientSelectors(policy); err != nil {
+			errs = append(errs, fmt.Errorf("skipping QuotaPolicy %s/%s: %w", policy.Namespace, policy.Name, err))
+			continue
+		}
 		for _, pmq := range policy.Spec.PerModelQuotas {
 			if pmq.ModelName == nil {
 				continue
@@ -578,24 +588,38 @@ func enableQuotaRateLimitOnRoute(_ logr.Logger, route *routev3.Route, policies [
 					})
 				}
 			} else if len(pmq.Quota.BucketRules) > 0 {
-				bucketActions := buildBucketRuleLimitEntries(modelName, policy.Namespace, &pmq.Quota, policy.Spec.TargetRefs, backendModels)
+				bucketActions, err := buildBucketRuleLimitEntries(modelName, policy.Namespace, &pmq.Quota, policy.Spec.TargetRefs, backendModels)
+				if err != nil {
+					return err
+				}
 				rateLimitActions = append(rateLimitActions, bucketActions...)
 				// Bucket rules: one stream-done per unique rule/header structure.
 				// Stream-done actions read backend/model from dynamic metadata and
 				// hits_addend uses a single quota_cost key, so entries are identical
 				// regardless of target or model.
-				for rIdx, rule := range pmq.Quota.BucketRules {
-					headers := flattenAndSortClientSelectorHeaders(rule.ClientSelectors)
+				for rIdx := range pmq.Quota.BucketRules {
+					rule := &pmq.Quota.BucketRules[rIdx]
+					clientActions, err := buildClientSelectorStreamDoneActions(rIdx, rule)
+					if err != nil {
+						return err
+					}
+					headers := translator.QuotaRuleHeaderMatches(rule)
+					cidrs, _ := translator.QuotaRuleSourceCIDRMatches(rule)
 					var dupKey string
 					for mIdx, hdr := range headers {
 						dupKey += "|" + translator.BucketRuleDescriptorKey(rIdx, mIdx, hdr.Name, headerMatchKeyValue(hdr))
 					}
-					if len(headers) == 0 {
+					for i, cidr := range cidrs {
+						dupKey += "|" + cidr.DescriptorKey(rIdx, len(headers)+i)
+						if cidr.Distinct {
+							dupKey += "*"
+						}
+					}
+					if len(headers) == 0 && len(cidrs) == 0 {
 						dupKey += "|" + translator.BucketRuleDescriptorKey(rIdx, 0, "", "")
 					}
 					if !seenStreamDoneKeys[dupKey] {
 						seenStreamDoneKeys[dupKey] = true
-						clientActions := buildClientSelectorStreamDoneActions(rIdx, rule.ClientSelectors)
 						streamDoneActions = append(streamDoneActions, &routev3.RateLimit{
 							Actions:           append(baseDescriptorActions(), clientActions...),
 							HitsAddend:        quotaHitsAddend(),
@@ -630,7 +654,7 @@ func enableQuotaRateLimitOnRoute(_ logr.Logger, route *routev3.Route, policies [
 	rateLimitActions = append(rateLimitActions, streamDoneActions...)
 
 	if len(rateLimitActions) == 0 {
-		return nil
+		return errors.Join(errs...)
 	}
 
 	perRouteConfig := &ratelimitfilterv3.RateLimitPerRoute{
@@ -647,7 +671,7 @@ func enableQuotaRateLimitOnRoute(_ logr.Logger, route *routev3.Route, policies [
 		route.TypedPerFilterConfig = make(map[string]*anypb.Any)
 	}
 	route.TypedPerFilterConfig[quotaRateLimitFilterName] = perRouteAny
-	return nil
+	return errors.Join(errs...)
 }
 
 // baseDescriptorActions returns the two base actions that read ai_service_backend_name
@@ -725,14 +749,17 @@ func quotaHitsAddend() *routev3.RateLimit_HitsAddend {
 //
 // Action order matches the translator's service config tree:
 // backend_name (Level 0) → model_name_override (Level 1) → bucket_rule_key (Level 2)
-func buildBucketRuleLimitEntries(modelName, policyNamespace string, quota *aigv1a1.QuotaDefinition, targets []gwapiv1a2.LocalPolicyTargetReference, routeModelNames map[string][]string) []*routev3.RateLimit {
+func buildBucketRuleLimitEntries(modelName, policyNamespace string, quota *aigv1a1.QuotaDefinition, targets []gwapiv1a2.LocalPolicyTargetReference, routeModelNames map[string][]string) ([]*routev3.RateLimit, error) {
 	var entries []*routev3.RateLimit
 
 	for _, target := range targets {
 		resolvedModel := resolveModelName(string(target.Name), modelName, routeModelNames)
 
-		for rIdx, rule := range quota.BucketRules {
-			clientActions := buildClientSelectorActions(rIdx, rule.ClientSelectors)
+		for rIdx := range quota.BucketRules {
+			clientActions, err := buildClientSelectorActions(rIdx, &quota.BucketRules[rIdx])
+			if err != nil {
+				return nil, err
+			}
 			actions := requestTimeBaseActions(policyNamespace, string(target.Name), resolvedModel)
 			actions = append(actions, clientActions...)
 			entries = append(entries, &routev3.RateLimit{Actions: actions})
@@ -754,7 +781,7 @@ func buildBucketRuleLimitEntries(modelName, policyNamespace string, quota *aigv1
 		}
 	}
 
-	return entries
+	return entries, nil
 }
 
 // resolveModelName returns the model name to use for request-time descriptors.
@@ -795,45 +822,39 @@ func requestTimeBaseActions(namespace, backendName, modelName string) []*routev3
 	}
 }
 
-// buildClientSelectorActions converts ClientSelectors into rate limit actions.
-// Headers from all selectors are flattened, sorted by name, and each becomes a
-// separate action. The sort order matches the nested descriptor tree in the rate
-// limit service config. If no selectors are specified, a GenericKey action is used.
+// buildClientSelectorActions converts the client selectors of a bucket rule into rate limit actions.
+// Header matches from all selectors (including the JWT claim and CEL selectors) are flattened,
+// sorted by name, and each becomes a separate action, followed by the actions of each source
+// CIDR match. The order matches the nested descriptor tree in the rate limit service config.
+// If no selectors are specified, a GenericKey action is used.
+//
+// Returns an error if the rule is invalid, in which case the translator rejects the QuotaPolicy.
 func buildClientSelectorActions(
-	ruleIndex int, selectors []egv1a1.RateLimitSelectCondition,
-) []*routev3.RateLimit_Action {
-	headers := flattenAndSortClientSelectorHeaders(selectors)
-
-	if len(headers) == 0 {
-		key := translator.BucketRuleDescriptorKey(ruleIndex, 0, "", "")
-		return []*routev3.RateLimit_Action{
-			{
-				ActionSpecifier: &routev3.RateLimit_Action_GenericKey_{
-					GenericKey: &routev3.RateLimit_Action_GenericKey{
-						DescriptorKey:   key,
-						DescriptorValue: key,
-					},
-				},
-			},
-		}
-	}
-
-	var actions []*routev3.RateLimit_Action
-	for mIdx, header := range headers {
-		actions = append(actions, buildHeaderMatchAction(ruleIndex, mIdx, header))
-	}
-	return actions
+	ruleIndex int, rule *aigv1a1.QuotaRule,
+) ([]*routev3.RateLimit_Action, error) {
+	return buildClientSelectorActionsWith(ruleIndex, rule, buildHeaderMatchAction)
 }
 
 // buildClientSelectorStreamDoneActions is like buildClientSelectorActions but
 // always uses ExpectMatch=true on HeaderValueMatch actions. Distinct headers fall
 // back to GenericKey because per-value bucketing is not applicable at stream-done time.
 func buildClientSelectorStreamDoneActions(
-	ruleIndex int, selectors []egv1a1.RateLimitSelectCondition,
-) []*routev3.RateLimit_Action {
-	headers := flattenAndSortClientSelectorHeaders(selectors)
+	ruleIndex int, rule *aigv1a1.QuotaRule,
+) ([]*routev3.RateLimit_Action, error) {
+	return buildClientSelectorActionsWith(ruleIndex, rule, buildStreamDoneHeaderMatchAction)
+}
 
-	if len(headers) == 0 {
+func buildClientSelectorActionsWith(
+	ruleIndex int, rule *aigv1a1.QuotaRule,
+	headerAction func(ruleIndex, matchIndex int, header egv1a1.HeaderMatch) *routev3.RateLimit_Action,
+) ([]*routev3.RateLimit_Action, error) {
+	headers := translator.QuotaRuleHeaderMatches(rule)
+	cidrs, err := translator.QuotaRuleSourceCIDRMatches(rule)
+	if err != nil {
+		return nil, fmt.Errorf("bucket rule %d: %w", ruleIndex, err)
+	}
+
+	if len(headers) == 0 && len(cidrs) == 0 {
 		key := translator.BucketRuleDescriptorKey(ruleIndex, 0, "", "")
 		return []*routev3.RateLimit_Action{
 			{
@@ -844,28 +865,70 @@ func buildClientSelectorStreamDoneActions(
 					},
 				},
 			},
-		}
+		}, nil
 	}
 
 	var actions []*routev3.RateLimit_Action
 	for mIdx, header := range headers {
-		actions = append(actions, buildStreamDoneHeaderMatchAction(ruleIndex, mIdx, header))
+		actions = append(actions, headerAction(ruleIndex, mIdx, header))
 	}
-	return actions
+	for i, cidr := range cidrs {
+		actions = append(actions, buildSourceCIDRActions(ruleIndex, len(headers)+i, cidr)...)
+	}
+	return actions, nil
+}
+
+// validateQuotaPolicyClientSelectors returns an error if the client selectors of a bucket rule of the
+// policy cannot be translated into rate limit actions.
+func validateQuotaPolicyClientSelectors(policy *aigv1a1.QuotaPolicy) error {
+	for i := range policy.Spec.PerModelQuotas {
+		rules := policy.Spec.PerModelQuotas[i].Quota.BucketRules
+		for rIdx := range rules {
+			if _, err := translator.QuotaRuleSourceCIDRMatches(&rules[rIdx]); err != nil {
+				return fmt.Errorf("perModelQuotas[%d] bucket rule %d: %w", i, rIdx, err)
+			}
+		}
+	}
+	return nil
 }
 
-// flattenAndSortClientSelectorHeaders collects all HeaderMatch entries from all
-// ClientSelectors and sorts them by header Name for deterministic action ordering
-// that matches the nested descriptor tree in the rate limit service config.
-func flattenAndSortClientSelectorHeaders(selectors []egv1a1.RateLimitSelectCondition) []egv1a1.HeaderMatch {
-	var headers []egv1a1.HeaderMatch
-	for _, sel := range selectors {
-		headers = append(headers, sel.Headers...)
-	}
-	sort.Slice(headers, func(i, j int) bool {
-		return headers[i].Name < headers[j].Name
-	})
-	return headers
+// buildSourceCIDRActions converts a source CIDR match into rate limit actions matching
+// the descriptor chain built by the translator: a rule-specific GenericKey, the client
+// address masked with the CIDR prefix length and, for Distinct matches, the full client address.
+// The masked address is only sent for the address family of the CIDR; clients of the other
+// family send their full address, which never matches the CIDR descriptor value.
+func buildSourceCIDRActions(ruleIndex, matchIndex int, cidr translator.SourceCIDRMatch) []*routev3.RateLimit_Action {
+	key := cidr.DescriptorKey(ruleIndex, matchIndex)
+	masked := &routev3.RateLimit_Action_MaskedRemoteAddress{}
+	if cidr.Prefix.Addr().Is4() {
+		masked.V4PrefixMaskLen = wrapperspb.UInt32(uint32(cidr.Prefix.Bits())) //nolint:gosec
+	} else {
+		masked.V6PrefixMaskLen = wrapperspb.UInt32(uint32(cidr.Prefix.Bits())) //nolint:gosec
+	}
+	actions := []*routev3.RateLimit_Action{
+		{
+			ActionSpecifier: &routev3.RateLimit_Action_GenericKey_{
+				GenericKey: &routev3.RateLimit_Action_GenericKey{
+					DescriptorKey:   key,
+					DescriptorValue: key,
+				},
+			},
+		},
+		{
+			ActionSpecifier: &routev3.RateLimit_Action_MaskedRemoteAddress_{MaskedRemoteAddress: masked},
+		},
+	}
+	if cidr.Distinct {
+		actions = append(actions, &routev3.RateLimit_Action{
+			ActionSpecifier: &routev3.RateLimit_Action_MaskedRemoteAddress_{
+				MaskedRemoteAddress: &routev3.RateLimit_Action_MaskedRemoteAddress{
+					V4PrefixMaskLen: wrapperspb.UInt32(32),
+					V6PrefixMaskLen: wrapperspb.UInt32(128),
+				},
+			},
+		})
+	}
+	return actions
 }
 
 // buildStreamDoneHeaderMatchAction is like buildHeaderMatchAction but always uses
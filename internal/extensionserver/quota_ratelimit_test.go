@@ -31,6 +31,7 @@ import (
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 	"github.com/envoyproxy/ai-gateway/internal/ratelimit/translator"
 )
 
@@ -414,6 +415,53 @@ func TestEnableQuotaRateLimitOnRoute_DescriptorChain(t *testing.T) {
 	require.NotNil(t, streamDone.HitsAddend)
 }
 
+func TestEnableQuotaRateLimitOnRoute_InvalidClientSelectors(t *testing.T) {
+	policy := func(name string, rule aigv1a1.QuotaRule) aigv1a1.QuotaPolicy {
+		return aigv1a1.QuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: aigv1a1.QuotaPolicySpec{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReference{{Name: "test-backend"}},
+				PerModelQuotas: []aigv1a1.PerModelQuota{
+					{
+						ModelName: ptr.To("gpt-4"),
+						Quota: aigv1a1.QuotaDefinition{
+							BucketRules:   []aigv1a1.QuotaRule{rule},
+							DefaultBucket: aigv1a1.QuotaValue{Limit: 100, Duration: "1m"},
+						},
+					},
+				},
+			},
+		}
+	}
+	valid := policy("valid", aigv1a1.QuotaRule{
+		ClientSelectors: []egv1a1.RateLimitSelectCondition{{SourceCIDR: &egv1a1.SourceMatch{Value: "10.0.0.0/8"}}},
+	})
+	invalid := policy("invalid", aigv1a1.QuotaRule{
+		ClientSelectors: []egv1a1.RateLimitSelectCondition{{SourceCIDR: &egv1a1.SourceMatch{Value: "10.0.0.0/8", Invert: ptr.To(true)}}},
+	})
+
+	t.Run("only invalid policies", func(t *testing.T) {
+		route := &routev3.Route{Name: "test-route"}
+		err := enableQuotaRateLimitOnRoute(logr.Discard(), route, []aigv1a1.QuotaPolicy{invalid}, nil)
+		require.EqualError(t, err, "skipping QuotaPolicy default/invalid: perModelQuotas[0] bucket rule 0: inverted source CIDR matches are not supported")
+		require.Empty(t, route.TypedPerFilterConfig)
+	})
+
+	t.Run("the other policies are still configured", func(t *testing.T) {
+		expected := &routev3.Route{Name: "test-route"}
+		require.NoError(t, enableQuotaRateLimitOnRoute(logr.Discard(), expected, []aigv1a1.QuotaPolicy{valid}, nil))
+
+		// The invalid policy is skipped as a whole rather than only its invalid bucket rule, which would
+		// leave its default bucket limiting the requests the rule was meant to select.
+		route := &routev3.Route{Name: "test-route"}
+		err := enableQuotaRateLimitOnRoute(logr.Discard(), route, []aigv1a1.QuotaPolicy{invalid, valid}, nil)
+		require.ErrorContains(t, err, "skipping QuotaPolicy default/invalid")
+		require.NotContains(t, err.Error(), "default/valid")
+		require.Equal(t, expected.TypedPerFilterConfig[quotaRateLimitFilterName].GetValue(),
+			route.TypedPerFilterConfig[quotaRateLimitFilterName].GetValue())
+	})
+}
+
 func TestQuotaHitsAddend(t *testing.T) {
 	ha := quotaHitsAddend()
 	require.NotNil(t, ha)
@@ -1217,7 +1265,8 @@ func TestBuildBucketRuleLimitEntries(t *testing.T) {
 
 	t.Run("no bucket rules returns nil", func(t *testing.T) {
 		quota := &aigv1a1.QuotaDefinition{}
-		entries := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		entries, err := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		require.NoError(t, err)
 		require.Nil(t, entries)
 	})
 
@@ -1229,7 +1278,8 @@ func TestBuildBucketRuleLimitEntries(t *testing.T) {
 				},
 			},
 		}
-		entries := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		entries, err := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		require.NoError(t, err)
 		require.Len(t, entries, 1) // 1 request-time only (stream-done added by enableQuotaRateLimitOnRoute)
 		// Request-time entry: backend_name + model_name + GenericKey = 3 actions
 		require.Len(t, entries[0].Actions, 3)
@@ -1246,7 +1296,8 @@ func TestBuildBucketRuleLimitEntries(t *testing.T) {
 			},
 			DefaultBucket: aigv1a1.QuotaValue{Limit: 10, Duration: "1m"},
 		}
-		entries := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		entries, err := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		require.NoError(t, err)
 		require.Len(t, entries, 2) // 1 bucket req-time + 1 default req-time (no stream-done)
 
 		// Default bucket request-time entry (index 1)
@@ -1265,7 +1316,8 @@ func TestBuildBucketRuleLimitEntries(t *testing.T) {
 			},
 			DefaultBucket: aigv1a1.QuotaValue{Limit: 0},
 		}
-		entries := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		entries, err := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		require.NoError(t, err)
 		require.Len(t, entries, 1) // 1 request-time only (no default, no stream-done)
 	})
 
@@ -1284,7 +1336,8 @@ func TestBuildBucketRuleLimitEntries(t *testing.T) {
 				},
 			},
 		}
-		entries := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		entries, err := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		require.NoError(t, err)
 		require.Len(t, entries, 1) // 1 request-time only (stream-done added by enableQuotaRateLimitOnRoute)
 		// Request-time entry: backend_name + model_name + 1 header match = 3 actions
 		require.Len(t, entries[0].Actions, 3)
@@ -1298,7 +1351,8 @@ func TestBuildBucketRuleLimitEntries(t *testing.T) {
 				{Quota: aigv1a1.QuotaValue{Limit: 100, Duration: "1m"}},
 			},
 		}
-		entries := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		entries, err := buildBucketRuleLimitEntries("gpt-4", "default", quota, oneTarget, nil)
+		require.NoError(t, err)
 		require.Len(t, entries, 1) // request-time only (stream-done added by enableQuotaRateLimitOnRoute)
 
 		// Request-time entry: GenericKey actions for backend_name and model_name.
@@ -1438,7 +1492,8 @@ func TestEnableQuotaRateLimitOnRoute_MultiplePerModelQuotas(t *testing.T) {
 
 func TestBuildClientSelectorActions(t *testing.T) {
 	t.Run("empty selectors returns GenericKey", func(t *testing.T) {
-		actions := buildClientSelectorActions(0, nil)
+		actions, err := buildClientSelectorActions(0, &aigv1a1.QuotaRule{})
+		require.NoError(t, err)
 		require.Len(t, actions, 1)
 		gk := actions[0].GetGenericKey()
 		require.NotNil(t, gk)
@@ -1449,7 +1504,8 @@ func TestBuildClientSelectorActions(t *testing.T) {
 		selectors := []egv1a1.RateLimitSelectCondition{
 			{}, // no headers
 		}
-		actions := buildClientSelectorActions(0, selectors)
+		actions, err := buildClientSelectorActions(0, &aigv1a1.QuotaRule{ClientSelectors: selectors})
+		require.NoError(t, err)
 		require.Len(t, actions, 1)
 		gk := actions[0].GetGenericKey()
 		require.NotNil(t, gk)
@@ -1463,7 +1519,8 @@ func TestBuildClientSelectorActions(t *testing.T) {
 				},
 			},
 		}
-		actions := buildClientSelectorActions(0, selectors)
+		actions, err := buildClientSelectorActions(0, &aigv1a1.QuotaRule{ClientSelectors: selectors})
+		require.NoError(t, err)
 		require.Len(t, actions, 1)
 		hvm := actions[0].GetHeaderValueMatch()
 		require.NotNil(t, hvm)
@@ -1478,7 +1535,8 @@ func TestBuildClientSelectorActions(t *testing.T) {
 				},
 			},
 		}
-		actions := buildClientSelectorActions(0, selectors)
+		actions, err := buildClientSelectorActions(0, &aigv1a1.QuotaRule{ClientSelectors: selectors})
+		require.NoError(t, err)
 		require.Len(t, actions, 1)
 		rh := actions[0].GetRequestHeaders()
 		require.NotNil(t, rh)
@@ -1500,7 +1558,8 @@ func TestBuildClientSelectorActions(t *testing.T) {
 				},
 			},
 		}
-		actions := buildClientSelectorActions(1, selectors)
+		actions, err := buildClientSelectorActions(1, &aigv1a1.QuotaRule{ClientSelectors: selectors})
+		require.NoError(t, err)
 		require.Len(t, actions, 3) // 3 headers total
 
 		// h1: HeaderValueMatch
@@ -1515,6 +1574,82 @@ func TestBuildClientSelectorActions(t *testing.T) {
 		require.NotNil(t, actions[2].GetRequestHeaders())
 		require.Equal(t, translator.BucketRuleDescriptorKey(1, 2, "h3", ""), actions[2].GetRequestHeaders().DescriptorKey)
 	})
+
+	t.Run("jwt claim and cel selectors match internal headers", func(t *testing.T) {
+		rule := &aigv1a1.QuotaRule{
+			JWTClaimSelectors: []aigv1a1.QuotaJWTClaimSelector{
+				{Name: "sub", Type: ptr.To(egv1a1.HeaderMatchDistinct)},
+			},
+			CELSelectors: []aigv1a1.QuotaCELSelector{
+				{Expression: `request.model == "gpt-4o"`},
+			},
+		}
+		celHeader := quotaselector.CELHeaderName(`request.model == "gpt-4o"`)
+		actions, err := buildClientSelectorActions(0, rule)
+		require.NoError(t, err)
+		require.Len(t, actions, 2)
+
+		// CEL header sorts first: "x-ai-eg-quota-cel-" < "x-ai-eg-quota-claim-".
+		hvm := actions[0].GetHeaderValueMatch()
+		require.NotNil(t, hvm)
+		require.Equal(t, translator.BucketRuleDescriptorKey(0, 0, celHeader, "true"), hvm.DescriptorKey)
+		require.Equal(t, celHeader, hvm.Headers[0].Name)
+		require.Equal(t, "true", hvm.Headers[0].GetStringMatch().GetExact())
+
+		rh := actions[1].GetRequestHeaders()
+		require.NotNil(t, rh)
+		require.Equal(t, "x-ai-eg-quota-claim-sub", rh.HeaderName)
+	})
+
+	t.Run("source cidr", func(t *testing.T) {
+		rule := &aigv1a1.QuotaRule{
+			ClientSelectors: []egv1a1.RateLimitSelectCondition{
+				{
+					Headers:    []egv1a1.HeaderMatch{{Name: "h1", Value: ptr.To("v1")}},
+					SourceCIDR: &egv1a1.SourceMatch{Value: "10.1.2.3/16"},
+				},
+				{SourceCIDR: &egv1a1.SourceMatch{Value: "2001:db8::/32", Type: ptr.To(egv1a1.SourceMatchDistinct)}},
+			},
+		}
+		actions, err := buildClientSelectorActions(2, rule)
+		require.NoError(t, err)
+		require.Len(t, actions, 6)
+
+		require.NotNil(t, actions[0].GetHeaderValueMatch())
+
+		// The IPv4 CIDR sorts first.
+		gk := actions[1].GetGenericKey()
+		require.NotNil(t, gk)
+		require.Equal(t, translator.BucketRuleDescriptorKey(2, 1, translator.MaskedRemoteAddressDescriptorKey, "10.1.0.0/16"), gk.DescriptorKey)
+		mra := actions[2].GetMaskedRemoteAddress()
+		require.NotNil(t, mra)
+		require.Equal(t, uint32(16), mra.V4PrefixMaskLen.GetValue())
+		require.Nil(t, mra.V6PrefixMaskLen)
+
+		gk = actions[3].GetGenericKey()
+		require.NotNil(t, gk)
+		require.Equal(t, translator.BucketRuleDescriptorKey(2, 2, translator.MaskedRemoteAddressDescriptorKey, "2001:db8::/32"), gk.DescriptorKey)
+		mra = actions[4].GetMaskedRemoteAddress()
+		require.NotNil(t, mra)
+		require.Nil(t, mra.V4PrefixMaskLen)
+		require.Equal(t, uint32(32), mra.V6PrefixMaskLen.GetValue())
+		mra = actions[5].GetMaskedRemoteAddress()
+		require.NotNil(t, mra)
+		require.Equal(t, uint32(32), mra.V4PrefixMaskLen.GetValue())
+		require.Equal(t, uint32(128), mra.V6PrefixMaskLen.GetValue())
+	})
+
+	t.Run("invalid source cidr returns an error", func(t *testing.T) {
+		rule := &aigv1a1.QuotaRule{
+			ClientSelectors: []egv1a1.RateLimitSelectCondition{
+				{SourceCIDR: &egv1a1.SourceMatch{Value: "10.0.0.0/8", Invert: ptr.To(true)}},
+			},
+		}
+		_, err := buildClientSelectorActions(0, rule)
+		require.EqualError(t, err, "bucket rule 0: inverted source CIDR matches are not supported")
+		_, err = buildClientSelectorStreamDoneActions(0, rule)
+		require.EqualError(t, err, "bucket rule 0: inverted source CIDR matches are not supported")
+	})
 }
 
 func TestBuildHeaderMatchAction(t *testing.T) {
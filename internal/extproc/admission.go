@@ -0,0 +1,104 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+
+	"github.com/envoyproxy/ai-gateway/internal/admission"
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/metrics"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
+	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
+)
+
+// admissionSpanEvent is the name of the span event recording the admission decision.
+const admissionSpanEvent = "ai_gateway.admission"
+
+// requestPriorityClass returns the priority class of the request, taken from the claim of the JWT verified by
+// Envoy, the header and the default, in this order. Values that are not a valid priority class are ignored.
+func requestPriorityClass(cfg *filterapi.PriorityClassConfig, requestHeaders map[string]string, claims map[string]any) filterapi.PriorityClass {
+	if cfg == nil {
+		return filterapi.PriorityClassNormal
+	}
+	if cfg.JWTClaim != "" {
+		if value, ok := quotaselector.ClaimValue(claims, cfg.JWTClaim); ok {
+			if p, ok := parsePriorityClass(value); ok {
+				return p
+			}
+		}
+	}
+	if cfg.Header != "" {
+		if p, ok := parsePriorityClass(requestHeaders[cfg.Header]); ok {
+			return p
+		}
+	}
+	return cmp.Or(cfg.Default, filterapi.PriorityClassNormal)
+}
+
+// parsePriorityClass parses the priority class case-insensitively.
+func parsePriorityClass(value string) (filterapi.PriorityClass, bool) {
+	for _, p := range []filterapi.PriorityClass{filterapi.PriorityClassHigh, filterapi.PriorityClassNormal, filterapi.PriorityClassLow} {
+		if strings.EqualFold(strings.TrimSpace(value), string(p)) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// recordAdmission records the admission decision in the metrics, if configured, and in the span, if it
+// supports events.
+func recordAdmission(ctx context.Context, m metrics.AdmissionMetrics, span any, cfg *filterapi.BackendAdmission,
+	priority filterapi.PriorityClass, res admission.Result,
+) {
+	queued := res.Decision != admission.DecisionAdmitted && res.Wait > 0
+	if m != nil {
+		m.RecordDecision(ctx, cfg.Key, string(priority), string(res.Decision), queued, res.Wait)
+	}
+	if recorder, ok := span.(tracingapi.SpanEventRecorder); ok {
+		recorder.RecordEvent(admissionSpanEvent,
+			attribute.String("ai_gateway.backend.name", cfg.Key),
+			attribute.String("ai_gateway.priority_class", string(priority)),
+			attribute.String("ai_gateway.admission.decision", string(res.Decision)),
+			attribute.Float64("ai_gateway.admission.pressure", res.Pressure),
+			attribute.Int64("ai_gateway.admission.queue_wait_ms", res.Wait.Milliseconds()),
+		)
+	}
+}
+
+// createAdmissionShedResponse creates the immediate response for a request shed by the admission control.
+func createAdmissionShedResponse(cfg *filterapi.BackendAdmission, res admission.Result) *extprocv3.ProcessingResponse {
+	const statusCode = 503
+	message := "too many requests queued for the backend"
+	if res.Decision == admission.DecisionTimeout {
+		message = "timed out waiting for admission to the backend"
+	}
+	body := formatUserFacingErrorJSON("ServiceUnavailable", statusCode, message)
+	retryAfter := max(int(math.Ceil(cfg.QueueTimeout.Seconds())), 1)
+	headerMutation := &extprocv3.HeaderMutation{}
+	setHeader(headerMutation, "content-type", "application/json")
+	setHeader(headerMutation, "content-length", strconv.Itoa(len(body)))
+	setHeader(headerMutation, "retry-after", strconv.Itoa(retryAfter))
+	return &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extprocv3.ImmediateResponse{
+				Status:     &typev3.HttpStatus{Code: typev3.StatusCode_ServiceUnavailable},
+				Headers:    headerMutation,
+				Body:       body,
+				GrpcStatus: &extprocv3.GrpcStatus{Status: uint32(codes.Unavailable)},
+			},
+		},
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/envoyproxy/ai-gateway/internal/admission"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+	"github.com/envoyproxy/ai-gateway/internal/testing/testotel"
+)
+
+func Test_requestPriorityClass(t *testing.T) {
+	claims := map[string]any{
+		"tier": map[string]any{"priority": "low"},
+		"bad":  "urgent",
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"tier": map[string]any{"priority": "high"},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		cfg     *filterapi.PriorityClassConfig
+		headers map[string]string
+		claims  map[string]any
+		exp     filterapi.PriorityClass
+	}{
+		{name: "not configured", exp: filterapi.PriorityClassNormal},
+		{name: "empty default", cfg: &filterapi.PriorityClassConfig{}, exp: filterapi.PriorityClassNormal},
+		{name: "default", cfg: &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassHigh}, exp: filterapi.PriorityClassHigh},
+		{
+			name:    "header",
+			cfg:     &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassNormal, Header: "x-priority"},
+			headers: map[string]string{"x-priority": " HIGH "},
+			exp:     filterapi.PriorityClassHigh,
+		},
+		{
+			name:    "invalid header",
+			cfg:     &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassLow, Header: "x-priority"},
+			headers: map[string]string{"x-priority": "urgent"},
+			exp:     filterapi.PriorityClassLow,
+		},
+		{
+			name:    "claim takes precedence over header",
+			cfg:     &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassHigh, Header: "x-priority", JWTClaim: "tier.priority"},
+			headers: map[string]string{"x-priority": "high"},
+			claims:  claims,
+			exp:     filterapi.PriorityClassLow,
+		},
+		{
+			name:    "invalid claim falls back to header",
+			cfg:     &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassLow, Header: "x-priority", JWTClaim: "bad"},
+			headers: map[string]string{"x-priority": "normal"},
+			claims:  claims,
+			exp:     filterapi.PriorityClassNormal,
+		},
+		{
+			// The claims of a token that Envoy did not verify are not used.
+			name:    "unverified token falls back to default",
+			cfg:     &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassLow, JWTClaim: "tier.priority"},
+			headers: map[string]string{"authorization": "Bearer " + forged},
+			exp:     filterapi.PriorityClassLow,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.exp, requestPriorityClass(tc.cfg, tc.headers, tc.claims))
+		})
+	}
+}
+
+type mockAdmissionMetrics struct {
+	backend, priorityClass, decision string
+	queued                           bool
+	wait                             time.Duration
+}
+
+// RecordDecision implements [metrics.AdmissionMetrics.RecordDecision].
+func (m *mockAdmissionMetrics) RecordDecision(_ context.Context, backend, priorityClass, decision string, queued bool, wait time.Duration) {
+	m.backend, m.priorityClass, m.decision, m.queued, m.wait = backend, priorityClass, decision, queued, wait
+}
+
+func Test_recordAdmission(t *testing.T) {
+	cfg := &filterapi.BackendAdmission{Key: "ns/azure"}
+	res := admission.Result{Decision: admission.DecisionQueued, Wait: 1500 * time.Millisecond, Pressure: 1.25}
+
+	m := &mockAdmissionMetrics{}
+	span := &testotel.MockSpan{}
+	recordAdmission(t.Context(), m, span, cfg, filterapi.PriorityClassLow, res)
+	require.Equal(t, &mockAdmissionMetrics{
+		backend: "ns/azure", priorityClass: "Low", decision: "queued", queued: true, wait: 1500 * time.Millisecond,
+	}, m)
+	require.Equal(t, []testotel.MockSpanEvent{{Name: admissionSpanEvent, Attrs: []attribute.KeyValue{
+		attribute.String("ai_gateway.backend.name", "ns/azure"),
+		attribute.String("ai_gateway.priority_class", "Low"),
+		attribute.String("ai_gateway.admission.decision", "queued"),
+		attribute.Float64("ai_gateway.admission.pressure", 1.25),
+		attribute.Int64("ai_gateway.admission.queue_wait_ms", 1500),
+	}}}, span.Events)
+
+	t.Run("shed without waiting is not queued", func(t *testing.T) {
+		m := &mockAdmissionMetrics{}
+		recordAdmission(t.Context(), m, nil, cfg, filterapi.PriorityClassNormal, admission.Result{Decision: admission.DecisionShed})
+		require.Equal(t, "shed", m.decision)
+		require.False(t, m.queued)
+	})
+
+	t.Run("nil metrics and span", func(t *testing.T) {
+		recordAdmission(t.Context(), nil, nil, cfg, filterapi.PriorityClassNormal, res)
+	})
+}
+
+func Test_createAdmissionShedResponse(t *testing.T) {
+	for _, tc := range []struct {
+		decision      admission.Decision
+		queueTimeout  time.Duration
+		expMessage    string
+		expRetryAfter string
+	}{
+		{decision: admission.DecisionShed, queueTimeout: 0, expMessage: "too many requests queued for the backend", expRetryAfter: "1"},
+		{decision: admission.DecisionTimeout, queueTimeout: 2500 * time.Millisecond, expMessage: "timed out waiting for admission to the backend", expRetryAfter: "3"},
+	} {
+		t.Run(string(tc.decision), func(t *testing.T) {
+			resp := createAdmissionShedResponse(&filterapi.BackendAdmission{QueueTimeout: tc.queueTimeout}, admission.Result{Decision: tc.decision})
+			ir := resp.GetImmediateResponse()
+			require.NotNil(t, ir)
+			require.Equal(t, typev3.StatusCode_ServiceUnavailable, ir.Status.Code)
+			require.JSONEq(t, `{"type":"error","error":{"type":"ServiceUnavailable","code":"503","message":"`+tc.expMessage+`"}}`, string(ir.Body))
+			headers := map[string]string{}
+			for _, h := range ir.Headers.SetHeaders {
+				headers[h.Header.Key] = string(h.Header.RawValue)
+			}
+			require.Equal(t, tc.expRetryAfter, headers["retry-after"])
+			require.Equal(t, "application/json", headers["content-type"])
+		})
+	}
+}
+
+func Test_chatCompletionProcessorUpstreamFilter_Admission(t *testing.T) {
+	origController, origMetrics := AdmissionController, AdmissionMetrics
+	t.Cleanup(func() { AdmissionController, AdmissionMetrics = origController, origMetrics })
+	AdmissionController = admission.NewController()
+	m := &mockAdmissionMetrics{}
+	AdmissionMetrics = m
+
+	cfg := &filterapi.BackendAdmission{
+		Key:                       "ns/azure",
+		Window:                    time.Minute,
+		ThrottledPercentThreshold: 10,
+		MaxQueueSize:              0,
+		QueueTimeout:              time.Second,
+	}
+	priorityClass := &filterapi.PriorityClassConfig{Default: filterapi.PriorityClassNormal, Header: "x-priority"}
+
+	newProcessor := func(t *testing.T, priority string) (*chatCompletionProcessorUpstreamFilter, *mockMetrics, *testotel.MockSpan) {
+		someBody := bodyFromModel(t, "some-model", false, nil)
+		var body openai.ChatCompletionRequest
+		require.NoError(t, json.Unmarshal(someBody, &body))
+		mm := &mockMetrics{}
+		span := &testotel.MockSpan{}
+		return &chatCompletionProcessorUpstreamFilter{
+			parent: &chatCompletionProcessorRouterFilter{
+				config:                 &filterapi.RuntimeConfig{},
+				logger:                 slog.Default(),
+				originalRequestBodyRaw: someBody,
+				originalRequestBody:    &body,
+				originalModel:          "some-model",
+				span:                   span,
+			},
+			requestHeaders: map[string]string{":path": "/foo", internalapi.ModelNameHeaderKeyDefault: "some-model", "x-priority": priority},
+			metrics:        mm,
+			translator:     &mockTranslator{t: t, expRequestBody: &body, expHeaders: map[string]string{":status": "429"}},
+			logger:         slog.Default(),
+			admission:      cfg,
+			priorityClass:  priorityClass,
+		}, mm, span
+	}
+
+	// The backend keeps responding with 429 to admitted requests.
+	for range 10 {
+		p, mm, span := newProcessor(t, "low")
+		resp, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		require.NotNil(t, resp.GetRequestHeaders())
+		mm.RequireRequestNotCompleted(t)
+		require.Len(t, span.Events, 1)
+		require.Equal(t, "admitted", m.decision)
+
+		_, err = p.ProcessResponseHeaders(t.Context(), &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":status", Value: "429"}}})
+		require.NoError(t, err)
+	}
+	require.InDelta(t, 10.0, AdmissionController.Pressure(cfg), 1e-9)
+
+	t.Run("low priority is shed", func(t *testing.T) {
+		p, mm, span := newProcessor(t, "low")
+		resp, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		ir := resp.Response.(*extprocv3.ProcessingResponse_ImmediateResponse).ImmediateResponse
+		require.Equal(t, typev3.StatusCode_ServiceUnavailable, ir.Status.Code)
+		mm.RequireRequestFailure(t)
+		require.Equal(t, &mockAdmissionMetrics{backend: "ns/azure", priorityClass: "Low", decision: "shed"}, m)
+		require.Len(t, span.Events, 1)
+		require.Equal(t, admissionSpanEvent, span.Events[0].Name)
+	})
+
+	t.Run("high priority is admitted", func(t *testing.T) {
+		p, mm, _ := newProcessor(t, "high")
+		resp, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		require.NotNil(t, resp.GetRequestHeaders())
+		mm.RequireRequestNotCompleted(t)
+		require.Equal(t, "admitted", m.decision)
+		require.Equal(t, "High", m.priorityClass)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		queueing := *cfg
+		queueing.MaxQueueSize = 1
+		p, mm, _ := newProcessor(t, "normal")
+		p.admission = &queueing
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		_, err := p.ProcessRequestHeaders(ctx, nil)
+		require.ErrorContains(t, err, "request canceled while waiting for admission to backend ns/azure")
+		mm.RequireRequestFailure(t)
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/grpc/codes"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/semaphore"
+)
+
+// acquireConcurrencyLimits acquires a slot of every concurrency limit matching the request.
+//
+// On success, it returns a function releasing all the acquired slots, which is nil when no limit matches.
+// When a limit is reached, the slots acquired so far are released and the rejecting limit is returned.
+//
+// Failures of the semaphore backend are logged and the corresponding limit is not enforced, so that
+// an unavailable Redis does not take down the whole gateway.
+func acquireConcurrencyLimits(ctx context.Context, logger *slog.Logger, sem semaphore.Semaphore,
+	limits []filterapi.RuntimeConcurrencyLimit, requestHeaders map[string]string, model string,
+) (release func(), rejectedBy *filterapi.RuntimeConcurrencyLimit) {
+	var releases []func()
+	releaseAll := func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for i := range limits {
+		l := &limits[i]
+		key, ok := concurrencyLimitKey(l, requestHeaders, model)
+		if !ok {
+			continue
+		}
+		r, err := sem.TryAcquire(ctx, key, l.MaxConcurrentRequests)
+		if err != nil {
+			logger.Error("failed to acquire concurrency limit, not enforcing it",
+				slog.String("limit", l.Name), slog.String("error", err.Error()))
+			continue
+		}
+		if r == nil {
+			releaseAll()
+			return nil, l
+		}
+		releases = append(releases, r)
+	}
+	if len(releases) == 0 {
+		return nil, nil
+	}
+	return releaseAll, nil
+}
+
+// concurrencyLimitKey returns the semaphore key of the request for the limit, and false when the limit
+// does not apply to the request. The key is the limit name followed by the values of the Distinct headers
+// so that each distinct client gets its own semaphore.
+func concurrencyLimitKey(l *filterapi.RuntimeConcurrencyLimit, requestHeaders map[string]string, model string) (string, bool) {
+	if !slices.Contains(l.Models, model) {
+		return "", false
+	}
+	var key strings.Builder
+	key.WriteString(l.Name)
+	for i := range l.Headers {
+		h := &l.Headers[i]
+		value, present := requestHeaders[h.Name]
+		var matched bool
+		switch h.Type {
+		case filterapi.ConcurrencyLimitHeaderMatchTypeDistinct:
+			if !present || value == "" {
+				return "", false
+			}
+			key.WriteByte('/')
+			key.WriteString(strconv.Quote(value))
+			continue
+		case filterapi.ConcurrencyLimitHeaderMatchTypeRegularExpression:
+			matched = present && h.Regexp.MatchString(value)
+		default:
+			matched = present && value == h.Value
+		}
+		if matched == h.Invert {
+			return "", false
+		}
+	}
+	return key.String(), true
+}
+
+// createConcurrencyLimitExceededResponse creates the 429 ImmediateResponse for a request rejected by the limit.
+func createConcurrencyLimitExceededResponse(l *filterapi.RuntimeConcurrencyLimit) *extprocv3.ProcessingResponse {
+	const statusCode = 429
+	body := formatUserFacingErrorJSON("TooManyRequests", statusCode, "too many concurrent requests")
+	retryAfter := max(l.RetryAfterSeconds, 1)
+	headerMutation := &extprocv3.HeaderMutation{}
+	setHeader(headerMutation, "content-type", "application/json")
+	setHeader(headerMutation, "content-length", strconv.Itoa(len(body)))
+	setHeader(headerMutation, "retry-after", strconv.FormatUint(uint64(retryAfter), 10))
+	return &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extprocv3.ImmediateResponse{
+				Status:     &typev3.HttpStatus{Code: typev3.StatusCode_TooManyRequests},
+				Headers:    headerMutation,
+				Body:       body,
+				GrpcStatus: &extprocv3.GrpcStatus{Status: uint32(codes.ResourceExhausted)},
+			},
+		},
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/semaphore"
+	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
+)
+
+func newTestConcurrencyLimits(t *testing.T, limits ...filterapi.ConcurrencyLimit) []filterapi.RuntimeConcurrencyLimit {
+	rc, err := filterapi.NewRuntimeConfig(t.Context(), &filterapi.Config{ConcurrencyLimits: limits}, nil)
+	require.NoError(t, err)
+	return rc.ConcurrencyLimits
+}
+
+func Test_concurrencyLimitKey(t *testing.T) {
+	limits := newTestConcurrencyLimits(t,
+		filterapi.ConcurrencyLimit{Name: "ns/p/llama/0", Models: []string{"llama", "llama-alias"}},
+		filterapi.ConcurrencyLimit{
+			Name:   "ns/p/llama/1",
+			Models: []string{"llama"},
+			Headers: []filterapi.ConcurrencyLimitHeaderMatch{
+				{Name: "x-tenant", Type: filterapi.ConcurrencyLimitHeaderMatchTypeDistinct},
+				{Name: "x-tier", Type: filterapi.ConcurrencyLimitHeaderMatchTypeExact, Value: "free"},
+			},
+		},
+		filterapi.ConcurrencyLimit{
+			Name:   "ns/p/llama/2",
+			Models: []string{"llama"},
+			Headers: []filterapi.ConcurrencyLimitHeaderMatch{
+				{Name: "x-team", Type: filterapi.ConcurrencyLimitHeaderMatchTypeRegularExpression, Value: "^sre-.*", Invert: true},
+			},
+		},
+	)
+
+	for _, tc := range []struct {
+		name    string
+		limit   int
+		headers map[string]string
+		model   string
+		expKey  string
+		expOK   bool
+	}{
+		{name: "model match", limit: 0, model: "llama-alias", expKey: "ns/p/llama/0", expOK: true},
+		{name: "model mismatch", limit: 0, model: "gpt-4o"},
+		{
+			name: "distinct and exact", limit: 1, model: "llama",
+			headers: map[string]string{"x-tenant": "acme", "x-tier": "free"},
+			expKey:  `ns/p/llama/1/"acme"`, expOK: true,
+		},
+		{name: "distinct missing", limit: 1, model: "llama", headers: map[string]string{"x-tier": "free"}},
+		{name: "exact mismatch", limit: 1, model: "llama", headers: map[string]string{"x-tenant": "acme", "x-tier": "paid"}},
+		{name: "inverted regex absent", limit: 2, model: "llama", expKey: "ns/p/llama/2", expOK: true},
+		{name: "inverted regex matching", limit: 2, model: "llama", headers: map[string]string{"x-team": "sre-1"}},
+		{name: "inverted regex not matching", limit: 2, model: "llama", headers: map[string]string{"x-team": "ml"}, expKey: "ns/p/llama/2", expOK: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key, ok := concurrencyLimitKey(&limits[tc.limit], tc.headers, tc.model)
+			require.Equal(t, tc.expOK, ok)
+			require.Equal(t, tc.expKey, key)
+		})
+	}
+}
+
+type failingSemaphore struct{}
+
+func (failingSemaphore) TryAcquire(context.Context, string, uint32) (func(), error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingSemaphore) Close() error { return nil }
+
+func Test_acquireConcurrencyLimits(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limits := newTestConcurrencyLimits(t,
+		filterapi.ConcurrencyLimit{Name: "model", Models: []string{"llama"}, MaxConcurrentRequests: 2},
+		filterapi.ConcurrencyLimit{
+			Name:                  "tenant",
+			Models:                []string{"llama"},
+			Headers:               []filterapi.ConcurrencyLimitHeaderMatch{{Name: "x-tenant", Type: filterapi.ConcurrencyLimitHeaderMatchTypeDistinct}},
+			MaxConcurrentRequests: 1,
+		},
+	)
+
+	t.Run("no matching limit", func(t *testing.T) {
+		release, rejectedBy := acquireConcurrencyLimits(t.Context(), logger, semaphore.NewLocal(), limits, nil, "gpt-4o")
+		require.Nil(t, release)
+		require.Nil(t, rejectedBy)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		sem := semaphore.NewLocal()
+		acme := map[string]string{"x-tenant": "acme"}
+		release1, rejectedBy := acquireConcurrencyLimits(t.Context(), logger, sem, limits, acme, "llama")
+		require.NotNil(t, release1)
+		require.Nil(t, rejectedBy)
+
+		// The tenant limit is reached, and the slot of the model limit acquired first is given back.
+		release, rejectedBy := acquireConcurrencyLimits(t.Context(), logger, sem, limits, acme, "llama")
+		require.Nil(t, release)
+		require.Equal(t, "tenant", rejectedBy.Name)
+
+		// Another tenant only hits the model limit once both of its slots are held.
+		release2, rejectedBy := acquireConcurrencyLimits(t.Context(), logger, sem, limits, map[string]string{"x-tenant": "other"}, "llama")
+		require.NotNil(t, release2)
+		require.Nil(t, rejectedBy)
+		_, rejectedBy = acquireConcurrencyLimits(t.Context(), logger, sem, limits, map[string]string{"x-tenant": "third"}, "llama")
+		require.Equal(t, "model", rejectedBy.Name)
+
+		release1()
+		release1, rejectedBy = acquireConcurrencyLimits(t.Context(), logger, sem, limits, acme, "llama")
+		require.NotNil(t, release1)
+		require.Nil(t, rejectedBy)
+		release1()
+		release2()
+	})
+
+	t.Run("semaphore failure is not enforced", func(t *testing.T) {
+		release, rejectedBy := acquireConcurrencyLimits(t.Context(), logger, failingSemaphore{}, limits, nil, "llama")
+		require.Nil(t, release)
+		require.Nil(t, rejectedBy)
+	})
+}
+
+func Test_createConcurrencyLimitExceededResponse(t *testing.T) {
+	for _, tc := range []struct {
+		retryAfter    uint32
+		expRetryAfter string
+	}{
+		{retryAfter: 0, expRetryAfter: "1"},
+		{retryAfter: 30, expRetryAfter: "30"},
+	} {
+		resp := createConcurrencyLimitExceededResponse(&filterapi.RuntimeConcurrencyLimit{
+			ConcurrencyLimit: &filterapi.ConcurrencyLimit{Name: "limit", RetryAfterSeconds: tc.retryAfter},
+		})
+		ir := resp.GetImmediateResponse()
+		require.NotNil(t, ir)
+		require.Equal(t, typev3.StatusCode_TooManyRequests, ir.Status.Code)
+		require.JSONEq(t, `{"type":"error","error":{"type":"TooManyRequests","code":"429","message":"too many concurrent requests"}}`, string(ir.Body))
+		headers := map[string]string{}
+		for _, h := range ir.Headers.SetHeaders {
+			headers[h.Header.Key] = string(h.Header.RawValue)
+		}
+		require.Equal(t, tc.expRetryAfter, headers["retry-after"])
+		require.Equal(t, "application/json", headers["content-type"])
+	}
+}
+
+func Test_chatCompletionProcessorRouterFilter_ConcurrencyLimits(t *testing.T) {
+	orig := ConcurrencyLimiter
+	ConcurrencyLimiter = semaphore.NewLocal()
+	t.Cleanup(func() { ConcurrencyLimiter = orig })
+
+	config := &filterapi.RuntimeConfig{ConcurrencyLimits: newTestConcurrencyLimits(t, filterapi.ConcurrencyLimit{
+		Name: "limit", Models: []string{"llama"}, MaxConcurrentRequests: 1, RetryAfterSeconds: 5,
+	})}
+	newProcessor := func() *chatCompletionProcessorRouterFilter {
+		return &chatCompletionProcessorRouterFilter{
+			config:         config,
+			requestHeaders: map[string]string{":path": "/v1/chat/completions"},
+			logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+			tracer:         tracingapi.NoopTracer[openai.ChatCompletionRequest, openai.ChatCompletionResponse, openai.ChatCompletionResponseChunk]{},
+		}
+	}
+
+	first := newProcessor()
+	resp, err := first.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "llama", true, nil)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetRequestBody())
+	require.NotNil(t, first.releaseConcurrencyLimits)
+
+	second := newProcessor()
+	resp, err = second.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "llama", true, nil)})
+	require.NoError(t, err)
+	require.Equal(t, typev3.StatusCode_TooManyRequests, resp.GetImmediateResponse().GetStatus().GetCode())
+
+	// Other models are not limited.
+	other := newProcessor()
+	resp, err = other.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "gpt-4o", true, nil)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetRequestBody())
+	require.Nil(t, other.releaseConcurrencyLimits)
+
+	// The slot is held until the end of the response stream.
+	_, err = first.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte("data: {}\n\n")})
+	require.NoError(t, err)
+	resp, err = newProcessor().ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "llama", true, nil)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetImmediateResponse())
+
+	_, err = first.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{EndOfStream: true})
+	require.NoError(t, err)
+	third := newProcessor()
+	resp, err = third.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "llama", true, nil)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetRequestBody())
+
+	// Releasing again when the gRPC stream ends is a no-op, and a reset stream releases the slot as well.
+	first.releaseResources()
+	resp, err = newProcessor().ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "llama", true, nil)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetImmediateResponse())
+	third.releaseResources()
+	resp, err = newProcessor().ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "llama", true, nil)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetRequestBody())
+}
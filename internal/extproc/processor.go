@@ -39,6 +39,13 @@ type Processor interface {
 	SetBackend(ctx context.Context, backend *filterapi.RuntimeBackend, routeName string, routerProcessor Processor) error
 }
 
+// resourceReleaser is implemented by the processors holding resources that must be released when the
+// gRPC stream ends, which happens both when the request completes and when the stream is reset.
+type resourceReleaser interface {
+	// releaseResources releases the held resources. It must be safe to call multiple times.
+	releaseResources()
+}
+
 // passThroughProcessor implements the Processor interface.
 type passThroughProcessor struct{}
 
@@ -15,6 +15,7 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
@@ -24,6 +25,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/envoyproxy/ai-gateway/internal/admission"
 	"github.com/envoyproxy/ai-gateway/internal/bodymutator"
 	"github.com/envoyproxy/ai-gateway/internal/endpointspec"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
@@ -32,14 +34,31 @@ import (
 	"github.com/envoyproxy/ai-gateway/internal/json"
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 	"github.com/envoyproxy/ai-gateway/internal/metrics"
+	"github.com/envoyproxy/ai-gateway/internal/semaphore"
 	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
 	"github.com/envoyproxy/ai-gateway/internal/translator"
+	"github.com/envoyproxy/ai-gateway/internal/usagerecord"
 )
 
 // LogRequestHeaderAttributes is the mapping of request headers to log as dynamic metadata attributes.
 // This is configured at the startup of the extproc server.
 var LogRequestHeaderAttributes map[string]string
 
+// UsageRecordExporter receives one usage record per completed request. Nil disables usage records.
+// This is configured at the startup of the extproc server.
+var UsageRecordExporter usagerecord.Exporter
+
+// ConcurrencyLimiter holds the semaphores of the QuotaPolicy concurrency limits.
+// This is configured at the startup of the extproc server.
+var ConcurrencyLimiter = semaphore.NewLocal()
+
+// AdmissionController decides on the admission of the requests to the backends configured with admission control.
+var AdmissionController = admission.NewController()
+
+// AdmissionMetrics records the admission decisions of the backends configured with admission control.
+// Nil disables the metrics. This is configured at the startup of the extproc server.
+var AdmissionMetrics metrics.AdmissionMetrics
+
 // NewFactory creates a ProcessorFactory with the given parameters.
 //
 // Type Parameters:
@@ -103,6 +122,12 @@ type (
 		stream              bool
 		debugLogEnabled     bool
 		enableRedaction     bool
+		// requestStart is the time at which the router filter started processing the request.
+		requestStart time.Time
+		// releaseConcurrencyLimits releases the concurrency limit slots held by the request. Nil when none is held.
+		releaseConcurrencyLimits func()
+		// jwtClaims are the claims of the JWT verified by Envoy, if any.
+		jwtClaims map[string]any
 	}
 	// upstreamProcessor implements [Processor] for the upstream filter for the standard LLM endpoints.
 	//
@@ -125,6 +150,16 @@ type (
 		handler            filterapi.BackendAuthHandler
 		// cost is the cost of the request that is accumulated during the processing of the response.
 		costs metrics.TokenUsage
+		// responseModel is the latest model reported by the backend in the response.
+		responseModel string
+		// requestCosts are the computed LLMRequestCost values keyed by metadata key, set at the end of the stream.
+		requestCosts map[string]uint64
+		// admission is the admission control configuration of the backend. Nil when not configured.
+		admission *filterapi.BackendAdmission
+		// priorityClass specifies how the priority class of the request is determined. Nil when not configured.
+		priorityClass *filterapi.PriorityClassConfig
+		// admittedAt is the time at which the request was admitted to the backend, used to measure its latency.
+		admittedAt time.Time
 		// metrics tracking.
 		metrics metrics.Metrics
 	}
@@ -146,6 +181,7 @@ func newRouterProcessor[ReqT, RespT, RespChunkT any, EndpointSpecT endpointspec.
 		forceBodyMutation: false,
 		debugLogEnabled:   debugLogEnabled,
 		enableRedaction:   enableRedaction,
+		requestStart:      time.Now(),
 	}
 }
 
@@ -179,9 +215,19 @@ func (r *routerProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRespons
 	} else {
 		resp, err = r.passThroughProcessor.ProcessResponseBody(ctx, body)
 	}
+	if body.GetEndOfStream() {
+		r.releaseResources()
+	}
 	return
 }
 
+// releaseResources implements [resourceReleaser.releaseResources].
+func (r *routerProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) releaseResources() {
+	if r.releaseConcurrencyLimits != nil {
+		r.releaseConcurrencyLimits()
+	}
+}
+
 // formatUserFacingErrorJSON formats a user-facing error as a JSON response body.
 // Returns JSON in format: {"type":"error","error":{"type":"<errorType>","code":"<statusCode>","message":"<message>"}}
 func formatUserFacingErrorJSON(errorType string, statusCode int, message string) []byte {
@@ -277,13 +323,25 @@ func (r *routerProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRequest
 			Header: &corev3.HeaderValue{Key: internalapi.EnvoyOriginalPathHeader, RawValue: []byte(originalPath)},
 		})
 	}
+	r.jwtClaims = jwtClaimsFromContext(ctx)
+	quotaSetHeaders, quotaRemoveHeaders := applyQuotaSelectors(logger, r.config.QuotaSelectors, r.requestHeaders, originalModel, r.jwtClaims)
+	additionalHeaders = append(additionalHeaders, quotaSetHeaders...)
+	if len(r.config.ConcurrencyLimits) > 0 {
+		release, rejectedBy := acquireConcurrencyLimits(ctx, logger, ConcurrencyLimiter, r.config.ConcurrencyLimits, r.requestHeaders, originalModel)
+		if rejectedBy != nil {
+			logger.Debug("rejecting request exceeding the concurrency limit", slog.String("limit", rejectedBy.Name))
+			return createConcurrencyLimitExceededResponse(rejectedBy), nil
+		}
+		r.releaseConcurrencyLimits = release
+	}
 	r.originalModel = originalModel
 	r.originalRequestBody = body
 	r.stream = stream
 
 	// Tracing may need to inject headers, so create a header mutation here.
 	headerMutation := &extprocv3.HeaderMutation{
-		SetHeaders: additionalHeaders,
+		SetHeaders:    additionalHeaders,
+		RemoveHeaders: quotaRemoveHeaders,
 	}
 	r.span = r.tracer.StartSpanAndInjectHeaders(
 		ctx,
@@ -330,6 +388,22 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessReque
 	reqModel := cmp.Or(u.requestHeaders[internalapi.ModelNameHeaderKeyDefault], u.parent.originalModel)
 	u.metrics.SetRequestModel(reqModel)
 
+	if u.admission != nil {
+		priority := requestPriorityClass(u.priorityClass, u.requestHeaders, u.parent.jwtClaims)
+		res := AdmissionController.Admit(ctx, u.admission, priority)
+		recordAdmission(ctx, AdmissionMetrics, u.parent.span, u.admission, priority, res)
+		switch {
+		case res.Decision == admission.DecisionCanceled:
+			return nil, fmt.Errorf("request canceled while waiting for admission to backend %s: %w", u.admission.Key, ctx.Err())
+		case !res.Admitted():
+			u.logger.Debug("request shed by admission control", slog.String("backend", u.admission.Key),
+				slog.String("priority_class", string(priority)), slog.String("decision", string(res.Decision)))
+			u.metrics.RecordRequestCompletion(ctx, false, u.requestHeaders)
+			return createAdmissionShedResponse(u.admission, res), nil
+		}
+		u.admittedAt = time.Now()
+	}
+
 	// We force the body mutation in the following cases:
 	// * The request is a retry request because the body mutation might have happened the previous iteration.
 	// * The request is a streaming request, and the IncludeUsage option is set to false since we need to ensure that
@@ -445,10 +519,15 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRespo
 	defer func() {
 		if err != nil {
 			u.metrics.RecordRequestCompletion(ctx, false, u.requestHeaders)
+			u.exportUsageRecord(false)
 		}
 	}()
 
 	u.responseHeaders = headersToMap(headers)
+	if u.admission != nil && !u.admittedAt.IsZero() {
+		statusCode, _ := strconv.Atoi(u.responseHeaders[":status"])
+		AdmissionController.Record(u.admission, statusCode, time.Since(u.admittedAt))
+	}
 	if enc := u.responseHeaders["content-encoding"]; enc != "" {
 		u.responseEncoding = enc
 	}
@@ -478,10 +557,12 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRespo
 	defer func() {
 		if err != nil || recordRequestCompletionErr {
 			u.metrics.RecordRequestCompletion(ctx, false, u.requestHeaders)
+			u.exportUsageRecord(false)
 			return
 		}
 		if body.EndOfStream {
 			u.metrics.RecordRequestCompletion(ctx, true, u.requestHeaders)
+			u.exportUsageRecord(true)
 		}
 	}()
 
@@ -553,6 +634,9 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRespo
 
 	// Set the response model for metrics
 	u.metrics.SetResponseModel(responseModel)
+	if responseModel != "" {
+		u.responseModel = responseModel
+	}
 
 	// Record metrics.
 	if u.parent.stream {
@@ -569,7 +653,7 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRespo
 	}
 
 	if body.EndOfStream && (len(u.parent.config.GlobalRequestCosts) > 0 || len(u.parent.config.RequestCosts) > 0) {
-		metadata, err := buildDynamicMetadata(u.parent.config.GlobalRequestCosts, u.parent.config.RequestCosts, &u.costs, u.requestHeaders, u.backendName, u.routeName, responseModel)
+		metadata, requestCosts, err := buildDynamicMetadata(u.parent.config.GlobalRequestCosts, u.parent.config.RequestCosts, &u.costs, u.requestHeaders, u.backendName, u.routeName, responseModel)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build dynamic metadata: %w", err)
 		}
@@ -578,6 +662,7 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) ProcessRespo
 			u.mergeWithTokenLatencyMetadata(metadata)
 		}
 		resp.DynamicMetadata = metadata
+		u.requestCosts = requestCosts
 	}
 
 	if body.EndOfStream && u.parent.span != nil {
@@ -628,6 +713,8 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) SetBackend(c
 	u.handler = backend.Handler
 	u.headerMutator = headermutator.NewHeaderMutator(backend.Backend.HeaderMutation, rp.requestHeaders)
 	u.bodyMutator = bodymutator.NewBodyMutator(backend.Backend.BodyMutation, rp.originalRequestBodyRaw)
+	u.admission = backend.Backend.Admission
+	u.priorityClass = backend.Backend.PriorityClass
 	// Header-derived labels/CEL must be able to see the overridden request model.
 	if u.modelNameOverride != "" {
 		u.requestHeaders[internalapi.ModelNameHeaderKeyDefault] = u.modelNameOverride
@@ -673,6 +760,35 @@ func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) mergeWithTok
 	innerVal.Fields["token_latency_itl"] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: interTokenLatencyMs}}
 }
 
+// exportUsageRecord hands the usage record of the completed request to the UsageRecordExporter, if configured.
+// The exporter only enqueues the record, so this never blocks the request path.
+func (u *upstreamProcessor[ReqT, RespT, RespChunkT, EndpointSpecT]) exportUsageRecord(success bool) {
+	if UsageRecordExporter == nil || u.parent == nil {
+		return
+	}
+	statusCode, _ := strconv.Atoi(u.responseHeaders[":status"])
+	record := usagerecord.Record{
+		Timestamp:     time.Now(),
+		RequestID:     u.requestHeaders["x-request-id"],
+		RouteName:     u.routeName,
+		BackendName:   u.backendName,
+		OriginalModel: u.parent.originalModel,
+		RequestModel:  cmp.Or(u.requestHeaders[internalapi.ModelNameHeaderKeyDefault], u.parent.originalModel),
+		ResponseModel: u.responseModel,
+		Stream:        u.parent.stream,
+		Costs:         u.requestCosts,
+		Attributes:    requestHeaderAttributes(u.requestHeaders),
+		LatencyMs:     time.Since(u.parent.requestStart).Milliseconds(),
+		StatusCode:    statusCode,
+		Success:       success,
+	}
+	if u.parent.stream {
+		record.TimeToFirstTokenMs = u.metrics.GetTimeToFirstTokenMs()
+	}
+	record.SetTokenUsage(u.costs)
+	UsageRecordExporter.Export(record)
+}
+
 // buildContentLengthDynamicMetadataOnRequest builds dynamic metadata for the request with content length.
 //
 // This is necessary to ensure that the content length can be set after the extproc filter has processed the request,
@@ -726,6 +842,20 @@ func buildRequestHeaderDynamicMetadata(requestHeaders map[string]string) *struct
 	}
 }
 
+// requestHeaderAttributes returns the LogRequestHeaderAttributes present in the request headers keyed by attribute name.
+func requestHeaderAttributes(requestHeaders map[string]string) map[string]string {
+	var attrs map[string]string
+	for header, attr := range LogRequestHeaderAttributes {
+		if value := requestHeaders[header]; value != "" {
+			if attrs == nil {
+				attrs = make(map[string]string, len(LogRequestHeaderAttributes))
+			}
+			attrs[attr] = value
+		}
+	}
+	return attrs
+}
+
 func mergeDynamicMetadata(base, extra *structpb.Struct) *structpb.Struct {
 	if base == nil {
 		return extra
@@ -814,10 +944,13 @@ func evalRuntimeRequestCost(rc *filterapi.RuntimeRequestCost, costs *metrics.Tok
 // This function is called by the upstream filter only at the end of the stream (body.EndOfStream=true)
 // when the response is successfully completed. It is not called for failed requests or partial responses.
 // The metadata includes token usage costs and model information for downstream processing.
-// Two-tier precedence: for each metadataKey, check route-scoped requestCosts first (matching RouteName == routeName).
-// If found, use it. Otherwise, fall back to globalRequestCosts. If neither exists, the key is not emitted.
-func buildDynamicMetadata(globalRequestCosts []filterapi.RuntimeGlobalRequestCost, requestCosts []filterapi.RuntimeRequestCost, costs *metrics.TokenUsage, requestHeaders map[string]string, backendName, routeName, responseModel string) (*structpb.Struct, error) {
+// Three-tier precedence: for each metadataKey, check the backend-scoped requestCosts first (matching RouteName == routeName
+// and Backend == the serving backend), then the route-scoped requestCosts without a Backend filter.
+// If neither is found, fall back to globalRequestCosts. If none exists, the key is not emitted.
+// The computed cost values are also returned keyed by metadata key.
+func buildDynamicMetadata(globalRequestCosts []filterapi.RuntimeGlobalRequestCost, requestCosts []filterapi.RuntimeRequestCost, costs *metrics.TokenUsage, requestHeaders map[string]string, backendName, routeName, responseModel string) (*structpb.Struct, map[string]uint64, error) {
 	metadata := make(map[string]*structpb.Value, len(requestCosts)+len(globalRequestCosts)+3)
+	computedCosts := make(map[string]uint64, len(requestCosts)+len(globalRequestCosts))
 
 	// Track which metadata keys have been populated by route-scoped costs.
 	populatedKeys := make(map[string]struct{})
@@ -829,25 +962,39 @@ func buildDynamicMetadata(globalRequestCosts []filterapi.RuntimeGlobalRequestCos
 
 	actualModel := requestHeaders[internalapi.ModelNameHeaderKeyDefault]
 
-	// First, process route-scoped costs that match this route.
+	// First, process route-scoped costs that match this route, starting with the ones scoped to the serving backend.
 	// Route-scoped costs must have a RouteName set (validated at runtime config creation).
-	for i := range requestCosts {
-		rc := &requestCosts[i]
-		if rc.Backend != "" && rc.Backend != shortBackend {
-			continue
-		}
-		if rc.RouteName != routeName {
-			continue
-		}
-		if rc.Model != "" && rc.Model != actualModel {
-			continue
+	for _, backendScoped := range []bool{true, false} {
+		// Within a tier, the last matching entry for a metadataKey wins.
+		tierKeys := make(map[string]struct{})
+		for i := range requestCosts {
+			rc := &requestCosts[i]
+			if (rc.Backend != "") != backendScoped {
+				continue
+			}
+			if rc.Backend != "" && rc.Backend != shortBackend {
+				continue
+			}
+			if rc.RouteName != routeName {
+				continue
+			}
+			if rc.Model != "" && rc.Model != actualModel {
+				continue
+			}
+			if _, exists := populatedKeys[rc.MetadataKey]; exists {
+				continue // Backend-scoped cost already set this key.
+			}
+			cost, err := evalRuntimeRequestCost(rc, costs, requestHeaders, backendName, routeName)
+			if err != nil {
+				return nil, nil, err
+			}
+			metadata[rc.MetadataKey] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(cost)}}
+			computedCosts[rc.MetadataKey] = cost
+			tierKeys[rc.MetadataKey] = struct{}{}
 		}
-		cost, err := evalRuntimeRequestCost(rc, costs, requestHeaders, backendName, routeName)
-		if err != nil {
-			return nil, err
+		for k := range tierKeys {
+			populatedKeys[k] = struct{}{}
 		}
-		metadata[rc.MetadataKey] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(cost)}}
-		populatedKeys[rc.MetadataKey] = struct{}{}
 	}
 
 	// Then, process global costs for keys not already populated.
@@ -858,9 +1005,10 @@ func buildDynamicMetadata(globalRequestCosts []filterapi.RuntimeGlobalRequestCos
 		}
 		cost, err := evalRuntimeGlobalRequestCost(rc, costs, requestHeaders, backendName, routeName)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		metadata[rc.MetadataKey] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(cost)}}
+		computedCosts[rc.MetadataKey] = cost
 	}
 
 	metadata["model_name_override"] = &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: actualModel}}
@@ -888,7 +1036,7 @@ func buildDynamicMetadata(globalRequestCosts []filterapi.RuntimeGlobalRequestCos
 	}
 
 	if len(metadata) == 0 {
-		return nil, nil
+		return nil, computedCosts, nil
 	}
 
 	return &structpb.Struct{
@@ -899,5 +1047,5 @@ func buildDynamicMetadata(globalRequestCosts []filterapi.RuntimeGlobalRequestCos
 				},
 			},
 		},
-	}, nil
+	}, computedCosts, nil
 }
@@ -14,6 +14,7 @@ import (
 	"log/slog"
 	"mime/multipart"
 	"testing"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
@@ -23,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/utils/ptr"
 
 	anthropicschema "github.com/envoyproxy/ai-gateway/internal/apischema/anthropic"
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
@@ -36,6 +38,7 @@ import (
 	"github.com/envoyproxy/ai-gateway/internal/metrics"
 	"github.com/envoyproxy/ai-gateway/internal/testing/testotel"
 	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
+	"github.com/envoyproxy/ai-gateway/internal/usagerecord"
 )
 
 func TestNewFactory(t *testing.T) {
@@ -1211,6 +1214,114 @@ func Test_ProcessResponseBody_UsesActualResponseModel(t *testing.T) {
 	mm.RequireRequestSuccess(t)
 }
 
+// fakeUsageRecordExporter collects the exported usage records.
+type fakeUsageRecordExporter struct {
+	records []usagerecord.Record
+}
+
+func (f *fakeUsageRecordExporter) Export(r usagerecord.Record)    { f.records = append(f.records, r) }
+func (f *fakeUsageRecordExporter) Shutdown(context.Context) error { return nil }
+
+func Test_ProcessResponseBody_ExportsUsageRecord(t *testing.T) {
+	exporter := &fakeUsageRecordExporter{}
+	UsageRecordExporter = exporter
+	LogRequestHeaderAttributes = map[string]string{"x-tenant-id": "tenant.id"}
+	t.Cleanup(func() {
+		UsageRecordExporter = nil
+		LogRequestHeaderAttributes = nil
+	})
+
+	newProcessor := func(mt *mockTranslator) *chatCompletionProcessorUpstreamFilter {
+		body := openai.ChatCompletionRequest{Model: "gpt-5-nano"}
+		raw, _ := json.Marshal(body)
+		mt.t = t
+		mt.expRequestBody = &body
+		return &chatCompletionProcessorUpstreamFilter{
+			requestHeaders: map[string]string{
+				":path":                               "/v1/chat/completions",
+				"x-request-id":                        "req-1",
+				"x-tenant-id":                         "acme",
+				internalapi.ModelNameHeaderKeyDefault: "gpt-5-nano-override",
+			},
+			metrics:     &mockMetrics{},
+			translator:  mt,
+			backendName: "default/openai/route/r/rule/0/ref/0",
+			routeName:   "default/r",
+			parent: &chatCompletionProcessorRouterFilter{
+				originalRequestBody:    &body,
+				originalRequestBodyRaw: raw,
+				logger:                 slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &filterapi.RuntimeConfig{
+					GlobalRequestCosts: []filterapi.RuntimeGlobalRequestCost{
+						{GlobalLLMRequestCost: &filterapi.GlobalLLMRequestCost{MetadataKey: "input", Type: filterapi.LLMRequestCostTypeInputToken}},
+					},
+				},
+				originalModel: "gpt-5-nano",
+				requestStart:  time.Now().Add(-time.Second),
+			},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		exporter.records = nil
+		mt := &mockTranslator{expHeaders: map[string]string{":status": "200"}, retResponseModel: "gpt-5-nano-2025-08-07"}
+		mt.retUsedToken.SetInputTokens(10)
+		mt.retUsedToken.SetOutputTokens(20)
+		p := newProcessor(mt)
+		_, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		_, err = p.ProcessResponseHeaders(t.Context(), &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":status", Value: "200"}}})
+		require.NoError(t, err)
+		_, err = p.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte("{}"), EndOfStream: true})
+		require.NoError(t, err)
+
+		require.Len(t, exporter.records, 1)
+		r := exporter.records[0]
+		require.Equal(t, "req-1", r.RequestID)
+		require.Equal(t, "default/r", r.RouteName)
+		require.Equal(t, "default/openai/route/r/rule/0/ref/0", r.BackendName)
+		require.Equal(t, "gpt-5-nano", r.OriginalModel)
+		require.Equal(t, "gpt-5-nano-override", r.RequestModel)
+		require.Equal(t, "gpt-5-nano-2025-08-07", r.ResponseModel)
+		require.Equal(t, ptr.To[uint32](10), r.InputTokens)
+		require.Equal(t, ptr.To[uint32](20), r.OutputTokens)
+		require.Nil(t, r.TotalTokens)
+		require.Equal(t, map[string]uint64{"input": 10}, r.Costs)
+		require.Equal(t, map[string]string{"tenant.id": "acme"}, r.Attributes)
+		require.GreaterOrEqual(t, r.LatencyMs, int64(1000))
+		require.Equal(t, 200, r.StatusCode)
+		require.True(t, r.Success)
+	})
+
+	t.Run("not exported before end of stream", func(t *testing.T) {
+		exporter.records = nil
+		p := newProcessor(&mockTranslator{expHeaders: map[string]string{":status": "200"}})
+		_, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		_, err = p.ProcessResponseHeaders(t.Context(), &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":status", Value: "200"}}})
+		require.NoError(t, err)
+		_, err = p.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte("{}")})
+		require.NoError(t, err)
+		require.Empty(t, exporter.records)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		exporter.records = nil
+		p := newProcessor(&mockTranslator{expHeaders: map[string]string{":status": "503"}})
+		_, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		_, err = p.ProcessResponseHeaders(t.Context(), &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":status", Value: "503"}}})
+		require.NoError(t, err)
+		_, err = p.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte("{}"), EndOfStream: true})
+		require.NoError(t, err)
+
+		require.Len(t, exporter.records, 1)
+		require.Equal(t, 503, exporter.records[0].StatusCode)
+		require.False(t, exporter.records[0].Success)
+		require.Nil(t, exporter.records[0].Costs)
+	})
+}
+
 func TestChatCompletionProcessorUpstreamFilter_ProcessRequestHeaders_WithBodyMutations(t *testing.T) {
 	t.Run("body mutations applied correctly", func(t *testing.T) {
 		headers := map[string]string{
@@ -1625,7 +1736,7 @@ func Test_buildDynamicMetadata(t *testing.T) {
 		costs := &metrics.TokenUsage{}
 		headers := map[string]string{internalapi.ModelNameHeaderKeyDefault: "gpt-4"}
 
-		md, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "", "", "")
+		md, _, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "", "", "")
 		require.NoError(t, err)
 		require.NotNil(t, md)
 
@@ -1638,7 +1749,7 @@ func Test_buildDynamicMetadata(t *testing.T) {
 		// After backend override, the header contains the backend-specific model name.
 		headers := map[string]string{internalapi.ModelNameHeaderKeyDefault: "us.anthropic.claude-sonnet-4.5-v2"}
 
-		md, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "default/my-backend", "", "")
+		md, _, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "default/my-backend", "", "")
 		require.NoError(t, err)
 		require.NotNil(t, md)
 
@@ -1650,7 +1761,7 @@ func Test_buildDynamicMetadata(t *testing.T) {
 		costs := &metrics.TokenUsage{}
 		headers := map[string]string{internalapi.ModelNameHeaderKeyDefault: "gpt-4"}
 
-		md, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "ns/backend-a", "", "")
+		md, _, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "ns/backend-a", "", "")
 		require.NoError(t, err)
 		require.NotNil(t, md)
 
@@ -1662,7 +1773,7 @@ func Test_buildDynamicMetadata(t *testing.T) {
 		costs := &metrics.TokenUsage{}
 		headers := map[string]string{internalapi.ModelNameHeaderKeyDefault: "gpt-4"}
 
-		md, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "", "", "")
+		md, _, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "", "", "")
 		require.NoError(t, err)
 		require.NotNil(t, md)
 
@@ -1682,7 +1793,7 @@ func Test_buildDynamicMetadata(t *testing.T) {
 		costs.SetInputTokens(50)
 		headers := map[string]string{internalapi.ModelNameHeaderKeyDefault: "claude-sonnet"}
 
-		md, err := buildDynamicMetadata(nil, config.RequestCosts, costs, headers, "default/backend", "", "")
+		md, _, err := buildDynamicMetadata(nil, config.RequestCosts, costs, headers, "default/backend", "", "")
 		require.NoError(t, err)
 		require.NotNil(t, md)
 
@@ -1697,7 +1808,7 @@ func Test_buildDynamicMetadata(t *testing.T) {
 		costs := &metrics.TokenUsage{}
 		headers := map[string]string{}
 
-		md, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "", "", "")
+		md, _, err := buildDynamicMetadata(nil, []filterapi.RuntimeRequestCost{}, costs, headers, "", "", "")
 		require.NoError(t, err)
 		require.NotNil(t, md)
 
@@ -1923,7 +2034,7 @@ func TestBuildDynamicMetadata_routeScoped(t *testing.T) {
 			tu.SetInputTokens(tt.inputTokens)
 			tu.SetTotalTokens(tt.totalTokens)
 
-			md, err := buildDynamicMetadata(nil, tt.requestCosts, &tu, tt.requestHeaders, tt.backendName, tt.routeName, "")
+			md, _, err := buildDynamicMetadata(nil, tt.requestCosts, &tu, tt.requestHeaders, tt.backendName, tt.routeName, "")
 			require.NoError(t, err)
 
 			ns := md.Fields[internalapi.AIGatewayFilterMetadataNamespace].GetStructValue().Fields
@@ -2083,6 +2194,59 @@ func TestBuildDynamicMetadata_GlobalAndRouteScoped(t *testing.T) {
 			routeName:      "ns/free-route",
 			wantCostValues: map[string]float64{"custom_cost": 0}, // Route CEL overrides global
 		},
+		{
+			name: "backend cost overrides route and global regardless of order",
+			globalCosts: []filterapi.RuntimeGlobalRequestCost{
+				{GlobalLLMRequestCost: &filterapi.GlobalLLMRequestCost{MetadataKey: "billing_charges", Type: filterapi.LLMRequestCostTypeInputToken}},
+				{GlobalLLMRequestCost: &filterapi.GlobalLLMRequestCost{MetadataKey: "global_only", Type: filterapi.LLMRequestCostTypeInputToken}},
+			},
+			routeCosts: []filterapi.RuntimeRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "billing_charges", RouteName: "ns/route", Backend: "ns/ptu", Type: filterapi.LLMRequestCostTypeTotalToken}},
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "billing_charges", RouteName: "ns/route", Type: filterapi.LLMRequestCostTypeOutputToken}},
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "route_only", RouteName: "ns/route", Type: filterapi.LLMRequestCostTypeOutputToken}},
+			},
+			inputTokens:    100,
+			outputTokens:   50,
+			totalTokens:    150,
+			requestHeaders: map[string]string{internalapi.ModelNameHeaderKeyDefault: "model"},
+			backendName:    "ns/ptu/route/route/rule/0/ref/0",
+			routeName:      "ns/route",
+			wantCostValues: map[string]float64{"billing_charges": 150, "route_only": 50, "global_only": 100},
+		},
+		{
+			name: "backend cost of another backend falls back to route",
+			globalCosts: []filterapi.RuntimeGlobalRequestCost{
+				{GlobalLLMRequestCost: &filterapi.GlobalLLMRequestCost{MetadataKey: "billing_charges", Type: filterapi.LLMRequestCostTypeInputToken}},
+			},
+			routeCosts: []filterapi.RuntimeRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "billing_charges", RouteName: "ns/route", Backend: "ns/ptu", Type: filterapi.LLMRequestCostTypeTotalToken}},
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "billing_charges", RouteName: "ns/route", Type: filterapi.LLMRequestCostTypeOutputToken}},
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "backend_only", RouteName: "ns/route", Backend: "ns/ptu", Type: filterapi.LLMRequestCostTypeTotalToken}},
+			},
+			inputTokens:    100,
+			outputTokens:   50,
+			totalTokens:    150,
+			requestHeaders: map[string]string{internalapi.ModelNameHeaderKeyDefault: "model"},
+			backendName:    "ns/on-demand/route/route/rule/0/ref/1",
+			routeName:      "ns/route",
+			wantCostValues: map[string]float64{"billing_charges": 50},
+			wantAbsent:     []string{"backend_only"},
+		},
+		{
+			name: "backend cost falls back to global when route has none",
+			globalCosts: []filterapi.RuntimeGlobalRequestCost{
+				{GlobalLLMRequestCost: &filterapi.GlobalLLMRequestCost{MetadataKey: "billing_charges", Type: filterapi.LLMRequestCostTypeInputToken}},
+			},
+			routeCosts: []filterapi.RuntimeRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{MetadataKey: "billing_charges", RouteName: "ns/route", Backend: "ns/ptu", Type: filterapi.LLMRequestCostTypeTotalToken}},
+			},
+			inputTokens:    100,
+			totalTokens:    150,
+			requestHeaders: map[string]string{internalapi.ModelNameHeaderKeyDefault: "model"},
+			backendName:    "ns/on-demand/route/route/rule/0/ref/1",
+			routeName:      "ns/route",
+			wantCostValues: map[string]float64{"billing_charges": 100},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2092,16 +2256,18 @@ func TestBuildDynamicMetadata_GlobalAndRouteScoped(t *testing.T) {
 			tu.SetOutputTokens(tt.outputTokens)
 			tu.SetTotalTokens(tt.totalTokens)
 
-			md, err := buildDynamicMetadata(tt.globalCosts, tt.routeCosts, &tu, tt.requestHeaders, tt.backendName, tt.routeName, "")
+			md, computedCosts, err := buildDynamicMetadata(tt.globalCosts, tt.routeCosts, &tu, tt.requestHeaders, tt.backendName, tt.routeName, "")
 			require.NoError(t, err)
 
 			ns := md.Fields[internalapi.AIGatewayFilterMetadataNamespace].GetStructValue().Fields
 			for k, want := range tt.wantCostValues {
 				require.Equal(t, want, ns[k].GetNumberValue(), "key %q", k)
+				require.Equal(t, uint64(want), computedCosts[k], "key %q", k)
 			}
 			for _, k := range tt.wantAbsent {
 				_, exists := ns[k]
 				require.False(t, exists, "key %q should be absent from metadata", k)
+				require.NotContains(t, computedCosts, k)
 			}
 		})
 	}
@@ -0,0 +1,59 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"log/slog"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
+)
+
+// applyQuotaSelectors evaluates the QuotaPolicy JWT claim and CEL selectors against the request and
+// returns the internal headers to set and to remove so that the rate limit filter can match on them.
+// The claims are the ones of the JWT verified by Envoy, see [quotaselector.VerifiedClaims].
+//
+// Headers whose value cannot be resolved are removed rather than left untouched so that clients
+// cannot select a quota bucket by sending the internal headers themselves.
+// requestHeaders is updated in place to reflect the returned mutation.
+func applyQuotaSelectors(logger *slog.Logger, selectors *filterapi.RuntimeQuotaSelectors, requestHeaders map[string]string, model string, claims map[string]any) (
+	setHeaders []*corev3.HeaderValueOption, removeHeaders []string,
+) {
+	if selectors == nil {
+		return nil, nil
+	}
+
+	apply := func(header, value string, ok bool) {
+		if !ok || value == "" {
+			delete(requestHeaders, header)
+			removeHeaders = append(removeHeaders, header)
+			return
+		}
+		requestHeaders[header] = value
+		setHeaders = append(setHeaders, &corev3.HeaderValueOption{
+			Header: &corev3.HeaderValue{Key: header, RawValue: []byte(value)},
+		})
+	}
+
+	for _, c := range selectors.JWTClaims {
+		value, ok := quotaselector.ClaimValue(claims, c.Name)
+		apply(c.Header, value, ok)
+	}
+
+	if len(selectors.CELs) > 0 {
+		attrs := quotaselector.RequestAttributes(requestHeaders, model, claims)
+		for _, c := range selectors.CELs {
+			value, err := quotaselector.EvaluateCEL(c.CELProg, attrs)
+			if err != nil {
+				logger.Debug("cannot evaluate CEL quota selector, ignoring", slog.String("header", c.Header), slog.String("error", err.Error()))
+			}
+			apply(c.Header, value, err == nil)
+		}
+	}
+	return
+}
@@ -0,0 +1,92 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
+)
+
+func Test_applyQuotaSelectors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("nil selectors", func(t *testing.T) {
+		set, remove := applyQuotaSelectors(logger, nil, map[string]string{}, "gpt-4o", nil)
+		require.Nil(t, set)
+		require.Nil(t, remove)
+	})
+
+	claims := map[string]any{
+		"sub": "alice",
+		"org": map[string]any{"tier": "free"},
+	}
+
+	selectors, err := filterapi.NewRuntimeConfig(t.Context(), &filterapi.Config{
+		QuotaSelectors: &filterapi.QuotaSelectors{
+			JWTClaims: []string{"sub", "org.tier", "missing"},
+			CELExpressions: []string{
+				`request.model == "gpt-4o" && request.auth.jwt.claims.org.tier == "free"`,
+				`request.headers["x-team"]`,
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	subHeader := quotaselector.ClaimHeaderName("sub")
+	tierHeader := quotaselector.ClaimHeaderName("org.tier")
+	missingHeader := quotaselector.ClaimHeaderName("missing")
+	matchHeader := quotaselector.CELHeaderName(`request.model == "gpt-4o" && request.auth.jwt.claims.org.tier == "free"`)
+	teamHeader := quotaselector.CELHeaderName(`request.headers["x-team"]`)
+
+	t.Run("with verified claims", func(t *testing.T) {
+		headers := map[string]string{
+			"x-team": "ml",
+			// Spoofed by the client.
+			missingHeader: "spoofed",
+		}
+		set, remove := applyQuotaSelectors(logger, selectors.QuotaSelectors, headers, "gpt-4o", claims)
+		actual := map[string]string{}
+		for _, h := range set {
+			actual[h.Header.Key] = string(h.Header.RawValue)
+		}
+		require.Equal(t, map[string]string{
+			subHeader:   "alice",
+			tierHeader:  "free",
+			matchHeader: "true",
+			teamHeader:  "ml",
+		}, actual)
+		require.Equal(t, []string{missingHeader}, remove)
+		require.Equal(t, "alice", headers[subHeader])
+		require.NotContains(t, headers, missingHeader)
+	})
+
+	t.Run("without token", func(t *testing.T) {
+		headers := map[string]string{subHeader: "spoofed"}
+		set, remove := applyQuotaSelectors(logger, selectors.QuotaSelectors, headers, "gpt-4o", nil)
+		require.Empty(t, set)
+		// The CEL expressions fail on the missing claim and header, so every header is removed.
+		require.ElementsMatch(t, []string{subHeader, tierHeader, missingHeader, matchHeader, teamHeader}, remove)
+		require.Empty(t, headers)
+	})
+
+	t.Run("unverified token", func(t *testing.T) {
+		// The claims of a token that Envoy did not verify are not used, even if it decodes.
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "bob"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+		headers := map[string]string{"authorization": "Bearer " + forged, "x-team": "ml"}
+		set, remove := applyQuotaSelectors(logger, selectors.QuotaSelectors, headers, "gpt-4o", nil)
+		require.Len(t, set, 1)
+		require.Equal(t, teamHeader, set[0].Header.Key)
+		require.ElementsMatch(t, []string{subHeader, tierHeader, missingHeader, matchHeader}, remove)
+	})
+}
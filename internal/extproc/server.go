@@ -28,6 +28,7 @@ import (
 	"github.com/envoyproxy/ai-gateway/internal/backendauth"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 	"github.com/envoyproxy/ai-gateway/internal/redaction"
 )
 
@@ -42,6 +43,16 @@ type contextKey string
 // loggerContextKey is the context key for the request-scoped logger.
 const loggerContextKey contextKey = "logger"
 
+// jwtClaimsContextKey is the context key for the claims of the JWT verified by Envoy.
+const jwtClaimsContextKey contextKey = "jwtClaims"
+
+// jwtClaimsFromContext extracts the claims of the JWT verified by Envoy from the context.
+// If no JWT was verified, it returns nil.
+func jwtClaimsFromContext(ctx context.Context) map[string]any {
+	claims, _ := ctx.Value(jwtClaimsContextKey).(map[string]any)
+	return claims
+}
+
 // loggerFromContext extracts the request-scoped logger from the context.
 // If no logger is found in the context, it returns nil.
 func loggerFromContext(ctx context.Context) *slog.Logger {
@@ -146,6 +157,9 @@ func (s *Server) Process(stream extprocv3.ExternalProcessor_ProcessServer) error
 	// Seed the context with the server-level logger as a fallback so that loggerFromContext never returns nil in processMsg.
 	ctx = context.WithValue(ctx, loggerContextKey, s.logger)
 	defer func() {
+		if r, ok := p.(resourceReleaser); ok {
+			r.releaseResources()
+		}
 		if !isUpstreamFilter {
 			s.routerProcessorsPerReqIDMutex.Lock()
 			defer s.routerProcessorsPerReqIDMutex.Unlock()
@@ -186,6 +200,8 @@ func (s *Server) Process(stream extprocv3.ExternalProcessor_ProcessServer) error
 					return status.Errorf(codes.Internal, "missing internal request ID header from router filter")
 				}
 			} else {
+				// The jwt_authn metadata is only forwarded to the router filter.
+				ctx = context.WithValue(ctx, jwtClaimsContextKey, quotaselector.VerifiedClaims(req.GetMetadataContext()))
 				// For router filter, create a unique internal request ID to avoid race conditions
 				// with duplicate x-request-id values by appending a UUID suffix to the original request ID
 				internalReqID = originalReqID + "-" + s.uuidFn()
@@ -25,6 +25,7 @@ import (
 
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
 )
 
@@ -294,6 +295,53 @@ func TestServer_Process(t *testing.T) {
 		err := s.Process(ms)
 		require.ErrorContains(t, err, "context deadline exceeded")
 	})
+	t.Run("releases processor resources at the end of the stream", func(t *testing.T) {
+		s, p := requireNewServerWithMockProcessor(t)
+		hm := &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":path", Value: "/releasing"}}}
+		p.t = t
+		p.expHeaderMap = hm
+		rp := &releasingMockProcessor{mockProcessor: p}
+		s.Register("/releasing", func(*filterapi.RuntimeConfig, map[string]string, *slog.Logger, bool, bool) (Processor, error) {
+			return rp, nil
+		})
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		req := &extprocv3.ProcessingRequest{
+			Request: &extprocv3.ProcessingRequest_RequestHeaders{RequestHeaders: &extprocv3.HttpHeaders{Headers: hm}},
+		}
+		ms := &mockExternalProcessingStream{t: t, ctx: ctx, retRecv: req}
+		err := s.Process(ms)
+		require.ErrorContains(t, err, "context deadline exceeded")
+		require.True(t, rp.released)
+	})
+	t.Run("passes the verified JWT claims to the router processor", func(t *testing.T) {
+		s, p := requireNewServerWithMockProcessor(t)
+		hm := &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":path", Value: "/claims"}}}
+		p.t = t
+		p.expHeaderMap = hm
+		cp := &claimsMockProcessor{mockProcessor: p}
+		s.Register("/claims", func(*filterapi.RuntimeConfig, map[string]string, *slog.Logger, bool, bool) (Processor, error) {
+			return cp, nil
+		})
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		req := &extprocv3.ProcessingRequest{
+			Request: &extprocv3.ProcessingRequest_RequestHeaders{RequestHeaders: &extprocv3.HttpHeaders{Headers: hm}},
+			MetadataContext: &corev3.Metadata{FilterMetadata: map[string]*structpb.Struct{
+				quotaselector.JWTAuthnMetadataNamespace: {Fields: map[string]*structpb.Value{
+					"provider": structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+						"sub": structpb.NewStringValue("alice"),
+					}}),
+				}},
+			}},
+		}
+		ms := &mockExternalProcessingStream{t: t, ctx: ctx, retRecv: req}
+		err := s.Process(ms)
+		require.ErrorContains(t, err, "context deadline exceeded")
+		require.Equal(t, map[string]any{"sub": "alice"}, cp.claims)
+	})
 	t.Run("without going through request headers phase", func(t *testing.T) {
 		// This is a regression test as in #419.
 		s, _ := requireNewServerWithMockProcessor(t)
@@ -415,6 +463,27 @@ func TestResolveRouteName(t *testing.T) {
 	require.Empty(t, actual)
 }
 
+// releasingMockProcessor is a mockProcessor implementing resourceReleaser.
+type releasingMockProcessor struct {
+	*mockProcessor
+	released bool
+}
+
+// releaseResources implements [resourceReleaser.releaseResources].
+func (m *releasingMockProcessor) releaseResources() { m.released = true }
+
+// claimsMockProcessor records the verified JWT claims of the context of the request headers.
+type claimsMockProcessor struct {
+	*mockProcessor
+	claims map[string]any
+}
+
+// ProcessRequestHeaders implements [Processor.ProcessRequestHeaders].
+func (m *claimsMockProcessor) ProcessRequestHeaders(ctx context.Context, headerMap *corev3.HeaderMap) (*extprocv3.ProcessingResponse, error) {
+	m.claims = jwtClaimsFromContext(ctx)
+	return m.mockProcessor.ProcessRequestHeaders(ctx, headerMap)
+}
+
 func TestServer_ProcessorSelection(t *testing.T) {
 	s, err := NewServer(slog.Default(), false)
 	require.NoError(t, err)
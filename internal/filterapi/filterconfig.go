@@ -51,6 +51,61 @@ type Config struct {
 	UnscopedModels []Model `json:"unscopedModels,omitempty"`
 	// MCPConfig is the configuration for the MCPRoute implementations.
 	MCPConfig *MCPConfig `json:"mcpConfig,omitempty"`
+	// QuotaSelectors is the list of request attributes referenced by the QuotaPolicy client selectors. Optional.
+	QuotaSelectors *QuotaSelectors `json:"quotaSelectors,omitempty"`
+	// ConcurrencyLimits is the list of the QuotaPolicy concurrent request limits. Optional.
+	ConcurrencyLimits []ConcurrencyLimit `json:"concurrencyLimits,omitempty"`
+}
+
+// ConcurrencyLimit caps the number of in-flight requests matching the given models and headers.
+// The filter acquires a slot when the request body is received and releases it at the end of the
+// stream, rejecting the request with a 429 when no slot is available.
+type ConcurrencyLimit struct {
+	// Name uniquely identifies the limit. It is the prefix of the semaphore keys.
+	Name string `json:"name"`
+	// Models is the list of the request models the limit applies to.
+	Models []string `json:"models"`
+	// Headers is the list of request header matches that must all hold for the limit to apply.
+	// The JWT claim and CEL selectors are matched on the internal headers set for QuotaSelectors.
+	Headers []ConcurrencyLimitHeaderMatch `json:"headers,omitempty"`
+	// MaxConcurrentRequests is the maximum number of in-flight requests per semaphore key.
+	MaxConcurrentRequests uint32 `json:"maxConcurrentRequests"`
+	// RetryAfterSeconds is the value of the retry-after header of the rejected requests.
+	RetryAfterSeconds uint32 `json:"retryAfterSeconds,omitempty"`
+}
+
+// ConcurrencyLimitHeaderMatch is a request header match of a ConcurrencyLimit.
+type ConcurrencyLimitHeaderMatch struct {
+	// Name is the lower-cased name of the header.
+	Name string `json:"name"`
+	// Type is the kind of the match.
+	Type ConcurrencyLimitHeaderMatchType `json:"type"`
+	// Value is the exact value or the regular expression to match. Empty for the Distinct type.
+	Value string `json:"value,omitempty"`
+	// Invert inverts the result of the Exact and RegularExpression matches.
+	Invert bool `json:"invert,omitempty"`
+}
+
+// ConcurrencyLimitHeaderMatchType specifies the kind of a ConcurrencyLimitHeaderMatch.
+type ConcurrencyLimitHeaderMatchType string
+
+const (
+	// ConcurrencyLimitHeaderMatchTypeExact matches the exact header value.
+	ConcurrencyLimitHeaderMatchTypeExact ConcurrencyLimitHeaderMatchType = "Exact"
+	// ConcurrencyLimitHeaderMatchTypeRegularExpression matches the header value against a regular expression.
+	ConcurrencyLimitHeaderMatchTypeRegularExpression ConcurrencyLimitHeaderMatchType = "RegularExpression"
+	// ConcurrencyLimitHeaderMatchTypeDistinct matches any present header and gives each distinct value its own semaphore.
+	ConcurrencyLimitHeaderMatchTypeDistinct ConcurrencyLimitHeaderMatchType = "Distinct"
+)
+
+// QuotaSelectors holds the JWT claims and CEL expressions referenced by the QuotaPolicy client selectors.
+// The filter evaluates each of them on every request and sets the result in an internal request header
+// so that the rate limit filter can match on it. See the quotaselector package for the header names.
+type QuotaSelectors struct {
+	// JWTClaims is the list of the (possibly dot-separated) JWT claim names.
+	JWTClaims []string `json:"jwtClaims,omitempty"`
+	// CELExpressions is the list of the CEL expressions.
+	CELExpressions []string `json:"celExpressions,omitempty"`
 }
 
 // Model corresponds to the OpenAI model object in the OpenAI-compatible APIs
@@ -95,11 +150,11 @@ type LLMRequestCost struct {
 	// CEL is the CEL expression to calculate the cost of the request.
 	// This is not empty when the Type is LLMRequestCostTypeCEL.
 	CEL string `json:"cel,omitempty"`
-	// Backend is an optional filter set exclusively by the QuotaPolicy controller.
-	// It is NOT exposed in any user-facing CRD. When non-empty, this cost entry is
-	// only evaluated when the serving backend's short name (namespace/name) matches.
-	// This allows different QuotaPolicies targeting different backends to use different
-	// cost expressions while sharing the same metadata key.
+	// Backend is an optional filter set by the controller for the costs configured on an AIServiceBackend
+	// and for the QuotaPolicy cost expressions. When non-empty, this cost entry is only evaluated when
+	// the serving backend's short name (namespace/name) matches, and it takes precedence over the
+	// entries without a Backend for the same metadata key.
+	// This allows different backends to use different cost expressions while sharing the same metadata key.
 	Backend string `json:"backend,omitempty"`
 	// Model is an optional filter set exclusively by the QuotaPolicy controller.
 	// It is NOT exposed in any user-facing CRD. When non-empty, this cost entry is
@@ -196,8 +251,52 @@ type Backend struct {
 	HeaderMutation *HTTPHeaderMutation `json:"httpHeaderMutation,omitempty"`
 	// Body mutations to be applied to the request before sending to the backend. Optional.
 	BodyMutation *HTTPBodyMutation `json:"httpBodyMutation,omitempty"`
+	// Admission is the admission control configuration of the AIServiceBackend. Optional.
+	Admission *BackendAdmission `json:"admission,omitempty"`
+	// PriorityClass specifies how the priority class of the requests routed to this backend is determined.
+	// This is configured per route. Optional.
+	PriorityClass *PriorityClassConfig `json:"priorityClass,omitempty"`
+}
+
+// BackendAdmission corresponds to AIServiceBackendAdmission in api/v1beta1/ai_service_backend.go.
+type BackendAdmission struct {
+	// Key identifies the AIServiceBackend in the form of "namespace/name". The admission signals are
+	// tracked per key, so that all the routes referencing the same AIServiceBackend share them.
+	Key string `json:"key"`
+	// Window is the duration over which the signals are tracked.
+	Window time.Duration `json:"window"`
+	// ThrottledPercentThreshold is the percentage of 429 responses at which the backend is under pressure.
+	ThrottledPercentThreshold uint32 `json:"throttledPercentThreshold"`
+	// LatencyThreshold is the average latency at which the backend is under pressure. Zero disables it.
+	LatencyThreshold time.Duration `json:"latencyThreshold,omitempty"`
+	// MaxQueueSize is the maximum number of requests waiting for admission.
+	MaxQueueSize uint32 `json:"maxQueueSize"`
+	// QueueTimeout is the maximum duration a request waits for admission.
+	QueueTimeout time.Duration `json:"queueTimeout"`
 }
 
+// PriorityClassConfig corresponds to AIGatewayRoutePriorityClass in api/v1beta1/ai_gateway_route.go.
+type PriorityClassConfig struct {
+	// Default is the priority class of the requests without a valid header or JWT claim.
+	Default PriorityClass `json:"default"`
+	// Header is the name of the request header carrying the priority class. Optional.
+	Header string `json:"header,omitempty"`
+	// JWTClaim is the dot-separated path of the JWT claim carrying the priority class. Optional.
+	JWTClaim string `json:"jwtClaim,omitempty"`
+}
+
+// PriorityClass is the priority class of a request used by the backend admission control.
+type PriorityClass string
+
+const (
+	// PriorityClassHigh is never queued nor shed.
+	PriorityClassHigh PriorityClass = "High"
+	// PriorityClassNormal is the default priority class.
+	PriorityClassNormal PriorityClass = "Normal"
+	// PriorityClassLow is queued and shed first.
+	PriorityClassLow PriorityClass = "Low"
+)
+
 // BackendAuth corresponds partially to BackendSecurityPolicy in api/v1alpha1/api.go.
 type BackendAuth struct {
 	// APIKey is a location of the api key secret file.
@@ -5,6 +5,8 @@
 
 package filterapi
 
+import "time"
+
 // MCPConfig is the configuration for the MCP listener and routing.
 type MCPConfig struct {
 	// BackendListenerAddr is the address that speaks plain HTTP and can be used to
@@ -33,22 +35,240 @@ type MCPRoute struct {
 
 	// ForwardHeaders specifies HTTP headers to extract from the incoming request and forward to backend MCP servers.
 	ForwardHeaders []string `json:"forwardHeaders,omitempty"`
+
+	// RateLimits limit the number of calls to the tools of the backends of this route per client.
+	RateLimits []MCPToolRateLimit `json:"rateLimits,omitempty"`
+
+	// ToolSearch replaces the tools of the backends of this route with the search_tools and call_tool meta tools.
+	ToolSearch *MCPToolSearch `json:"toolSearch,omitempty"`
+}
+
+// MCPToolSearch is the configuration of the tool search of a route.
+type MCPToolSearch struct {
+	// MaxResults is the maximum number of tools returned by a search.
+	MaxResults int `json:"maxResults"`
+	// Embeddings ranks the tools by semantic similarity in addition to keywords, if set.
+	Embeddings *MCPToolSearchEmbeddings `json:"embeddings,omitempty"`
+}
+
+// MCPToolSearchEmbeddings is the embeddings model used to search the tools.
+type MCPToolSearchEmbeddings struct {
+	// URL is the URL of an OpenAI-compatible embeddings endpoint.
+	URL string `json:"url"`
+	// Model is the name of the embeddings model.
+	Model string `json:"model"`
+}
+
+// MCPToolRateLimit limits the number of calls to the tools of a backend per client in a fixed time window.
+type MCPToolRateLimit struct {
+	// Backend is the name of the backend whose tools are limited.
+	Backend MCPBackendName `json:"backend"`
+	// Tools are the upstream names of the limited tools. Empty means all the tools of the backend.
+	Tools []string `json:"tools,omitempty"`
+	// Calls is the maximum number of calls to each tool allowed per client in a window.
+	Calls uint32 `json:"calls"`
+	// Window is the duration of the window.
+	Window time.Duration `json:"window"`
+	// ClientKeyType specifies how the clients are identified.
+	ClientKeyType MCPToolRateLimitClientKeyType `json:"clientKeyType"`
+	// ClientKeyHeader is the request header identifying the clients when ClientKeyType is header.
+	ClientKeyHeader string `json:"clientKeyHeader,omitempty"`
 }
 
+// MCPToolRateLimitClientKeyType specifies how the clients of a rate limit are identified.
+type MCPToolRateLimitClientKeyType string
+
+const (
+	// MCPToolRateLimitClientKeyTypeJWTSubject identifies the clients by the subject of their bearer token.
+	MCPToolRateLimitClientKeyTypeJWTSubject MCPToolRateLimitClientKeyType = "JWTSubject"
+	// MCPToolRateLimitClientKeyTypeHeader identifies the clients by the value of a request header.
+	MCPToolRateLimitClientKeyTypeHeader MCPToolRateLimitClientKeyType = "Header"
+	// MCPToolRateLimitClientKeyTypeSession identifies the clients by their MCP session.
+	MCPToolRateLimitClientKeyTypeSession MCPToolRateLimitClientKeyType = "Session"
+)
+
 // MCPBackend is the MCP backend configuration.
 type MCPBackend struct {
 	// Name is the fully qualified identifier of a MCP backend.
 	// This name is set in [internalapi.MCPBackendHeader] header to route the request to the specific backend.
 	Name MCPBackendName `json:"name"`
 
+	// OpenAPI exposes the operations of a REST service described by an OpenAPI document as the tools of this
+	// backend. If set, the backend doesn't speak MCP, and the tool calls are translated into HTTP requests.
+	OpenAPI *MCPOpenAPIBackend `json:"openAPI,omitempty"`
+
+	// Transport is the MCP transport spoken by this backend. Empty means [MCPBackendTransportStreamableHTTP].
+	Transport MCPBackendTransport `json:"transport,omitempty"`
+
+	// Path is the path of the SSE endpoint of the backend. Only set with [MCPBackendTransportSSE], as the requests to
+	// such backends are not rewritten by Envoy: the messages are posted to the endpoint advertised by the server.
+	Path string `json:"path,omitempty"`
+
 	// ToolSelector filters the tools exposed by this backend. If not set, all tools are exposed.
 	ToolSelector *MCPToolSelector `json:"toolSelector,omitempty"`
 
+	// ResourceSelector filters the resources exposed by this backend by URI. If not set, all resources are exposed.
+	ResourceSelector *MCPResourceSelector `json:"resourceSelector,omitempty"`
+
+	// PromptSelector filters the prompts exposed by this backend by name. If not set, all prompts are exposed.
+	PromptSelector *MCPPromptSelector `json:"promptSelector,omitempty"`
+
 	// ForwardHeaders specifies HTTP headers to extract from the incoming request and forward to this backend.
 	// Each entry maps a source header name to an optional destination header name.
 	ForwardHeaders []MCPHeaderForward `json:"forwardHeaders,omitempty"`
+
+	// ToolPinning pins the tool definitions of this backend to the approved fingerprints. If not set, the tool
+	// definitions are not checked.
+	ToolPinning *MCPToolPinning `json:"toolPinning,omitempty"`
+
+	// SchemaValidation validates the tool calls to this backend against the schemas of the tools. If not set,
+	// the tool calls are not validated.
+	SchemaValidation *MCPSchemaValidation `json:"schemaValidation,omitempty"`
+
+	// ToolNamePrefix is the prefix of the tool names of this backend exposed to the clients. If not set, the tool
+	// names are prefixed with the backend name followed by "__".
+	ToolNamePrefix *MCPToolNamePrefix `json:"toolNamePrefix,omitempty"`
+
+	// ToolOverrides customizes the tools of this backend exposed to the clients.
+	ToolOverrides []MCPToolOverride `json:"toolOverrides,omitempty"`
+
+	// ToolApproval requires the calls to some of the tools of this backend to be approved before being forwarded.
+	// If not set, the tool calls are forwarded without approval.
+	ToolApproval *MCPToolApproval `json:"toolApproval,omitempty"`
+
+	// OAuthTokenExchange exchanges the bearer token of the incoming request for a token of this backend.
+	// If not set, the incoming token is not sent to this backend.
+	OAuthTokenExchange *MCPBackendOAuthTokenExchange `json:"oauthTokenExchange,omitempty"`
+}
+
+// MCPBackendOAuthTokenExchange is the configuration to exchange the user token for a token of a backend.
+type MCPBackendOAuthTokenExchange struct {
+	// TokenEndpoint is the URL of the token endpoint that performs the exchange.
+	TokenEndpoint string `json:"tokenEndpoint"`
+	// GrantType is the OAuth grant used to exchange the token. Empty means [MCPOAuthGrantTypeTokenExchange].
+	GrantType MCPOAuthGrantType `json:"grantType,omitempty"`
+	// SubjectTokenType is the type of the user token. Empty means the access token type.
+	SubjectTokenType string `json:"subjectTokenType,omitempty"`
+	// RequestedTokenType is the type of the token to issue.
+	RequestedTokenType string `json:"requestedTokenType,omitempty"`
+	// Audience is the logical name of the backend the token is requested for.
+	Audience string `json:"audience,omitempty"`
+	// Resource is the URI of the backend the token is requested for.
+	Resource string `json:"resource,omitempty"`
+	// Scopes is the list of scopes to request for the exchanged token.
+	Scopes []string `json:"scopes,omitempty"`
+	// ClientID is the client identifier of the gateway at the token endpoint.
+	ClientID string `json:"clientID,omitempty"`
+	// ClientSecret is the client secret of the gateway at the token endpoint as a literal string.
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// MCPOAuthGrantType is the OAuth grant used to exchange a token.
+type MCPOAuthGrantType string
+
+const (
+	// MCPOAuthGrantTypeTokenExchange is the RFC 8693 token exchange grant.
+	MCPOAuthGrantTypeTokenExchange MCPOAuthGrantType = "TokenExchange"
+	// MCPOAuthGrantTypeOnBehalfOf is the on-behalf-of flow based on the RFC 7523 JWT bearer grant.
+	MCPOAuthGrantTypeOnBehalfOf MCPOAuthGrantType = "OnBehalfOf"
+)
+
+// MCPBackendTransport is the MCP transport spoken by a backend.
+type MCPBackendTransport string
+
+const (
+	// MCPBackendTransportStreamableHTTP is the Streamable HTTP transport.
+	MCPBackendTransportStreamableHTTP MCPBackendTransport = "StreamableHTTP"
+	// MCPBackendTransportSSE is the legacy HTTP+SSE transport of the 2024-11-05 MCP specification.
+	MCPBackendTransportSSE MCPBackendTransport = "SSE"
+)
+
+// MCPOpenAPIBackend is a REST service described by an OpenAPI document exposed as a virtual MCP backend.
+type MCPOpenAPIBackend struct {
+	// Document is the OpenAPI 3 document in JSON or YAML.
+	Document string `json:"document"`
+	// BasePath is prepended to the paths of the operations. Empty means the path of the first server URL of the
+	// document, if any.
+	BasePath string `json:"basePath,omitempty"`
+	// Operations is the list of the operationIds of the operations exposed as tools. Empty means all the operations
+	// that have an operationId.
+	Operations []string `json:"operations,omitempty"`
+}
+
+// MCPToolPinning is the approved baseline of the tool definitions of a backend.
+type MCPToolPinning struct {
+	// Action is the action taken on the tools whose definition doesn't match the approved fingerprint.
+	Action MCPToolPinningAction `json:"action"`
+	// Fingerprints maps the tool names to the fingerprints of their approved definition.
+	// Tools that are not in the map are not approved.
+	Fingerprints map[string]string `json:"fingerprints,omitempty"`
+}
+
+// MCPToolPinningAction is the action taken on the tools whose definition doesn't match the approved fingerprint.
+type MCPToolPinningAction string
+
+const (
+	// MCPToolPinningActionDrop removes the changed tools from the tool lists.
+	MCPToolPinningActionDrop MCPToolPinningAction = "Drop"
+	// MCPToolPinningActionFlag flags the changed tools in the tool lists.
+	MCPToolPinningActionFlag MCPToolPinningAction = "Flag"
+	// MCPToolPinningActionBlock removes the changed tools from the tool lists, and rejects the calls to them.
+	MCPToolPinningActionBlock MCPToolPinningAction = "Block"
+)
+
+// MCPToolNamePrefix is the prefix of the tool names of a backend.
+type MCPToolNamePrefix struct {
+	// Disabled exposes the tool names without the prefix.
+	Disabled bool `json:"disabled,omitempty"`
+	// Separator separates the backend name and the tool name. Empty means "__".
+	Separator string `json:"separator,omitempty"`
 }
 
+// MCPToolOverride customizes a tool of a backend.
+type MCPToolOverride struct {
+	// Name is the name of the tool as exposed by the backend.
+	Name string `json:"name"`
+	// Rename is the name of the tool exposed to the clients, before the prefix is prepended. Empty means the
+	// tool is not renamed.
+	Rename string `json:"rename,omitempty"`
+	// Description replaces the description of the tool if not empty.
+	Description string `json:"description,omitempty"`
+	// AppendDescription is appended to the description of the tool if not empty.
+	AppendDescription string `json:"appendDescription,omitempty"`
+	// FixedArguments maps the parameter names to the JSON encoded values injected into the tool calls.
+	FixedArguments map[string]string `json:"fixedArguments,omitempty"`
+}
+
+// MCPSchemaValidation is the validation of the tool calls of a backend against the schemas of the tools.
+type MCPSchemaValidation struct {
+	// Arguments enables the validation of the tool call arguments against the input schemas.
+	Arguments bool `json:"arguments,omitempty"`
+	// StructuredContent is the action taken on the structured content of the tool call results that doesn't match
+	// the output schemas. Empty means the results are not validated.
+	StructuredContent MCPStructuredContentValidationAction `json:"structuredContent,omitempty"`
+}
+
+// MCPToolApproval is the approval of the calls to the tools of a backend.
+type MCPToolApproval struct {
+	// Tools is the list of the upstream names of the tools whose calls must be approved.
+	Tools []string `json:"tools"`
+	// Timeout is the maximum time to wait for the approval. Zero means the default of the proxy.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// WebhookURL is the URL of the webhook asked to approve the calls of the clients that don't support
+	// elicitation. Empty means such calls are rejected.
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+// MCPStructuredContentValidationAction is the action taken on the structured content that doesn't match the output schema.
+type MCPStructuredContentValidationAction string
+
+const (
+	// MCPStructuredContentValidationActionFlag flags the invalid structured content in the result.
+	MCPStructuredContentValidationActionFlag MCPStructuredContentValidationAction = "Flag"
+	// MCPStructuredContentValidationActionStrip removes the invalid structured content from the result.
+	MCPStructuredContentValidationActionStrip MCPStructuredContentValidationAction = "Strip"
+)
+
 // MCPHeaderForward specifies a header to extract from the incoming request and forward to a backend.
 type MCPHeaderForward struct {
 	// Name is the header name to extract from the incoming client request.
@@ -90,6 +310,12 @@ type MCPToolSelector struct {
 	ExcludeRegex []string `json:"excludeRegex,omitempty"`
 }
 
+// MCPResourceSelector filters resources by URI with the same include and exclude semantics as [MCPToolSelector].
+type MCPResourceSelector = MCPToolSelector
+
+// MCPPromptSelector filters prompts by name with the same include and exclude semantics as [MCPToolSelector].
+type MCPPromptSelector = MCPToolSelector
+
 // MCPRouteName is the name of the MCP route.
 type MCPRouteName = string
 
@@ -141,6 +367,15 @@ type MCPRouteAuthorizationRule struct {
 type MCPAuthorizationTarget struct {
 	// Tools defines the list of tools this rule applies to.
 	Tools []ToolCall `json:"tools"`
+
+	// Resources defines the list of resources this rule applies to.
+	Resources []ResourceTarget `json:"resources,omitempty"`
+
+	// Prompts defines the list of prompts this rule applies to.
+	Prompts []PromptTarget `json:"prompts,omitempty"`
+
+	// Methods defines the list of JSON-RPC methods this rule applies to.
+	Methods []MethodTarget `json:"methods,omitempty"`
 }
 
 type MCPAuthorizationSource struct {
@@ -186,3 +421,27 @@ type ToolCall struct {
 	// Tool is the name of the tool.
 	Tool string `json:"tool"`
 }
+
+type ResourceTarget struct {
+	// Backend is the name of the backend these resources belong to.
+	Backend string `json:"backend"`
+
+	// URI is the URI pattern of the resources, where "*" matches any sequence of characters.
+	URI string `json:"uri"`
+}
+
+type PromptTarget struct {
+	// Backend is the name of the backend this prompt belongs to.
+	Backend string `json:"backend"`
+
+	// Prompt is the name of the prompt.
+	Prompt string `json:"prompt"`
+}
+
+type MethodTarget struct {
+	// Method is the JSON-RPC method.
+	Method string `json:"method"`
+
+	// Backend is the name of the backend the request is sent to. If empty, any backend matches.
+	Backend string `json:"backend,omitempty"`
+}
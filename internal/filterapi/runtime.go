@@ -8,11 +8,13 @@ package filterapi
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/google/cel-go/cel"
 
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 )
 
 // BackendAuthHandler is the interface that deals with the backend auth for a specific backend.
@@ -45,6 +47,47 @@ type RuntimeConfig struct {
 	UnscopedModels []Model
 	// Backends is the map of backends by name.
 	Backends map[string]*RuntimeBackend
+	// QuotaSelectors is the compiled QuotaSelectors configuration. Nil when not configured.
+	QuotaSelectors *RuntimeQuotaSelectors
+	// ConcurrencyLimits is the list of concurrency limits with the compiled header matches.
+	ConcurrencyLimits []RuntimeConcurrencyLimit
+}
+
+// RuntimeConcurrencyLimit is derived from the filterapi.ConcurrencyLimit configuration.
+type RuntimeConcurrencyLimit struct {
+	*ConcurrencyLimit
+	// Headers is the list of the header matches with the compiled regular expressions.
+	Headers []RuntimeConcurrencyLimitHeaderMatch
+}
+
+// RuntimeConcurrencyLimitHeaderMatch is a ConcurrencyLimitHeaderMatch with the compiled regular expression.
+type RuntimeConcurrencyLimitHeaderMatch struct {
+	*ConcurrencyLimitHeaderMatch
+	// Regexp is non-nil for the RegularExpression type.
+	Regexp *regexp.Regexp
+}
+
+// RuntimeQuotaSelectors is derived from the filterapi.QuotaSelectors configuration.
+type RuntimeQuotaSelectors struct {
+	// JWTClaims is the list of JWT claim selectors.
+	JWTClaims []RuntimeQuotaClaimSelector
+	// CELs is the list of CEL selectors with the compiled CEL programs.
+	CELs []RuntimeQuotaCELSelector
+}
+
+// RuntimeQuotaClaimSelector is a JWT claim referenced by the QuotaPolicy client selectors.
+type RuntimeQuotaClaimSelector struct {
+	// Name is the claim name.
+	Name string
+	// Header is the internal request header carrying the claim value.
+	Header string
+}
+
+// RuntimeQuotaCELSelector is a CEL expression referenced by the QuotaPolicy client selectors.
+type RuntimeQuotaCELSelector struct {
+	// Header is the internal request header carrying the result of the expression.
+	Header  string
+	CELProg cel.Program
 }
 
 // RuntimeBackend is a filter backend with its auth handler that is derived from the filterapi.Backend configuration.
@@ -123,6 +166,46 @@ func NewRuntimeConfig(ctx context.Context, config *Config, fn NewBackendAuthHand
 		costs = append(costs, RuntimeRequestCost{LLMRequestCost: c, CELProg: prog})
 	}
 
+	var quotaSelectors *RuntimeQuotaSelectors
+	if qs := config.QuotaSelectors; qs != nil {
+		quotaSelectors = &RuntimeQuotaSelectors{}
+		for _, claim := range qs.JWTClaims {
+			quotaSelectors.JWTClaims = append(quotaSelectors.JWTClaims, RuntimeQuotaClaimSelector{
+				Name:   claim,
+				Header: quotaselector.ClaimHeaderName(claim),
+			})
+		}
+		for _, expr := range qs.CELExpressions {
+			prog, err := quotaselector.NewCELProgram(expr)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create CEL program for quota selector: %w", err)
+			}
+			quotaSelectors.CELs = append(quotaSelectors.CELs, RuntimeQuotaCELSelector{
+				Header:  quotaselector.CELHeaderName(expr),
+				CELProg: prog,
+			})
+		}
+	}
+
+	concurrencyLimits := make([]RuntimeConcurrencyLimit, 0, len(config.ConcurrencyLimits))
+	for i := range config.ConcurrencyLimits {
+		l := &config.ConcurrencyLimits[i]
+		headers := make([]RuntimeConcurrencyLimitHeaderMatch, 0, len(l.Headers))
+		for j := range l.Headers {
+			h := &l.Headers[j]
+			var re *regexp.Regexp
+			if h.Type == ConcurrencyLimitHeaderMatchTypeRegularExpression {
+				var err error
+				re, err = regexp.Compile(h.Value)
+				if err != nil {
+					return nil, fmt.Errorf("cannot compile regular expression for concurrency limit %s: %w", l.Name, err)
+				}
+			}
+			headers = append(headers, RuntimeConcurrencyLimitHeaderMatch{ConcurrencyLimitHeaderMatch: h, Regexp: re})
+		}
+		concurrencyLimits = append(concurrencyLimits, RuntimeConcurrencyLimit{ConcurrencyLimit: l, Headers: headers})
+	}
+
 	return &RuntimeConfig{
 		UUID:               config.UUID,
 		Backends:           backends,
@@ -131,5 +214,7 @@ func NewRuntimeConfig(ctx context.Context, config *Config, fn NewBackendAuthHand
 		DeclaredModels:     config.Models,
 		ModelsByHost:       config.ModelsByHost,
 		UnscopedModels:     config.UnscopedModels,
+		QuotaSelectors:     quotaSelectors,
+		ConcurrencyLimits:  concurrencyLimits,
 	}, nil
 }
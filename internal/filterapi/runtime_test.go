@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 )
 
 func TestServer_LoadConfig(t *testing.T) {
@@ -131,4 +132,70 @@ func TestServer_LoadConfig(t *testing.T) {
 		require.Contains(t, err.Error(), "must have non-empty RouteName")
 		require.Contains(t, err.Error(), "missing_route")
 	})
+
+	t.Run("with quota selectors", func(t *testing.T) {
+		config := &Config{
+			QuotaSelectors: &QuotaSelectors{
+				JWTClaims:      []string{"sub", "org.id"},
+				CELExpressions: []string{`request.model == "gpt-4o"`},
+			},
+		}
+		rc, err := NewRuntimeConfig(t.Context(), config, func(_ context.Context, _ *BackendAuth) (BackendAuthHandler, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.NotNil(t, rc.QuotaSelectors)
+		require.Equal(t, []RuntimeQuotaClaimSelector{
+			{Name: "sub", Header: "x-ai-eg-quota-claim-sub"},
+			{Name: "org.id", Header: "x-ai-eg-quota-claim-org.id"},
+		}, rc.QuotaSelectors.JWTClaims)
+		require.Len(t, rc.QuotaSelectors.CELs, 1)
+		require.Equal(t, quotaselector.CELHeaderName(`request.model == "gpt-4o"`), rc.QuotaSelectors.CELs[0].Header)
+		require.NotNil(t, rc.QuotaSelectors.CELs[0].CELProg)
+	})
+
+	t.Run("error - invalid CEL in quota selector", func(t *testing.T) {
+		config := &Config{QuotaSelectors: &QuotaSelectors{CELExpressions: []string{"request.model =="}}}
+		_, err := NewRuntimeConfig(t.Context(), config, func(_ context.Context, _ *BackendAuth) (BackendAuthHandler, error) {
+			return nil, nil
+		})
+		require.ErrorContains(t, err, "cannot create CEL program for quota selector")
+	})
+
+	t.Run("with concurrency limits", func(t *testing.T) {
+		config := &Config{
+			ConcurrencyLimits: []ConcurrencyLimit{{
+				Name:   "ns/policy/llama/0",
+				Models: []string{"llama"},
+				Headers: []ConcurrencyLimitHeaderMatch{
+					{Name: "x-tenant", Type: ConcurrencyLimitHeaderMatchTypeDistinct},
+					{Name: "x-tier", Type: ConcurrencyLimitHeaderMatchTypeRegularExpression, Value: "^(free|trial)$"},
+				},
+				MaxConcurrentRequests: 4,
+				RetryAfterSeconds:     2,
+			}},
+		}
+		rc, err := NewRuntimeConfig(t.Context(), config, func(_ context.Context, _ *BackendAuth) (BackendAuthHandler, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.Len(t, rc.ConcurrencyLimits, 1)
+		l := rc.ConcurrencyLimits[0]
+		require.Equal(t, "ns/policy/llama/0", l.Name)
+		require.Len(t, l.Headers, 2)
+		require.Nil(t, l.Headers[0].Regexp)
+		require.NotNil(t, l.Headers[1].Regexp)
+		require.True(t, l.Headers[1].Regexp.MatchString("trial"))
+	})
+
+	t.Run("error - invalid regular expression in concurrency limit", func(t *testing.T) {
+		config := &Config{ConcurrencyLimits: []ConcurrencyLimit{{
+			Name:    "ns/policy/llama/0",
+			Headers: []ConcurrencyLimitHeaderMatch{{Name: "x-tier", Type: ConcurrencyLimitHeaderMatchTypeRegularExpression, Value: "("}},
+		}}}
+		_, err := NewRuntimeConfig(t.Context(), config, func(_ context.Context, _ *BackendAuth) (BackendAuthHandler, error) {
+			return nil, nil
+		})
+		require.ErrorContains(t, err, "cannot compile regular expression for concurrency limit ns/policy/llama/0")
+	})
 }
@@ -37,6 +37,12 @@ const (
 	MCPBackendListenerPort = 10088
 	// MCPProxyPort is the port where the MCP proxy listens.
 	MCPProxyPort = 9856
+	// MCPLegacySSEPathSuffix is appended to the path of an MCPRoute to serve the SSE stream of the clients using the
+	// legacy HTTP+SSE transport.
+	MCPLegacySSEPathSuffix = "/sse"
+	// MCPLegacyMessagesPathSuffix is appended to the path of an MCPRoute to receive the messages of the clients using
+	// the legacy HTTP+SSE transport.
+	MCPLegacyMessagesPathSuffix = "/messages"
 	// MCPGeneratedResourceCommonPrefix is the common prefix for all MCP-related generated resources.
 	MCPGeneratedResourceCommonPrefix = "ai-eg-mcp-"
 	// MCPMainHTTPRoutePrefix is the prefix for the main HTTPRoute resources generated for MCP.
@@ -15,6 +15,10 @@ var (
 	config = sonicjson.Config{
 		CaseSensitive: true,
 	}.Froze()
+	sortedKeysConfig = sonicjson.Config{
+		CaseSensitive: true,
+		SortMapKeys:   true,
+	}.Froze()
 
 	// Unmarshal is equivalent to encoding/json.Unmarshal.
 	Unmarshal = config.Unmarshal
@@ -24,6 +28,11 @@ var (
 	NewEncoder = config.NewEncoder
 	// NewDecoder is equivalent to encoding/json.NewDecoder.
 	NewDecoder = config.NewDecoder
+	// MarshalSortedKeys is equivalent to encoding/json.Marshal, which sorts the map keys so that
+	// equal values are always marshaled to the same bytes.
+	MarshalSortedKeys = sortedKeysConfig.Marshal
+	// MarshalIndentSortedKeys is equivalent to encoding/json.MarshalIndent, which sorts the map keys.
+	MarshalIndentSortedKeys = sortedKeysConfig.MarshalIndent
 	// MarshalForDeterministicTesting marshals a value to JSON in a deterministic way for testing.
 	// The normal sonic configuration does not guarantee deterministic output in terms of field order.
 	// It panics if called outside of tests.
@@ -30,3 +30,20 @@ func CaseInsensitiveValue(m map[string]any, key string) string {
 
 	return ""
 }
+
+// NestedValue looks up a value in nested maps using a dot-separated path, e.g. "realm_access.roles".
+// It returns false if any segment of the path is missing or an intermediate value is not a map.
+func NestedValue(m map[string]any, path string) (any, bool) {
+	current := any(m)
+	for _, part := range strings.Split(path, ".") {
+		next, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = next[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
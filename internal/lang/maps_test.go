@@ -61,3 +61,32 @@ func TestCaseInsensitiveValue(t *testing.T) {
 		})
 	}
 }
+
+func TestNestedValue(t *testing.T) {
+	m := map[string]any{
+		"sub": "user-1",
+		"org": map[string]any{
+			"id":    "acme",
+			"roles": []any{"admin"},
+		},
+	}
+	tests := []struct {
+		name   string
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{name: "top level", path: "sub", want: "user-1", wantOK: true},
+		{name: "nested", path: "org.id", want: "acme", wantOK: true},
+		{name: "missing", path: "org.name", wantOK: false},
+		{name: "not a map", path: "sub.id", wantOK: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := NestedValue(m, tc.path)
+			if ok != tc.wantOK || (ok && got != tc.want) {
+				t.Fatalf("NestedValue(%q) = (%v, %v); want (%v, %v)", tc.path, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
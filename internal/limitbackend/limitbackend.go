@@ -0,0 +1,62 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package limitbackend configures where the state of the limits enforced in process is kept: either in the memory
+// of each replica, or in a Redis server shared by all the replicas.
+package limitbackend
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Type is the kind of storage of the state of the limits.
+type Type string
+
+const (
+	// TypeLocal keeps the state in memory. Limits are enforced per replica.
+	TypeLocal Type = "local"
+	// TypeRedis keeps the state in Redis. Limits are shared by all replicas.
+	TypeRedis Type = "redis"
+)
+
+// Config configures the backend of the limits.
+type Config struct {
+	// Backend is the type of the backend.
+	Backend Type
+	// RedisURL is the URL of the Redis server for TypeRedis, for example "redis://:password@localhost:6379/0".
+	RedisURL string
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	switch c.Backend {
+	case TypeLocal:
+	case TypeRedis:
+		if c.RedisURL == "" {
+			return fmt.Errorf("a Redis URL is required for the %q backend", c.Backend)
+		}
+		if _, err := redis.ParseURL(c.RedisURL); err != nil {
+			return fmt.Errorf("invalid Redis URL: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown backend %q: must be one of local or redis", c.Backend)
+	}
+	return nil
+}
+
+// NewRedisClient returns a client of the Redis server of the TypeRedis configuration.
+func (c *Config) NewRedisClient() (*redis.Client, error) {
+	if c.Backend != TypeRedis {
+		return nil, errors.New("not a Redis backend")
+	}
+	opts, err := redis.ParseURL(c.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	return redis.NewClient(opts), nil
+}
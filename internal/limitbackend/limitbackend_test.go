@@ -0,0 +1,62 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package limitbackend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cfg    Config
+		errMsg string
+	}{
+		{name: "local", cfg: Config{Backend: TypeLocal}},
+		{name: "redis", cfg: Config{Backend: TypeRedis, RedisURL: "redis://localhost:6379/0"}},
+		{
+			name:   "redis without URL",
+			cfg:    Config{Backend: TypeRedis},
+			errMsg: `a Redis URL is required for the "redis" backend`,
+		},
+		{
+			name:   "redis with invalid URL",
+			cfg:    Config{Backend: TypeRedis, RedisURL: "http://localhost"},
+			errMsg: "invalid Redis URL",
+		},
+		{
+			name:   "unknown",
+			cfg:    Config{Backend: "memcached"},
+			errMsg: `unknown backend "memcached": must be one of local or redis`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestConfig_NewRedisClient(t *testing.T) {
+	cfg := Config{Backend: TypeRedis, RedisURL: "redis://:secret@localhost:6379/2"}
+	client, err := cfg.NewRedisClient()
+	require.NoError(t, err)
+	require.Equal(t, "localhost:6379", client.Options().Addr)
+	require.Equal(t, "secret", client.Options().Password)
+	require.Equal(t, 2, client.Options().DB)
+	require.NoError(t, client.Close())
+
+	_, err = (&Config{Backend: TypeLocal}).NewRedisClient()
+	require.EqualError(t, err, "not a Redis backend")
+	_, err = (&Config{Backend: TypeRedis, RedisURL: "http://localhost"}).NewRedisClient()
+	require.ErrorContains(t, err, "invalid Redis URL")
+}
@@ -0,0 +1,327 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// codeToolCallNotApproved is the JSON-RPC error code of the tool calls that have not been approved. It is in the
+	// range reserved for the implementation-defined server errors.
+	codeToolCallNotApproved int64 = -32030
+	// defaultToolApprovalTimeout is the time to wait for an approval when the backend doesn't configure it.
+	defaultToolApprovalTimeout = time.Minute
+	// maxToolApprovalWebhookResponseSize is the maximum size of the responses of the approval webhooks.
+	maxToolApprovalWebhookResponseSize = 64 * 1024
+
+	envoyAIGatewayServerToClientApprovalRequestIDPrefix = "aigw-server-to-client-approval"
+
+	toolApprovalMethodElicitation = "elicitation"
+	toolApprovalMethodWebhook     = "webhook"
+	toolApprovalMethodNone        = "none"
+
+	toolApprovalOutcomeApproved = "approved"
+	toolApprovalOutcomeDenied   = "denied"
+	toolApprovalOutcomeTimeout  = "timeout"
+	toolApprovalOutcomeError    = "error"
+)
+
+type (
+	// pendingToolApproval is an approval requested from a client with an elicitation request, waiting for the
+	// response of the client. The response is POSTed by the client in a separate HTTP request, so it only reaches
+	// the pending approval if it is sent to the same replica of the gateway.
+	pendingToolApproval struct {
+		// sessionID is the session of the tool call. Only the responses sent in the same session are accepted.
+		sessionID secureClientToGatewaySessionID
+		response  chan *jsonrpc.Response
+	}
+
+	// toolApprovalWebhookRequest is the body of the requests sent to the approval webhooks.
+	toolApprovalWebhookRequest struct {
+		Route     filterapi.MCPRouteName   `json:"route"`
+		Backend   filterapi.MCPBackendName `json:"backend"`
+		Tool      string                   `json:"tool"`
+		Arguments any                      `json:"arguments,omitempty"`
+		Subject   string                   `json:"subject,omitempty"`
+	}
+
+	// toolApprovalWebhookResponse is the body of the responses of the approval webhooks.
+	toolApprovalWebhookResponse struct {
+		Approved bool   `json:"approved"`
+		Reason   string `json:"reason,omitempty"`
+	}
+)
+
+// requiresToolApproval returns true if the calls to the given upstream tool of the backend must be approved.
+func requiresToolApproval(backend filterapi.MCPBackend, tool string) bool {
+	return backend.ToolApproval != nil && slices.Contains(backend.ToolApproval.Tools, tool)
+}
+
+// approveToolCall requests the approval of the call to the given upstream tool of the backend, with an elicitation
+// request if the client supports it, or from the approval webhook otherwise.
+//
+// When the approval is requested with an elicitation request, the response has been started as a stream of
+// server-sent events, and the result of the call must be written to the returned writer, which must be finished
+// afterward. When the call is not approved, the JSON-RPC error has been sent to the client and is returned.
+func (m *mcpRequestContext) approveToolCall(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request,
+	backend filterapi.MCPBackend, tool string, p *mcp.CallToolParams, headers http.Header,
+) (*sseResponseWriter, error) {
+	approval := backend.ToolApproval
+	timeout := cmp.Or(approval.Timeout, defaultToolApprovalTimeout)
+	var (
+		method, outcome, reason string
+		sw                      *sseResponseWriter
+	)
+	switch {
+	case s.supportsElicitation():
+		method = toolApprovalMethodElicitation
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set(sessionIDHeader, string(s.clientGatewaySessionID()))
+		w.WriteHeader(http.StatusOK)
+		sw = newSSEResponseWriter(w, req)
+		outcome, reason = m.elicitToolApproval(ctx, s, w, p, timeout)
+	case approval.WebhookURL != "":
+		method = toolApprovalMethodWebhook
+		outcome, reason = m.callToolApprovalWebhook(ctx, approval.WebhookURL, &toolApprovalWebhookRequest{
+			Route:     s.route,
+			Backend:   backend.Name,
+			Tool:      tool,
+			Arguments: p.Arguments,
+			Subject:   jwtSubject(headers),
+		}, timeout)
+	default:
+		method, outcome, reason = toolApprovalMethodNone, toolApprovalOutcomeDenied, "the client does not support elicitation"
+	}
+	m.metrics.WithBackend(backend.Name).RecordToolApproval(ctx, tool, method, outcome)
+	if outcome == toolApprovalOutcomeApproved {
+		return sw, nil
+	}
+
+	m.l.Info("tool call not approved", slog.String("backend", backend.Name), slog.String("tool", tool),
+		slog.String("method", method), slog.String("outcome", outcome), slog.String("reason", reason))
+	message := fmt.Sprintf("call to tool %s was not approved: %s", p.Name, reason)
+	if sw == nil {
+		return nil, onJSONRPCErrorResponse(w, req, codeToolCallNotApproved, message)
+	}
+	rpcErr := &jsonrpc.Error{Code: codeToolCallNotApproved, Message: message}
+	event := &sseEvent{event: "message", messages: []jsonrpc.Message{&jsonrpc.Response{ID: req.ID, Error: rpcErr}}}
+	event.writeAndMaybeFlush(w)
+	return nil, rpcErr
+}
+
+// elicitToolApproval sends an elicitation request showing the tool call to the client on the given stream, and waits
+// for the response of the client. It returns the outcome of the approval and the reason of the denial, if any.
+func (m *mcpRequestContext) elicitToolApproval(ctx context.Context, s *session, w http.ResponseWriter, p *mcp.CallToolParams, timeout time.Duration) (outcome, reason string) {
+	args := []byte("{}")
+	if p.Arguments != nil {
+		args, _ = json.Marshal(p.Arguments)
+	}
+	params, _ := json.Marshal(&mcp.ElicitParams{
+		Mode:    "form",
+		Message: fmt.Sprintf("The tool %s is about to be called with the arguments %s. Do you approve this call?", p.Name, args),
+		// Nothing to fill in: the user only accepts or declines the call.
+		RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+	})
+
+	requestID := envoyAIGatewayServerToClientApprovalRequestIDPrefix + uuid.NewString()
+	pending := &pendingToolApproval{sessionID: s.clientGatewaySessionID(), response: make(chan *jsonrpc.Response, 1)}
+	m.pendingToolApprovals.Store(requestID, pending)
+	defer m.pendingToolApprovals.Delete(requestID)
+
+	id, _ := jsonrpc.MakeID(requestID)
+	event := &sseEvent{event: "message", messages: []jsonrpc.Message{&jsonrpc.Request{ID: id, Method: "elicitation/create", Params: params}}}
+	event.writeAndMaybeFlush(w)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case resp := <-pending.response:
+		return elicitationOutcome(resp)
+	case <-timer.C:
+		return toolApprovalOutcomeTimeout, fmt.Sprintf("no approval within %s", timeout)
+	case <-ctx.Done():
+		return toolApprovalOutcomeTimeout, "the request was cancelled while waiting for the approval"
+	}
+}
+
+// elicitationOutcome returns the outcome of an approval from the response of the client to the elicitation request.
+func elicitationOutcome(resp *jsonrpc.Response) (outcome, reason string) {
+	if resp.Error != nil {
+		return toolApprovalOutcomeError, fmt.Sprintf("elicitation failed: %v", resp.Error)
+	}
+	var result mcp.ElicitResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return toolApprovalOutcomeError, "invalid elicitation result"
+	}
+	switch result.Action {
+	case "accept":
+		return toolApprovalOutcomeApproved, ""
+	case "decline":
+		return toolApprovalOutcomeDenied, "declined by the user"
+	default:
+		return toolApprovalOutcomeDenied, "cancelled by the user"
+	}
+}
+
+// isToolApprovalResponse returns true if the given response of the client answers an elicitation request sent by the
+// gateway to approve a tool call.
+func isToolApprovalResponse(msg *jsonrpc.Response) bool {
+	str, ok := msg.ID.Raw().(string)
+	return ok && strings.HasPrefix(str, envoyAIGatewayServerToClientApprovalRequestIDPrefix)
+}
+
+// deliverToolApprovalResponse hands the response of the client to the tool call waiting for it, if any.
+func (m *mcpRequestContext) deliverToolApprovalResponse(s *session, msg *jsonrpc.Response) {
+	id, _ := msg.ID.Raw().(string)
+	v, ok := m.pendingToolApprovals.Load(id)
+	if !ok {
+		// The approval may have timed out, or the tool call may be handled by another replica of the gateway.
+		m.l.Warn("received a response to an unknown tool approval request", slog.String("id", id))
+		return
+	}
+	pending := v.(*pendingToolApproval)
+	if pending.sessionID != s.clientGatewaySessionID() {
+		m.l.Warn("received a response to a tool approval request of another session", slog.String("id", id))
+		return
+	}
+	select {
+	case pending.response <- msg:
+	default: // Already answered.
+	}
+}
+
+// callToolApprovalWebhook asks the approval webhook to approve the tool call. It returns the outcome of the approval
+// and the reason of the denial, if any. The calls are denied when the webhook fails.
+func (m *mcpRequestContext) callToolApprovalWebhook(ctx context.Context, url string, call *toolApprovalWebhookRequest, timeout time.Duration) (outcome, reason string) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(call)
+	if err != nil {
+		m.l.Error("failed to marshal tool approval webhook request", slog.String("error", err.Error()))
+		return toolApprovalOutcomeError, "the approval webhook failed"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		m.l.Error("failed to create tool approval webhook request", slog.String("error", err.Error()))
+		return toolApprovalOutcomeError, "the approval webhook failed"
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return toolApprovalOutcomeTimeout, fmt.Sprintf("no approval within %s", timeout)
+		}
+		m.l.Error("failed to call tool approval webhook", slog.String("error", err.Error()))
+		return toolApprovalOutcomeError, "the approval webhook failed"
+	}
+	defer ensureHTTPConnectionReused(resp)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		m.l.Error("tool approval webhook failed", slog.Int("status_code", resp.StatusCode))
+		return toolApprovalOutcomeError, "the approval webhook failed"
+	}
+	var result toolApprovalWebhookResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxToolApprovalWebhookResponseSize)).Decode(&result); err != nil {
+		m.l.Error("invalid tool approval webhook response", slog.String("error", err.Error()))
+		return toolApprovalOutcomeError, "the approval webhook failed"
+	}
+	if result.Approved {
+		return toolApprovalOutcomeApproved, ""
+	}
+	return toolApprovalOutcomeDenied, cmp.Or(result.Reason, "denied by the approval webhook")
+}
+
+// sseResponseWriter writes the response to a request to the stream of server-sent events already started for it,
+// whose status and headers have already been sent. The streamed responses are passed through, the JSON responses
+// are sent as a single event, and the HTTP errors are converted to JSON-RPC errors.
+type sseResponseWriter struct {
+	w         http.ResponseWriter
+	req       *jsonrpc.Request
+	header    http.Header
+	status    int
+	streaming bool
+	body      bytes.Buffer
+}
+
+func newSSEResponseWriter(w http.ResponseWriter, req *jsonrpc.Request) *sseResponseWriter {
+	return &sseResponseWriter{w: w, req: req, header: http.Header{}}
+}
+
+// Header implements [http.ResponseWriter.Header]. The headers are discarded.
+func (s *sseResponseWriter) Header() http.Header { return s.header }
+
+// WriteHeader implements [http.ResponseWriter.WriteHeader].
+func (s *sseResponseWriter) WriteHeader(status int) {
+	if s.status != 0 {
+		return
+	}
+	s.status = status
+	s.streaming = status >= 200 && status < 300 && strings.HasPrefix(s.header.Get("Content-Type"), "text/event-stream")
+}
+
+// Write implements [http.ResponseWriter.Write].
+func (s *sseResponseWriter) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.WriteHeader(http.StatusOK)
+	}
+	if s.streaming {
+		return s.w.Write(b)
+	}
+	return s.body.Write(b)
+}
+
+// Flush implements [http.Flusher.Flush].
+func (s *sseResponseWriter) Flush() {
+	if f, ok := s.w.(http.Flusher); ok && s.streaming {
+		f.Flush()
+	}
+}
+
+// finish sends the buffered response, if any, to the stream.
+func (s *sseResponseWriter) finish() {
+	if s.streaming || (s.body.Len() == 0 && s.status < 300) {
+		return
+	}
+	var msg jsonrpc.Message
+	if s.status >= 200 && s.status < 300 {
+		var ok bool
+		if msg, ok = tryDecodeJSONRPCMessage(s.body.Bytes()); !ok {
+			// Already a stream of events, e.g. from a backend sending events with the JSON content type.
+			_, _ = s.w.Write(s.body.Bytes())
+			if f, ok := s.w.(http.Flusher); ok {
+				f.Flush()
+			}
+			return
+		}
+	} else {
+		if s.req == nil || !s.req.ID.IsValid() {
+			// The errors of notifications and responses have no request to respond to.
+			return
+		}
+		message := cmp.Or(strings.TrimSpace(s.body.String()), http.StatusText(s.status))
+		msg = &jsonrpc.Response{ID: s.req.ID, Error: &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: message}}
+	}
+	event := &sseEvent{event: "message", messages: []jsonrpc.Message{msg}}
+	event.writeAndMaybeFlush(s.w)
+}
@@ -0,0 +1,350 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+func Test_requiresToolApproval(t *testing.T) {
+	backend := filterapi.MCPBackend{Name: "github", ToolApproval: &filterapi.MCPToolApproval{Tools: []string{"delete_repository"}}}
+	require.True(t, requiresToolApproval(backend, "delete_repository"))
+	require.False(t, requiresToolApproval(backend, "create_issue"))
+	require.False(t, requiresToolApproval(filterapi.MCPBackend{Name: "github"}, "delete_repository"))
+}
+
+func Test_elicitationOutcome(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		resp            *jsonrpc.Response
+		outcome, reason string
+	}{
+		{name: "accept", resp: &jsonrpc.Response{Result: []byte(`{"action":"accept"}`)}, outcome: toolApprovalOutcomeApproved},
+		{name: "decline", resp: &jsonrpc.Response{Result: []byte(`{"action":"decline"}`)}, outcome: toolApprovalOutcomeDenied, reason: "declined by the user"},
+		{name: "cancel", resp: &jsonrpc.Response{Result: []byte(`{"action":"cancel"}`)}, outcome: toolApprovalOutcomeDenied, reason: "cancelled by the user"},
+		{
+			name:    "error",
+			resp:    &jsonrpc.Response{Error: &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: "not supported"}},
+			outcome: toolApprovalOutcomeError,
+			reason:  "elicitation failed: not supported",
+		},
+		{name: "invalid", resp: &jsonrpc.Response{Result: []byte(`[]`)}, outcome: toolApprovalOutcomeError, reason: "invalid elicitation result"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			outcome, reason := elicitationOutcome(tc.resp)
+			require.Equal(t, tc.outcome, outcome)
+			require.Equal(t, tc.reason, reason)
+		})
+	}
+}
+
+func Test_isToolApprovalResponse(t *testing.T) {
+	id, _ := jsonrpc.MakeID(envoyAIGatewayServerToClientApprovalRequestIDPrefix + "123")
+	require.True(t, isToolApprovalResponse(&jsonrpc.Response{ID: id}))
+	id, _ = jsonrpc.MakeID(envoyAIGatewayServerToClientPingRequestIDPrefix + "123")
+	require.False(t, isToolApprovalResponse(&jsonrpc.Response{ID: id}))
+	require.False(t, isToolApprovalResponse(&jsonrpc.Response{ID: mustJSONRPCRequestID()}))
+}
+
+func TestServePOST_ToolApprovalResponse(t *testing.T) {
+	proxy := newTestMCPProxy()
+	sessionID := secureID(t, proxy, "test-route@@backend1:dGVzdC1zZXNzaW9u")
+	pending := &pendingToolApproval{sessionID: secureClientToGatewaySessionID(sessionID), response: make(chan *jsonrpc.Response, 1)}
+	proxy.pendingToolApprovals.Store(envoyAIGatewayServerToClientApprovalRequestIDPrefix+"123", pending)
+
+	id, _ := jsonrpc.MakeID(envoyAIGatewayServerToClientApprovalRequestIDPrefix + "123")
+	body, err := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: id, Result: []byte(`{"action":"accept"}`)})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header.Set(sessionIDHeader, sessionID)
+	rr := httptest.NewRecorder()
+	proxy.servePOST(rr, req)
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	select {
+	case resp := <-pending.response:
+		require.JSONEq(t, `{"action":"accept"}`, string(resp.Result))
+	default:
+		t.Fatal("the response has not been delivered")
+	}
+}
+
+func TestDeliverToolApprovalResponse(t *testing.T) {
+	proxy := newTestMCPProxy()
+	pending := &pendingToolApproval{sessionID: "session-a", response: make(chan *jsonrpc.Response, 1)}
+	proxy.pendingToolApprovals.Store(envoyAIGatewayServerToClientApprovalRequestIDPrefix+"123", pending)
+	id, _ := jsonrpc.MakeID(envoyAIGatewayServerToClientApprovalRequestIDPrefix + "123")
+	unknownID, _ := jsonrpc.MakeID(envoyAIGatewayServerToClientApprovalRequestIDPrefix + "456")
+
+	// The responses from other sessions and to unknown requests are ignored.
+	proxy.deliverToolApprovalResponse(&session{id: "session-b"}, &jsonrpc.Response{ID: id})
+	proxy.deliverToolApprovalResponse(&session{id: "session-a"}, &jsonrpc.Response{ID: unknownID})
+	require.Empty(t, pending.response)
+
+	proxy.deliverToolApprovalResponse(&session{id: "session-a"}, &jsonrpc.Response{ID: id})
+	require.Len(t, pending.response, 1)
+	// Duplicate responses don't block.
+	proxy.deliverToolApprovalResponse(&session{id: "session-a"}, &jsonrpc.Response{ID: id})
+	require.Len(t, pending.response, 1)
+}
+
+func TestSSEResponseWriter(t *testing.T) {
+	req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"}
+
+	t.Run("json", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		sw := newSSEResponseWriter(rr, req)
+		sw.Header().Set("Content-Type", "application/json")
+		sw.WriteHeader(http.StatusOK)
+		_, _ = sw.Write([]byte(`{"jsonrpc":"2.0","id":"1","result":{"content":[]}}`))
+		require.Empty(t, rr.Body.String())
+		sw.finish()
+		require.Equal(t, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":\"1\",\"result\":{\"content\":[]}}\n\n\n", rr.Body.String())
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		sw := newSSEResponseWriter(rr, req)
+		sw.Header().Set("Content-Type", "text/event-stream")
+		sw.WriteHeader(http.StatusOK)
+		_, _ = sw.Write([]byte("event: message\ndata: {}\n\n"))
+		require.Equal(t, "event: message\ndata: {}\n\n", rr.Body.String())
+		sw.finish()
+		require.Equal(t, "event: message\ndata: {}\n\n", rr.Body.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		sw := newSSEResponseWriter(rr, req)
+		onErrorResponse(sw, http.StatusInternalServerError, "call to backend1 failed")
+		sw.finish()
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), `"error":{"code":-32603,"message":"call to backend1 failed"}`)
+	})
+
+	t.Run("error without request", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		sw := newSSEResponseWriter(rr, nil)
+		onErrorResponse(sw, http.StatusBadRequest, "missing session ID")
+		sw.finish()
+		require.Empty(t, rr.Body.String())
+	})
+}
+
+func TestHandleToolCallRequest_ToolApproval(t *testing.T) {
+	var backendCalls atomic.Int32
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backendCalls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		msg, _ := jsonrpc.DecodeMessage(body)
+		resp, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: msg.(*jsonrpc.Request).ID, Result: []byte(`{"content":[]}`)})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(backendServer.Close)
+
+	var (
+		webhookCallsMu sync.Mutex
+		webhookCalls   []toolApprovalWebhookRequest
+	)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var call toolApprovalWebhookRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&call))
+		webhookCallsMu.Lock()
+		webhookCalls = append(webhookCalls, call)
+		webhookCallsMu.Unlock()
+		switch r.URL.Path {
+		case "/approve":
+			_, _ = w.Write([]byte(`{"approved":true}`))
+		case "/deny":
+			_, _ = w.Write([]byte(`{"approved":false,"reason":"outside of the change window"}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"approved":true}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(webhookServer.Close)
+
+	newProxy := func(webhookPath string) *mcpRequestContext {
+		proxy := newTestMCPProxy()
+		proxy.backendListenerAddr = backendServer.URL
+		approval := &filterapi.MCPToolApproval{Tools: []string{"test-tool"}, Timeout: 100 * time.Millisecond}
+		if webhookPath != "" {
+			approval.WebhookURL = webhookServer.URL + webhookPath
+		}
+		proxy.routes["test-route"].backends["backend1"] = filterapi.MCPBackend{Name: "backend1", ToolApproval: approval}
+		return proxy
+	}
+	newSession := func(proxy *mcpRequestContext, elicitation bool) *session {
+		s := &session{
+			id:                 "test-session-id",
+			reqCtx:             proxy,
+			route:              "test-route",
+			perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"backend1": {sessionID: "test-session"}},
+			clientCapabilities: &mcp.ClientCapabilities{},
+		}
+		if elicitation {
+			s.clientCapabilities.Elicitation = &mcp.ElicitationCapabilities{}
+		}
+		return s
+	}
+	call := func(t *testing.T, proxy *mcpRequestContext, s *session, rr http.ResponseWriter) error {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "alice"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		_, err = proxy.handleToolCallRequest(t.Context(), s, rr, &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"},
+			&mcp.CallToolParams{Name: "backend1__test-tool", Arguments: map[string]any{"repo": "ai-gateway"}}, nil, httpReq)
+		return err
+	}
+	// respond waits for the elicitation request of the tool call and responds to it with the given result.
+	respond := func(proxy *mcpRequestContext, s *session, result string) {
+		var id string
+		require.Eventually(t, func() bool {
+			proxy.pendingToolApprovals.Range(func(key, _ any) bool {
+				id = key.(string)
+				return false
+			})
+			return id != ""
+		}, time.Second, time.Millisecond)
+		jsonrpcID, _ := jsonrpc.MakeID(id)
+		proxy.deliverToolApprovalResponse(s, &jsonrpc.Response{ID: jsonrpcID, Result: []byte(result)})
+	}
+	requireNotApproved := func(t *testing.T, err error, message string) {
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, codeToolCallNotApproved, rpcErr.Code)
+		require.Equal(t, message, rpcErr.Message)
+	}
+
+	t.Run("no approval method", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("")
+		rr := httptest.NewRecorder()
+		err := call(t, proxy, newSession(proxy, false), rr)
+		requireNotApproved(t, err, "call to tool backend1__test-tool was not approved: the client does not support elicitation")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, int32(0), backendCalls.Load())
+	})
+
+	t.Run("elicitation accepted", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("/deny")
+		proxy.routes["test-route"].backends["backend1"].ToolApproval.Timeout = time.Minute
+		s := newSession(proxy, true)
+		rr := httptest.NewRecorder()
+		done := make(chan error)
+		go func() { done <- call(t, proxy, s, rr) }()
+		respond(proxy, s, `{"action":"accept"}`)
+		require.NoError(t, <-done)
+		require.Equal(t, int32(1), backendCalls.Load())
+
+		require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		require.Equal(t, "test-session-id", rr.Header().Get(sessionIDHeader))
+		events := strings.Split(strings.TrimSpace(rr.Body.String()), "\n\n")
+		require.Len(t, events, 2)
+		msg, err := jsonrpc.DecodeMessage([]byte(strings.TrimPrefix(strings.Split(events[0], "\n")[1], "data: ")))
+		require.NoError(t, err)
+		elicitation := msg.(*jsonrpc.Request)
+		require.Equal(t, "elicitation/create", elicitation.Method)
+		require.True(t, isToolApprovalResponse(&jsonrpc.Response{ID: elicitation.ID}))
+		var params mcp.ElicitParams
+		require.NoError(t, json.Unmarshal(elicitation.Params, &params))
+		require.Equal(t, `The tool backend1__test-tool is about to be called with the arguments {"repo":"ai-gateway"}. Do you approve this call?`, params.Message)
+		require.Contains(t, events[1], `"result":{"content":[]}`)
+		// The pending approval is removed once answered.
+		proxy.pendingToolApprovals.Range(func(any, any) bool {
+			t.Fatal("unexpected pending approval")
+			return false
+		})
+	})
+
+	t.Run("elicitation declined", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("")
+		proxy.routes["test-route"].backends["backend1"].ToolApproval.Timeout = time.Minute
+		s := newSession(proxy, true)
+		rr := httptest.NewRecorder()
+		done := make(chan error)
+		go func() { done <- call(t, proxy, s, rr) }()
+		respond(proxy, s, `{"action":"decline"}`)
+		err := <-done
+		requireNotApproved(t, err, "call to tool backend1__test-tool was not approved: declined by the user")
+		require.Equal(t, int32(0), backendCalls.Load())
+		require.Contains(t, rr.Body.String(), `"error":{"code":-32030,"message":"call to tool backend1__test-tool was not approved: declined by the user"}`)
+	})
+
+	t.Run("elicitation timeout", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("")
+		err := call(t, proxy, newSession(proxy, true), httptest.NewRecorder())
+		requireNotApproved(t, err, "call to tool backend1__test-tool was not approved: no approval within 100ms")
+		require.Equal(t, int32(0), backendCalls.Load())
+	})
+
+	t.Run("webhook approved", func(t *testing.T) {
+		backendCalls.Store(0)
+		webhookCallsMu.Lock()
+		webhookCalls = nil
+		webhookCallsMu.Unlock()
+		proxy := newProxy("/approve")
+		rr := httptest.NewRecorder()
+		require.NoError(t, call(t, proxy, newSession(proxy, false), rr))
+		require.Equal(t, int32(1), backendCalls.Load())
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		webhookCallsMu.Lock()
+		defer webhookCallsMu.Unlock()
+		require.Equal(t, []toolApprovalWebhookRequest{{
+			Route:     "test-route",
+			Backend:   "backend1",
+			Tool:      "test-tool",
+			Arguments: map[string]any{"repo": "ai-gateway"},
+			Subject:   "alice",
+		}}, webhookCalls)
+	})
+
+	t.Run("webhook denied", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("/deny")
+		err := call(t, proxy, newSession(proxy, false), httptest.NewRecorder())
+		requireNotApproved(t, err, "call to tool backend1__test-tool was not approved: outside of the change window")
+		require.Equal(t, int32(0), backendCalls.Load())
+	})
+
+	t.Run("webhook failure", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("/fail")
+		err := call(t, proxy, newSession(proxy, false), httptest.NewRecorder())
+		requireNotApproved(t, err, "call to tool backend1__test-tool was not approved: the approval webhook failed")
+		require.Equal(t, int32(0), backendCalls.Load())
+	})
+
+	t.Run("webhook timeout", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy("/slow")
+		err := call(t, proxy, newSession(proxy, false), httptest.NewRecorder())
+		requireNotApproved(t, err, "call to tool backend1__test-tool was not approved: no approval within 100ms")
+		require.Equal(t, int32(0), backendCalls.Load())
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"log/slog"
 	"net/http"
 	"reflect"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -22,6 +23,7 @@ import (
 
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/json"
+	"github.com/envoyproxy/ai-gateway/internal/lang"
 )
 
 type compiledAuthorization struct {
@@ -32,15 +34,17 @@ type compiledAuthorization struct {
 
 type compiledAuthorizationRule struct {
 	Source *filterapi.MCPAuthorizationSource
-	Target []filterapi.ToolCall
+	Target *filterapi.MCPAuthorizationTarget
 	Action filterapi.AuthorizationAction
+	// resourceURIs are the compiled URI patterns of Target.Resources, in the same order.
+	resourceURIs []*regexp.Regexp
 	// CEL expression compiled for request-level evaluation.
 	celExpression string
 	celProgram    cel.Program
 }
 
 // same reports whether two compiledAuthorization values are semantically equivalent.
-// celProgram is excluded because it is derived from celExpression and is not comparable.
+// celProgram and resourceURIs are excluded because they are derived from celExpression and Target.
 func (a *compiledAuthorization) same(other *compiledAuthorization) bool {
 	if a == nil || other == nil {
 		return a == other
@@ -65,7 +69,11 @@ type authorizationRequest struct {
 	MCPMethod  string
 	Backend    string
 	Tool       string
-	Params     mcp.Params
+	// Resource is the URI of the resource without the backend prefix, for requests referencing a resource.
+	Resource string
+	// Prompt is the name of the prompt without the backend prefix, for requests referencing a prompt.
+	Prompt string
+	Params mcp.Params
 }
 
 // compileAuthorization compiles the MCPRouteAuthorization into a compiledAuthorization for efficient CEL evaluation.
@@ -93,7 +101,10 @@ func compileAuthorization(auth *filterapi.MCPRouteAuthorization) (*compiledAutho
 			Action: rule.Action,
 		}
 		if rule.Target != nil {
-			cr.Target = append(cr.Target, rule.Target.Tools...)
+			cr.Target = rule.Target
+			for _, resource := range rule.Target.Resources {
+				cr.resourceURIs = append(cr.resourceURIs, compileURIPattern(resource.URI))
+			}
 		}
 		if rule.CEL != nil && strings.TrimSpace(*rule.CEL) != "" {
 			expr := strings.TrimSpace(*rule.CEL)
@@ -151,10 +162,20 @@ func (m *mcpRequestContext) authorizeRequest(authorization *compiledAuthorizatio
 	var requiredScopesForChallenge []string
 	var celActivation map[string]any
 
+	// Rules without a target only apply to the tool calls, which were the only authorized requests before
+	// resource, prompt and method targets were introduced. The other requests are only subject to the
+	// authorization once a rule with a target matches them.
+	toolCall := req.Tool != ""
+	targeted := toolCall
+
 	for i := range authorization.Rules {
 		rule := &authorization.Rules[i]
 		action := rule.Action == filterapi.AuthorizationActionAllow
 
+		if !toolCall && rule.Target == nil {
+			continue
+		}
+
 		// Evaluate CEL expression if present.
 		if rule.celProgram != nil {
 			if celActivation == nil {
@@ -171,9 +192,10 @@ func (m *mcpRequestContext) authorizeRequest(authorization *compiledAuthorizatio
 		}
 
 		// If no target is specified, the rule matches all targets.
-		if rule.Target != nil && !m.toolMatches(req.Backend, req.Tool, rule.Target) {
+		if rule.Target != nil && !rule.targetMatches(req) {
 			continue
 		}
+		targeted = true
 
 		// If no source is specified, the rule matches all sources.
 		if rule.Source == nil {
@@ -199,9 +221,61 @@ func (m *mcpRequestContext) authorizeRequest(authorization *compiledAuthorizatio
 		}
 	}
 
+	if !targeted {
+		return true, nil
+	}
 	return defaultAction, requiredScopesForChallenge
 }
 
+// newAuthorizationRequest completes the given authorization request with the HTTP request of this context.
+func (m *mcpRequestContext) newAuthorizationRequest(req *authorizationRequest) *authorizationRequest {
+	req.Headers = m.requestHeaders
+	if req.Headers == nil {
+		req.Headers = http.Header{}
+	}
+	req.HTTPMethod = http.MethodPost
+	req.Host = m.requestHost
+	req.HTTPPath = m.requestPath
+	return req
+}
+
+// authorizeBackendRequest authorizes a request sent to a single backend other than a tool call, and writes
+// the error response when it is denied.
+func (m *mcpRequestContext) authorizeBackendRequest(w http.ResponseWriter, s *session, req *authorizationRequest) error {
+	route := m.routes[s.route]
+	if route == nil || route.authorization == nil {
+		return nil
+	}
+	allowed, requiredScopes := m.authorizeRequest(route.authorization, m.newAuthorizationRequest(req))
+	if !allowed {
+		return onAuthorizationDenied(w, route.authorization, requiredScopes)
+	}
+	return nil
+}
+
+// authorizeFanOut returns true if the request sent to all the backends is authorized for the given backend.
+func (m *mcpRequestContext) authorizeFanOut(authorization *compiledAuthorization, method string, backend filterapi.MCPBackendName, params mcp.Params) bool {
+	allowed, _ := m.authorizeRequest(authorization, m.newAuthorizationRequest(&authorizationRequest{
+		MCPMethod: method,
+		Backend:   backend,
+		Params:    params,
+	}))
+	return allowed
+}
+
+// onAuthorizationDenied writes the response of a request denied by the authorization and returns the error.
+func onAuthorizationDenied(w http.ResponseWriter, authorization *compiledAuthorization, requiredScopes []string) error {
+	// Specify the minimum required scopes in the WWW-Authenticate header.
+	// Reference: https://mcp.mintlify.app/specification/2025-11-25/basic/authorization#runtime-insufficient-scope-errors
+	if len(requiredScopes) > 0 {
+		if challenge := buildInsufficientScopeHeader(requiredScopes, authorization.ResourceMetadataURL); challenge != "" {
+			w.Header().Set("WWW-Authenticate", challenge)
+		}
+	}
+	onErrorResponse(w, http.StatusForbidden, "access denied")
+	return fmt.Errorf("authorization failed")
+}
+
 func buildCELActivation(req *authorizationRequest, claims jwt.MapClaims, scopes sets.Set[string]) map[string]any {
 	// Normalize headers to lowercased keys to align with Envoy's behavior.
 	// Expose both single-value and multi-value header views for CEL.
@@ -231,10 +305,12 @@ func buildCELActivation(req *authorizationRequest, claims jwt.MapClaims, scopes
 			},
 		},
 		"mcp": map[string]any{
-			"method":  req.MCPMethod,
-			"backend": req.Backend,
-			"tool":    req.Tool,
-			"params":  normalizeParams(req.Params),
+			"method":   req.MCPMethod,
+			"backend":  req.Backend,
+			"tool":     req.Tool,
+			"resource": req.Resource,
+			"prompt":   req.Prompt,
+			"params":   normalizeParams(req.Params),
 		},
 	}
 	// Only request is supported for now. Future expansions may include more context.
@@ -323,22 +399,48 @@ func (m *mcpRequestContext) evalRuleCEL(rule *compiledAuthorizationRule, activat
 	}
 }
 
-func (m *mcpRequestContext) toolMatches(backend, tool string, tools []filterapi.ToolCall) bool {
-	// Empty tools means all tools match.
-	if len(tools) == 0 {
+// targetMatches returns true if any of the tools, resources, prompts or methods of the rule target matches
+// the request. A target without any entry matches all the requests.
+func (r *compiledAuthorizationRule) targetMatches(req *authorizationRequest) bool {
+	t := r.Target
+	if len(t.Tools) == 0 && len(t.Resources) == 0 && len(t.Prompts) == 0 && len(t.Methods) == 0 {
 		return true
 	}
-
-	for _, t := range tools {
-		if t.Backend != backend || t.Tool != tool {
-			continue
+	if req.Tool != "" {
+		for _, tool := range t.Tools {
+			if tool.Backend == req.Backend && tool.Tool == req.Tool {
+				return true
+			}
+		}
+	}
+	if req.Resource != "" {
+		for i, resource := range t.Resources {
+			if resource.Backend == req.Backend && r.resourceURIs[i].MatchString(req.Resource) {
+				return true
+			}
+		}
+	}
+	if req.Prompt != "" {
+		for _, prompt := range t.Prompts {
+			if prompt.Backend == req.Backend && prompt.Prompt == req.Prompt {
+				return true
+			}
+		}
+	}
+	for _, method := range t.Methods {
+		if method.Method == req.MCPMethod && (method.Backend == "" || method.Backend == req.Backend) {
+			return true
 		}
-		return true
 	}
-	// If no matching tool entry or no arguments matched, fail.
 	return false
 }
 
+// compileURIPattern compiles a resource URI pattern where "*" matches any sequence of characters.
+func compileURIPattern(pattern string) *regexp.Regexp {
+	quoted := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
 func scopesSatisfied(have sets.Set[string], required []string) bool {
 	if len(required) == 0 {
 		return true
@@ -358,7 +460,7 @@ func claimsSatisfied(claims jwt.MapClaims, required []filterapi.JWTClaim) bool {
 	}
 
 	for _, claim := range required {
-		value, ok := lookupClaim(claims, claim.Name)
+		value, ok := lang.NestedValue(claims, claim.Name)
 		if !ok {
 			return false
 		}
@@ -381,22 +483,6 @@ func claimsSatisfied(claims jwt.MapClaims, required []filterapi.JWTClaim) bool {
 	return true
 }
 
-func lookupClaim(claims map[string]any, path string) (any, bool) {
-	current := any(claims)
-	for _, part := range strings.Split(path, ".") {
-		m, ok := current.(map[string]any)
-		if !ok {
-			return nil, false
-		}
-		next, ok := m[part]
-		if !ok {
-			return nil, false
-		}
-		current = next
-	}
-	return current, true
-}
-
 // When the claim is an array, check if any of the values is in the allowed list.
 func claimHasAllowedString(value any, allowed []string) bool {
 	switch v := value.(type) {
@@ -46,6 +46,8 @@ func TestAuthorizeRequest(t *testing.T) {
 		auth          *filterapi.MCPRouteAuthorization
 		backend       string
 		tool          string
+		resource      string
+		prompt        string
 		args          mcp.Params
 		host          string
 		headers       http.Header
@@ -960,6 +962,224 @@ func TestAuthorizeRequest(t *testing.T) {
 			tool:          "tool1",
 			expectAllowed: true,
 		},
+		{
+			name: "resource target allows matching claim",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Deny",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{
+							JWT: filterapi.JWTSource{Claims: []filterapi.JWTClaim{{Name: "group", ValueType: filterapi.JWTClaimValueTypeString, Values: []string{"sre"}}}},
+						},
+						Target: &filterapi.MCPAuthorizationTarget{
+							Resources: []filterapi.ResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+						},
+					},
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{JWT: filterapi.JWTSource{Scopes: []string{"tools"}}},
+					},
+				},
+			},
+			headers:       http.Header{"Authorization": []string{"Bearer " + makeTokenWithClaims(jwt.MapClaims{"group": "sre"})}},
+			mcpMethod:     "resources/read",
+			backend:       "postgres",
+			resource:      "schema://users",
+			expectAllowed: true,
+		},
+		{
+			name: "resource target falls back to default deny",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Deny",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{
+							JWT: filterapi.JWTSource{Claims: []filterapi.JWTClaim{{Name: "group", ValueType: filterapi.JWTClaimValueTypeString, Values: []string{"sre"}}}},
+						},
+						Target: &filterapi.MCPAuthorizationTarget{
+							Resources: []filterapi.ResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+						},
+					},
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{JWT: filterapi.JWTSource{Scopes: []string{"tools"}}},
+					},
+				},
+			},
+			headers:       http.Header{"Authorization": []string{"Bearer " + makeTokenWithClaims(jwt.MapClaims{"group": "dev"}, "tools")}},
+			mcpMethod:     "resources/read",
+			backend:       "postgres",
+			resource:      "schema://users",
+			expectAllowed: false,
+		},
+		{
+			name: "resource not targeted by any rule is allowed",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Deny",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{
+							JWT: filterapi.JWTSource{Claims: []filterapi.JWTClaim{{Name: "group", ValueType: filterapi.JWTClaimValueTypeString, Values: []string{"sre"}}}},
+						},
+						Target: &filterapi.MCPAuthorizationTarget{
+							Resources: []filterapi.ResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+						},
+					},
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{JWT: filterapi.JWTSource{Scopes: []string{"tools"}}},
+					},
+				},
+			},
+			headers:       http.Header{"Authorization": []string{"Bearer " + makeTokenWithClaims(jwt.MapClaims{"group": "dev"})}},
+			mcpMethod:     "resources/read",
+			backend:       "postgres",
+			resource:      "table://users",
+			expectAllowed: true,
+		},
+		{
+			name: "resource target does not match other backend",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Deny",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{
+							JWT: filterapi.JWTSource{Claims: []filterapi.JWTClaim{{Name: "group", ValueType: filterapi.JWTClaimValueTypeString, Values: []string{"sre"}}}},
+						},
+						Target: &filterapi.MCPAuthorizationTarget{
+							Resources: []filterapi.ResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+						},
+					},
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{JWT: filterapi.JWTSource{Scopes: []string{"tools"}}},
+					},
+				},
+			},
+			mcpMethod:     "resources/read",
+			backend:       "mysql",
+			resource:      "schema://users",
+			expectAllowed: true,
+		},
+		{
+			name: "rules without target still apply to tool calls",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Deny",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{
+							JWT: filterapi.JWTSource{Claims: []filterapi.JWTClaim{{Name: "group", ValueType: filterapi.JWTClaimValueTypeString, Values: []string{"sre"}}}},
+						},
+						Target: &filterapi.MCPAuthorizationTarget{
+							Resources: []filterapi.ResourceTarget{{Backend: "postgres", URI: "schema://*"}},
+						},
+					},
+					{
+						Action: "Allow",
+						Source: &filterapi.MCPAuthorizationSource{JWT: filterapi.JWTSource{Scopes: []string{"tools"}}},
+					},
+				},
+			},
+			headers:       http.Header{"Authorization": []string{"Bearer " + makeToken("tools")}},
+			backend:       "postgres",
+			tool:          "query",
+			expectAllowed: true,
+		},
+		{
+			name: "prompt target denies",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Allow",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Deny",
+						Target: &filterapi.MCPAuthorizationTarget{
+							Prompts: []filterapi.PromptTarget{{Backend: "backend1", Prompt: "internal"}},
+						},
+					},
+				},
+			},
+			mcpMethod:     "prompts/get",
+			backend:       "backend1",
+			prompt:        "internal",
+			expectAllowed: false,
+		},
+		{
+			name: "method target denies any request of the method to the backend",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Allow",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Deny",
+						Target: &filterapi.MCPAuthorizationTarget{
+							Methods: []filterapi.MethodTarget{{Method: "completion/complete", Backend: "backend1"}},
+						},
+					},
+				},
+			},
+			mcpMethod:     "completion/complete",
+			backend:       "backend1",
+			prompt:        "summarize",
+			expectAllowed: false,
+		},
+		{
+			name: "method target does not match other backend",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Deny",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Deny",
+						Target: &filterapi.MCPAuthorizationTarget{
+							Methods: []filterapi.MethodTarget{{Method: "completion/complete", Backend: "backend1"}},
+						},
+					},
+				},
+			},
+			mcpMethod:     "completion/complete",
+			backend:       "backend2",
+			prompt:        "summarize",
+			expectAllowed: true,
+		},
+		{
+			name: "method target without backend matches tool calls",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Allow",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Deny",
+						Target: &filterapi.MCPAuthorizationTarget{
+							Methods: []filterapi.MethodTarget{{Method: "tools/call"}},
+						},
+					},
+				},
+			},
+			backend:       "backend1",
+			tool:          "tool1",
+			expectAllowed: false,
+		},
+		{
+			name: "resource in CEL",
+			auth: &filterapi.MCPRouteAuthorization{
+				DefaultAction: "Allow",
+				Rules: []filterapi.MCPRouteAuthorizationRule{
+					{
+						Action: "Deny",
+						CEL:    ptr.To(`request.mcp.resource.startsWith("schema://")`),
+						Target: &filterapi.MCPAuthorizationTarget{
+							Methods: []filterapi.MethodTarget{{Method: "resources/read"}},
+						},
+					},
+				},
+			},
+			mcpMethod:     "resources/read",
+			backend:       "postgres",
+			resource:      "schema://users",
+			expectAllowed: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -983,6 +1203,8 @@ func TestAuthorizeRequest(t *testing.T) {
 				MCPMethod:  cmp.Or(tt.mcpMethod, "tools/call"),
 				Backend:    tt.backend,
 				Tool:       tt.tool,
+				Resource:   tt.resource,
+				Prompt:     tt.prompt,
 				Params:     tt.args,
 			})
 			if allowed != tt.expectAllowed {
@@ -1019,3 +1241,24 @@ func TestCompileAuthorizationInvalidRuleCEL(t *testing.T) {
 		t.Fatalf("expected compile error for invalid rule CEL expression")
 	}
 }
+
+func Test_compileURIPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, uri string
+		exp          bool
+	}{
+		{pattern: "schema://*", uri: "schema://users", exp: true},
+		{pattern: "schema://*", uri: "schema://public/users", exp: true},
+		{pattern: "schema://*", uri: "table://users", exp: false},
+		{pattern: "file:///docs/*.md", uri: "file:///docs/readme.md", exp: true},
+		{pattern: "file:///docs/*.md", uri: "file:///docs/readme.txt", exp: false},
+		{pattern: "file:///a.b", uri: "file:///axb", exp: false},
+		{pattern: "file:///a.b", uri: "file:///a.b", exp: true},
+	} {
+		t.Run(tc.pattern+" "+tc.uri, func(t *testing.T) {
+			if got := compileURIPattern(tc.pattern).MatchString(tc.uri); got != tc.exp {
+				t.Fatalf("expected %v, got %v", tc.exp, got)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
 )
@@ -32,6 +33,24 @@ type (
 		client                     http.Client
 		logRequestHeaderAttributes map[string]string
 		maxRequestBodySize         int64 // maximum allowed POST body size in bytes
+		tokenExchanger             *tokenExchanger
+		toolSchemas                *sessionToolCache[*toolSchemas]
+		// toolFingerprints are the fingerprints of the tool definitions listed in each session by the backends
+		// whose calls are blocked by the tool pinning when they don't match the approved fingerprints.
+		toolFingerprints *sessionToolCache[string]
+		rateLimiter      callcounter.Limiter
+		// pendingToolApprovals are the tool calls waiting for the response of the client to the elicitation request
+		// asking for their approval. The keys are the IDs of the elicitation requests.
+		pendingToolApprovals sync.Map
+		// sseBackendConnections are the connections to the backends speaking the legacy HTTP+SSE transport. The keys
+		// are the session IDs of the backends.
+		sseBackendConnections sync.Map
+		// legacySSEStreams are the SSE streams of the clients speaking the legacy HTTP+SSE transport. The keys are the
+		// IDs of the streams sent to the clients in the endpoint event.
+		legacySSEStreams sync.Map
+		// toolSearchEmbeddingsCache caches the embeddings of the tools searched with embeddings. The keys are
+		// computed by toolSearchEmbeddingsKey.
+		toolSearchEmbeddingsCache sync.Map
 	}
 
 	mcpProxyConfig struct {
@@ -40,10 +59,16 @@ type (
 	}
 
 	mcpProxyConfigRoute struct {
-		backends       map[filterapi.MCPBackendName]filterapi.MCPBackend
-		toolSelectors  map[filterapi.MCPBackendName]*toolSelector
-		authorization  *compiledAuthorization
-		forwardHeaders []string
+		backends          map[filterapi.MCPBackendName]filterapi.MCPBackend
+		toolSelectors     map[filterapi.MCPBackendName]*toolSelector
+		resourceSelectors map[filterapi.MCPBackendName]*toolSelector
+		promptSelectors   map[filterapi.MCPBackendName]*toolSelector
+		toolNames         map[filterapi.MCPBackendName]*toolNaming
+		openAPIBackends   map[filterapi.MCPBackendName]*openAPIBackend
+		authorization     *compiledAuthorization
+		forwardHeaders    []string
+		rateLimits        []*toolRateLimit
+		toolSearch        *toolSearch
 	}
 
 	// toolSelector filters tools using include and exclude patterns with exact matches or regular expressions.
@@ -91,6 +116,18 @@ func (m *mcpProxyConfigRoute) sameTools(other *mcpProxyConfigRoute) bool {
 	if !m.authorization.same(other.authorization) {
 		return false
 	}
+	// The tools are replaced by the meta tools when the tool search is enabled.
+	if (m.toolSearch == nil) != (other.toolSearch == nil) {
+		return false
+	}
+	for backend := range m.backends {
+		if !m.toolNaming(backend).sameTools(other.toolNaming(backend)) {
+			return false
+		}
+		if !sameOpenAPIBackend(m.backends[backend].OpenAPI, other.backends[backend].OpenAPI) {
+			return false
+		}
+	}
 	return maps.EqualFunc(m.toolSelectors, other.toolSelectors, func(a, b *toolSelector) bool {
 		return a.sameTools(b)
 	})
@@ -114,6 +151,49 @@ func compileRegexps(exprs []string, kind string, backendName filterapi.MCPBacken
 	return regexps, nil
 }
 
+// newToolSelector compiles the given selector. This is used for the tool, resource and prompt selectors.
+func newToolSelector(s *filterapi.MCPToolSelector, backendName filterapi.MCPBackendName, routeName filterapi.MCPRouteName) (*toolSelector, error) {
+	ts := &toolSelector{
+		include: make(map[string]struct{}),
+		exclude: make(map[string]struct{}),
+	}
+	for _, name := range s.Include {
+		ts.include[name] = struct{}{}
+	}
+	includeRegexps, err := compileRegexps(s.IncludeRegex, "include", backendName, routeName)
+	if err != nil {
+		return nil, err
+	}
+	ts.includeRegexps = includeRegexps
+	for _, name := range s.Exclude {
+		ts.exclude[name] = struct{}{}
+	}
+	excludeRegexps, err := compileRegexps(s.ExcludeRegex, "exclude", backendName, routeName)
+	if err != nil {
+		return nil, err
+	}
+	ts.excludeRegexps = excludeRegexps
+	return ts, nil
+}
+
+// allowsResource returns true if the resource URI of the given backend is selected by the route.
+func (m *mcpProxyConfigRoute) allowsResource(backendName filterapi.MCPBackendName, uri string) bool {
+	if m == nil {
+		return true
+	}
+	s := m.resourceSelectors[backendName]
+	return s == nil || s.allows(uri)
+}
+
+// allowsPrompt returns true if the prompt name of the given backend is selected by the route.
+func (m *mcpProxyConfigRoute) allowsPrompt(backendName filterapi.MCPBackendName, name string) bool {
+	if m == nil {
+		return true
+	}
+	s := m.promptSelectors[backendName]
+	return s == nil || s.allows(name)
+}
+
 func (t *toolSelector) sameTools(other *toolSelector) bool {
 	if t == nil || other == nil {
 		return t == other
@@ -200,35 +280,51 @@ func (p *ProxyConfig) LoadConfig(_ context.Context, config *filterapi.Config) er
 		}
 
 		r := &mcpProxyConfigRoute{
-			backends:       make(map[filterapi.MCPBackendName]filterapi.MCPBackend, len(route.Backends)),
-			toolSelectors:  make(map[filterapi.MCPBackendName]*toolSelector, len(route.Backends)),
-			authorization:  compiledAuth,
-			forwardHeaders: route.ForwardHeaders,
+			backends:          make(map[filterapi.MCPBackendName]filterapi.MCPBackend, len(route.Backends)),
+			toolSelectors:     make(map[filterapi.MCPBackendName]*toolSelector, len(route.Backends)),
+			resourceSelectors: make(map[filterapi.MCPBackendName]*toolSelector, len(route.Backends)),
+			promptSelectors:   make(map[filterapi.MCPBackendName]*toolSelector, len(route.Backends)),
+			toolNames:         make(map[filterapi.MCPBackendName]*toolNaming, len(route.Backends)),
+			openAPIBackends:   make(map[filterapi.MCPBackendName]*openAPIBackend),
+			authorization:     compiledAuth,
+			forwardHeaders:    route.ForwardHeaders,
+			rateLimits:        newToolRateLimits(route.RateLimits),
+			toolSearch:        newToolSearch(route.ToolSearch),
 		}
 		for _, backend := range route.Backends {
 			r.backends[backend.Name] = backend
 			if s := backend.ToolSelector; s != nil {
-				ts := &toolSelector{
-					include: make(map[string]struct{}),
-					exclude: make(map[string]struct{}),
-				}
-				for _, tool := range s.Include {
-					ts.include[tool] = struct{}{}
-				}
-				includeRegexps, err := compileRegexps(s.IncludeRegex, "include", backend.Name, route.Name)
+				ts, err := newToolSelector(s, backend.Name, route.Name)
 				if err != nil {
 					return err
 				}
-				ts.includeRegexps = includeRegexps
-				for _, tool := range s.Exclude {
-					ts.exclude[tool] = struct{}{}
+				r.toolSelectors[backend.Name] = ts
+			}
+			if s := backend.ResourceSelector; s != nil {
+				rs, err := newToolSelector(s, backend.Name, route.Name)
+				if err != nil {
+					return fmt.Errorf("invalid resource selector: %w", err)
+				}
+				r.resourceSelectors[backend.Name] = rs
+			}
+			if s := backend.PromptSelector; s != nil {
+				ps, err := newToolSelector(s, backend.Name, route.Name)
+				if err != nil {
+					return fmt.Errorf("invalid prompt selector: %w", err)
 				}
-				excludeRegexps, err := compileRegexps(s.ExcludeRegex, "exclude", backend.Name, route.Name)
+				r.promptSelectors[backend.Name] = ps
+			}
+			tn, err := newToolNaming(backend)
+			if err != nil {
+				return fmt.Errorf("invalid tool overrides for backend %q in route %q: %w", backend.Name, route.Name, err)
+			}
+			r.toolNames[backend.Name] = tn
+			if backend.OpenAPI != nil {
+				ob, err := newOpenAPIBackend(backend.OpenAPI)
 				if err != nil {
-					return err
+					return fmt.Errorf("invalid OpenAPI backend %q in route %q: %w", backend.Name, route.Name, err)
 				}
-				ts.excludeRegexps = excludeRegexps
-				r.toolSelectors[backend.Name] = ts
+				r.openAPIBackends[backend.Name] = ob
 			}
 		}
 		newConfig.routes[route.Name] = r
@@ -14,6 +14,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 )
 
@@ -85,7 +86,7 @@ func Test_toolSelector_Allows(t *testing.T) {
 }
 
 func TestLoadConfig_NilMCPConfig(t *testing.T) {
-	proxy, _, err := NewMCPProxy(slog.Default(), stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil)
+	proxy, _, err := NewMCPProxy(slog.Default(), stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil, callcounter.NewLocal())
 	require.NoError(t, err)
 
 	config := &filterapi.Config{MCPConfig: nil}
@@ -123,6 +124,10 @@ func TestLoadConfig_BasicConfiguration(t *testing.T) {
 						{Name: "backend3"},
 						{Name: "backend4"},
 					},
+					RateLimits: []filterapi.MCPToolRateLimit{
+						{Backend: "backend3", Tools: []string{"tool1"}, Calls: 10, Window: time.Minute},
+					},
+					ToolSearch: &filterapi.MCPToolSearch{},
 				},
 			},
 		},
@@ -147,6 +152,34 @@ func TestLoadConfig_BasicConfiguration(t *testing.T) {
 	require.Len(t, selector.includeRegexps, 1)
 	require.True(t, selector.includeRegexps[0].MatchString("test123"))
 	require.False(t, selector.includeRegexps[0].MatchString("other"))
+	require.Empty(t, proxy.routes["route1"].rateLimits)
+	require.Len(t, proxy.routes["route2"].rateLimits, 1)
+	require.True(t, proxy.routes["route2"].rateLimits[0].appliesTo("backend3", "tool1"))
+	require.Nil(t, proxy.routes["route1"].toolSearch)
+	require.Equal(t, &toolSearch{maxResults: defaultToolSearchMaxResults}, proxy.routes["route2"].toolSearch)
+}
+
+func TestLoadConfig_ToolSearchChanged(t *testing.T) {
+	toolChangeSignaler := newMultiWatcherSignaler()
+	proxy := &ProxyConfig{toolChangeSignaler: toolChangeSignaler}
+	requireSignaled := func(expected bool, ts *filterapi.MCPToolSearch) {
+		watcher := toolChangeSignaler.Watch()
+		require.NoError(t, proxy.LoadConfig(t.Context(), &filterapi.Config{MCPConfig: &filterapi.MCPConfig{
+			Routes: []filterapi.MCPRoute{{Name: "route1", Backends: []filterapi.MCPBackend{{Name: "backend1"}}, ToolSearch: ts}},
+		}}))
+		select {
+		case <-watcher:
+			require.True(t, expected, "unexpected tools changed notification")
+		case <-time.After(100 * time.Millisecond):
+			require.False(t, expected, "expected tools changed notification but didn't receive one")
+		}
+	}
+
+	requireSignaled(true, nil)
+	requireSignaled(true, &filterapi.MCPToolSearch{})
+	// The listed meta tools don't depend on the search options.
+	requireSignaled(false, &filterapi.MCPToolSearch{MaxResults: 5})
+	requireSignaled(true, nil)
 }
 
 func TestLoadConfig_ToolsChangedNotification(t *testing.T) {
@@ -316,6 +349,65 @@ func TestLoadConfig_InvalidRegex(t *testing.T) {
 	require.Contains(t, err.Error(), "failed to compile include regex")
 }
 
+func TestLoadConfig_ResourceAndPromptSelectors(t *testing.T) {
+	proxy := &ProxyConfig{
+		mcpProxyConfig:     &mcpProxyConfig{},
+		toolChangeSignaler: newMultiWatcherSignaler(),
+	}
+
+	config := &filterapi.Config{
+		MCPConfig: &filterapi.MCPConfig{
+			BackendListenerAddr: "http://localhost:8080",
+			Routes: []filterapi.MCPRoute{
+				{
+					Name: "route1",
+					Backends: []filterapi.MCPBackend{
+						{
+							Name: "backend1",
+							ResourceSelector: &filterapi.MCPResourceSelector{
+								IncludeRegex: []string{"^file:///public/.*"},
+								Exclude:      []string{"file:///public/secret.txt"},
+							},
+							PromptSelector: &filterapi.MCPPromptSelector{Exclude: []string{"internal"}},
+						},
+						{Name: "backend2"},
+					},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, proxy.LoadConfig(t.Context(), config))
+	route := proxy.routes["route1"]
+	require.Len(t, route.resourceSelectors, 1)
+	require.Len(t, route.promptSelectors, 1)
+
+	require.True(t, route.allowsResource("backend1", "file:///public/readme.md"))
+	require.False(t, route.allowsResource("backend1", "file:///public/secret.txt"))
+	require.False(t, route.allowsResource("backend1", "file:///private/salaries.csv"))
+	require.True(t, route.allowsResource("backend2", "file:///private/salaries.csv"))
+
+	require.True(t, route.allowsPrompt("backend1", "summarize"))
+	require.False(t, route.allowsPrompt("backend1", "internal"))
+	require.True(t, route.allowsPrompt("backend2", "internal"))
+
+	t.Run("nil route", func(t *testing.T) {
+		var route *mcpProxyConfigRoute
+		require.True(t, route.allowsResource("backend1", "file:///private/salaries.csv"))
+		require.True(t, route.allowsPrompt("backend1", "internal"))
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		config.MCPConfig.Routes[0].Backends[0].PromptSelector = &filterapi.MCPPromptSelector{ExcludeRegex: []string{"[invalid"}}
+		err := proxy.LoadConfig(t.Context(), config)
+		require.ErrorContains(t, err, "invalid prompt selector: failed to compile exclude regex")
+
+		config.MCPConfig.Routes[0].Backends[0].ResourceSelector = &filterapi.MCPResourceSelector{IncludeRegex: []string{"[invalid"}}
+		err = proxy.LoadConfig(t.Context(), config)
+		require.ErrorContains(t, err, "invalid resource selector: failed to compile include regex")
+	})
+}
+
 func TestLoadConfig_InvalidExcludeRegex(t *testing.T) {
 	proxy := &ProxyConfig{
 		mcpProxyConfig:     &mcpProxyConfig{},
@@ -595,3 +687,45 @@ func TestLoadConfig_AuthorizationChangeTriggersNotification(t *testing.T) {
 	require.NoError(t, err)
 	wg.Wait()
 }
+
+func TestLoadConfig_ToolOverridesChanged(t *testing.T) {
+	toolChangeSignaler := newMultiWatcherSignaler()
+	proxy := &ProxyConfig{toolChangeSignaler: toolChangeSignaler}
+	newConfig := func(backend filterapi.MCPBackend) *filterapi.Config {
+		return &filterapi.Config{MCPConfig: &filterapi.MCPConfig{
+			Routes: []filterapi.MCPRoute{{Name: "route1", Backends: []filterapi.MCPBackend{backend}}},
+		}}
+	}
+	requireSignaled := func(expected bool, backend filterapi.MCPBackend) {
+		watcher := toolChangeSignaler.Watch()
+		require.NoError(t, proxy.LoadConfig(t.Context(), newConfig(backend)))
+		select {
+		case <-watcher:
+			require.True(t, expected, "unexpected tools changed notification")
+		case <-time.After(100 * time.Millisecond):
+			require.False(t, expected, "expected tools changed notification but didn't receive one")
+		}
+	}
+
+	requireSignaled(true, filterapi.MCPBackend{Name: "backend1"})
+	requireSignaled(false, filterapi.MCPBackend{Name: "backend1"})
+	requireSignaled(true, filterapi.MCPBackend{
+		Name:          "backend1",
+		ToolOverrides: []filterapi.MCPToolOverride{{Name: "tool", Description: "new"}},
+	})
+	requireSignaled(false, filterapi.MCPBackend{
+		Name:          "backend1",
+		ToolOverrides: []filterapi.MCPToolOverride{{Name: "tool", Description: "new"}},
+	})
+	requireSignaled(true, filterapi.MCPBackend{
+		Name:           "backend1",
+		ToolNamePrefix: &filterapi.MCPToolNamePrefix{Separator: "_"},
+		ToolOverrides:  []filterapi.MCPToolOverride{{Name: "tool", Description: "new"}},
+	})
+
+	err := proxy.LoadConfig(t.Context(), newConfig(filterapi.MCPBackend{
+		Name:          "backend1",
+		ToolOverrides: []filterapi.MCPToolOverride{{Name: "tool", FixedArguments: map[string]string{"a": "{"}}},
+	}))
+	require.ErrorContains(t, err, `invalid tool overrides for backend "backend1" in route "route1"`)
+}
@@ -39,6 +39,9 @@ var (
 	errSessionNotFound      = errors.New("session not found")
 	errBackendNotFound      = errors.New("backend not found")
 	errInvalidToolName      = errors.New("invalid tool name")
+	errInvalidResourceURI   = errors.New("invalid resource URI")
+	errInvalidPromptName    = errors.New("invalid prompt name")
+	errToolDefinitionDrift  = errors.New("tool definition drifted from the approved fingerprint")
 	errBackendResponseError = errors.New("one or more backends returned an error response")
 )
 
@@ -180,6 +183,8 @@ func (m *mcpRequestContext) serverDELETE(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	_ = s.Close() // Ignore error as it's not recoverable here. Errors per backend are logged in Close().
+	m.toolSchemas.delete(s.clientGatewaySessionID())
+	m.toolFingerprints.delete(s.clientGatewaySessionID())
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -302,7 +307,16 @@ func (m *mcpRequestContext) servePOST(w http.ResponseWriter, r *http.Request) {
 
 	switch msg := rawMsg.(type) {
 	case *jsonrpc.Response:
-		if doNotForwardResponseToBackends(msg) {
+		if isToolApprovalResponse(msg) {
+			if s == nil {
+				errType = metrics.MCPErrorInvalidSessionID
+				onErrorResponse(w, http.StatusBadRequest, "missing session ID")
+				return
+			}
+			m.deliverToolApprovalResponse(s, msg)
+			w.Header().Set(sessionIDHeader, string(s.clientGatewaySessionID()))
+			w.WriteHeader(http.StatusAccepted)
+		} else if doNotForwardResponseToBackends(msg) {
 			w.Header().Set(sessionIDHeader, string(s.clientGatewaySessionID()))
 			w.WriteHeader(http.StatusAccepted)
 		} else {
@@ -533,7 +547,8 @@ func errorType(err error) metrics.MCPErrorType {
 	}
 
 	// Check for specific error types
-	if errors.Is(err, errBackendNotFound) || errors.Is(err, errSessionNotFound) || errors.Is(err, errInvalidToolName) {
+	if errors.Is(err, errBackendNotFound) || errors.Is(err, errSessionNotFound) || errors.Is(err, errInvalidToolName) ||
+		errors.Is(err, errInvalidResourceURI) || errors.Is(err, errInvalidPromptName) || errors.Is(err, errToolDefinitionDrift) {
 		return metrics.MCPErrorInvalidParam
 	}
 	var toolCallValidaitonError *errToolCall
@@ -700,7 +715,21 @@ func (m *mcpRequestContext) handleClientToServerResponse(ctx context.Context, s
 }
 
 func (m *mcpRequestContext) handleToolCallRequest(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request, p *mcp.CallToolParams, span tracingapi.MCPSpan, r *http.Request) (handlerResult, error) {
-	backendName, toolName, err := upstreamResourceName(p.Name)
+	if route := m.routes[s.route]; route != nil && route.toolSearch != nil {
+		switch p.Name {
+		case searchToolsToolName:
+			return handlerResult{}, m.handleSearchToolsRequest(ctx, s, w, req, route, p)
+		case callToolToolName:
+			// The call is handled as a direct call to the requested tool, so that it is subject to the same checks.
+			target, err := callToolTarget(p)
+			if err != nil {
+				return handlerResult{}, onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams,
+					fmt.Sprintf("invalid arguments for tool %s: %v", callToolToolName, err))
+			}
+			p = target
+		}
+	}
+	backendName, toolName, err := m.routes[s.route].upstreamToolName(p.Name)
 	if err != nil {
 		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid tool name %s: %v", p.Name, err))
 		return handlerResult{}, err
@@ -725,7 +754,6 @@ func (m *mcpRequestContext) handleToolCallRequest(ctx context.Context, s *sessio
 		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid tool name: %s", toolName))
 		return result, fmt.Errorf("%w: %s", errInvalidToolName, toolName)
 	}
-
 	// Enforce authentication if required by the route.
 	if route.authorization != nil {
 		httpPath := ""
@@ -743,17 +771,13 @@ func (m *mcpRequestContext) handleToolCallRequest(ctx context.Context, s *sessio
 			Params:     p,
 		})
 		if !allowed {
-			// Specify the minimum required scopes in the WWW-Authenticate header.
-			// Reference: https://mcp.mintlify.app/specification/2025-11-25/basic/authorization#runtime-insufficient-scope-errors
-			if len(requiredScopes) > 0 {
-				if challenge := buildInsufficientScopeHeader(requiredScopes, route.authorization.ResourceMetadataURL); challenge != "" {
-					w.Header().Set("WWW-Authenticate", challenge)
-				}
-			}
-			onErrorResponse(w, http.StatusForbidden, "access denied")
-			return result, fmt.Errorf("authorization failed")
+			return result, onAuthorizationDenied(w, route.authorization, requiredScopes)
 		}
 	}
+	if m.isToolBlocked(ctx, s, backendName, toolName) {
+		onErrorResponse(w, http.StatusForbidden, fmt.Sprintf("tool %s is blocked: its definition has not been approved", toolName))
+		return result, fmt.Errorf("%w: %s", errToolDefinitionDrift, toolName)
+	}
 
 	cse := s.getCompositeSessionEntry(backendName)
 	if cse == nil {
@@ -761,6 +785,31 @@ func (m *mcpRequestContext) handleToolCallRequest(ctx context.Context, s *sessio
 		return result, fmt.Errorf("%w: no MCP session found for backend %s", errSessionNotFound, backendName)
 	}
 
+	if err = route.injectFixedArguments(backendName, toolName, p); err != nil {
+		return result, onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams,
+			fmt.Sprintf("invalid arguments for tool %s: %v", p.Name, err))
+	}
+	if err = m.validateToolArguments(ctx, s, backend, toolName, p.Arguments); err != nil {
+		return result, onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams,
+			fmt.Sprintf("invalid arguments for tool %s: %v", p.Name, err))
+	}
+	if requiresToolApproval(backend, toolName) {
+		var sw *sseResponseWriter
+		if sw, err = m.approveToolCall(ctx, s, w, req, backend, toolName, p, r.Header); err != nil {
+			return result, err
+		}
+		if sw != nil {
+			// The response has been started as a stream of events to send the elicitation request.
+			defer sw.finish()
+			w = sw
+		}
+	}
+	// The rate limits are checked last, so that only the calls that are actually forwarded are counted.
+	if limit, retryAfter := m.checkToolRateLimits(ctx, s, backendName, toolName, r.Header); limit != nil {
+		m.metrics.WithBackend(backendName).RecordToolRateLimited(ctx, toolName)
+		return result, onJSONRPCErrorResponse(w, req, codeToolRateLimited, rateLimitedMessage(p.Name, limit, retryAfter))
+	}
+
 	// Send the request to the MCP backend listener.
 	p.Name = toolName
 	param, _ := json.Marshal(p)
@@ -772,6 +821,9 @@ func (m *mcpRequestContext) handleToolCallRequest(ctx context.Context, s *sessio
 		span.RecordRouteToBackend(backend.Name, string(cse.sessionID), false)
 	}
 	req.Params = param
+	if backend.OpenAPI != nil {
+		return result, m.invokeOpenAPIOperation(ctx, s, w, backend, req, p)
+	}
 	return result, m.invokeAndProxyResponse(ctx, s, w, backend, cse, req, p)
 }
 
@@ -817,7 +869,7 @@ func (m *mcpRequestContext) proxyResponseBody(ctx context.Context, s *session, w
 				body, _ = jsonrpc.EncodeMessage(msg)
 			case *jsonrpc.Response:
 				if req != nil {
-					if err = m.maybeResponseModify(ctx, req, msg, backend.Name); err != nil {
+					if err = m.maybeResponseModify(ctx, s, req, msg, backend); err != nil {
 						m.l.Error("failed to modify response", slog.String("error", err.Error()))
 						return err
 					}
@@ -888,7 +940,7 @@ func (m *mcpRequestContext) proxyResponseBody(ctx context.Context, s *session, w
 				case *jsonrpc.Response:
 					// Correct the ID to match the original request if possible.
 					if req != nil {
-						if err = m.maybeResponseModify(ctx, req, msg, backend.Name); err != nil {
+						if err = m.maybeResponseModify(ctx, s, req, msg, backend); err != nil {
 							m.l.Error("failed to modify response", slog.String("error", err.Error()))
 							continue
 						}
@@ -956,20 +1008,37 @@ func (m *mcpRequestContext) maybeUpdateProgressTokenMetadata(ctx context.Context
 }
 
 // maybeResponseModify modifies the client->server response to include the backend name where needed.
-func (m *mcpRequestContext) maybeResponseModify(_ context.Context, req *jsonrpc.Request, msg *jsonrpc.Response, backend filterapi.MCPBackendName) error {
-	if req.Method != "resources/read" || msg.Result == nil {
+func (m *mcpRequestContext) maybeResponseModify(ctx context.Context, s *session, req *jsonrpc.Request, msg *jsonrpc.Response, backend filterapi.MCPBackend) error {
+	if msg.Result == nil {
 		return nil
 	}
-
-	result := &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{}}
-	if err := json.Unmarshal(msg.Result, result); err != nil {
-		return fmt.Errorf("failed to unmarshal resources/read result: %w", err)
-	}
-	for _, res := range result.Contents {
-		res.URI = downstreamResourceURI(res.URI, backend)
+	switch req.Method {
+	case "resources/read":
+		result := &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{}}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("failed to unmarshal resources/read result: %w", err)
+		}
+		for _, res := range result.Contents {
+			res.URI = downstreamResourceURI(res.URI, backend.Name)
+		}
+		msg.Result, _ = json.Marshal(result) // Already decoded result, so ignore error.
+	case "tools/call":
+		if backend.SchemaValidation == nil || backend.SchemaValidation.StructuredContent == "" {
+			return nil
+		}
+		// The request params hold the upstream tool name at this point.
+		params := &mcp.CallToolParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return fmt.Errorf("failed to unmarshal tools/call params: %w", err)
+		}
+		result := &mcp.CallToolResult{}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("failed to unmarshal tools/call result: %w", err)
+		}
+		if m.maybeValidateStructuredContent(ctx, s, backend, params.Name, result) {
+			msg.Result, _ = json.Marshal(result) // Already decoded result, so ignore error.
+		}
 	}
-	msg.Result, _ = json.Marshal(result) // Already decoded result, so ignore error.
-
 	return nil
 }
 
@@ -1107,6 +1176,15 @@ func (m *mcpRequestContext) handleResourceReadRequest(ctx context.Context, s *se
 		onErrorResponse(w, http.StatusNotFound, fmt.Sprintf("unknown backend %s", backendName))
 		return result, fmt.Errorf("%w: unknown backend %s in resource name %s", errBackendNotFound, backendName, p.URI)
 	}
+	if !m.routes[s.route].allowsResource(backendName, resourceName) {
+		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid resource URI: %s", p.URI))
+		return result, fmt.Errorf("%w: %s", errInvalidResourceURI, p.URI)
+	}
+	if err = m.authorizeBackendRequest(w, s, &authorizationRequest{
+		MCPMethod: req.Method, Backend: backendName, Resource: resourceName, Params: p,
+	}); err != nil {
+		return result, err
+	}
 	sess := s.getCompositeSessionEntry(backendName)
 	if sess == nil {
 		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("no MCP session found for backend %s", backendName))
@@ -1158,6 +1236,15 @@ func (m *mcpRequestContext) handleResourcesSubscriptionRequest(ctx context.Conte
 		onErrorResponse(w, http.StatusNotFound, fmt.Sprintf("unknown backend %s", backendName))
 		return result, fmt.Errorf("%w: unknown backend %s in resource name %s", errBackendNotFound, backendName, uri)
 	}
+	if !m.routes[s.route].allowsResource(backendName, resourceName) {
+		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid resource URI: %s", uri))
+		return result, fmt.Errorf("%w: %s", errInvalidResourceURI, uri)
+	}
+	if err = m.authorizeBackendRequest(w, s, &authorizationRequest{
+		MCPMethod: req.Method, Backend: backendName, Resource: resourceName, Params: p,
+	}); err != nil {
+		return result, err
+	}
 	cse := s.getCompositeSessionEntry(backendName)
 	if cse == nil {
 		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("no MCP session found for backend %s", backendName))
@@ -1315,6 +1402,15 @@ func (m *mcpRequestContext) handlePromptGetRequest(ctx context.Context, s *sessi
 		onErrorResponse(w, http.StatusNotFound, fmt.Sprintf("unknown backend %s", backendName))
 		return result, fmt.Errorf("%w: unknown backend %s in prompt name %s", errBackendNotFound, backendName, p.Name)
 	}
+	if !m.routes[s.route].allowsPrompt(backendName, promptName) {
+		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid prompt name: %s", p.Name))
+		return result, fmt.Errorf("%w: %s", errInvalidPromptName, p.Name)
+	}
+	if err = m.authorizeBackendRequest(w, s, &authorizationRequest{
+		MCPMethod: req.Method, Backend: backendName, Prompt: promptName, Params: p,
+	}); err != nil {
+		return result, err
+	}
 	cse := s.getCompositeSessionEntry(backendName)
 	if cse == nil {
 		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("no MCP session found for backend %s", backendName))
@@ -1359,6 +1455,24 @@ func (m *mcpRequestContext) handleCompletionComplete(ctx context.Context, s *ses
 		onErrorResponse(w, http.StatusNotFound, fmt.Sprintf("unknown backend %s", backendName))
 		return result, fmt.Errorf("%w: unknown backend %s in resource name %s", errBackendNotFound, backendName, cmp.Or(param.Ref.Name, param.Ref.URI))
 	}
+	route := m.routes[s.route]
+	switch {
+	case param.Ref.Type == "ref/prompt" && !route.allowsPrompt(backendName, param.Ref.Name):
+		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid prompt name: %s", param.Ref.Name))
+		return result, fmt.Errorf("%w: %s", errInvalidPromptName, param.Ref.Name)
+	case param.Ref.Type == "ref/resource" && !route.allowsResource(backendName, param.Ref.URI):
+		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid resource URI: %s", param.Ref.URI))
+		return result, fmt.Errorf("%w: %s", errInvalidResourceURI, param.Ref.URI)
+	}
+	authzReq := &authorizationRequest{MCPMethod: req.Method, Backend: backendName, Params: param}
+	if param.Ref.Type == "ref/prompt" {
+		authzReq.Prompt = param.Ref.Name
+	} else {
+		authzReq.Resource = param.Ref.URI
+	}
+	if err = m.authorizeBackendRequest(w, s, authzReq); err != nil {
+		return result, err
+	}
 
 	// Send the request to the MCP backend listener.
 	cse := s.getCompositeSessionEntry(backend.Name)
@@ -1448,6 +1562,11 @@ func (m *mcpRequestContext) handleClientToServerNotificationsProgress(ctx contex
 func (m *mcpRequestContext) invokeAndProxyResponse(ctx context.Context, s *session, w http.ResponseWriter, backend filterapi.MCPBackend, sess *compositeSessionEntry, req *jsonrpc.Request, params mcp.Params) error {
 	resp, err := m.invokeJSONRPCRequest(ctx, s.route, backend, sess, req, params)
 	if err != nil {
+		var exchangeErr *tokenExchangeError
+		if errors.As(err, &exchangeErr) {
+			onErrorResponse(w, exchangeErr.statusCode, fmt.Sprintf("call to %s failed: %v", backend.Name, err))
+			return err
+		}
 		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("call to %s failed: %v", backend.Name, err))
 		return err
 	}
@@ -1497,24 +1616,76 @@ type (
 	// broadCastResponseMergeFn is a function that merges multiple broadCastResponse into a single response type.
 	//
 	// Used in sendToAllBackendsAndAggregateResponses.
-	broadCastResponseMergeFn[T any] func(*session, []broadCastResponse[T]) T
+	broadCastResponseMergeFn[T any] func(context.Context, *session, []broadCastResponse[T]) T
 )
 
 // sendToAllBackendsAndAggregateResponses is a generic function that can be used for handling all "list" variant
 // JSON-RPC methods that require sending the request to all backends and aggregating the responses.
 //
 // The mergeFn is used to merge the responses from all backends into a single response that will be sent back to the client.
-func sendToAllBackendsAndAggregateResponses[responseType any, paramsType mcp.Params](ctx context.Context, m *mcpRequestContext, w http.ResponseWriter, s *session, request *jsonrpc.Request, p paramsType, mergeFn broadCastResponseMergeFn[responseType], span tracingapi.MCPSpan, filter func(*compositeSessionEntry) bool) error {
+//
+// The cursor points to the cursor field of the params for paginated lists, and is nil otherwise. When the client sends
+// a composite cursor returned by a previous page, the request is only sent to the backends that still have pages, each
+// with its own cursor.
+func sendToAllBackendsAndAggregateResponses[responseType any, paramsType mcp.Params](ctx context.Context, m *mcpRequestContext, w http.ResponseWriter, s *session, request *jsonrpc.Request, p paramsType, cursor *string, mergeFn broadCastResponseMergeFn[responseType], span tracingapi.MCPSpan, filter func(*compositeSessionEntry) bool) error {
 	// Mark that per-backend metrics will be recorded to avoid duplicate recording in defer.
 	// This must be set early to handle any early returns that might occur.
 	m.perBackendMetricsRecorded = true
 
-	encoded, _ := json.Marshal(p)
-	request.Params = encoded
-	backendMsgs := s.sendToBackendsFiltered(ctx, http.MethodPost, request, p, span, filter)
+	// Backends the request is not authorized for are skipped, as if they did not support the method.
+	if route := m.routes[s.route]; route != nil && route.authorization != nil {
+		capabilityFilter := filter
+		filter = func(cse *compositeSessionEntry) bool {
+			return (capabilityFilter == nil || capabilityFilter(cse)) && m.authorizeFanOut(route.authorization, request.Method, cse.backendName, p)
+		}
+	}
+
+	if cursor == nil || *cursor == "" {
+		encoded, _ := json.Marshal(p)
+		request.Params = encoded
+		backendMsgs := s.sendToBackendsFiltered(ctx, http.MethodPost, request, p, span, filter)
+		return sendToAllBackendsAndAggregateResponsesImpl(ctx, backendMsgs, m, w, s, request, p, mergeFn)
+	}
+
+	backendCursors, err := decodeListCursor(m.sessionCrypto, request.Method, *cursor)
+	if err != nil {
+		m.l.Error("failed to decode list cursor", slog.String("method", request.Method), slog.String("error", err.Error()))
+		return onJSONRPCErrorResponse(w, request, jsonrpc.CodeInvalidParams, errInvalidCursor.Error())
+	}
+	compositeCursor := *cursor
+	requests := make(map[filterapi.MCPBackendName]*jsonrpc.Request, len(backendCursors))
+	for backendName, backendCursor := range backendCursors {
+		*cursor = backendCursor
+		encoded, _ := json.Marshal(p)
+		backendRequest := *request
+		backendRequest.Params = encoded
+		requests[backendName] = &backendRequest
+	}
+	*cursor = compositeCursor
+	backendMsgs := s.sendPerBackendRequests(ctx, http.MethodPost,
+		func(backendName filterapi.MCPBackendName) *jsonrpc.Request { return requests[backendName] }, p, span,
+		func(cse *compositeSessionEntry) bool {
+			_, ok := requests[cse.backendName]
+			return ok && (filter == nil || filter(cse))
+		})
 	return sendToAllBackendsAndAggregateResponsesImpl(ctx, backendMsgs, m, w, s, request, p, mergeFn)
 }
 
+// onJSONRPCErrorResponse writes a JSON-RPC error response to the given request and returns the error so that
+// it is recorded in the metrics.
+func onJSONRPCErrorResponse(w http.ResponseWriter, request *jsonrpc.Request, code int64, message string) error {
+	rpcErr := &jsonrpc.Error{Code: code, Message: message}
+	encoded, err := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: request.ID, Error: rpcErr})
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(encoded)
+	return rpcErr
+}
+
 // sendToAllBackendsAndAggregateResponsesImpl is the implementation of sendToAllBackendsAndAggregateResponses for better testability.
 func sendToAllBackendsAndAggregateResponsesImpl[responseType any, paramsType mcp.Params](ctx context.Context, events <-chan *backendEvent, m *mcpRequestContext, w http.ResponseWriter, s *session, request *jsonrpc.Request, params paramsType, mergeFn broadCastResponseMergeFn[responseType]) error {
 	logger := m.l.With(slog.String("method", request.Method), slog.String("client_gateway_session_id", string(s.clientGatewaySessionID())))
@@ -1577,7 +1748,7 @@ func sendToAllBackendsAndAggregateResponsesImpl[responseType any, paramsType mcp
 		}
 	}
 
-	mergedResp := mergeFn(s, responses)
+	mergedResp := mergeFn(ctx, s, responses)
 	encodedResp, err := json.Marshal(mergedResp)
 	if err != nil {
 		return fmt.Errorf("failed to marshal response: %w", err)
@@ -1619,18 +1790,19 @@ func parseParamsAndMaybeStartSpan[paramType mcp.Params](ctx context.Context, m *
 
 // handleToolsListRequest handles the "tools/list" JSON-RPC method.
 //
-// This aggregates and returns the list of tools from all backends.
+// This aggregates and returns the list of tools from all backends, or the meta tools of the tool search if enabled.
 func (m *mcpRequestContext) handleToolsListRequest(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request, p *mcp.ListToolsParams, span tracingapi.MCPSpan) error {
-	// TODO: use cursor for pagination, but in spec it's "SHOULD" not "MUST".
-	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, m.mergeToolsList, span,
+	if route := m.routes[s.route]; route != nil && route.toolSearch != nil {
+		return m.handleToolSearchListRequest(w, s, req)
+	}
+	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, &p.Cursor, m.mergeToolsList, span,
 		func(cse *compositeSessionEntry) bool { return cse.capabilities != nil && cse.capabilities.Tools != nil })
 }
 
 // handleResourceListRequest handles the "resources/list" JSON-RPC method.
 // This aggregates and returns the list of resources from all backends.
 func (m *mcpRequestContext) handleResourceListRequest(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request, p *mcp.ListResourcesParams, span tracingapi.MCPSpan) error {
-	// TODO: use cursor for pagination, but in spec it's "SHOULD" not "MUST".
-	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, m.mergeResourceList, span,
+	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, &p.Cursor, m.mergeResourceList, span,
 		func(cse *compositeSessionEntry) bool {
 			return cse.capabilities != nil && cse.capabilities.Resources != nil
 		})
@@ -1638,8 +1810,7 @@ func (m *mcpRequestContext) handleResourceListRequest(ctx context.Context, s *se
 
 // handleResourcesTemplatesListRequest handles the "resources/templates/list" JSON-RPC method.
 func (m *mcpRequestContext) handleResourcesTemplatesListRequest(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request, p *mcp.ListResourceTemplatesParams, span tracingapi.MCPSpan) error {
-	// TODO: use cursor for pagination, but in spec it's "SHOULD" not "MUST".
-	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, m.mergeResourcesTemplateList, span,
+	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, &p.Cursor, m.mergeResourcesTemplateList, span,
 		func(cse *compositeSessionEntry) bool {
 			return cse.capabilities != nil && cse.capabilities.Resources != nil
 		})
@@ -1648,8 +1819,7 @@ func (m *mcpRequestContext) handleResourcesTemplatesListRequest(ctx context.Cont
 // handlePromptListRequest handles the "prompts/list" JSON-RPC method.
 // This aggregates and returns the list of prompts from all backends.
 func (m *mcpRequestContext) handlePromptListRequest(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request, p *mcp.ListPromptsParams, span tracingapi.MCPSpan) error {
-	// TODO: use cursor for pagination, but in spec it's "SHOULD" not "MUST".
-	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, m.mergePromptsList, span,
+	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, req, p, &p.Cursor, m.mergePromptsList, span,
 		func(cse *compositeSessionEntry) bool {
 			return cse.capabilities != nil && cse.capabilities.Prompts != nil
 		})
@@ -1657,7 +1827,7 @@ func (m *mcpRequestContext) handlePromptListRequest(ctx context.Context, s *sess
 
 // handleSetLoggingLevel handles the "logging/setLevel" JSON-RPC method.
 func (m *mcpRequestContext) handleSetLoggingLevel(ctx context.Context, s *session, w http.ResponseWriter, originalRequest *jsonrpc.Request, p *mcp.SetLoggingLevelParams, span tracingapi.MCPSpan) error {
-	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, originalRequest, p, func(*session, []broadCastResponse[any]) any {
+	return sendToAllBackendsAndAggregateResponses(ctx, m, w, s, originalRequest, p, nil, func(context.Context, *session, []broadCastResponse[any]) any {
 		return struct{}{}
 	}, span, func(cse *compositeSessionEntry) bool {
 		return cse.capabilities != nil && cse.capabilities.Logging != nil
@@ -1665,7 +1835,7 @@ func (m *mcpRequestContext) handleSetLoggingLevel(ctx context.Context, s *sessio
 }
 
 // mergeToolsList merges the list of tools from all backends and prepare the response message to be sent back to the client.
-func (m *mcpRequestContext) mergeToolsList(s *session, responses []broadCastResponse[mcp.ListToolsResult]) mcp.ListToolsResult {
+func (m *mcpRequestContext) mergeToolsList(ctx context.Context, s *session, responses []broadCastResponse[mcp.ListToolsResult]) mcp.ListToolsResult {
 	// Use a non-nil empty slice so JSON encodes as [] not null; some clients reject tools:null.
 	resp := mcp.ListToolsResult{Tools: make([]*mcp.Tool, 0)}
 	route := m.routes[s.route]
@@ -1674,16 +1844,25 @@ func (m *mcpRequestContext) mergeToolsList(s *session, responses []broadCastResp
 		return resp
 	}
 
+	nextCursors := make(map[string]string)
 	// Aggregate the tools from all responses.
 	// A backend specific prefix is added to the tool name to avoid name collision.
 	// The tools are filtered based on the toolFilters configured for each backend,
 	// and additionally by authorization rules so callers only see tools they can invoke.
+	// Tools whose definitions don't match their approved fingerprints are handled per the tool pinning of the backend.
 	for _, r := range responses {
 		selector := route.toolSelectors[r.backendName]
+		pinning := route.backends[r.backendName].ToolPinning
 		for _, tool := range r.res.Tools {
 			if selector != nil && !selector.allows(tool.Name) {
 				continue
 			}
+			if pinning != nil && !m.pinTool(ctx, s, r.backendName, pinning, tool) {
+				continue
+			}
+			if validation := route.backends[r.backendName].SchemaValidation; validation != nil {
+				m.cacheToolSchemas(s, r.backendName, validation, tool)
+			}
 			if route.authorization != nil {
 				allowed, _ := m.authorizeRequest(route.authorization, &authorizationRequest{
 					Headers:   m.requestHeaders,
@@ -1695,53 +1874,103 @@ func (m *mcpRequestContext) mergeToolsList(s *session, responses []broadCastResp
 					continue
 				}
 			}
-			tool.Name = downstreamResourceName(tool.Name, r.backendName)
+			route.applyToolOverride(r.backendName, tool)
+			tool.Name = route.downstreamToolName(r.backendName, tool.Name)
 			resp.Tools = append(resp.Tools, tool)
 		}
+		if r.res.NextCursor != "" {
+			nextCursors[r.backendName] = r.res.NextCursor
+		}
 	}
-
+	resp.NextCursor = m.nextListCursor("tools/list", nextCursors)
 	return resp
 }
 
 // mergeResourceList merges the list of resources from all backends and prepare the response message to be sent back to the client.
-func (m *mcpRequestContext) mergeResourceList(_ *session, responses []broadCastResponse[mcp.ListResourcesResult]) mcp.ListResourcesResult {
+func (m *mcpRequestContext) mergeResourceList(ctx context.Context, s *session, responses []broadCastResponse[mcp.ListResourcesResult]) mcp.ListResourcesResult {
 	// Aggregate the resources from all responses with some logic to match the actual proxy behavior.
 	// TODO: do we need a more sophisticated merging logic here?
-	// TODO: how to handle NextCursor?
 	resp := mcp.ListResourcesResult{Resources: make([]*mcp.Resource, 0)}
+	nextCursors := make(map[string]string)
+	route := m.routes[s.route]
 	for _, r := range responses {
 		for _, res := range r.res.Resources {
+			if !route.allowsResource(r.backendName, res.URI) {
+				continue
+			}
+			if route != nil && route.authorization != nil {
+				allowed, _ := m.authorizeRequest(route.authorization, m.newAuthorizationRequest(&authorizationRequest{
+					MCPMethod: "resources/read",
+					Backend:   r.backendName,
+					Resource:  res.URI,
+				}))
+				if !allowed {
+					continue
+				}
+			}
 			res.Name = downstreamResourceName(res.Name, r.backendName)
 			res.URI = downstreamResourceURI(res.URI, r.backendName)
 			resp.Resources = append(resp.Resources, res)
 		}
+		if r.res.NextCursor != "" {
+			nextCursors[r.backendName] = r.res.NextCursor
+		}
 	}
+	resp.NextCursor = m.nextListCursor("resources/list", nextCursors)
 	return resp
 }
 
 // mergeResourcesTemplateList merges the list of resource templates from all backends and prepare the response message to be sent back to the client.
-func (m *mcpRequestContext) mergeResourcesTemplateList(_ *session, responses []broadCastResponse[mcp.ListResourceTemplatesResult]) mcp.ListResourceTemplatesResult {
+func (m *mcpRequestContext) mergeResourcesTemplateList(ctx context.Context, s *session, responses []broadCastResponse[mcp.ListResourceTemplatesResult]) mcp.ListResourceTemplatesResult {
 	resp := mcp.ListResourceTemplatesResult{ResourceTemplates: make([]*mcp.ResourceTemplate, 0)}
+	nextCursors := make(map[string]string)
+	route := m.routes[s.route]
 	for _, r := range responses {
 		for _, res := range r.res.ResourceTemplates {
+			if !route.allowsResource(r.backendName, res.URITemplate) {
+				continue
+			}
 			res.Name = downstreamResourceName(res.Name, r.backendName)
 			res.URITemplate = downstreamResourceURI(res.URITemplate, r.backendName)
 			resp.ResourceTemplates = append(resp.ResourceTemplates, res)
 		}
+		if r.res.NextCursor != "" {
+			nextCursors[r.backendName] = r.res.NextCursor
+		}
 	}
+	resp.NextCursor = m.nextListCursor("resources/templates/list", nextCursors)
 	return resp
 }
 
 // mergePromptsList merges the list of prompts from all backends and prepare the response message to be sent back to the client.
-func (m *mcpRequestContext) mergePromptsList(_ *session, responses []broadCastResponse[mcp.ListPromptsResult]) mcp.ListPromptsResult {
+func (m *mcpRequestContext) mergePromptsList(ctx context.Context, s *session, responses []broadCastResponse[mcp.ListPromptsResult]) mcp.ListPromptsResult {
 	// Aggregate the resources from all responses with some logic to match the actual proxy behavior.
 	aggregatedResponse := mcp.ListPromptsResult{Prompts: make([]*mcp.Prompt, 0)}
+	nextCursors := make(map[string]string)
+	route := m.routes[s.route]
 	for _, r := range responses {
 		for _, res := range r.res.Prompts {
+			if !route.allowsPrompt(r.backendName, res.Name) {
+				continue
+			}
+			if route != nil && route.authorization != nil {
+				allowed, _ := m.authorizeRequest(route.authorization, m.newAuthorizationRequest(&authorizationRequest{
+					MCPMethod: "prompts/get",
+					Backend:   r.backendName,
+					Prompt:    res.Name,
+				}))
+				if !allowed {
+					continue
+				}
+			}
 			res.Name = downstreamResourceName(res.Name, r.backendName)
 			aggregatedResponse.Prompts = append(aggregatedResponse.Prompts, res)
 		}
+		if r.res.NextCursor != "" {
+			nextCursors[r.backendName] = r.res.NextCursor
+		}
 	}
+	aggregatedResponse.NextCursor = m.nextListCursor("prompts/list", nextCursors)
 	return aggregatedResponse
 }
 
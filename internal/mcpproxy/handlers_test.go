@@ -21,6 +21,7 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -32,6 +33,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
 	"github.com/envoyproxy/ai-gateway/internal/json"
@@ -74,8 +76,12 @@ func newTestMCPProxyWithTracer(t tracingapi.MCPTracer) *mcpRequestContext {
 					},
 				},
 			},
-			tracer: t,
-			l:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			tracer:           t,
+			l:                slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			tokenExchanger:   newTokenExchanger(),
+			toolSchemas:      newSessionToolCache[*toolSchemas](),
+			toolFingerprints: newSessionToolCache[string](),
+			rateLimiter:      callcounter.NewLocal(),
 		},
 	}
 }
@@ -641,7 +647,7 @@ func TestMergeToolsList_AuthorizationFiltering(t *testing.T) {
 				proxy.requestHeaders = http.Header{}
 			}
 
-			result := proxy.mergeToolsList(session, responses)
+			result := proxy.mergeToolsList(t.Context(), session, responses)
 
 			got := make([]string, len(result.Tools))
 			for i, tool := range result.Tools {
@@ -652,6 +658,314 @@ func TestMergeToolsList_AuthorizationFiltering(t *testing.T) {
 	}
 }
 
+func TestMergeResourceAndPromptLists_Selectors(t *testing.T) {
+	proxy := newTestMCPProxy()
+	route := proxy.routes["test-route"]
+	var err error
+	route.resourceSelectors = map[filterapi.MCPBackendName]*toolSelector{}
+	route.resourceSelectors["backend1"], err = newToolSelector(&filterapi.MCPResourceSelector{
+		IncludeRegex: []string{"^file:///public/"},
+	}, "backend1", "test-route")
+	require.NoError(t, err)
+	route.promptSelectors = map[filterapi.MCPBackendName]*toolSelector{}
+	route.promptSelectors["backend1"], err = newToolSelector(&filterapi.MCPPromptSelector{Exclude: []string{"internal"}}, "backend1", "test-route")
+	require.NoError(t, err)
+	s := &session{route: "test-route"}
+
+	resources := proxy.mergeResourceList(t.Context(), s, []broadCastResponse[mcp.ListResourcesResult]{
+		{backendName: "backend1", res: mcp.ListResourcesResult{Resources: []*mcp.Resource{
+			{Name: "readme", URI: "file:///public/readme.md"},
+			{Name: "salaries", URI: "file:///private/salaries.csv"},
+		}}},
+		{backendName: "backend2", res: mcp.ListResourcesResult{Resources: []*mcp.Resource{
+			{Name: "salaries", URI: "file:///private/salaries.csv"},
+		}}},
+	})
+	var names []string
+	for _, r := range resources.Resources {
+		names = append(names, r.Name)
+	}
+	require.Equal(t, []string{"backend1__readme", "backend2__salaries"}, names)
+
+	templates := proxy.mergeResourcesTemplateList(t.Context(), s, []broadCastResponse[mcp.ListResourceTemplatesResult]{
+		{backendName: "backend1", res: mcp.ListResourceTemplatesResult{ResourceTemplates: []*mcp.ResourceTemplate{
+			{Name: "public", URITemplate: "file:///public/{name}"},
+			{Name: "private", URITemplate: "file:///private/{name}"},
+		}}},
+	})
+	require.Len(t, templates.ResourceTemplates, 1)
+	require.Equal(t, "backend1__public", templates.ResourceTemplates[0].Name)
+
+	prompts := proxy.mergePromptsList(t.Context(), s, []broadCastResponse[mcp.ListPromptsResult]{
+		{backendName: "backend1", res: mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "summarize"}, {Name: "internal"}}}},
+		{backendName: "backend2", res: mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "internal"}}}},
+	})
+	names = nil
+	for _, p := range prompts.Prompts {
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{"backend1__summarize", "backend2__internal"}, names)
+}
+
+func TestMCPProxy_ResourceAndPromptSelectorsEnforced(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("requests filtered out by the selectors should not be sent to the backend")
+	}))
+	t.Cleanup(testServer.Close)
+
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = testServer.URL
+	route := proxy.routes["test-route"]
+	var err error
+	route.resourceSelectors = map[filterapi.MCPBackendName]*toolSelector{}
+	route.resourceSelectors["backend1"], err = newToolSelector(&filterapi.MCPResourceSelector{Exclude: []string{"file:///secret"}}, "backend1", "test-route")
+	require.NoError(t, err)
+	route.promptSelectors = map[filterapi.MCPBackendName]*toolSelector{}
+	route.promptSelectors["backend1"], err = newToolSelector(&filterapi.MCPPromptSelector{Include: []string{"summarize"}}, "backend1", "test-route")
+	require.NoError(t, err)
+	s := &session{
+		reqCtx:             proxy,
+		route:              "test-route",
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"backend1": {sessionID: "test-session"}},
+	}
+	reqID, _ := jsonrpc.MakeID("id")
+	secretURI := downstreamResourceURI("file:///secret", "backend1")
+
+	for _, tc := range []struct {
+		name    string
+		call    func(w http.ResponseWriter) error
+		expBody string
+		expErr  error
+	}{
+		{
+			name: "resources/read",
+			call: func(w http.ResponseWriter) error {
+				_, err := proxy.handleResourceReadRequest(t.Context(), s, w, &jsonrpc.Request{ID: reqID, Method: "resources/read"},
+					&mcp.ReadResourceParams{URI: secretURI})
+				return err
+			},
+			expBody: "invalid resource URI: " + secretURI,
+			expErr:  errInvalidResourceURI,
+		},
+		{
+			name: "resources/subscribe",
+			call: func(w http.ResponseWriter) error {
+				_, err := proxy.handleResourcesSubscribeRequest(t.Context(), s, w, &jsonrpc.Request{ID: reqID, Method: "resources/subscribe"},
+					&mcp.SubscribeParams{URI: secretURI}, nil)
+				return err
+			},
+			expBody: "invalid resource URI: " + secretURI,
+			expErr:  errInvalidResourceURI,
+		},
+		{
+			name: "resources/unsubscribe",
+			call: func(w http.ResponseWriter) error {
+				_, err := proxy.handleResourcesUnsubscribeRequest(t.Context(), s, w, &jsonrpc.Request{ID: reqID, Method: "resources/unsubscribe"},
+					&mcp.UnsubscribeParams{URI: secretURI}, nil)
+				return err
+			},
+			expBody: "invalid resource URI: " + secretURI,
+			expErr:  errInvalidResourceURI,
+		},
+		{
+			name: "prompts/get",
+			call: func(w http.ResponseWriter) error {
+				_, err := proxy.handlePromptGetRequest(t.Context(), s, w, &jsonrpc.Request{ID: reqID, Method: "prompts/get"},
+					&mcp.GetPromptParams{Name: "backend1__internal"})
+				return err
+			},
+			expBody: "invalid prompt name: backend1__internal",
+			expErr:  errInvalidPromptName,
+		},
+		{
+			name: "completion/complete prompt",
+			call: func(w http.ResponseWriter) error {
+				_, err := proxy.handleCompletionComplete(t.Context(), s, w, &jsonrpc.Request{ID: reqID, Method: "completion/complete"},
+					&mcp.CompleteParams{Ref: &mcp.CompleteReference{Type: "ref/prompt", Name: "backend1__internal"}}, nil)
+				return err
+			},
+			expBody: "invalid prompt name: internal",
+			expErr:  errInvalidPromptName,
+		},
+		{
+			name: "completion/complete resource",
+			call: func(w http.ResponseWriter) error {
+				_, err := proxy.handleCompletionComplete(t.Context(), s, w, &jsonrpc.Request{ID: reqID, Method: "completion/complete"},
+					&mcp.CompleteParams{Ref: &mcp.CompleteReference{Type: "ref/resource", URI: secretURI}}, nil)
+				return err
+			},
+			expBody: "invalid resource URI: file:///secret",
+			expErr:  errInvalidResourceURI,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			err := tc.call(rr)
+			require.ErrorIs(t, err, tc.expErr)
+			require.Equal(t, metrics.MCPErrorInvalidParam, errorType(err))
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Equal(t, tc.expBody, rr.Body.String())
+		})
+	}
+}
+
+func TestMCPProxy_ResourcePromptAndMethodAuthorization(t *testing.T) {
+	auth, err := compileAuthorization(&filterapi.MCPRouteAuthorization{
+		DefaultAction: filterapi.AuthorizationActionDeny,
+		Rules: []filterapi.MCPRouteAuthorizationRule{
+			{
+				Action: filterapi.AuthorizationActionAllow,
+				Source: &filterapi.MCPAuthorizationSource{JWT: filterapi.JWTSource{Scopes: []string{"sre"}}},
+				Target: &filterapi.MCPAuthorizationTarget{
+					Resources: []filterapi.ResourceTarget{{Backend: "backend1", URI: "schema://*"}},
+					Prompts:   []filterapi.PromptTarget{{Backend: "backend1", Prompt: "incident"}},
+				},
+			},
+			{
+				Action: filterapi.AuthorizationActionDeny,
+				Target: &filterapi.MCPAuthorizationTarget{
+					Methods: []filterapi.MethodTarget{{Method: "resources/templates/list", Backend: "backend2"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	newProxy := func(scopes ...string) *mcpRequestContext {
+		proxy := newTestMCPProxy()
+		proxy.routes["test-route"].authorization = auth
+		claims := jwt.MapClaims{}
+		if len(scopes) > 0 {
+			claims["scope"] = scopes
+		}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		proxy.requestHeaders = http.Header{"Authorization": []string{"Bearer " + token}}
+		return proxy
+	}
+	s := &session{route: "test-route"}
+
+	t.Run("lists are filtered", func(t *testing.T) {
+		resourcesResponses := []broadCastResponse[mcp.ListResourcesResult]{
+			{backendName: "backend1", res: mcp.ListResourcesResult{Resources: []*mcp.Resource{
+				{Name: "users-schema", URI: "schema://users"},
+				{Name: "readme", URI: "file:///readme.md"},
+			}}},
+		}
+		promptsResponses := []broadCastResponse[mcp.ListPromptsResult]{
+			{backendName: "backend1", res: mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "incident"}, {Name: "summarize"}}}},
+		}
+		for _, tc := range []struct {
+			scopes       []string
+			expResources []string
+			expPrompts   []string
+		}{
+			{scopes: []string{"sre"}, expResources: []string{"backend1__users-schema", "backend1__readme"}, expPrompts: []string{"backend1__incident", "backend1__summarize"}},
+			{scopes: []string{"dev"}, expResources: []string{"backend1__readme"}, expPrompts: []string{"backend1__summarize"}},
+		} {
+			t.Run(strings.Join(tc.scopes, ","), func(t *testing.T) {
+				proxy := newProxy(tc.scopes...)
+				// The merge functions rename the items in place, so each run gets fresh copies.
+				resources := proxy.mergeResourceList(t.Context(), s, []broadCastResponse[mcp.ListResourcesResult]{{
+					backendName: "backend1",
+					res:         mcp.ListResourcesResult{Resources: cloneResources(resourcesResponses[0].res.Resources)},
+				}})
+				var names []string
+				for _, r := range resources.Resources {
+					names = append(names, r.Name)
+				}
+				require.Equal(t, tc.expResources, names)
+
+				prompts := proxy.mergePromptsList(t.Context(), s, []broadCastResponse[mcp.ListPromptsResult]{{
+					backendName: "backend1",
+					res:         mcp.ListPromptsResult{Prompts: clonePrompts(promptsResponses[0].res.Prompts)},
+				}})
+				names = nil
+				for _, p := range prompts.Prompts {
+					names = append(names, p.Name)
+				}
+				require.Equal(t, tc.expPrompts, names)
+			})
+		}
+	})
+
+	t.Run("requests are denied", func(t *testing.T) {
+		proxy := newProxy("dev")
+		reqID, _ := jsonrpc.MakeID("id")
+		sess := &session{
+			reqCtx:             proxy,
+			route:              "test-route",
+			perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"backend1": {sessionID: "test-session"}},
+		}
+
+		rr := httptest.NewRecorder()
+		_, err := proxy.handleResourceReadRequest(t.Context(), sess, rr, &jsonrpc.Request{ID: reqID, Method: "resources/read"},
+			&mcp.ReadResourceParams{URI: downstreamResourceURI("schema://users", "backend1")})
+		require.ErrorContains(t, err, "authorization failed")
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		require.Contains(t, rr.Header().Get("WWW-Authenticate"), `scope="sre"`)
+
+		rr = httptest.NewRecorder()
+		_, err = proxy.handlePromptGetRequest(t.Context(), sess, rr, &jsonrpc.Request{ID: reqID, Method: "prompts/get"},
+			&mcp.GetPromptParams{Name: "backend1__incident"})
+		require.ErrorContains(t, err, "authorization failed")
+		require.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = httptest.NewRecorder()
+		_, err = proxy.handleCompletionComplete(t.Context(), sess, rr, &jsonrpc.Request{ID: reqID, Method: "completion/complete"},
+			&mcp.CompleteParams{Ref: &mcp.CompleteReference{Type: "ref/resource", URI: downstreamResourceURI("schema://{table}", "backend1")}}, nil)
+		require.ErrorContains(t, err, "authorization failed")
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("fan-out skips denied backends", func(t *testing.T) {
+		var called sync.Map
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called.Store(r.Header.Get(internalapi.MCPBackendHeader), true)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":"id","result":{"resourceTemplates":[]}}`))
+		}))
+		t.Cleanup(testServer.Close)
+
+		proxy := newProxy("dev")
+		proxy.backendListenerAddr = testServer.URL
+		resources := &mcp.ServerCapabilities{Resources: &mcp.ResourceCapabilities{}}
+		sess := &session{
+			reqCtx: proxy,
+			route:  "test-route",
+			perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{
+				"backend1": {backendName: "backend1", sessionID: "session-1", capabilities: resources},
+				"backend2": {backendName: "backend2", sessionID: "session-2", capabilities: resources},
+			},
+		}
+		reqID, _ := jsonrpc.MakeID("id")
+		err := proxy.handleResourcesTemplatesListRequest(t.Context(), sess, httptest.NewRecorder(),
+			&jsonrpc.Request{ID: reqID, Method: "resources/templates/list"}, &mcp.ListResourceTemplatesParams{}, nil)
+		require.NoError(t, err)
+		_, ok := called.Load("backend1")
+		require.True(t, ok)
+		_, ok = called.Load("backend2")
+		require.False(t, ok)
+	})
+}
+
+func cloneResources(resources []*mcp.Resource) []*mcp.Resource {
+	cloned := make([]*mcp.Resource, len(resources))
+	for i, r := range resources {
+		c := *r
+		cloned[i] = &c
+	}
+	return cloned
+}
+
+func clonePrompts(prompts []*mcp.Prompt) []*mcp.Prompt {
+	cloned := make([]*mcp.Prompt, len(prompts))
+	for i, p := range prompts {
+		c := *p
+		cloned[i] = &c
+	}
+	return cloned
+}
+
 func TestServePOST_ToolsCallRequest(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1694,6 +2008,116 @@ func TestMCPPRoxy_handleSetLoggingLevel(t *testing.T) {
 	})
 }
 
+func TestMCPProxy_handlePromptListRequest_Pagination(t *testing.T) {
+	var calls sync.Map // backend name -> cursor received.
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		msg, err := jsonrpc.DecodeMessage(body)
+		require.NoError(t, err)
+		var p mcp.ListPromptsParams
+		require.NoError(t, json.Unmarshal(msg.(*jsonrpc.Request).Params, &p))
+		backend := r.Header.Get(internalapi.MCPBackendHeader)
+		calls.Store(backend, p.Cursor)
+
+		var res mcp.ListPromptsResult
+		switch {
+		case backend == "backend1" && p.Cursor == "":
+			res = mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "p1"}}, NextCursor: "backend1-page2"}
+		case backend == "backend1" && p.Cursor == "backend1-page2":
+			res = mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "p2"}}}
+		case backend == "backend2" && p.Cursor == "":
+			res = mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "p3"}}}
+		default:
+			t.Errorf("unexpected request to %s with cursor %q", backend, p.Cursor)
+		}
+		encoded, _ := json.Marshal(res)
+		resp, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: msg.(*jsonrpc.Request).ID, Result: encoded})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(testServer.Close)
+
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = testServer.URL
+	promptsCapability := &mcp.ServerCapabilities{Prompts: &mcp.PromptCapabilities{}}
+	s := &session{
+		reqCtx: proxy,
+		route:  "test-route",
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{
+			"backend1": {backendName: "backend1", sessionID: "session-1", capabilities: promptsCapability},
+			"backend2": {backendName: "backend2", sessionID: "session-2", capabilities: promptsCapability},
+		},
+	}
+	reqID, _ := jsonrpc.MakeID("id")
+
+	listPrompts := func(t *testing.T, cursor string) (*httptest.ResponseRecorder, error) {
+		calls.Clear()
+		rr := httptest.NewRecorder()
+		err := proxy.handlePromptListRequest(t.Context(), s, rr, &jsonrpc.Request{ID: reqID, Method: "prompts/list"},
+			&mcp.ListPromptsParams{Cursor: cursor}, nil)
+		return rr, err
+	}
+	decodeResult := func(t *testing.T, rr *httptest.ResponseRecorder) mcp.ListPromptsResult {
+		body := rr.Body.String()
+		idx := strings.LastIndex(body, "data: ")
+		require.GreaterOrEqual(t, idx, 0, body)
+		msg, err := jsonrpc.DecodeMessage([]byte(strings.TrimSpace(body[idx+len("data: "):])))
+		require.NoError(t, err)
+		var res mcp.ListPromptsResult
+		require.NoError(t, json.Unmarshal(msg.(*jsonrpc.Response).Result, &res))
+		return res
+	}
+	promptNames := func(res mcp.ListPromptsResult) []string {
+		var names []string
+		for _, p := range res.Prompts {
+			names = append(names, p.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	// The first page is requested from all the backends.
+	rr, err := listPrompts(t, "")
+	require.NoError(t, err)
+	firstPage := decodeResult(t, rr)
+	require.Equal(t, []string{"backend1__p1", "backend2__p3"}, promptNames(firstPage))
+	require.NotEmpty(t, firstPage.NextCursor)
+	backendCursors, err := decodeListCursor(proxy.sessionCrypto, "prompts/list", firstPage.NextCursor)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"backend1": "backend1-page2"}, backendCursors)
+
+	// The next page is only requested from the backend that still has pages, with its own cursor.
+	rr, err = listPrompts(t, firstPage.NextCursor)
+	require.NoError(t, err)
+	secondPage := decodeResult(t, rr)
+	require.Equal(t, []string{"backend1__p2"}, promptNames(secondPage))
+	require.Empty(t, secondPage.NextCursor)
+	cursor, ok := calls.Load("backend1")
+	require.True(t, ok)
+	require.Equal(t, "backend1-page2", cursor)
+	_, ok = calls.Load("backend2")
+	require.False(t, ok, "backend without more pages should not be called")
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		rr, err := listPrompts(t, "not-a-cursor")
+		require.Equal(t, metrics.MCPErrorInvalidParam, errorType(err))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.JSONEq(t, `{"jsonrpc":"2.0","id":"id","error":{"code":-32602,"message":"invalid cursor"}}`, rr.Body.String())
+		calls.Range(func(key, _ any) bool {
+			t.Errorf("backend %v should not be called", key)
+			return true
+		})
+	})
+
+	t.Run("cursor of another list", func(t *testing.T) {
+		toolsCursor, err := encodeListCursor(proxy.sessionCrypto, "tools/list", map[string]string{"backend1": "c"})
+		require.NoError(t, err)
+		_, err = listPrompts(t, toolsCursor)
+		require.ErrorContains(t, err, "invalid cursor")
+	})
+}
+
 func TestMCPPRoxy_handleResourceReadRequest(t *testing.T) {
 	t.Run("invalid resource name", func(t *testing.T) {
 		proxy := newTestMCPProxy()
@@ -2136,7 +2560,7 @@ func Test_sendToAllBackendsAndAggregateResponsesImpl(t *testing.T) {
 	var testParams *mcp.ListToolsParams
 	err = sendToAllBackendsAndAggregateResponsesImpl(t.Context(), events, proxy, rr, s, &jsonrpc.Request{ID: reqID, Method: "test"},
 		testParams,
-		func(_ *session, res []broadCastResponse[testData]) testData {
+		func(_ context.Context, _ *session, res []broadCastResponse[testData]) testData {
 			var combined testData
 			for _, r := range res {
 				combined.Value += r.res.Value
@@ -2239,6 +2663,11 @@ func Test_errorType(t *testing.T) {
 			err:      errInvalidToolName,
 			expected: metrics.MCPErrorInvalidParam,
 		},
+		{
+			name:     "tool definition drift error",
+			err:      errToolDefinitionDrift,
+			expected: metrics.MCPErrorInvalidParam,
+		},
 		{
 			name:     "wrapped backend not found error",
 			err:      fmt.Errorf("failed to call backend: %w", errBackendNotFound),
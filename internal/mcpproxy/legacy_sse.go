@@ -0,0 +1,235 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+
+	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+)
+
+// legacySSESessionIDQueryParam is the query parameter of the message endpoint identifying the SSE stream of the
+// client, as in the reference implementations of the legacy HTTP+SSE transport.
+const legacySSESessionIDQueryParam = "sessionId"
+
+// legacySSEStream is the SSE stream of a client speaking the legacy HTTP+SSE transport of the 2024-11-05 MCP
+// specification.
+//
+// With this transport, the client opens a long-lived SSE stream, and posts its messages to the endpoint advertised on
+// the stream. The responses are sent on the stream. The messages are served like the Streamable HTTP requests with
+// the MCP session tied to the stream, and the responses and notifications are written to the stream.
+//
+// The streams live in the memory of the MCP proxy, so the messages must reach the replica serving the stream.
+type legacySSEStream struct {
+	id string
+	// ctx is the context of the stream, canceled when the client disconnects.
+	ctx context.Context
+	// events are the complete SSE events to write to the stream.
+	events chan []byte
+
+	mu sync.Mutex
+	// sessionID is the ID of the MCP session, set once the client is initialized.
+	sessionID secureClientToGatewaySessionID
+}
+
+// isLegacySSERequest returns true if the request opens the SSE stream of the legacy HTTP+SSE transport.
+func isLegacySSERequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.Header.Get(sessionIDHeader) == "" &&
+		strings.HasSuffix(r.URL.Path, internalapi.MCPLegacySSEPathSuffix)
+}
+
+// isLegacyMessageRequest returns true if the request posts a message of the legacy HTTP+SSE transport.
+func isLegacyMessageRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && r.URL.Query().Has(legacySSESessionIDQueryParam) &&
+		strings.HasSuffix(r.URL.Path, internalapi.MCPLegacyMessagesPathSuffix)
+}
+
+// serveLegacySSE serves the SSE stream of a client speaking the legacy HTTP+SSE transport.
+func (m *mcpRequestContext) serveLegacySSE(w http.ResponseWriter, r *http.Request) {
+	stream := &legacySSEStream{id: uuid.NewString(), ctx: r.Context(), events: make(chan []byte, 16)}
+	m.legacySSEStreams.Store(stream.id, stream)
+	defer func() {
+		m.legacySSEStreams.Delete(stream.id)
+		// The MCP session ends with the stream.
+		if sessionID := stream.session(); sessionID != "" {
+			if s, err := m.sessionFromID(sessionID, ""); err == nil {
+				_ = s.Close() // Errors per backend are logged in Close().
+				m.toolSchemas.delete(sessionID)
+				m.toolFingerprints.delete(sessionID)
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	endpoint := strings.TrimSuffix(r.URL.Path, internalapi.MCPLegacySSEPathSuffix) + internalapi.MCPLegacyMessagesPathSuffix +
+		"?" + legacySSESessionIDQueryParam + "=" + stream.id
+	_, _ = fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", endpoint)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	for {
+		select {
+		case event := <-stream.events:
+			_, _ = w.Write(event)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		case <-stream.ctx.Done():
+			return
+		}
+	}
+}
+
+// serveLegacyMessage serves a message posted by a client speaking the legacy HTTP+SSE transport. The message is
+// served like a Streamable HTTP request, and its response is sent on the SSE stream of the client.
+func (m *mcpRequestContext) serveLegacyMessage(w http.ResponseWriter, r *http.Request) {
+	v, ok := m.legacySSEStreams.Load(r.URL.Query().Get(legacySSESessionIDQueryParam))
+	if !ok {
+		onErrorResponse(w, http.StatusNotFound, "unknown SSE session")
+		return
+	}
+	stream := v.(*legacySSEStream)
+
+	limit := m.maxRequestBodySize
+	if limit <= 0 {
+		limit = defaultMaxRequestBodySize
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			onErrorResponse(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		onErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	msg, err := jsonrpc.DecodeMessage(body)
+	if err != nil {
+		onErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON-RPC message: %v", err))
+		return
+	}
+
+	// The message is served with the context of the stream, as its response is sent on the stream.
+	req, err := http.NewRequestWithContext(stream.ctx, http.MethodPost, r.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	req.Header = r.Header.Clone()
+	req.Host = r.Host
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID := stream.session(); sessionID != "" {
+		req.Header.Set(sessionIDHeader, string(sessionID))
+	}
+	reqCtx := *m
+	reqCtx.requestHeaders = req.Header
+
+	jsonrpcReq, _ := msg.(*jsonrpc.Request)
+	serve := func() {
+		sw := newSSEResponseWriter(&legacySSEEventWriter{stream: stream}, jsonrpcReq)
+		reqCtx.servePOST(sw, req)
+		sw.finish()
+		if sessionID := sw.Header().Get(sessionIDHeader); sessionID != "" && stream.setSession(secureClientToGatewaySessionID(sessionID)) {
+			m.streamLegacyNotifications(stream, &reqCtx, req)
+		}
+	}
+	if jsonrpcReq != nil && jsonrpcReq.Method == "initialize" {
+		// The initialize request is served before accepting the message so that the session is known by the
+		// following messages.
+		serve()
+	} else {
+		go serve()
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// streamLegacyNotifications streams the notifications of the MCP session of the stream to it, like the
+// notification stream of a Streamable HTTP client.
+func (m *mcpRequestContext) streamLegacyNotifications(stream *legacySSEStream, reqCtx *mcpRequestContext, r *http.Request) {
+	req, err := http.NewRequestWithContext(stream.ctx, http.MethodGet, r.URL.String(), nil)
+	if err != nil {
+		m.l.Error("failed to create the notification request of SSE stream", slog.String("error", err.Error()))
+		return
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Set(sessionIDHeader, string(stream.session()))
+	notificationsCtx := *reqCtx
+	notificationsCtx.requestHeaders = req.Header
+	go notificationsCtx.serveGET(&legacySSEEventWriter{stream: stream}, req)
+}
+
+// session returns the ID of the MCP session of the stream, if initialized.
+func (s *legacySSEStream) session() secureClientToGatewaySessionID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessionID
+}
+
+// setSession sets the ID of the MCP session of the stream, and returns true if it was not initialized yet.
+func (s *legacySSEStream) setSession(sessionID secureClientToGatewaySessionID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	initialized := s.sessionID != ""
+	s.sessionID = sessionID
+	return !initialized
+}
+
+// legacySSEEventWriter is an [http.ResponseWriter] sending the SSE events written to it to a legacy SSE stream.
+// The events are sent once complete, so that the events of the concurrent writers are not interleaved.
+type legacySSEEventWriter struct {
+	stream *legacySSEStream
+	header http.Header
+	buf    []byte
+}
+
+// Header implements [http.ResponseWriter.Header]. The headers are discarded.
+func (l *legacySSEEventWriter) Header() http.Header {
+	if l.header == nil {
+		l.header = http.Header{}
+	}
+	return l.header
+}
+
+// WriteHeader implements [http.ResponseWriter.WriteHeader]. The status is discarded, as the errors are converted
+// into JSON-RPC errors by [sseResponseWriter].
+func (l *legacySSEEventWriter) WriteHeader(int) {}
+
+// Write implements [http.ResponseWriter.Write].
+func (l *legacySSEEventWriter) Write(b []byte) (int, error) {
+	l.buf = append(l.buf, b...)
+	for {
+		l.buf = bytes.TrimLeft(l.buf, "\n")
+		idx := bytes.Index(l.buf, sseLFLF)
+		if idx < 0 {
+			return len(b), nil
+		}
+		event := make([]byte, idx+len(sseLFLF))
+		copy(event, l.buf)
+		l.buf = l.buf[idx+len(sseLFLF):]
+		select {
+		case l.stream.events <- event:
+		case <-l.stream.ctx.Done():
+			return 0, l.stream.ctx.Err()
+		}
+	}
+}
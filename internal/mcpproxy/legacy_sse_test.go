@@ -0,0 +1,219 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+)
+
+func TestIsLegacySSERequest(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		method    string
+		path      string
+		sessionID string
+		exp       bool
+	}{
+		{name: "sse stream", method: http.MethodGet, path: "/mcp/sse", exp: true},
+		{name: "streamable notifications", method: http.MethodGet, path: "/mcp"},
+		{name: "streamable notifications on sse path", method: http.MethodGet, path: "/mcp/sse", sessionID: "session"},
+		{name: "post", method: http.MethodPost, path: "/mcp/sse"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.sessionID != "" {
+				req.Header.Set(sessionIDHeader, tc.sessionID)
+			}
+			require.Equal(t, tc.exp, isLegacySSERequest(req))
+		})
+	}
+}
+
+func TestIsLegacyMessageRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		method string
+		target string
+		exp    bool
+	}{
+		{name: "message", method: http.MethodPost, target: "/mcp/messages?sessionId=abc", exp: true},
+		{name: "no stream", method: http.MethodPost, target: "/mcp/messages"},
+		{name: "streamable", method: http.MethodPost, target: "/mcp?sessionId=abc"},
+		{name: "get", method: http.MethodGet, target: "/mcp/messages?sessionId=abc"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.exp, isLegacyMessageRequest(httptest.NewRequest(tc.method, tc.target, nil)))
+		})
+	}
+}
+
+func TestLegacySSEEventWriter(t *testing.T) {
+	stream := &legacySSEStream{ctx: t.Context(), events: make(chan []byte, 3)}
+	w := &legacySSEEventWriter{stream: stream}
+	for _, chunk := range []string{"event: message\ndata: a\n\n\nevent: mess", "age\n", "data: b\n\n\n", "event: message\n"} {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+	require.Len(t, stream.events, 2)
+	require.Equal(t, "event: message\ndata: a\n\n", string(<-stream.events))
+	require.Equal(t, "event: message\ndata: b\n\n", string(<-stream.events))
+
+	// The writes fail once the stream is closed.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	w = &legacySSEEventWriter{stream: &legacySSEStream{ctx: ctx, events: make(chan []byte)}}
+	_, err := w.Write([]byte("data: c\n\n"))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestServeLegacyMessage_Errors(t *testing.T) {
+	proxy := newTestMCPProxy()
+	proxy.legacySSEStreams.Store("stream", &legacySSEStream{ctx: t.Context(), events: make(chan []byte, 1)})
+
+	t.Run("unknown stream", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		proxy.serveLegacyMessage(rr, httptest.NewRequest(http.MethodPost, "/mcp/messages?sessionId=unknown", strings.NewReader(`{}`)))
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Equal(t, "unknown SSE session", rr.Body.String())
+	})
+	t.Run("invalid message", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		proxy.serveLegacyMessage(rr, httptest.NewRequest(http.MethodPost, "/mcp/messages?sessionId=stream", strings.NewReader(`{`)))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid JSON-RPC message")
+	})
+}
+
+func TestLegacySSE_MixedTransports(t *testing.T) {
+	// The legacy backend speaks the HTTP+SSE transport, and the other one the Streamable HTTP transport.
+	legacy := newFakeSSEBackend()
+	var streamableClosed atomic.Bool
+	backendServer := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(internalapi.MCPBackendHeader) != "streamable" {
+			legacy.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		case http.MethodDelete:
+			streamableClosed.Store(true)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		msg, _ := jsonrpc.DecodeMessage(body)
+		req := msg.(*jsonrpc.Request)
+		w.Header().Set(sessionIDHeader, "streamable-session")
+		if !req.ID.IsValid() {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		result := `{"tools":[{"name":"search","inputSchema":{"type":"object"}}]}`
+		if req.Method == "initialize" {
+			result = `{"protocolVersion":"2025-06-18","capabilities":{"tools":{}},"serverInfo":{"name":"streamable","version":"1.0.0"}}`
+		}
+		resp, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: req.ID, Result: []byte(result)})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	}))
+
+	cfg, mux, err := NewMCPProxy(slog.Default(), stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil, callcounter.NewLocal())
+	require.NoError(t, err)
+	cfg.mcpProxyConfig = &mcpProxyConfig{
+		backendListenerAddr: backendServer.URL,
+		routes: map[filterapi.MCPRouteName]*mcpProxyConfigRoute{
+			"mixed-route": {backends: map[filterapi.MCPBackendName]filterapi.MCPBackend{
+				"legacy":     {Name: "legacy", Transport: filterapi.MCPBackendTransportSSE, Path: "/sse"},
+				"streamable": {Name: "streamable"},
+			}},
+		},
+	}
+	gateway := startTestServer(t, mux)
+
+	// The client opens the SSE stream, and receives the endpoint of the messages.
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	streamReq, err := http.NewRequestWithContext(ctx, http.MethodGet, gateway.URL+"/mcp/sse", nil)
+	require.NoError(t, err)
+	streamReq.Header.Set(internalapi.MCPRouteHeader, "mixed-route")
+	streamResp, err := http.DefaultClient.Do(streamReq)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, streamResp.StatusCode)
+	require.Equal(t, "text/event-stream", streamResp.Header.Get("Content-Type"))
+	events := make(chan [2]string, 16)
+	go func() {
+		_ = readSSEBackendEvents(streamResp.Body, func(event, data string) { events <- [2]string{event, data} })
+	}()
+	endpoint := <-events
+	require.Equal(t, "endpoint", endpoint[0])
+	require.True(t, strings.HasPrefix(endpoint[1], "/mcp/messages?sessionId="), endpoint[1])
+
+	post := func(msg jsonrpc.Message) {
+		encoded, err := jsonrpc.EncodeMessage(msg)
+		require.NoError(t, err)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, gateway.URL+endpoint[1], bytes.NewReader(encoded))
+		require.NoError(t, err)
+		req.Header.Set(internalapi.MCPRouteHeader, "mixed-route")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		ensureHTTPConnectionReused(resp)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+	// response waits for the response to the given request on the stream, skipping the other messages such as pings.
+	response := func(id jsonrpc.ID) *jsonrpc.Response {
+		for {
+			select {
+			case event := <-events:
+				require.Equal(t, "message", event[0])
+				msg, err := jsonrpc.DecodeMessage([]byte(event[1]))
+				require.NoError(t, err)
+				if resp, ok := msg.(*jsonrpc.Response); ok && resp.ID == id {
+					return resp
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the response")
+			}
+		}
+	}
+
+	initID, _ := jsonrpc.MakeID(float64(1))
+	post(&jsonrpc.Request{ID: initID, Method: "initialize", Params: []byte(`{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"ide","version":"1.0.0"}}`)})
+	initResp := response(initID)
+	require.Nil(t, initResp.Error)
+	require.Contains(t, string(initResp.Result), `"tools"`)
+	post(&jsonrpc.Request{Method: "notifications/initialized"})
+
+	listID, _ := jsonrpc.MakeID(float64(2))
+	post(&jsonrpc.Request{ID: listID, Method: "tools/list", Params: emptyJSONRPCMessage})
+	listResp := response(listID)
+	require.Nil(t, listResp.Error)
+	require.Contains(t, string(listResp.Result), `"name":"legacy__echo"`)
+	require.Contains(t, string(listResp.Result), `"name":"streamable__search"`)
+
+	// The MCP session ends with the stream.
+	cancel()
+	require.Eventually(t, func() bool {
+		return legacy.closed.Load() == 1 && streamableClosed.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+}
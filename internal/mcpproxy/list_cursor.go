@@ -0,0 +1,74 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+// errInvalidCursor is returned when the cursor of a "list" request cannot be decoded.
+var errInvalidCursor = errors.New("invalid cursor")
+
+// listCursor is the composite cursor of an aggregated "list" response. It records the cursor of each backend
+// that still has pages, keyed by the backend name. Backends that returned their last page are not present.
+type listCursor struct {
+	// Method is the JSON-RPC method the cursor was issued for, so that it cannot be used for another list.
+	Method string `json:"m"`
+	// Backends maps the backend names to their own cursor.
+	Backends map[string]string `json:"b"`
+}
+
+// encodeListCursor encrypts the composite cursor of the given method. This returns an empty string when
+// no backend has more pages, which tells the client that the list is complete.
+func encodeListCursor(sc SessionCrypto, method string, backends map[string]string) (string, error) {
+	if len(backends) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(listCursor{Method: method, Backends: backends})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+	encrypted, err := sc.Encrypt(string(encoded))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt cursor: %w", err)
+	}
+	return encrypted, nil
+}
+
+// decodeListCursor decrypts the composite cursor sent by the client for the given method and returns the
+// cursor of each backend that still has pages.
+func decodeListCursor(sc SessionCrypto, method, cursor string) (map[string]string, error) {
+	decrypted, err := sc.Decrypt(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCursor, err)
+	}
+	var c listCursor
+	if err = json.Unmarshal([]byte(decrypted), &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCursor, err)
+	}
+	if c.Method != method {
+		return nil, fmt.Errorf("%w: issued for %s", errInvalidCursor, c.Method)
+	}
+	if len(c.Backends) == 0 {
+		return nil, fmt.Errorf("%w: no backend", errInvalidCursor)
+	}
+	return c.Backends, nil
+}
+
+// nextListCursor returns the composite cursor to send back to the client for the given method. Failing to
+// encode it is logged and ends the pagination rather than failing the whole list.
+func (m *mcpRequestContext) nextListCursor(method string, backends map[string]string) string {
+	cursor, err := encodeListCursor(m.sessionCrypto, method, backends)
+	if err != nil {
+		m.l.Error("failed to encode list cursor", slog.String("method", method), slog.String("error", err.Error()))
+		return ""
+	}
+	return cursor
+}
@@ -0,0 +1,72 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingSessionCrypto struct{}
+
+func (failingSessionCrypto) Encrypt(string) (string, error) { return "", errors.New("encrypt failed") }
+func (failingSessionCrypto) Decrypt(string) (string, error) { return "", errors.New("decrypt failed") }
+
+func TestListCursor_RoundTrip(t *testing.T) {
+	sc := NewPBKDF2AesGcmSessionCrypto("test", 100)
+
+	cursor, err := encodeListCursor(sc, "tools/list", map[string]string{"backend1": "page2", "backend2": "abc"})
+	require.NoError(t, err)
+	require.NotEmpty(t, cursor)
+	require.NotContains(t, cursor, "page2")
+
+	backends, err := decodeListCursor(sc, "tools/list", cursor)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"backend1": "page2", "backend2": "abc"}, backends)
+
+	t.Run("no more pages", func(t *testing.T) {
+		cursor, err := encodeListCursor(sc, "tools/list", map[string]string{})
+		require.NoError(t, err)
+		require.Empty(t, cursor)
+	})
+
+	t.Run("another method", func(t *testing.T) {
+		_, err := decodeListCursor(sc, "prompts/list", cursor)
+		require.ErrorIs(t, err, errInvalidCursor)
+		require.ErrorContains(t, err, "issued for tools/list")
+	})
+
+	t.Run("another key", func(t *testing.T) {
+		_, err := decodeListCursor(NewPBKDF2AesGcmSessionCrypto("other", 100), "tools/list", cursor)
+		require.ErrorIs(t, err, errInvalidCursor)
+	})
+}
+
+func TestDecodeListCursor_Invalid(t *testing.T) {
+	sc := NewPBKDF2AesGcmSessionCrypto("test", 100)
+	notJSON, err := sc.Encrypt("not json")
+	require.NoError(t, err)
+	noBackend, err := sc.Encrypt(`{"m":"tools/list","b":{}}`)
+	require.NoError(t, err)
+
+	for _, cursor := range []string{"garbage", notJSON, noBackend} {
+		_, err := decodeListCursor(sc, "tools/list", cursor)
+		require.ErrorIs(t, err, errInvalidCursor)
+	}
+}
+
+func TestMCPRequestContext_nextListCursor(t *testing.T) {
+	proxy := newTestMCPProxy()
+	cursor := proxy.nextListCursor("resources/list", map[string]string{"backend1": "next"})
+	backends, err := decodeListCursor(proxy.sessionCrypto, "resources/list", cursor)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"backend1": "next"}, backends)
+
+	proxy.sessionCrypto = failingSessionCrypto{}
+	require.Empty(t, proxy.nextListCursor("resources/list", map[string]string{"backend1": "next"}))
+}
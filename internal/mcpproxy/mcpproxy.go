@@ -24,6 +24,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
 	"github.com/envoyproxy/ai-gateway/internal/json"
@@ -37,6 +38,8 @@ type mcpRequestContext struct {
 	*ProxyConfig
 	metrics                   metrics.MCPMetrics
 	requestHeaders            http.Header
+	requestHost               string
+	requestPath               string
 	originalPath              string
 	perBackendMetricsRecorded bool
 }
@@ -56,7 +59,7 @@ func getMaxRequestBodySize() int64 {
 }
 
 // NewMCPProxy creates a new MCPProxy instance.
-func NewMCPProxy(l *slog.Logger, mcpMetrics metrics.MCPMetrics, tracer tracingapi.MCPTracer, sessionCrypto SessionCrypto, logRequestHeaderAttributes map[string]string) (*ProxyConfig, *http.ServeMux, error) {
+func NewMCPProxy(l *slog.Logger, mcpMetrics metrics.MCPMetrics, tracer tracingapi.MCPTracer, sessionCrypto SessionCrypto, logRequestHeaderAttributes map[string]string, rateLimiter callcounter.Limiter) (*ProxyConfig, *http.ServeMux, error) {
 	toolChangeSignaler := newMultiWatcherSignaler() // used to signal changes to all active sessions.
 	cfg := &ProxyConfig{
 		toolChangeSignaler:         toolChangeSignaler,
@@ -66,6 +69,10 @@ func NewMCPProxy(l *slog.Logger, mcpMetrics metrics.MCPMetrics, tracer tracingap
 		client:                     http.Client{}, // No timeout as it's enforced at Envoy level.
 		logRequestHeaderAttributes: maps.Clone(logRequestHeaderAttributes),
 		maxRequestBodySize:         getMaxRequestBodySize(),
+		tokenExchanger:             newTokenExchanger(),
+		toolSchemas:                newSessionToolCache[*toolSchemas](),
+		toolFingerprints:           newSessionToolCache[string](),
+		rateLimiter:                rateLimiter,
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc(
@@ -79,13 +86,23 @@ func NewMCPProxy(l *slog.Logger, mcpMetrics metrics.MCPMetrics, tracer tracingap
 				metrics:        mcpMetrics.WithRequestAttributes(r),
 				ProxyConfig:    cfg,
 				requestHeaders: r.Header,
+				requestHost:    r.Host,
+				requestPath:    r.URL.Path,
 				originalPath:   originalPathForRequest(r),
 			}
 			switch r.Method {
 			case http.MethodGet:
-				proxy.serveGET(w, r)
+				if isLegacySSERequest(r) {
+					proxy.serveLegacySSE(w, r)
+				} else {
+					proxy.serveGET(w, r)
+				}
 			case http.MethodPost:
-				proxy.servePOST(w, r)
+				if isLegacyMessageRequest(r) {
+					proxy.serveLegacyMessage(w, r)
+				} else {
+					proxy.servePOST(w, r)
+				}
 			case http.MethodDelete:
 				proxy.serverDELETE(w, r)
 			default:
@@ -229,7 +246,9 @@ func (m *mcpRequestContext) newSession(ctx context.Context, p *mcp.InitializePar
 		return nil, errors.New("failed to create MCP session to any backend")
 	}
 
-	encrypted, err := m.sessionCrypto.Encrypt(string(clientToGatewaySessionIDFromEntries(subject, finalEntries, routeName)))
+	clientCapabilities := decodeClientCapabilityFlags(encodeClientCapabilityFlags(p.Capabilities))
+	encrypted, err := m.sessionCrypto.Encrypt(string(clientToGatewaySessionIDFromEntries(subject, finalEntries, routeName).
+		withClientCapabilities(clientCapabilities)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt session ID: %w", err)
 	}
@@ -246,6 +265,7 @@ func (m *mcpRequestContext) newSession(ctx context.Context, p *mcp.InitializePar
 		id:                     secureClientToGatewaySessionID(encrypted),
 		route:                  routeName,
 		perBackendSessions:     perBackendSessions,
+		clientCapabilities:     clientCapabilities,
 		extraHeaders:           forwardHeaders,
 		perBackendExtraHeaders: perBackendHeaders,
 	}, nil
@@ -258,7 +278,8 @@ func (m *mcpRequestContext) sessionFromID(id secureClientToGatewaySessionID, las
 		return nil, fmt.Errorf("failed to decrypt session ID: %w", err)
 	}
 
-	perBackendSessionIDs, route, err := clientToGatewaySessionID(decrypted).backendSessionIDs()
+	sessionID, clientCapabilities := clientToGatewaySessionID(decrypted).clientCapabilities()
+	perBackendSessionIDs, route, err := sessionID.backendSessionIDs()
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +313,10 @@ func (m *mcpRequestContext) sessionFromID(id secureClientToGatewaySessionID, las
 		}
 	}
 
-	return &session{id: id, route: route, reqCtx: m, perBackendSessions: perBackendSessionIDs, extraHeaders: extraHeaders, perBackendExtraHeaders: perBackendHeaders}, nil
+	return &session{
+		id: id, route: route, reqCtx: m, perBackendSessions: perBackendSessionIDs, clientCapabilities: clientCapabilities,
+		extraHeaders: extraHeaders, perBackendExtraHeaders: perBackendHeaders,
+	}, nil
 }
 
 type initializeResult struct {
@@ -301,6 +325,10 @@ type initializeResult struct {
 }
 
 func (m *mcpRequestContext) initializeSession(ctx context.Context, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, p *mcp.InitializeParams, startAt time.Time) (*initializeResult, error) {
+	if backend.OpenAPI != nil {
+		// OpenAPI backends are virtual MCP servers served by the proxy itself, so there is no session to initialize.
+		return &initializeResult{result: &mcp.InitializeResult{Capabilities: openAPIBackendCapabilities}}, nil
+	}
 	// Send the initialize request to the MCP backend listener.
 	reqID := mustJSONRPCRequestID()
 	var (
@@ -424,6 +452,12 @@ func (m *mcpRequestContext) initializeSession(ctx context.Context, routeName fil
 }
 
 func (m *mcpRequestContext) invokeJSONRPCRequest(ctx context.Context, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, cse *compositeSessionEntry, msg jsonrpc.Message, params mcp.Params) (*http.Response, error) {
+	if backend.OpenAPI != nil {
+		return nil, fmt.Errorf("backend %s is an OpenAPI backend that only supports tools", backend.Name)
+	}
+	if backend.Transport == filterapi.MCPBackendTransportSSE {
+		return m.invokeSSEBackend(ctx, routeName, backend, cse, msg, params)
+	}
 	encoded, err := jsonrpc.EncodeMessage(msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode MCP message: %w", err)
@@ -446,22 +480,9 @@ func (m *mcpRequestContext) invokeJSONRPCRequest(ctx context.Context, routeName
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json, text/event-stream")
 
-	// Forward configured headers to backend.
-	if routeConfig := m.routes[routeName]; routeConfig != nil {
-		// Route-level headers (e.g., OAuth claimToHeaders).
-		for _, header := range routeConfig.forwardHeaders {
-			if value := m.requestHeaders.Get(header); value != "" {
-				req.Header.Set(header, value)
-			}
-		}
-		// Per-backend headers (from MCPRouteBackendRef.forwardHeaders) with optional renaming.
-		if b, ok := routeConfig.backends[backend.Name]; ok {
-			for _, fh := range b.ForwardHeaders {
-				if value := m.requestHeaders.Get(fh.Name); value != "" {
-					req.Header.Set(fh.ForwardName(), value)
-				}
-			}
-		}
+	m.applyForwardHeaders(req, routeName, backend.Name)
+	if err = m.setBackendToken(ctx, req, routeName, backend); err != nil {
+		return nil, err
 	}
 
 	resp, err := m.client.Do(req)
@@ -471,6 +492,28 @@ func (m *mcpRequestContext) invokeJSONRPCRequest(ctx context.Context, routeName
 	return resp, nil
 }
 
+// applyForwardHeaders forwards the configured headers of the incoming request to the given backend.
+func (m *mcpRequestContext) applyForwardHeaders(req *http.Request, routeName filterapi.MCPRouteName, backendName filterapi.MCPBackendName) {
+	routeConfig := m.routes[routeName]
+	if routeConfig == nil {
+		return
+	}
+	// Route-level headers (e.g., OAuth claimToHeaders).
+	for _, header := range routeConfig.forwardHeaders {
+		if value := m.requestHeaders.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	// Per-backend headers (from MCPRouteBackendRef.forwardHeaders) with optional renaming.
+	if b, ok := routeConfig.backends[backendName]; ok {
+		for _, fh := range b.ForwardHeaders {
+			if value := m.requestHeaders.Get(fh.Name); value != "" {
+				req.Header.Set(fh.ForwardName(), value)
+			}
+		}
+	}
+}
+
 func (m *mcpRequestContext) getBackendForRoute(route, backend filterapi.MCPBackendName) (filterapi.MCPBackend, error) {
 	r := m.routes[route]
 	if r == nil {
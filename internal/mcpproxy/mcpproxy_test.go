@@ -20,6 +20,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/envoyproxy/ai-gateway/internal/callcounter"
 	"github.com/envoyproxy/ai-gateway/internal/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/internalapi"
 	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
@@ -81,7 +82,7 @@ func TestGetMaxRequestBodySize(t *testing.T) {
 
 func TestNewMCPProxy(t *testing.T) {
 	l := slog.Default()
-	proxy, mux, err := NewMCPProxy(l, stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil)
+	proxy, mux, err := NewMCPProxy(l, stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil, callcounter.NewLocal())
 
 	require.NoError(t, err)
 	require.NotNil(t, proxy)
@@ -90,7 +91,7 @@ func TestNewMCPProxy(t *testing.T) {
 
 func TestMCPProxy_HTTPMethods(t *testing.T) {
 	l := slog.Default()
-	_, mux, err := NewMCPProxy(l, stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil)
+	_, mux, err := NewMCPProxy(l, stubMetrics{}, noopTracer, NewPBKDF2AesGcmSessionCrypto("test", 100), nil, callcounter.NewLocal())
 	require.NoError(t, err)
 
 	// Test unsupported method.
@@ -283,11 +284,20 @@ func TestNewSession_Success(t *testing.T) {
 	proxy := newTestMCPProxy()
 	proxy.backendListenerAddr = backendServer.URL
 
-	s, err := proxy.newSession(t.Context(), &mcp.InitializeParams{}, "test-route", "", nil, time.Now())
+	s, err := proxy.newSession(t.Context(), &mcp.InitializeParams{
+		Capabilities: &mcp.ClientCapabilities{Elicitation: &mcp.ElicitationCapabilities{}},
+	}, "test-route", "", nil, time.Now())
 
 	require.NoError(t, err)
 	require.NotNil(t, s)
 	require.NotEmpty(t, s.clientGatewaySessionID())
+	require.True(t, s.supportsElicitation())
+
+	// The client capabilities are restored from the session ID.
+	restored, err := proxy.sessionFromID(s.clientGatewaySessionID(), "")
+	require.NoError(t, err)
+	require.True(t, restored.supportsElicitation())
+	require.Len(t, restored.perBackendSessions, 2)
 }
 
 func TestNewSession_NoBackend(t *testing.T) {
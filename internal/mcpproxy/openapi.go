@@ -0,0 +1,736 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// openAPIBodyProperty is the property of the input schemas of the tools that holds the request body.
+	openAPIBodyProperty = "body"
+	// openAPIFormMediaType is the media type of the form encoded request bodies.
+	openAPIFormMediaType = "application/x-www-form-urlencoded"
+	// maxOpenAPIResponseSize is the maximum size of the response bodies of the operations.
+	maxOpenAPIResponseSize = 16 * 1024 * 1024
+
+	openAPISchemaRefPrefix      = "#/components/schemas/"
+	openAPIParameterRefPrefix   = "#/components/parameters/"
+	openAPIRequestBodyRefPrefix = "#/components/requestBodies/"
+)
+
+// openAPIBackendCapabilities are the capabilities of the virtual MCP servers of the OpenAPI backends.
+var openAPIBackendCapabilities = &mcp.ServerCapabilities{Tools: &mcp.ToolCapabilities{}}
+
+// openAPIJSONPointerUnescaper decodes the escaped characters of a JSON pointer token as defined in RFC 6901.
+var openAPIJSONPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+type (
+	// openAPIBackend is a REST service described by an OpenAPI document exposed as a virtual MCP server.
+	openAPIBackend struct {
+		// operations maps the tool names to the operations.
+		operations map[string]*openAPIOperation
+		// tools are the tools synthesized from the operations, sorted by name.
+		tools []*mcp.Tool
+	}
+
+	// openAPIOperation is an operation of an OpenAPI document exposed as a tool.
+	openAPIOperation struct {
+		method string
+		// path is the path template of the operation including the base path, e.g. "/v1/pets/{petId}".
+		path       string
+		parameters []openAPIParameter
+		// bodyMediaType is the media type of the request body. Empty means the operation has no request body.
+		bodyMediaType string
+		bodyRequired  bool
+	}
+
+	// openAPIParameter is a path, query, header or cookie parameter of an operation.
+	openAPIParameter struct {
+		name     string
+		in       string
+		required bool
+	}
+)
+
+type (
+	// openAPIDocument is the subset of an OpenAPI 3 document used to synthesize the tools.
+	openAPIDocument struct {
+		OpenAPI    string                     `json:"openapi"`
+		Servers    []openAPIServer            `json:"servers,omitempty"`
+		Paths      map[string]openAPIPathItem `json:"paths,omitempty"`
+		Components openAPIComponents          `json:"components,omitempty"`
+	}
+
+	openAPIServer struct {
+		URL string `json:"url"`
+	}
+
+	openAPIComponents struct {
+		Schemas       map[string]any                       `json:"schemas,omitempty"`
+		Parameters    map[string]*openAPIParameterObject   `json:"parameters,omitempty"`
+		RequestBodies map[string]*openAPIRequestBodyObject `json:"requestBodies,omitempty"`
+	}
+
+	openAPIPathItem struct {
+		Parameters []*openAPIParameterObject `json:"parameters,omitempty"`
+		Get        *openAPIOperationObject   `json:"get,omitempty"`
+		Put        *openAPIOperationObject   `json:"put,omitempty"`
+		Post       *openAPIOperationObject   `json:"post,omitempty"`
+		Delete     *openAPIOperationObject   `json:"delete,omitempty"`
+		Options    *openAPIOperationObject   `json:"options,omitempty"`
+		Head       *openAPIOperationObject   `json:"head,omitempty"`
+		Patch      *openAPIOperationObject   `json:"patch,omitempty"`
+		Trace      *openAPIOperationObject   `json:"trace,omitempty"`
+	}
+
+	openAPIOperationObject struct {
+		OperationID string                    `json:"operationId,omitempty"`
+		Summary     string                    `json:"summary,omitempty"`
+		Description string                    `json:"description,omitempty"`
+		Parameters  []*openAPIParameterObject `json:"parameters,omitempty"`
+		RequestBody *openAPIRequestBodyObject `json:"requestBody,omitempty"`
+	}
+
+	openAPIParameterObject struct {
+		Ref         string `json:"$ref,omitempty"`
+		Name        string `json:"name,omitempty"`
+		In          string `json:"in,omitempty"`
+		Description string `json:"description,omitempty"`
+		Required    bool   `json:"required,omitempty"`
+		Schema      any    `json:"schema,omitempty"`
+	}
+
+	openAPIRequestBodyObject struct {
+		Ref         string                      `json:"$ref,omitempty"`
+		Description string                      `json:"description,omitempty"`
+		Required    bool                        `json:"required,omitempty"`
+		Content     map[string]openAPIMediaType `json:"content,omitempty"`
+	}
+
+	openAPIMediaType struct {
+		Schema any `json:"schema,omitempty"`
+	}
+)
+
+// newOpenAPIBackend parses the OpenAPI document of the given backend and synthesizes the tools of the selected
+// operations. An empty document results in a backend without tools.
+func newOpenAPIBackend(cfg *filterapi.MCPOpenAPIBackend) (*openAPIBackend, error) {
+	b := &openAPIBackend{operations: map[string]*openAPIOperation{}, tools: []*mcp.Tool{}}
+	if strings.TrimSpace(cfg.Document) == "" {
+		return b, nil
+	}
+	encoded, err := yaml.YAMLToJSON([]byte(cfg.Document))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenAPI document: %w", err)
+	}
+	var doc openAPIDocument
+	if err = json.Unmarshal(encoded, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode the OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q: only OpenAPI 3 documents are supported", doc.OpenAPI)
+	}
+	basePath := strings.TrimSuffix(cmp.Or(cfg.BasePath, doc.serverBasePath()), "/")
+
+	// selected tracks whether the selected operations have been found in the document.
+	selected := make(map[string]bool, len(cfg.Operations))
+	for _, id := range cfg.Operations {
+		selected[id] = false
+	}
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		item := doc.Paths[path]
+		for method, op := range item.operations() {
+			// Operations without an operationId cannot be named, so they are not exposed.
+			if op.OperationID == "" {
+				continue
+			}
+			if len(selected) > 0 {
+				if _, ok := selected[op.OperationID]; !ok {
+					continue
+				}
+				selected[op.OperationID] = true
+			}
+			if _, ok := b.operations[op.OperationID]; ok {
+				return nil, fmt.Errorf("duplicate operationId %q", op.OperationID)
+			}
+			operation, tool, err := doc.newOperation(method, basePath+path, item.Parameters, op)
+			if err != nil {
+				return nil, fmt.Errorf("invalid operation %q: %w", op.OperationID, err)
+			}
+			b.operations[op.OperationID] = operation
+			b.tools = append(b.tools, tool)
+		}
+	}
+	for _, id := range cfg.Operations {
+		if !selected[id] {
+			return nil, fmt.Errorf("operation %q not found in the OpenAPI document", id)
+		}
+	}
+	slices.SortFunc(b.tools, func(a, b *mcp.Tool) int { return strings.Compare(a.Name, b.Name) })
+	return b, nil
+}
+
+// sameOpenAPIBackend returns true if the given OpenAPI backends expose the same tools.
+func sameOpenAPIBackend(a, b *filterapi.MCPOpenAPIBackend) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Document == b.Document && slices.Equal(a.Operations, b.Operations)
+}
+
+// serverBasePath returns the path of the first server URL of the document, or an empty string if there is none.
+// Server URLs with variables are not supported.
+func (d *openAPIDocument) serverBasePath() string {
+	if len(d.Servers) == 0 || strings.Contains(d.Servers[0].URL, "{") {
+		return ""
+	}
+	u, err := url.Parse(d.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// operations returns the operations of the path item keyed by their HTTP method.
+func (p *openAPIPathItem) operations() map[string]*openAPIOperationObject {
+	ops := make(map[string]*openAPIOperationObject)
+	for method, op := range map[string]*openAPIOperationObject{
+		http.MethodGet:     p.Get,
+		http.MethodPut:     p.Put,
+		http.MethodPost:    p.Post,
+		http.MethodDelete:  p.Delete,
+		http.MethodOptions: p.Options,
+		http.MethodHead:    p.Head,
+		http.MethodPatch:   p.Patch,
+		http.MethodTrace:   p.Trace,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// newOperation converts the given operation into an operation to invoke and the tool exposing it.
+//
+// The path, query, header and cookie parameters become the properties of the input schema of the tool, and the
+// request body becomes the "body" property. The schemas referenced by the parameters and the request body are
+// copied into the "$defs" of the input schema.
+func (d *openAPIDocument) newOperation(method, path string, pathItemParameters []*openAPIParameterObject, op *openAPIOperationObject) (*openAPIOperation, *mcp.Tool, error) {
+	operation := &openAPIOperation{method: method, path: path}
+	refs := &openAPISchemaRefs{components: d.Components.Schemas, defs: map[string]any{}}
+	properties := map[string]any{}
+	var required []string
+
+	parameters, err := d.operationParameters(pathItemParameters, op.Parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range parameters {
+		if _, ok := properties[p.Name]; ok || p.Name == openAPIBodyProperty {
+			return nil, nil, fmt.Errorf("parameter %q conflicts with another parameter or the request body", p.Name)
+		}
+		schema, err := refs.rewrite(p.Schema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid schema of parameter %q: %w", p.Name, err)
+		}
+		properties[p.Name] = withSchemaDescription(schema, p.Description, "string")
+		// Path parameters are always required.
+		isRequired := p.Required || p.In == "path"
+		if isRequired {
+			required = append(required, p.Name)
+		}
+		operation.parameters = append(operation.parameters, openAPIParameter{name: p.Name, in: p.In, required: isRequired})
+	}
+
+	if op.RequestBody != nil {
+		body, err := d.resolveRequestBody(op.RequestBody)
+		if err != nil {
+			return nil, nil, err
+		}
+		if mediaType, content := body.mediaType(); mediaType != "" {
+			schema, err := refs.rewrite(content.Schema)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid schema of the request body: %w", err)
+			}
+			defaultType := "object"
+			if !isJSONMediaType(mediaType) && mediaType != openAPIFormMediaType {
+				defaultType = "string"
+			}
+			properties[openAPIBodyProperty] = withSchemaDescription(schema, body.Description, defaultType)
+			if body.Required {
+				required = append(required, openAPIBodyProperty)
+			}
+			operation.bodyMediaType = mediaType
+			operation.bodyRequired = body.Required
+		}
+	}
+
+	inputSchema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		inputSchema["required"] = required
+	}
+	if len(refs.defs) > 0 {
+		inputSchema["$defs"] = refs.defs
+	}
+	description := strings.TrimSpace(op.Summary + "\n\n" + op.Description)
+	if description == "" {
+		description = method + " " + path
+	}
+	return operation, &mcp.Tool{
+		Name:        op.OperationID,
+		Description: description,
+		InputSchema: inputSchema,
+		Annotations: openAPIToolAnnotations(method),
+	}, nil
+}
+
+// operationParameters returns the resolved parameters of an operation. The parameters of the operation override the
+// parameters of the path item with the same name and location. The header parameters that the OpenAPI specification
+// requires to ignore are skipped.
+func (d *openAPIDocument) operationParameters(pathItemParameters, operationParameters []*openAPIParameterObject) ([]*openAPIParameterObject, error) {
+	var parameters []*openAPIParameterObject
+	for _, p := range slices.Concat(pathItemParameters, operationParameters) {
+		resolved, err := d.resolveParameter(p)
+		if err != nil {
+			return nil, err
+		}
+		switch resolved.In {
+		case "path", "query", "cookie":
+		case "header":
+			if name := http.CanonicalHeaderKey(resolved.Name); name == "Accept" || name == "Content-Type" || name == "Authorization" {
+				continue
+			}
+		default:
+			return nil, fmt.Errorf("unsupported location %q of parameter %q", resolved.In, resolved.Name)
+		}
+		if i := slices.IndexFunc(parameters, func(existing *openAPIParameterObject) bool {
+			return existing.Name == resolved.Name && existing.In == resolved.In
+		}); i >= 0 {
+			parameters[i] = resolved
+			continue
+		}
+		parameters = append(parameters, resolved)
+	}
+	return parameters, nil
+}
+
+// resolveParameter returns the parameter referenced by the given parameter, or the parameter itself if it is not a
+// reference.
+func (d *openAPIDocument) resolveParameter(p *openAPIParameterObject) (*openAPIParameterObject, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, openAPIParameterRefPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported parameter reference %q", p.Ref)
+	}
+	resolved, ok := d.Components.Parameters[openAPIJSONPointerUnescaper.Replace(name)]
+	if !ok || resolved.Ref != "" {
+		return nil, fmt.Errorf("parameter reference %q not found", p.Ref)
+	}
+	return resolved, nil
+}
+
+// resolveRequestBody returns the request body referenced by the given request body, or the request body itself if it
+// is not a reference.
+func (d *openAPIDocument) resolveRequestBody(b *openAPIRequestBodyObject) (*openAPIRequestBodyObject, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	name, ok := strings.CutPrefix(b.Ref, openAPIRequestBodyRefPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported request body reference %q", b.Ref)
+	}
+	resolved, ok := d.Components.RequestBodies[openAPIJSONPointerUnescaper.Replace(name)]
+	if !ok || resolved.Ref != "" {
+		return nil, fmt.Errorf("request body reference %q not found", b.Ref)
+	}
+	return resolved, nil
+}
+
+// mediaType returns the media type of the request body to send, preferring JSON, then form encoded bodies.
+func (b *openAPIRequestBodyObject) mediaType() (string, openAPIMediaType) {
+	mediaTypes := slices.Sorted(maps.Keys(b.Content))
+	if i := slices.IndexFunc(mediaTypes, isJSONMediaType); i >= 0 {
+		return mediaTypes[i], b.Content[mediaTypes[i]]
+	}
+	if content, ok := b.Content[openAPIFormMediaType]; ok {
+		return openAPIFormMediaType, content
+	}
+	if len(mediaTypes) > 0 {
+		return mediaTypes[0], b.Content[mediaTypes[0]]
+	}
+	return "", openAPIMediaType{}
+}
+
+// withSchemaDescription returns the given schema with the given description if it has none. A nil schema is replaced
+// by a schema of the given type.
+func withSchemaDescription(schema any, description, defaultType string) any {
+	if schema == nil {
+		schema = map[string]any{"type": defaultType}
+	}
+	if s, ok := schema.(map[string]any); ok && description != "" {
+		if _, ok = s["description"]; !ok {
+			s["description"] = description
+		}
+	}
+	return schema
+}
+
+// openAPIToolAnnotations returns the annotations of the tools of the operations with the given HTTP method.
+func openAPIToolAnnotations(method string) *mcp.ToolAnnotations {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return &mcp.ToolAnnotations{ReadOnlyHint: true}
+	case http.MethodPut, http.MethodDelete:
+		return &mcp.ToolAnnotations{IdempotentHint: true}
+	}
+	return nil
+}
+
+// openAPISchemaRefs rewrites the references to the component schemas of an OpenAPI document into references to the
+// "$defs" of an input schema, and collects the referenced schemas.
+type openAPISchemaRefs struct {
+	components map[string]any
+	defs       map[string]any
+}
+
+// rewrite returns a copy of the given schema with the references to the component schemas rewritten.
+func (r *openAPISchemaRefs) rewrite(schema any) (any, error) {
+	switch s := schema.(type) {
+	case map[string]any:
+		rewritten := make(map[string]any, len(s))
+		for k, v := range s {
+			if ref, ok := v.(string); ok && k == "$ref" {
+				name, ok := strings.CutPrefix(ref, openAPISchemaRefPrefix)
+				if !ok {
+					return nil, fmt.Errorf("unsupported schema reference %q", ref)
+				}
+				if err := r.define(openAPIJSONPointerUnescaper.Replace(name)); err != nil {
+					return nil, err
+				}
+				rewritten[k] = "#/$defs/" + name
+				continue
+			}
+			value, err := r.rewrite(v)
+			if err != nil {
+				return nil, err
+			}
+			rewritten[k] = value
+		}
+		return rewritten, nil
+	case []any:
+		rewritten := make([]any, len(s))
+		for i, v := range s {
+			value, err := r.rewrite(v)
+			if err != nil {
+				return nil, err
+			}
+			rewritten[i] = value
+		}
+		return rewritten, nil
+	default:
+		return schema, nil
+	}
+}
+
+// define adds the component schema with the given name, and the schemas it references, to the collected schemas.
+func (r *openAPISchemaRefs) define(name string) error {
+	if _, ok := r.defs[name]; ok {
+		return nil
+	}
+	schema, ok := r.components[name]
+	if !ok {
+		return fmt.Errorf("schema %q not found", name)
+	}
+	// Mark the schema as defined before rewriting it to support the recursive schemas.
+	r.defs[name] = true
+	rewritten, err := r.rewrite(schema)
+	if err != nil {
+		return err
+	}
+	r.defs[name] = rewritten
+	return nil
+}
+
+// newRequest creates the HTTP request of the operation with the given tool call arguments.
+func (o *openAPIOperation) newRequest(ctx context.Context, baseURL string, args map[string]any) (*http.Request, error) {
+	path := o.path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+	for _, p := range o.parameters {
+		value, ok := args[p.name]
+		if !ok || value == nil {
+			if p.required {
+				return nil, fmt.Errorf("missing required parameter %q", p.name)
+			}
+			continue
+		}
+		switch p.in {
+		case "path":
+			segment := openAPIParameterValue(value)
+			// These are left unchanged by the escaping, and would make the request reach another path of the backend.
+			if segment == "" || segment == "." || segment == ".." {
+				return nil, fmt.Errorf("invalid value %q for path parameter %q", segment, p.name)
+			}
+			path = strings.ReplaceAll(path, "{"+p.name+"}", url.PathEscape(segment))
+		case "query":
+			if values, ok := value.([]any); ok {
+				for _, v := range values {
+					query.Add(p.name, openAPIParameterValue(v))
+				}
+			} else {
+				query.Set(p.name, openAPIParameterValue(value))
+			}
+		case "header":
+			header.Set(p.name, openAPIParameterValue(value))
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: p.name, Value: openAPIParameterValue(value)})
+		}
+	}
+
+	var body io.Reader
+	if value := args[openAPIBodyProperty]; value != nil && o.bodyMediaType != "" {
+		encoded, err := o.encodeBody(value)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(encoded)
+	} else if o.bodyRequired {
+		return nil, errors.New("missing required request body")
+	}
+
+	u := strings.TrimSuffix(baseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, o.method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	maps.Copy(req.Header, header)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", o.bodyMediaType)
+	}
+	req.Header.Set("Accept", "application/json, */*;q=0.8")
+	return req, nil
+}
+
+// encodeBody encodes the given request body according to the media type of the operation.
+func (o *openAPIOperation) encodeBody(value any) ([]byte, error) {
+	switch {
+	case isJSONMediaType(o.bodyMediaType):
+		return json.Marshal(value)
+	case o.bodyMediaType == openAPIFormMediaType:
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, errors.New("the request body must be an object")
+		}
+		form := url.Values{}
+		for name, v := range fields {
+			if values, ok := v.([]any); ok {
+				for _, item := range values {
+					form.Add(name, openAPIParameterValue(item))
+				}
+				continue
+			}
+			form.Set(name, openAPIParameterValue(v))
+		}
+		return []byte(form.Encode()), nil
+	default:
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+		return json.Marshal(value)
+	}
+}
+
+// openAPIParameterValue formats the given argument as a parameter value. Objects and arrays are JSON encoded.
+func openAPIParameterValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// isJSONMediaType returns true if the given media type is JSON, e.g. "application/json" or "application/problem+json".
+func isJSONMediaType(mediaType string) bool {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return parsed == "application/json" || strings.HasSuffix(parsed, "+json")
+}
+
+// openAPIToolResult converts the HTTP response of an operation into a tool call result. The body is returned as text
+// content, along with the structured content when it is a JSON object. Error responses are returned as tool errors.
+func openAPIToolResult(resp *http.Response) (*mcp.CallToolResult, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOpenAPIResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body: %w", err)
+	}
+	if len(body) > maxOpenAPIResponseSize {
+		return nil, fmt.Errorf("the response body exceeds %d bytes", maxOpenAPIResponseSize)
+	}
+	result := &mcp.CallToolResult{IsError: resp.StatusCode >= http.StatusBadRequest}
+	text := string(body)
+	switch {
+	case result.IsError:
+		text = strings.TrimSpace("HTTP " + resp.Status + "\n" + text)
+	case len(body) == 0:
+		text = "HTTP " + resp.Status
+	case isJSONMediaType(resp.Header.Get("Content-Type")):
+		var structured map[string]any
+		if json.Unmarshal(body, &structured) == nil && structured != nil {
+			result.StructuredContent = structured
+		}
+	}
+	result.Content = []mcp.Content{&mcp.TextContent{Text: text}}
+	return result, nil
+}
+
+// openAPIBackend returns the OpenAPI backend with the given name in the given route, or nil if there is none.
+func (m *mcpRequestContext) openAPIBackend(route filterapi.MCPRouteName, backend filterapi.MCPBackendName) *openAPIBackend {
+	if r := m.routes[route]; r != nil {
+		return r.openAPIBackends[backend]
+	}
+	return nil
+}
+
+// sendOpenAPIBackendRequest answers the given request on behalf of the virtual MCP server of the given OpenAPI
+// backend. Only the tools/list requests are answered, as the OpenAPI backends only have tools.
+func (s *session) sendOpenAPIBackendRequest(eventChan chan<- *backendEvent, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, request *jsonrpc.Request) error {
+	if request == nil || request.Method != "tools/list" {
+		return nil
+	}
+	tools := []*mcp.Tool{}
+	if b := s.reqCtx.openAPIBackend(routeName, backend.Name); b != nil {
+		tools = b.tools
+	}
+	// The tools are encoded so that the shared tools are not modified when merging the lists.
+	result, err := json.Marshal(&mcp.ListToolsResult{Tools: tools})
+	if err != nil {
+		return fmt.Errorf("failed to encode the tools of OpenAPI backend %s: %w", backend.Name, err)
+	}
+	eventChan <- &backendEvent{
+		sseEvent: &sseEvent{
+			backend:  backend.Name,
+			event:    "message",
+			messages: []jsonrpc.Message{&jsonrpc.Response{ID: request.ID, Result: result}},
+		},
+		startAt: time.Now(),
+	}
+	return nil
+}
+
+// invokeOpenAPIOperation translates the given tool call into an HTTP request to the given OpenAPI backend, and
+// responds to the client with the HTTP response converted into the tool call result.
+//
+// The request is sent to the backend listener like the MCP requests, so that the security policy of the backend
+// applies to it.
+func (m *mcpRequestContext) invokeOpenAPIOperation(ctx context.Context, s *session, w http.ResponseWriter, backend filterapi.MCPBackend, req *jsonrpc.Request, p *mcp.CallToolParams) error {
+	var operation *openAPIOperation
+	if b := m.openAPIBackend(s.route, backend.Name); b != nil {
+		operation = b.operations[p.Name]
+	}
+	if operation == nil {
+		return onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams, fmt.Sprintf("unknown tool %s", p.Name))
+	}
+	args, err := toArgumentsMap(p.Arguments)
+	if err != nil {
+		return onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams, fmt.Sprintf("invalid arguments for tool %s: %v", p.Name, err))
+	}
+	httpReq, err := operation.newRequest(ctx, m.backendListenerAddr, args)
+	if err != nil {
+		return onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams, fmt.Sprintf("invalid arguments for tool %s: %v", p.Name, err))
+	}
+	addMCPHeaders(httpReq, req, p, s.route, backend.Name)
+	m.applyLogHeaderMappings(httpReq, req)
+	m.applyOriginalPathHeaders(httpReq)
+	m.applyForwardHeaders(httpReq, s.route, backend.Name)
+	if err = m.setBackendToken(ctx, httpReq, s.route, backend); err != nil {
+		var exchangeErr *tokenExchangeError
+		if errors.As(err, &exchangeErr) {
+			onErrorResponse(w, exchangeErr.statusCode, fmt.Sprintf("call to %s failed: %v", backend.Name, err))
+			return err
+		}
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("call to %s failed: %v", backend.Name, err))
+		return err
+	}
+	if m.l.Enabled(ctx, slog.LevelDebug) {
+		m.l.Debug("invoking OpenAPI operation", slog.String("backend", backend.Name), slog.String("tool", p.Name),
+			slog.String("http_method", httpReq.Method), slog.String("url", httpReq.URL.String()))
+	}
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("call to %s failed: %v", backend.Name, err))
+		return err
+	}
+	defer func() {
+		ensureHTTPConnectionReused(resp)
+	}()
+	result, err := openAPIToolResult(resp)
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("call to %s failed: %v", backend.Name, err))
+		return err
+	}
+	encoded, _ := json.Marshal(result)
+	msg := &jsonrpc.Response{ID: req.ID, Result: encoded}
+	if err = m.maybeResponseModify(ctx, s, req, msg, backend); err != nil {
+		m.l.Error("failed to modify response", slog.String("error", err.Error()))
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("failed to modify response: %v", err))
+		return err
+	}
+	m.recordResponse(ctx, msg)
+	body, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(sessionIDHeader, string(s.clientGatewaySessionID()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+	if toolErr := checkToolCallError(req, msg, backend.Name); toolErr != nil {
+		return toolErr
+	}
+	return nil
+}
@@ -0,0 +1,661 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const testPetstoreOpenAPI = `
+openapi: 3.0.3
+servers:
+  - url: https://petstore.example.com/v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List all pets
+      parameters:
+        - name: limit
+          in: query
+          description: How many items to return
+          schema:
+            type: integer
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: Accept
+          in: header
+          schema:
+            type: string
+    post:
+      operationId: createPet
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/petId'
+    get:
+      operationId: showPetById
+      summary: Info for a specific pet
+      description: Returns the pet with the given ID.
+      parameters:
+        - name: X-Request-ID
+          in: header
+          required: true
+          schema:
+            type: string
+    delete:
+      operationId: deletePet
+    patch:
+      summary: Operations without an operationId are not exposed
+components:
+  parameters:
+    petId:
+      name: petId
+      in: path
+      description: The id of the pet
+      schema:
+        type: string
+  requestBodies:
+    Pet:
+      required: true
+      content:
+        application/xml:
+          schema:
+            type: string
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        category:
+          $ref: '#/components/schemas/Category'
+    Category:
+      type: object
+      properties:
+        name:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Category'
+    Unused:
+      type: string
+`
+
+func requireNewTestPetstoreBackend(t *testing.T) *openAPIBackend {
+	b, err := newOpenAPIBackend(&filterapi.MCPOpenAPIBackend{Document: testPetstoreOpenAPI})
+	require.NoError(t, err)
+	return b
+}
+
+func TestNewOpenAPIBackend(t *testing.T) {
+	b := requireNewTestPetstoreBackend(t)
+
+	names := make([]string, 0, len(b.tools))
+	for _, tool := range b.tools {
+		names = append(names, tool.Name)
+	}
+	require.Equal(t, []string{"createPet", "deletePet", "listPets", "showPetById"}, names)
+
+	require.Equal(t, &openAPIOperation{
+		method: http.MethodGet,
+		path:   "/v1/pets",
+		parameters: []openAPIParameter{
+			{name: "limit", in: "query"},
+			{name: "tags", in: "query"},
+		},
+	}, b.operations["listPets"])
+	require.Equal(t, &openAPIOperation{
+		method: http.MethodGet,
+		path:   "/v1/pets/{petId}",
+		parameters: []openAPIParameter{
+			{name: "petId", in: "path", required: true},
+			{name: "X-Request-ID", in: "header", required: true},
+		},
+	}, b.operations["showPetById"])
+	require.Equal(t, &openAPIOperation{
+		method:        http.MethodPost,
+		path:          "/v1/pets",
+		bodyMediaType: "application/json",
+		bodyRequired:  true,
+	}, b.operations["createPet"])
+
+	tools := make(map[string]*mcp.Tool, len(b.tools))
+	for _, tool := range b.tools {
+		tools[tool.Name] = tool
+	}
+	require.Equal(t, "List all pets", tools["listPets"].Description)
+	require.Equal(t, &mcp.ToolAnnotations{ReadOnlyHint: true}, tools["listPets"].Annotations)
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"limit": map[string]any{"type": "integer", "description": "How many items to return"},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}, tools["listPets"].InputSchema)
+
+	require.Equal(t, "Info for a specific pet\n\nReturns the pet with the given ID.", tools["showPetById"].Description)
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"petId":        map[string]any{"type": "string", "description": "The id of the pet"},
+			"X-Request-ID": map[string]any{"type": "string"},
+		},
+		"required": []string{"petId", "X-Request-ID"},
+	}, tools["showPetById"].InputSchema)
+
+	require.Equal(t, "DELETE /v1/pets/{petId}", tools["deletePet"].Description)
+	require.Equal(t, &mcp.ToolAnnotations{IdempotentHint: true}, tools["deletePet"].Annotations)
+
+	// The referenced schemas, and only those, are copied into the input schema.
+	require.Nil(t, tools["createPet"].Annotations)
+	require.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"body": map[string]any{"$ref": "#/$defs/Pet"}},
+		"required":   []string{"body"},
+		"$defs": map[string]any{
+			"Pet": map[string]any{
+				"type":     "object",
+				"required": []any{"name"},
+				"properties": map[string]any{
+					"name":     map[string]any{"type": "string"},
+					"category": map[string]any{"$ref": "#/$defs/Category"},
+				},
+			},
+			"Category": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":   map[string]any{"type": "string"},
+					"parent": map[string]any{"$ref": "#/$defs/Category"},
+				},
+			},
+		},
+	}, tools["createPet"].InputSchema)
+	// The input schemas can be used for the schema validation.
+	_, err := resolveToolSchema(tools["createPet"].InputSchema)
+	require.NoError(t, err)
+}
+
+func TestNewOpenAPIBackend_SelectedOperations(t *testing.T) {
+	b, err := newOpenAPIBackend(&filterapi.MCPOpenAPIBackend{
+		Document:   testPetstoreOpenAPI,
+		BasePath:   "/api/",
+		Operations: []string{"showPetById", "listPets"},
+	})
+	require.NoError(t, err)
+	require.Len(t, b.tools, 2)
+	require.Equal(t, "listPets", b.tools[0].Name)
+	require.Equal(t, "showPetById", b.tools[1].Name)
+	require.Equal(t, "/api/pets/{petId}", b.operations["showPetById"].path)
+
+	// Empty documents have no tools.
+	b, err = newOpenAPIBackend(&filterapi.MCPOpenAPIBackend{})
+	require.NoError(t, err)
+	require.Empty(t, b.tools)
+	require.NotNil(t, b.tools)
+}
+
+func TestNewOpenAPIBackend_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		document   string
+		operations []string
+		expErr     string
+	}{
+		{
+			name:     "invalid document",
+			document: "openapi: [",
+			expErr:   "failed to parse the OpenAPI document",
+		},
+		{
+			name:     "swagger",
+			document: `{"swagger": "2.0"}`,
+			expErr:   `unsupported OpenAPI version "": only OpenAPI 3 documents are supported`,
+		},
+		{
+			name:       "unknown operation",
+			document:   testPetstoreOpenAPI,
+			operations: []string{"listPets", "adoptPet"},
+			expErr:     `operation "adoptPet" not found in the OpenAPI document`,
+		},
+		{
+			name: "duplicate operationId",
+			document: `
+openapi: 3.1.0
+paths:
+  /a:
+    get:
+      operationId: op
+  /b:
+    get:
+      operationId: op`,
+			expErr: `duplicate operationId "op"`,
+		},
+		{
+			name: "missing schema",
+			document: `
+openapi: 3.1.0
+paths:
+  /a:
+    get:
+      operationId: op
+      parameters:
+        - name: a
+          in: query
+          schema:
+            $ref: '#/components/schemas/Missing'`,
+			expErr: `invalid operation "op": invalid schema of parameter "a": schema "Missing" not found`,
+		},
+		{
+			name: "external schema",
+			document: `
+openapi: 3.1.0
+paths:
+  /a:
+    post:
+      operationId: op
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: 'https://example.com/schema.json'`,
+			expErr: `invalid operation "op": invalid schema of the request body: unsupported schema reference "https://example.com/schema.json"`,
+		},
+		{
+			name: "missing parameter",
+			document: `
+openapi: 3.1.0
+paths:
+  /a:
+    get:
+      operationId: op
+      parameters:
+        - $ref: '#/components/parameters/missing'`,
+			expErr: `invalid operation "op": parameter reference "#/components/parameters/missing" not found`,
+		},
+		{
+			name: "conflicting parameters",
+			document: `
+openapi: 3.1.0
+paths:
+  /a/{id}:
+    get:
+      operationId: op
+      parameters:
+        - name: id
+          in: path
+        - name: id
+          in: query`,
+			expErr: `invalid operation "op": parameter "id" conflicts with another parameter or the request body`,
+		},
+		{
+			name: "unsupported location",
+			document: `
+openapi: 3.1.0
+paths:
+  /a:
+    get:
+      operationId: op
+      parameters:
+        - name: id
+          in: body`,
+			expErr: `invalid operation "op": unsupported location "body" of parameter "id"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newOpenAPIBackend(&filterapi.MCPOpenAPIBackend{Document: tc.document, Operations: tc.operations})
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func Test_sameOpenAPIBackend(t *testing.T) {
+	a := &filterapi.MCPOpenAPIBackend{Document: "doc", BasePath: "/v1", Operations: []string{"a"}}
+	require.True(t, sameOpenAPIBackend(nil, nil))
+	require.False(t, sameOpenAPIBackend(a, nil))
+	require.True(t, sameOpenAPIBackend(a, &filterapi.MCPOpenAPIBackend{Document: "doc", BasePath: "/v2", Operations: []string{"a"}}))
+	require.False(t, sameOpenAPIBackend(a, &filterapi.MCPOpenAPIBackend{Document: "other", Operations: []string{"a"}}))
+	require.False(t, sameOpenAPIBackend(a, &filterapi.MCPOpenAPIBackend{Document: "doc"}))
+}
+
+func TestOpenAPIOperation_newRequest(t *testing.T) {
+	b := requireNewTestPetstoreBackend(t)
+
+	t.Run("path and header parameters", func(t *testing.T) {
+		req, err := b.operations["showPetById"].newRequest(t.Context(), "http://127.0.0.1:10088/",
+			map[string]any{"petId": "a b/c", "X-Request-ID": 42.0, "unknown": "ignored"})
+		require.NoError(t, err)
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "http://127.0.0.1:10088/v1/pets/a%20b%2Fc", req.URL.String())
+		require.Equal(t, "42", req.Header.Get("X-Request-ID"))
+		require.Nil(t, req.Body)
+	})
+
+	t.Run("query parameters", func(t *testing.T) {
+		req, err := b.operations["listPets"].newRequest(t.Context(), "http://127.0.0.1:10088",
+			map[string]any{"limit": 10.0, "tags": []any{"cat", "dog"}})
+		require.NoError(t, err)
+		require.Equal(t, "/v1/pets", req.URL.Path)
+		require.Equal(t, "limit=10&tags=cat&tags=dog", req.URL.RawQuery)
+
+		req, err = b.operations["listPets"].newRequest(t.Context(), "http://127.0.0.1:10088", map[string]any{})
+		require.NoError(t, err)
+		require.Empty(t, req.URL.RawQuery)
+	})
+
+	t.Run("json body", func(t *testing.T) {
+		req, err := b.operations["createPet"].newRequest(t.Context(), "http://127.0.0.1:10088",
+			map[string]any{"body": map[string]any{"name": "rex"}})
+		require.NoError(t, err)
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"rex"}`, string(body))
+	})
+
+	t.Run("form body and cookie", func(t *testing.T) {
+		op := &openAPIOperation{
+			method:        http.MethodPost,
+			path:          "/login",
+			parameters:    []openAPIParameter{{name: "session", in: "cookie"}},
+			bodyMediaType: openAPIFormMediaType,
+		}
+		req, err := op.newRequest(t.Context(), "http://127.0.0.1:10088",
+			map[string]any{"session": "abc", "body": map[string]any{"user": "me", "roles": []any{"a", "b"}, "admin": true}})
+		require.NoError(t, err)
+		require.Equal(t, openAPIFormMediaType, req.Header.Get("Content-Type"))
+		require.Equal(t, "session=abc", req.Header.Get("Cookie"))
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, "admin=true&roles=a&roles=b&user=me", string(body))
+
+		_, err = op.newRequest(t.Context(), "http://127.0.0.1:10088", map[string]any{"body": "user=me"})
+		require.EqualError(t, err, "the request body must be an object")
+	})
+
+	t.Run("raw body", func(t *testing.T) {
+		op := &openAPIOperation{method: http.MethodPut, path: "/notes", bodyMediaType: "text/plain"}
+		req, err := op.newRequest(t.Context(), "http://127.0.0.1:10088", map[string]any{"body": "hello"})
+		require.NoError(t, err)
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(body))
+	})
+
+	t.Run("missing required", func(t *testing.T) {
+		_, err := b.operations["showPetById"].newRequest(t.Context(), "http://127.0.0.1:10088", map[string]any{"petId": "1"})
+		require.EqualError(t, err, `missing required parameter "X-Request-ID"`)
+		_, err = b.operations["createPet"].newRequest(t.Context(), "http://127.0.0.1:10088", map[string]any{})
+		require.EqualError(t, err, "missing required request body")
+	})
+
+	t.Run("invalid path parameter", func(t *testing.T) {
+		for _, value := range []string{"", ".", ".."} {
+			_, err := b.operations["showPetById"].newRequest(t.Context(), "http://127.0.0.1:10088",
+				map[string]any{"petId": value, "X-Request-ID": "1"})
+			require.EqualError(t, err, fmt.Sprintf("invalid value %q for path parameter \"petId\"", value))
+		}
+	})
+}
+
+func Test_openAPIParameterValue(t *testing.T) {
+	require.Equal(t, "text", openAPIParameterValue("text"))
+	require.Equal(t, "true", openAPIParameterValue(true))
+	require.Equal(t, "1.5", openAPIParameterValue(1.5))
+	require.Equal(t, "100000000", openAPIParameterValue(1e8))
+	require.Equal(t, "3", openAPIParameterValue(3))
+	require.JSONEq(t, `{"a":1}`, openAPIParameterValue(map[string]any{"a": 1}))
+}
+
+func Test_isJSONMediaType(t *testing.T) {
+	require.True(t, isJSONMediaType("application/json"))
+	require.True(t, isJSONMediaType("application/json; charset=utf-8"))
+	require.True(t, isJSONMediaType("application/problem+json"))
+	require.False(t, isJSONMediaType("text/plain"))
+	require.False(t, isJSONMediaType(""))
+}
+
+func Test_openAPIToolResult(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		status        int
+		contentType   string
+		body          string
+		expIsError    bool
+		expText       string
+		expStructured any
+	}{
+		{
+			name:          "json object",
+			status:        http.StatusOK,
+			contentType:   "application/json",
+			body:          `{"name":"rex"}`,
+			expText:       `{"name":"rex"}`,
+			expStructured: map[string]any{"name": "rex"},
+		},
+		{
+			name:        "json array",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `[{"name":"rex"}]`,
+			expText:     `[{"name":"rex"}]`,
+		},
+		{
+			name:        "text",
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			body:        `{"name":"rex"}`,
+			expText:     `{"name":"rex"}`,
+		},
+		{
+			name:    "empty",
+			status:  http.StatusNoContent,
+			expText: "HTTP 204 No Content",
+		},
+		{
+			name:        "error",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"error":"not found"}`,
+			expIsError:  true,
+			expText:     "HTTP 404 Not Found\n{\"error\":\"not found\"}",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tc.status,
+				Status:     strconv.Itoa(tc.status) + " " + http.StatusText(tc.status),
+				Header:     http.Header{"Content-Type": []string{tc.contentType}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			}
+			result, err := openAPIToolResult(resp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expIsError, result.IsError)
+			require.Equal(t, []mcp.Content{&mcp.TextContent{Text: tc.expText}}, result.Content)
+			require.Equal(t, tc.expStructured, result.StructuredContent)
+		})
+	}
+
+	t.Run("too large", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(strings.Repeat("a", maxOpenAPIResponseSize+1))),
+		}
+		_, err := openAPIToolResult(resp)
+		require.EqualError(t, err, fmt.Sprintf("the response body exceeds %d bytes", maxOpenAPIResponseSize))
+	})
+}
+
+func TestSession_sendOpenAPIBackendRequest(t *testing.T) {
+	proxy := newTestMCPProxy()
+	proxy.routes["test-route"].openAPIBackends = map[filterapi.MCPBackendName]*openAPIBackend{
+		"petstore": requireNewTestPetstoreBackend(t),
+	}
+	s := &session{reqCtx: proxy, route: "test-route"}
+	backend := filterapi.MCPBackend{Name: "petstore", OpenAPI: &filterapi.MCPOpenAPIBackend{}}
+
+	events := make(chan *backendEvent, 1)
+	id := mustJSONRPCRequestID()
+	require.NoError(t, s.sendOpenAPIBackendRequest(events, "test-route", backend, &jsonrpc.Request{ID: id, Method: "tools/list"}))
+	event := <-events
+	require.Equal(t, "petstore", event.backend)
+	require.Len(t, event.messages, 1)
+	resp, ok := event.messages[0].(*jsonrpc.Response)
+	require.True(t, ok)
+	require.Equal(t, id, resp.ID)
+	var result mcp.ListToolsResult
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	require.Len(t, result.Tools, 4)
+
+	// The other requests are ignored.
+	require.NoError(t, s.sendOpenAPIBackendRequest(events, "test-route", backend, &jsonrpc.Request{ID: id, Method: "ping"}))
+	require.NoError(t, s.sendOpenAPIBackendRequest(events, "test-route", backend, nil))
+	require.Empty(t, events)
+
+	// Backends without a parsed document have no tools.
+	require.NoError(t, s.sendOpenAPIBackendRequest(events, "test-route", filterapi.MCPBackend{Name: "unknown"},
+		&jsonrpc.Request{ID: id, Method: "tools/list"}))
+	event = <-events
+	require.JSONEq(t, `{"tools":[]}`, string(event.messages[0].(*jsonrpc.Response).Result))
+}
+
+func TestInitializeSession_OpenAPIBackend(t *testing.T) {
+	proxy := newTestMCPProxy()
+	// No request is sent to the backend listener.
+	proxy.backendListenerAddr = "http://127.0.0.1:0"
+	result, err := proxy.initializeSession(t.Context(), "test-route",
+		filterapi.MCPBackend{Name: "petstore", OpenAPI: &filterapi.MCPOpenAPIBackend{}}, &mcp.InitializeParams{}, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, result.sessionID)
+	require.Equal(t, openAPIBackendCapabilities, result.result.Capabilities)
+
+	_, err = proxy.invokeJSONRPCRequest(t.Context(), "test-route",
+		filterapi.MCPBackend{Name: "petstore", OpenAPI: &filterapi.MCPOpenAPIBackend{}}, nil, &jsonrpc.Request{Method: "prompts/get"}, nil)
+	require.EqualError(t, err, "backend petstore is an OpenAPI backend that only supports tools")
+}
+
+func TestHandleToolCallRequest_OpenAPI(t *testing.T) {
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "petstore", r.Header.Get(internalapi.MCPBackendHeader))
+		require.Equal(t, "test-route", r.Header.Get(internalapi.MCPRouteHeader))
+		require.Equal(t, "tools/call", r.Header.Get(internalapi.MCPMetadataHeaderMethod))
+		require.Equal(t, "showPetById", r.Header.Get(internalapi.MCPMetadataHeaderToolName))
+		require.Equal(t, "req-1", r.Header.Get("X-Request-ID"))
+		require.Equal(t, "forwarded", r.Header.Get("X-Tenant"))
+		switch r.URL.Path {
+		case "/v1/pets/1":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","name":"rex"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(backendServer.Close)
+
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = backendServer.URL
+	proxy.requestHeaders = http.Header{"X-Tenant": []string{"forwarded"}}
+	route := proxy.routes["test-route"]
+	route.backends["petstore"] = filterapi.MCPBackend{
+		Name:           "petstore",
+		OpenAPI:        &filterapi.MCPOpenAPIBackend{Document: testPetstoreOpenAPI},
+		ForwardHeaders: []filterapi.MCPHeaderForward{{Name: "X-Tenant"}},
+	}
+	route.openAPIBackends = map[filterapi.MCPBackendName]*openAPIBackend{"petstore": requireNewTestPetstoreBackend(t)}
+	s := &session{
+		reqCtx:             proxy,
+		route:              "test-route",
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"petstore": {backendName: "petstore"}},
+	}
+
+	call := func(t *testing.T, args map[string]any) (*jsonrpc.Response, error) {
+		req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"}
+		rr := httptest.NewRecorder()
+		_, err := proxy.handleToolCallRequest(t.Context(), s, rr, req,
+			&mcp.CallToolParams{Name: "petstore__showPetById", Arguments: args}, nil, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		msg, decodeErr := jsonrpc.DecodeMessage(rr.Body.Bytes())
+		require.NoError(t, decodeErr)
+		resp := msg.(*jsonrpc.Response)
+		require.Equal(t, req.ID, resp.ID)
+		return resp, err
+	}
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := call(t, map[string]any{"petId": "1", "X-Request-ID": "req-1"})
+		require.NoError(t, err)
+		var result map[string]any
+		require.NoError(t, json.Unmarshal(resp.Result, &result))
+		require.Equal(t, map[string]any{
+			"content":           []any{map[string]any{"type": "text", "text": `{"id":"1","name":"rex"}`}},
+			"structuredContent": map[string]any{"id": "1", "name": "rex"},
+		}, result)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		resp, err := call(t, map[string]any{"petId": "2", "X-Request-ID": "req-1"})
+		var toolErr *errToolCall
+		require.ErrorAs(t, err, &toolErr)
+		var result mcp.CallToolResult
+		require.NoError(t, json.Unmarshal(resp.Result, &result))
+		require.True(t, result.IsError)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		resp, err := call(t, map[string]any{"X-Request-ID": "req-1"})
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, int64(jsonrpc.CodeInvalidParams), resp.Error.(*jsonrpc.Error).Code)
+		require.Equal(t, `invalid arguments for tool showPetById: missing required parameter "petId"`, rpcErr.Message)
+	})
+}
+
+func TestHandleToolsListRequest_OpenAPI(t *testing.T) {
+	proxy := newTestMCPProxy()
+	route := proxy.routes["test-route"]
+	route.backends["petstore"] = filterapi.MCPBackend{Name: "petstore", OpenAPI: &filterapi.MCPOpenAPIBackend{}}
+	route.openAPIBackends = map[filterapi.MCPBackendName]*openAPIBackend{"petstore": requireNewTestPetstoreBackend(t)}
+	s := &session{
+		reqCtx: proxy,
+		route:  "test-route",
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{
+			"petstore": {backendName: "petstore", capabilities: openAPIBackendCapabilities},
+		},
+	}
+
+	rr := httptest.NewRecorder()
+	req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/list"}
+	require.NoError(t, proxy.handleToolsListRequest(t.Context(), s, rr, req, &mcp.ListToolsParams{}, nil))
+	body := rr.Body.String()
+	for _, name := range []string{"petstore__createPet", "petstore__deletePet", "petstore__listPets", "petstore__showPetById"} {
+		require.Contains(t, body, `"name":"`+name+`"`)
+	}
+}
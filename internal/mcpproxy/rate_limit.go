@@ -0,0 +1,135 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+// codeToolRateLimited is the JSON-RPC error code of the tool calls rejected by a rate limit. It is in the range
+// reserved for the implementation-defined server errors.
+const codeToolRateLimited int64 = -32029
+
+// toolRateLimit is a tool rate limit of a route.
+type toolRateLimit struct {
+	filterapi.MCPToolRateLimit
+	// index is the position of the limit in the route, which tells apart the counters of the limits.
+	index int
+	// tools is the set of the limited tools, nil meaning all the tools of the backend.
+	tools map[string]struct{}
+}
+
+// newToolRateLimits compiles the tool rate limits of a route.
+func newToolRateLimits(limits []filterapi.MCPToolRateLimit) []*toolRateLimit {
+	ret := make([]*toolRateLimit, 0, len(limits))
+	for i, l := range limits {
+		rl := &toolRateLimit{MCPToolRateLimit: l, index: i}
+		if len(l.Tools) > 0 {
+			rl.tools = make(map[string]struct{}, len(l.Tools))
+			for _, tool := range l.Tools {
+				rl.tools[tool] = struct{}{}
+			}
+		}
+		ret = append(ret, rl)
+	}
+	return ret
+}
+
+// appliesTo returns true if the limit applies to the calls of the given upstream tool of the backend.
+func (l *toolRateLimit) appliesTo(backend filterapi.MCPBackendName, tool string) bool {
+	if l.Backend != backend {
+		return false
+	}
+	if l.tools == nil {
+		return true
+	}
+	_, ok := l.tools[tool]
+	return ok
+}
+
+// clientKey returns the value identifying the client of the request for the limit. The clients whose key
+// is missing share the empty key.
+func (l *toolRateLimit) clientKey(s *session, headers http.Header) string {
+	switch l.ClientKeyType {
+	case filterapi.MCPToolRateLimitClientKeyTypeHeader:
+		return headers.Get(l.ClientKeyHeader)
+	case filterapi.MCPToolRateLimitClientKeyTypeSession:
+		return string(s.clientGatewaySessionID())
+	default:
+		return jwtSubject(headers)
+	}
+}
+
+// counterKey returns the key of the counter of the client for the given tool. The values are hashed so that
+// the keys have a bounded length and don't leak the client keys, which may be credentials, to the Redis server.
+func (l *toolRateLimit) counterKey(route filterapi.MCPRouteName, tool, client string) string {
+	h := sha256.New()
+	for _, part := range []string{route, strconv.Itoa(l.index), l.Backend, tool, client} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return "mcp:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// jwtSubject returns the "sub" claim of the bearer token in the Authorization header, or an empty string if there
+// is none. The token is not verified here as it is done by Envoy before reaching the proxy.
+func jwtSubject(headers http.Header) string {
+	token, err := bearerToken(headers.Get("Authorization"))
+	if err != nil {
+		return ""
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err = jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	sub, _ := claims.GetSubject()
+	return sub
+}
+
+// checkToolRateLimits records the call to the upstream tool of the backend against the rate limits of the route
+// that apply to it. It returns the first limit that has been exceeded, if any, along with the time until it resets.
+//
+// Failures of the rate limit backend are logged and the corresponding limit is not enforced, so that an
+// unavailable Redis does not take down the tool calls.
+func (m *mcpRequestContext) checkToolRateLimits(ctx context.Context, s *session, backend filterapi.MCPBackendName, tool string, headers http.Header) (*toolRateLimit, time.Duration) {
+	route := m.routes[s.route]
+	if route == nil {
+		return nil, 0
+	}
+	for _, l := range route.rateLimits {
+		if !l.appliesTo(backend, tool) {
+			continue
+		}
+		key := l.counterKey(s.route, tool, l.clientKey(s, headers))
+		allowed, retryAfter, err := m.rateLimiter.Allow(ctx, key, l.Calls, l.Window)
+		if err != nil {
+			m.l.Error("failed to check tool rate limit, not enforcing it",
+				slog.String("backend", backend), slog.String("tool", tool), slog.String("error", err.Error()))
+			continue
+		}
+		if !allowed {
+			return l, retryAfter
+		}
+	}
+	return nil, 0
+}
+
+// rateLimitedMessage returns the message of the JSON-RPC error returned for a call rejected by a rate limit.
+func rateLimitedMessage(toolName string, l *toolRateLimit, retryAfter time.Duration) string {
+	return fmt.Sprintf("rate limit of %d calls per %s exceeded for tool %s, retry in %s",
+		l.Calls, l.Window, toolName, (retryAfter + time.Second - 1).Truncate(time.Second))
+}
@@ -0,0 +1,196 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+func Test_newToolRateLimits(t *testing.T) {
+	limits := newToolRateLimits([]filterapi.MCPToolRateLimit{
+		{Backend: "browser", Tools: []string{"navigate", "click"}, Calls: 10, Window: time.Minute},
+		{Backend: "search", Calls: 100, Window: time.Hour},
+	})
+	require.Len(t, limits, 2)
+	require.Equal(t, 0, limits[0].index)
+	require.Equal(t, 1, limits[1].index)
+
+	require.True(t, limits[0].appliesTo("browser", "navigate"))
+	require.True(t, limits[0].appliesTo("browser", "click"))
+	require.False(t, limits[0].appliesTo("browser", "screenshot"))
+	require.False(t, limits[0].appliesTo("search", "navigate"))
+	require.True(t, limits[1].appliesTo("search", "web_search"))
+	require.False(t, limits[1].appliesTo("browser", "web_search"))
+
+	require.Empty(t, newToolRateLimits(nil))
+}
+
+func TestToolRateLimit_clientKey(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "alice"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	headers := http.Header{"Authorization": []string{"Bearer " + token}, "X-User-Id": []string{"bob"}}
+	s := &session{id: "session-id"}
+
+	jwtLimit := &toolRateLimit{MCPToolRateLimit: filterapi.MCPToolRateLimit{ClientKeyType: filterapi.MCPToolRateLimitClientKeyTypeJWTSubject}}
+	require.Equal(t, "alice", jwtLimit.clientKey(s, headers))
+	require.Empty(t, jwtLimit.clientKey(s, http.Header{}))
+	require.Empty(t, jwtLimit.clientKey(s, http.Header{"Authorization": []string{"Bearer not-a-jwt"}}))
+
+	headerLimit := &toolRateLimit{MCPToolRateLimit: filterapi.MCPToolRateLimit{
+		ClientKeyType:   filterapi.MCPToolRateLimitClientKeyTypeHeader,
+		ClientKeyHeader: "x-user-id",
+	}}
+	require.Equal(t, "bob", headerLimit.clientKey(s, headers))
+
+	sessionLimit := &toolRateLimit{MCPToolRateLimit: filterapi.MCPToolRateLimit{ClientKeyType: filterapi.MCPToolRateLimitClientKeyTypeSession}}
+	require.Equal(t, "session-id", sessionLimit.clientKey(s, headers))
+}
+
+func TestToolRateLimit_counterKey(t *testing.T) {
+	limits := newToolRateLimits([]filterapi.MCPToolRateLimit{{Backend: "browser"}, {Backend: "browser"}})
+	key := limits[0].counterKey("route", "navigate", "alice")
+	require.Equal(t, key, limits[0].counterKey("route", "navigate", "alice"))
+	require.Len(t, key, len("mcp:")+64)
+	require.NotContains(t, key, "alice")
+	require.NotEqual(t, key, limits[1].counterKey("route", "navigate", "alice"))
+	require.NotEqual(t, key, limits[0].counterKey("other-route", "navigate", "alice"))
+	require.NotEqual(t, key, limits[0].counterKey("route", "click", "alice"))
+	require.NotEqual(t, key, limits[0].counterKey("route", "navigate", "bob"))
+	// The separators prevent ambiguous concatenations.
+	require.NotEqual(t, limits[0].counterKey("route", "ab", "c"), limits[0].counterKey("route", "a", "bc"))
+}
+
+func Test_rateLimitedMessage(t *testing.T) {
+	l := &toolRateLimit{MCPToolRateLimit: filterapi.MCPToolRateLimit{Calls: 10, Window: time.Minute}}
+	require.Equal(t, "rate limit of 10 calls per 1m0s exceeded for tool browser__navigate, retry in 3s",
+		rateLimitedMessage("browser__navigate", l, 2500*time.Millisecond))
+}
+
+// failingRateLimiter is a callcounter.Limiter that always fails.
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) Allow(context.Context, string, uint32, time.Duration) (bool, time.Duration, error) {
+	return false, 0, errors.New("redis is down")
+}
+func (failingRateLimiter) Close() error { return nil }
+
+func TestHandleToolCallRequest_RateLimited(t *testing.T) {
+	var backendCalls atomic.Int32
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backendCalls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		msg, _ := jsonrpc.DecodeMessage(body)
+		resp, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: msg.(*jsonrpc.Request).ID, Result: []byte(`{"content":[]}`)})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(backendServer.Close)
+
+	newProxy := func() *mcpRequestContext {
+		proxy := newTestMCPProxy()
+		proxy.backendListenerAddr = backendServer.URL
+		proxy.routes["test-route"].rateLimits = newToolRateLimits([]filterapi.MCPToolRateLimit{
+			{
+				Backend:         "backend1",
+				Tools:           []string{"test-tool"},
+				Calls:           2,
+				Window:          time.Minute,
+				ClientKeyType:   filterapi.MCPToolRateLimitClientKeyTypeHeader,
+				ClientKeyHeader: "x-user-id",
+			},
+		})
+		return proxy
+	}
+	call := func(t *testing.T, proxy *mcpRequestContext, user string) (*httptest.ResponseRecorder, error) {
+		s := &session{
+			reqCtx:             proxy,
+			route:              "test-route",
+			perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"backend1": {sessionID: "test-session"}},
+		}
+		httpReq := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		httpReq.Header.Set("x-user-id", user)
+		rr := httptest.NewRecorder()
+		_, err := proxy.handleToolCallRequest(t.Context(), s, rr, &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"},
+			&mcp.CallToolParams{Name: "backend1__test-tool"}, nil, httpReq)
+		return rr, err
+	}
+
+	t.Run("limited", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy()
+		for range 2 {
+			_, err := call(t, proxy, "alice")
+			require.NoError(t, err)
+		}
+		rr, err := call(t, proxy, "alice")
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, codeToolRateLimited, rpcErr.Code)
+		require.Equal(t, "rate limit of 2 calls per 1m0s exceeded for tool backend1__test-tool, retry in 1m0s", rpcErr.Message)
+		require.Equal(t, http.StatusOK, rr.Code)
+		msg, err := jsonrpc.DecodeMessage(rr.Body.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, codeToolRateLimited, msg.(*jsonrpc.Response).Error.(*jsonrpc.Error).Code)
+		require.Equal(t, int32(2), backendCalls.Load())
+
+		// Other clients have their own counters.
+		_, err = call(t, proxy, "bob")
+		require.NoError(t, err)
+		require.Equal(t, int32(3), backendCalls.Load())
+	})
+
+	t.Run("rejected calls", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy()
+		proxy.routes["test-route"].backends["backend1"] = filterapi.MCPBackend{
+			Name:             "backend1",
+			SchemaValidation: &filterapi.MCPSchemaValidation{Arguments: true},
+		}
+		input, err := resolveToolSchema(testToolSchema)
+		require.NoError(t, err)
+		proxy.toolSchemas.store("", "backend1", "test-tool", &toolSchemas{input: input})
+		for range 3 {
+			_, err = call(t, proxy, "alice")
+			var rpcErr *jsonrpc.Error
+			require.ErrorAs(t, err, &rpcErr)
+			require.Equal(t, int64(jsonrpc.CodeInvalidParams), rpcErr.Code)
+		}
+		require.Equal(t, int32(0), backendCalls.Load())
+
+		// The calls rejected before being forwarded don't count against the rate limits.
+		proxy.routes["test-route"].backends["backend1"] = filterapi.MCPBackend{Name: "backend1"}
+		for range 2 {
+			_, err = call(t, proxy, "alice")
+			require.NoError(t, err)
+		}
+		require.Equal(t, int32(2), backendCalls.Load())
+	})
+
+	t.Run("limiter failure", func(t *testing.T) {
+		backendCalls.Store(0)
+		proxy := newProxy()
+		proxy.rateLimiter = failingRateLimiter{}
+		for range 3 {
+			_, err := call(t, proxy, "alice")
+			require.NoError(t, err)
+		}
+		require.Equal(t, int32(3), backendCalls.Load())
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// structuredContentValidationErrorMetaKey is the key of the "_meta" field set on the tool call results whose
+	// structured content doesn't match the output schema of the tool.
+	structuredContentValidationErrorMetaKey = "aigateway.envoyproxy.io/structuredContentValidationError"
+
+	// toolSchemaInput and toolSchemaOutput are the schemas of a tool reported in the validation failure metrics.
+	toolSchemaInput  = "input"
+	toolSchemaOutput = "output"
+)
+
+// errToolSchemaUnknown is returned for the calls to tools whose input schema cannot be found in their backend.
+var errToolSchemaUnknown = errors.New("the tool is not listed by the backend, so its input schema is unknown")
+
+// toolSchemas are the resolved schemas of a tool. A nil schema is not validated.
+type toolSchemas struct {
+	input  *jsonschema.Resolved
+	output *jsonschema.Resolved
+}
+
+// resolveToolSchema resolves the given schema of a tool as advertised by the backend.
+func resolveToolSchema(schema any) (*jsonschema.Resolved, error) {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	var s jsonschema.Schema
+	if err = json.Unmarshal(encoded, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	return s.Resolve(nil)
+}
+
+// validateWithSchema validates the given value against the schema. The value is converted to generic JSON values
+// first, since the schema validation doesn't support arbitrary Go types.
+func validateWithSchema(schema *jsonschema.Resolved, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	var generic any
+	if err = json.Unmarshal(encoded, &generic); err != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+	return schema.Validate(generic)
+}
+
+// cacheToolSchemas caches the schemas of the given tool listed by a backend, so that the calls to the tool in the
+// session can be validated.
+func (m *mcpRequestContext) cacheToolSchemas(s *session, backend filterapi.MCPBackendName, validation *filterapi.MCPSchemaValidation, tool *mcp.Tool) {
+	schemas := &toolSchemas{}
+	var err error
+	if validation.Arguments && tool.InputSchema != nil {
+		if schemas.input, err = resolveToolSchema(tool.InputSchema); err != nil {
+			m.l.Warn("failed to resolve the input schema of the tool, its arguments won't be validated",
+				slog.String("backend", backend), slog.String("tool", tool.Name), slog.String("error", err.Error()))
+		}
+	}
+	if validation.StructuredContent != "" && tool.OutputSchema != nil {
+		if schemas.output, err = resolveToolSchema(tool.OutputSchema); err != nil {
+			m.l.Warn("failed to resolve the output schema of the tool, its results won't be validated",
+				slog.String("backend", backend), slog.String("tool", tool.Name), slog.String("error", err.Error()))
+		}
+	}
+	m.toolSchemas.store(s.clientGatewaySessionID(), backend, tool.Name, schemas)
+}
+
+// validateToolArguments validates the arguments of a tool call against the input schema of the tool cached for
+// the session. When the tool has not been listed in the session by this replica, the tools of the backend are
+// listed first, and the calls to tools whose schema is still unknown are rejected.
+func (m *mcpRequestContext) validateToolArguments(ctx context.Context, s *session, backend filterapi.MCPBackend, tool string, arguments any) error {
+	if backend.SchemaValidation == nil || !backend.SchemaValidation.Arguments {
+		return nil
+	}
+	schemas := m.toolSchemas.load(s.clientGatewaySessionID(), backend.Name, tool)
+	if schemas == nil {
+		m.listBackendTools(ctx, s, backend.Name, tool)
+		schemas = m.toolSchemas.load(s.clientGatewaySessionID(), backend.Name, tool)
+	}
+	if schemas == nil {
+		m.l.Warn("tool call arguments cannot be validated",
+			slog.String("backend", backend.Name), slog.String("tool", tool))
+		m.metrics.WithBackend(backend.Name).RecordToolSchemaValidationFailure(ctx, tool, toolSchemaInput)
+		return errToolSchemaUnknown
+	}
+	if schemas.input == nil {
+		return nil
+	}
+	if arguments == nil {
+		// Missing arguments are equivalent to no arguments.
+		arguments = map[string]any{}
+	}
+	if err := validateWithSchema(schemas.input, arguments); err != nil {
+		m.l.Warn("tool call arguments don't match the input schema",
+			slog.String("backend", backend.Name), slog.String("tool", tool), slog.String("error", err.Error()))
+		m.metrics.WithBackend(backend.Name).RecordToolSchemaValidationFailure(ctx, tool, toolSchemaInput)
+		return err
+	}
+	return nil
+}
+
+// maybeValidateStructuredContent validates the structured content of the given tool call result against the output
+// schema of the tool cached for the session, and flags or strips it if it is invalid. It returns true if the
+// result has been modified.
+func (m *mcpRequestContext) maybeValidateStructuredContent(ctx context.Context, s *session, backend filterapi.MCPBackend, tool string, result *mcp.CallToolResult) bool {
+	if backend.SchemaValidation == nil || backend.SchemaValidation.StructuredContent == "" ||
+		result.StructuredContent == nil || result.IsError {
+		return false
+	}
+	schemas := m.toolSchemas.load(s.clientGatewaySessionID(), backend.Name, tool)
+	if schemas == nil || schemas.output == nil {
+		return false
+	}
+	err := validateWithSchema(schemas.output, result.StructuredContent)
+	if err == nil {
+		return false
+	}
+
+	action := backend.SchemaValidation.StructuredContent
+	m.l.Warn("tool call structured content doesn't match the output schema",
+		slog.String("backend", backend.Name), slog.String("tool", tool),
+		slog.String("action", string(action)), slog.String("error", err.Error()))
+	m.metrics.WithBackend(backend.Name).RecordToolSchemaValidationFailure(ctx, tool, toolSchemaOutput)
+	switch action {
+	case filterapi.MCPStructuredContentValidationActionStrip:
+		result.StructuredContent = nil
+	default:
+		if result.Meta == nil {
+			result.Meta = mcp.Meta{}
+		}
+		result.Meta[structuredContentValidationErrorMetaKey] = err.Error()
+	}
+	return true
+}
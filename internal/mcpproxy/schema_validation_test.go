@@ -0,0 +1,218 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+var testToolSchema = map[string]any{
+	"type":       "object",
+	"properties": map[string]any{"query": map[string]any{"type": "string"}},
+	"required":   []any{"query"},
+}
+
+func TestMergeToolsList_CachesToolSchemas(t *testing.T) {
+	proxy := newTestMCPProxy()
+	proxy.routes["test-route"].toolSelectors = nil
+	proxy.routes["test-route"].backends["backend1"] = filterapi.MCPBackend{
+		Name:             "backend1",
+		SchemaValidation: &filterapi.MCPSchemaValidation{Arguments: true},
+	}
+	s := &session{id: "session", route: "test-route"}
+	proxy.mergeToolsList(t.Context(), s, []broadCastResponse[mcp.ListToolsResult]{
+		{
+			backendName: "backend1",
+			res: mcp.ListToolsResult{Tools: []*mcp.Tool{
+				{Name: "search", InputSchema: testToolSchema, OutputSchema: testToolSchema},
+				{Name: "invalid", InputSchema: map[string]any{"type": 1}},
+			}},
+		},
+		{
+			backendName: "backend2",
+			res:         mcp.ListToolsResult{Tools: []*mcp.Tool{{Name: "search", InputSchema: testToolSchema}}},
+		},
+	})
+
+	schemas := proxy.toolSchemas.load("session", "backend1", "search")
+	require.NotNil(t, schemas)
+	require.NotNil(t, schemas.input)
+	// The results are not validated, so the output schema is not cached.
+	require.Nil(t, schemas.output)
+	// A tool whose schema cannot be resolved is not validated.
+	require.Equal(t, &toolSchemas{}, proxy.toolSchemas.load("session", "backend1", "invalid"))
+	// Backends without schema validation are not cached.
+	require.Nil(t, proxy.toolSchemas.load("session", "backend2", "search"))
+}
+
+func TestHandleToolCallRequest_SchemaValidation(t *testing.T) {
+	var calls, lists atomic.Int32
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		msg, _ := jsonrpc.DecodeMessage(body)
+		req := msg.(*jsonrpc.Request)
+		result := []byte(`{"content":[]}`)
+		if req.Method == "tools/list" {
+			lists.Add(1)
+			result, _ = json.Marshal(&mcp.ListToolsResult{Tools: []*mcp.Tool{{Name: "test-tool", InputSchema: testToolSchema}}})
+		} else {
+			calls.Add(1)
+		}
+		respBody, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: req.ID, Result: result})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(respBody)
+	}))
+	t.Cleanup(backendServer.Close)
+
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = backendServer.URL
+	proxy.routes["test-route"].toolSelectors = nil
+	proxy.routes["test-route"].backends["backend1"] = filterapi.MCPBackend{
+		Name:             "backend1",
+		SchemaValidation: &filterapi.MCPSchemaValidation{Arguments: true},
+	}
+	s := &session{
+		id:                 "session",
+		reqCtx:             proxy,
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"backend1": {backendName: "backend1", sessionID: "test-session"}},
+		route:              "test-route",
+	}
+	input, err := resolveToolSchema(testToolSchema)
+	require.NoError(t, err)
+	proxy.toolSchemas.store("session", "backend1", "test-tool", &toolSchemas{input: input})
+
+	for _, tc := range []struct {
+		name      string
+		arguments any
+		expValid  bool
+	}{
+		{name: "valid", arguments: map[string]any{"query": "envoy"}, expValid: true},
+		{name: "missing required", arguments: map[string]any{}},
+		{name: "missing arguments"},
+		{name: "wrong type", arguments: map[string]any{"query": 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls.Store(0)
+			params := &mcp.CallToolParams{Name: "backend1__test-tool", Arguments: tc.arguments}
+			req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"}
+			rr := httptest.NewRecorder()
+			_, err := proxy.handleToolCallRequest(t.Context(), s, rr, req, params, nil, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+			if tc.expValid {
+				require.NoError(t, err)
+				require.Equal(t, int32(1), calls.Load())
+				return
+			}
+			var rpcErr *jsonrpc.Error
+			require.ErrorAs(t, err, &rpcErr)
+			require.Equal(t, int64(jsonrpc.CodeInvalidParams), rpcErr.Code)
+			require.Contains(t, rpcErr.Message, "invalid arguments for tool backend1__test-tool")
+			require.Equal(t, int32(0), calls.Load())
+		})
+	}
+
+	t.Run("not listed", func(t *testing.T) {
+		calls.Store(0)
+		lists.Store(0)
+		other := &session{
+			id:                 "other-session",
+			reqCtx:             proxy,
+			perBackendSessions: s.perBackendSessions,
+			route:              "test-route",
+		}
+		call := func(tool string, arguments any) error {
+			params := &mcp.CallToolParams{Name: "backend1__" + tool, Arguments: arguments}
+			req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"}
+			_, err := proxy.handleToolCallRequest(t.Context(), other, httptest.NewRecorder(), req, params, nil, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+			return err
+		}
+
+		// The tools of the backend are listed to validate the call to a tool that has not been listed in the session.
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, call("test-tool", map[string]any{}), &rpcErr)
+		require.Equal(t, int64(jsonrpc.CodeInvalidParams), rpcErr.Code)
+		require.Equal(t, int32(1), lists.Load())
+		require.Equal(t, int32(0), calls.Load())
+
+		// The listed schemas are cached for the next calls.
+		require.NoError(t, call("test-tool", map[string]any{"query": "envoy"}))
+		require.Equal(t, int32(1), lists.Load())
+		require.Equal(t, int32(1), calls.Load())
+
+		// The calls to tools that the backend doesn't list cannot be validated, so they are rejected.
+		require.ErrorAs(t, call("unknown-tool", map[string]any{}), &rpcErr)
+		require.Contains(t, rpcErr.Message, errToolSchemaUnknown.Error())
+		require.Equal(t, int32(2), lists.Load())
+		require.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestMaybeResponseModify_StructuredContentValidation(t *testing.T) {
+	output, err := resolveToolSchema(testToolSchema)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name      string
+		action    filterapi.MCPStructuredContentValidationAction
+		result    string
+		expResult map[string]any
+	}{
+		{
+			name:      "valid",
+			action:    filterapi.MCPStructuredContentValidationActionStrip,
+			result:    `{"content":[],"structuredContent":{"query":"envoy"}}`,
+			expResult: map[string]any{"content": []any{}, "structuredContent": map[string]any{"query": "envoy"}},
+		},
+		{
+			name:      "strip",
+			action:    filterapi.MCPStructuredContentValidationActionStrip,
+			result:    `{"content":[{"type":"text","text":"1"}],"structuredContent":{"query":1}}`,
+			expResult: map[string]any{"content": []any{map[string]any{"type": "text", "text": "1"}}},
+		},
+		{
+			name:   "flag",
+			action: filterapi.MCPStructuredContentValidationActionFlag,
+			result: `{"content":[],"structuredContent":{}}`,
+			expResult: map[string]any{
+				"_meta":             map[string]any{structuredContentValidationErrorMetaKey: `validating root: required: missing properties: ["query"]`},
+				"content":           []any{},
+				"structuredContent": map[string]any{},
+			},
+		},
+		{
+			name:      "error results are not validated",
+			action:    filterapi.MCPStructuredContentValidationActionStrip,
+			result:    `{"content":[],"structuredContent":{},"isError":true}`,
+			expResult: map[string]any{"content": []any{}, "structuredContent": map[string]any{}, "isError": true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy := newTestMCPProxy()
+			proxy.toolSchemas.store("session", "backend1", "test-tool", &toolSchemas{output: output})
+			backend := filterapi.MCPBackend{
+				Name:             "backend1",
+				SchemaValidation: &filterapi.MCPSchemaValidation{StructuredContent: tc.action},
+			}
+			req := &jsonrpc.Request{Method: "tools/call", Params: []byte(`{"name":"test-tool"}`)}
+			msg := &jsonrpc.Response{Result: []byte(tc.result)}
+			require.NoError(t, proxy.maybeResponseModify(t.Context(), &session{id: "session"}, req, msg, backend))
+
+			var result map[string]any
+			require.NoError(t, json.Unmarshal(msg.Result, &result))
+			require.Equal(t, tc.expResult, result)
+		})
+	}
+}
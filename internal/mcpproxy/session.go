@@ -54,6 +54,9 @@ type session struct {
 	reqCtx             *mcpRequestContext
 	mu                 sync.RWMutex
 	perBackendSessions map[filterapi.MCPBackendName]*compositeSessionEntry
+	// clientCapabilities are the capabilities of the client relevant to the gateway, as declared in the
+	// initialize request.
+	clientCapabilities *mcpsdk.ClientCapabilities
 	// extraHeaders contains header values extracted from the current HTTP request to be forwarded to ALL backends.
 	// These are derived from the route's configured forward headers (e.g., OAuth claimToHeaders) and the current request's headers.
 	// Note: extraHeaders is NOT encoded in the session ID. It is re-extracted from each incoming request.
@@ -73,6 +76,11 @@ func (s *session) Close() error {
 			// Stateless backend, nothing to do.
 			continue
 		}
+		if backend, err := s.reqCtx.getBackendForRoute(s.route, backendName); err == nil && backend.Transport == filterapi.MCPBackendTransportSSE {
+			// The sessions of the SSE backends end with their streams.
+			s.reqCtx.closeSSEBackendConnection(sessionID)
+			continue
+		}
 		req, err := http.NewRequest(http.MethodDelete, s.reqCtx.backendListenerAddr, nil)
 		if err != nil {
 			s.reqCtx.l.Error("failed to create DELETE request to MCP server to close session",
@@ -85,6 +93,16 @@ func (s *session) Close() error {
 		addMCPHeaders(req, nil, nil, s.route, backendName)
 		s.reqCtx.applyOriginalPathHeaders(req)
 		req.Header.Set(sessionIDHeader, sessionID.String())
+		if backend, err := s.reqCtx.getBackendForRoute(s.route, backendName); err == nil {
+			if err = s.reqCtx.setBackendToken(req.Context(), req, s.route, backend); err != nil {
+				s.reqCtx.l.Error("failed to exchange the token to close session",
+					slog.String("backend", backendName),
+					slog.String("session_id", string(sessionID)),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+		}
 		resp, err := s.reqCtx.client.Do(req)
 		if err != nil {
 			s.reqCtx.l.Error("failed to send DELETE request to MCP server to close session",
@@ -296,6 +314,12 @@ func (s *session) sendToAllBackends(ctx context.Context, httpMethod string, requ
 // and returns a channel that streams the response events from those backends.
 // If filter is nil, all backends are included.
 func (s *session) sendToBackendsFiltered(ctx context.Context, httpMethod string, request *jsonrpc.Request, params mcpsdk.Params, span tracingapi.MCPSpan, filter func(*compositeSessionEntry) bool) <-chan *backendEvent {
+	return s.sendPerBackendRequests(ctx, httpMethod, func(string) *jsonrpc.Request { return request }, params, span, filter)
+}
+
+// sendPerBackendRequests is like sendToBackendsFiltered, but sends to each backend the request returned by
+// requestFor for its name. This is used when the request differs per backend, e.g. to carry its own cursor.
+func (s *session) sendPerBackendRequests(ctx context.Context, httpMethod string, requestFor func(backendName filterapi.MCPBackendName) *jsonrpc.Request, params mcpsdk.Params, span tracingapi.MCPSpan, filter func(*compositeSessionEntry) bool) <-chan *backendEvent {
 	var (
 		logger      = s.reqCtx.l
 		backendMsgs = make(chan *backendEvent, 200)
@@ -319,7 +343,7 @@ func (s *session) sendToBackendsFiltered(ctx context.Context, httpMethod string,
 				)
 				return
 			}
-			err = s.sendRequestPerBackend(ctx, backendMsgs, s.route, backend, cse, httpMethod, request, params)
+			err = s.sendRequestPerBackend(ctx, backendMsgs, s.route, backend, cse, httpMethod, requestFor(backendName), params)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
 					return
@@ -347,6 +371,12 @@ func (s *session) sendToBackendsFiltered(ctx context.Context, httpMethod string,
 func (s *session) sendRequestPerBackend(ctx context.Context, eventChan chan<- *backendEvent, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, cse *compositeSessionEntry,
 	httpMethod string, request *jsonrpc.Request, params mcpsdk.Params,
 ) error {
+	if backend.OpenAPI != nil {
+		return s.sendOpenAPIBackendRequest(eventChan, routeName, backend, request)
+	}
+	if backend.Transport == filterapi.MCPBackendTransportSSE {
+		return s.sendSSEBackendRequest(ctx, eventChan, routeName, backend, cse, httpMethod, request, params)
+	}
 	var body io.Reader
 	if request != nil {
 		encodedReq, err := jsonrpc.EncodeMessage(request)
@@ -385,6 +415,10 @@ func (s *session) sendRequestPerBackend(ctx context.Context, eventChan chan<- *b
 		}
 	}
 
+	if err = s.reqCtx.setBackendToken(ctx, req, routeName, backend); err != nil {
+		return err
+	}
+
 	if lastEventID := cse.lastEventID; lastEventID != "" {
 		req.Header.Set(lastEventIDHeader, lastEventID)
 	}
@@ -575,6 +609,56 @@ const (
 		capBitCompletions
 )
 
+// Client capability bitmask constants for encoding the client capabilities relevant to the gateway in the session ID.
+const (
+	clientCapBitElicitation = 1 << iota // bit 0: Elicitation non-nil
+)
+
+// clientCapabilitiesSeparator separates the client capability flags from the rest of the session ID. It cannot appear
+// in the backend session segment, which only contains backend names, base64 and hex characters.
+const clientCapabilitiesSeparator = "#"
+
+// encodeClientCapabilityFlags encodes the client capabilities relevant to the gateway as a hex string.
+func encodeClientCapabilityFlags(caps *mcpsdk.ClientCapabilities) string {
+	var bits uint
+	if caps != nil && caps.Elicitation != nil {
+		bits |= clientCapBitElicitation
+	}
+	return strconv.FormatUint(uint64(bits), 16)
+}
+
+// decodeClientCapabilityFlags decodes a hex string into the client capabilities relevant to the gateway.
+// Invalid strings decode to no capabilities.
+func decodeClientCapabilityFlags(hex string) *mcpsdk.ClientCapabilities {
+	bits, _ := strconv.ParseUint(hex, 16, 16)
+	caps := &mcpsdk.ClientCapabilities{}
+	if bits&clientCapBitElicitation != 0 {
+		caps.Elicitation = &mcpsdk.ElicitationCapabilities{}
+	}
+	return caps
+}
+
+// withClientCapabilities appends the client capability flags to the session ID.
+func (c clientToGatewaySessionID) withClientCapabilities(caps *mcpsdk.ClientCapabilities) clientToGatewaySessionID {
+	return c + clientCapabilitiesSeparator + clientToGatewaySessionID(encodeClientCapabilityFlags(caps))
+}
+
+// clientCapabilities splits the client capability flags from the session ID. The session IDs without flags,
+// created before they were introduced, have no client capabilities.
+func (c clientToGatewaySessionID) clientCapabilities() (clientToGatewaySessionID, *mcpsdk.ClientCapabilities) {
+	id := string(c)
+	sep := strings.LastIndex(id, clientCapabilitiesSeparator)
+	if sep < 0 || sep < strings.LastIndex(id, "@") {
+		return c, &mcpsdk.ClientCapabilities{}
+	}
+	return clientToGatewaySessionID(id[:sep]), decodeClientCapabilityFlags(id[sep+1:])
+}
+
+// supportsElicitation returns true if the client declared the elicitation capability.
+func (s *session) supportsElicitation() bool {
+	return s.clientCapabilities != nil && s.clientCapabilities.Elicitation != nil
+}
+
 // encodeCapabilityFlags encodes server capabilities as a zero-padded 3-char hex string.
 func encodeCapabilityFlags(caps *mcpsdk.ServerCapabilities) string {
 	if caps == nil {
@@ -47,7 +47,11 @@ func (stubMetrics) RecordClientCapabilities(context.Context, *mcpsdk.ClientCapab
 
 func (stubMetrics) RecordServerCapabilities(context.Context, *mcpsdk.ServerCapabilities, mcpsdk.Params) {
 }
-func (stubMetrics) RecordProgress(context.Context, mcpsdk.Params) {}
+func (stubMetrics) RecordProgress(context.Context, mcpsdk.Params)                     {}
+func (stubMetrics) RecordToolDefinitionDrift(context.Context, string, string)         {}
+func (stubMetrics) RecordToolSchemaValidationFailure(context.Context, string, string) {}
+func (stubMetrics) RecordToolRateLimited(context.Context, string)                     {}
+func (stubMetrics) RecordToolApproval(context.Context, string, string, string)        {}
 
 func TestEncodeCapabilityFlags(t *testing.T) {
 	t.Parallel()
@@ -339,6 +343,40 @@ func TestClientToGatewaySessionIDFromEntries_WithCapabilities(t *testing.T) {
 	require.Nil(t, m["b2"].capabilities.Logging)
 }
 
+func TestClientToGatewaySessionID_ClientCapabilities(t *testing.T) {
+	t.Parallel()
+	entries := []compositeSessionEntry{{backendName: "b1", sessionID: "sid-1"}}
+	for _, tc := range []struct {
+		name        string
+		caps        *mcpsdk.ClientCapabilities
+		elicitation bool
+	}{
+		{name: "nil"},
+		{name: "no elicitation", caps: &mcpsdk.ClientCapabilities{Sampling: &mcpsdk.SamplingCapabilities{}}},
+		{name: "elicitation", caps: &mcpsdk.ClientCapabilities{Elicitation: &mcpsdk.ElicitationCapabilities{}}, elicitation: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			// The subject may contain the separator.
+			id := clientToGatewaySessionIDFromEntries("user#1@example.com", entries, "route1").withClientCapabilities(tc.caps)
+			sessionID, caps := id.clientCapabilities()
+			require.Equal(t, tc.elicitation, (&session{clientCapabilities: caps}).supportsElicitation())
+			m, route, err := sessionID.backendSessionIDs()
+			require.NoError(t, err)
+			require.Equal(t, "route1", route)
+			require.Equal(t, "sid-1", string(m["b1"].sessionID))
+		})
+	}
+
+	t.Run("legacy", func(t *testing.T) {
+		t.Parallel()
+		id := clientToGatewaySessionIDFromEntries("user#1@example.com", entries, "route1")
+		sessionID, caps := id.clientCapabilities()
+		require.Equal(t, id, sessionID)
+		require.Nil(t, caps.Elicitation)
+	})
+}
+
 func TestBackendSessionIDs_EmailSubject(t *testing.T) {
 	t.Parallel()
 	backendA := "backendA"
@@ -0,0 +1,86 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+// sessionToolCacheIdleTimeout is the time after which the cached values of a session that is not used anymore are
+// evicted. Sessions that are closed by the clients are evicted right away.
+const sessionToolCacheIdleTimeout = time.Hour
+
+type (
+	// sessionToolCache caches a value per tool listed in each session, so that the tool calls of the session can be
+	// checked against the tool definitions advertised by the backends.
+	sessionToolCache[T any] struct {
+		mu       sync.Mutex
+		sessions map[secureClientToGatewaySessionID]*sessionTools[T]
+		now      func() time.Time
+	}
+
+	// sessionTools are the cached values of the tools of a session.
+	sessionTools[T any] struct {
+		// tools is keyed by sessionToolKey.
+		tools        map[string]T
+		lastAccessed time.Time
+	}
+)
+
+func newSessionToolCache[T any]() *sessionToolCache[T] {
+	return &sessionToolCache[T]{
+		sessions: make(map[secureClientToGatewaySessionID]*sessionTools[T]),
+		now:      time.Now,
+	}
+}
+
+// sessionToolKey returns the key of a tool in the cached values of a session.
+func sessionToolKey(backend filterapi.MCPBackendName, tool string) string {
+	return backend + "\x00" + tool
+}
+
+// store caches the value of the given tool for the given session, and evicts the idle sessions.
+func (c *sessionToolCache[T]) store(session secureClientToGatewaySessionID, backend filterapi.MCPBackendName, tool string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for id, s := range c.sessions {
+		if now.Sub(s.lastAccessed) >= sessionToolCacheIdleTimeout {
+			delete(c.sessions, id)
+		}
+	}
+	s, ok := c.sessions[session]
+	if !ok {
+		s = &sessionTools[T]{tools: make(map[string]T)}
+		c.sessions[session] = s
+	}
+	s.tools[sessionToolKey(backend, tool)] = value
+	s.lastAccessed = now
+}
+
+// load returns the cached value of the given tool for the given session, or the zero value if the tool has not
+// been listed in the session.
+func (c *sessionToolCache[T]) load(session secureClientToGatewaySessionID, backend filterapi.MCPBackendName, tool string) T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[session]
+	if !ok {
+		var zero T
+		return zero
+	}
+	s.lastAccessed = c.now()
+	return s.tools[sessionToolKey(backend, tool)]
+}
+
+// delete evicts the cached values of the given session.
+func (c *sessionToolCache[T]) delete(session secureClientToGatewaySessionID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, session)
+}
@@ -0,0 +1,36 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionToolCache(t *testing.T) {
+	now := time.Now()
+	c := newSessionToolCache[string]()
+	c.now = func() time.Time { return now }
+
+	c.store("session1", "backend1", "tool", "fingerprint")
+	require.Equal(t, "fingerprint", c.load("session1", "backend1", "tool"))
+	require.Empty(t, c.load("session1", "backend2", "tool"))
+	require.Empty(t, c.load("session2", "backend1", "tool"))
+
+	// Idle sessions are evicted when other sessions store values.
+	c.store("session2", "backend1", "tool", "fingerprint")
+	now = now.Add(sessionToolCacheIdleTimeout / 2)
+	require.NotEmpty(t, c.load("session1", "backend1", "tool"))
+	now = now.Add(sessionToolCacheIdleTimeout / 2)
+	c.store("session3", "backend1", "tool", "fingerprint")
+	require.NotEmpty(t, c.load("session1", "backend1", "tool"))
+	require.Empty(t, c.load("session2", "backend1", "tool"))
+
+	c.delete("session1")
+	require.Empty(t, c.load("session1", "backend1", "tool"))
+}
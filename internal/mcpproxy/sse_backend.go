@@ -0,0 +1,433 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// protocolVersion20241105 is the version of the MCP specification that defines the legacy HTTP+SSE transport.
+	protocolVersion20241105 = "2024-11-05"
+
+	// sseBackendIdleTimeout is the time after which an unused connection to an SSE backend is closed.
+	sseBackendIdleTimeout = 30 * time.Minute
+	// sseBackendMessagesBufferSize is the number of the server-initiated messages of an SSE backend buffered until
+	// they are sent to the notification stream of the client. The messages are dropped when the buffer is full.
+	sseBackendMessagesBufferSize = 100
+)
+
+// errSSEBackendConnectionClosed is returned when the connection to an SSE backend is closed while waiting for a
+// response.
+var errSSEBackendConnectionClosed = errors.New("connection to the SSE backend closed")
+
+// sseBackendConnection is a connection to a backend speaking the legacy HTTP+SSE transport of the 2024-11-05 MCP
+// specification.
+//
+// With this transport, the backend sends the responses on a long-lived SSE stream, and the messages are posted to the
+// endpoint advertised by the backend on the stream. The connection maps this onto the request/response model of the
+// Streamable HTTP transport used for the other backends: the responses are matched to the requests by their IDs,
+// and the messages initiated by the backend are sent to the notification stream of the client.
+//
+// The connections live in the memory of the MCP proxy, and are identified by a gateway-generated ID used as the
+// session ID of the backend. When the connection of a session is not found, e.g. because the request reached another
+// replica or the backend closed the stream, a new connection is opened and initialized transparently.
+type sseBackendConnection struct {
+	id      gatewayToMCPServerSessionID
+	backend filterapi.MCPBackendName
+	cancel  context.CancelFunc
+	// ready is closed when the connection is established, or failed to be with err.
+	ready chan struct{}
+	err   error
+	// endpoint is the request URI the messages are posted to.
+	endpoint string
+	// messages are the requests and notifications initiated by the backend.
+	messages chan jsonrpc.Message
+	// done is closed when the stream of the backend ends.
+	done chan struct{}
+
+	mu      sync.Mutex
+	pending map[jsonrpc.ID]chan *jsonrpc.Response
+
+	// streams is the number of the notification streams of the clients reading the messages.
+	streams   atomic.Int32
+	idleTimer *time.Timer
+}
+
+// sseBackendConnection returns the connection of the given session of an SSE backend. When cse is nil or has no
+// session ID, a new connection is opened for the initialize request. When the connection of the session is not
+// found, a new one is opened and initialized under the same session ID.
+func (m *mcpRequestContext) sseBackendConnection(ctx context.Context, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, cse *compositeSessionEntry) (*sseBackendConnection, error) {
+	var id gatewayToMCPServerSessionID
+	if cse != nil {
+		id = cse.sessionID
+	}
+	reinitialize := id != ""
+	if !reinitialize {
+		id = gatewayToMCPServerSessionID(uuid.NewString())
+	}
+
+	conn := &sseBackendConnection{
+		id:       id,
+		backend:  backend.Name,
+		ready:    make(chan struct{}),
+		messages: make(chan jsonrpc.Message, sseBackendMessagesBufferSize),
+		done:     make(chan struct{}),
+		pending:  make(map[jsonrpc.ID]chan *jsonrpc.Response),
+	}
+	if existing, loaded := m.sseBackendConnections.LoadOrStore(id, conn); loaded {
+		existingConn := existing.(*sseBackendConnection)
+		select {
+		case <-existingConn.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if existingConn.err != nil {
+			return nil, existingConn.err
+		}
+		return existingConn, nil
+	}
+
+	conn.err = m.connectSSEBackend(ctx, routeName, backend, conn)
+	if conn.err == nil && reinitialize {
+		conn.err = m.reinitializeSSEBackend(ctx, routeName, backend, conn)
+	}
+	if conn.err != nil {
+		conn.close()
+		m.sseBackendConnections.CompareAndDelete(id, conn)
+	}
+	close(conn.ready)
+	if conn.err != nil {
+		return nil, conn.err
+	}
+	return conn, nil
+}
+
+// connectSSEBackend opens the SSE stream of the backend, and waits for the endpoint the messages are posted to.
+func (m *mcpRequestContext) connectSSEBackend(ctx context.Context, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, conn *sseBackendConnection) error {
+	// The stream outlives the request that opened it, so it is only canceled when the connection is closed.
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	conn.cancel = cancel
+	conn.idleTimer = time.AfterFunc(sseBackendIdleTimeout, conn.expire)
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, m.backendListenerAddr+backend.Path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create SSE request: %w", err)
+	}
+	addMCPHeaders(req, nil, nil, routeName, backend.Name)
+	m.applyOriginalPathHeaders(req)
+	req.Header.Set("Accept", "text/event-stream")
+	m.applyForwardHeaders(req, routeName, backend.Name)
+	if err = m.setBackendToken(ctx, req, routeName, backend); err != nil {
+		return err
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open SSE stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return fmt.Errorf("SSE stream request failed with status code %d, body=%s", resp.StatusCode, string(body))
+	}
+
+	endpoints := make(chan string, 1)
+	go func() {
+		defer func() {
+			_ = resp.Body.Close()
+			conn.close()
+			m.sseBackendConnections.CompareAndDelete(conn.id, conn)
+		}()
+		err := readSSEBackendEvents(resp.Body, func(event, data string) {
+			switch event {
+			case "endpoint":
+				endpoint, err := req.URL.Parse(data)
+				if err != nil {
+					m.l.Error("invalid endpoint of SSE backend", slog.String("backend", backend.Name), slog.String("endpoint", data))
+					return
+				}
+				select {
+				case endpoints <- endpoint.RequestURI():
+				default: // Only the first endpoint is used.
+				}
+			case "", "message":
+				msg, err := jsonrpc.DecodeMessage([]byte(data))
+				if err != nil {
+					m.l.Error("invalid message from SSE backend", slog.String("backend", backend.Name), slog.String("error", err.Error()))
+					return
+				}
+				conn.dispatch(m.l, msg)
+			}
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			m.l.Debug("SSE backend stream ended", slog.String("backend", backend.Name), slog.String("error", err.Error()))
+		}
+	}()
+
+	select {
+	case conn.endpoint = <-endpoints:
+	case <-conn.done:
+		return errors.New("SSE stream ended before the endpoint was received")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// reinitializeSSEBackend initializes a new connection to a backend on behalf of a client whose session was
+// initialized with another connection.
+func (m *mcpRequestContext) reinitializeSSEBackend(ctx context.Context, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, conn *sseBackendConnection) error {
+	p := &mcp.InitializeParams{
+		ProtocolVersion: protocolVersion20241105,
+		Capabilities:    &mcp.ClientCapabilities{},
+		ClientInfo:      &mcp.Implementation{Name: "envoy-ai-gateway"},
+	}
+	params, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP initialize params: %w", err)
+	}
+	resp, err := conn.send(ctx, m, routeName, backend, &jsonrpc.Request{Method: "initialize", Params: params, ID: mustJSONRPCRequestID()}, p)
+	if err != nil {
+		return fmt.Errorf("failed to reinitialize SSE backend: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("failed to reinitialize SSE backend: %w", resp.Error)
+	}
+	if _, err = conn.send(ctx, m, routeName, backend, &jsonrpc.Request{Method: "notifications/initialized", Params: emptyJSONRPCMessage}, p); err != nil {
+		return fmt.Errorf("failed to send MCP notifications/initialized request: %w", err)
+	}
+	return nil
+}
+
+// send posts the message to the backend, and returns the response when the message is a request.
+func (c *sseBackendConnection) send(ctx context.Context, m *mcpRequestContext, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, msg jsonrpc.Message, params mcp.Params) (*jsonrpc.Response, error) {
+	c.touch()
+	var responses chan *jsonrpc.Response
+	if req, ok := msg.(*jsonrpc.Request); ok && req.ID.IsValid() {
+		responses = make(chan *jsonrpc.Response, 1)
+		c.mu.Lock()
+		c.pending[req.ID] = responses
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.pending, req.ID)
+			c.mu.Unlock()
+		}()
+	}
+
+	encoded, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode MCP message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.backendListenerAddr+c.endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP request: %w", err)
+	}
+	addMCPHeaders(req, msg, params, routeName, backend.Name)
+	m.applyLogHeaderMappings(req, msg)
+	m.applyOriginalPathHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	m.applyForwardHeaders(req, routeName, backend.Name)
+	if err = m.setBackendToken(ctx, req, routeName, backend); err != nil {
+		return nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send MCP request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("MCP request failed with status code %d, body=%s", resp.StatusCode, string(body))
+	}
+	ensureHTTPConnectionReused(resp)
+
+	if responses == nil {
+		return nil, nil
+	}
+	select {
+	case response := <-responses:
+		return response, nil
+	case <-c.done:
+		return nil, errSSEBackendConnectionClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch delivers a message received on the stream of the backend to the request waiting for it, or to the
+// notification stream of the client.
+func (c *sseBackendConnection) dispatch(l *slog.Logger, msg jsonrpc.Message) {
+	c.touch()
+	if resp, ok := msg.(*jsonrpc.Response); ok {
+		c.mu.Lock()
+		responses, found := c.pending[resp.ID]
+		c.mu.Unlock()
+		if found {
+			responses <- resp
+			return
+		}
+		l.Debug("dropping unexpected response from SSE backend", slog.String("backend", c.backend), slog.Any("id", resp.ID.Raw()))
+		return
+	}
+	select {
+	case c.messages <- msg:
+	default:
+		l.Warn("dropping message from SSE backend as no client is reading them", slog.String("backend", c.backend))
+	}
+}
+
+// touch postpones the expiry of the connection.
+func (c *sseBackendConnection) touch() {
+	if c.idleTimer != nil {
+		c.idleTimer.Reset(sseBackendIdleTimeout)
+	}
+}
+
+// expire closes the connection unless a client is reading its messages.
+func (c *sseBackendConnection) expire() {
+	if c.streams.Load() > 0 {
+		c.touch()
+		return
+	}
+	c.close()
+}
+
+// close closes the stream of the backend.
+func (c *sseBackendConnection) close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+}
+
+// closeSSEBackendConnection closes the connection of the given session of an SSE backend, if any.
+func (m *mcpRequestContext) closeSSEBackendConnection(id gatewayToMCPServerSessionID) {
+	if conn, ok := m.sseBackendConnections.LoadAndDelete(id); ok {
+		conn.(*sseBackendConnection).close()
+	}
+}
+
+// invokeSSEBackend sends the message to an SSE backend, and returns the response as if the backend spoke the
+// Streamable HTTP transport.
+func (m *mcpRequestContext) invokeSSEBackend(ctx context.Context, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, cse *compositeSessionEntry, msg jsonrpc.Message, params mcp.Params) (*http.Response, error) {
+	conn, err := m.sseBackendConnection(ctx, routeName, backend, cse)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := conn.send(ctx, m, routeName, backend, msg, params)
+	if err != nil {
+		return nil, err
+	}
+	httpResp := &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{}, Body: http.NoBody}
+	httpResp.Header.Set(sessionIDHeader, conn.id.String())
+	if resp != nil {
+		encoded, err := jsonrpc.EncodeMessage(resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode MCP response: %w", err)
+		}
+		httpResp.StatusCode = http.StatusOK
+		httpResp.Header.Set("Content-Type", "application/json")
+		httpResp.Body = io.NopCloser(bytes.NewReader(encoded))
+	}
+	return httpResp, nil
+}
+
+// sendSSEBackendRequest is the counterpart of sendRequestPerBackend for the SSE backends. The GET requests stream
+// the messages initiated by the backend, and the other requests are sent to the backend and their responses are
+// streamed to eventChan.
+func (s *session) sendSSEBackendRequest(ctx context.Context, eventChan chan<- *backendEvent, routeName filterapi.MCPRouteName, backend filterapi.MCPBackend, cse *compositeSessionEntry,
+	httpMethod string, request *jsonrpc.Request, params mcp.Params,
+) error {
+	conn, err := s.reqCtx.sseBackendConnection(ctx, routeName, backend, cse)
+	if err != nil {
+		return err
+	}
+	startAt := time.Now()
+	if httpMethod != http.MethodGet {
+		resp, err := conn.send(ctx, s.reqCtx, routeName, backend, request, params)
+		if err != nil || resp == nil {
+			return err
+		}
+		eventChan <- &backendEvent{
+			sseEvent: &sseEvent{backend: backend.Name, event: "message", messages: []jsonrpc.Message{resp}},
+			startAt:  startAt,
+		}
+		return nil
+	}
+
+	conn.streams.Add(1)
+	defer conn.streams.Add(-1)
+	for {
+		select {
+		case msg := <-conn.messages:
+			eventChan <- &backendEvent{
+				sseEvent: &sseEvent{backend: backend.Name, event: "message", messages: []jsonrpc.Message{msg}},
+				startAt:  startAt,
+			}
+		case <-conn.done:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// readSSEBackendEvents reads the events of the SSE stream of a backend and calls fn for each of them, until the
+// stream ends. Unlike sseEventParser, the data of the events is not expected to be JSON-RPC messages.
+func readSSEBackendEvents(r io.Reader, fn func(event, data string)) error {
+	var (
+		scanner = bufio.NewScanner(r)
+		event   string
+		data    []string
+	)
+	scanner.Buffer(make([]byte, 0, 4096), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				fn(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
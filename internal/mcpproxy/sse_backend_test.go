@@ -0,0 +1,320 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+// fakeSSEBackend is an MCP server speaking the legacy HTTP+SSE transport.
+type fakeSSEBackend struct {
+	mu      sync.Mutex
+	streams map[string]chan string
+	methods []string
+	opened  atomic.Int32
+	closed  atomic.Int32
+}
+
+func newFakeSSEBackend() *fakeSSEBackend {
+	return &fakeSSEBackend{streams: map[string]chan string{}}
+}
+
+// ServeHTTP implements [http.Handler.ServeHTTP].
+func (f *fakeSSEBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/sse":
+		id := uuid.NewString()
+		events := make(chan string, 16)
+		f.mu.Lock()
+		f.streams[id] = events
+		f.mu.Unlock()
+		f.opened.Add(1)
+		defer f.closed.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, "event: endpoint\ndata: /messages?sessionId=%s\n\n", id)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case event := <-events:
+				_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case r.Method == http.MethodPost && r.URL.Path == "/messages":
+		f.mu.Lock()
+		events, ok := f.streams[r.URL.Query().Get("sessionId")]
+		f.mu.Unlock()
+		if !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		msg, err := jsonrpc.DecodeMessage(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		req, ok := msg.(*jsonrpc.Request)
+		if !ok {
+			return
+		}
+		f.mu.Lock()
+		f.methods = append(f.methods, req.Method)
+		f.mu.Unlock()
+		if !req.ID.IsValid() {
+			return
+		}
+		result := `{}`
+		switch req.Method {
+		case "initialize":
+			result = `{"protocolVersion":"2024-11-05","capabilities":{"tools":{}},"serverInfo":{"name":"legacy","version":"1.0.0"}}`
+		case "tools/list":
+			result = `{"tools":[{"name":"echo","inputSchema":{"type":"object"}}]}`
+		}
+		resp, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: req.ID, Result: []byte(result)})
+		events <- string(resp)
+	case r.URL.Path == "/error":
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	default:
+		// Ends the stream without an endpoint.
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// broadcast sends the given message to all the streams.
+func (f *fakeSSEBackend) broadcast(msg string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, events := range f.streams {
+		events <- msg
+	}
+}
+
+func (f *fakeSSEBackend) receivedMethods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.methods...)
+}
+
+// startTestServer starts a test server that closes the long-lived connections when the test ends.
+func startTestServer(t *testing.T, h http.Handler) *httptest.Server {
+	server := httptest.NewServer(h)
+	t.Cleanup(func() {
+		server.CloseClientConnections()
+		server.Close()
+	})
+	return server
+}
+
+func newTestSSEBackendProxy(t *testing.T, backendListenerAddr string) (*mcpRequestContext, filterapi.MCPBackend) {
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = backendListenerAddr
+	backend := filterapi.MCPBackend{Name: "legacy", Transport: filterapi.MCPBackendTransportSSE, Path: "/sse"}
+	proxy.routes["sse-route"] = &mcpProxyConfigRoute{
+		backends: map[filterapi.MCPBackendName]filterapi.MCPBackend{"legacy": backend},
+	}
+	t.Cleanup(func() {
+		proxy.sseBackendConnections.Range(func(_, conn any) bool {
+			conn.(*sseBackendConnection).close()
+			return true
+		})
+	})
+	return proxy, backend
+}
+
+func TestInvokeSSEBackend(t *testing.T) {
+	f := newFakeSSEBackend()
+	proxy, backend := newTestSSEBackendProxy(t, startTestServer(t, f).URL)
+
+	initResult, err := proxy.initializeSession(t.Context(), "sse-route", backend, &mcp.InitializeParams{ProtocolVersion: protocolVersion20250618}, time.Now())
+	require.NoError(t, err)
+	require.NotEmpty(t, initResult.sessionID)
+	require.NotNil(t, initResult.result.Capabilities.Tools)
+	cse := &compositeSessionEntry{sessionID: initResult.sessionID, backendName: "legacy"}
+
+	resp, err := proxy.invokeJSONRPCRequest(t.Context(), "sse-route", backend, cse,
+		&jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/list", Params: emptyJSONRPCMessage}, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.Equal(t, initResult.sessionID.String(), resp.Header.Get(sessionIDHeader))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"name":"echo"`)
+
+	// The responses to the server->client requests are only posted.
+	resp, err = proxy.invokeJSONRPCRequest(t.Context(), "sse-route", backend, cse,
+		&jsonrpc.Response{ID: mustJSONRPCRequestID(), Result: emptyJSONRPCMessage}, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	require.Equal(t, int32(1), f.opened.Load())
+	require.Equal(t, []string{"initialize", "notifications/initialized", "tools/list"}, f.receivedMethods())
+}
+
+func TestInvokeSSEBackend_Reconnect(t *testing.T) {
+	f := newFakeSSEBackend()
+	proxy, backend := newTestSSEBackendProxy(t, startTestServer(t, f).URL)
+
+	initResult, err := proxy.initializeSession(t.Context(), "sse-route", backend, &mcp.InitializeParams{ProtocolVersion: protocolVersion20250618}, time.Now())
+	require.NoError(t, err)
+	cse := &compositeSessionEntry{sessionID: initResult.sessionID, backendName: "legacy"}
+
+	// E.g. the backend closed the stream, or the request reached another replica.
+	proxy.closeSSEBackendConnection(initResult.sessionID)
+	require.Eventually(t, func() bool { return f.closed.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The concurrent requests share the new connection, initialized once.
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			resp, err := proxy.invokeJSONRPCRequest(t.Context(), "sse-route", backend, cse,
+				&jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/list", Params: emptyJSONRPCMessage}, nil)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, initResult.sessionID.String(), resp.Header.Get(sessionIDHeader))
+		})
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), f.opened.Load())
+	methods := f.receivedMethods()
+	require.Equal(t, []string{"initialize", "notifications/initialized", "initialize", "notifications/initialized"}, methods[:4])
+	require.Len(t, methods, 9)
+}
+
+func TestInvokeSSEBackend_Errors(t *testing.T) {
+	f := newFakeSSEBackend()
+	server := startTestServer(t, f)
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		expErr string
+	}{
+		{name: "error status", path: "/error", expErr: "SSE stream request failed with status code 503, body=unavailable\n"},
+		{name: "no endpoint", path: "/no-endpoint", expErr: "SSE stream ended before the endpoint was received"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy, backend := newTestSSEBackendProxy(t, server.URL)
+			backend.Path = tc.path
+			_, err := proxy.invokeJSONRPCRequest(t.Context(), "sse-route", backend, nil,
+				&jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "initialize", Params: emptyJSONRPCMessage}, nil)
+			require.EqualError(t, err, tc.expErr)
+			// The failed connection is forgotten.
+			proxy.sseBackendConnections.Range(func(any, any) bool {
+				t.Fatal("unexpected connection")
+				return false
+			})
+		})
+	}
+}
+
+func TestSendSSEBackendRequest(t *testing.T) {
+	f := newFakeSSEBackend()
+	proxy, backend := newTestSSEBackendProxy(t, startTestServer(t, f).URL)
+
+	initResult, err := proxy.initializeSession(t.Context(), "sse-route", backend, &mcp.InitializeParams{ProtocolVersion: protocolVersion20250618}, time.Now())
+	require.NoError(t, err)
+	cse := &compositeSessionEntry{sessionID: initResult.sessionID, backendName: "legacy"}
+	s := &session{
+		reqCtx:             proxy,
+		route:              "sse-route",
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"legacy": cse},
+	}
+
+	t.Run("request", func(t *testing.T) {
+		events := make(chan *backendEvent, 1)
+		req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/list", Params: emptyJSONRPCMessage}
+		require.NoError(t, s.sendRequestPerBackend(t.Context(), events, "sse-route", backend, cse, http.MethodPost, req, nil))
+		event := <-events
+		require.Equal(t, "legacy", event.backend)
+		require.Len(t, event.messages, 1)
+		resp := event.messages[0].(*jsonrpc.Response)
+		require.Equal(t, req.ID, resp.ID)
+		require.Contains(t, string(resp.Result), `"name":"echo"`)
+	})
+
+	t.Run("notifications", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		events := make(chan *backendEvent, 1)
+		done := make(chan error)
+		go func() {
+			done <- s.sendRequestPerBackend(ctx, events, "sse-route", backend, cse, http.MethodGet, nil, nil)
+		}()
+		f.broadcast(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)
+		event := <-events
+		require.Equal(t, "legacy", event.backend)
+		require.Equal(t, "notifications/tools/list_changed", event.messages[0].(*jsonrpc.Request).Method)
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	// Closing the session closes the stream.
+	require.NoError(t, s.Close())
+	require.Eventually(t, func() bool { return f.closed.Load() == 1 }, time.Second, time.Millisecond)
+	_, ok := proxy.sseBackendConnections.Load(cse.sessionID)
+	require.False(t, ok)
+}
+
+func TestSSEBackendConnection_Dispatch(t *testing.T) {
+	l := newTestMCPProxy().l
+	conn := &sseBackendConnection{
+		backend:  "legacy",
+		messages: make(chan jsonrpc.Message, 1),
+		pending:  map[jsonrpc.ID]chan *jsonrpc.Response{},
+	}
+	id := mustJSONRPCRequestID()
+	responses := make(chan *jsonrpc.Response, 1)
+	conn.pending[id] = responses
+
+	conn.dispatch(l, &jsonrpc.Response{ID: id, Result: emptyJSONRPCMessage})
+	require.Equal(t, id, (<-responses).ID)
+	// Unexpected responses are dropped.
+	conn.dispatch(l, &jsonrpc.Response{ID: mustJSONRPCRequestID(), Result: emptyJSONRPCMessage})
+	require.Empty(t, responses)
+
+	conn.dispatch(l, &jsonrpc.Request{Method: "notifications/message"})
+	// The messages are dropped when no client reads them.
+	conn.dispatch(l, &jsonrpc.Request{Method: "notifications/progress"})
+	require.Len(t, conn.messages, 1)
+	require.Equal(t, "notifications/message", (<-conn.messages).(*jsonrpc.Request).Method)
+}
+
+func TestReadSSEBackendEvents(t *testing.T) {
+	var events [][2]string
+	err := readSSEBackendEvents(strings.NewReader(
+		"event: endpoint\r\ndata: /messages?sessionId=1\r\n\r\n"+
+			": comment\n\n"+
+			"data: {\"a\":1}\ndata:x\n\n"+
+			"event: message\ndata: {}\n"), // Incomplete event.
+		func(event, data string) { events = append(events, [2]string{event, data}) })
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, [][2]string{
+		{"endpoint", "/messages?sessionId=1"},
+		{"", "{\"a\":1}\nx"},
+	}, events)
+}
@@ -0,0 +1,258 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// grantTypeTokenExchange is the grant type of the OAuth 2.0 Token Exchange defined in RFC 8693.
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// grantTypeJWTBearer is the grant type of the JWT bearer assertion defined in RFC 7523, used by the on-behalf-of flow.
+	grantTypeJWTBearer = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	// tokenTypeAccessToken is the default type of the subject token.
+	tokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+
+	// tokenExchangeTimeout is the timeout of the requests to the token endpoints.
+	tokenExchangeTimeout = 10 * time.Second
+	// tokenExchangeExpirySkew is subtracted from the lifetime of the exchanged tokens, so that a cached token
+	// is not sent to a backend right before it expires.
+	tokenExchangeExpirySkew = 30 * time.Second
+)
+
+// tokenExchangeError is returned when the token of the user cannot be exchanged for a token of the backend.
+type tokenExchangeError struct {
+	// statusCode is the HTTP status code to return to the client.
+	statusCode int
+	msg        string
+}
+
+// Error implements [error.Error].
+func (e *tokenExchangeError) Error() string { return e.msg }
+
+type (
+	// tokenExchanger exchanges the tokens of the users for tokens of the backends, and caches the exchanged tokens
+	// per user token and backend until they expire.
+	tokenExchanger struct {
+		client *http.Client
+		// group deduplicates the concurrent exchanges of the same token for the same backend.
+		group singleflight.Group
+		mu    sync.Mutex
+		cache map[string]exchangedToken
+		now   func() time.Time
+	}
+
+	// exchangedToken is a cached token of a backend.
+	exchangedToken struct {
+		token     string
+		expiresAt time.Time
+	}
+
+	// tokenEndpointResponse is the successful response of a token endpoint as defined in RFC 6749 Section 5.1.
+	tokenEndpointResponse struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type,omitempty"`
+		ExpiresIn   int64  `json:"expires_in,omitempty"`
+	}
+
+	// tokenEndpointError is the error response of a token endpoint as defined in RFC 6749 Section 5.2.
+	tokenEndpointError struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+)
+
+func newTokenExchanger() *tokenExchanger {
+	return &tokenExchanger{
+		client: &http.Client{Timeout: tokenExchangeTimeout},
+		cache:  make(map[string]exchangedToken),
+		now:    time.Now,
+	}
+}
+
+// exchange returns the token of the given backend for the given token of the user, either from the cache or by
+// calling the token endpoint.
+func (t *tokenExchanger) exchange(ctx context.Context, route filterapi.MCPRouteName, backend filterapi.MCPBackendName,
+	cfg *filterapi.MCPBackendOAuthTokenExchange, subjectToken string,
+) (string, error) {
+	key := tokenExchangeCacheKey(route, backend, cfg, subjectToken)
+	if token, ok := t.cached(key); ok {
+		return token, nil
+	}
+	token, err, _ := t.group.Do(key, func() (any, error) {
+		if token, ok := t.cached(key); ok {
+			return token, nil
+		}
+		// The exchange is shared by all the concurrent requests, so it must not be canceled with the first one.
+		resp, err := t.requestToken(context.WithoutCancel(ctx), cfg, subjectToken)
+		if err != nil {
+			return "", err
+		}
+		if resp.ExpiresIn > 0 {
+			now := t.now()
+			if expiresAt := now.Add(time.Duration(resp.ExpiresIn)*time.Second - tokenExchangeExpirySkew); expiresAt.After(now) {
+				t.store(key, exchangedToken{token: resp.AccessToken, expiresAt: expiresAt})
+			}
+		}
+		return resp.AccessToken, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
+// cached returns the cached token for the given key if it has not expired.
+func (t *tokenExchanger) cached(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cached, ok := t.cache[key]
+	if !ok || !t.now().Before(cached.expiresAt) {
+		return "", false
+	}
+	return cached.token, true
+}
+
+// store caches the given token, and evicts the expired ones.
+func (t *tokenExchanger) store(key string, token exchangedToken) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	for k, v := range t.cache {
+		if !now.Before(v.expiresAt) {
+			delete(t.cache, k)
+		}
+	}
+	t.cache[key] = token
+}
+
+// requestToken calls the token endpoint to exchange the given token of the user.
+func (t *tokenExchanger) requestToken(ctx context.Context, cfg *filterapi.MCPBackendOAuthTokenExchange, subjectToken string) (*tokenEndpointResponse, error) {
+	form := url.Values{}
+	onBehalfOf := cfg.GrantType == filterapi.MCPOAuthGrantTypeOnBehalfOf
+	if onBehalfOf {
+		form.Set("grant_type", grantTypeJWTBearer)
+		form.Set("assertion", subjectToken)
+		form.Set("requested_token_use", "on_behalf_of")
+		// The on-behalf-of flow expects the client credentials in the request body.
+		form.Set("client_id", cfg.ClientID)
+		form.Set("client_secret", cfg.ClientSecret)
+	} else {
+		form.Set("grant_type", grantTypeTokenExchange)
+		form.Set("subject_token", subjectToken)
+		form.Set("subject_token_type", cmp.Or(cfg.SubjectTokenType, tokenTypeAccessToken))
+		if cfg.RequestedTokenType != "" {
+			form.Set("requested_token_type", cfg.RequestedTokenType)
+		}
+		if cfg.Audience != "" {
+			form.Set("audience", cfg.Audience)
+		}
+		if cfg.Resource != "" {
+			form.Set("resource", cfg.Resource)
+		}
+	}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token exchange request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !onBehalfOf && cfg.ClientID != "" {
+		// RFC 6749 Section 2.3.1 requires the client credentials to be form-encoded before the basic authentication.
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, &tokenExchangeError{statusCode: http.StatusServiceUnavailable, msg: fmt.Sprintf("token exchange failed: %v", err)}
+	}
+	defer func() {
+		ensureHTTPConnectionReused(resp)
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &tokenExchangeError{statusCode: http.StatusServiceUnavailable, msg: fmt.Sprintf("token exchange failed to read response: %v", err)}
+	}
+
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, &tokenExchangeError{statusCode: http.StatusServiceUnavailable, msg: fmt.Sprintf("token exchange failed with status code %d", resp.StatusCode)}
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		// Errors other than server errors mean that the token or the configuration is rejected, so retrying won't help.
+		var tokenErr tokenEndpointError
+		if err = json.Unmarshal(body, &tokenErr); err != nil || tokenErr.Error == "" {
+			return nil, &tokenExchangeError{statusCode: http.StatusForbidden, msg: fmt.Sprintf("token exchange rejected with status code %d", resp.StatusCode)}
+		}
+		msg := "token exchange rejected: " + tokenErr.Error
+		if tokenErr.ErrorDescription != "" {
+			msg += ": " + tokenErr.ErrorDescription
+		}
+		return nil, &tokenExchangeError{statusCode: http.StatusForbidden, msg: msg}
+	}
+
+	var tokenResp tokenEndpointResponse
+	if err = json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, &tokenExchangeError{statusCode: http.StatusServiceUnavailable, msg: fmt.Sprintf("token exchange returned an invalid response: %v", err)}
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, &tokenExchangeError{statusCode: http.StatusServiceUnavailable, msg: "token exchange returned no access token"}
+	}
+	return &tokenResp, nil
+}
+
+// tokenExchangeCacheKey returns the key of the exchanged token for the given token of the user and backend.
+// The configuration of the exchange is part of the key so that a configuration change doesn't reuse stale tokens.
+func tokenExchangeCacheKey(route filterapi.MCPRouteName, backend filterapi.MCPBackendName,
+	cfg *filterapi.MCPBackendOAuthTokenExchange, subjectToken string,
+) string {
+	h := sha256.New()
+	for _, s := range []string{route, backend, subjectToken} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	encoded, _ := json.Marshal(cfg)
+	_, _ = h.Write(encoded)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// setBackendToken sets the "Authorization" header of the given request to the backend with the token exchanged
+// for the token of the user, when the backend is configured with the OAuth token exchange.
+func (m *mcpRequestContext) setBackendToken(ctx context.Context, req *http.Request, route filterapi.MCPRouteName, backend filterapi.MCPBackend) error {
+	cfg := backend.OAuthTokenExchange
+	if cfg == nil {
+		return nil
+	}
+	subjectToken, err := bearerToken(m.requestHeaders.Get("Authorization"))
+	if err != nil {
+		return &tokenExchangeError{statusCode: http.StatusUnauthorized, msg: fmt.Sprintf("token exchange failed: %v", err)}
+	}
+	token, err := m.tokenExchanger.exchange(ctx, route, backend.Name, cfg, subjectToken)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
@@ -0,0 +1,259 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+)
+
+func TestTokenExchanger_exchange(t *testing.T) {
+	var calls atomic.Int32
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		clientID, clientSecret, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "gateway", clientID)
+		require.Equal(t, "s3cr3t%2F", clientSecret)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, grantTypeTokenExchange, r.PostForm.Get("grant_type"))
+		require.Equal(t, tokenTypeAccessToken, r.PostForm.Get("subject_token_type"))
+		require.Equal(t, "https://api.github.com", r.PostForm.Get("audience"))
+		require.Equal(t, "https://api.github.com/mcp", r.PostForm.Get("resource"))
+		require.Equal(t, "repo read:org", r.PostForm.Get("scope"))
+		require.Empty(t, r.PostForm.Get("requested_token_type"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"upstream-` + r.PostForm.Get("subject_token") + `","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer sts.Close()
+
+	cfg := &filterapi.MCPBackendOAuthTokenExchange{
+		TokenEndpoint: sts.URL,
+		Audience:      "https://api.github.com",
+		Resource:      "https://api.github.com/mcp",
+		Scopes:        []string{"repo", "read:org"},
+		ClientID:      "gateway",
+		ClientSecret:  "s3cr3t/",
+	}
+	now := time.Now()
+	te := newTokenExchanger()
+	te.now = func() time.Time { return now }
+
+	token, err := te.exchange(t.Context(), "route", "github", cfg, "user1")
+	require.NoError(t, err)
+	require.Equal(t, "upstream-user1", token)
+	require.Equal(t, int32(1), calls.Load())
+
+	// The token is cached per user token and backend.
+	token, err = te.exchange(t.Context(), "route", "github", cfg, "user1")
+	require.NoError(t, err)
+	require.Equal(t, "upstream-user1", token)
+	require.Equal(t, int32(1), calls.Load())
+
+	token, err = te.exchange(t.Context(), "route", "github", cfg, "user2")
+	require.NoError(t, err)
+	require.Equal(t, "upstream-user2", token)
+	require.Equal(t, int32(2), calls.Load())
+
+	_, err = te.exchange(t.Context(), "route", "jira", cfg, "user1")
+	require.NoError(t, err)
+	require.Equal(t, int32(3), calls.Load())
+
+	// The cached tokens are exchanged again when they are about to expire, and the expired ones are evicted.
+	now = now.Add(time.Hour - tokenExchangeExpirySkew)
+	_, err = te.exchange(t.Context(), "route", "github", cfg, "user1")
+	require.NoError(t, err)
+	require.Equal(t, int32(4), calls.Load())
+	require.Len(t, te.cache, 1)
+}
+
+func TestTokenExchanger_exchange_OnBehalfOf(t *testing.T) {
+	var calls atomic.Int32
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _, ok := r.BasicAuth()
+		require.False(t, ok)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, grantTypeJWTBearer, r.PostForm.Get("grant_type"))
+		require.Equal(t, "user-token", r.PostForm.Get("assertion"))
+		require.Equal(t, "on_behalf_of", r.PostForm.Get("requested_token_use"))
+		require.Equal(t, "gateway", r.PostForm.Get("client_id"))
+		require.Equal(t, "s3cr3t", r.PostForm.Get("client_secret"))
+		require.Equal(t, "api://jira/.default", r.PostForm.Get("scope"))
+		require.Empty(t, r.PostForm.Get("subject_token"))
+		// No expiration means the token is not cached.
+		_, _ = w.Write([]byte(`{"access_token":"upstream","token_type":"Bearer"}`))
+	}))
+	defer sts.Close()
+
+	cfg := &filterapi.MCPBackendOAuthTokenExchange{
+		TokenEndpoint: sts.URL,
+		GrantType:     filterapi.MCPOAuthGrantTypeOnBehalfOf,
+		Audience:      "ignored",
+		Scopes:        []string{"api://jira/.default"},
+		ClientID:      "gateway",
+		ClientSecret:  "s3cr3t",
+	}
+	te := newTokenExchanger()
+	for range 2 {
+		token, err := te.exchange(t.Context(), "route", "jira", cfg, "user-token")
+		require.NoError(t, err)
+		require.Equal(t, "upstream", token)
+	}
+	require.Equal(t, int32(2), calls.Load())
+	require.Empty(t, te.cache)
+}
+
+func TestTokenExchanger_exchange_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		status     int
+		body       string
+		expStatus  int
+		expMessage string
+	}{
+		{
+			name:       "server error",
+			status:     http.StatusBadGateway,
+			body:       `{"error":"temporarily_unavailable"}`,
+			expStatus:  http.StatusServiceUnavailable,
+			expMessage: "token exchange failed with status code 502",
+		},
+		{
+			name:       "rejected",
+			status:     http.StatusBadRequest,
+			body:       `{"error":"invalid_target","error_description":"unknown audience"}`,
+			expStatus:  http.StatusForbidden,
+			expMessage: "token exchange rejected: invalid_target: unknown audience",
+		},
+		{
+			name:       "rejected without error",
+			status:     http.StatusUnauthorized,
+			body:       `unauthorized`,
+			expStatus:  http.StatusForbidden,
+			expMessage: "token exchange rejected with status code 401",
+		},
+		{
+			name:       "invalid response",
+			status:     http.StatusOK,
+			body:       `not json`,
+			expStatus:  http.StatusServiceUnavailable,
+			expMessage: "token exchange returned an invalid response",
+		},
+		{
+			name:       "missing access token",
+			status:     http.StatusOK,
+			body:       `{"token_type":"Bearer"}`,
+			expStatus:  http.StatusServiceUnavailable,
+			expMessage: "token exchange returned no access token",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer sts.Close()
+
+			_, err := newTokenExchanger().exchange(t.Context(), "route", "backend",
+				&filterapi.MCPBackendOAuthTokenExchange{TokenEndpoint: sts.URL}, "user-token")
+			var exchangeErr *tokenExchangeError
+			require.ErrorAs(t, err, &exchangeErr)
+			require.Equal(t, tc.expStatus, exchangeErr.statusCode)
+			require.Contains(t, exchangeErr.Error(), tc.expMessage)
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		sts := httptest.NewServer(http.NotFoundHandler())
+		sts.Close()
+
+		_, err := newTokenExchanger().exchange(t.Context(), "route", "backend",
+			&filterapi.MCPBackendOAuthTokenExchange{TokenEndpoint: sts.URL}, "user-token")
+		var exchangeErr *tokenExchangeError
+		require.ErrorAs(t, err, &exchangeErr)
+		require.Equal(t, http.StatusServiceUnavailable, exchangeErr.statusCode)
+		require.Contains(t, exchangeErr.Error(), "token exchange failed")
+	})
+}
+
+func Test_tokenExchangeCacheKey(t *testing.T) {
+	cfg := &filterapi.MCPBackendOAuthTokenExchange{TokenEndpoint: "https://sts.example.com", Audience: "a"}
+	key := tokenExchangeCacheKey("route", "backend", cfg, "token")
+	require.Equal(t, key, tokenExchangeCacheKey("route", "backend", cfg, "token"))
+	require.NotEqual(t, key, tokenExchangeCacheKey("route", "backend", cfg, "other-token"))
+	require.NotEqual(t, key, tokenExchangeCacheKey("route", "other-backend", cfg, "token"))
+	require.NotEqual(t, key, tokenExchangeCacheKey("other-route", "backend", cfg, "token"))
+	require.NotEqual(t, key, tokenExchangeCacheKey("route", "backend",
+		&filterapi.MCPBackendOAuthTokenExchange{TokenEndpoint: "https://sts.example.com", Audience: "b"}, "token"))
+	// The separators prevent ambiguous concatenations.
+	require.NotEqual(t, tokenExchangeCacheKey("ab", "c", cfg, "token"), tokenExchangeCacheKey("a", "bc", cfg, "token"))
+}
+
+func TestInvokeJSONRPCRequest_TokenExchange(t *testing.T) {
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("subject_token") != "user-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"upstream-token","expires_in":3600}`))
+	}))
+	defer sts.Close()
+
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer upstream-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"result": "success"}`))
+	}))
+	defer backendServer.Close()
+
+	backend := filterapi.MCPBackend{
+		Name:               "backend1",
+		OAuthTokenExchange: &filterapi.MCPBackendOAuthTokenExchange{TokenEndpoint: sts.URL},
+	}
+
+	t.Run("exchanged", func(t *testing.T) {
+		m := newTestMCPProxy()
+		m.backendListenerAddr = backendServer.URL
+		m.requestHeaders = http.Header{"Authorization": []string{"Bearer user-token"}}
+		resp, err := m.invokeJSONRPCRequest(t.Context(), "test-route", backend, nil, &jsonrpc.Request{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("missing user token", func(t *testing.T) {
+		m := newTestMCPProxy()
+		m.backendListenerAddr = backendServer.URL
+		m.requestHeaders = http.Header{}
+		_, err := m.invokeJSONRPCRequest(t.Context(), "test-route", backend, nil, &jsonrpc.Request{}, nil)
+		var exchangeErr *tokenExchangeError
+		require.ErrorAs(t, err, &exchangeErr)
+		require.Equal(t, http.StatusUnauthorized, exchangeErr.statusCode)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		m := newTestMCPProxy()
+		m.backendListenerAddr = backendServer.URL
+		m.requestHeaders = http.Header{"Authorization": []string{"Bearer other-token"}}
+		_, err := m.invokeJSONRPCRequest(t.Context(), "test-route", backend, nil, &jsonrpc.Request{}, nil)
+		var exchangeErr *tokenExchangeError
+		require.ErrorAs(t, err, &exchangeErr)
+		require.Equal(t, http.StatusForbidden, exchangeErr.statusCode)
+		require.Equal(t, "token exchange rejected: invalid_grant", exchangeErr.Error())
+	})
+}
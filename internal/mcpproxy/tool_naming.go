@@ -0,0 +1,235 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+type (
+	// toolNaming maps the tools of a backend between their definitions on the MCP server and the ones exposed
+	// to the clients.
+	toolNaming struct {
+		// prefix is prepended to the tool names exposed to the clients. Empty means the prefix is disabled.
+		prefix string
+		// overrides are keyed by the tool names on the MCP server.
+		overrides map[string]*toolOverride
+		// renamed maps the renamed tool names, without the prefix, to the tool names on the MCP server.
+		renamed map[string]string
+	}
+
+	// toolOverride is a compiled [filterapi.MCPToolOverride].
+	toolOverride struct {
+		filterapi.MCPToolOverride
+		// fixedArguments are the decoded fixed arguments.
+		fixedArguments map[string]any
+	}
+)
+
+// newToolNaming compiles the tool naming of the given backend.
+func newToolNaming(backend filterapi.MCPBackend) (*toolNaming, error) {
+	n := &toolNaming{
+		prefix:    backend.Name + nameSeparator,
+		overrides: make(map[string]*toolOverride, len(backend.ToolOverrides)),
+		renamed:   make(map[string]string),
+	}
+	if p := backend.ToolNamePrefix; p != nil {
+		if p.Disabled {
+			n.prefix = ""
+		} else {
+			n.prefix = backend.Name + cmp.Or(p.Separator, nameSeparator)
+		}
+	}
+	for _, o := range backend.ToolOverrides {
+		compiled := &toolOverride{MCPToolOverride: o, fixedArguments: make(map[string]any, len(o.FixedArguments))}
+		for name, value := range o.FixedArguments {
+			var decoded any
+			if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+				return nil, fmt.Errorf("invalid fixed argument %s of tool %s: %w", name, o.Name, err)
+			}
+			compiled.fixedArguments[name] = decoded
+		}
+		n.overrides[o.Name] = compiled
+		if o.Rename != "" {
+			if other, ok := n.renamed[o.Rename]; ok {
+				return nil, fmt.Errorf("tools %s and %s are both renamed to %s", other, o.Name, o.Rename)
+			}
+			n.renamed[o.Rename] = o.Name
+		}
+	}
+	return n, nil
+}
+
+// downstream returns the name of the given tool exposed to the clients.
+func (n *toolNaming) downstream(tool string) string {
+	if o := n.overrides[tool]; o != nil && o.Rename != "" {
+		tool = o.Rename
+	}
+	return n.prefix + tool
+}
+
+// upstream returns the name of the tool on the MCP server for the given tool name exposed to the clients,
+// without the prefix.
+func (n *toolNaming) upstream(name string) (string, error) {
+	if tool, ok := n.renamed[name]; ok {
+		return tool, nil
+	}
+	if o := n.overrides[name]; o != nil && o.Rename != "" {
+		// The original name of a renamed tool is not exposed to the clients.
+		return "", fmt.Errorf("%w: %s", errInvalidToolName, name)
+	}
+	return name, nil
+}
+
+// sameTools returns true if the tools exposed to the clients are the same with both namings.
+func (n *toolNaming) sameTools(other *toolNaming) bool {
+	return n.prefix == other.prefix && maps.EqualFunc(n.overrides, other.overrides, func(a, b *toolOverride) bool {
+		return reflect.DeepEqual(a.MCPToolOverride, b.MCPToolOverride)
+	})
+}
+
+// toolNaming returns the tool naming of the given backend, which defaults to the backend name prefix.
+func (m *mcpProxyConfigRoute) toolNaming(backend filterapi.MCPBackendName) *toolNaming {
+	if m != nil {
+		if n := m.toolNames[backend]; n != nil {
+			return n
+		}
+	}
+	return &toolNaming{prefix: backend + nameSeparator}
+}
+
+// downstreamToolName returns the name exposed to the clients of the given tool of the given backend.
+func (m *mcpProxyConfigRoute) downstreamToolName(backend filterapi.MCPBackendName, tool string) string {
+	return m.toolNaming(backend).downstream(tool)
+}
+
+// upstreamToolName returns the backend and the name on the MCP server of the given tool name exposed to the clients.
+//
+// The tool names are matched against the prefixes of the backends, preferring the longest matching prefix, and
+// the names that don't match any prefix belong to the backend with the prefix disabled, if any.
+func (m *mcpProxyConfigRoute) upstreamToolName(name string) (backendName, tool string, err error) {
+	if m == nil || len(m.toolNames) == 0 {
+		return upstreamResourceName(name)
+	}
+	var matched, unprefixed filterapi.MCPBackendName
+	for b, n := range m.toolNames {
+		switch {
+		case n.prefix == "":
+			unprefixed = b
+		case strings.HasPrefix(name, n.prefix) && (matched == "" || len(n.prefix) > len(m.toolNames[matched].prefix)):
+			matched = b
+		}
+	}
+	switch {
+	case matched != "":
+		n := m.toolNames[matched]
+		tool, err = n.upstream(strings.TrimPrefix(name, n.prefix))
+		return matched, tool, err
+	case unprefixed != "":
+		tool, err = m.toolNames[unprefixed].upstream(name)
+		return unprefixed, tool, err
+	default:
+		// Fall back to the default naming so that the unknown backends are reported as such.
+		return upstreamResourceName(name)
+	}
+}
+
+// applyToolOverride applies the override of the given tool of the given backend, if any, to its definition
+// exposed to the clients. The name of the tool is not changed.
+func (m *mcpProxyConfigRoute) applyToolOverride(backend filterapi.MCPBackendName, tool *mcp.Tool) {
+	o := m.toolOverride(backend, tool.Name)
+	if o == nil {
+		return
+	}
+	if o.Description != "" {
+		tool.Description = o.Description
+	}
+	if o.AppendDescription != "" {
+		if tool.Description == "" {
+			tool.Description = o.AppendDescription
+		} else {
+			tool.Description += "\n" + o.AppendDescription
+		}
+	}
+	if len(o.fixedArguments) > 0 {
+		tool.InputSchema = hideSchemaProperties(tool.InputSchema, o.fixedArguments)
+	}
+}
+
+// injectFixedArguments sets the fixed arguments of the given tool of the given backend, if any, in the arguments
+// of the tool call, replacing the values set by the client.
+func (m *mcpProxyConfigRoute) injectFixedArguments(backend filterapi.MCPBackendName, tool string, p *mcp.CallToolParams) error {
+	o := m.toolOverride(backend, tool)
+	if o == nil || len(o.fixedArguments) == 0 {
+		return nil
+	}
+	arguments, err := toArgumentsMap(p.Arguments)
+	if err != nil {
+		return err
+	}
+	maps.Copy(arguments, o.fixedArguments)
+	p.Arguments = arguments
+	return nil
+}
+
+func (m *mcpProxyConfigRoute) toolOverride(backend filterapi.MCPBackendName, tool string) *toolOverride {
+	return m.toolNaming(backend).overrides[tool]
+}
+
+// toArgumentsMap converts the given tool call arguments to a map of generic JSON values.
+func toArgumentsMap(arguments any) (map[string]any, error) {
+	switch a := arguments.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return a, nil
+	}
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+	result := map[string]any{}
+	if err = json.Unmarshal(encoded, &result); err != nil {
+		return nil, fmt.Errorf("arguments must be an object: %w", err)
+	}
+	return result, nil
+}
+
+// hideSchemaProperties returns a copy of the given object schema without the given properties.
+// Schemas that are not objects are returned as is.
+func hideSchemaProperties(schema any, hidden map[string]any) any {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return schema
+	}
+	var s map[string]any
+	if err = json.Unmarshal(encoded, &s); err != nil {
+		return schema
+	}
+	if properties, ok := s["properties"].(map[string]any); ok {
+		for name := range hidden {
+			delete(properties, name)
+		}
+	}
+	if required, ok := s["required"].([]any); ok {
+		s["required"] = slices.DeleteFunc(required, func(r any) bool {
+			name, _ := r.(string)
+			_, ok := hidden[name]
+			return ok
+		})
+	}
+	return s
+}
@@ -0,0 +1,236 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+func newTestToolNamingRoute(t *testing.T, backends ...filterapi.MCPBackend) *mcpProxyConfigRoute {
+	r := &mcpProxyConfigRoute{
+		backends:  make(map[filterapi.MCPBackendName]filterapi.MCPBackend),
+		toolNames: make(map[filterapi.MCPBackendName]*toolNaming),
+	}
+	for _, b := range backends {
+		n, err := newToolNaming(b)
+		require.NoError(t, err)
+		r.backends[b.Name] = b
+		r.toolNames[b.Name] = n
+	}
+	return r
+}
+
+func TestNewToolNaming_Errors(t *testing.T) {
+	_, err := newToolNaming(filterapi.MCPBackend{
+		Name: "backend",
+		ToolOverrides: []filterapi.MCPToolOverride{
+			{Name: "tool", FixedArguments: map[string]string{"arg": "not json"}},
+		},
+	})
+	require.ErrorContains(t, err, "invalid fixed argument arg of tool tool")
+
+	_, err = newToolNaming(filterapi.MCPBackend{
+		Name: "backend",
+		ToolOverrides: []filterapi.MCPToolOverride{
+			{Name: "a", Rename: "c"},
+			{Name: "b", Rename: "c"},
+		},
+	})
+	require.ErrorContains(t, err, "tools a and b are both renamed to c")
+}
+
+func TestMCPProxyConfigRoute_ToolNames(t *testing.T) {
+	route := newTestToolNamingRoute(t,
+		filterapi.MCPBackend{Name: "default"},
+		filterapi.MCPBackend{
+			Name:           "github",
+			ToolNamePrefix: &filterapi.MCPToolNamePrefix{Separator: "_"},
+			ToolOverrides:  []filterapi.MCPToolOverride{{Name: "create_issue", Rename: "open_ticket"}},
+		},
+		// The longest matching prefix wins, so "github_enterprise_*" is not routed to "github".
+		filterapi.MCPBackend{Name: "github_enterprise", ToolNamePrefix: &filterapi.MCPToolNamePrefix{Separator: "_"}},
+		filterapi.MCPBackend{
+			Name:           "jira",
+			ToolNamePrefix: &filterapi.MCPToolNamePrefix{Disabled: true},
+			ToolOverrides:  []filterapi.MCPToolOverride{{Name: "search", Rename: "jira_search"}},
+		},
+	)
+
+	for _, tc := range []struct {
+		backend    filterapi.MCPBackendName
+		tool       string
+		downstream string
+	}{
+		{backend: "default", tool: "echo", downstream: "default__echo"},
+		{backend: "github", tool: "list_issues", downstream: "github_list_issues"},
+		{backend: "github", tool: "create_issue", downstream: "github_open_ticket"},
+		{backend: "github_enterprise", tool: "list_issues", downstream: "github_enterprise_list_issues"},
+		{backend: "jira", tool: "create_ticket", downstream: "create_ticket"},
+		{backend: "jira", tool: "search", downstream: "jira_search"},
+	} {
+		t.Run(tc.downstream, func(t *testing.T) {
+			require.Equal(t, tc.downstream, route.downstreamToolName(tc.backend, tc.tool))
+			backend, tool, err := route.upstreamToolName(tc.downstream)
+			require.NoError(t, err)
+			require.Equal(t, tc.backend, backend)
+			require.Equal(t, tc.tool, tool)
+		})
+	}
+
+	// The original names of the renamed tools are not exposed.
+	_, _, err := route.upstreamToolName("github_create_issue")
+	require.ErrorIs(t, err, errInvalidToolName)
+	_, _, err = route.upstreamToolName("search")
+	require.ErrorIs(t, err, errInvalidToolName)
+
+	// Without a backend with the prefix disabled, the unknown names fall back to the default naming.
+	route = newTestToolNamingRoute(t, filterapi.MCPBackend{Name: "default"})
+	backend, tool, err := route.upstreamToolName("unknown__tool")
+	require.NoError(t, err)
+	require.Equal(t, "unknown", backend)
+	require.Equal(t, "tool", tool)
+	_, _, err = route.upstreamToolName("tool")
+	require.Error(t, err)
+
+	// A nil route uses the default naming.
+	require.Equal(t, "backend__tool", (*mcpProxyConfigRoute)(nil).downstreamToolName("backend", "tool"))
+}
+
+func TestMCPProxyConfigRoute_applyToolOverride(t *testing.T) {
+	route := newTestToolNamingRoute(t, filterapi.MCPBackend{
+		Name: "github",
+		ToolOverrides: []filterapi.MCPToolOverride{
+			{
+				Name:              "create_issue",
+				AppendDescription: "Only for the envoyproxy organization.",
+				FixedArguments:    map[string]string{"owner": `"envoyproxy"`},
+			},
+			{Name: "search", Description: "Search the code."},
+			{Name: "empty", AppendDescription: "Appended."},
+		},
+	})
+
+	tool := &mcp.Tool{
+		Name:        "create_issue",
+		Description: "Create an issue.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"owner": map[string]any{"type": "string"},
+				"title": map[string]any{"type": "string"},
+			},
+			"required": []any{"owner", "title"},
+		},
+	}
+	route.applyToolOverride("github", tool)
+	require.Equal(t, "Create an issue.\nOnly for the envoyproxy organization.", tool.Description)
+	require.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"title": map[string]any{"type": "string"}},
+		"required":   []any{"title"},
+	}, tool.InputSchema)
+	// The name is not changed.
+	require.Equal(t, "create_issue", tool.Name)
+
+	tool = &mcp.Tool{Name: "search", Description: "Search."}
+	route.applyToolOverride("github", tool)
+	require.Equal(t, "Search the code.", tool.Description)
+
+	tool = &mcp.Tool{Name: "empty"}
+	route.applyToolOverride("github", tool)
+	require.Equal(t, "Appended.", tool.Description)
+
+	tool = &mcp.Tool{Name: "other", Description: "Other."}
+	route.applyToolOverride("github", tool)
+	require.Equal(t, "Other.", tool.Description)
+}
+
+func TestMCPProxyConfigRoute_injectFixedArguments(t *testing.T) {
+	route := newTestToolNamingRoute(t, filterapi.MCPBackend{
+		Name: "github",
+		ToolOverrides: []filterapi.MCPToolOverride{
+			{Name: "create_issue", FixedArguments: map[string]string{"owner": `"envoyproxy"`, "labels": `["bug"]`}},
+		},
+	})
+
+	p := &mcp.CallToolParams{Arguments: map[string]any{"owner": "attacker", "title": "Bug"}}
+	require.NoError(t, route.injectFixedArguments("github", "create_issue", p))
+	require.Equal(t, map[string]any{"owner": "envoyproxy", "labels": []any{"bug"}, "title": "Bug"}, p.Arguments)
+
+	p = &mcp.CallToolParams{}
+	require.NoError(t, route.injectFixedArguments("github", "create_issue", p))
+	require.Equal(t, map[string]any{"owner": "envoyproxy", "labels": []any{"bug"}}, p.Arguments)
+
+	p = &mcp.CallToolParams{Arguments: []any{"not", "an", "object"}}
+	require.Error(t, route.injectFixedArguments("github", "create_issue", p))
+
+	p = &mcp.CallToolParams{Arguments: []any{"untouched"}}
+	require.NoError(t, route.injectFixedArguments("github", "other", p))
+	require.Equal(t, []any{"untouched"}, p.Arguments)
+}
+
+func TestToolOverrides_ListAndCall(t *testing.T) {
+	var received mcp.CallToolParams
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		msg, _ := jsonrpc.DecodeMessage(body)
+		req := msg.(*jsonrpc.Request)
+		require.NoError(t, json.Unmarshal(req.Params, &received))
+		respBody, _ := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: req.ID, Result: []byte(`{"content":[]}`)})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(respBody)
+	}))
+	t.Cleanup(backendServer.Close)
+
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = backendServer.URL
+	backend := filterapi.MCPBackend{
+		Name:           "backend1",
+		ToolNamePrefix: &filterapi.MCPToolNamePrefix{Disabled: true},
+		ToolOverrides: []filterapi.MCPToolOverride{
+			{Name: "test-tool", Rename: "renamed", FixedArguments: map[string]string{"owner": `"envoyproxy"`}},
+		},
+	}
+	naming, err := newToolNaming(backend)
+	require.NoError(t, err)
+	route := proxy.routes["test-route"]
+	route.backends["backend1"] = backend
+	route.toolNames = map[filterapi.MCPBackendName]*toolNaming{"backend1": naming}
+	s := &session{
+		reqCtx:             proxy,
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{"backend1": {sessionID: "test-session"}},
+		route:              "test-route",
+	}
+
+	// The tool selector keeps referring to the tool by its original name.
+	result := proxy.mergeToolsList(t.Context(), s, []broadCastResponse[mcp.ListToolsResult]{{
+		backendName: "backend1",
+		res: mcp.ListToolsResult{Tools: []*mcp.Tool{
+			{Name: "test-tool", InputSchema: map[string]any{"type": "object", "properties": map[string]any{"owner": map[string]any{}}}},
+			{Name: "other-tool"},
+		}},
+	}})
+	require.Len(t, result.Tools, 1)
+	require.Equal(t, "renamed", result.Tools[0].Name)
+	require.Equal(t, map[string]any{"type": "object", "properties": map[string]any{}}, result.Tools[0].InputSchema)
+
+	params := &mcp.CallToolParams{Name: "renamed", Arguments: map[string]any{"owner": "attacker"}}
+	req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"}
+	_, err = proxy.handleToolCallRequest(t.Context(), s, httptest.NewRecorder(), req, params, nil, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	require.NoError(t, err)
+	require.Equal(t, "test-tool", received.Name)
+	require.Equal(t, map[string]any{"owner": "envoyproxy"}, received.Arguments)
+}
@@ -0,0 +1,182 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// toolDefinitionDriftMetaKey is the key of the "_meta" field set on the tools flagged by the tool pinning.
+	toolDefinitionDriftMetaKey = "aigateway.envoyproxy.io/toolDefinitionDrift"
+	// toolListMaxPages is the maximum number of pages of tools listed from a backend to verify the definition
+	// of a called tool that has not been listed in the session.
+	toolListMaxPages = 10
+
+	envoyAIGatewayToolListRequestIDPrefix = "aigw-tool-list"
+)
+
+// toolFingerprintFields are the fields of a tool definition covered by the fingerprint.
+type toolFingerprintFields struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema any    `json:"inputSchema"`
+}
+
+// ToolFingerprint returns the fingerprint of the given tool definition, which is the hex encoded SHA-256 of
+// its name, description and input schema. The other fields, such as annotations, don't change the fingerprint.
+func ToolFingerprint(tool *mcp.Tool) (string, error) {
+	encoded, err := json.Marshal(toolFingerprintFields{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: tool.InputSchema,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool %s: %w", tool.Name, err)
+	}
+	// The input schema may be of any type, so it is decoded back into generic values to be marshaled with
+	// sorted keys, so that equivalent schemas have the same fingerprint regardless of the order of their keys.
+	var generic any
+	if err = json.Unmarshal(encoded, &generic); err != nil {
+		return "", fmt.Errorf("failed to unmarshal tool %s: %w", tool.Name, err)
+	}
+	canonical, err := json.MarshalSortedKeys(generic)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool %s: %w", tool.Name, err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pinTool checks the given tool definition listed by a backend in the session against its approved fingerprint,
+// and returns false if the tool must be removed from the list.
+func (m *mcpRequestContext) pinTool(ctx context.Context, s *session, backend filterapi.MCPBackendName,
+	pinning *filterapi.MCPToolPinning, tool *mcp.Tool,
+) bool {
+	approved := pinning.Fingerprints[tool.Name]
+	fingerprint, err := ToolFingerprint(tool)
+	if err != nil {
+		// A tool that cannot be fingerprinted cannot be approved either.
+		m.l.Warn("failed to fingerprint tool", slog.String("tool", tool.Name), slog.String("error", err.Error()))
+	} else if pinning.Action == filterapi.MCPToolPinningActionBlock {
+		// The calls are checked against the definition listed in the session, see isToolBlocked.
+		m.toolFingerprints.store(s.clientGatewaySessionID(), backend, tool.Name, fingerprint)
+	}
+	if err == nil && approved == fingerprint {
+		return true
+	}
+
+	m.l.Warn("tool definition drift detected",
+		slog.String("route", s.route),
+		slog.String("backend", backend),
+		slog.String("tool", tool.Name),
+		slog.String("approved_fingerprint", approved),
+		slog.String("fingerprint", fingerprint),
+		slog.String("action", string(pinning.Action)),
+	)
+	m.metrics.WithBackend(backend).RecordToolDefinitionDrift(ctx, tool.Name, string(pinning.Action))
+
+	switch pinning.Action {
+	case filterapi.MCPToolPinningActionFlag:
+		if tool.Meta == nil {
+			tool.Meta = mcp.Meta{}
+		}
+		tool.Meta[toolDefinitionDriftMetaKey] = map[string]string{
+			"approvedFingerprint": approved,
+			"fingerprint":         fingerprint,
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// isToolBlocked returns true if calls to the given tool are blocked because its definition listed in the session
+// doesn't match the approved fingerprint. The approved fingerprints are the ones of the current configuration, so
+// that a change of the baseline applies to the calls right away.
+//
+// When the tool has not been listed in the session by this replica, the tools of the backend are listed first, so
+// that the calls are never let through without a verified definition.
+func (m *mcpRequestContext) isToolBlocked(ctx context.Context, s *session, backend filterapi.MCPBackendName, tool string) bool {
+	pinning := m.routes[s.route].backends[backend].ToolPinning
+	if pinning == nil || pinning.Action != filterapi.MCPToolPinningActionBlock {
+		return false
+	}
+	approved, ok := pinning.Fingerprints[tool]
+	if !ok {
+		// Tools that are not approved at all are always blocked.
+		return true
+	}
+	fingerprint := m.toolFingerprints.load(s.clientGatewaySessionID(), backend, tool)
+	if fingerprint == "" {
+		m.listBackendTools(ctx, s, backend, tool)
+		fingerprint = m.toolFingerprints.load(s.clientGatewaySessionID(), backend, tool)
+	}
+	return fingerprint != approved
+}
+
+// listBackendTools lists the tools of the backend in the session, and caches the fingerprints and the schemas of
+// their definitions as the tools/list requests of the client do. The pages are followed until the given tool is
+// found, up to toolListMaxPages.
+func (m *mcpRequestContext) listBackendTools(ctx context.Context, s *session, backend filterapi.MCPBackendName, tool string) {
+	backendCfg := m.routes[s.route].backends[backend]
+	var cursor string
+	for range toolListMaxPages {
+		id, _ := jsonrpc.MakeID(fmt.Sprintf("%s-%s", envoyAIGatewayToolListRequestIDPrefix, uuid.NewString()))
+		params := &mcp.ListToolsParams{Cursor: cursor}
+		encoded, _ := json.Marshal(params)
+		events := s.sendToBackendsFiltered(ctx, http.MethodPost, &jsonrpc.Request{ID: id, Method: "tools/list", Params: encoded},
+			params, nil, func(cse *compositeSessionEntry) bool { return cse.backendName == backend })
+		var result *mcp.ListToolsResult
+		for event := range events {
+			l := len(event.messages)
+			if l == 0 {
+				continue
+			}
+			resp, ok := event.messages[l-1].(*jsonrpc.Response)
+			if !ok || resp.ID != id || resp.Error != nil || resp.Result == nil {
+				continue
+			}
+			result = &mcp.ListToolsResult{}
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				m.l.Error("failed to unmarshal the tools of backend",
+					slog.String("backend", backend), slog.String("error", err.Error()))
+				return
+			}
+		}
+		if result == nil {
+			return
+		}
+		found := false
+		for _, t := range result.Tools {
+			found = found || t.Name == tool
+			if pinning := backendCfg.ToolPinning; pinning != nil && pinning.Action == filterapi.MCPToolPinningActionBlock {
+				if fingerprint, err := ToolFingerprint(t); err == nil {
+					m.toolFingerprints.store(s.clientGatewaySessionID(), backend, t.Name, fingerprint)
+				}
+			}
+			if validation := backendCfg.SchemaValidation; validation != nil {
+				m.cacheToolSchemas(s, backend, validation, t)
+			}
+		}
+		if found || result.NextCursor == "" {
+			return
+		}
+		cursor = result.NextCursor
+	}
+}
@@ -0,0 +1,556 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	// searchToolsToolName and callToolToolName are the names of the meta tools listed instead of the tools of the
+	// backends when the tool search is enabled.
+	searchToolsToolName = "search_tools"
+	callToolToolName    = "call_tool"
+
+	// defaultToolSearchMaxResults is the maximum number of tools returned by a search when the route doesn't
+	// configure it.
+	defaultToolSearchMaxResults = 10
+	// toolSearchMaxListPages is the maximum number of pages of tools listed from each backend for a search.
+	toolSearchMaxListPages = 10
+	// toolSearchEmbeddingsTimeout is the time to wait for the embeddings before ranking by keywords only.
+	toolSearchEmbeddingsTimeout = 10 * time.Second
+	// maxToolSearchEmbeddingsResponseSize is the maximum size of the responses of the embeddings endpoints.
+	maxToolSearchEmbeddingsResponseSize = 32 * 1024 * 1024
+
+	// bm25K1 and bm25B are the usual parameters of the BM25 ranking function.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// toolSearchNameWeight is the number of times the terms of the tool names are counted, as the names are more
+	// relevant than the descriptions.
+	toolSearchNameWeight = 3
+	// rrfK is the constant of the reciprocal rank fusion of the keyword and the embeddings rankings.
+	rrfK = 60
+
+	envoyAIGatewayToolSearchRequestIDPrefix = "aigw-tool-search"
+)
+
+type (
+	// toolSearch is the compiled tool search configuration of a route.
+	toolSearch struct {
+		maxResults int
+		embeddings *filterapi.MCPToolSearchEmbeddings
+	}
+
+	// searchToolsArguments are the arguments of the search_tools meta tool.
+	searchToolsArguments struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit,omitempty"`
+	}
+
+	// searchToolsResult is the structured content of the results of the search_tools meta tool.
+	searchToolsResult struct {
+		Tools []*mcp.Tool `json:"tools"`
+	}
+
+	// embeddingsRequest and embeddingsResponse are the bodies of the OpenAI-compatible embeddings API.
+	embeddingsRequest struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	embeddingsResponse struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+)
+
+// newToolSearch compiles the tool search configuration of a route. This returns nil if the tool search is disabled.
+func newToolSearch(ts *filterapi.MCPToolSearch) *toolSearch {
+	if ts == nil {
+		return nil
+	}
+	return &toolSearch{
+		maxResults: cmp.Or(ts.MaxResults, defaultToolSearchMaxResults),
+		embeddings: ts.Embeddings,
+	}
+}
+
+// toolSearchMetaTools returns the meta tools listed instead of the tools of the backends.
+func toolSearchMetaTools() []*mcp.Tool {
+	return []*mcp.Tool{
+		{
+			Name: searchToolsToolName,
+			Description: "Search the available tools. Returns the definitions of the tools best matching the query, " +
+				"which can then be called with the call_tool tool.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Keywords or a description of the task to find the tools for.",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"minimum":     1,
+						"description": "Maximum number of tools to return.",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		{
+			Name:        callToolToolName,
+			Description: "Call a tool returned by the search_tools tool.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{
+						"type":        "string",
+						"description": "Name of the tool to call.",
+					},
+					"arguments": map[string]any{
+						"type":        "object",
+						"description": "Arguments of the tool, matching its input schema.",
+					},
+				},
+				"required": []string{"name"},
+			},
+		},
+	}
+}
+
+// isToolSearchMetaTool returns true if the given tool name is one of the meta tools of the tool search.
+func isToolSearchMetaTool(name string) bool {
+	return name == searchToolsToolName || name == callToolToolName
+}
+
+// callToolTarget returns the params of the call to the tool requested with the call_tool meta tool.
+func callToolTarget(p *mcp.CallToolParams) (*mcp.CallToolParams, error) {
+	arguments, err := toArgumentsMap(p.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := arguments["name"].(string)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if isToolSearchMetaTool(name) {
+		return nil, fmt.Errorf("%s cannot be called with %s", name, callToolToolName)
+	}
+	target := &mcp.CallToolParams{Meta: p.Meta, Name: name}
+	switch args := arguments["arguments"].(type) {
+	case nil:
+	case map[string]any:
+		target.Arguments = args
+	default:
+		return nil, errors.New("arguments must be an object")
+	}
+	return target, nil
+}
+
+// handleToolSearchListRequest answers the "tools/list" requests of the routes with the tool search enabled with the
+// meta tools.
+func (m *mcpRequestContext) handleToolSearchListRequest(w http.ResponseWriter, s *session, req *jsonrpc.Request) error {
+	return writeJSONRPCResult(w, s, req, &mcp.ListToolsResult{Tools: toolSearchMetaTools()})
+}
+
+// handleSearchToolsRequest handles the calls to the search_tools meta tool. The tools of all the backends are listed
+// as for a "tools/list" request, so that the client only finds the tools it could list and call, and the best
+// matches are returned.
+func (m *mcpRequestContext) handleSearchToolsRequest(ctx context.Context, s *session, w http.ResponseWriter, req *jsonrpc.Request, route *mcpProxyConfigRoute, p *mcp.CallToolParams) error {
+	var args searchToolsArguments
+	encoded, err := json.Marshal(p.Arguments)
+	if err == nil {
+		err = json.Unmarshal(encoded, &args)
+	}
+	if err == nil && strings.TrimSpace(args.Query) == "" {
+		err = errors.New("query is required")
+	}
+	if err != nil {
+		return onJSONRPCErrorResponse(w, req, jsonrpc.CodeInvalidParams,
+			fmt.Sprintf("invalid arguments for tool %s: %v", searchToolsToolName, err))
+	}
+	limit := route.toolSearch.maxResults
+	if args.Limit > 0 && args.Limit < limit {
+		limit = args.Limit
+	}
+
+	tools := m.listToolsForSearch(ctx, s, route)
+	ranked := m.rankTools(ctx, route.toolSearch, args.Query, tools)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	result := searchToolsResult{Tools: ranked}
+	text, _ := json.Marshal(result)
+	return writeJSONRPCResult(w, s, req, &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(text)}},
+		StructuredContent: result,
+	})
+}
+
+// listToolsForSearch lists the tools of all the backends of the session, following the pages of each backend up to
+// toolSearchMaxListPages. The tools are filtered and renamed as in the responses to the "tools/list" requests.
+func (m *mcpRequestContext) listToolsForSearch(ctx context.Context, s *session, route *mcpProxyConfigRoute) []*mcp.Tool {
+	var (
+		responses []broadCastResponse[mcp.ListToolsResult]
+		cursors   map[filterapi.MCPBackendName]string
+	)
+	for page := range toolSearchMaxListPages {
+		if page > 0 && len(cursors) == 0 {
+			break
+		}
+		id, _ := jsonrpc.MakeID(fmt.Sprintf("%s-%s", envoyAIGatewayToolSearchRequestIDPrefix, uuid.NewString()))
+		events := s.sendPerBackendRequests(ctx, http.MethodPost, func(backendName filterapi.MCPBackendName) *jsonrpc.Request {
+			params, _ := json.Marshal(&mcp.ListToolsParams{Cursor: cursors[backendName]})
+			return &jsonrpc.Request{ID: id, Method: "tools/list", Params: params}
+		}, &mcp.ListToolsParams{}, nil, func(cse *compositeSessionEntry) bool {
+			if _, ok := cursors[cse.backendName]; page > 0 && !ok {
+				return false
+			}
+			if cse.capabilities == nil || cse.capabilities.Tools == nil {
+				return false
+			}
+			return route.authorization == nil || m.authorizeFanOut(route.authorization, "tools/list", cse.backendName, &mcp.ListToolsParams{})
+		})
+		next := make(map[filterapi.MCPBackendName]string)
+		for event := range events {
+			l := len(event.messages)
+			if l == 0 {
+				continue
+			}
+			resp, ok := event.messages[l-1].(*jsonrpc.Response)
+			if !ok || resp.ID != id || resp.Error != nil || resp.Result == nil {
+				continue
+			}
+			var result mcp.ListToolsResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				m.l.Error("failed to unmarshal the tools of backend for the tool search",
+					slog.String("backend", event.backend), slog.String("error", err.Error()))
+				continue
+			}
+			if result.NextCursor != "" {
+				next[event.backend] = result.NextCursor
+				result.NextCursor = ""
+			}
+			responses = append(responses, broadCastResponse[mcp.ListToolsResult]{backendName: event.backend, res: result})
+		}
+		cursors = next
+	}
+	// The responses are sorted so that the ties of the ranking are deterministic.
+	slices.SortStableFunc(responses, func(a, b broadCastResponse[mcp.ListToolsResult]) int {
+		return strings.Compare(a.backendName, b.backendName)
+	})
+	return m.mergeToolsList(ctx, s, responses).Tools
+}
+
+// rankTools returns the tools matching the query, best matches first. The tools are ranked by the BM25 relevance of
+// their terms to the terms of the query, fused with their semantic similarity to the query when embeddings are
+// configured. The embeddings failures are logged, and the tools are then ranked by keywords only.
+func (m *mcpRequestContext) rankTools(ctx context.Context, ts *toolSearch, query string, tools []*mcp.Tool) []*mcp.Tool {
+	scores := bm25Scores(tokenize(query), toolSearchDocuments(tools))
+	keywordRanking := rankByScore(scores, func(score float64) bool { return score > 0 })
+	if ts.embeddings == nil || len(tools) == 0 {
+		return pick(tools, keywordRanking)
+	}
+
+	texts := make([]string, len(tools))
+	for i, tool := range tools {
+		texts[i] = toolSearchEmbeddingsText(tool)
+	}
+	ctx, cancel := context.WithTimeout(ctx, toolSearchEmbeddingsTimeout)
+	defer cancel()
+	toolEmbeddings, err := m.toolSearchEmbeddings(ctx, ts.embeddings, texts, true)
+	var queryEmbeddings [][]float64
+	if err == nil {
+		queryEmbeddings, err = m.toolSearchEmbeddings(ctx, ts.embeddings, []string{query}, false)
+	}
+	if err != nil {
+		m.l.Warn("failed to compute the embeddings of the tool search, ranking by keywords only", slog.String("error", err.Error()))
+		return pick(tools, keywordRanking)
+	}
+
+	similarities := make([]float64, len(tools))
+	for i := range tools {
+		similarities[i] = cosineSimilarity(queryEmbeddings[0], toolEmbeddings[i])
+	}
+	fused := make([]float64, len(tools))
+	for rank, i := range keywordRanking {
+		fused[i] += 1 / float64(rrfK+rank+1)
+	}
+	for rank, i := range rankByScore(similarities, func(float64) bool { return true }) {
+		fused[i] += 1 / float64(rrfK+rank+1)
+	}
+	return pick(tools, rankByScore(fused, func(score float64) bool { return score > 0 }))
+}
+
+// rankByScore returns the indexes of the scores kept by the given function, highest scores first. The ties keep
+// their order.
+func rankByScore(scores []float64, keep func(float64) bool) []int {
+	var ranking []int
+	for i, score := range scores {
+		if keep(score) {
+			ranking = append(ranking, i)
+		}
+	}
+	slices.SortStableFunc(ranking, func(a, b int) int { return cmp.Compare(scores[b], scores[a]) })
+	return ranking
+}
+
+// pick returns the tools at the given indexes. The result is never nil so that it is encoded as an empty list.
+func pick(tools []*mcp.Tool, indexes []int) []*mcp.Tool {
+	ret := make([]*mcp.Tool, 0, len(indexes))
+	for _, i := range indexes {
+		ret = append(ret, tools[i])
+	}
+	return ret
+}
+
+// toolSearchDocuments returns the terms of each tool searched by keywords: the terms of its name, weighted with
+// toolSearchNameWeight, title and description, and the names and descriptions of the properties of its input.
+func toolSearchDocuments(tools []*mcp.Tool) [][]string {
+	docs := make([][]string, len(tools))
+	for i, tool := range tools {
+		var doc []string
+		nameTerms := tokenize(tool.Name)
+		for range toolSearchNameWeight {
+			doc = append(doc, nameTerms...)
+		}
+		doc = append(doc, tokenize(tool.Title)...)
+		doc = append(doc, tokenize(tool.Description)...)
+		for _, prop := range toolInputProperties(tool) {
+			doc = append(doc, tokenize(prop)...)
+		}
+		docs[i] = doc
+	}
+	return docs
+}
+
+// toolSearchEmbeddingsText returns the text of a tool whose embedding is compared to the embedding of the query.
+func toolSearchEmbeddingsText(tool *mcp.Tool) string {
+	return strings.Join(append([]string{tool.Name, tool.Title, tool.Description}, toolInputProperties(tool)...), "\n")
+}
+
+// toolInputProperties returns the names and descriptions of the top-level properties of the input of a tool, sorted
+// by name.
+func toolInputProperties(tool *mcp.Tool) []string {
+	var schema struct {
+		Properties map[string]struct {
+			Description string `json:"description"`
+		} `json:"properties"`
+	}
+	encoded, err := json.Marshal(tool.InputSchema)
+	if err != nil || json.Unmarshal(encoded, &schema) != nil {
+		return nil
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var ret []string
+	for _, name := range names {
+		ret = append(ret, name)
+		if d := schema.Properties[name].Description; d != "" {
+			ret = append(ret, d)
+		}
+	}
+	return ret
+}
+
+// tokenize splits the given text into lowercase terms. The words are split on the characters other than letters and
+// digits, and on the case changes of camelCase words, e.g. "listPullRequests" is split into "list", "pull" and
+// "requests".
+func tokenize(text string) []string {
+	var (
+		terms []string
+		term  []rune
+		prev  rune
+	)
+	flush := func() {
+		if len(term) > 0 {
+			terms = append(terms, strings.ToLower(string(term)))
+			term = term[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush()
+			term = append(term, r)
+		default:
+			term = append(term, r)
+		}
+		prev = r
+	}
+	flush()
+	return terms
+}
+
+// bm25Scores returns the BM25 relevance of each document to the query terms.
+func bm25Scores(query []string, docs [][]string) []float64 {
+	scores := make([]float64, len(docs))
+	if len(docs) == 0 {
+		return scores
+	}
+	var totalLen int
+	freqs := make([]map[string]int, len(docs))
+	docFreqs := make(map[string]int)
+	for i, doc := range docs {
+		totalLen += len(doc)
+		freqs[i] = make(map[string]int)
+		for _, term := range doc {
+			if freqs[i][term] == 0 {
+				docFreqs[term]++
+			}
+			freqs[i][term]++
+		}
+	}
+	avgLen := math.Max(float64(totalLen)/float64(len(docs)), 1)
+	n := float64(len(docs))
+	seen := make(map[string]struct{}, len(query))
+	for _, term := range query {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		df := float64(docFreqs[term])
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for i, doc := range docs {
+			tf := float64(freqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			scores[i] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(len(doc))/avgLen))
+		}
+	}
+	return scores
+}
+
+// cosineSimilarity returns the cosine similarity of the given vectors, or zero if they cannot be compared.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// toolSearchEmbeddingsKey returns the key of the cached embedding of the given text.
+func toolSearchEmbeddingsKey(model, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return model + ":" + hex.EncodeToString(sum[:])
+}
+
+// toolSearchEmbeddings returns the embeddings of the given texts. When cache is true, the embeddings are cached, so
+// that the embeddings of the tools are only computed once per definition.
+func (m *mcpRequestContext) toolSearchEmbeddings(ctx context.Context, emb *filterapi.MCPToolSearchEmbeddings, texts []string, cache bool) ([][]float64, error) {
+	ret := make([][]float64, len(texts))
+	var missing []int
+	for i, text := range texts {
+		if v, ok := m.toolSearchEmbeddingsCache.Load(toolSearchEmbeddingsKey(emb.Model, text)); cache && ok {
+			ret[i] = v.([]float64)
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return ret, nil
+	}
+
+	input := make([]string, len(missing))
+	for i, idx := range missing {
+		input[i] = texts[idx]
+	}
+	body, err := json.Marshal(&embeddingsRequest{Model: emb.Model, Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embeddings request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, emb.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call embeddings endpoint: %w", err)
+	}
+	defer ensureHTTPConnectionReused(resp)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("embeddings endpoint returned status %d", resp.StatusCode)
+	}
+	var result embeddingsResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxToolSearchEmbeddingsResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid embeddings response: %w", err)
+	}
+	if len(result.Data) != len(missing) {
+		return nil, fmt.Errorf("embeddings endpoint returned %d embeddings for %d inputs", len(result.Data), len(missing))
+	}
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(missing) {
+			return nil, fmt.Errorf("embeddings endpoint returned an embedding at invalid index %d", d.Index)
+		}
+		idx := missing[d.Index]
+		ret[idx] = d.Embedding
+		if cache {
+			m.toolSearchEmbeddingsCache.Store(toolSearchEmbeddingsKey(emb.Model, texts[idx]), d.Embedding)
+		}
+	}
+	return ret, nil
+}
+
+// writeJSONRPCResult writes the JSON-RPC response with the given result to the given request.
+func writeJSONRPCResult(w http.ResponseWriter, s *session, req *jsonrpc.Request, result any) error {
+	encodedResult, err := json.Marshal(result)
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("failed to marshal result: %v", err))
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	encoded, err := jsonrpc.EncodeMessage(&jsonrpc.Response{ID: req.ID, Result: encodedResult})
+	if err != nil {
+		onErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(sessionIDHeader, string(s.clientGatewaySessionID()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(encoded)
+	return nil
+}
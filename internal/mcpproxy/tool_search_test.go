@@ -0,0 +1,321 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package mcpproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+func Test_newToolSearch(t *testing.T) {
+	require.Nil(t, newToolSearch(nil))
+	require.Equal(t, &toolSearch{maxResults: defaultToolSearchMaxResults}, newToolSearch(&filterapi.MCPToolSearch{}))
+	emb := &filterapi.MCPToolSearchEmbeddings{URL: "http://localhost/v1/embeddings", Model: "model"}
+	require.Equal(t, &toolSearch{maxResults: 3, embeddings: emb}, newToolSearch(&filterapi.MCPToolSearch{MaxResults: 3, Embeddings: emb}))
+}
+
+func Test_tokenize(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		exp  []string
+	}{
+		{text: "", exp: nil},
+		{text: "github__listPullRequests", exp: []string{"github", "list", "pull", "requests"}},
+		{text: "create_issue", exp: []string{"create", "issue"}},
+		{text: "Returns the pet with the given ID.", exp: []string{"returns", "the", "pet", "with", "the", "given", "id"}},
+		{text: "s3GetObject v2", exp: []string{"s3", "get", "object", "v2"}},
+	} {
+		t.Run(tc.text, func(t *testing.T) {
+			require.Equal(t, tc.exp, tokenize(tc.text))
+		})
+	}
+}
+
+func Test_bm25Scores(t *testing.T) {
+	docs := [][]string{
+		{"list", "pets"},
+		{"show", "pet", "by", "id", "pet", "id"},
+		{"delete", "pet", "pet", "id", "the", "id", "of", "the", "pet", "to", "delete"},
+	}
+	require.Equal(t, []float64{0, 0, 0}, bm25Scores([]string{"unknown"}, docs))
+	require.Empty(t, bm25Scores([]string{"pet"}, nil))
+
+	scores := bm25Scores([]string{"delete", "pet"}, docs)
+	require.Zero(t, scores[0])
+	require.Greater(t, scores[2], scores[1])
+	require.Greater(t, scores[1], scores[0])
+	// The repeated terms of the query are only counted once.
+	require.Equal(t, scores, bm25Scores([]string{"delete", "pet", "delete"}, docs))
+	// The rare terms are more relevant than the common ones.
+	scores = bm25Scores([]string{"pets"}, docs)
+	require.Greater(t, scores[0], bm25Scores([]string{"pet"}, docs)[1])
+}
+
+func Test_cosineSimilarity(t *testing.T) {
+	require.InDelta(t, 1.0, cosineSimilarity([]float64{1, 2}, []float64{2, 4}), 1e-9)
+	require.InDelta(t, 0.0, cosineSimilarity([]float64{1, 0}, []float64{0, 1}), 1e-9)
+	require.Zero(t, cosineSimilarity([]float64{1}, []float64{1, 2}))
+	require.Zero(t, cosineSimilarity([]float64{0, 0}, []float64{1, 2}))
+	require.Zero(t, cosineSimilarity(nil, nil))
+}
+
+func Test_callToolTarget(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		args   any
+		exp    *mcp.CallToolParams
+		expErr string
+	}{
+		{
+			name: "with arguments",
+			args: map[string]any{"name": "github__create_issue", "arguments": map[string]any{"title": "bug"}},
+			exp:  &mcp.CallToolParams{Name: "github__create_issue", Arguments: map[string]any{"title": "bug"}},
+		},
+		{
+			name: "without arguments",
+			args: map[string]any{"name": "github__list_issues"},
+			exp:  &mcp.CallToolParams{Name: "github__list_issues"},
+		},
+		{name: "no name", args: map[string]any{"arguments": map[string]any{}}, expErr: "name is required"},
+		{name: "no arguments", args: nil, expErr: "name is required"},
+		{name: "meta tool", args: map[string]any{"name": "call_tool"}, expErr: "call_tool cannot be called with call_tool"},
+		{name: "invalid arguments", args: map[string]any{"name": "a__b", "arguments": "x"}, expErr: "arguments must be an object"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			target, err := callToolTarget(&mcp.CallToolParams{Name: callToolToolName, Arguments: tc.args})
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, target)
+		})
+	}
+}
+
+func Test_toolInputProperties(t *testing.T) {
+	tool := &mcp.Tool{InputSchema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"repo":  map[string]any{"type": "string", "description": "The repository"},
+			"title": map[string]any{"type": "string"},
+		},
+	}}
+	require.Equal(t, []string{"repo", "The repository", "title"}, toolInputProperties(tool))
+	require.Empty(t, toolInputProperties(&mcp.Tool{}))
+}
+
+// newTestToolSearchProxy returns a proxy whose test route exposes the tools of the Petstore OpenAPI backend, with the
+// tool search enabled, and a session of the route.
+func newTestToolSearchProxy(t *testing.T, backendListenerAddr string) (*mcpRequestContext, *session) {
+	proxy := newTestMCPProxy()
+	proxy.backendListenerAddr = backendListenerAddr
+	route := proxy.routes["test-route"]
+	route.backends = map[filterapi.MCPBackendName]filterapi.MCPBackend{
+		"petstore": {Name: "petstore", OpenAPI: &filterapi.MCPOpenAPIBackend{Document: testPetstoreOpenAPI}},
+	}
+	route.openAPIBackends = map[filterapi.MCPBackendName]*openAPIBackend{"petstore": requireNewTestPetstoreBackend(t)}
+	route.toolSearch = &toolSearch{maxResults: 3}
+	s := &session{
+		reqCtx: proxy,
+		route:  "test-route",
+		perBackendSessions: map[filterapi.MCPBackendName]*compositeSessionEntry{
+			"petstore": {backendName: "petstore", capabilities: openAPIBackendCapabilities},
+		},
+	}
+	return proxy, s
+}
+
+func TestHandleToolsListRequest_ToolSearch(t *testing.T) {
+	proxy, s := newTestToolSearchProxy(t, "")
+	rr := httptest.NewRecorder()
+	req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/list"}
+	require.NoError(t, proxy.handleToolsListRequest(t.Context(), s, rr, req, &mcp.ListToolsParams{}, nil))
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	msg, err := jsonrpc.DecodeMessage(rr.Body.Bytes())
+	require.NoError(t, err)
+	var result mcp.ListToolsResult
+	require.NoError(t, json.Unmarshal(msg.(*jsonrpc.Response).Result, &result))
+	require.Len(t, result.Tools, 2)
+	require.Equal(t, searchToolsToolName, result.Tools[0].Name)
+	require.Equal(t, callToolToolName, result.Tools[1].Name)
+	require.Empty(t, result.NextCursor)
+}
+
+func TestHandleToolCallRequest_ToolSearch(t *testing.T) {
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/pets/1", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","name":"rex"}`))
+	}))
+	t.Cleanup(backendServer.Close)
+	proxy, s := newTestToolSearchProxy(t, backendServer.URL)
+
+	call := func(t *testing.T, name string, args map[string]any) (*jsonrpc.Response, error) {
+		req := &jsonrpc.Request{ID: mustJSONRPCRequestID(), Method: "tools/call"}
+		rr := httptest.NewRecorder()
+		_, err := proxy.handleToolCallRequest(t.Context(), s, rr, req,
+			&mcp.CallToolParams{Name: name, Arguments: args}, nil, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		msg, decodeErr := jsonrpc.DecodeMessage(rr.Body.Bytes())
+		require.NoError(t, decodeErr)
+		resp := msg.(*jsonrpc.Response)
+		require.Equal(t, req.ID, resp.ID)
+		return resp, err
+	}
+	search := func(t *testing.T, args map[string]any) []string {
+		resp, err := call(t, searchToolsToolName, args)
+		require.NoError(t, err)
+		var result mcp.CallToolResult
+		require.NoError(t, json.Unmarshal(resp.Result, &result))
+		require.Len(t, result.Content, 1)
+		var structured searchToolsResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &structured))
+		var names []string
+		for _, tool := range structured.Tools {
+			require.NotNil(t, tool.InputSchema)
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	t.Run("search", func(t *testing.T) {
+		require.Equal(t, []string{"petstore__deletePet"}, search(t, map[string]any{"query": "delete"}))
+		names := search(t, map[string]any{"query": "show the pet by id"})
+		require.Equal(t, "petstore__showPetById", names[0])
+		require.Len(t, names, 3) // Limited by the max results of the route.
+		require.Len(t, search(t, map[string]any{"query": "pet", "limit": 1}), 1)
+		require.Empty(t, search(t, map[string]any{"query": "weather forecast"}))
+	})
+
+	t.Run("search without query", func(t *testing.T) {
+		resp, err := call(t, searchToolsToolName, map[string]any{"limit": 1})
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, int64(jsonrpc.CodeInvalidParams), resp.Error.(*jsonrpc.Error).Code)
+		require.Equal(t, "invalid arguments for tool search_tools: query is required", rpcErr.Message)
+	})
+
+	t.Run("call", func(t *testing.T) {
+		resp, err := call(t, callToolToolName, map[string]any{
+			"name":      "petstore__showPetById",
+			"arguments": map[string]any{"petId": "1", "X-Request-ID": "req-1"},
+		})
+		require.NoError(t, err)
+		var result mcp.CallToolResult
+		require.NoError(t, json.Unmarshal(resp.Result, &result))
+		require.False(t, result.IsError)
+		require.Equal(t, map[string]any{"id": "1", "name": "rex"}, result.StructuredContent)
+	})
+
+	t.Run("call with invalid tool arguments", func(t *testing.T) {
+		_, err := call(t, callToolToolName, map[string]any{
+			"name":      "petstore__showPetById",
+			"arguments": map[string]any{"X-Request-ID": "req-1"},
+		})
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, `invalid arguments for tool showPetById: missing required parameter "petId"`, rpcErr.Message)
+	})
+
+	t.Run("call meta tool", func(t *testing.T) {
+		_, err := call(t, callToolToolName, map[string]any{"name": searchToolsToolName})
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, "invalid arguments for tool call_tool: search_tools cannot be called with call_tool", rpcErr.Message)
+	})
+}
+
+func TestHandleSearchToolsRequest_Authorization(t *testing.T) {
+	proxy, s := newTestToolSearchProxy(t, "")
+	route := proxy.routes["test-route"]
+	auth, err := compileAuthorization(&filterapi.MCPRouteAuthorization{
+		DefaultAction: filterapi.AuthorizationActionDeny,
+		Rules: []filterapi.MCPRouteAuthorizationRule{{
+			Action: filterapi.AuthorizationActionAllow,
+			Target: &filterapi.MCPAuthorizationTarget{Tools: []filterapi.ToolCall{{Backend: "petstore", Tool: "listPets"}}},
+		}},
+	})
+	require.NoError(t, err)
+	route.authorization = auth
+
+	// Only the tools the client is allowed to call are found.
+	tools := proxy.listToolsForSearch(t.Context(), s, route)
+	require.Len(t, tools, 1)
+	require.Equal(t, "petstore__listPets", tools[0].Name)
+}
+
+func TestRankTools_Embeddings(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	embeddingsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req embeddingsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "test-model", req.Model)
+		// The texts about removing things are close to each other.
+		var resp embeddingsResponse
+		for i, text := range req.Input {
+			embedding := []float64{0, 1}
+			if strings.Contains(text, "delete") || strings.Contains(text, "remove") {
+				embedding = []float64{1, 0}
+			}
+			resp.Data = append(resp.Data, struct {
+				Index     int       `json:"index"`
+				Embedding []float64 `json:"embedding"`
+			}{Index: i, Embedding: embedding})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(&resp))
+	}))
+	t.Cleanup(embeddingsServer.Close)
+
+	proxy := newTestMCPProxy()
+	tools := []*mcp.Tool{
+		{Name: "petstore__listPets", Description: "List all pets"},
+		{Name: "petstore__deletePet", Description: "Deletes a pet"},
+		{Name: "petstore__showPetById", Description: "Info for a specific pet"},
+	}
+	ts := &toolSearch{maxResults: 10, embeddings: &filterapi.MCPToolSearchEmbeddings{URL: embeddingsServer.URL, Model: "test-model"}}
+	names := func(tools []*mcp.Tool) []string {
+		var ret []string
+		for _, tool := range tools {
+			ret = append(ret, tool.Name)
+		}
+		return ret
+	}
+
+	// No keyword matches, but the semantic similarity finds the tool.
+	ranked := proxy.rankTools(t.Context(), ts, "remove", tools)
+	require.Equal(t, "petstore__deletePet", ranked[0].Name)
+	require.Len(t, ranked, 3)
+	require.Equal(t, int32(2), requests.Load())
+
+	// The embeddings of the tools are cached, only the query is embedded.
+	ranked = proxy.rankTools(t.Context(), ts, "list pets", tools)
+	require.Equal(t, "petstore__listPets", ranked[0].Name)
+	require.Equal(t, int32(3), requests.Load())
+
+	// The tools are ranked by keywords only when the embeddings fail.
+	fail.Store(true)
+	require.Equal(t, []string{"petstore__listPets"}, names(proxy.rankTools(t.Context(), ts, "list", tools)))
+	require.Empty(t, proxy.rankTools(t.Context(), ts, "remove", tools))
+}
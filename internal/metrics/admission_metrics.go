@@ -0,0 +1,80 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// nolint: godot
+const (
+	// Admission Decisions is a counter metric that records the admission decisions of the requests sent to
+	// the backends with admission control.
+	//
+	// Dimensions:
+	// - ai_gateway.backend.name
+	// - ai_gateway.priority_class
+	// - ai_gateway.admission.decision
+	admissionDecisions = "ai_gateway.admission.decisions"
+	// Admission Queue Duration is a histogram metric that records the time the requests spent waiting for admission.
+	//
+	// Dimensions:
+	// - ai_gateway.backend.name
+	// - ai_gateway.priority_class
+	// - ai_gateway.admission.decision
+	admissionQueueDuration = "ai_gateway.admission.queue.duration"
+	// Backend attribute, which is the AIServiceBackend in the form of "namespace/name".
+	admissionAttributeBackend = "ai_gateway.backend.name"
+	// Priority class attribute, which is either "High", "Normal" or "Low".
+	admissionAttributePriorityClass = "ai_gateway.priority_class"
+	// Admission decision attribute, which is for example "admitted" or "shed".
+	admissionAttributeDecision = "ai_gateway.admission.decision"
+)
+
+// AdmissionMetrics holds metrics for the backend admission control.
+type AdmissionMetrics interface {
+	// RecordDecision records the admission decision of a request and the time it spent in the queue.
+	// The queue duration is only recorded for the requests that were queued.
+	RecordDecision(ctx context.Context, backend, priorityClass, decision string, queued bool, wait time.Duration)
+}
+
+type admission struct {
+	decisions     metric.Float64Counter
+	queueDuration metric.Float64Histogram
+}
+
+// NewAdmission creates a new admission metrics instance.
+func NewAdmission(meter metric.Meter) AdmissionMetrics {
+	return &admission{
+		decisions: mustRegisterCounter(meter,
+			admissionDecisions,
+			metric.WithDescription("Total number of admission decisions of the requests sent to the backends with admission control"),
+		),
+		queueDuration: mustRegisterHistogram(meter,
+			admissionQueueDuration,
+			metric.WithDescription("Time the requests spent waiting for admission"),
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+		),
+	}
+}
+
+// RecordDecision implements [AdmissionMetrics.RecordDecision].
+func (a *admission) RecordDecision(ctx context.Context, backend, priorityClass, decision string, queued bool, wait time.Duration) {
+	attrs := metric.WithAttributes(
+		attribute.String(admissionAttributeBackend, backend),
+		attribute.String(admissionAttributePriorityClass, priorityClass),
+		attribute.String(admissionAttributeDecision, decision),
+	)
+	a.decisions.Add(ctx, 1, attrs)
+	if queued {
+		a.queueDuration.Record(ctx, wait.Seconds(), attrs)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/envoyproxy/ai-gateway/internal/testing/testotel"
+)
+
+func TestAdmission_RecordDecision(t *testing.T) {
+	mr := metric.NewManualReader()
+	meter := metric.NewMeterProvider(metric.WithReader(mr)).Meter("test")
+	a := NewAdmission(meter)
+
+	a.RecordDecision(t.Context(), "default/azure", "Low", "queued", true, 1500*time.Millisecond)
+	a.RecordDecision(t.Context(), "default/azure", "Low", "queued", true, 500*time.Millisecond)
+	a.RecordDecision(t.Context(), "default/azure", "Normal", "admitted", false, 0)
+
+	queued := attribute.NewSet(
+		attribute.String(admissionAttributeBackend, "default/azure"),
+		attribute.String(admissionAttributePriorityClass, "Low"),
+		attribute.String(admissionAttributeDecision, "queued"),
+	)
+	admitted := attribute.NewSet(
+		attribute.String(admissionAttributeBackend, "default/azure"),
+		attribute.String(admissionAttributePriorityClass, "Normal"),
+		attribute.String(admissionAttributeDecision, "admitted"),
+	)
+	require.Equal(t, 2.0, testotel.GetCounterValue(t, mr, admissionDecisions, queued))
+	require.Equal(t, 1.0, testotel.GetCounterValue(t, mr, admissionDecisions, admitted))
+
+	count, sum := testotel.GetHistogramValues(t, mr, admissionQueueDuration, queued)
+	require.Equal(t, uint64(2), count)
+	require.Equal(t, 2.0, sum)
+}
@@ -42,6 +42,34 @@ const (
 	mcpCapabilitiesNegotiated = "mcp.capabilities.negotiated"
 	// MCP Progress Notifications is a counter metric that records the total number of MCP progress notifications sent.
 	mpcProgressNotifications = "mcp.progress.notifications"
+	// MCP Tool Definition Drifts is a counter metric that records the total number of tool definitions listed by
+	// a backend that don't match their approved fingerprint.
+	//
+	// Dimensions:
+	// - mcp.tool.name
+	// - mcp.tool.pinning.action
+	mcpToolDefinitionDrifts = "mcp.tool.definition_drifts"
+	// MCP Tool Schema Validation Failures is a counter metric that records the total number of tool call arguments
+	// and results that don't match the input and output schemas of the tools.
+	//
+	// Dimensions:
+	// - mcp.tool.name
+	// - mcp.tool.schema
+	mcpToolSchemaValidationFailures = "mcp.tool.schema_validation_failures"
+	// MCP Tool Rate Limited Calls is a counter metric that records the total number of tool calls rejected by
+	// the rate limits of the MCPRoute.
+	//
+	// Dimensions:
+	// - mcp.tool.name
+	mcpToolRateLimitedCalls = "mcp.tool.rate_limited_calls"
+	// MCP Tool Approvals is a counter metric that records the total number of tool calls that required an approval,
+	// by the way the approval was requested and its outcome.
+	//
+	// Dimensions:
+	// - mcp.tool.name
+	// - mcp.tool.approval.method
+	// - mcp.tool.approval.outcome
+	mcpToolApprovals = "mcp.tool.approvals"
 	// MCP JSON-RPC method name attribute.
 	mcpAttributeMethodName = "mcp.method.name"
 	// MCP status attribute, which is either "success" or "error". See mcpStatusType for all statuses.
@@ -54,6 +82,17 @@ const (
 	mcpAttributeCapabilitySide = "capability.side"
 	// MCP backend attribute, which identifies the upstream MCP backend that handled the request.
 	mcpAttributeBackend = "mcp.backend"
+	// MCP tool name attribute.
+	mcpAttributeToolName = "mcp.tool.name"
+	// MCP tool pinning action attribute, which is the action taken on a drifted tool definition.
+	mcpAttributeToolPinningAction = "mcp.tool.pinning.action"
+	// MCP tool schema attribute, which is either "input" or "output".
+	mcpAttributeToolSchema = "mcp.tool.schema"
+	// MCP tool approval method attribute, which is either "elicitation", "webhook" or "none" when the approval could
+	// not be requested.
+	mcpAttributeToolApprovalMethod = "mcp.tool.approval.method"
+	// MCP tool approval outcome attribute, which is for example "approved" or "denied".
+	mcpAttributeToolApprovalOutcome = "mcp.tool.approval.outcome"
 )
 
 // MCPErrorType defines the type of error that occurred during an MCP request.
@@ -131,6 +170,14 @@ type MCPMetrics interface {
 	RecordServerCapabilities(ctx context.Context, capabilities *mcpsdk.ServerCapabilities, meta mcpsdk.Params)
 	// RecordProgress records a progress notification sent/received.
 	RecordProgress(ctx context.Context, meta mcpsdk.Params)
+	// RecordToolDefinitionDrift records a tool definition that doesn't match its approved fingerprint, and the action taken on it.
+	RecordToolDefinitionDrift(ctx context.Context, toolName, action string)
+	// RecordToolSchemaValidationFailure records tool call arguments or results that don't match the input or output schema of the tool.
+	RecordToolSchemaValidationFailure(ctx context.Context, toolName, schema string)
+	// RecordToolRateLimited records a tool call rejected by a rate limit.
+	RecordToolRateLimited(ctx context.Context, toolName string)
+	// RecordToolApproval records the outcome of the approval of a tool call, and the method used to request it.
+	RecordToolApproval(ctx context.Context, toolName, method, outcome string)
 }
 
 type mcp struct {
@@ -139,6 +186,10 @@ type mcp struct {
 	initializationDuration        metric.Float64Histogram
 	capabilitiesNegotiated        metric.Float64Counter
 	progressNotifications         metric.Float64Counter
+	toolDefinitionDrifts          metric.Float64Counter
+	toolSchemaValidationFailures  metric.Float64Counter
+	toolRateLimitedCalls          metric.Float64Counter
+	toolApprovals                 metric.Float64Counter
 	requestHeaderAttributeMapping map[string]string // maps HTTP headers to metric attribute names.
 	defaultAttributes             []attribute.KeyValue
 }
@@ -172,6 +223,26 @@ func NewMCP(meter metric.Meter, requestHeaderAttributeMapping map[string]string)
 			mpcProgressNotifications,
 			metric.WithDescription("Total number of MCP progress notifications sent"),
 		),
+		toolDefinitionDrifts: mustRegisterCounter(
+			meter,
+			mcpToolDefinitionDrifts,
+			metric.WithDescription("Total number of MCP tool definitions that don't match their approved fingerprint"),
+		),
+		toolSchemaValidationFailures: mustRegisterCounter(
+			meter,
+			mcpToolSchemaValidationFailures,
+			metric.WithDescription("Total number of MCP tool call arguments and results that don't match the tool schemas"),
+		),
+		toolRateLimitedCalls: mustRegisterCounter(
+			meter,
+			mcpToolRateLimitedCalls,
+			metric.WithDescription("Total number of MCP tool calls rejected by a rate limit"),
+		),
+		toolApprovals: mustRegisterCounter(
+			meter,
+			mcpToolApprovals,
+			metric.WithDescription("Total number of MCP tool calls that required an approval"),
+		),
 	}
 }
 
@@ -183,6 +254,10 @@ func (m *mcp) WithBackend(backend string) MCPMetrics {
 		initializationDuration:        m.initializationDuration,
 		capabilitiesNegotiated:        m.capabilitiesNegotiated,
 		progressNotifications:         m.progressNotifications,
+		toolDefinitionDrifts:          m.toolDefinitionDrifts,
+		toolSchemaValidationFailures:  m.toolSchemaValidationFailures,
+		toolRateLimitedCalls:          m.toolRateLimitedCalls,
+		toolApprovals:                 m.toolApprovals,
 		requestHeaderAttributeMapping: m.requestHeaderAttributeMapping,
 		defaultAttributes: append(
 			slices.Clone(m.defaultAttributes),
@@ -201,6 +276,10 @@ func (m *mcp) WithRequestAttributes(req *http.Request) MCPMetrics {
 		initializationDuration:        m.initializationDuration,
 		capabilitiesNegotiated:        m.capabilitiesNegotiated,
 		progressNotifications:         m.progressNotifications,
+		toolDefinitionDrifts:          m.toolDefinitionDrifts,
+		toolSchemaValidationFailures:  m.toolSchemaValidationFailures,
+		toolRateLimitedCalls:          m.toolRateLimitedCalls,
+		toolApprovals:                 m.toolApprovals,
 		requestHeaderAttributeMapping: m.requestHeaderAttributeMapping,
 	}
 
@@ -265,6 +344,38 @@ func (m *mcp) RecordProgress(ctx context.Context, params mcpsdk.Params) {
 	m.progressNotifications.Add(ctx, 1, m.withDefaultAttributes(params))
 }
 
+// RecordToolDefinitionDrift implements [MCPMetrics.RecordToolDefinitionDrift].
+func (m *mcp) RecordToolDefinitionDrift(ctx context.Context, toolName, action string) {
+	m.toolDefinitionDrifts.Add(ctx, 1, m.withDefaultAttributes(nil,
+		attribute.Key(mcpAttributeToolName).String(toolName),
+		attribute.Key(mcpAttributeToolPinningAction).String(action),
+	))
+}
+
+// RecordToolSchemaValidationFailure implements [MCPMetrics.RecordToolSchemaValidationFailure].
+func (m *mcp) RecordToolSchemaValidationFailure(ctx context.Context, toolName, schema string) {
+	m.toolSchemaValidationFailures.Add(ctx, 1, m.withDefaultAttributes(nil,
+		attribute.Key(mcpAttributeToolName).String(toolName),
+		attribute.Key(mcpAttributeToolSchema).String(schema),
+	))
+}
+
+// RecordToolRateLimited implements [MCPMetrics.RecordToolRateLimited].
+func (m *mcp) RecordToolRateLimited(ctx context.Context, toolName string) {
+	m.toolRateLimitedCalls.Add(ctx, 1, m.withDefaultAttributes(nil,
+		attribute.Key(mcpAttributeToolName).String(toolName),
+	))
+}
+
+// RecordToolApproval implements [MCPMetrics.RecordToolApproval].
+func (m *mcp) RecordToolApproval(ctx context.Context, toolName, method, outcome string) {
+	m.toolApprovals.Add(ctx, 1, m.withDefaultAttributes(nil,
+		attribute.Key(mcpAttributeToolName).String(toolName),
+		attribute.Key(mcpAttributeToolApprovalMethod).String(method),
+		attribute.Key(mcpAttributeToolApprovalOutcome).String(outcome),
+	))
+}
+
 // RecordClientCapabilities implements [MCPMetrics.RecordClientCapabilities].
 func (m *mcp) RecordClientCapabilities(ctx context.Context, capabilities *mcpsdk.ClientCapabilities, params mcpsdk.Params) {
 	if capabilities == nil {
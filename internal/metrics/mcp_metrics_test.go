@@ -205,6 +205,77 @@ func TestRecordProgressNotifications(t *testing.T) {
 	require.Equal(t, float64(2), val)
 }
 
+func TestRecordToolDefinitionDrift(t *testing.T) {
+	mr := metric.NewManualReader()
+	meter := metric.NewMeterProvider(metric.WithReader(mr)).Meter("test")
+
+	m := NewMCP(meter, nil).WithBackend("github")
+	m.RecordToolDefinitionDrift(t.Context(), "create_issue", "Drop")
+	m.RecordToolDefinitionDrift(t.Context(), "create_issue", "Drop")
+	val := testotel.GetCounterValue(t, mr, mcpToolDefinitionDrifts, attribute.NewSet(
+		attribute.Key(mcpAttributeBackend).String("github"),
+		attribute.Key(mcpAttributeToolName).String("create_issue"),
+		attribute.Key(mcpAttributeToolPinningAction).String("Drop"),
+	))
+	require.Equal(t, float64(2), val)
+}
+
+func TestRecordToolSchemaValidationFailure(t *testing.T) {
+	mr := metric.NewManualReader()
+	meter := metric.NewMeterProvider(metric.WithReader(mr)).Meter("test")
+
+	m := NewMCP(meter, nil).WithBackend("github")
+	m.RecordToolSchemaValidationFailure(t.Context(), "create_issue", "input")
+	m.RecordToolSchemaValidationFailure(t.Context(), "create_issue", "output")
+	for _, schema := range []string{"input", "output"} {
+		val := testotel.GetCounterValue(t, mr, mcpToolSchemaValidationFailures, attribute.NewSet(
+			attribute.Key(mcpAttributeBackend).String("github"),
+			attribute.Key(mcpAttributeToolName).String("create_issue"),
+			attribute.Key(mcpAttributeToolSchema).String(schema),
+		))
+		require.Equal(t, float64(1), val)
+	}
+}
+
+func TestRecordToolRateLimited(t *testing.T) {
+	mr := metric.NewManualReader()
+	meter := metric.NewMeterProvider(metric.WithReader(mr)).Meter("test")
+
+	m := NewMCP(meter, nil).WithBackend("browser")
+	m.RecordToolRateLimited(t.Context(), "navigate")
+	m.RecordToolRateLimited(t.Context(), "navigate")
+	val := testotel.GetCounterValue(t, mr, mcpToolRateLimitedCalls, attribute.NewSet(
+		attribute.Key(mcpAttributeBackend).String("browser"),
+		attribute.Key(mcpAttributeToolName).String("navigate"),
+	))
+	require.Equal(t, float64(2), val)
+}
+
+func TestRecordToolApproval(t *testing.T) {
+	mr := metric.NewManualReader()
+	meter := metric.NewMeterProvider(metric.WithReader(mr)).Meter("test")
+
+	m := NewMCP(meter, nil).WithBackend("github")
+	m.RecordToolApproval(t.Context(), "delete_repository", "elicitation", "approved")
+	m.RecordToolApproval(t.Context(), "delete_repository", "elicitation", "denied")
+	m.RecordToolApproval(t.Context(), "delete_repository", "webhook", "denied")
+	for _, tc := range []struct {
+		method, outcome string
+	}{
+		{"elicitation", "approved"},
+		{"elicitation", "denied"},
+		{"webhook", "denied"},
+	} {
+		val := testotel.GetCounterValue(t, mr, mcpToolApprovals, attribute.NewSet(
+			attribute.Key(mcpAttributeBackend).String("github"),
+			attribute.Key(mcpAttributeToolName).String("delete_repository"),
+			attribute.Key(mcpAttributeToolApprovalMethod).String(tc.method),
+			attribute.Key(mcpAttributeToolApprovalOutcome).String(tc.outcome),
+		))
+		require.Equal(t, float64(1), val)
+	}
+}
+
 func TestWithBackend(t *testing.T) {
 	mr := metric.NewManualReader()
 	meter := metric.NewMeterProvider(metric.WithReader(mr)).Meter("test")
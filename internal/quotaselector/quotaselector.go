@@ -0,0 +1,196 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package quotaselector implements the QuotaPolicy client selectors that need the
+// request to be inspected by the external processor, i.e. the JWT claim and CEL selectors.
+//
+// The external processor exposes the result of each selector as an internal request header,
+// so the rate limit actions generated by the extension server can match on it exactly like
+// on a regular request header. This package is shared by the controller, the extension server
+// and the external processor so that all of them agree on the header names.
+package quotaselector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+
+	"github.com/envoyproxy/ai-gateway/internal/internalapi"
+	"github.com/envoyproxy/ai-gateway/internal/lang"
+)
+
+const (
+	// ClaimHeaderPrefix is the prefix of the internal headers carrying JWT claim values.
+	ClaimHeaderPrefix = internalapi.EnvoyAIGatewayHeaderPrefix + "quota-claim-"
+	// CELHeaderPrefix is the prefix of the internal headers carrying CEL selector results.
+	CELHeaderPrefix = internalapi.EnvoyAIGatewayHeaderPrefix + "quota-cel-"
+
+	// JWTAuthnMetadataNamespace is the dynamic metadata namespace where the Envoy jwt_authn filter writes the
+	// payload of the verified tokens, keyed by the name of the JWT provider.
+	JWTAuthnMetadataNamespace = "envoy.filters.http.jwt_authn"
+
+	// celRequestKey is the name of the only variable available to the CEL selectors.
+	celRequestKey = "request"
+)
+
+var env *cel.Env
+
+func init() {
+	var err error
+	env, err = cel.NewEnv(
+		cel.Variable(celRequestKey, cel.DynType),
+		cel.OptionalTypes(),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("cannot create CEL environment: %v", err))
+	}
+}
+
+// ClaimHeaderName returns the internal header name that carries the value of the given JWT claim.
+//
+// Lower-case letters, digits and "." are kept as is, and every other byte of the claim name, including "-"
+// and the upper-case letters, is escaped as "-" followed by its two hex digits. The result is always a valid
+// header name, and distinct claims such as "org_id", "org-id" and "Org_ID" never share the same header.
+func ClaimHeaderName(claim string) string {
+	var b strings.Builder
+	b.WriteString(ClaimHeaderPrefix)
+	for i := 0; i < len(claim); i++ {
+		switch c := claim[i]; {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '.':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('-')
+			b.WriteString(hex.EncodeToString([]byte{c}))
+		}
+	}
+	return b.String()
+}
+
+// CELHeaderName returns the internal header name that carries the result of the given CEL expression.
+// The name is derived from the hash of the expression, so the same expression always maps to the same header.
+func CELHeaderName(expr string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(expr)))
+	return CELHeaderPrefix + hex.EncodeToString(sum[:8])
+}
+
+// NewCELProgram compiles the given CEL selector expression.
+func NewCELProgram(expr string) (cel.Program, error) {
+	ast, issues := env.Compile(strings.TrimSpace(expr))
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("cannot compile CEL expression: %w", issues.Err())
+	}
+	prog, err := env.Program(ast, cel.CostLimit(10000), cel.EvalOptions(cel.OptOptimize))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create CEL program: %w", err)
+	}
+	return prog, nil
+}
+
+// EvaluateCEL evaluates the CEL selector program against the given request attributes.
+// Boolean results are returned as "true" or "false", string results are returned as is.
+func EvaluateCEL(prog cel.Program, attrs map[string]any) (string, error) {
+	out, _, err := prog.Eval(map[string]any{celRequestKey: attrs})
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate CEL expression: %w", err)
+	}
+	switch v := out.Value().(type) {
+	case bool:
+		return fmt.Sprintf("%t", v), nil
+	case types.Bool:
+		return fmt.Sprintf("%t", bool(v)), nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("CEL expression result is neither a bool nor a string, got %v", out.Type())
+	}
+}
+
+// RequestAttributes builds the value of the "request" CEL variable from the request headers,
+// the model name and the JWT claims.
+func RequestAttributes(requestHeaders map[string]string, model string, claims map[string]any) map[string]any {
+	headers := make(map[string]string, len(requestHeaders))
+	for k, v := range requestHeaders {
+		if strings.HasPrefix(k, ":") {
+			continue
+		}
+		headers[strings.ToLower(k)] = v
+	}
+	path := requestHeaders[internalapi.EnvoyOriginalPathHeader]
+	if path == "" {
+		path = requestHeaders[":path"]
+	}
+	if claims == nil {
+		claims = map[string]any{}
+	}
+	return map[string]any{
+		"method":  requestHeaders[":method"],
+		"host":    requestHeaders[":authority"],
+		"path":    path,
+		"headers": headers,
+		"model":   model,
+		"auth": map[string]any{
+			"jwt": map[string]any{
+				"claims": claims,
+			},
+		},
+	}
+}
+
+// VerifiedClaims returns the claims of the JWTs verified by the Envoy jwt_authn filter, taken from the
+// dynamic metadata forwarded to the external processor.
+//
+// The claims of the tokens that were not verified, such as a bearer token sent to a route without a JWT
+// SecurityPolicy, are never used, so that clients cannot pick a quota bucket or a priority class by forging them.
+// When the tokens of multiple providers were verified, their claims are merged, the provider whose name sorts
+// first taking precedence. Returns nil when no token was verified.
+func VerifiedClaims(metadata *corev3.Metadata) map[string]any {
+	providers := metadata.GetFilterMetadata()[JWTAuthnMetadataNamespace].GetFields()
+	var claims map[string]any
+	for _, provider := range slices.Sorted(maps.Keys(providers)) {
+		payload := providers[provider].GetStructValue()
+		if payload == nil {
+			continue
+		}
+		if claims == nil {
+			claims = make(map[string]any)
+		}
+		for k, v := range payload.AsMap() {
+			if _, ok := claims[k]; !ok {
+				claims[k] = v
+			}
+		}
+	}
+	return claims
+}
+
+// ClaimValue returns the string representation of the claim at the given dot-separated path.
+// String array claims are joined with ",". Other values are formatted with their default format.
+func ClaimValue(claims map[string]any, name string) (string, bool) {
+	value, ok := lang.NestedValue(claims, name)
+	if !ok || value == nil {
+		return "", false
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []string:
+		return strings.Join(v, ","), true
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(parts, ","), true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package quotaselector
+
+import (
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestClaimHeaderName(t *testing.T) {
+	for _, tc := range []struct {
+		claim, expected string
+	}{
+		{claim: "sub", expected: "x-ai-eg-quota-claim-sub"},
+		{claim: "realm_access.roles", expected: "x-ai-eg-quota-claim-realm-5faccess.roles"},
+		{claim: "org-id", expected: "x-ai-eg-quota-claim-org-2did"},
+		{claim: "Org-ID", expected: "x-ai-eg-quota-claim--4frg-2d-49-44"},
+		{claim: "https://example.com/tier", expected: "x-ai-eg-quota-claim-https-3a-2f-2fexample.com-2ftier"},
+	} {
+		t.Run(tc.claim, func(t *testing.T) {
+			require.Equal(t, tc.expected, ClaimHeaderName(tc.claim))
+		})
+	}
+
+	// Claims that only differ by the escaped characters never share the same header.
+	names := map[string]string{}
+	for _, claim := range []string{"org_id", "org-id", "org.id", "Org_ID", "org-5fid", "org_5fid"} {
+		name := ClaimHeaderName(claim)
+		require.NotContains(t, names, name, "%q and %q share the same header", names[name], claim)
+		names[name] = claim
+	}
+}
+
+func TestCELHeaderName(t *testing.T) {
+	a := CELHeaderName(`request.model == "gpt-4o"`)
+	require.Equal(t, a, CELHeaderName(`  request.model == "gpt-4o" `))
+	require.NotEqual(t, a, CELHeaderName(`request.model == "gpt-4o-mini"`))
+	require.Len(t, a, len(CELHeaderPrefix)+16)
+}
+
+func TestNewCELProgram(t *testing.T) {
+	_, err := NewCELProgram(`request.method == "POST"`)
+	require.NoError(t, err)
+	_, err = NewCELProgram(`request.method ==`)
+	require.ErrorContains(t, err, "cannot compile CEL expression")
+}
+
+func TestEvaluateCEL(t *testing.T) {
+	attrs := RequestAttributes(map[string]string{
+		":method":               "POST",
+		":authority":            "gateway.example.com",
+		":path":                 "/v1/chat/completions?x=1",
+		"x-envoy-original-path": "/original",
+		"X-Team":                "ml",
+	}, "gpt-4o", map[string]any{"tier": "free", "org": map[string]any{"id": "acme"}})
+
+	for _, tc := range []struct {
+		name, expr, expected, expErr string
+	}{
+		{name: "method", expr: `request.method == "POST"`, expected: "true"},
+		{name: "host", expr: `request.host == "other"`, expected: "false"},
+		{name: "path", expr: `request.path`, expected: "/original"},
+		{name: "header", expr: `request.headers["x-team"]`, expected: "ml"},
+		{name: "model", expr: `request.model.startsWith("gpt-")`, expected: "true"},
+		{name: "claims", expr: `request.auth.jwt.claims.org.id + "/" + request.auth.jwt.claims.tier`, expected: "acme/free"},
+		{name: "missing claim", expr: `request.auth.jwt.claims.missing == "x"`, expErr: "no such key"},
+		{name: "int result", expr: `1 + 1`, expErr: "neither a bool nor a string"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prog, err := NewCELProgram(tc.expr)
+			require.NoError(t, err)
+			actual, err := EvaluateCEL(prog, attrs)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	attrs := RequestAttributes(map[string]string{":path": "/v1/models", "Foo": "bar"}, "", nil)
+	require.Equal(t, "/v1/models", attrs["path"])
+	require.Equal(t, map[string]string{"foo": "bar"}, attrs["headers"])
+	require.Equal(t, map[string]any{"jwt": map[string]any{"claims": map[string]any{}}}, attrs["auth"])
+}
+
+func TestVerifiedClaims(t *testing.T) {
+	require.Nil(t, VerifiedClaims(nil))
+	require.Nil(t, VerifiedClaims(&corev3.Metadata{FilterMetadata: map[string]*structpb.Struct{
+		"io.envoy.ai_gateway": {Fields: map[string]*structpb.Value{"sub": structpb.NewStringValue("spoofed")}},
+	}}))
+
+	payload := func(claims map[string]any) *structpb.Value {
+		s, err := structpb.NewStruct(claims)
+		require.NoError(t, err)
+		return structpb.NewStructValue(s)
+	}
+	claims := VerifiedClaims(&corev3.Metadata{FilterMetadata: map[string]*structpb.Struct{
+		JWTAuthnMetadataNamespace: {Fields: map[string]*structpb.Value{
+			"b-provider": payload(map[string]any{"sub": "bob", "tier": "free"}),
+			"a-provider": payload(map[string]any{"sub": "alice", "org": map[string]any{"id": "acme"}}),
+		}},
+	}})
+	// The provider whose name sorts first takes precedence.
+	require.Equal(t, map[string]any{"sub": "alice", "tier": "free", "org": map[string]any{"id": "acme"}}, claims)
+}
+
+func TestClaimValue(t *testing.T) {
+	claims := map[string]any{
+		"sub":    "alice",
+		"groups": []any{"admin", "dev"},
+		"scopes": []string{"read", "write"},
+		"level":  float64(3),
+		"realm":  map[string]any{"roles": []any{"user"}},
+		"nil":    nil,
+	}
+	for _, tc := range []struct {
+		name, expected string
+		found          bool
+	}{
+		{name: "sub", expected: "alice", found: true},
+		{name: "groups", expected: "admin,dev", found: true},
+		{name: "scopes", expected: "read,write", found: true},
+		{name: "level", expected: "3", found: true},
+		{name: "realm.roles", expected: "user", found: true},
+		{name: "nil"},
+		{name: "missing"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, found := ClaimValue(claims, tc.name)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
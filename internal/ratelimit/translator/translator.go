@@ -6,7 +6,9 @@
 package translator
 
 import (
+	"errors"
 	"fmt"
+	"net/netip"
 	"sort"
 	"strings"
 
@@ -15,6 +17,7 @@ import (
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 )
 
 const (
@@ -31,6 +34,10 @@ const (
 	// This matches the descriptor key sent by the rate limit MetaData action that reads
 	// the model name from model_name_override in dynamic metadata set by the ext_proc filter.
 	ModelNameDescriptorKey = "model_name_override"
+
+	// MaskedRemoteAddressDescriptorKey is the descriptor key sent by the Envoy MaskedRemoteAddress
+	// rate limit action, used for the source CIDR client selectors. The key is fixed by Envoy.
+	MaskedRemoteAddressDescriptorKey = "masked_remote_address"
 )
 
 // KeyedDescriptor pairs a leaf rate limit descriptor with a comparable key that
@@ -241,14 +248,26 @@ func buildPerModelDescriptorKeyed(descriptorModelName string, quota *aigv1a1.Quo
 		}
 		nested = append(nested, ruleDescs...)
 
-		// Build comparable keys using semantic header names/values.
-		headers := flattenAndSortHeaders(rule.ClientSelectors)
+		// Build comparable keys using semantic header names/values and source CIDRs.
+		// The CIDRs were already validated by buildBucketRuleDescriptors.
+		headers := QuotaRuleHeaderMatches(&rule)
+		cidrs, _ := QuotaRuleSourceCIDRMatches(&rule)
 		leafKey := modelPrefix
-		if len(headers) == 0 {
+		if len(headers) == 0 && len(cidrs) == 0 {
 			leafKey += "/" + ComparableKeySegment("__catch_all", 2, "")
 		} else {
-			for depth, header := range headers {
-				leafKey += "/" + ComparableKeySegment(header.Name, depth+2, headerComparableValue(header))
+			depth := 2
+			for _, header := range headers {
+				leafKey += "/" + ComparableKeySegment(header.Name, depth, headerComparableValue(header))
+				depth++
+			}
+			for _, cidr := range cidrs {
+				leafKey += "/" + ComparableKeySegment(MaskedRemoteAddressDescriptorKey, depth, cidr.Prefix.String())
+				depth++
+				if cidr.Distinct {
+					leafKey += "/" + ComparableKeySegment(MaskedRemoteAddressDescriptorKey, depth, "")
+					depth++
+				}
 			}
 		}
 		for _, rd := range ruleDescs {
@@ -306,9 +325,10 @@ func buildServiceQuotaDescriptor(sq *aigv1a1.ServiceQuotaDefinition) (*rlsconfv3
 }
 
 // buildBucketRuleDescriptors creates a nested chain of descriptors for a single
-// bucket rule. Header matches from all ClientSelectors are flattened, sorted by
-// header name, and nested so that the rate limit service enforces AND logic at
-// the descriptor level (matching the Envoy action chain order).
+// bucket rule. Header matches from all ClientSelectors, JWT claim selectors and CEL
+// selectors are flattened, sorted by header name, and nested so that the rate limit
+// service enforces AND logic at the descriptor level (matching the Envoy action chain order).
+// Source CIDR matches are nested below the header matches, sorted by CIDR.
 //
 // Descriptor value strategy per match type:
 //   - Distinct: key only (no value). The RequestHeaders action sends the actual header
@@ -316,6 +336,7 @@ func buildServiceQuotaDescriptor(sq *aigv1a1.ServiceQuotaDefinition) (*rlsconfv3
 //   - Exact / Regex: key and value both set to the BucketRuleDescriptorKey. The
 //     HeaderValueMatch action sends the fixed DescriptorValue (not the actual header
 //     value), so the service config must match that same fixed string.
+//   - Source CIDR: see sourceCIDRDescriptors.
 func buildBucketRuleDescriptors(ruleIndex int, rule *aigv1a1.QuotaRule) ([]*rlsconfv3.RateLimitDescriptor, error) {
 	policy, err := quotaValueToPolicy(&rule.Quota)
 	if err != nil {
@@ -323,11 +344,21 @@ func buildBucketRuleDescriptors(ruleIndex int, rule *aigv1a1.QuotaRule) ([]*rlsc
 	}
 	shadowMode := rule.ShadowMode != nil && *rule.ShadowMode
 
-	// Flatten and sort all header matches across all ClientSelectors.
-	allHeaders := flattenAndSortHeaders(rule.ClientSelectors)
+	for _, sel := range rule.CELSelectors {
+		if _, err = quotaselector.NewCELProgram(sel.Expression); err != nil {
+			return nil, fmt.Errorf("invalid CEL selector %q: %w", sel.Expression, err)
+		}
+	}
 
-	// No headers: single catch-all descriptor for this rule.
-	if len(allHeaders) == 0 {
+	// Flatten and sort all header matches across all selectors.
+	allHeaders := QuotaRuleHeaderMatches(rule)
+	cidrs, err := QuotaRuleSourceCIDRMatches(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// No selectors: single catch-all descriptor for this rule.
+	if len(allHeaders) == 0 && len(cidrs) == 0 {
 		key := BucketRuleDescriptorKey(ruleIndex, 0, "", "")
 		return []*rlsconfv3.RateLimitDescriptor{{
 			Key:        key,
@@ -340,27 +371,49 @@ func buildBucketRuleDescriptors(ruleIndex int, rule *aigv1a1.QuotaRule) ([]*rlsc
 
 	// Build a nested chain of descriptors. The rate limit, shadow mode, and
 	// quota mode are applied only to the leaf (deepest) descriptor.
-	// Each level corresponds to one header match in sorted order.
-	var root *rlsconfv3.RateLimitDescriptor
-	var leaf *rlsconfv3.RateLimitDescriptor
+	// Each level corresponds to one header match in sorted order, followed by
+	// the levels of each source CIDR match.
+	var chain []*rlsconfv3.RateLimitDescriptor
 	for mIdx, header := range allHeaders {
 		key := BucketRuleDescriptorKey(ruleIndex, mIdx, header.Name, headerMatchValue(header))
 		desc := &rlsconfv3.RateLimitDescriptor{Key: key}
 		if header.Type == nil || *header.Type != egv1a1.HeaderMatchDistinct {
 			desc.Value = key
 		}
-		if root == nil {
-			root = desc
-		} else {
-			leaf.Descriptors = []*rlsconfv3.RateLimitDescriptor{desc}
-		}
-		leaf = desc
+		chain = append(chain, desc)
+	}
+	for i, cidr := range cidrs {
+		chain = append(chain, sourceCIDRDescriptors(ruleIndex, len(allHeaders)+i, cidr)...)
+	}
+	for i := 1; i < len(chain); i++ {
+		chain[i-1].Descriptors = []*rlsconfv3.RateLimitDescriptor{chain[i]}
 	}
+	leaf := chain[len(chain)-1]
 	leaf.RateLimit = policy
 	leaf.ShadowMode = shadowMode
 	leaf.QuotaMode = true
 
-	return []*rlsconfv3.RateLimitDescriptor{root}, nil
+	return []*rlsconfv3.RateLimitDescriptor{chain[0]}, nil
+}
+
+// sourceCIDRDescriptors returns the chain of descriptors for a source CIDR match.
+//
+// The masked_remote_address descriptor key is fixed by Envoy and therefore not unique
+// per rule, so the chain starts with a GenericKey level keyed by the BucketRuleDescriptorKey.
+// It is followed by a masked_remote_address level whose value is the CIDR itself: Envoy sends
+// the client address masked with the CIDR prefix length, which only equals the CIDR when the
+// client is within it. Distinct matches add a key-only level for the full client address so
+// that each client address gets its own bucket.
+func sourceCIDRDescriptors(ruleIndex, matchIndex int, cidr SourceCIDRMatch) []*rlsconfv3.RateLimitDescriptor {
+	key := cidr.DescriptorKey(ruleIndex, matchIndex)
+	descs := []*rlsconfv3.RateLimitDescriptor{
+		{Key: key, Value: key},
+		{Key: MaskedRemoteAddressDescriptorKey, Value: cidr.Prefix.String()},
+	}
+	if cidr.Distinct {
+		descs = append(descs, &rlsconfv3.RateLimitDescriptor{Key: MaskedRemoteAddressDescriptorKey})
+	}
+	return descs
 }
 
 // headerMatchValue returns the value to include in a BucketRuleDescriptorKey for a header.
@@ -376,19 +429,79 @@ func headerMatchValue(header egv1a1.HeaderMatch) string {
 	return ""
 }
 
-// flattenAndSortHeaders collects all HeaderMatch entries from all ClientSelectors
-// and sorts them by header Name for deterministic descriptor nesting order.
-func flattenAndSortHeaders(selectors []egv1a1.RateLimitSelectCondition) []egv1a1.HeaderMatch {
+// QuotaRuleHeaderMatches returns all header matches of the quota rule sorted by header Name
+// for deterministic descriptor nesting order. These include the HeaderMatch entries from all
+// ClientSelectors, as well as the JWT claim and CEL selectors which are matched on the internal
+// headers set by the external processor.
+func QuotaRuleHeaderMatches(rule *aigv1a1.QuotaRule) []egv1a1.HeaderMatch {
 	var headers []egv1a1.HeaderMatch
-	for _, sel := range selectors {
+	for _, sel := range rule.ClientSelectors {
 		headers = append(headers, sel.Headers...)
 	}
-	sort.Slice(headers, func(i, j int) bool {
+	for _, sel := range rule.JWTClaimSelectors {
+		headers = append(headers, egv1a1.HeaderMatch{
+			Name:   quotaselector.ClaimHeaderName(sel.Name),
+			Type:   sel.Type,
+			Value:  sel.Value,
+			Invert: sel.Invert,
+		})
+	}
+	for _, sel := range rule.CELSelectors {
+		header := egv1a1.HeaderMatch{Name: quotaselector.CELHeaderName(sel.Expression)}
+		if sel.Type != nil && *sel.Type == aigv1a1.QuotaCELSelectorTypeDistinct {
+			distinct := egv1a1.HeaderMatchDistinct
+			header.Type = &distinct
+		} else {
+			exact, value := egv1a1.HeaderMatchExact, "true"
+			header.Type, header.Value = &exact, &value
+		}
+		headers = append(headers, header)
+	}
+	sort.SliceStable(headers, func(i, j int) bool {
 		return headers[i].Name < headers[j].Name
 	})
 	return headers
 }
 
+// SourceCIDRMatch is a source CIDR client selector of a quota rule.
+type SourceCIDRMatch struct {
+	// Prefix is the CIDR with the host bits masked out.
+	Prefix netip.Prefix
+	// Distinct is true when each client address within the CIDR gets its own bucket.
+	Distinct bool
+}
+
+// DescriptorKey returns the BucketRuleDescriptorKey of the rule-specific level of the match.
+func (m SourceCIDRMatch) DescriptorKey(ruleIndex, matchIndex int) string {
+	return BucketRuleDescriptorKey(ruleIndex, matchIndex, MaskedRemoteAddressDescriptorKey, m.Prefix.String())
+}
+
+// QuotaRuleSourceCIDRMatches returns the source CIDR matches from all ClientSelectors of the
+// quota rule, sorted by CIDR. Inverted matches are not supported and result in an error.
+func QuotaRuleSourceCIDRMatches(rule *aigv1a1.QuotaRule) ([]SourceCIDRMatch, error) {
+	var matches []SourceCIDRMatch
+	for _, sel := range rule.ClientSelectors {
+		if sel.SourceCIDR == nil {
+			continue
+		}
+		if sel.SourceCIDR.Invert != nil && *sel.SourceCIDR.Invert {
+			return nil, errors.New("inverted source CIDR matches are not supported")
+		}
+		prefix, err := netip.ParsePrefix(sel.SourceCIDR.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source CIDR %q: %w", sel.SourceCIDR.Value, err)
+		}
+		matches = append(matches, SourceCIDRMatch{
+			Prefix:   prefix.Masked(),
+			Distinct: sel.SourceCIDR.Type != nil && *sel.SourceCIDR.Type == egv1a1.SourceMatchDistinct,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Prefix.String() < matches[j].Prefix.String()
+	})
+	return matches, nil
+}
+
 func quotaValueToPolicy(qv *aigv1a1.QuotaValue) (*rlsconfv3.RateLimitPolicy, error) {
 	unit, err := parseDuration(qv.Duration)
 	if err != nil {
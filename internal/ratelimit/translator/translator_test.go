@@ -6,6 +6,7 @@
 package translator
 
 import (
+	"slices"
 	"testing"
 
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
@@ -16,6 +17,7 @@ import (
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
+	"github.com/envoyproxy/ai-gateway/internal/quotaselector"
 )
 
 func TestBackendDomainValue(t *testing.T) {
@@ -465,6 +467,121 @@ func TestBuildBucketRuleDescriptors(t *testing.T) {
 		require.Len(t, descs, 1)
 		require.Equal(t, "rule-5-match-0", descs[0].Key)
 	})
+
+	t.Run("jwt claim and cel selectors are sorted with headers", func(t *testing.T) {
+		distinct := egv1a1.HeaderMatchDistinct
+		celDistinct := aigv1a1.QuotaCELSelectorTypeDistinct
+		rule := &aigv1a1.QuotaRule{
+			ClientSelectors: []egv1a1.RateLimitSelectCondition{
+				{Headers: []egv1a1.HeaderMatch{{Name: "x-org", Value: ptr.To("acme")}}},
+			},
+			JWTClaimSelectors: []aigv1a1.QuotaJWTClaimSelector{
+				{Name: "sub", Type: &distinct},
+				{Name: "tier", Value: ptr.To("free")},
+			},
+			CELSelectors: []aigv1a1.QuotaCELSelector{
+				{Expression: `request.model == "gpt-4o"`},
+				{Expression: `request.headers["x-team"]`, Type: &celDistinct},
+			},
+			Quota: aigv1a1.QuotaValue{Limit: 100, Duration: "1m"},
+		}
+		headers := QuotaRuleHeaderMatches(rule)
+		names := make([]string, 0, len(headers))
+		for _, h := range headers {
+			names = append(names, h.Name)
+		}
+		require.IsIncreasing(t, names)
+		require.Len(t, names, 5)
+
+		descs, err := buildBucketRuleDescriptors(0, rule)
+		require.NoError(t, err)
+		require.Len(t, descs, 1)
+		var keys []string
+		for d := descs[0]; d != nil; {
+			keys = append(keys, d.Key)
+			if len(d.Descriptors) == 0 {
+				require.NotNil(t, d.RateLimit)
+				break
+			}
+			d = d.Descriptors[0]
+		}
+		matchCEL := quotaselector.CELHeaderName(`request.model == "gpt-4o"`)
+		teamCEL := quotaselector.CELHeaderName(`request.headers["x-team"]`)
+		require.Contains(t, keys, BucketRuleDescriptorKey(0, slices.Index(names, matchCEL), matchCEL, "true"))
+		require.Contains(t, keys, BucketRuleDescriptorKey(0, slices.Index(names, teamCEL), teamCEL, ""))
+		require.Contains(t, keys, BucketRuleDescriptorKey(0, slices.Index(names, "x-ai-eg-quota-claim-sub"), "x-ai-eg-quota-claim-sub", ""))
+		require.Contains(t, keys, BucketRuleDescriptorKey(0, slices.Index(names, "x-ai-eg-quota-claim-tier"), "x-ai-eg-quota-claim-tier", "free"))
+		require.Equal(t, BucketRuleDescriptorKey(0, 4, "x-org", "acme"), keys[4])
+	})
+
+	t.Run("invalid cel selector", func(t *testing.T) {
+		rule := &aigv1a1.QuotaRule{
+			CELSelectors: []aigv1a1.QuotaCELSelector{{Expression: "request.model =="}},
+			Quota:        aigv1a1.QuotaValue{Limit: 100, Duration: "1m"},
+		}
+		_, err := buildBucketRuleDescriptors(0, rule)
+		require.ErrorContains(t, err, "invalid CEL selector")
+	})
+
+	t.Run("source cidr after headers", func(t *testing.T) {
+		distinct := egv1a1.SourceMatchDistinct
+		rule := &aigv1a1.QuotaRule{
+			ClientSelectors: []egv1a1.RateLimitSelectCondition{
+				{
+					Headers:    []egv1a1.HeaderMatch{{Name: "x-org", Value: ptr.To("acme")}},
+					SourceCIDR: &egv1a1.SourceMatch{Value: "10.1.2.3/16"},
+				},
+				{SourceCIDR: &egv1a1.SourceMatch{Value: "192.168.0.0/24", Type: &distinct}},
+			},
+			Quota: aigv1a1.QuotaValue{Limit: 100, Duration: "1m"},
+		}
+		descs, err := buildBucketRuleDescriptors(0, rule)
+		require.NoError(t, err)
+		require.Len(t, descs, 1)
+
+		d := descs[0]
+		require.Equal(t, BucketRuleDescriptorKey(0, 0, "x-org", "acme"), d.Key)
+		d = d.Descriptors[0]
+		require.Equal(t, BucketRuleDescriptorKey(0, 1, MaskedRemoteAddressDescriptorKey, "10.1.0.0/16"), d.Key)
+		require.Equal(t, d.Key, d.Value)
+		d = d.Descriptors[0]
+		require.Equal(t, MaskedRemoteAddressDescriptorKey, d.Key)
+		require.Equal(t, "10.1.0.0/16", d.Value)
+		require.Nil(t, d.RateLimit)
+		d = d.Descriptors[0]
+		require.Equal(t, BucketRuleDescriptorKey(0, 2, MaskedRemoteAddressDescriptorKey, "192.168.0.0/24"), d.Key)
+		d = d.Descriptors[0]
+		require.Equal(t, MaskedRemoteAddressDescriptorKey, d.Key)
+		require.Equal(t, "192.168.0.0/24", d.Value)
+		d = d.Descriptors[0]
+		require.Equal(t, MaskedRemoteAddressDescriptorKey, d.Key)
+		require.Empty(t, d.Value)
+		require.NotNil(t, d.RateLimit)
+		require.True(t, d.QuotaMode)
+		require.Nil(t, d.Descriptors)
+	})
+
+	t.Run("inverted source cidr", func(t *testing.T) {
+		rule := &aigv1a1.QuotaRule{
+			ClientSelectors: []egv1a1.RateLimitSelectCondition{
+				{SourceCIDR: &egv1a1.SourceMatch{Value: "10.0.0.0/8", Invert: ptr.To(true)}},
+			},
+			Quota: aigv1a1.QuotaValue{Limit: 100, Duration: "1m"},
+		}
+		_, err := buildBucketRuleDescriptors(0, rule)
+		require.ErrorContains(t, err, "inverted source CIDR matches are not supported")
+	})
+
+	t.Run("invalid source cidr", func(t *testing.T) {
+		rule := &aigv1a1.QuotaRule{
+			ClientSelectors: []egv1a1.RateLimitSelectCondition{
+				{SourceCIDR: &egv1a1.SourceMatch{Value: "10.0.0.0"}},
+			},
+			Quota: aigv1a1.QuotaValue{Limit: 100, Duration: "1m"},
+		}
+		_, err := buildBucketRuleDescriptors(0, rule)
+		require.ErrorContains(t, err, `invalid source CIDR "10.0.0.0"`)
+	})
 }
 
 func TestBuildBackendDescriptor(t *testing.T) {
@@ -0,0 +1,47 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package semaphore
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/limitbackend"
+)
+
+// Config configures the Semaphore.
+type Config struct {
+	limitbackend.Config
+	// LeaseTTL is the time after which a slot held by a replica that stopped renewing it is released
+	// for limitbackend.TypeRedis.
+	LeaseTTL time.Duration
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	errs := []error{c.Config.Validate()}
+	if c.Backend == limitbackend.TypeRedis && c.LeaseTTL <= 0 {
+		errs = append(errs, fmt.Errorf("lease TTL must be positive, got %s", c.LeaseTTL))
+	}
+	return errors.Join(errs...)
+}
+
+// New creates the Semaphore described by the configuration.
+func New(logger *slog.Logger, cfg Config) (Semaphore, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Backend == limitbackend.TypeRedis {
+		client, err := cfg.NewRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewRedis(logger, client, cfg.LeaseTTL), nil
+	}
+	return NewLocal(), nil
+}
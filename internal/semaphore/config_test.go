@@ -0,0 +1,76 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package semaphore
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/limitbackend"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	redisBackend := func(url string) limitbackend.Config {
+		return limitbackend.Config{Backend: limitbackend.TypeRedis, RedisURL: url}
+	}
+	for _, tc := range []struct {
+		name   string
+		cfg    Config
+		errMsg string
+	}{
+		{name: "local", cfg: Config{Config: limitbackend.Config{Backend: limitbackend.TypeLocal}}},
+		{name: "redis", cfg: Config{Config: redisBackend("redis://localhost:6379/0"), LeaseTTL: time.Second}},
+		{
+			name:   "redis without URL",
+			cfg:    Config{Config: redisBackend(""), LeaseTTL: time.Second},
+			errMsg: `a Redis URL is required for the "redis" backend`,
+		},
+		{
+			name:   "redis without lease TTL",
+			cfg:    Config{Config: redisBackend("redis://localhost:6379")},
+			errMsg: "lease TTL must be positive, got 0s",
+		},
+		{
+			name:   "unknown",
+			cfg:    Config{Config: limitbackend.Config{Backend: "memcached"}},
+			errMsg: `unknown backend "memcached": must be one of local or redis`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	s, err := New(logger, Config{Config: limitbackend.Config{Backend: limitbackend.TypeLocal}})
+	require.NoError(t, err)
+	require.IsType(t, &local{}, s)
+
+	mr := miniredis.RunT(t)
+	s, err = New(logger, Config{
+		Config:   limitbackend.Config{Backend: limitbackend.TypeRedis, RedisURL: "redis://" + mr.Addr()},
+		LeaseTTL: time.Second,
+	})
+	require.NoError(t, err)
+	require.IsType(t, &redisSemaphore{}, s)
+	require.NoError(t, s.Close())
+
+	_, err = New(logger, Config{Config: limitbackend.Config{Backend: "unknown"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,166 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisKeyPrefix is the prefix of the Redis keys of the semaphores.
+	redisKeyPrefix = "ai-gateway:concurrency:"
+	// redisOperationTimeout bounds the Redis calls made on release and lease renewal, which
+	// are not tied to a request context.
+	redisOperationTimeout = 5 * time.Second
+)
+
+// acquireScript adds a lease to the sorted set of the semaphore when fewer than ARGV[1] unexpired leases
+// are held. Each lease is scored by its expiry time in milliseconds, so that the leases of the replicas
+// that died without releasing them are eventually evicted. The Redis server time is used so that the
+// clocks of the replicas do not need to be synchronized.
+var acquireScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[1]) then
+  return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// renewScript extends the expiry of the leases ARGV[2:] that are still held by ARGV[1] milliseconds.
+var renewScript = redis.NewScript(`
+local t = redis.call('TIME')
+local expiry = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000) + tonumber(ARGV[1])
+for i = 2, #ARGV do
+  redis.call('ZADD', KEYS[1], 'XX', expiry, ARGV[i])
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 0
+`)
+
+// NewRedis returns a Semaphore shared by all the replicas connected to the same Redis.
+//
+// Each acquired slot is a lease that expires after leaseTTL unless it is renewed. The leases held by this
+// process are renewed in the background every third of leaseTTL until they are released or Close is called.
+func NewRedis(logger *slog.Logger, client redis.UniversalClient, leaseTTL time.Duration) Semaphore {
+	r := &redisSemaphore{
+		logger:   logger,
+		client:   client,
+		leaseTTL: leaseTTL,
+		leases:   make(map[string]map[string]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go r.renewLoop()
+	return r
+}
+
+type redisSemaphore struct {
+	logger   *slog.Logger
+	client   redis.UniversalClient
+	leaseTTL time.Duration
+
+	mu sync.Mutex
+	// leases are the IDs of the leases held by this process keyed by the Redis key.
+	leases map[string]map[string]struct{}
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// TryAcquire implements [Semaphore.TryAcquire].
+func (r *redisSemaphore) TryAcquire(ctx context.Context, key string, limit uint32) (func(), error) {
+	redisKey := redisKeyPrefix + key
+	leaseID := uuid.NewString()
+	acquired, err := acquireScript.Run(ctx, r.client, []string{redisKey}, limit, r.leaseTTL.Milliseconds(), leaseID).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire semaphore %s: %w", key, err)
+	}
+	if acquired == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	if r.leases[redisKey] == nil {
+		r.leases[redisKey] = make(map[string]struct{})
+	}
+	r.leases[redisKey][leaseID] = struct{}{}
+	r.mu.Unlock()
+
+	return releaseOnce(func() {
+		r.mu.Lock()
+		delete(r.leases[redisKey], leaseID)
+		if len(r.leases[redisKey]) == 0 {
+			delete(r.leases, redisKey)
+		}
+		r.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+		defer cancel()
+		if err := r.client.ZRem(ctx, redisKey, leaseID).Err(); err != nil {
+			// The lease will expire after leaseTTL since it is no longer renewed.
+			r.logger.Warn("failed to release semaphore lease", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}), nil
+}
+
+// renewLoop periodically extends the expiry of the held leases.
+func (r *redisSemaphore) renewLoop() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.renew()
+		}
+	}
+}
+
+func (r *redisSemaphore) renew() {
+	r.mu.Lock()
+	leases := make(map[string][]any, len(r.leases))
+	for redisKey, ids := range r.leases {
+		args := []any{r.leaseTTL.Milliseconds()}
+		for _, id := range slices.Sorted(maps.Keys(ids)) {
+			args = append(args, id)
+		}
+		leases[redisKey] = args
+	}
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+	for redisKey, args := range leases {
+		if err := renewScript.Run(ctx, r.client, []string{redisKey}, args...).Err(); err != nil {
+			r.logger.Warn("failed to renew semaphore leases", slog.String("key", redisKey), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Close implements [Semaphore.Close].
+//
+// The leases still held are not released: they expire after the lease TTL.
+func (r *redisSemaphore) Close() error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+	return r.client.Close()
+}
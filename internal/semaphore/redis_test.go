@@ -0,0 +1,104 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package semaphore
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisSemaphore(t *testing.T, mr *miniredis.Miniredis, leaseTTL time.Duration) *redisSemaphore {
+	s := NewRedis(slog.New(slog.NewTextHandler(io.Discard, nil)), redis.NewClient(&redis.Options{Addr: mr.Addr()}), leaseTTL)
+	t.Cleanup(func() { _ = s.Close() })
+	return s.(*redisSemaphore)
+}
+
+func TestRedis_TryAcquire(t *testing.T) {
+	mr := miniredis.RunT(t)
+	// Two replicas sharing the same Redis.
+	s1 := newTestRedisSemaphore(t, mr, time.Minute)
+	s2 := newTestRedisSemaphore(t, mr, time.Minute)
+
+	release1, err := s1.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release1)
+	release2, err := s2.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release2)
+
+	release, err := s1.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.Nil(t, release)
+	members, err := mr.ZMembers(redisKeyPrefix + "a")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// Releasing on one replica frees the slot for the other one.
+	release1()
+	release1()
+	release3, err := s2.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release3)
+
+	release2()
+	release3()
+	require.False(t, mr.Exists(redisKeyPrefix+"a"))
+	require.Empty(t, s1.leases)
+	require.Empty(t, s2.leases)
+}
+
+func TestRedis_ExpiredLeasesAreEvicted(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestRedisSemaphore(t, mr, time.Minute)
+
+	// Simulate a lease left behind by a replica that died without releasing it.
+	_, err := mr.ZAdd(redisKeyPrefix+"a", float64(time.Now().Add(-time.Second).UnixMilli()), "stale")
+	require.NoError(t, err)
+
+	release, err := s.TryAcquire(t.Context(), "a", 1)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	members, err := mr.ZMembers(redisKeyPrefix + "a")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.NotEqual(t, "stale", members[0])
+}
+
+func TestRedis_RenewsLeases(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestRedisSemaphore(t, mr, 300*time.Millisecond)
+
+	release, err := s.TryAcquire(t.Context(), "a", 1)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	members, err := mr.ZMembers(redisKeyPrefix + "a")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	initial, err := mr.ZScore(redisKeyPrefix+"a", members[0])
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		score, err := mr.ZScore(redisKeyPrefix+"a", members[0])
+		return err == nil && score > initial
+	}, 5*time.Second, 50*time.Millisecond)
+	release()
+}
+
+func TestRedis_TryAcquire_Error(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestRedisSemaphore(t, mr, time.Minute)
+	mr.Close()
+
+	release, err := s.TryAcquire(t.Context(), "a", 1)
+	require.ErrorContains(t, err, "failed to acquire semaphore a")
+	require.Nil(t, release)
+}
@@ -0,0 +1,61 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package semaphore provides counting semaphores keyed by an arbitrary string, used to enforce
+// the QuotaPolicy concurrent request limits.
+package semaphore
+
+import (
+	"context"
+	"sync"
+)
+
+// Semaphore is a set of counting semaphores identified by key.
+type Semaphore interface {
+	// TryAcquire acquires a slot of the semaphore identified by key without blocking, given that at most
+	// limit slots can be held at the same time.
+	//
+	// It returns a non-nil release function when a slot was acquired, and nil when the limit is reached.
+	// The release function is safe to call multiple times and from multiple goroutines.
+	TryAcquire(ctx context.Context, key string, limit uint32) (release func(), err error)
+	// Close releases the resources held by the Semaphore.
+	Close() error
+}
+
+// NewLocal returns an in-memory Semaphore. The limits are only enforced within the current process.
+func NewLocal() Semaphore {
+	return &local{counts: make(map[string]uint32)}
+}
+
+type local struct {
+	mu     sync.Mutex
+	counts map[string]uint32
+}
+
+// TryAcquire implements [Semaphore.TryAcquire].
+func (l *local) TryAcquire(_ context.Context, key string, limit uint32) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.counts[key] >= limit {
+		return nil, nil
+	}
+	l.counts[key]++
+	return releaseOnce(func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.counts[key]--; l.counts[key] == 0 {
+			delete(l.counts, key)
+		}
+	}), nil
+}
+
+// Close implements [Semaphore.Close].
+func (l *local) Close() error { return nil }
+
+// releaseOnce wraps the release function so that the slot is only released once.
+func releaseOnce(release func()) func() {
+	var once sync.Once
+	return func() { once.Do(release) }
+}
@@ -0,0 +1,76 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package semaphore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_TryAcquire(t *testing.T) {
+	s := NewLocal()
+	t.Cleanup(func() { require.NoError(t, s.Close()) })
+
+	release1, err := s.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release1)
+	release2, err := s.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release2)
+
+	// The limit is reached for "a" but not for "b".
+	release, err := s.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.Nil(t, release)
+	releaseB, err := s.TryAcquire(t.Context(), "b", 1)
+	require.NoError(t, err)
+	require.NotNil(t, releaseB)
+
+	// Releasing twice only frees a single slot.
+	release1()
+	release1()
+	release3, err := s.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release3)
+	release, err = s.TryAcquire(t.Context(), "a", 2)
+	require.NoError(t, err)
+	require.Nil(t, release)
+
+	release2()
+	release3()
+	releaseB()
+	require.Empty(t, s.(*local).counts)
+}
+
+func TestLocal_TryAcquire_Concurrent(t *testing.T) {
+	s := NewLocal()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		releases []func()
+	)
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := s.TryAcquire(t.Context(), "key", 10)
+			require.NoError(t, err)
+			if release != nil {
+				mu.Lock()
+				releases = append(releases, release)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Len(t, releases, 10)
+	for _, release := range releases {
+		release()
+	}
+	require.Empty(t, s.(*local).counts)
+}
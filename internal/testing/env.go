@@ -16,6 +16,13 @@ func ClearTestEnv(t testing.TB) {
 		"AZURE_OPENAI_API_KEY",
 		"ANTHROPIC_API_KEY",
 		"ANTHROPIC_BASE_URL",
+		"AWS_PROFILE",
+		"AWS_ACCESS_KEY_ID",
+		"AWS_REGION",
+		"AWS_DEFAULT_REGION",
+		"GOOGLE_APPLICATION_CREDENTIALS",
+		"GOOGLE_CLOUD_PROJECT",
+		"GOOGLE_CLOUD_LOCATION",
 		"OTEL_EXPORTER_OTLP_ENDPOINT",
 		"OTEL_EXPORTER_OTLP_PROTOCOL",
 		"OTEL_EXPORTER_OTLP_HEADERS",
@@ -6,6 +6,8 @@
 package testotel
 
 import (
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 )
 
@@ -16,6 +18,13 @@ type MockSpan struct {
 	ErrorStatus   int
 	ErrBody       string
 	EndSpanCalled bool
+	Events        []MockSpanEvent
+}
+
+// MockSpanEvent is an event recorded by MockSpan.
+type MockSpanEvent struct {
+	Name  string
+	Attrs []attribute.KeyValue
 }
 
 // RecordResponseChunk implements tracingapi.ChatCompletionSpan.
@@ -38,3 +47,8 @@ func (s *MockSpan) EndSpanOnError(statusCode int, body []byte) {
 func (s *MockSpan) EndSpan() {
 	s.EndSpanCalled = true
 }
+
+// RecordEvent implements tracingapi.SpanEventRecorder.
+func (s *MockSpan) RecordEvent(name string, attrs ...attribute.KeyValue) {
+	s.Events = append(s.Events, MockSpanEvent{Name: name, Attrs: attrs})
+}
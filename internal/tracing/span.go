@@ -6,6 +6,7 @@
 package tracing
 
 import (
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
 	anthropicschema "github.com/envoyproxy/ai-gateway/internal/apischema/anthropic"
@@ -44,6 +45,11 @@ func (s *span[RespT, ChunkT]) EndSpanOnError(statusCode int, body []byte) {
 	s.span.End()
 }
 
+// RecordEvent implements [tracingapi.SpanEventRecorder.RecordEvent]
+func (s *span[RespT, ChunkT]) RecordEvent(name string, attrs ...attribute.KeyValue) {
+	s.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
 // Type aliases tying generic implementations to concrete recorder contracts.
 type (
 	chatCompletionSpan  = span[openai.ChatCompletionResponse, openai.ChatCompletionResponseChunk]
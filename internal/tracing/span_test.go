@@ -15,6 +15,7 @@ import (
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 	"github.com/envoyproxy/ai-gateway/internal/json"
 	"github.com/envoyproxy/ai-gateway/internal/testing/testotel"
+	"github.com/envoyproxy/ai-gateway/internal/tracing/tracingapi"
 )
 
 func TestChatCompletionSpan_RecordResponseChunk(t *testing.T) {
@@ -74,6 +75,20 @@ func TestChatCompletionSpan_EndSpan(t *testing.T) {
 	}, actualSpan.Attributes)
 }
 
+func TestChatCompletionSpan_RecordEvent(t *testing.T) {
+	s := &chatCompletionSpan{}
+	require.Implements(t, (*tracingapi.SpanEventRecorder)(nil), s)
+	actualSpan := testotel.RecordWithSpan(t, func(span oteltrace.Span) bool {
+		s.span = span
+		s.RecordEvent("admission", attribute.String("decision", "queued"))
+		return false // Recording of events shouldn't end the span.
+	})
+
+	require.Len(t, actualSpan.Events, 1)
+	require.Equal(t, "admission", actualSpan.Events[0].Name)
+	require.Equal(t, []attribute.KeyValue{attribute.String("decision", "queued")}, actualSpan.Events[0].Attributes)
+}
+
 func TestEmbeddingsSpan_EndSpanOnError(t *testing.T) {
 	msg := "embeddings error occurred"
 	actualSpan := testotel.RecordWithSpan(t, func(span oteltrace.Span) bool {
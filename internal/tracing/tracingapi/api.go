@@ -10,6 +10,7 @@ package tracingapi
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
@@ -100,6 +101,12 @@ type (
 		// EndSpan finalizes and ends the span.
 		EndSpan()
 	}
+	// SpanEventRecorder is optionally implemented by a [Span] to record the events that happen
+	// while the request is processed, such as the admission decision of the backend.
+	SpanEventRecorder interface {
+		// RecordEvent adds an event with the given name and attributes to the span.
+		RecordEvent(name string, attrs ...attribute.KeyValue)
+	}
 	// ChatCompletionSpan represents an OpenAI chat completion.
 	ChatCompletionSpan = Span[openai.ChatCompletionResponse, openai.ChatCompletionResponseChunk]
 	// CompletionSpan represents an OpenAI completion request.
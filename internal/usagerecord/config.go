@@ -0,0 +1,117 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SinkType is the kind of destination of usage records.
+type SinkType string
+
+const (
+	// SinkTypeNone disables usage records.
+	SinkTypeNone SinkType = ""
+	// SinkTypeFile writes usage records as JSON lines to a rotated local file.
+	SinkTypeFile SinkType = "file"
+	// SinkTypeOTLP emits usage records as OpenTelemetry log records.
+	SinkTypeOTLP SinkType = "otlp"
+	// SinkTypeWebhook POSTs batches of usage records to an HTTP endpoint.
+	SinkTypeWebhook SinkType = "webhook"
+)
+
+// Config configures the usage record Exporter.
+type Config struct {
+	// Sink is the type of the sink. SinkTypeNone disables usage records.
+	Sink SinkType
+	// FilePath is the path of the JSONL file for SinkTypeFile.
+	FilePath string
+	// FileMaxSizeBytes is the size at which the file is rotated for SinkTypeFile. Zero disables rotation.
+	FileMaxSizeBytes int64
+	// FileMaxBackups is the number of rotated files to keep for SinkTypeFile.
+	FileMaxBackups int
+	// WebhookURL is the endpoint for SinkTypeWebhook.
+	WebhookURL string
+	// WebhookMaxRetries is the number of retries of a failed batch for SinkTypeWebhook.
+	WebhookMaxRetries int
+	// BatchSize is the maximum number of records written to the sink at once.
+	BatchSize int
+	// BufferSize is the number of records buffered before new records are dropped.
+	BufferSize int
+	// FlushInterval is the maximum time a record is buffered before it is written to the sink.
+	FlushInterval time.Duration
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	var errs []error
+	switch c.Sink {
+	case SinkTypeNone:
+		return nil
+	case SinkTypeFile:
+		if c.FilePath == "" {
+			errs = append(errs, fmt.Errorf("a file path is required for the %q usage record sink", c.Sink))
+		}
+		if c.FileMaxSizeBytes < 0 {
+			errs = append(errs, fmt.Errorf("usage record file max size must be non-negative, got %d", c.FileMaxSizeBytes))
+		}
+		if c.FileMaxBackups < 0 {
+			errs = append(errs, fmt.Errorf("usage record file max backups must be non-negative, got %d", c.FileMaxBackups))
+		}
+	case SinkTypeOTLP:
+	case SinkTypeWebhook:
+		if c.WebhookURL == "" {
+			errs = append(errs, fmt.Errorf("a webhook URL is required for the %q usage record sink", c.Sink))
+		}
+		if c.WebhookMaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("usage record webhook max retries must be non-negative, got %d", c.WebhookMaxRetries))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown usage record sink %q: must be one of file, otlp or webhook", c.Sink))
+	}
+	if c.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("usage record batch size must be positive, got %d", c.BatchSize))
+	}
+	if c.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("usage record buffer size must be positive, got %d", c.BufferSize))
+	}
+	if c.FlushInterval <= 0 {
+		errs = append(errs, fmt.Errorf("usage record flush interval must be positive, got %s", c.FlushInterval))
+	}
+	return errors.Join(errs...)
+}
+
+// NewExporter creates the Exporter described by the configuration.
+// It returns nil without an error when usage records are disabled.
+func NewExporter(ctx context.Context, logger *slog.Logger, cfg Config) (Exporter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var sink Sink
+	switch cfg.Sink {
+	case SinkTypeNone:
+		return nil, nil
+	case SinkTypeFile:
+		s, err := NewFileSink(cfg.FilePath, cfg.FileMaxSizeBytes, cfg.FileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case SinkTypeOTLP:
+		s, err := NewOTLPSink(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sink = s
+	case SinkTypeWebhook:
+		sink = NewWebhookSink(cfg.WebhookURL, cfg.WebhookMaxRetries)
+	}
+	return NewBatchExporter(logger, sink, cfg.BatchSize, cfg.BufferSize, cfg.FlushInterval), nil
+}
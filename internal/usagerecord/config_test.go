@@ -0,0 +1,107 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func validConfig(sink SinkType) Config {
+	return Config{Sink: sink, BatchSize: 10, BufferSize: 100, FlushInterval: time.Second}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		expectErr string
+	}{
+		{name: "disabled", cfg: Config{}},
+		{name: "file", cfg: func() Config { c := validConfig(SinkTypeFile); c.FilePath = "/tmp/usage.jsonl"; return c }()},
+		{name: "file without path", cfg: validConfig(SinkTypeFile), expectErr: `a file path is required for the "file" usage record sink`},
+		{
+			name:      "file negative size",
+			cfg:       func() Config { c := validConfig(SinkTypeFile); c.FilePath = "a"; c.FileMaxSizeBytes = -1; return c }(),
+			expectErr: "usage record file max size must be non-negative, got -1",
+		},
+		{
+			name:      "file negative backups",
+			cfg:       func() Config { c := validConfig(SinkTypeFile); c.FilePath = "a"; c.FileMaxBackups = -1; return c }(),
+			expectErr: "usage record file max backups must be non-negative, got -1",
+		},
+		{name: "otlp", cfg: validConfig(SinkTypeOTLP)},
+		{name: "webhook", cfg: func() Config { c := validConfig(SinkTypeWebhook); c.WebhookURL = "http://localhost"; return c }()},
+		{name: "webhook without url", cfg: validConfig(SinkTypeWebhook), expectErr: `a webhook URL is required for the "webhook" usage record sink`},
+		{
+			name: "webhook negative retries",
+			cfg: func() Config {
+				c := validConfig(SinkTypeWebhook)
+				c.WebhookURL = "http://localhost"
+				c.WebhookMaxRetries = -1
+				return c
+			}(),
+			expectErr: "usage record webhook max retries must be non-negative, got -1",
+		},
+		{name: "unknown sink", cfg: validConfig("kafka"), expectErr: `unknown usage record sink "kafka": must be one of file, otlp or webhook`},
+		{
+			name:      "invalid batching",
+			cfg:       Config{Sink: SinkTypeOTLP},
+			expectErr: "usage record batch size must be positive, got 0\nusage record buffer size must be positive, got 0\nusage record flush interval must be positive, got 0s",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectErr != "" {
+				require.EqualError(t, err, tc.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		e, err := NewExporter(t.Context(), slog.Default(), Config{})
+		require.NoError(t, err)
+		require.Nil(t, e)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewExporter(t.Context(), slog.Default(), validConfig(SinkTypeFile))
+		require.ErrorContains(t, err, "a file path is required")
+	})
+	t.Run("file", func(t *testing.T) {
+		cfg := validConfig(SinkTypeFile)
+		cfg.FilePath = filepath.Join(t.TempDir(), "usage.jsonl")
+		e, err := NewExporter(t.Context(), slog.Default(), cfg)
+		require.NoError(t, err)
+		e.Export(Record{RequestID: "1"})
+		require.NoError(t, e.Shutdown(t.Context()))
+		require.Len(t, readRecords(t, cfg.FilePath), 1)
+	})
+	t.Run("otlp", func(t *testing.T) {
+		internaltesting.ClearTestEnv(t)
+		t.Setenv("OTEL_LOGS_EXPORTER", "none")
+		e, err := NewExporter(t.Context(), slog.Default(), validConfig(SinkTypeOTLP))
+		require.NoError(t, err)
+		require.NoError(t, e.Shutdown(t.Context()))
+	})
+	t.Run("webhook", func(t *testing.T) {
+		cfg := validConfig(SinkTypeWebhook)
+		cfg.WebhookURL = "http://127.0.0.1:1"
+		e, err := NewExporter(t.Context(), slog.Default(), cfg)
+		require.NoError(t, err)
+		require.NoError(t, e.Shutdown(t.Context()))
+	})
+}
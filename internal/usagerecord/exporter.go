@@ -0,0 +1,135 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink is the destination of usage records.
+type Sink interface {
+	// Write writes a batch of records. It is only ever called from a single goroutine, and the
+	// slice must not be retained after Write returns.
+	Write(ctx context.Context, records []Record) error
+	// Close flushes any buffered data and releases the resources held by the sink.
+	Close(ctx context.Context) error
+}
+
+// Exporter accepts usage records from the request path and delivers them to a Sink.
+type Exporter interface {
+	// Export enqueues the record for delivery. It never blocks: when the internal buffer is
+	// full, the record is dropped and counted.
+	Export(record Record)
+	// Shutdown flushes the buffered records and closes the underlying sink.
+	Shutdown(ctx context.Context) error
+}
+
+// NewBatchExporter returns an Exporter that buffers up to bufferSize records and writes them
+// to the sink in a background goroutine, either once batchSize records are pending or every
+// flushInterval, whichever comes first.
+func NewBatchExporter(logger *slog.Logger, sink Sink, batchSize, bufferSize int, flushInterval time.Duration) Exporter {
+	e := &batchExporter{
+		logger:        logger,
+		sink:          sink,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		records:       make(chan Record, bufferSize),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+type batchExporter struct {
+	logger        *slog.Logger
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+	records       chan Record
+	// stop is closed on Shutdown, and done is closed once the background goroutine has flushed everything.
+	stop, done chan struct{}
+	stopOnce   sync.Once
+	// dropped is the number of records dropped since the last flush because the buffer was full.
+	dropped atomic.Uint64
+}
+
+// Export implements [Exporter.Export].
+func (e *batchExporter) Export(record Record) {
+	select {
+	case <-e.stop:
+		e.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case e.records <- record:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Shutdown implements [Exporter.Shutdown].
+func (e *batchExporter) Shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.sink.Close(ctx)
+}
+
+func (e *batchExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, e.batchSize)
+	for {
+		select {
+		case r := <-e.records:
+			batch = append(batch, r)
+			if len(batch) >= e.batchSize {
+				batch = e.flush(batch)
+			}
+		case <-ticker.C:
+			batch = e.flush(batch)
+		case <-e.stop:
+			// Drain whatever is left in the buffer before exiting.
+			for {
+				select {
+				case r := <-e.records:
+					batch = append(batch, r)
+					if len(batch) >= e.batchSize {
+						batch = e.flush(batch)
+					}
+				default:
+					e.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes the batch to the sink and returns the emptied batch for reuse.
+func (e *batchExporter) flush(batch []Record) []Record {
+	if dropped := e.dropped.Swap(0); dropped > 0 {
+		e.logger.Warn("dropped usage records because the buffer is full", slog.Uint64("count", dropped))
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := e.sink.Write(context.Background(), batch); err != nil {
+		e.logger.Error("failed to write usage records", slog.Int("count", len(batch)), slog.String("error", err.Error()))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,137 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSink records the batches written to it.
+type fakeSink struct {
+	mu       sync.Mutex
+	batches  [][]Record
+	writeErr error
+	closed   bool
+	// block, if set, blocks Write until it is closed after signaling on writing.
+	block, writing chan struct{}
+}
+
+func (f *fakeSink) Write(_ context.Context, records []Record) error {
+	if f.block != nil {
+		if f.writing != nil {
+			f.writing <- struct{}{}
+		}
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, append([]Record(nil), records...))
+	return f.writeErr
+}
+
+func (f *fakeSink) Close(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeSink) records() []Record {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []Record
+	for _, b := range f.batches {
+		out = append(out, b...)
+	}
+	return out
+}
+
+func TestBatchExporter_FlushOnBatchSize(t *testing.T) {
+	sink := &fakeSink{}
+	e := NewBatchExporter(slog.Default(), sink, 2, 10, time.Hour)
+	e.Export(Record{RequestID: "1"})
+	e.Export(Record{RequestID: "2"})
+	require.Eventually(t, func() bool { return len(sink.records()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(t.Context()))
+	require.True(t, sink.closed)
+	require.Len(t, sink.batches, 1)
+}
+
+func TestBatchExporter_FlushOnInterval(t *testing.T) {
+	sink := &fakeSink{}
+	e := NewBatchExporter(slog.Default(), sink, 100, 10, 10*time.Millisecond)
+	e.Export(Record{RequestID: "1"})
+	require.Eventually(t, func() bool { return len(sink.records()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(t.Context()))
+}
+
+func TestBatchExporter_ShutdownDrains(t *testing.T) {
+	sink := &fakeSink{}
+	e := NewBatchExporter(slog.Default(), sink, 100, 10, time.Hour)
+	for _, id := range []string{"1", "2", "3"} {
+		e.Export(Record{RequestID: id})
+	}
+	require.NoError(t, e.Shutdown(t.Context()))
+	ids := make([]string, 0, 3)
+	for _, r := range sink.records() {
+		ids = append(ids, r.RequestID)
+	}
+	require.Equal(t, []string{"1", "2", "3"}, ids)
+
+	// Exports after shutdown are dropped without panicking.
+	e.Export(Record{RequestID: "4"})
+	require.Len(t, sink.records(), 3)
+}
+
+func TestBatchExporter_DropsWhenFull(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	sink := &fakeSink{block: make(chan struct{}), writing: make(chan struct{}, 1)}
+	e := NewBatchExporter(logger, sink, 1, 1, time.Hour)
+
+	// The first record is picked up by the background goroutine which then blocks in Write,
+	// the second one fills the buffer and the rest are dropped.
+	e.Export(Record{RequestID: "1"})
+	<-sink.writing
+	e.Export(Record{RequestID: "2"})
+	e.Export(Record{RequestID: "3"})
+	e.Export(Record{RequestID: "4"})
+	require.Equal(t, uint64(2), e.(*batchExporter).dropped.Load())
+
+	close(sink.block)
+	require.NoError(t, e.Shutdown(t.Context()))
+	require.Len(t, sink.records(), 2)
+	require.Contains(t, buf.String(), "dropped usage records because the buffer is full")
+}
+
+func TestBatchExporter_WriteError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	sink := &fakeSink{writeErr: errors.New("boom")}
+	e := NewBatchExporter(logger, sink, 1, 10, time.Hour)
+	e.Export(Record{RequestID: "1"})
+	require.NoError(t, e.Shutdown(t.Context()))
+	require.Contains(t, buf.String(), "failed to write usage records")
+	require.Contains(t, buf.String(), "boom")
+}
+
+func TestBatchExporter_ShutdownTimeout(t *testing.T) {
+	sink := &fakeSink{block: make(chan struct{})}
+	defer close(sink.block)
+	e := NewBatchExporter(slog.Default(), sink, 1, 10, time.Hour)
+	e.Export(Record{RequestID: "1"})
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, e.Shutdown(ctx), context.DeadlineExceeded)
+}
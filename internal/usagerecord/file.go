@@ -0,0 +1,136 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+// backupTimeFormat is the suffix format of rotated files. It sorts lexicographically by time.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// NewFileSink returns a Sink that appends records as JSON lines to the file at path.
+//
+// Once the file would exceed maxSizeBytes, it is renamed with a timestamp suffix and a new file
+// is started. Only the most recent maxBackups rotated files are kept.
+func NewFileSink(path string, maxSizeBytes int64, maxBackups int) (Sink, error) {
+	s := &fileSink{path: path, maxSizeBytes: maxSizeBytes, maxBackups: maxBackups, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type fileSink struct {
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	file         *os.File
+	size         int64
+	buf          bytes.Buffer
+	now          func() time.Time
+}
+
+func (s *fileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for usage records: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage record file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat usage record file: %w", err)
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+// Write implements [Sink.Write].
+func (s *fileSink) Write(_ context.Context, records []Record) error {
+	for i := range records {
+		line, err := json.Marshal(&records[i])
+		if err != nil {
+			return fmt.Errorf("failed to marshal usage record: %w", err)
+		}
+		lineSize := int64(len(line) + 1)
+		// Rotate before writing a line that would overflow the file, unless the file is empty,
+		// in which case a single oversized line is written as is.
+		if s.maxSizeBytes > 0 && s.size+int64(s.buf.Len())+lineSize > s.maxSizeBytes && s.size+int64(s.buf.Len()) > 0 {
+			if err := s.writeBuffer(); err != nil {
+				return err
+			}
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		s.buf.Write(line)
+		s.buf.WriteByte('\n')
+	}
+	return s.writeBuffer()
+}
+
+func (s *fileSink) writeBuffer() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+	n, err := s.file.Write(s.buf.Bytes())
+	s.size += int64(n)
+	s.buf.Reset()
+	if err != nil {
+		return fmt.Errorf("failed to write usage records: %w", err)
+	}
+	return nil
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close usage record file: %w", err)
+	}
+	backup := s.path + "." + s.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(s.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate usage record file: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.pruneBackups()
+}
+
+func (s *fileSink) pruneBackups() error {
+	backups, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return fmt.Errorf("failed to list rotated usage record files: %w", err)
+	}
+	if len(backups) <= s.maxBackups {
+		return nil
+	}
+	slices.Sort(backups)
+	for _, b := range backups[:len(backups)-s.maxBackups] {
+		if err := os.Remove(b); err != nil {
+			return fmt.Errorf("failed to remove rotated usage record file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close implements [Sink.Close].
+func (s *fileSink) Close(context.Context) error {
+	if err := s.writeBuffer(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
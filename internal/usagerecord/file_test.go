@@ -0,0 +1,113 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestFileSink_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "usage.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(t.Context(), []Record{{RequestID: "1"}, {RequestID: "2"}}))
+	require.NoError(t, sink.Close(t.Context()))
+
+	// Reopening appends to the existing file.
+	sink, err = NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(t.Context(), []Record{{RequestID: "3"}}))
+	require.NoError(t, sink.Close(t.Context()))
+
+	records := readRecords(t, path)
+	require.Len(t, records, 3)
+	require.Equal(t, "1", records[0].RequestID)
+	require.Equal(t, "3", records[2].RequestID)
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.jsonl")
+	line, err := json.Marshal(&Record{RequestID: "0"})
+	require.NoError(t, err)
+	lineSize := int64(len(line) + 1)
+
+	// Each file holds two records.
+	s, err := NewFileSink(path, 2*lineSize, 2)
+	require.NoError(t, err)
+	fs := s.(*fileSink)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fs.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	for _, id := range []string{"0", "1", "2", "3", "4", "5", "6"} {
+		require.NoError(t, s.Write(t.Context(), []Record{{RequestID: id}}))
+	}
+	require.NoError(t, s.Close(t.Context()))
+
+	// 7 records make 4 files, of which the oldest backup was pruned.
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		path + ".20250101T000002.000000000",
+		path + ".20250101T000003.000000000",
+	}, backups)
+	require.Len(t, readRecords(t, backups[0]), 2)
+	require.Equal(t, "2", readRecords(t, backups[0])[0].RequestID)
+	current := readRecords(t, path)
+	require.Len(t, current, 1)
+	require.Equal(t, "6", current[0].RequestID)
+}
+
+func TestFileSink_RotationWithinBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	line, err := json.Marshal(&Record{RequestID: "0"})
+	require.NoError(t, err)
+
+	s, err := NewFileSink(path, int64(len(line)+1), 5)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(t.Context(), []Record{{RequestID: "0"}, {RequestID: "1"}, {RequestID: "2"}}))
+	require.NoError(t, s.Close(t.Context()))
+
+	current := readRecords(t, path)
+	require.Len(t, current, 1)
+	require.Equal(t, "2", current[0].RequestID)
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+}
+
+func TestNewFileSink_Error(t *testing.T) {
+	dir := t.TempDir()
+	// A directory cannot be opened as the file.
+	_, err := NewFileSink(dir, 0, 0)
+	require.ErrorContains(t, err, "failed to open usage record file")
+}
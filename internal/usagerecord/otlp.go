@@ -0,0 +1,124 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.opentelemetry.io/contrib/exporters/autoexport"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	// otlpScopeName is the instrumentation scope of the emitted log records.
+	otlpScopeName = "envoyproxy/ai-gateway/usagerecord"
+	// otlpEventName is the event name of the emitted log records.
+	otlpEventName = "ai_gateway.usage_record"
+)
+
+// NewOTLPSink returns a Sink that emits each record as an OpenTelemetry log record.
+//
+// The log exporter is configured with the standard OTEL_LOGS_EXPORTER and
+// OTEL_EXPORTER_OTLP_* environment variables, defaulting to OTLP.
+func NewOTLPSink(ctx context.Context) (Sink, error) {
+	exp, err := autoexport.NewLogExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
+	}
+	envRes, err := resource.New(ctx, resource.WithFromEnv(), resource.WithTelemetrySDK())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP resource: %w", err)
+	}
+	// We hardcode "service.name" to avoid pinning semconv version.
+	res, err := resource.Merge(resource.NewSchemaless(attribute.String("service.name", "ai-gateway")), envRes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP resource: %w", err)
+	}
+	return newOTLPSink(exp, res), nil
+}
+
+func newOTLPSink(exp sdklog.Exporter, res *resource.Resource) *otlpSink {
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)),
+		sdklog.WithResource(res),
+	)
+	return &otlpSink{provider: provider, logger: provider.Logger(otlpScopeName)}
+}
+
+type otlpSink struct {
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+}
+
+// Write implements [Sink.Write].
+func (s *otlpSink) Write(ctx context.Context, records []Record) error {
+	for i := range records {
+		s.logger.Emit(ctx, toLogRecord(&records[i]))
+	}
+	return nil
+}
+
+// Close implements [Sink.Close].
+func (s *otlpSink) Close(ctx context.Context) error {
+	return s.provider.Shutdown(ctx)
+}
+
+// toLogRecord converts the record into an OpenTelemetry log record, using the GenAI semantic
+// convention attribute names where they exist.
+func toLogRecord(r *Record) otellog.Record {
+	var lr otellog.Record
+	lr.SetTimestamp(r.Timestamp)
+	lr.SetObservedTimestamp(r.Timestamp)
+	lr.SetEventName(otlpEventName)
+	lr.SetSeverity(otellog.SeverityInfo)
+	lr.SetBody(otellog.StringValue("usage record"))
+
+	attrs := []otellog.KeyValue{
+		otellog.String("request.id", r.RequestID),
+		otellog.String("ai_gateway.route.name", r.RouteName),
+		otellog.String("ai_gateway.backend.name", r.BackendName),
+		otellog.String("ai_gateway.original.model", r.OriginalModel),
+		otellog.String("gen_ai.request.model", r.RequestModel),
+		otellog.String("gen_ai.response.model", r.ResponseModel),
+		otellog.Bool("ai_gateway.stream", r.Stream),
+		otellog.Int64("ai_gateway.latency_ms", r.LatencyMs),
+		otellog.Int("http.response.status_code", r.StatusCode),
+		otellog.Bool("ai_gateway.success", r.Success),
+	}
+	if r.TimeToFirstTokenMs > 0 {
+		attrs = append(attrs, otellog.Float64("ai_gateway.time_to_first_token_ms", r.TimeToFirstTokenMs))
+	}
+	for _, t := range []struct {
+		key   string
+		value *uint32
+	}{
+		{"gen_ai.usage.input_tokens", r.InputTokens},
+		{"gen_ai.usage.cache_read.input_tokens", r.CachedInputTokens},
+		{"gen_ai.usage.cache_creation.input_tokens", r.CacheCreationInputTokens},
+		{"gen_ai.usage.output_tokens", r.OutputTokens},
+		{"gen_ai.usage.reasoning_tokens", r.ReasoningTokens},
+		{"gen_ai.usage.total_tokens", r.TotalTokens},
+	} {
+		if t.value != nil {
+			attrs = append(attrs, otellog.Int64(t.key, int64(*t.value)))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.Costs)) {
+		// Costs are reported as int64 since log attributes have no unsigned type.
+		attrs = append(attrs, otellog.Int64("ai_gateway.cost."+k, int64(r.Costs[k]))) //nolint:gosec
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.Attributes)) {
+		attrs = append(attrs, otellog.String(k, r.Attributes[k]))
+	}
+	lr.AddAttributes(attrs...)
+	return lr
+}
@@ -0,0 +1,100 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"k8s.io/utils/ptr"
+
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+// fakeLogExporter collects the exported log records.
+type fakeLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (f *fakeLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range records {
+		f.records = append(f.records, r.Clone())
+	}
+	return nil
+}
+
+func (f *fakeLogExporter) Shutdown(context.Context) error   { return nil }
+func (f *fakeLogExporter) ForceFlush(context.Context) error { return nil }
+
+func TestOTLPSink_Write(t *testing.T) {
+	exp := &fakeLogExporter{}
+	s := newOTLPSink(exp, resource.Empty())
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, s.Write(t.Context(), []Record{{
+		Timestamp:          ts,
+		RequestID:          "req-1",
+		RouteName:          "route",
+		BackendName:        "backend",
+		OriginalModel:      "gpt-4o",
+		RequestModel:       "gpt-4o",
+		ResponseModel:      "gpt-4o-2024-08-06",
+		Stream:             true,
+		InputTokens:        ptr.To[uint32](10),
+		OutputTokens:       ptr.To[uint32](20),
+		Costs:              map[string]uint64{"total": 30},
+		Attributes:         map[string]string{"tenant.id": "a"},
+		LatencyMs:          100,
+		TimeToFirstTokenMs: 12.5,
+		StatusCode:         200,
+		Success:            true,
+	}}))
+	require.NoError(t, s.Close(t.Context()))
+
+	require.Len(t, exp.records, 1)
+	r := exp.records[0]
+	require.Equal(t, ts, r.Timestamp())
+	require.Equal(t, otlpEventName, r.EventName())
+	require.Equal(t, otellog.SeverityInfo, r.Severity())
+	require.Equal(t, otlpScopeName, r.InstrumentationScope().Name)
+
+	attrs := map[string]otellog.Value{}
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	require.Equal(t, "req-1", attrs["request.id"].AsString())
+	require.Equal(t, "route", attrs["ai_gateway.route.name"].AsString())
+	require.Equal(t, "backend", attrs["ai_gateway.backend.name"].AsString())
+	require.Equal(t, "gpt-4o-2024-08-06", attrs["gen_ai.response.model"].AsString())
+	require.True(t, attrs["ai_gateway.stream"].AsBool())
+	require.Equal(t, int64(10), attrs["gen_ai.usage.input_tokens"].AsInt64())
+	require.Equal(t, int64(20), attrs["gen_ai.usage.output_tokens"].AsInt64())
+	require.NotContains(t, attrs, "gen_ai.usage.total_tokens")
+	require.Equal(t, int64(30), attrs["ai_gateway.cost.total"].AsInt64())
+	require.Equal(t, "a", attrs["tenant.id"].AsString())
+	require.Equal(t, int64(100), attrs["ai_gateway.latency_ms"].AsInt64())
+	require.InDelta(t, 12.5, attrs["ai_gateway.time_to_first_token_ms"].AsFloat64(), 0.001)
+	require.Equal(t, int64(200), attrs["http.response.status_code"].AsInt64())
+	require.True(t, attrs["ai_gateway.success"].AsBool())
+}
+
+func TestNewOTLPSink(t *testing.T) {
+	internaltesting.ClearTestEnv(t)
+	t.Setenv("OTEL_LOGS_EXPORTER", "none")
+	s, err := NewOTLPSink(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, s.Write(t.Context(), []Record{{RequestID: "1"}}))
+	require.NoError(t, s.Close(t.Context()))
+}
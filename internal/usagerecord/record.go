@@ -0,0 +1,79 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package usagerecord emits one structured usage record per completed LLM request so that
+// token consumption and computed costs can be attributed to individual requests, as opposed
+// to the aggregated histograms exposed via metrics.
+package usagerecord
+
+import (
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/metrics"
+)
+
+// Record is the usage of a single completed request.
+type Record struct {
+	// Timestamp is the time at which the request completed.
+	Timestamp time.Time `json:"timestamp"`
+	// RequestID is the value of the x-request-id header assigned by Envoy.
+	RequestID string `json:"requestId,omitempty"`
+	// RouteName is the name of the AIGatewayRoute that matched the request.
+	RouteName string `json:"routeName,omitempty"`
+	// BackendName is the name of the backend that served the request.
+	BackendName string `json:"backendName,omitempty"`
+	// OriginalModel is the model in the original request body.
+	OriginalModel string `json:"originalModel,omitempty"`
+	// RequestModel is the model sent to the backend, after any override.
+	RequestModel string `json:"requestModel,omitempty"`
+	// ResponseModel is the model reported by the backend in the response.
+	ResponseModel string `json:"responseModel,omitempty"`
+	// Stream is true when the response was streamed.
+	Stream bool `json:"stream"`
+
+	// InputTokens is the number of input tokens, if reported.
+	InputTokens *uint32 `json:"inputTokens,omitempty"`
+	// CachedInputTokens is the number of input tokens read from cache, if reported.
+	CachedInputTokens *uint32 `json:"cachedInputTokens,omitempty"`
+	// CacheCreationInputTokens is the number of input tokens written to cache, if reported.
+	CacheCreationInputTokens *uint32 `json:"cacheCreationInputTokens,omitempty"`
+	// OutputTokens is the number of output tokens, if reported.
+	OutputTokens *uint32 `json:"outputTokens,omitempty"`
+	// ReasoningTokens is the number of reasoning tokens, if reported.
+	ReasoningTokens *uint32 `json:"reasoningTokens,omitempty"`
+	// TotalTokens is the total number of tokens, if reported.
+	TotalTokens *uint32 `json:"totalTokens,omitempty"`
+
+	// Costs maps the metadata key of each configured LLMRequestCost to its computed value.
+	Costs map[string]uint64 `json:"costs,omitempty"`
+	// Attributes are the request header attributes selected for logging.
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// LatencyMs is the duration from the start of the request to its completion in milliseconds.
+	LatencyMs int64 `json:"latencyMs"`
+	// TimeToFirstTokenMs is the time to the first token in milliseconds. Only set for streaming requests.
+	TimeToFirstTokenMs float64 `json:"timeToFirstTokenMs,omitempty"`
+	// StatusCode is the HTTP status code returned by the backend, if any.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Success is true when the request completed successfully.
+	Success bool `json:"success"`
+}
+
+// SetTokenUsage copies every token count that is set in the given usage into the record.
+func (r *Record) SetTokenUsage(usage metrics.TokenUsage) {
+	r.InputTokens = optional(usage.InputTokens())
+	r.CachedInputTokens = optional(usage.CachedInputTokens())
+	r.CacheCreationInputTokens = optional(usage.CacheCreationInputTokens())
+	r.OutputTokens = optional(usage.OutputTokens())
+	r.ReasoningTokens = optional(usage.ReasoningTokens())
+	r.TotalTokens = optional(usage.TotalTokens())
+}
+
+func optional(v uint32, ok bool) *uint32 {
+	if !ok {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,70 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+	"github.com/envoyproxy/ai-gateway/internal/metrics"
+)
+
+func TestRecord_SetTokenUsage(t *testing.T) {
+	var usage metrics.TokenUsage
+	usage.SetInputTokens(10)
+	usage.SetCachedInputTokens(4)
+	usage.SetOutputTokens(20)
+	usage.SetTotalTokens(30)
+
+	var r Record
+	r.SetTokenUsage(usage)
+	require.Equal(t, ptr.To[uint32](10), r.InputTokens)
+	require.Equal(t, ptr.To[uint32](4), r.CachedInputTokens)
+	require.Nil(t, r.CacheCreationInputTokens)
+	require.Equal(t, ptr.To[uint32](20), r.OutputTokens)
+	require.Nil(t, r.ReasoningTokens)
+	require.Equal(t, ptr.To[uint32](30), r.TotalTokens)
+}
+
+func TestRecord_JSON(t *testing.T) {
+	r := Record{
+		Timestamp:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		RequestID:     "req-1",
+		RouteName:     "route",
+		BackendName:   "backend",
+		OriginalModel: "gpt-4o",
+		RequestModel:  "gpt-4o-2024",
+		ResponseModel: "gpt-4o-2024-08-06",
+		InputTokens:   ptr.To[uint32](0),
+		Costs:         map[string]uint64{"llm_input_token": 0},
+		Attributes:    map[string]string{"tenant": "a"},
+		LatencyMs:     12,
+		StatusCode:    200,
+		Success:       true,
+	}
+	b, err := json.Marshal(&r)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"timestamp": "2025-01-02T03:04:05Z",
+		"requestId": "req-1",
+		"routeName": "route",
+		"backendName": "backend",
+		"originalModel": "gpt-4o",
+		"requestModel": "gpt-4o-2024",
+		"responseModel": "gpt-4o-2024-08-06",
+		"stream": false,
+		"inputTokens": 0,
+		"costs": {"llm_input_token": 0},
+		"attributes": {"tenant": "a"},
+		"latencyMs": 12,
+		"statusCode": 200,
+		"success": true
+	}`, string(b))
+}
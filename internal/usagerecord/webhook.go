@@ -0,0 +1,93 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+const (
+	defaultWebhookTimeout        = 10 * time.Second
+	defaultWebhookMaxRetries     = 3
+	defaultWebhookInitialBackoff = 500 * time.Millisecond
+)
+
+// NewWebhookSink returns a Sink that POSTs each batch of records as a JSON array to url.
+//
+// Requests failing with a network error, a 429 or a 5xx status are retried up to maxRetries
+// times with exponential backoff.
+func NewWebhookSink(url string, maxRetries int) Sink {
+	return &webhookSink{
+		url:            url,
+		client:         &http.Client{Timeout: defaultWebhookTimeout},
+		maxRetries:     maxRetries,
+		initialBackoff: defaultWebhookInitialBackoff,
+	}
+}
+
+type webhookSink struct {
+	url            string
+	client         *http.Client
+	maxRetries     int
+	initialBackoff time.Duration
+}
+
+// Write implements [Sink.Write].
+func (s *webhookSink) Write(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage records: %w", err)
+	}
+	backoff := s.initialBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= s.maxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the body once and reports whether a failure is worth retrying.
+func (s *webhookSink) post(ctx context.Context, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create usage record webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send usage records: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("usage record webhook returned status %d", resp.StatusCode)
+}
+
+// Close implements [Sink.Close].
+func (s *webhookSink) Close(context.Context) error {
+	s.client.CloseIdleConnections()
+	return nil
+}
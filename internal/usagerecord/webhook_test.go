@@ -0,0 +1,86 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package usagerecord
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/json"
+)
+
+func TestWebhookSink_Write(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		maxRetries    int
+		expectErr     string
+		expectAttempt int32
+	}{
+		{name: "success", statuses: []int{http.StatusOK}, expectAttempt: 1},
+		{name: "retry on 5xx", statuses: []int{http.StatusBadGateway, http.StatusOK}, maxRetries: 3, expectAttempt: 2},
+		{name: "retry on 429", statuses: []int{http.StatusTooManyRequests, http.StatusAccepted}, maxRetries: 3, expectAttempt: 2},
+		{
+			name:          "no retry on 4xx",
+			statuses:      []int{http.StatusBadRequest},
+			maxRetries:    3,
+			expectErr:     "usage record webhook returned status 400",
+			expectAttempt: 1,
+		},
+		{
+			name:          "retries exhausted",
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxRetries:    2,
+			expectErr:     "usage record webhook returned status 503",
+			expectAttempt: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				var records []Record
+				require.NoError(t, json.Unmarshal(body, &records))
+				require.Len(t, records, 2)
+				w.WriteHeader(tc.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			s := NewWebhookSink(srv.URL, tc.maxRetries).(*webhookSink)
+			s.initialBackoff = time.Millisecond
+			err := s.Write(t.Context(), []Record{{RequestID: "1"}, {RequestID: "2"}})
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectAttempt, attempts.Load())
+			require.NoError(t, s.Close(t.Context()))
+		})
+	}
+}
+
+func TestWebhookSink_NetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	s := NewWebhookSink(url, 1).(*webhookSink)
+	s.initialBackoff = time.Millisecond
+	err := s.Write(t.Context(), []Record{{RequestID: "1"}})
+	require.ErrorContains(t, err, "failed to send usage records")
+}
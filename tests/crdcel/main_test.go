@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
+	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	aigv1b1 "github.com/envoyproxy/ai-gateway/api/v1beta1"
 	testsinternal "github.com/envoyproxy/ai-gateway/tests/internal"
 )
@@ -93,6 +94,8 @@ func TestAIServiceBackends(t *testing.T) {
 			expErr: "spec.schema.name: Unsupported value: \"SomeRandomVendor\": supported values: \"OpenAI\", \"Cohere\", \"AWSBedrock\", \"AzureOpenAI\", \"GCPVertexAI\", \"GCPAnthropic\", \"Anthropic\"",
 		},
 		{name: "k8s-svc.yaml", expErr: "BackendRef must be a Backend resource of Envoy Gateway"},
+		{name: "admission.yaml"},
+		{name: "admission-queue-timeout.yaml", expErr: "queueTimeout must be less than 10s"},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			data, err := testdata.ReadFile(path.Join("testdata/aiservicebackends", tc.name))
@@ -247,6 +250,15 @@ func TestMCPRoutes(t *testing.T) {
 			name:   "tool_selector_exclude_both.yaml",
 			expErr: "spec.backendRefs[0].toolSelector: Invalid value: \"object\": exclude and excludeRegex are mutually exclusive",
 		},
+		{name: "resource_and_prompt_selectors.yaml"},
+		{
+			name:   "resource_selector_both.yaml",
+			expErr: "spec.backendRefs[0].resourceSelector: Invalid value: \"object\": include and includeRegex are mutually exclusive",
+		},
+		{
+			name:   "prompt_selector_missing.yaml",
+			expErr: "spec.backendRefs[0].promptSelector: Invalid value: \"object\": at least one of include, includeRegex, exclude, or excludeRegex must be specified",
+		},
 		{
 			name:   "backend_api_key_inline_and_secret.yaml",
 			expErr: "spec.backendRefs[0].securityPolicy.apiKey: Invalid value: \"object\": exactly one of secretRef or inline must be set",
@@ -259,6 +271,91 @@ func TestMCPRoutes(t *testing.T) {
 			name:   "backend_api_key_both_header_and_query.yaml",
 			expErr: "only one of header or queryParam can be set",
 		},
+		{name: "backend_oauth_token_exchange.yaml"},
+		{
+			name:   "backend_oauth_token_exchange_http_endpoint.yaml",
+			expErr: "spec.backendRefs[0].securityPolicy.oauthTokenExchange.tokenEndpoint: Invalid value: \"string\": tokenEndpoint must be an https URL",
+		},
+		{
+			name:   "backend_oauth_token_exchange_obo_without_client_auth.yaml",
+			expErr: "spec.backendRefs[0].securityPolicy.oauthTokenExchange: Invalid value: \"object\": clientAuth is required for the OnBehalfOf grant type",
+		},
+		{
+			name:   "backend_oauth_token_exchange_and_api_key.yaml",
+			expErr: "spec.backendRefs[0].securityPolicy: Invalid value: \"object\": only one of apiKey or oauthTokenExchange can be set",
+		},
+		{name: "tool_pinning.yaml"},
+		{
+			name:   "tool_pinning_invalid_action.yaml",
+			expErr: "spec.backendRefs[0].toolPinning.action: Unsupported value: \"Allow\": supported values: \"Drop\", \"Flag\", \"Block\"",
+		},
+		{name: "schema_validation.yaml"},
+		{
+			name:   "schema_validation_invalid_action.yaml",
+			expErr: "spec.backendRefs[0].schemaValidation.structuredContent: Unsupported value: \"Drop\": supported values: \"Flag\", \"Strip\"",
+		},
+		{name: "tool_overrides.yaml"},
+		{
+			name:   "tool_name_prefix_disabled_twice.yaml",
+			expErr: "spec.backendRefs: Invalid value: \"array\": at most one backendRef can disable the tool name prefix",
+		},
+		{
+			name:   "tool_override_description_and_append.yaml",
+			expErr: "spec.backendRefs[0].toolOverrides[0]: Invalid value: \"object\": description and appendDescription are mutually exclusive",
+		},
+		{name: "openapi_backend.yaml"},
+		{
+			name:   "openapi_document_missing.yaml",
+			expErr: "spec.backendRefs[0].openAPI.document: Invalid value: \"object\": exactly one of inline or configMapRef must be set",
+		},
+		{
+			name:   "openapi_query_param_api_key.yaml",
+			expErr: "spec.backendRefs[0]: Invalid value: \"object\": queryParam API keys are not supported for openAPI backends",
+		},
+		{name: "rate_limits.yaml"},
+		{
+			name:   "rate_limit_unknown_backend.yaml",
+			expErr: "spec: Invalid value: \"object\": rateLimits must reference the names of backendRefs",
+		},
+		{
+			name:   "rate_limit_header_missing.yaml",
+			expErr: "spec.rateLimits[0].clientKey: Invalid value: \"object\": header must be set if and only if type is Header",
+		},
+		{
+			name:   "rate_limit_zero_window.yaml",
+			expErr: "spec.rateLimits[0]: Invalid value: \"object\": window must be positive",
+		},
+		{name: "tool_approval.yaml"},
+		{
+			name:   "tool_approval_invalid_webhook_url.yaml",
+			expErr: "spec.backendRefs[0].toolApproval.webhook.url: Invalid value: \"string\": url must be an http or https URL",
+		},
+		{
+			name:   "tool_approval_zero_timeout.yaml",
+			expErr: "spec.backendRefs[0].toolApproval: Invalid value: \"object\": timeout must be positive",
+		},
+		{name: "sse_transport.yaml"},
+		{
+			name:   "sse_transport_unsupported.yaml",
+			expErr: "spec.backendRefs[0].transport: Unsupported value: \"WebSocket\": supported values: \"StreamableHTTP\", \"SSE\"",
+		},
+		{
+			name:   "sse_transport_openapi.yaml",
+			expErr: "spec.backendRefs[0]: Invalid value: \"object\": transport cannot be SSE for openAPI backends",
+		},
+		{
+			name:   "sse_transport_query_param_api_key.yaml",
+			expErr: "spec.backendRefs[0]: Invalid value: \"object\": queryParam API keys are not supported for SSE backends",
+		},
+		{name: "tool_search.yaml"},
+		{
+			name:   "tool_search_invalid_embeddings_url.yaml",
+			expErr: "spec.toolSearch.embeddings.url: Invalid value: \"string\": url must be an http or https URL",
+		},
+		{
+			name:   "tool_search_zero_max_results.yaml",
+			expErr: "spec.toolSearch.maxResults: Invalid value: 0: spec.toolSearch.maxResults in body should be greater than or equal to 1",
+		},
 		{
 			name:   "jwks_missing.yaml",
 			expErr: "spec.securityPolicy.oauth.jwks: Invalid value: \"object\": either remoteJWKS or localJWKS must be specified.",
@@ -280,6 +377,11 @@ func TestMCPRoutes(t *testing.T) {
 			expErr: "spec.securityPolicy.authorization.rules[0].source.jwt: Invalid value: \"object\": either scopes or claims must be specified",
 		},
 		{name: "authorization_without_jwt_source.yaml"},
+		{name: "authorization_resource_prompt_method_targets.yaml"},
+		{
+			name:   "authorization_empty_target.yaml",
+			expErr: "spec.securityPolicy.authorization.rules[0].target: Invalid value: \"object\": at least one of tools, resources, prompts or methods must be specified",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			data, err := testdata.ReadFile(path.Join("testdata/mcpgatewayroutes", tc.name))
@@ -298,3 +400,35 @@ func TestMCPRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestQuotaPolicies(t *testing.T) {
+	c, _, _ := testsinternal.NewEnvTest(t)
+	ctx := t.Context()
+
+	for _, tc := range []struct {
+		name   string
+		expErr string
+	}{
+		{name: "concurrency_limits.yaml"},
+		{
+			name:   "concurrency_limits_source_cidr.yaml",
+			expErr: "spec.perModelQuotas[0].quota.concurrencyLimits[1].clientSelectors: Invalid value: \"array\": only header selectors are supported in concurrency limits",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := testdata.ReadFile(path.Join("testdata/quotapolicies", tc.name))
+			require.NoError(t, err)
+
+			quotaPolicy := &aigv1a1.QuotaPolicy{}
+			err = yaml.UnmarshalStrict(data, quotaPolicy)
+			require.NoError(t, err)
+
+			if tc.expErr != "" {
+				require.ErrorContains(t, c.Create(ctx, quotaPolicy), tc.expErr)
+			} else {
+				require.NoError(t, c.Create(ctx, quotaPolicy))
+				require.NoError(t, c.Delete(ctx, quotaPolicy))
+			}
+		})
+	}
+}